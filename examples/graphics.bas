@@ -527,8 +527,6 @@
 9160 NEXT I
 9170 
 9180 WAIT 100
-9185 REM Musik stoppen bei Programmende
-9186 MUSIC STOP
 9190 END
 
 REM Subroutinen
@@ -51,7 +51,9 @@ const RESPONSE_TYPE_MAP = {
     28: 'IMAGE',        // Image commands (LOAD, SHOW, HIDE, ROTATE)
     29: 'PARTICLE',     // Particle system commands
     30: 'SFX',          // Sound effects via sfxr.js
-    31: 'PHYSICS'       // Physics commands via Planck.js
+    31: 'PHYSICS',      // Physics commands via Planck.js
+    32: 'THEME',        // Terminal-Theme (Farben, Scanlines, Schrift)
+    34: 'PING'          // Latenzmessung (ping-Befehl)
 };
 
 // Zentrales RetroConsole-Objekt global anlegen, falls noch nicht vorhanden
@@ -81,6 +83,7 @@ Object.assign(window.RetroConsole, {
     telnetEchoBuffer: [], // Buffer for tracking sent characters to suppress echo {char, timestamp}
     telnetServerEcho: false, // Whether server handles echoing (prevents double characters)
     chatMode: false,    // Chat mode activated
+    musicSuspended: false, // SID music paused while telnet or chat is active
       // LOCATE-Support
     cursorX: 0,         // Aktuelle Cursor-X-Position (0-basiert)
     cursorY: 0,         // Aktuelle Cursor-Y-Position (0-basiert)
@@ -88,6 +91,9 @@ Object.assign(window.RetroConsole, {
     
     // 2D-Array für positioned text (für LOCATE)
     screenBuffer: null, // Wird dynamisch erstellt: [y][x] = {char, inverse}
+
+    // WIDTH-Support: CFG.TEXT_COLS ist die aktuelle Spaltenzahl, baseTextCols die des Terminals
+    baseTextCols: CFG.TEXT_COLS,
     
     // INVERSE-Support
     inverseMode: false, // Inverser Text-Modus aktiviert
@@ -246,9 +252,10 @@ Object.assign(window.RetroConsole, {
 
         // Berechne die finalen Zeichendimensionen basierend auf dem verfügbaren Platz
         // CHAR_WIDTH (OHNE Math.floor für diesen Test)
-        this.CHAR_WIDTH = availableWidth / CFG.TEXT_COLS; 
+        // Immer die Breite der Terminal-Spalten: WIDTH 40 streckt beim Zeichnen (siehe drawTerminal)
+        this.CHAR_WIDTH = availableWidth / this.baseTextCols; 
         // cursorAdvanceRate (präzise) für Cursor-Positionierung und -Breite
-        this.cursorAdvanceRate = availableWidth / CFG.TEXT_COLS;
+        this.cursorAdvanceRate = availableWidth / this.baseTextCols;
 
         this.CHAR_HEIGHT = availableHeight / CFG.TEXT_ROWS;
         this.CHAR_FONT_SIZE = CFG.FONT_SIZE_PX; // FONT_SIZE_PX bleibt als Referenz für die Schriftgröße
@@ -335,6 +342,10 @@ Object.assign(window.RetroConsole, {
         const padL = CFG.SCREEN_PADDING_LEFT; // Direkte Verwendung aus CFG
         const padT = CFG.SCREEN_PADDING_TOP;   // Direkte Verwendung aus CFG
 
+        // WIDTH 40: Zeichen horizontal strecken, damit die schmaleren Zeilen die volle Breite füllen
+        const widthScale = this.baseTextCols / CFG.TEXT_COLS;
+        ctx.setTransform(widthScale, 0, 0, 1, padL * (1 - widthScale), 0);
+
         // Entferne gerundete Padding-Werte, verwende padL, padT direkt
         // const rPadL = Math.round(padL);
         // const rPadT = Math.round(padT);
@@ -388,6 +399,9 @@ Object.assign(window.RetroConsole, {
                     const bgHeight = this.CHAR_HEIGHT - 2; // Text-Höhe minus 2 Pixel (endet 2 Pixel vor nächster Linie)
                     ctx.fillRect(x, bgY, this.CHAR_WIDTH, bgHeight);
                     ctx.fillStyle = '#000000';
+                } else if (typeof invArr[col] === 'number') {
+                    // Farbiger Text: Helligkeitsstufe der Palette
+                    ctx.fillStyle = CFG.BRIGHTNESS_LEVELS[invArr[col]];
                 } else {
                     ctx.fillStyle = CFG.BRIGHTNESS_LEVELS[15];
                 }
@@ -647,6 +661,24 @@ Object.assign(window.RetroConsole, {
         if (this.telnetMode || this.terminalStatus) {
             this.drawTerminalStatusLine(ctx, padL, padT);
         }
+        ctx.setTransform(1, 0, 0, 1, 0, 0);
+    },
+
+    // WIDTH n aus BASIC: n Spalten anzeigen und den Bildschirm löschen wie GW-BASIC
+    setTextWidth: function(cols) {
+        cols = parseInt(cols, 10);
+        if (!cols || cols < 1 || cols > this.baseTextCols) {
+            cols = this.baseTextCols;
+        }
+        if (cols === CFG.TEXT_COLS) return;
+        CFG.TEXT_COLS = cols;
+        if (window.CONFIG && window.CONFIG !== CFG) window.CONFIG.TEXT_COLS = cols;
+        this.lines = [];
+        this.inverseLines = [];
+        this.screenBuffer = null;
+        this.cursorX = 0;
+        this.cursorY = 0;
+        this.drawTerminal();
     },
 
     // Draws the terminal status line in the last row (for pager mode etc.)
@@ -763,7 +795,9 @@ Object.assign(window.RetroConsole, {
 
                     // Process BREAK messages
                     if (contentStr === "BREAK") {
-                        // Stop SID music on BREAK (Ctrl-C / program interruption)
+                        // Stop SID music on BREAK (Ctrl-C / program interruption),
+                        // also music that telnet or chat has paused
+                        this.musicSuspended = false;
                         try {
                             if (window.RetroSound && typeof window.RetroSound.stopSidMusic === 'function') {
                                 window.RetroSound.stopSidMusic();
@@ -789,7 +823,9 @@ Object.assign(window.RetroConsole, {
                         return;
                     } else {
                         // Normal sequential text mode with inverse support
+                        // inverseLines holds true for inverse and a palette level for colored characters
                         const isInverse = (typeof response.inverse === 'boolean') ? response.inverse : this.inverseMode;
+                        const mark = (!isInverse && response.color >= 1 && response.color <= 15) ? response.color : isInverse;
                         if (response.noNewline) {
                             // Append to last line
                             if (this.lines.length > 0) {
@@ -798,18 +834,18 @@ Object.assign(window.RetroConsole, {
                                 // Fill inverse array
                                 if (!this.inverseLines[lastIdx]) this.inverseLines[lastIdx] = [];
                                 for (let i = 0; i < contentStr.length; i++) {
-                                    this.inverseLines[lastIdx].push(isInverse);
+                                    this.inverseLines[lastIdx].push(mark);
                                 }
                             } else {
                                 this.lines.push(contentStr);
-                                this.inverseLines.push(Array(contentStr.length).fill(isInverse));
+                                this.inverseLines.push(Array(contentStr.length).fill(mark));
                             }
                         } else {
                             // Add new line(s)
                             const wrappedLines = this.wrapText(contentStr, CFG.TEXT_COLS);
                             for (let i = 0; i < wrappedLines.length; i++) {
                                 this.lines.push(wrappedLines[i]);
-                                this.inverseLines.push(Array(wrappedLines[i].length).fill(isInverse));
+                                this.inverseLines.push(Array(wrappedLines[i].length).fill(mark));
                             }
                         }
                     }
@@ -897,6 +933,7 @@ Object.assign(window.RetroConsole, {
                             const action = response.params.action;
                             switch (action) {
                                 case 'music_open':
+                                    this.musicSuspended = false; // New music is not resumed later
                                     if (window.RetroSound && typeof window.RetroSound.openSidMusic === 'function') {
                                         window.RetroSound.openSidMusic(response.params.filename);
                                     }
@@ -907,6 +944,7 @@ Object.assign(window.RetroConsole, {
                                     }
                                     break;
                                 case 'music_stop':
+                                    this.musicSuspended = false; // Stopped music is not resumed after telnet or chat
                                     if (window.RetroSound && typeof window.RetroSound.stopSidMusic === 'function') {
                                         window.RetroSound.stopSidMusic();
                                     }
@@ -916,6 +954,28 @@ Object.assign(window.RetroConsole, {
                                         window.RetroSound.pauseSidMusic();
                                     }
                                     break;
+                                case 'music_resume':
+                                    if (window.RetroSound && typeof window.RetroSound.resumeSidMusic === 'function') {
+                                        window.RetroSound.resumeSidMusic();
+                                    }
+                                    break;
+                                case 'music_tune':
+                                    if (window.RetroSound && typeof window.RetroSound.selectSidTune === 'function') {
+                                        window.RetroSound.selectSidTune(response.params.tune);
+                                    }
+                                    break;
+                                case 'music_progress':
+                                    // MUSIC SCOPE ON: play position only, no sound
+                                    if (window.RetroSound && typeof window.RetroSound.sidProgress === 'function') {
+                                        window.RetroSound.sidProgress(response.params);
+                                    }
+                                    break;
+                                case 'sfx':
+                                    // Named system sound effect, already scaled by the user's volume
+                                    if (typeof window.RetroSound.playSFXEvent === 'function') {
+                                        window.RetroSound.playSFXEvent(response.params.effect, response.params.volume);
+                                    }
+                                    break;
                             }
                             break;
                         }
@@ -1163,6 +1223,13 @@ Object.assign(window.RetroConsole, {
                                 // console.log('[RetroConsole-CIRCLE] window.RetroGraphics:', window.RetroGraphics);
                             }
                             break;
+                        case 'PAINT':
+                            if (window.RetroGraphics && typeof window.RetroGraphics.handlePaint === 'function') {
+                                window.RetroGraphics.handlePaint(graphicsCommand);
+                            } else if (this.debugMode) {
+                                // console.log('[RetroConsole-GRAPHICS] RetroGraphics.handlePaint not available');
+                            }
+                            break;
                         case 'FILL':
                              if (window.RetroGraphics && typeof window.RetroGraphics.handleFill === 'function') {
                                 window.RetroGraphics.handleFill(graphicsCommand);
@@ -1180,6 +1247,19 @@ Object.assign(window.RetroConsole, {
                                 // console.log('[RetroConsole-GRAPHICS] RetroGraphics.handleClearScreen not available');
                             }
                             break;
+                        case 'SCREEN_MODE':
+                            if (window.RetroGraphics && typeof window.RetroGraphics.handleScreenMode === 'function') {
+                                window.RetroGraphics.handleScreenMode(graphicsCommand);
+                            }
+                            break;
+                        case 'FLIP':
+                            if (window.RetroGraphics && typeof window.RetroGraphics.handleFlip === 'function') {
+                                window.RetroGraphics.handleFlip();
+                            }
+                            break;
+                        case 'TEXT_WIDTH':
+                            this.setTextWidth(graphicsCommand.cols);
+                            break;
                         case 'UPDATE_VECTOR':
                             if (window.RetroGraphics && typeof window.RetroGraphics.handleUpdateVector === 'function') {
                                 window.RetroGraphics.handleUpdateVector(graphicsCommand);
@@ -1241,6 +1321,9 @@ Object.assign(window.RetroConsole, {
                 }
                 break;
             case 'CHAT':
+                if (response.params?.muteMusic) {
+                    this.suspendMusic();
+                }
                 this.initiateChatMode();
                 break;
             case 'LOCATE':
@@ -1323,6 +1406,16 @@ Object.assign(window.RetroConsole, {
                 // Handle PHYSICS messages
                 this.handlePhysicsMessage(response);
                 break;
+            case 'THEME':
+                // Theme descriptor sent at connect, login and by the theme command
+                this.handleThemeMessage(response);
+                break;
+            case 'PING':
+                // Echo the timestamp at once, the backend measures the round trip
+                if (window.ws && window.ws.readyState === WebSocket.OPEN) {
+                    window.ws.send(JSON.stringify({ type: 34, content: response.content }));
+                }
+                break;
                 
             default:
                 // console.warn('[EDITOR-CONSOLE] Unknown editor command:', message.editorCommand, message);
@@ -1505,6 +1598,9 @@ Object.assign(window.RetroConsole, {
                         // console.log('[EDITOR-CONSOLE] "render": Set statusText =', this.editorData.statusText);
                     }
                     
+                    // Row of the last runtime error after save & run (-1 = none)
+                    this.editorData.highlightLine = typeof message.params.highlightLine === 'number' ? message.params.highlightLine : -1;
+                    
                     // Handle content lines
                     if (message.params.lines && Array.isArray(message.params.lines)) {
                         this.editorData.lines = message.params.lines;
@@ -1597,6 +1693,12 @@ Object.assign(window.RetroConsole, {
                 const line = this.editorData.lines[i];
                 let lineChars = Array.from(line || ""); // Konvertiere zu Array von Zeichen für korrekte Unicode-Behandlung
                 
+                // Fehlerzeile nach Save & Run hinterlegen
+                if (i === this.editorData.highlightLine) {
+                    ctx.fillStyle = CFG.BRIGHTNESS_LEVELS[4];
+                    ctx.fillRect(padL, padT + i * this.CHAR_HEIGHT, this.editorData.cols * this.CHAR_WIDTH, this.CHAR_HEIGHT);
+                }
+                
                 ctx.fillStyle = CFG.BRIGHTNESS_LEVELS[15]; // Standard-Grün für Text
                 
                 // Optimiert: Zeichne jeden Buchstaben einzeln für bessere Kontrolle
@@ -1712,10 +1814,16 @@ Object.assign(window.RetroConsole, {
             this.telnetServerEcho = false; // Initialize as false until server negotiates
             // console.log('[TELNET-MODE] Telnet mode activated, server:', response.params?.serverName);
             this.telnetServerName = response.params?.serverName || 'Unknown Server';
+            if (response.params?.muteMusic) {
+                this.suspendMusic();
+            }
             this.inputEnabled = true;
             this.runMode = false;            // Clear screen and show telnet header
-            this.lines = [];
-            this.inverseLines = [];
+            // The shell screen comes back from the server after the session
+            if (response.params?.clearScreen !== false) {
+                this.lines = [];
+                this.inverseLines = [];
+            }
             this.lines.push(`Connected to ${this.telnetServerName}`);
             this.inverseLines.push([]);
             this.lines.push('Press Ctrl+X or ESC to exit telnet session');
@@ -1733,6 +1841,7 @@ Object.assign(window.RetroConsole, {
             this.telnetServerEcho = false; // Reset echo flag
             this.telnetEchoBuffer = []; // Clear echo buffer
             this.telnetServerName = '';
+            this.resumeSuspendedMusic();
             
             // Reset message processing state
             if (window.lastTelnetMessage) {
@@ -2198,6 +2307,8 @@ Object.assign(window.RetroConsole, {
         };        // Handle special keys and control combinations
         if (event.ctrlKey && event.key.toLowerCase() === "s") {
             data = { key: "CTRL+S" };
+        } else if (event.ctrlKey && event.key.toLowerCase() === "r") {
+            data = { key: "CTRL+R" }; // Save & run
         } else if (event.ctrlKey && event.key.toLowerCase() === "x") {
             data = { key: "CTRL+X" };
         } else if (event.ctrlKey && event.key.toLowerCase() === "c") {
@@ -2264,6 +2375,59 @@ Object.assign(window.RetroConsole, {
         // Debug output removed for production
     },
 
+    // Appends a chunk of a streamed MCP answer. The answer is wrapped again
+    // in place as long as nothing was printed below it.
+    appendChatStream: function(content) {
+        const stream = this.chatStream;
+        if (stream && stream.lineCount > 0 && this.lines[this.lines.length - 1] === stream.lastLine) {
+            this.lines.splice(Math.max(0, this.lines.length - stream.lineCount), stream.lineCount);
+        } else {
+            // New answer, or the answer goes on below other output
+            this.chatStream = { text: stream ? "" : "MCP: ", lineCount: 0, lastLine: null };
+        }
+        this.chatStream.text += content;
+        const wrappedLines = this.wrapText(this.chatStream.text, CFG.TEXT_COLS);
+        this.lines.push(...wrappedLines);
+        this.chatStream.lineCount = wrappedLines.length;
+        this.chatStream.lastLine = wrappedLines[wrappedLines.length - 1];
+    },
+
+    // Ends a streamed MCP answer, marking it if it was cancelled
+    endChatStream: function(cancelled) {
+        if (cancelled) {
+            if (this.chatStream) {
+                this.appendChatStream(" [cancelled]");
+            } else {
+                this.lines.push("MCP: [cancelled]");
+            }
+        }
+        this.chatStream = null;
+    },
+
+    // Pauses SID music that plays when telnet or chat starts. Only music
+    // paused here is resumed by resumeSuspendedMusic.
+    suspendMusic: function() {
+        if (this.musicSuspended || !window.RetroSound || typeof window.RetroSound.isSidMusicPlaying !== 'function') {
+            return;
+        }
+        if (window.RetroSound.isSidMusicPlaying()) {
+            window.RetroSound.pauseSidMusic();
+            this.musicSuspended = true;
+        }
+    },
+
+    // Resumes the music paused by suspendMusic. Music stopped or replaced in
+    // the meantime has cleared musicSuspended and stays silent.
+    resumeSuspendedMusic: function() {
+        if (!this.musicSuspended) {
+            return;
+        }
+        this.musicSuspended = false;
+        if (window.RetroSound && typeof window.RetroSound.resumeSidMusic === 'function') {
+            window.RetroSound.resumeSidMusic();
+        }
+    },
+
     // Chat mode initiation - automatically opens a Chat WebSocket connection
     initiateChatMode: function() {
         
@@ -2328,6 +2492,16 @@ Object.assign(window.RetroConsole, {
                     messages.forEach(function(messageData) {
                         try {
                             const chatResponse = JSON.parse(messageData);
+
+                            // Streamed MCP answer
+                            if (chatResponse.type === 40) { // CHUNK
+                                window.RetroConsole.appendChatStream(chatResponse.content);
+                                return;
+                            }
+                            if (chatResponse.type === 41) { // DONE
+                                window.RetroConsole.endChatStream(chatResponse.content === "cancelled");
+                                return;
+                            }
                               
                             // Add chat messages to terminal
                             if (chatResponse.content) {
@@ -2376,6 +2550,7 @@ Object.assign(window.RetroConsole, {
               
             window.chatWs.onclose = function(event) {
                 window.RetroConsole.inputEnabled = true; // Re-enable input
+                window.RetroConsole.resumeSuspendedMusic();
                 window.RetroConsole.lines.push("");
                 window.RetroConsole.lines.push("Chat terminated.");
                 window.RetroConsole.drawTerminal();
@@ -2384,6 +2559,7 @@ Object.assign(window.RetroConsole, {
             
             window.chatWs.onerror = function(error) {
                 window.RetroConsole.inputEnabled = true; // Re-enable input
+                window.RetroConsole.resumeSuspendedMusic();
                 window.RetroConsole.lines.push("Error in chat connection.");
                 window.RetroConsole.drawTerminal();
                 window.chatWs = null;
@@ -2889,6 +3065,25 @@ Object.assign(window.RetroConsole, {
         }
     },
     
+    // Apply a theme descriptor (palette, scanlines, glow, font) from the backend
+    handleThemeMessage: function(response) {
+        const theme = response.params;
+        if (!theme || !Array.isArray(theme.palette) || theme.palette.length !== 16) {
+            console.warn('[RetroConsole-THEME] Invalid theme descriptor:', response);
+            return;
+        }
+        // Update the palette in place: other modules keep references to these arrays
+        for (const config of [CFG, window.CONFIG]) {
+            if (!config) continue;
+            theme.palette.forEach((color, i) => { config.BRIGHTNESS_LEVELS[i] = color; });
+            if (theme.font) config.FONT_FAMILY = theme.font;
+        }
+        if (window.RetroGraphics && typeof window.RetroGraphics.applyTheme === 'function') {
+            window.RetroGraphics.applyTheme(theme);
+        }
+        if (this.editorMode) this.drawEditor(); else this.drawTerminal();
+    },
+
     refreshTokenForSession: async function(sessionId) {
         // console.log('[RetroConsole-TOKEN] Refreshing token for session:', sessionId);
         
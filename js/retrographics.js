@@ -409,6 +409,12 @@ let persistentGraphicsContext = null;
 let persistent2DCanvas = null;
 let persistent2DContext = null;
 
+// Sichtbare Seite bei SCREEN DOUBLE: persistent2DCanvas ist dann die verdeckte
+// Zeichenseite, angezeigt wird der zuletzt mit FLIP übernommene Inhalt
+let displayed2DCanvas = null;
+let displayed2DContext = null;
+let screenBuffered = false;
+
 let glowCanvas, glowTexture, glowCtx;
 
 let renderer;
@@ -508,6 +514,10 @@ function initGraphicsPipeline(textCanvasSource, textTextureSource) {
     persistent2DContext = persistent2DCanvas.getContext('2d');
     if (persistent2DContext) {
         persistent2DContext.imageSmoothingEnabled = CFG.GRAPHICS_ANTIALIASING !== undefined ? CFG.GRAPHICS_ANTIALIASING : false;
+        displayed2DCanvas = document.createElement('canvas');
+        displayed2DCanvas.width = CFG.GRAPHICS_WIDTH;
+        displayed2DCanvas.height = CFG.GRAPHICS_HEIGHT;
+        displayed2DContext = displayed2DCanvas.getContext('2d');
     } else {
 
         return;
@@ -750,7 +760,9 @@ function animateCRT() {
             
             // Copy persistent2D graphics to main graphics canvas if needed
             if (persistent2DCanvas && persistent2DContext) {
-                persistentGraphicsContext.drawImage(persistent2DCanvas, 0, 0);
+                // Bei SCREEN DOUBLE die zuletzt übernommene Seite anzeigen
+                const visible2DCanvas = screenBuffered && displayed2DCanvas ? displayed2DCanvas : persistent2DCanvas;
+                persistentGraphicsContext.drawImage(visible2DCanvas, 0, 0);
                 // Keep graphics2D dirty if persistent2D canvas has content
                 window.RetroGraphics._graphics2DDirty = true;
             }
@@ -971,6 +983,105 @@ function handleFill(data) {
     window.RetroGraphics._graphics2DDirty = true;
 }
 
+// Toleranz pro Farbkanal, damit geglättete Kanten (Antialiasing) die Füllung begrenzen
+const PAINT_COLOR_TOLERANCE = 48;
+
+function parseHexColor(hex) {
+    const match = /^#?([0-9a-f]{6})$/i.exec(hex || '');
+    if (!match) {
+        return null;
+    }
+    const value = parseInt(match[1], 16);
+    return [(value >> 16) & 255, (value >> 8) & 255, value & 255, 255];
+}
+
+function colorsMatch(pixels, offset, color) {
+    return Math.abs(pixels[offset] - color[0]) <= PAINT_COLOR_TOLERANCE &&
+        Math.abs(pixels[offset + 1] - color[1]) <= PAINT_COLOR_TOLERANCE &&
+        Math.abs(pixels[offset + 2] - color[2]) <= PAINT_COLOR_TOLERANCE &&
+        Math.abs(pixels[offset + 3] - color[3]) <= PAINT_COLOR_TOLERANCE;
+}
+
+// PAINT: Flood Fill ab (x,y). Ohne Randfarbe wird die zusammenhängende Fläche
+// in der Farbe des Startpixels gefüllt, mit Randfarbe bis zu Pixeln dieser Farbe.
+function handlePaint(data) {
+    if (!persistent2DCanvas || !persistent2DContext) {
+        console.warn("[RETROGRAPHICS] Canvas oder Context nicht verfügbar für PAINT");
+        return;
+    }
+
+    const width = persistent2DCanvas.width;
+    const height = persistent2DCanvas.height;
+    const startX = Math.floor(data.x);
+    const startY = Math.floor(data.y);
+    if (startX < 0 || startY < 0 || startX >= width || startY >= height) {
+        return;
+    }
+
+    const fill = parseHexColor(data.color) || parseHexColor('#5FFF5F');
+    const border = data.border ? parseHexColor(data.border) : null;
+
+    const ctx = persistent2DContext;
+    const image = ctx.getImageData(0, 0, width, height);
+    const pixels = image.data;
+    const seed = Array.from(pixels.slice((startY * width + startX) * 4, (startY * width + startX) * 4 + 4));
+
+    const isInside = border
+        ? (offset) => !colorsMatch(pixels, offset, border)
+        : (offset) => colorsMatch(pixels, offset, seed);
+
+    if (!isInside((startY * width + startX) * 4)) {
+        return;
+    }
+
+    // Scanline-Füllung mit eigenem Stack; visited verhindert Endlosschleifen,
+    // wenn Füll- und Flächenfarbe übereinstimmen
+    const visited = new Uint8Array(width * height);
+    const stack = [[startX, startY]];
+    while (stack.length > 0) {
+        const [px, py] = stack.pop();
+        let x = px;
+        while (x > 0 && !visited[py * width + x - 1] && isInside((py * width + x - 1) * 4)) {
+            x--;
+        }
+        let spanAbove = false;
+        let spanBelow = false;
+        for (; x < width; x++) {
+            const index = py * width + x;
+            if (visited[index] || !isInside(index * 4)) {
+                break;
+            }
+            visited[index] = 1;
+            pixels[index * 4] = fill[0];
+            pixels[index * 4 + 1] = fill[1];
+            pixels[index * 4 + 2] = fill[2];
+            pixels[index * 4 + 3] = fill[3];
+
+            if (py > 0) {
+                const above = index - width;
+                const inside = !visited[above] && isInside(above * 4);
+                if (inside && !spanAbove) {
+                    stack.push([x, py - 1]);
+                }
+                spanAbove = inside;
+            }
+            if (py < height - 1) {
+                const below = index + width;
+                const inside = !visited[below] && isInside(below * 4);
+                if (inside && !spanBelow) {
+                    stack.push([x, py + 1]);
+                }
+                spanBelow = inside;
+            }
+        }
+    }
+
+    ctx.putImageData(image, 0, 0);
+
+    // Setze Dirty-Flag für 2D-Grafiken
+    window.RetroGraphics._graphics2DDirty = true;
+}
+
 function handleClearScreen() {
     // Clear the persistent 2D graphics canvas
     if (persistent2DContext && persistent2DCanvas) {
@@ -1005,6 +1116,43 @@ function handleClearScreen() {
     window.RetroGraphics._graphics2DDirty = true;
 }
 
+// copy2DPage kopiert den Inhalt einer 2D-Seite vollständig auf eine andere
+function copy2DPage(fromCanvas, toContext) {
+    if (!fromCanvas || !toContext) {
+        return;
+    }
+    toContext.clearRect(0, 0, toContext.canvas.width, toContext.canvas.height);
+    toContext.drawImage(fromCanvas, 0, 0);
+}
+
+// SCREEN DOUBLE/SINGLE: Doppelpufferung der 2D-Grafik ein- oder ausschalten.
+// Beim Einschalten bleibt das aktuelle Bild sichtbar, beim Ausschalten wird
+// die zuletzt angezeigte Seite zur Zeichenseite, damit nach Programmende oder
+// BREAK nichts Halbfertiges erscheint.
+function handleScreenMode(data) {
+    const buffered = !!(data && data.buffered);
+    if (buffered === screenBuffered || !displayed2DContext) {
+        return;
+    }
+    if (buffered) {
+        copy2DPage(persistent2DCanvas, displayed2DContext);
+    } else {
+        copy2DPage(displayed2DCanvas, persistent2DContext);
+    }
+    screenBuffered = buffered;
+    window.RetroGraphics._graphics2DDirty = true;
+}
+
+// FLIP: verdeckte Zeichenseite auf einmal anzeigen. Die Zeichenseite behält
+// ihren Inhalt.
+function handleFlip() {
+    if (!screenBuffered || !displayed2DContext) {
+        return;
+    }
+    copy2DPage(persistent2DCanvas, displayed2DContext);
+    window.RetroGraphics._graphics2DDirty = true;
+}
+
 // Vektor-Grafik-Handler
 function handleUpdateVector(data) {
     // Debug-Ausgaben nur bei aktiviertem Debug-Flag
@@ -1118,7 +1266,10 @@ window.RetroGraphics.handleLine = handleLine;
 window.RetroGraphics.handleRect = handleRect;
 window.RetroGraphics.handleCircle = handleCircle;
 window.RetroGraphics.handleFill = handleFill;
+window.RetroGraphics.handlePaint = handlePaint;
 window.RetroGraphics.handleClearScreen = handleClearScreen;
+window.RetroGraphics.handleScreenMode = handleScreenMode;
+window.RetroGraphics.handleFlip = handleFlip;
 
 // Vektor-Handler
 window.RetroGraphics.handleUpdateVector = handleUpdateVector;
@@ -1177,6 +1328,26 @@ function triggerEvilEffect() {
 // Export the evil effect function
 window.RetroGraphics.triggerEvilEffect = triggerEvilEffect;
 
+// Theme: Scanline-Intensität und Hintergrundleuchten der Bildröhre anpassen
+function applyTheme(theme) {
+    if (typeof theme.scanlines === 'number') {
+        CFG.CRT_EFFECTS.SCANLINES_INTENSITY = theme.scanlines;
+    }
+    if (theme.glow) {
+        CFG.CRT_EFFECTS.BACKGROUND_GLOW.COLOR = theme.glow;
+    }
+    if (!crtMaterial || !crtMaterial.uniforms) {
+        return; // Uniforms werden bei der Initialisierung aus CFG gelesen
+    }
+    if (crtMaterial.uniforms.scanlineIntensity) {
+        crtMaterial.uniforms.scanlineIntensity.value = CFG.CRT_EFFECTS.SCANLINES_INTENSITY;
+    }
+    if (crtMaterial.uniforms.backgroundGlowColor) {
+        crtMaterial.uniforms.backgroundGlowColor.value.set(CFG.CRT_EFFECTS.BACKGROUND_GLOW.COLOR);
+    }
+}
+window.RetroGraphics.applyTheme = applyTheme;
+
 // Temporäre Lösung: Direkt auf Terminal-Canvas zeichnen
 function debugDirectDraw() {
     // Debug output removed for production
@@ -905,11 +905,66 @@ function playNoiseComplexInternal(ctx, pitch, attack, decay) {
     }
 }
 
+// Tonfolgen der System-Soundeffekte: [Frequenz (Hz), Dauer (s), Wellenform]
+const SFX_TONES = {
+    error: [[140, 0.25, 'sawtooth']],
+    login: [[523, 0.09, 'square'], [659, 0.09, 'square'], [784, 0.16, 'square']],
+    beep: [[800, 0.2, 'square']]
+};
+
+// Spielt einen benannten System-Soundeffekt mit der Lautstärke des Benutzers (0.0 bis 1.0)
+function playSFXEvent(effect, volume) {
+    if (typeof volume !== 'number' || volume <= 0) {
+        return; // Stummgeschaltet
+    }
+    volume = Math.min(volume, 1);
+
+    if (effect === 'disk') {
+        if (window.RetroSound.floppyAudio) {
+            window.RetroSound.floppyAudio.volume = volume;
+        }
+        playFloppySound();
+        return;
+    }
+
+    const tones = SFX_TONES[effect];
+    if (!tones) {
+        return;
+    }
+    try {
+        if (!window.RetroSound.audioContext) {
+            window.RetroSound.audioContext = new (window.AudioContext || window.webkitAudioContext)();
+        }
+        const ctx = window.RetroSound.audioContext;
+        if (ctx.state === 'suspended') {
+            ctx.resume();
+        }
+
+        let start = ctx.currentTime;
+        for (const [frequency, duration, waveform] of tones) {
+            const oscillator = ctx.createOscillator();
+            const gainNode = ctx.createGain();
+            oscillator.type = waveform;
+            oscillator.frequency.setValueAtTime(frequency, start);
+            gainNode.gain.setValueAtTime(0.5 * volume, start);
+            gainNode.gain.linearRampToValueAtTime(0, start + duration);
+            oscillator.connect(gainNode);
+            gainNode.connect(ctx.destination);
+            oscillator.start(start);
+            oscillator.stop(start + duration);
+            start += duration;
+        }
+    } catch (e) {
+
+    }
+}
+
 // Methoden an das globale RetroSound-Objekt binden
 window.RetroSound.initSpeech = initSpeech;
 window.RetroSound.speakText = speakText;
 window.RetroSound.playBeep = playBeep;
 window.RetroSound.playFloppySound = playFloppySound;
+window.RetroSound.playSFXEvent = playSFXEvent;
 window.RetroSound.unlockAudio = unlockAudio;
 window.RetroSound.initAudio = window.RetroSound.initAudio; // Verwende bereits definierte Funktion
 window.RetroSound.playSound = playSound;
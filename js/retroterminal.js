@@ -213,8 +213,14 @@ async function setupWebSocket() {
         await globalAuthManager.initialize();
     }
     
-    // Get websocket URL with authentication
-    const wsURL = await globalAuthManager.getWebSocketUrl();
+    // Get websocket URL with authentication; features tells the server this frontend shows colored text
+    const authURL = await globalAuthManager.getWebSocketUrl();
+    let wsURL = authURL + (authURL.includes('?') ? '&' : '?') + 'features=color';
+    // ?scratch=1 on the page starts new guest sessions without the example programs
+    const scratch = new URLSearchParams(window.location.search).get('scratch');
+    if (scratch) {
+        wsURL += '&scratch=' + encodeURIComponent(scratch);
+    }
     
     try {
         const ws = new WebSocket(wsURL);
@@ -389,6 +395,15 @@ function setupInputHandling() {
             // Im Editor-Modus wird STRG+C von RetroConsole.handleEditorKeyDown behandelt
             // und sollte dort sendEditorCommand('key_input', 'CTRL+C') auslösen.
             // Daher hier keine spezielle Behandlung mehr für Editor-Modus nötig.
+
+            // Im Chat-Modus bricht STRG+C die laufende Antwort des MCP ab
+            if (window.chatWs && window.chatWs.readyState === WebSocket.OPEN) {
+                window.chatWs.send(JSON.stringify({ type: "cancel" }));
+                window.RetroConsole.input = "";
+                window.RetroConsole.cursorPos = 0;
+                window.RetroConsole.drawTerminal();
+                return;
+            }
             
             // Im normalen Terminal-Modus: __BREAK__ Kommando als Nachricht senden
             const breakMessage = {
@@ -414,6 +429,10 @@ function setupInputHandling() {
             return;
         }        // INKEY$ Events should work even when normal input is disabled
         if (shouldSendKeyEvent(event.key)) {
+            // F1-F10 gehören im RUN-Modus dem Programm (KEY, ON KEY), nicht dem Browser
+            if (window.RetroConsole.runMode && isFunctionKey(event.key)) {
+                event.preventDefault();
+            }
             sendKeyEvent(16, event.key); // MessageTypeKeyDown = 16
         }
         
@@ -922,6 +941,11 @@ function shouldSendKeyEvent(key) {
         return true;
     }
     
+    // Funktionstasten für KEY-Makros und ON KEY
+    if (isFunctionKey(key)) {
+        return true;
+    }
+    
     // Sende auch normale Zeichen (a-z, A-Z, 0-9, Leerzeichen)
     if (key.length === 1) {
         return /[a-zA-Z0-9 ]/.test(key);
@@ -930,6 +954,11 @@ function shouldSendKeyEvent(key) {
     return false;
 }
 
+// isFunctionKey prüft auf F1 bis F10
+function isFunctionKey(key) {
+    return /^F([1-9]|10)$/.test(key);
+}
+
 function sendKeyEvent(messageType, key) {
     if (!window.ws || window.ws.readyState !== WebSocket.OPEN) {
         return false;
@@ -953,13 +982,26 @@ function sendKeyEvent(messageType, key) {
 function handlePagerKeyDown(event) {
     // Prevent default behavior
     event.preventDefault();
-    
+
+    // "/text" (search) and ":N" (go to line) are typed into the status line
+    // and sent as a whole with Enter
+    if (window.pagerCommandInput !== undefined) {
+        handlePagerCommandKey(event);
+        return;
+    }
+    if (event.key === '/' || event.key === ':') {
+        window.pagerStatusBackup = window.RetroConsole.terminalStatus;
+        window.pagerCommandInput = event.key;
+        updatePagerCommandDisplay();
+        return;
+    }
+
     // Only handle specific keys for pager
     const key = event.key.toLowerCase();
-    if (key === 'm' || key === 'q' || key === 'enter') {
+    if (key === 'm' || key === 'q' || key === 'enter' || key === ' ') {
         // Send immediately without waiting for Enter
         let command = key;
-        if (key === 'enter') command = 'm'; // Enter acts as 'more'        // Send via WebSocket with session ID
+        if (key === 'enter' || key === ' ') command = 'm'; // Enter and Space act as 'more'        // Send via WebSocket with session ID
         const message = {
             type: 1, // Normal text input (like Enter key)
             content: command
@@ -969,6 +1011,41 @@ function handlePagerKeyDown(event) {
     // Ignore other keys in pager mode
 }
 
+// Handle a key while a pager command (/text or :N) is being typed
+function handlePagerCommandKey(event) {
+    if (event.key === 'Enter') {
+        const command = window.pagerCommandInput;
+        endPagerCommandInput();
+        sendMessageWithSessionID({ type: 1, content: command });
+    } else if (event.key === 'Escape') {
+        endPagerCommandInput();
+    } else if (event.key === 'Backspace') {
+        window.pagerCommandInput = window.pagerCommandInput.slice(0, -1);
+        if (window.pagerCommandInput === '') {
+            endPagerCommandInput(); // Deleting the / or : cancels
+        } else {
+            updatePagerCommandDisplay();
+        }
+    } else if (event.key.length === 1 && window.pagerCommandInput.length < 80) {
+        window.pagerCommandInput += event.key;
+        updatePagerCommandDisplay();
+    }
+}
+
+// Show the pager command being typed in the status line
+function updatePagerCommandDisplay() {
+    window.RetroConsole.terminalStatus = window.pagerCommandInput;
+    window.RetroConsole.drawTerminal();
+}
+
+// Leave pager command input and restore the pager status line
+function endPagerCommandInput() {
+    window.RetroConsole.terminalStatus = window.pagerStatusBackup || '';
+    window.pagerCommandInput = undefined;
+    window.pagerStatusBackup = undefined;
+    window.RetroConsole.drawTerminal();
+}
+
 // Handle keydown in telnet mode - send characters immediately and check for exit commands
 function handleTelnetKeyDown(event) {
     try {
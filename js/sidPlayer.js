@@ -16,6 +16,8 @@
     let audioContext = null;
     let scriptProcessor = null;
     let gainNode = null;
+    let analyserNode = null;   // Taps the SID output for the music scope
+    let lastProgress = null;   // Last MUSIC SCOPE position from the server
     
     // Performance-Optimierung für Grafik-intensive Szenen
     let graphicsIntensiveMode = false;
@@ -136,7 +138,10 @@
         audioContext = new (window.AudioContext || window.webkitAudioContext)();
         gainNode = audioContext.createGain();
         gainNode.gain.setValueAtTime(0.5, audioContext.currentTime);
-        gainNode.connect(audioContext.destination);        // Load SID library
+        gainNode.connect(audioContext.destination);
+        analyserNode = audioContext.createAnalyser();
+        analyserNode.fftSize = 256;
+        gainNode.connect(analyserNode);        // Load SID library
         loadJSSID().then(() => {
             // console.log('[SID-PLAYER] SID Player initialized successfully');
         }).catch(error => {
@@ -221,6 +226,9 @@
                 return loadSidFile(filename).then(success => {
                     if (success) {
                         currentFilename = filename;
+                        // jsSID starts the default tune as soon as it is loaded
+                        isPlaying = true;
+                        isPaused = false;
                     } else {
                         console.error(`Failed to load SID music: ${filename}`);
                     }
@@ -238,12 +246,14 @@
 
             if (isPaused) {
                 // Resume from pause
-                startAudioProcessing();
-                isPaused = false;
-                isPlaying = true;
+                window.RetroSound.resumeSidMusic();
             } else if (!isPlaying) {
                 // Start from beginning
-                currentSidPlayer.play();
+                if (typeof currentSidPlayer.play === 'function') {
+                    currentSidPlayer.play();
+                } else if (typeof currentSidPlayer.playcont === 'function') {
+                    currentSidPlayer.playcont();
+                }
                 startAudioProcessing();
                 isPlaying = true;
             }
@@ -258,6 +268,7 @@
                 isPaused = false;
                 currentSidPlayer = null; // Clear the player reference
                 currentFilename = '';     // Clear the filename
+                lastProgress = null;
             }        };
         
         // Audio priority control for graphics-intensive scenes
@@ -276,6 +287,10 @@
           // Pause SID music playbook
         window.RetroSound.pauseSidMusic = function() {
             if (isPlaying) {
+                // jsSID plays through its own audio node
+                if (currentSidPlayer && typeof currentSidPlayer.pause === 'function') {
+                    currentSidPlayer.pause();
+                }
                 stopAudioProcessing();
                 isPlaying = false;
                 isPaused = true;
@@ -285,12 +300,32 @@
         // Resume SID music playback
         window.RetroSound.resumeSidMusic = function() {
             if (isPaused && currentSidPlayer) {
+                if (typeof currentSidPlayer.playcont === 'function') {
+                    currentSidPlayer.playcont();
+                }
                 startAudioProcessing();
                 isPaused = false;
                 isPlaying = true;
             }
         };
 
+        // Switch to another tune of the loaded SID file (1-based like MUSIC TUNE)
+        window.RetroSound.selectSidTune = function(tune) {
+            if (!currentSidPlayer || typeof currentSidPlayer.start !== 'function') {
+                console.warn('No SID music loaded. Use MUSIC OPEN first.');
+                return;
+            }
+            const count = typeof currentSidPlayer.getsubtunes === 'function' ? currentSidPlayer.getsubtunes() : 0;
+            if (count > 0 && (tune < 1 || tune > count)) {
+                console.warn(`SID tune ${tune} does not exist (1-${count})`);
+                return;
+            }
+            currentSidPlayer.start(tune - 1);
+            startAudioProcessing();
+            isPaused = false;
+            isPlaying = true;
+        };
+
         // Check if SID music is playing
         window.RetroSound.isSidMusicPlaying = function() {
             return isPlaying;
@@ -299,11 +334,28 @@
             return {
                 filename: currentFilename,
                 isPlaying: isPlaying,
-                isPaused: isPaused,                currentTime: 0, // TODO: Implement time tracking
+                isPaused: isPaused,                currentTime: lastProgress ? lastProgress.position / 1000 : 0, // Only with MUSIC SCOPE ON
                 duration: 0     // TODO: Implement duration tracking
             };
         };
 
+        // MUSIC SCOPE ON: the server reports the play position while music
+        // plays. Visualizers listen for the 'sidprogress' event, which also
+        // carries a snapshot of the current waveform (128 samples, 128 = silence).
+        window.RetroSound.sidProgress = function(params) {
+            if (!isPlaying || params.filename !== currentFilename) {
+                return; // Late update of music that already stopped
+            }
+            lastProgress = { tune: params.tune, position: params.position };
+            const waveform = new Uint8Array(analyserNode ? analyserNode.fftSize : 0);
+            if (analyserNode) {
+                analyserNode.getByteTimeDomainData(waveform);
+            }
+            window.dispatchEvent(new CustomEvent('sidprogress', {
+                detail: { filename: params.filename, tune: params.tune, position: params.position, waveform: waveform }
+            }));
+        };
+
     // Start audio processing
     function startAudioProcessing() {
         if (scriptProcessor || !audioContext || !currentSidPlayer) return;
@@ -407,6 +459,9 @@
         resume: function() {
             return window.RetroSound.resumeSidMusic();
         },
+        selectTune: function(tune) {
+            return window.RetroSound.selectSidTune(tune);
+        },
         isPlaying: function() {
             return isPlaying;
         }
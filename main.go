@@ -7,7 +7,9 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/antibyte/retroterm/pkg/auth"
@@ -20,7 +22,10 @@ import (
 	"github.com/antibyte/retroterm/pkg/virtualfs"
 )
 
-func main() { // Initialize configuration (before all other initializations)
+func main() {
+	startTime := time.Now()
+
+	// Initialize configuration (before all other initializations)
 	configPath := "settings.cfg"
 	err := configuration.Initialize(configPath)
 	if err != nil {
@@ -38,19 +43,7 @@ func main() { // Initialize configuration (before all other initializations)
 	// First log message
 	logger.ConfigInfo("System started - Configuration loaded from: %s", configPath)
 
-	// Read log file from configuration (for compatibility)
-	logFilePath := configuration.GetString("Debug", "log_file", "debug.log")
-
-	// On program start, delete all previous standard log entries (not the new structured logs)
-	// os.Remove(logFilePath) // Entfernt - wir nutzen jetzt Log-Rotation
-
-	// Open log file in overwrite mode
-	logFile, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-	if err != nil {
-		fmt.Printf("Error opening log file: %v\n", err)
-		return
-	}
-	defer logFile.Close() // Check if legacy logging should be disabled (multiplatform solution)
+	// Check if legacy logging should be disabled (multiplatform solution)
 	disableLegacyLogging := configuration.GetBool("Debug", "disable_legacy_logging", false)
 
 	// TEMPORARY FIX: Disable the stdout/stderr redirection that's causing server crashes
@@ -61,14 +54,21 @@ func main() { // Initialize configuration (before all other initializations)
 		// Confirmation message to terminal
 		fmt.Println("Legacy logging disabled. Using structured logging only.")
 	} else {
-		// Normal logging to file - but don't redirect stdout/stderr for now
-		log.SetOutput(logFile)
+		// Legacy log.Printf output goes to its own file, rotated like the structured log
+		legacyLog, err := logger.OpenLegacyLog()
+		if err != nil {
+			fmt.Printf("Error opening log file: %v\n", err)
+			return
+		}
+		defer legacyLog.Close()
+		log.SetOutput(legacyLog)
 
+		legacyLogPath := configuration.GetString("Debug", "legacy_log_file", "legacy.log")
 		// Confirmation message to terminal
-		fmt.Println("Log outputs are redirected to debug.log.")
+		fmt.Printf("Log outputs are redirected to %s.\n", legacyLogPath)
 		// Better startup message with timestamp (now in log file)
-		log.Printf("=== SERVER START %s ===", time.Now().Format("2006-01-02 15:04:05"))
-		log.Printf("Log redirection activated. Terminal outputs are saved in debug.log.")
+		log.Printf("=== SERVER START %s ===", startTime.Format("2006-01-02 15:04:05"))
+		log.Printf("Log redirection activated. Terminal outputs are saved in %s.", legacyLogPath)
 	}
 	// Database initialization
 	db, err := tinyos.InitDB("tinyos.db")
@@ -99,6 +99,7 @@ func main() { // Initialize configuration (before all other initializations)
 
 	// Initialize TinyOS
 	tinyOSInstance := tinyos.NewTinyOS(vfs, promptManager)
+	tinyOSInstance.SetStartTime(startTime)
 	logger.Info(logger.AreaGeneral, "TinyOS initialized: %p", tinyOSInstance)
 
 	// Create TerminalHandler without global TinyBASIC instance
@@ -123,6 +124,12 @@ func main() { // Initialize configuration (before all other initializations)
 	http.HandleFunc("/chat", handler.HandleChatWebSocket) // Chat WebSocket Route	http.HandleFunc("/cleanup-guest", handler.CleanupGuestSession) // Guest VFS cleanup endpoint
 	// API route for SID files
 	http.HandleFunc("/api/file", serveUserFile(handler))
+	// Read-only example gallery listing
+	http.HandleFunc("/api/examples", handler.HandleExamplesList)
+	// Configuration reload (loopback only)
+	http.HandleFunc("/api/admin/reload-config", handler.HandleConfigReload)
+	// Prometheus metrics, on the main server or a port of their own
+	startMetricsEndpoint(handler)
 
 	// Static file handlers for assets
 	http.HandleFunc("/floppy.mp3", serveFile("assets/floppy.mp3"))
@@ -159,6 +166,9 @@ func main() { // Initialize configuration (before all other initializations)
 			http.NotFound(w, r)
 		}
 	})
+	// Re-read settings.cfg on SIGHUP without dropping connections
+	watchConfigReloadSignal()
+
 	// Initialize TLS Manager
 	tlsManager, err := tlsmanager.NewTLSManager()
 	if err != nil {
@@ -173,6 +183,41 @@ func main() { // Initialize configuration (before all other initializations)
 	}
 }
 
+// watchConfigReloadSignal reloads the configuration whenever the process receives SIGHUP
+func watchConfigReloadSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			terminal.ReloadConfiguration("SIGHUP")
+		}
+	}()
+}
+
+// startMetricsEndpoint serves /metrics when [Metrics] enabled is set: on a
+// separate plain HTTP listener if [Metrics] port is given, otherwise next to
+// the other routes
+func startMetricsEndpoint(handler *terminal.TerminalHandler) {
+	if !configuration.GetBool("Metrics", "enabled", false) {
+		return
+	}
+	port := strings.TrimSpace(configuration.GetString("Metrics", "port", ""))
+	if port == "" {
+		http.HandleFunc("/metrics", handler.HandleMetrics)
+		logger.Info(logger.AreaGeneral, "Metrics endpoint enabled at /metrics")
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", handler.HandleMetrics)
+	go func() {
+		logger.Info(logger.AreaGeneral, "Starting metrics server on port %s", port)
+		if err := http.ListenAndServe(":"+port, mux); err != nil {
+			logger.Error(logger.AreaGeneral, "Metrics server failed: %v", err)
+		}
+	}()
+}
+
 // startHTTPServer starts the HTTP server
 func startHTTPServer(port string) {
 	logger.Info(logger.AreaGeneral, "Starting HTTP server on port %s", port)
@@ -215,7 +260,7 @@ func startTLSServers(tlsManager *tlsmanager.TLSManager) {
 		httpsServer := &http.Server{
 			Addr:      ":" + httpsPort,
 			TLSConfig: tlsManager.GetTLSConfig(),
-			Handler:   nil, // Use default mux with all registered handlers
+			Handler:   tlsManager.SecurityHeadersHandler(http.DefaultServeMux), // Default mux with all registered handlers
 		}
 
 		logger.Info(logger.AreaSecurity, "Starting HTTPS server on port %s", httpsPort)
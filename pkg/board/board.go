@@ -83,12 +83,19 @@ func NewBoardManagerWithConfig(db *sql.DB, config SecurityConfig) *BoardManager
 
 // migrateDatabase handles database schema migrations
 func (bm *BoardManager) migrateDatabase() error {
+	// A new database has no board_messages table yet; it is created with
+	// the parent_id column by InitializeDatabase
+	var tables int
+	err := bm.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'board_messages'`).Scan(&tables)
+	if err != nil || tables == 0 {
+		return nil
+	}
+	
 	// Check if parent_id column exists in board_messages table
 	query := `SELECT COUNT(*) FROM pragma_table_info('board_messages') WHERE name = 'parent_id'`
 	var count int
-	err := bm.db.QueryRow(query).Scan(&count)
+	err = bm.db.QueryRow(query).Scan(&count)
 	if err != nil {
-		// Table might not exist yet, which is fine
 		return nil
 	}
 	
@@ -671,7 +678,7 @@ func (bm *BoardManager) FormatCategoryList(categories []BoardCategory, terminalW
 	
 	lines = append(lines, createFrameBorder("bottom"))
 	lines = append(lines, "")
-	lines = append(lines, "Enter category number to view messages, or 'q' to quit.")
+	lines = append(lines, "Enter category number to view messages, 'who' to see who is online, or 'q' to quit.")
 	lines = append(lines, "")
 	
 	return lines
@@ -790,8 +797,52 @@ func (bm *BoardManager) FormatMessage(message BoardMessage, terminalWidth int) [
 	return lines
 }
 
+// BoardPresence describes a user currently connected to the board
+type BoardPresence struct {
+	Handle string
+	Idle   time.Duration
+}
+
+// FormatPresenceList formats the list of users currently on the board
+func (bm *BoardManager) FormatPresenceList(users []BoardPresence) []string {
+	lines := []string{}
+	lines = append(lines, "")
+	lines = append(lines, createFrameBorder("top"))
+	lines = append(lines, formatFrameLine(centerPad("Who is online", CONTENT_WIDTH)))
+	lines = append(lines, createFrameBorder("middle"))
+	
+	if len(users) == 0 {
+		lines = append(lines, formatFrameLine("Nobody else is visible on the board right now."))
+	} else {
+		lines = append(lines, formatFrameLine(fmt.Sprintf("%-20s %s", "Handle", "Idle")))
+		for _, user := range users {
+			lines = append(lines, formatFrameLine(fmt.Sprintf("%-20s %s",
+				truncateString(user.Handle, 20), formatIdle(user.Idle))))
+		}
+	}
+	
+	lines = append(lines, createFrameBorder("bottom"))
+	lines = append(lines, "")
+	lines = append(lines, "Use 'who hide' to leave this list or 'who show' to appear again.")
+	lines = append(lines, "")
+	
+	return lines
+}
+
 // Helper functions
 
+// formatIdle formats an idle duration in a compact form (e.g. "5m", "1h12m")
+func formatIdle(idle time.Duration) string {
+	switch {
+	case idle < time.Minute:
+		return "active"
+	case idle < time.Hour:
+		return fmt.Sprintf("%dm", int(idle.Minutes()))
+	default:
+		return fmt.Sprintf("%dh%02dm", int(idle.Hours()), int(idle.Minutes())%60)
+	}
+}
+
 // centerPad centers text within a given width
 func centerPad(text string, width int) string {
 	if len(text) >= width {
@@ -512,6 +512,26 @@ func (ui *BoardUI) IsViewingMessage() bool {
 	return ui.state == BoardStateViewMessage
 }
 
+// IsComposing returns true while a new message or reply is being written
+func (ui *BoardUI) IsComposing() bool {
+	switch ui.state {
+	case BoardStateNewMessage, BoardStateNewMessageContent, BoardStateNewMessageConfirm,
+		BoardStateNewReply, BoardStateNewReplyContent, BoardStateNewReplyConfirm:
+		return true
+	}
+	return false
+}
+
+// Username returns the name the board session posts under
+func (ui *BoardUI) Username() string {
+	return ui.username
+}
+
+// IsGuest returns true if the board session belongs to a guest
+func (ui *BoardUI) IsGuest() bool {
+	return ui.isGuest
+}
+
 // IsActive returns true if the board UI is still active
 func (ui *BoardUI) IsActive() bool {
 	return ui.state != BoardStateCategories // Could be enhanced with quit state
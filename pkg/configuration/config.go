@@ -165,12 +165,17 @@ func (c *Config) createDefaultConfig() {
 		"max_message_length":              "1000",
 		"max_sessions_per_user":           "3",
 		"max_sessions_per_ip":             "5",
+		"max_connections_per_ip":          "5",
+		"trusted_ips":                     "127.0.0.1,::1",
+		"trusted_proxies":                 "",
+		"sudo_timeout":                    "5m",
 		"rate_limit_messages":             "60",
 		"rate_limit_bandwidth":            "10240",
 		"rate_limit_window":               "1m",
 		"enable_sql_injection_filter":     "true",
 		"enable_xss_filter":               "true",
 		"enable_command_injection_filter": "true",
+		"session_timeout":                 "24h",
 	}
 
 	// [Authentication] Sektion
@@ -182,6 +187,12 @@ func (c *Config) createDefaultConfig() {
 		"session_token_length": "32",
 		"password_hash_cost":   "12",
 		"enable_guest_access":  "true",
+
+		"max_failed_login_attempts":      "5",
+		"login_lockout_duration_seconds": "30",
+		"login_lockout_multiplier":       "2.0",
+		"login_lockout_max_seconds":      "3600",
+		"transfer_code_ttl":              "2m",
 	}
 
 	// [ChatRateLimit] Sektion
@@ -210,10 +221,13 @@ func (c *Config) createDefaultConfig() {
 		"max_directories_per_user": "20",
 		"max_files_per_directory":  "100",
 		"max_file_size_kb":         "1024",
+		"max_path_depth":           "8",
+		"max_entries_per_user":     "1000",
 		"user_quota_kb":            "10240",
 		"enable_guest_persistence": "false",
 		"backup_interval":          "1h",
 		"enable_file_compression":  "false",
+		"examples_cache_ttl":       "5m",
 	}
 
 	// [Terminal] Sektion
@@ -221,6 +235,12 @@ func (c *Config) createDefaultConfig() {
 		"max_session_requests_per_minute": "3",
 		"session_request_time_window":     "1m",
 		"ip_ban_duration":                 "24h",
+		"default_theme":                   "green",
+		"command_timeout":                 "60s",
+		"max_output_messages_per_second":  "2000",
+		"telnet_clear_screen":             "true",
+		"telnet_restore_lines":            "200",
+		"guest_basic_idle_timeout":        "15m",
 	}
 
 	// [Editor] Sektion
@@ -228,14 +248,47 @@ func (c *Config) createDefaultConfig() {
 		"max_lines": "5000",
 	}
 
+	// [Mail] Sektion
+	c.settings["Mail"] = map[string]string{
+		"max_messages_per_hour": "10",
+		"max_subject_length":    "60",
+		"max_body_length":       "4000",
+		"max_mailbox_size":      "200",
+	}
+
+	// [Fetch] Sektion
+	c.settings["Fetch"] = map[string]string{
+		"max_size_kb":          "64",
+		"timeout":              "10s",
+		"max_fetches_per_hour": "20",
+		"allowed_hosts":        "",
+	}
+
+	// [BasicPrograms] Sektion
+	c.settings["BasicPrograms"] = map[string]string{
+		"print_zone_width":     "14",
+		"max_file_line_length": "8192",
+		"max_steps_guest":      "100000000",
+		"max_steps_user":       "1000000000",
+	}
+
 	// [Network] Sektion
 	c.settings["Network"] = map[string]string{
-		"pong_timeout":            "90s",
-		"write_wait_timeout":      "10s",
-		"max_message_size_kb":     "64",
-		"max_messages_per_second": "50",
-		"max_channel_buffer":      "10000",
-		"client_timeout":          "30s",
+		"pong_timeout":                 "90s",
+		"write_wait_timeout":           "10s",
+		"ping_interval":                "",
+		"max_message_size_kb":          "64",
+		"max_messages_per_second":      "50",
+		"max_channel_buffer":           "10000",
+		"client_timeout":               "30s",
+		"examples_requests_per_minute": "30",
+	}
+
+	// [WebSocket] Sektion
+	c.settings["WebSocket"] = map[string]string{
+		"read_buffer_size":  "16384",
+		"write_buffer_size": "16384",
+		"allowed_origins":   "http://localhost:8080,http://127.0.0.1:8080",
 	}
 	// [Debug] Sektion
 	c.settings["Debug"] = map[string]string{
@@ -244,6 +297,8 @@ func (c *Config) createDefaultConfig() {
 		"log_file":                      "debug.log",
 		"max_log_size_mb":               "10",
 		"log_rotation_count":            "3",
+		"log_rotation_interval":         "0",
+		"legacy_log_file":               "legacy.log",
 		"enable_performance_monitoring": "false",
 		"enable_request_logging":        "false",
 		// Selektive Logging-Bereiche
@@ -284,7 +339,7 @@ func (c *Config) saveToFile() error {
 	file.WriteString(";\n\n")
 
 	// Schreibe alle Sektionen in einer definierten Reihenfolge
-	sections := []string{"System", "Security", "Authentication", "ChatRateLimit", "BanSystem", "FileSystem", "Terminal", "Editor", "Network", "Debug"}
+	sections := []string{"System", "Security", "Authentication", "ChatRateLimit", "BanSystem", "FileSystem", "Terminal", "Editor", "BasicPrograms", "Mail", "Fetch", "Network", "WebSocket", "Debug"}
 
 	for _, section := range sections {
 		if settings, exists := c.settings[section]; exists {
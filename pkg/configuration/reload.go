@@ -0,0 +1,89 @@
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// restartRequiredKeys enthält Einstellungen, die nur beim Start gelesen werden.
+// "Sektion.*" steht für alle Schlüssel einer Sektion. Alle anderen Werte werden
+// bei jeder Verwendung über GetInt/GetDuration/... gelesen und sind nach einem
+// Reload sofort aktiv.
+var restartRequiredKeys = []string{
+	"System.*",                    // Ressourcenmanager wird beim Start dimensioniert
+	"TLS.*",                       // Listener und Zertifikate
+	"Debug.*",                     // Logger und Log-Datei
+	"WebSocket.read_buffer_size",  // Upgrader wird beim Start erzeugt
+	"WebSocket.write_buffer_size", // Upgrader wird beim Start erzeugt
+	"Metrics.enabled",             // Route und Listener werden beim Start eingerichtet
+	"Metrics.port",                // Route und Listener werden beim Start eingerichtet
+}
+
+// RequiresRestart gibt an, ob eine Änderung an Sektion/Schlüssel erst nach einem Neustart wirkt
+func RequiresRestart(section, key string) bool {
+	for _, entry := range restartRequiredKeys {
+		if entry == section+".*" || entry == section+"."+key {
+			return true
+		}
+	}
+	return false
+}
+
+// Reload liest die Konfigurationsdatei (und settings.local.cfg) neu ein und ersetzt
+// die aktiven Werte atomar. Zurückgegeben werden die geänderten Schlüssel als
+// "Sektion.schlüssel", sortiert. Bei einem Fehler bleibt die alte Konfiguration aktiv.
+func Reload() ([]string, error) {
+	if globalConfig == nil {
+		return nil, fmt.Errorf("configuration not initialized")
+	}
+
+	fresh, err := loadConfig(globalConfig.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload %s: %v", globalConfig.filePath, err)
+	}
+	localConfigPath := "settings.local.cfg"
+	if _, err := os.Stat(localConfigPath); err == nil {
+		if err := fresh.loadLocalConfig(localConfigPath); err != nil {
+			return nil, fmt.Errorf("failed to reload %s: %v", localConfigPath, err)
+		}
+	}
+
+	globalConfig.mu.Lock()
+	defer globalConfig.mu.Unlock()
+
+	changed := diffSettings(globalConfig.settings, fresh.settings)
+	globalConfig.settings = fresh.settings
+	return changed, nil
+}
+
+// diffSettings liefert alle Schlüssel, die hinzugekommen, entfernt oder geändert sind
+func diffSettings(old, new map[string]map[string]string) []string {
+	seen := make(map[string]bool)
+	var changed []string
+	collect := func(a, b map[string]map[string]string) {
+		for section, values := range a {
+			for key, value := range values {
+				name := section + "." + key
+				if seen[name] {
+					continue
+				}
+				if other, ok := b[section][key]; !ok || other != value {
+					seen[name] = true
+					changed = append(changed, name)
+				}
+			}
+		}
+	}
+	collect(old, new)
+	collect(new, old)
+	sort.Strings(changed)
+	return changed
+}
+
+// SplitKey zerlegt einen von Reload gelieferten Namen in Sektion und Schlüssel
+func SplitKey(name string) (section, key string) {
+	section, key, _ = strings.Cut(name, ".")
+	return section, key
+}
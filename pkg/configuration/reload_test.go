@@ -0,0 +1,108 @@
+package configuration
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeConfigFile schreibt eine Konfigurationsdatei in ein temporäres Verzeichnis
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+// TestReloadReportsChangedKeys lädt eine geänderte Datei neu und prüft die
+// gemeldeten Schlüssel und die danach aktiven Werte
+func TestReloadReportsChangedKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.cfg")
+	writeConfigFile(t, path, "[Security]\nmax_sessions_per_ip = 5\nsudo_timeout = 5m\n[Mail]\nmax_mails_per_hour = 10\n")
+
+	loaded, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	previous := globalConfig
+	globalConfig = loaded
+	t.Cleanup(func() { globalConfig = previous })
+
+	writeConfigFile(t, path, "[Security]\nmax_sessions_per_ip = 8\nsudo_timeout = 5m\n[Network]\nport = 8080\n")
+	changed, err := Reload()
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	want := []string{"Mail.max_mails_per_hour", "Network.port", "Security.max_sessions_per_ip"}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("Reload() = %v, want %v", changed, want)
+	}
+	if got := GetInt("Security", "max_sessions_per_ip", 0); got != 8 {
+		t.Errorf("max_sessions_per_ip after reload = %d, want 8", got)
+	}
+	if got := GetInt("Mail", "max_mails_per_hour", -1); got != -1 {
+		t.Errorf("removed key still has the value %d", got)
+	}
+}
+
+// TestReloadKeepsConfigOnError prüft, dass eine unlesbare Datei die aktive
+// Konfiguration nicht ersetzt
+func TestReloadKeepsConfigOnError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.cfg")
+	writeConfigFile(t, path, "[Security]\nmax_sessions_per_ip = 5\n")
+
+	loaded, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	previous := globalConfig
+	globalConfig = loaded
+	t.Cleanup(func() { globalConfig = previous })
+
+	// Ein Verzeichnis an Stelle der Datei lässt sich nicht lesen
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(path, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Reload(); err == nil {
+		t.Error("Reload of an unreadable file succeeded")
+	}
+	if got := GetInt("Security", "max_sessions_per_ip", 0); got != 5 {
+		t.Errorf("max_sessions_per_ip after failed reload = %d, want 5", got)
+	}
+}
+
+// TestRequiresRestart prüft ganze Sektionen und einzelne Schlüssel
+func TestRequiresRestart(t *testing.T) {
+	for _, tc := range []struct {
+		section, key string
+		want         bool
+	}{
+		{"System", "max_concurrent_users", true},
+		{"TLS", "cert_file", true},
+		{"WebSocket", "read_buffer_size", true},
+		{"WebSocket", "max_message_size", false},
+		{"Metrics", "port", true},
+		{"Metrics", "token", false},
+		{"Security", "max_sessions_per_ip", false},
+		{"Authentication", "login_lockout_multiplier", false},
+	} {
+		if got := RequiresRestart(tc.section, tc.key); got != tc.want {
+			t.Errorf("RequiresRestart(%q, %q) = %v, want %v", tc.section, tc.key, got, tc.want)
+		}
+	}
+}
+
+// TestSplitKey prüft das Zerlegen der von Reload gelieferten Namen
+func TestSplitKey(t *testing.T) {
+	if section, key := SplitKey("Security.max_sessions_per_ip"); section != "Security" || key != "max_sessions_per_ip" {
+		t.Errorf("SplitKey = %q, %q", section, key)
+	}
+	if section, key := SplitKey("Security"); section != "Security" || key != "" {
+		t.Errorf("SplitKey without key = %q, %q", section, key)
+	}
+}
@@ -17,13 +17,15 @@ import (
 
 // EditorManager manages active editor sessions
 type EditorManager struct {
-	editors map[string]*Editor // sessionID -> Editor
-	mu      sync.RWMutex
+	editors   map[string]*Editor // sessionID -> Editor
+	suspended map[string]*Editor // sessionID -> Editor waiting for a save & run to finish
+	mu        sync.RWMutex
 }
 
 // Global editor manager instance
 var globalEditorManager = &EditorManager{
-	editors: make(map[string]*Editor),
+	editors:   make(map[string]*Editor),
+	suspended: make(map[string]*Editor),
 }
 
 // GetEditorManager returns the global editor manager instance
@@ -97,6 +99,57 @@ func (em *EditorManager) CloseEditor(sessionID string) {
 	}
 }
 
+// SuspendEditor parks the editor of a session while its program runs (save & run).
+// The editor keeps its content, cursor and output channel.
+func (em *EditorManager) SuspendEditor(sessionID string) *Editor {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	editor, exists := em.editors[sessionID]
+	if !exists {
+		return nil
+	}
+	delete(em.editors, sessionID)
+	em.suspended[sessionID] = editor
+	logger.Info(logger.AreaEditor, "Suspended editor for session %s (%s)", sessionID, editor.filename)
+	return editor
+}
+
+// ResumeEditor reactivates a suspended editor and redraws it.
+// lineNumber and message describe the runtime error that ended the program.
+func (em *EditorManager) ResumeEditor(sessionID string, lineNumber int, message string) *Editor {
+	em.mu.Lock()
+	editor, exists := em.suspended[sessionID]
+	if !exists {
+		em.mu.Unlock()
+		return nil
+	}
+	delete(em.suspended, sessionID)
+	if existing, ok := em.editors[sessionID]; ok {
+		existing.Close()
+	}
+	em.editors[sessionID] = editor
+	em.mu.Unlock()
+
+	editor.ShowRunError(lineNumber, message)
+	logger.Info(logger.AreaEditor, "Resuming editor for session %s after error in line %d", sessionID, lineNumber)
+	editor.Start()
+	return editor
+}
+
+// DiscardSuspendedEditor drops a suspended editor, e.g. after its program ended cleanly
+func (em *EditorManager) DiscardSuspendedEditor(sessionID string) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	if editor, exists := em.suspended[sessionID]; exists {
+		delete(em.suspended, sessionID)
+		// The editor is already inactive, so Close would skip it - close the channel
+		// directly to stop the message forwarder
+		close(editor.outputChan)
+	}
+}
+
 // ProcessEditorInput processes input for an active editor session
 func (em *EditorManager) ProcessEditorInput(sessionID string, input string) bool {
 	em.mu.RLock()
@@ -142,6 +195,12 @@ type Editor struct {
 	requestingFilename bool   // Whether currently requesting filename input
 	filenameInput      string // Current filename being typed
 	exitAfterSave      bool   // Whether to exit after successful save
+	runAfterSave       bool   // Whether to run the file after successful save (CTRL+R)
+
+	// Save & run state
+	runRequested bool   // Editor was left to run the saved file
+	runErrorRow  int    // Editor row of the last runtime error (-1 if none)
+	runErrorNote string // Error shown in the status line after returning from a run
 
 	// Channel to wait for frontend ready signal
 	readyChan chan bool
@@ -186,6 +245,7 @@ func NewEditor(config EditorConfig) *Editor {
 		vfs:          config.VFS,
 		active:       true,
 		lastActivity: time.Now(),
+		runErrorRow:  -1,
 	}
 
 	// Initialize wrapped lines immediately - essential for ReadOnly mode
@@ -272,6 +332,7 @@ func (e *Editor) ProcessInput(input string) bool {
 
 	logger.Debug(logger.AreaEditor, "ProcessInput called with input: %q, showingExitWarning: %v, readOnly: %v", input, e.showingExitWarning, e.readOnly)
 	e.lastActivity = time.Now()
+	e.clearRunError() // The error marker only lasts until the next key press
 
 	// DEBUG: Log current editor state
 	logger.Debug(logger.AreaEditor, "Editor state: readOnly=%v, scrollY=%d, totalLines=%d, textRows=%d", e.readOnly, e.scrollY, len(e.lines), e.textRows)
@@ -427,6 +488,9 @@ func (e *Editor) ProcessEditorMessage(command, data string) bool {
 
 	logger.Debug(logger.AreaEditor, "ProcessEditorMessage: command=%s, data=%s, readOnly=%v, showingExitWarning=%v, requestingFilename=%v", command, data, e.readOnly, e.showingExitWarning, e.requestingFilename)
 	e.lastActivity = time.Now() // Block all editing commands in read-only mode (except exit commands)
+	if command != "ready" {
+		e.clearRunError()
+	}
 	if e.readOnly {
 		switch command {
 		case "exit":
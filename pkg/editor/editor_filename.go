@@ -17,6 +17,7 @@ func (e *Editor) handleFilenameInput(input string) bool {
 		e.requestingFilename = false
 		e.filenameInput = ""
 		e.exitAfterSave = false
+		e.runAfterSave = false
 		e.sendStatusMessage("Save cancelled")
 		e.Render()
 		return true
@@ -48,6 +49,12 @@ func (e *Editor) handleFilenameInput(input string) bool {
 				log.Printf("[EDITOR-BACKEND] File saved successfully: %s", e.filename)
 				e.sendStatusMessage("File saved: " + e.filename)
 
+				// Save & run was requested before the filename was known
+				if e.runAfterSave {
+					e.runAfterSave = false
+					return e.requestRun()
+				}
+
 				// If we should exit after save (user pressed Ctrl+X then Ctrl+S)
 				if e.exitAfterSave {
 					e.exitAfterSave = false
@@ -114,6 +121,12 @@ func (e *Editor) handleFilenameSubmit(data string) bool {
 	log.Printf("[EDITOR-BACKEND] File saved successfully: %s", e.filename)
 	e.sendStatusMessage("File saved: " + e.filename)
 
+	// Save & run was requested before the filename was known
+	if e.runAfterSave {
+		e.runAfterSave = false
+		return e.requestRun()
+	}
+
 	// If we were exiting before the save, exit now
 	if e.exitAfterSave {
 
@@ -138,6 +151,7 @@ func (e *Editor) handleFilenameCancel() bool {
 	e.requestingFilename = false
 	e.filenameInput = ""
 	e.exitAfterSave = false
+	e.runAfterSave = false
 
 	// Send command to frontend to exit filename input mode
 	e.sendEditorMessage("filename_input_complete", nil)
@@ -39,6 +39,12 @@ func cleanCodeForLoading(content string) string {
 	return strings.Join(lines, "\n")
 }
 
+// CleanCodeForLoading applies the editor's save-time cleanup to content that
+// reaches the VFS by other means, such as downloads
+func CleanCodeForLoading(content string) string {
+	return cleanCodeForLoading(content)
+}
+
 // normalizeBASICLine converts BASIC commands and variables to uppercase while preserving string literals
 func normalizeBASICLine(line string) string {
 	if strings.TrimSpace(line) == "" {
@@ -24,6 +24,10 @@ func (e *Editor) handleControlKey(key string) bool {
 			e.sendStatusMessage("Read-only mode - cannot save")
 			return true
 		}
+	case "r":
+		// Save and run the BASIC program
+		logger.Info(logger.AreaEditor, "handleControlKey: Save & run requested (CTRL+R)")
+		return e.handleSaveAndRun()
 	case "x":
 		// Exit editor
 		logger.Info(logger.AreaEditor, "handleControlKey: Exit requested (CTRL+X)")
@@ -120,6 +120,7 @@ func (e *Editor) GetRenderParams() map[string]interface{} {
 		"showingExitWarning": e.showingExitWarning,
 		"renderTimestamp":    time.Now().UnixMilli(), // For debugging
 		"mappingSuccess":     visibleCursorLine >= 0, // Flag for frontend
+		"highlightLine":      e.visibleRunErrorRow(), // Screen row of the last runtime error (-1 if none)
 	}
 	return renderParams
 }
@@ -137,6 +138,16 @@ func (e *Editor) buildStatusLine() string {
 		}
 		return warning
 	}
+	// After a failed save & run, show the error until the next key press
+	if e.runErrorNote != "" {
+		note := "ERROR " + e.runErrorNote
+		if len(note) > e.cols {
+			note = note[:e.cols]
+		} else if len(note) < e.cols {
+			note += strings.Repeat(" ", e.cols-len(note))
+		}
+		return note
+	}
 	// Normal status line
 	filename := e.filename
 	if filename == "" {
@@ -154,7 +165,7 @@ func (e *Editor) buildStatusLine() string {
 	// Display wrapped line position (1-based for user display)
 	lineInfo := fmt.Sprintf("L%d C%d", wrappedLineIdx+1, wrappedCol+1)
 	// Key commands
-	commands := "^S:Save ^X:Exit"
+	commands := "^S:Save ^R:Run ^X:Exit"
 	if e.readOnly {
 		commands = "^X:Exit (READ-ONLY)"
 	}
@@ -0,0 +1,158 @@
+package editor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+)
+
+// This file contains the "save and run" workflow (CTRL+R) of the editor
+
+// handleSaveAndRun saves the buffer and asks the terminal to run it.
+// Returns false when the editor hands over to the BASIC run.
+func (e *Editor) handleSaveAndRun() bool {
+	if e.readOnly {
+		e.sendStatusMessage("Read-only mode - cannot save")
+		return true
+	}
+
+	if e.filename == "" || e.filename == "<current program>" || e.filename == "<new file>" {
+		// Ask for a filename first, the run starts after the save
+		e.requestingFilename = true
+		e.filenameInput = ""
+		e.runAfterSave = true
+		e.sendEditorMessage("filename_input", map[string]interface{}{
+			"prompt": "Save as: ",
+		})
+		return true
+	}
+
+	if !isBASICFilename(e.filename) {
+		e.sendStatusMessage("Save & run only works with .bas files")
+		return true
+	}
+
+	e.sendStatusMessage("Saving file...")
+	done := make(chan error, 1)
+	go func() {
+		done <- e.SaveFile()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			e.sendStatusMessage("Error saving: " + err.Error())
+			return true
+		}
+	case <-time.After(5 * time.Second):
+		e.sendStatusMessage("Save timeout - try again")
+		return true
+	}
+
+	return e.requestRun()
+}
+
+// requestRun marks the saved file for execution and hides the editor.
+// Unlike Close, the output channel stays open so the editor can be resumed.
+func (e *Editor) requestRun() bool {
+	if !isBASICFilename(e.filename) {
+		e.sendStatusMessage("File saved: " + e.filename + " (not a .bas file, not run)")
+		e.Render()
+		return true
+	}
+
+	logger.Info(logger.AreaEditor, "Save & run requested for %s (session %s)", e.filename, e.sessionID)
+	e.runRequested = true
+	e.showingExitWarning = false
+	e.clearRunError()
+	e.active = false
+	e.sendEditorMessage("stop", nil)
+	return false
+}
+
+// TakeRunRequest reports whether the editor was left via save & run and resets the flag
+func (e *Editor) TakeRunRequest() bool {
+	requested := e.runRequested
+	e.runRequested = false
+	return requested
+}
+
+// ShowRunError moves the cursor to the BASIC line that caused a runtime error
+// and shows the error in the status line until the next key press.
+func (e *Editor) ShowRunError(lineNumber int, message string) {
+	e.runErrorNote = message
+	if lineNumber > 0 {
+		e.runErrorNote = fmt.Sprintf("Line %d: %s", lineNumber, message)
+	}
+
+	e.runErrorRow = e.findBASICLine(lineNumber)
+	if e.runErrorRow >= 0 {
+		e.cursorY = e.runErrorRow
+		e.cursorX = 0
+		e.updateWrappedLines()
+		e.adjustScroll()
+	}
+}
+
+// clearRunError removes the error marker of the last run
+func (e *Editor) clearRunError() {
+	e.runErrorRow = -1
+	e.runErrorNote = ""
+}
+
+// findBASICLine returns the editor row that holds the given BASIC line number.
+// Rows are matched by their leading line number, not by their position, since
+// BASIC line numbers rarely correspond to editor rows. Returns -1 if not found.
+func (e *Editor) findBASICLine(lineNumber int) int {
+	if lineNumber <= 0 {
+		return -1
+	}
+	for row, line := range e.lines {
+		if num, ok := basicLineNumber(line); ok && num == lineNumber {
+			return row
+		}
+	}
+	return -1
+}
+
+// visibleRunErrorRow returns the screen row of the error line, or -1 if none is visible
+func (e *Editor) visibleRunErrorRow() int {
+	if e.runErrorRow < 0 {
+		return -1
+	}
+	for wrappedIdx, logical := range e.lineMapping {
+		if logical == e.runErrorRow {
+			if row := wrappedIdx - e.scrollY; row >= 0 && row < e.textRows {
+				return row
+			}
+			return -1
+		}
+	}
+	return -1
+}
+
+// basicLineNumber parses the line number at the start of a BASIC program line
+func basicLineNumber(line string) (int, bool) {
+	line = strings.TrimSpace(line)
+	end := 0
+	for end < len(line) && line[end] >= '0' && line[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return 0, false
+	}
+	num, err := strconv.Atoi(line[:end])
+	if err != nil {
+		return 0, false
+	}
+	return num, true
+}
+
+// isBASICFilename reports whether the file can be run by TinyBASIC
+func isBASICFilename(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".bas")
+}
@@ -2,8 +2,8 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"log"
-	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -56,15 +56,14 @@ const (
 
 // Logger ist das Hauptlogging-System
 type Logger struct {
-	enabled       int32              // atomic bool - performance critical
-	level         int32              // atomic LogLevel
-	areaEnabled   map[LogArea]*int32 // atomic bools per area
-	file          *os.File
-	mutex         sync.RWMutex
-	logPath       string
-	maxSizeMB     int64
-	rotationCount int
-	currentSize   int64
+	enabled     int32              // atomic bool - performance critical
+	level       int32              // atomic LogLevel
+	areaEnabled map[LogArea]*int32 // atomic bools per area
+	file        *rotatingFile      // Strukturiertes Log (log_file)
+	legacyFile  *rotatingFile      // Ziel des Standard-log-Pakets (legacy_log_file)
+	mutex       sync.RWMutex
+	logPath     string
+	rotation    rotationConfig
 }
 
 var (
@@ -121,9 +120,18 @@ func (l *Logger) loadConfig() error {
 	level := parseLogLevel(levelStr)
 	atomic.StoreInt32(&l.level, int32(level))
 
-	l.logPath = configuration.GetString("Debug", "log_file", "debug.log")
-	l.maxSizeMB = int64(configuration.GetInt("Debug", "max_log_size_mb", 10))
-	l.rotationCount = configuration.GetInt("Debug", "log_rotation_count", 3)
+	l.mutex.Lock()
+	if l.file == nil {
+		// Der Pfad wird nur beim Start gelesen, die Rotation auch bei ReloadConfig
+		l.logPath = configuration.GetString("Debug", "log_file", "debug.log")
+	}
+	l.rotation = loadRotationConfig()
+	for _, f := range []*rotatingFile{l.file, l.legacyFile} {
+		if f != nil {
+			f.setConfig(l.rotation)
+		}
+	}
+	l.mutex.Unlock()
 
 	// Bereichs-spezifische Konfiguration
 	for area, atomicBool := range l.areaEnabled {
@@ -145,63 +153,11 @@ func (l *Logger) openLogFile() error {
 		l.file.Close()
 	}
 
-	// Erstelle Verzeichnis falls nötig
-	dir := filepath.Dir(l.logPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-
-	// Öffne neue Datei
-	file, err := os.OpenFile(l.logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		return err
-	}
-
-	l.file = file
-
-	// Aktuelle Dateigröße ermitteln
-	if stat, err := file.Stat(); err == nil {
-		l.currentSize = stat.Size()
-	}
-
-	return nil
-}
-
-// rotateLogFile rotiert die Log-Datei wenn sie zu groß wird
-func (l *Logger) rotateLogFile() error {
-	l.mutex.Lock()
-	defer l.mutex.Unlock()
-
-	if l.file != nil {
-		l.file.Close()
-		l.file = nil
-	}
-
-	// Rotiere bestehende Dateien
-	for i := l.rotationCount - 1; i >= 1; i-- {
-		oldName := fmt.Sprintf("%s.%d", l.logPath, i)
-		newName := fmt.Sprintf("%s.%d", l.logPath, i+1)
-
-		if i == l.rotationCount-1 {
-			// Lösche älteste Datei
-			os.Remove(newName)
-		}
-
-		os.Rename(oldName, newName)
-	}
-
-	// Verschiebe aktuelle Datei zu .1
-	os.Rename(l.logPath, l.logPath+".1")
-
-	// Öffne neue Datei
-	file, err := os.OpenFile(l.logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	file, err := openRotatingFile(l.logPath, l.rotation)
 	if err != nil {
 		return err
 	}
-
 	l.file = file
-	l.currentSize = 0
-
 	return nil
 }
 
@@ -251,20 +207,14 @@ func (l *Logger) writeLog(level LogLevel, area LogArea, format string, args ...i
 		strings.ToUpper(string(area)),
 		message)
 
-	// Schreibe in Datei
-	l.mutex.Lock()
-	defer l.mutex.Unlock()
+	// Schreibe in Datei, die Rotation übernimmt rotatingFile
+	l.mutex.RLock()
+	out := l.file
+	l.mutex.RUnlock()
 
-	if l.file != nil {
-		n, err := l.file.WriteString(logEntry)
-		if err == nil {
-			l.currentSize += int64(n)
-			l.file.Sync() // Sofort auf Disk schreiben
-
-			// Prüfe ob Rotation nötig ist
-			if l.currentSize > l.maxSizeMB*1024*1024 {
-				l.rotateLogFile()
-			}
+	if out != nil {
+		if _, err := out.Write([]byte(logEntry)); err == nil {
+			out.Sync() // Sofort auf Disk schreiben
 		}
 	}
 
@@ -412,6 +362,32 @@ func parseLogLevel(level string) LogLevel {
 	}
 }
 
+// OpenLegacyLog öffnet die Datei für das Standard-log-Paket (legacy_log_file).
+// Sie wird wie das strukturierte Log rotiert, ist aber eine eigene Datei, damit
+// sich beide nicht gegenseitig abschneiden oder wegrotieren.
+func OpenLegacyLog() (io.WriteCloser, error) {
+	if globalLogger == nil {
+		return nil, fmt.Errorf("logger not initialized")
+	}
+	l := globalLogger
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	path := configuration.GetString("Debug", "legacy_log_file", "legacy.log")
+	if filepath.Clean(path) == filepath.Clean(l.logPath) {
+		return nil, fmt.Errorf("legacy_log_file and log_file must be different files (both are %s)", path)
+	}
+	if l.legacyFile != nil {
+		l.legacyFile.Close()
+	}
+	file, err := openRotatingFile(path, l.rotation)
+	if err != nil {
+		return nil, err
+	}
+	l.legacyFile = file
+	return file, nil
+}
+
 // Close schließt das Logging-System
 func Close() {
 	if globalLogger != nil {
@@ -422,5 +398,9 @@ func Close() {
 			globalLogger.file.Close()
 			globalLogger.file = nil
 		}
+		if globalLogger.legacyFile != nil {
+			globalLogger.legacyFile.Close()
+			globalLogger.legacyFile = nil
+		}
 	}
 }
@@ -0,0 +1,159 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+)
+
+// rotationConfig beschreibt, wann eine Log-Datei rotiert wird und wie viele
+// alte Dateien (datei.1 ... datei.N) erhalten bleiben
+type rotationConfig struct {
+	maxSize  int64         // Rotation ab dieser Größe in Bytes, 0 = nie
+	interval time.Duration // Rotation nach dieser Zeit, 0 = nie
+	keep     int           // Anzahl aufbewahrter alter Dateien
+}
+
+// loadRotationConfig liest die Rotationsparameter aus der [Debug]-Sektion.
+// Strukturiertes und Legacy-Log verwenden dieselben Werte.
+func loadRotationConfig() rotationConfig {
+	rc := rotationConfig{
+		maxSize:  int64(configuration.GetInt("Debug", "max_log_size_mb", 10)) * 1024 * 1024,
+		interval: configuration.GetDuration("Debug", "log_rotation_interval", 0),
+		keep:     configuration.GetInt("Debug", "log_rotation_count", 3),
+	}
+	if rc.maxSize < 0 {
+		rc.maxSize = 0
+	}
+	if rc.interval < 0 {
+		rc.interval = 0
+	}
+	if rc.keep < 0 {
+		rc.keep = 0
+	}
+	return rc
+}
+
+// rotatingFile ist eine Log-Datei, die sich beim Schreiben selbst rotiert.
+// Alle Methoden sind nebenläufig sicher; ein Eintrag wird nie auf zwei
+// Dateien verteilt.
+type rotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	config  rotationConfig
+	file    *os.File
+	size    int64
+	started time.Time // Beginn der aktuellen Datei für die zeitbasierte Rotation
+}
+
+// openRotatingFile öffnet path zum Anhängen. Eine bestehende Datei wird
+// fortgesetzt; für die zeitbasierte Rotation zählt ihr letzter Schreibzugriff.
+func openRotatingFile(path string, config rotationConfig) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	rf := &rotatingFile{path: path, config: config, file: file, started: time.Now()}
+	if stat, err := file.Stat(); err == nil && stat.Size() > 0 {
+		rf.size = stat.Size()
+		rf.started = stat.ModTime()
+	}
+	return rf, nil
+}
+
+// Write hängt p an und rotiert vorher, wenn die Datei zu groß oder zu alt ist
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		return 0, os.ErrClosed
+	}
+	if rf.needsRotation(int64(len(p))) {
+		if err := rf.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "log rotation of %s failed: %v\n", rf.path, err)
+			if rf.file == nil {
+				return 0, err
+			}
+		}
+	}
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// needsRotation prüft, ob vor dem Schreiben von n Bytes rotiert werden muss.
+// Eine leere Datei wird nie rotiert, auch wenn ein Eintrag allein zu groß ist.
+func (rf *rotatingFile) needsRotation(n int64) bool {
+	if rf.size == 0 {
+		return false
+	}
+	if rf.config.maxSize > 0 && rf.size+n > rf.config.maxSize {
+		return true
+	}
+	return rf.config.interval > 0 && time.Since(rf.started) >= rf.config.interval
+}
+
+// rotate schiebt datei.1 ... datei.N-1 eins weiter, löscht die älteste Datei
+// und beginnt eine neue. Muss mit rf.mu aufgerufen werden.
+func (rf *rotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return err
+	}
+	rf.file = nil
+
+	if rf.config.keep == 0 {
+		os.Remove(rf.path)
+	} else {
+		os.Remove(fmt.Sprintf("%s.%d", rf.path, rf.config.keep))
+		for i := rf.config.keep - 1; i >= 1; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", rf.path, i), fmt.Sprintf("%s.%d", rf.path, i+1))
+		}
+		os.Rename(rf.path, rf.path+".1")
+	}
+
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	rf.file = file
+	rf.size = 0
+	rf.started = time.Now()
+	return nil
+}
+
+// setConfig übernimmt neue Rotationsparameter, z.B. nach ReloadConfig
+func (rf *rotatingFile) setConfig(config rotationConfig) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	rf.config = config
+}
+
+// Sync schreibt gepufferte Daten auf die Platte
+func (rf *rotatingFile) Sync() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.file == nil {
+		return os.ErrClosed
+	}
+	return rf.file.Sync()
+}
+
+// Close schließt die Datei; spätere Writes schlagen fehl
+func (rf *rotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.file == nil {
+		return nil
+	}
+	err := rf.file.Close()
+	rf.file = nil
+	return err
+}
@@ -0,0 +1,191 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// openTestLog öffnet test.log in einem temporären Verzeichnis
+func openTestLog(t *testing.T, config rotationConfig) (*rotatingFile, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.log")
+	rf, err := openRotatingFile(path, config)
+	if err != nil {
+		t.Fatalf("openRotatingFile: %v", err)
+	}
+	t.Cleanup(func() { rf.Close() })
+	return rf, path
+}
+
+// readLog liest eine Log-Datei, "" wenn sie nicht existiert
+func readLog(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	return string(data)
+}
+
+// TestRotateBySize schreibt mehr als maxSize und prüft, dass nur keep alte
+// Dateien bleiben und die neuesten Einträge erhalten sind
+func TestRotateBySize(t *testing.T) {
+	rf, path := openTestLog(t, rotationConfig{maxSize: 20, keep: 2})
+	for _, entry := range []string{"first entry\n", "second entry\n", "third entry\n", "fourth entry\n"} {
+		if _, err := rf.Write([]byte(entry)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	for file, want := range map[string]string{
+		path:        "fourth entry\n",
+		path + ".1": "third entry\n",
+		path + ".2": "second entry\n",
+		path + ".3": "",
+	} {
+		if got := readLog(t, file); got != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(file), got, want)
+		}
+	}
+}
+
+// TestRotateWithoutRetention verwirft alte Einträge, wenn keep 0 ist
+func TestRotateWithoutRetention(t *testing.T) {
+	rf, path := openTestLog(t, rotationConfig{maxSize: 10, keep: 0})
+	rf.Write([]byte("old entry\n"))
+	rf.Write([]byte("new entry\n"))
+
+	if got := readLog(t, path); got != "new entry\n" {
+		t.Errorf("log = %q, want only the new entry", got)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Errorf("%s.1 exists although nothing is kept", filepath.Base(path))
+	}
+}
+
+// TestOversizedEntryIsNotSplit schreibt einen Eintrag, der allein größer als
+// maxSize ist; er landet vollständig in einer Datei
+func TestOversizedEntryIsNotSplit(t *testing.T) {
+	rf, path := openTestLog(t, rotationConfig{maxSize: 10, keep: 1})
+	long := strings.Repeat("x", 50) + "\n"
+	rf.Write([]byte(long))
+	if got := readLog(t, path); got != long {
+		t.Errorf("log = %q, want the whole entry", got)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Error("an empty file was rotated")
+	}
+}
+
+// TestRotateByAge rotiert eine Datei, die älter als das Intervall ist
+func TestRotateByAge(t *testing.T) {
+	rf, path := openTestLog(t, rotationConfig{interval: time.Hour, keep: 1})
+	rf.Write([]byte("yesterday\n"))
+	rf.Write([]byte("still today\n"))
+	if got := readLog(t, path + ".1"); got != "" {
+		t.Fatalf("rotated before the interval: %q", got)
+	}
+
+	rf.mu.Lock()
+	rf.started = time.Now().Add(-2 * time.Hour)
+	rf.mu.Unlock()
+	rf.Write([]byte("today\n"))
+
+	if got := readLog(t, path); got != "today\n" {
+		t.Errorf("log = %q, want the new entry", got)
+	}
+	if got := readLog(t, path + ".1"); got != "yesterday\nstill today\n" {
+		t.Errorf("log.1 = %q, want the old entries", got)
+	}
+}
+
+// TestReopenContinuesSize prüft, dass eine bestehende Datei mit ihrer Größe
+// fortgesetzt wird
+func TestReopenContinuesSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("0123456789\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rf, err := openRotatingFile(path, rotationConfig{maxSize: 15, keep: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	rf.Write([]byte("next\n"))
+	if got := readLog(t, path + ".1"); got != "0123456789\n" {
+		t.Errorf("log.1 = %q, want the previous content", got)
+	}
+	if got := readLog(t, path); got != "next\n" {
+		t.Errorf("log = %q, want the new entry", got)
+	}
+}
+
+// TestConcurrentWrites schreibt aus mehreren Goroutinen und prüft, dass kein
+// Eintrag verloren geht oder auf zwei Dateien verteilt wird
+func TestConcurrentWrites(t *testing.T) {
+	const writers, entries = 8, 200
+	rf, path := openTestLog(t, rotationConfig{maxSize: 4096, keep: 100})
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < entries; i++ {
+				rf.Write([]byte(fmt.Sprintf("writer %d entry %03d\n", w, i)))
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	files, _ := filepath.Glob(path + "*")
+	if len(files) < 2 {
+		t.Fatalf("expected rotation, found %d files", len(files))
+	}
+	count := 0
+	for _, file := range files {
+		content := readLog(t, file)
+		if !strings.HasSuffix(content, "\n") {
+			t.Errorf("%s ends inside an entry", filepath.Base(file))
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+			var w, i int
+			if _, err := fmt.Sscanf(line, "writer %d entry %d", &w, &i); err != nil {
+				t.Errorf("%s contains the broken entry %q", filepath.Base(file), line)
+			}
+			count++
+		}
+	}
+	if count != writers*entries {
+		t.Errorf("found %d entries, want %d", count, writers*entries)
+	}
+}
+
+// TestWriteAfterClose meldet os.ErrClosed
+func TestWriteAfterClose(t *testing.T) {
+	rf, _ := openTestLog(t, rotationConfig{})
+	if err := rf.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := rf.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write after Close = %v, want os.ErrClosed", err)
+	}
+	if err := rf.Close(); err != nil {
+		t.Errorf("second Close = %v", err)
+	}
+}
+
+// TestLoadRotationConfigDefaults prüft die Standardwerte ohne settings.cfg
+func TestLoadRotationConfigDefaults(t *testing.T) {
+	want := rotationConfig{maxSize: 10 * 1024 * 1024, interval: 0, keep: 3}
+	if got := loadRotationConfig(); got != want {
+		t.Errorf("loadRotationConfig() = %+v, want %+v", got, want)
+	}
+}
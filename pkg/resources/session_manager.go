@@ -289,3 +289,16 @@ func (srm *SessionResourceManager) GetSessionResource(sessionID string) (*Sessio
 
 	return session, nil
 }
+
+// GetSessionSnapshots gibt Kopien aller registrierten Sessions zurück,
+// damit Aufrufer die Zähler ohne Sperre auswerten können
+func (srm *SessionResourceManager) GetSessionSnapshots() map[string]SessionResource {
+	srm.sessionsMutex.RLock()
+	defer srm.sessionsMutex.RUnlock()
+
+	result := make(map[string]SessionResource, len(srm.sessions))
+	for sessionID, session := range srm.sessions {
+		result[sessionID] = *session
+	}
+	return result
+}
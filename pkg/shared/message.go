@@ -37,11 +37,19 @@ const (
 	MessageTypeParticle     MessageType = 29 // Particle system commands
 	MessageTypeSFX          MessageType = 30 // Sound effects via sfxr.js
 	MessageTypePhysics      MessageType = 31 // Physics commands via Planck.js
+	MessageTypeTheme        MessageType = 32 // Terminal-Theme (Farben, Scanlines, Schrift)
+	MessageTypeResult       MessageType = 33 // Befehlsergebnis (nur strukturiertes Protokoll, siehe CommandResult)
+	MessageTypePing         MessageType = 34 // Latenzmessung: Zeitstempel, den der Client unverändert zurückschickt
 
 	// MessageTypeError könnte hier mit einem Wert außerhalb des Frontend-Bereichs definiert werden, falls benötigt
 	// z.B. MessageTypeError MessageType = 100
 )
 
+// FeatureColor steht im Query-Parameter features von Clients, die farbigen
+// Text (Message.Color) darstellen können. Allen anderen entfernt das Terminal
+// die Farbe.
+const FeatureColor = "color"
+
 // Message repräsentiert eine Nachricht, die über WebSocket gesendet oder empfangen wird.
 // Die Felder sind so strukturiert, dass sie den direkten Zugriffen im Frontend (retroconsole.js) entsprechen.
 type Message struct {
@@ -51,6 +59,9 @@ type Message struct {
 	NoNewline bool `json:"noNewline"`
 	// Für PRINT/LOCATE: Inverser Text-Modus
 	Inverse bool `json:"inverse"`
+	// Für TEXT: Helligkeitsstufe der Theme-Palette (1-15), 0 = normale Textfarbe.
+	// Nur Clients mit FeatureColor erhalten das Feld.
+	Color int `json:"color,omitempty"`
 
 	// Für SESSION
 	SessionID string `json:"sessionId,omitempty"` // Beibehaltung des Namens sessionId für Kompatibilität
@@ -128,6 +139,9 @@ type Message struct {
 	BitmapScale  float64 `json:"bitmapScale,omitempty"`  // Scale factor (1.0 = original size)
 	BitmapRotate float64 `json:"bitmapRotate,omitempty"` // Rotation in degrees
 	BitmapID     string  `json:"bitmapId,omitempty"`     // Unique identifier for the bitmap
+
+	// Für RESULT (Type == MessageTypeResult, nur im strukturierten Protokoll)
+	Result *CommandResult `json:"result,omitempty"`
 }
 
 // TerminalMessage ist ein Alias, um alte Referenzen nicht sofort zu brechen.
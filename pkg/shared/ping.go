@@ -0,0 +1,26 @@
+package shared
+
+import (
+	"strconv"
+	"time"
+)
+
+// NewPingMessage returns a MessageTypePing carrying the send time. The client
+// sends it back unchanged so the server can measure the round trip.
+func NewPingMessage(now time.Time) Message {
+	return Message{Type: MessageTypePing, Content: strconv.FormatInt(now.UnixNano(), 10)}
+}
+
+// PingRoundTrip returns the round-trip time of an echoed ping token. ok is
+// false for tokens that were not issued by NewPingMessage within maxAge.
+func PingRoundTrip(token string, now time.Time, maxAge time.Duration) (rtt time.Duration, ok bool) {
+	sent, err := strconv.ParseInt(token, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	rtt = now.Sub(time.Unix(0, sent))
+	if rtt < 0 || rtt > maxAge {
+		return 0, false
+	}
+	return rtt, true
+}
@@ -0,0 +1,88 @@
+package shared
+
+import "strings"
+
+// ProtocolStructured is the value of the protocol query parameter that asks
+// for command results wrapped in CommandResult envelopes (MessageTypeResult).
+// The browser frontend never sets it.
+const ProtocolStructured = "structured"
+
+// Exit status of a command, following shell conventions
+const (
+	ExitOK             = 0
+	ExitFailure        = 1
+	ExitUsage          = 2
+	ExitTimeout        = 124
+	ExitUnknownCommand = 127
+)
+
+// Error codes reported in ResultError.Code
+const (
+	ErrCodeFailed           = "FAILED"
+	ErrCodeUsage            = "USAGE"
+	ErrCodeNotFound         = "NOT_FOUND"
+	ErrCodePermissionDenied = "PERMISSION_DENIED"
+	ErrCodeTimeout          = "TIMEOUT"
+	ErrCodeUnknownCommand   = "UNKNOWN_COMMAND"
+)
+
+// CommandResult is the machine-readable envelope around the output of one
+// shell command
+type CommandResult struct {
+	Command string       `json:"command"`
+	Status  int          `json:"status"`
+	Output  []Message    `json:"output"`
+	Error   *ResultError `json:"error,omitempty"`
+}
+
+// ResultError describes why a command failed
+type ResultError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewResultMessage wraps the output of a command in a result envelope. The
+// shell reports errors as text, so status and error code are derived from
+// the first line of output.
+func NewResultMessage(command string, output []Message) Message {
+	result := &CommandResult{Command: command, Status: ExitOK, Output: output}
+	if output == nil {
+		result.Output = []Message{}
+	}
+	for _, msg := range output {
+		if msg.Type != MessageTypeText || strings.TrimSpace(msg.Content) == "" {
+			continue
+		}
+		text := strings.TrimSpace(msg.Content)
+		if code, status := classifyError(command, text); code != "" {
+			result.Status = status
+			result.Error = &ResultError{Code: code, Message: text}
+		}
+		break
+	}
+	return Message{Type: MessageTypeResult, Result: result}
+}
+
+// classifyError maps an error line of the shell to an error code and exit
+// status. Returns an empty code for regular output.
+func classifyError(command, text string) (string, int) {
+	lower := strings.ToLower(text)
+	name := strings.ToLower(strings.SplitN(strings.TrimSpace(command), " ", 2)[0])
+
+	switch {
+	case strings.HasPrefix(lower, "unknown command"):
+		return ErrCodeUnknownCommand, ExitUnknownCommand
+	case strings.HasPrefix(lower, "command timed out"):
+		return ErrCodeTimeout, ExitTimeout
+	case strings.HasPrefix(lower, "usage:"):
+		return ErrCodeUsage, ExitUsage
+	case !strings.HasPrefix(lower, "error:") && (name == "" || !strings.HasPrefix(lower, name+":")):
+		return "", ExitOK
+	case strings.Contains(lower, "permission denied"), strings.Contains(lower, "access denied"),
+		strings.Contains(lower, "log in"), strings.Contains(lower, "logged in"):
+		return ErrCodePermissionDenied, ExitFailure
+	case strings.Contains(lower, "not found"), strings.Contains(lower, "no such"):
+		return ErrCodeNotFound, ExitFailure
+	}
+	return ErrCodeFailed, ExitFailure
+}
@@ -0,0 +1,105 @@
+package shared
+
+import (
+	"sort"
+	"strings"
+)
+
+// Limits of Suggest. It only runs when a command was not found, but a caller
+// passing a long list should not turn a typo into an expensive error.
+const (
+	maxSuggestCandidates = 500
+	maxSuggestions       = 3
+)
+
+// Suggest returns the candidates that are most likely meant by a misspelt
+// word, nearest first and at most three. Case is ignored. Words of up to four
+// characters may differ by one edit, longer ones by two. Words shorter than two
+// characters or equal to a candidate get no suggestion. Only the first 500
+// candidates are compared.
+func Suggest(word string, candidates []string) []string {
+	target := []rune(strings.ToLower(word))
+	if len(target) < 2 {
+		return nil
+	}
+	maxDistance := 1
+	if len(target) > 4 {
+		maxDistance = 2
+	}
+	if len(candidates) > maxSuggestCandidates {
+		candidates = candidates[:maxSuggestCandidates]
+	}
+
+	type match struct {
+		name     string
+		distance int
+	}
+	var matches []match
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		lower := strings.ToLower(candidate)
+		if seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		d := editDistance(target, []rune(lower), maxDistance)
+		if d == 0 {
+			return nil
+		}
+		if d <= maxDistance {
+			matches = append(matches, match{candidate, d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].distance < matches[j].distance })
+	if len(matches) > maxSuggestions {
+		matches = matches[:maxSuggestions]
+	}
+	suggestions := make([]string, len(matches))
+	for i, m := range matches {
+		suggestions[i] = m.name
+	}
+	return suggestions
+}
+
+// DidYouMean turns suggestions into "did you mean a, b or c?". It returns an
+// empty string if there are none.
+func DidYouMean(suggestions []string) string {
+	switch len(suggestions) {
+	case 0:
+		return ""
+	case 1:
+		return "did you mean " + suggestions[0] + "?"
+	}
+	last := len(suggestions) - 1
+	return "did you mean " + strings.Join(suggestions[:last], ", ") + " or " + suggestions[last] + "?"
+}
+
+// editDistance is the Levenshtein distance of a and b. Once it is certain to
+// exceed limit it stops and returns limit+1.
+func editDistance(a, b []rune, limit int) int {
+	if diff := len(a) - len(b); diff > limit || -diff > limit {
+		return limit + 1
+	}
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
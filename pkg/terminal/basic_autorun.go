@@ -0,0 +1,74 @@
+package terminal
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+	"github.com/antibyte/retroterm/pkg/tinyos"
+)
+
+// autorunFailWindow: Bricht autorun.bas innerhalb dieser Zeit mit einem
+// Fehler ab, wird es in dieser Verbindung nicht mehr gestartet. Sonst landet
+// der Benutzer bei jedem "basic" wieder in derselben Fehlermeldung.
+const autorunFailWindow = 5 * time.Second
+
+// autorunRequest liefert das autorun.bas, das der Moduswechsel nach BASIC
+// unter den Nachrichten mitbringt, oder ""
+func autorunRequest(messages []shared.Message) string {
+	for _, message := range messages {
+		if message.Result != nil {
+			if autorun := autorunRequest(message.Result.Output); autorun != "" {
+				return autorun
+			}
+		}
+		if message.Type == shared.MessageTypeMode && message.Content == ModeBasic {
+			if autorun, ok := message.Params[tinyos.AutorunParam].(string); ok {
+				return autorun
+			}
+		}
+	}
+	return ""
+}
+
+// runUserAutorun lädt das autorun.bas eines Benutzers beim Start von BASIC
+// und führt es aus. Anders als beim "run"-Befehl bleibt der Benutzer nach
+// dem Programmende in BASIC, das Programm bleibt geladen.
+func (h *TerminalHandler) runUserAutorun(client *Client, filename string) {
+	sessionID := client.sessionID
+	name := path.Base(filename)
+	if client.autorunFailed == filename {
+		h.sendMessageToClient(client, shared.Message{Type: shared.MessageTypeText,
+			Content: name + " skipped, it failed right after starting. It runs again after you reconnect; RUN \"" + filename + "\" starts it now."})
+		return
+	}
+
+	basic := h.getBasicInstance(sessionID)
+	basic.SetTerminalDimensions(client.cols, client.rows)
+	h.sendMessageToClient(client, shared.Message{Type: shared.MessageTypeText, Content: "Running " + name + " (basic -n starts without it)"})
+
+	for _, message := range basic.Execute(fmt.Sprintf(`LOAD "%s"`, filename)) {
+		h.sendMessageToClient(client, message)
+	}
+	h.processBasicOutputForSession(sessionID, basic)
+	if !basic.HasProgram() {
+		return
+	}
+
+	started := time.Now()
+	basic.SetOnProgramEnd(func() {
+		line, message := basic.LastError()
+		if (line > 0 || message != "") && time.Since(started) < autorunFailWindow {
+			client.autorunFailed = filename
+			log.Printf("[BASIC-AUTORUN] %s of session %s failed at once, not starting it again", filename, sessionID)
+		}
+	})
+
+	log.Printf("[BASIC-AUTORUN] Running %s for session %s", filename, sessionID)
+	for _, message := range basic.Execute("RUN") {
+		h.sendMessageToClient(client, message)
+	}
+	h.processBasicOutputForSession(sessionID, basic)
+}
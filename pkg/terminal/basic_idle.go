@@ -0,0 +1,66 @@
+package terminal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/tinyos"
+)
+
+// basicIdleCheckInterval ist der Abstand zwischen zwei Prüfungen auf
+// untätige BASIC-Sitzungen von Gästen
+const basicIdleCheckInterval = time.Minute
+
+// guestBasicIdleTimeout liest guest_basic_idle_timeout bei jeder Prüfung neu,
+// damit ein Reload der Konfiguration sofort wirkt. 0 schaltet die Freigabe ab,
+// kürzere Werte als das Prüfintervall werden auf dieses angehoben.
+func guestBasicIdleTimeout() time.Duration {
+	idle := configuration.GetDuration("Terminal", "guest_basic_idle_timeout", 15*time.Minute)
+	if idle > 0 && idle < basicIdleCheckInterval {
+		idle = basicIdleCheckInterval
+	}
+	return idle
+}
+
+// reclaimIdleGuestBasicSessions beendet BASIC-Sitzungen von Gästen, in denen
+// länger als guest_basic_idle_timeout nichts eingegeben wurde. Ein vergessener
+// Tab belegt sonst bis zum Ablauf der Session einen der MaxGuestBasicSessions-Plätze.
+func (h *TerminalHandler) reclaimIdleGuestBasicSessions() {
+	ticker := time.NewTicker(basicIdleCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		timeout := guestBasicIdleTimeout()
+		if timeout <= 0 {
+			continue
+		}
+		for _, sessionID := range h.os.IdleGuestBasicSessions(timeout) {
+			h.endIdleBasicSession(sessionID, timeout)
+		}
+	}
+}
+
+// endIdleBasicSession bricht das Programm ab, schaltet den Tab zurück in die
+// Shell und gibt den Platz der BASIC-Sitzung frei
+func (h *TerminalHandler) endIdleBasicSession(sessionID string, timeout time.Duration) {
+	h.mutex.Lock()
+	var target *Client
+	for client := range h.clients {
+		if client.sessionID == sessionID && client.mode == ModeBasic {
+			target = client
+			break
+		}
+	}
+	h.mutex.Unlock()
+
+	minutes := int(timeout.Minutes())
+	if target != nil {
+		h.returnToOSAfterProgram(target, fmt.Sprintf("BASIC session ended after %d minutes without input. Back to TinyOS.", minutes))
+	} else {
+		// Kein Tab mehr im BASIC-Modus, nur noch den Platz freigeben
+		h.cleanupBasicInstance(sessionID)
+	}
+	logger.Info(logger.AreaSession, "Reclaimed guest BASIC slot of session %s after %d minutes without input (%d guest slots)",
+		sessionID, minutes, tinyos.MaxGuestBasicSessions)
+}
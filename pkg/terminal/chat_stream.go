@@ -0,0 +1,102 @@
+package terminal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// chatStreamCancelled steht im Content von ChatResponseTypeDone, wenn die
+// Antwort abgebrochen wurde
+const chatStreamCancelled = "cancelled"
+
+// startChatResponse startet die gestreamte Antwort des MCP in einer eigenen
+// Goroutine, damit chatReadPump währenddessen einen Abbruch lesen kann.
+// Gibt false zurück, wenn noch eine Antwort läuft.
+func (h *TerminalHandler) startChatResponse(client *Client, content string) bool {
+	client.chatMutex.Lock()
+	if client.chatCancel != nil {
+		client.chatMutex.Unlock()
+		return false
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	client.chatCancel = cancel
+	client.chatMutex.Unlock()
+	h.os.CountChatRequest()
+
+	go func() {
+		messages := h.os.StreamDeepSeek(ctx, content, client.sessionID, func(chunk string) {
+			sendChatResponse(client, ChatResponse{Role: chatRoleAI, Content: chunk, Type: ChatResponseTypeChunk})
+		})
+		cancelled := errors.Is(ctx.Err(), context.Canceled)
+
+		// Vor der Abschlussnachricht freigeben, damit die nächste Frage
+		// nicht abgewiesen wird
+		client.chatMutex.Lock()
+		client.chatCancel = nil
+		client.chatMutex.Unlock()
+		cancel()
+
+		done := ChatResponse{Role: chatRoleAI, Type: ChatResponseTypeDone}
+		if cancelled {
+			done.Content = chatStreamCancelled
+		}
+		sendChatResponse(client, done)
+		for _, msg := range messages {
+			sendChatResponse(client, chatResponseFor(msg))
+		}
+	}()
+	return true
+}
+
+// cancelChatResponse bricht die laufende Antwort samt Anfrage an DeepSeek ab.
+// Gibt false zurück, wenn keine Antwort läuft.
+func (c *Client) cancelChatResponse() bool {
+	c.chatMutex.Lock()
+	defer c.chatMutex.Unlock()
+	if c.chatCancel == nil {
+		return false
+	}
+	c.chatCancel()
+	return true
+}
+
+// chatResponseRunning meldet, ob der MCP gerade antwortet
+func (c *Client) chatResponseRunning() bool {
+	c.chatMutex.Lock()
+	defer c.chatMutex.Unlock()
+	return c.chatCancel != nil
+}
+
+// chatResponseFor wandelt eine Nachricht von TinyOS in eine Chat-Antwort um
+func chatResponseFor(msg shared.Message) ChatResponse {
+	response := ChatResponse{
+		Role:    chatRoleAI,
+		Content: msg.Content,
+	}
+	switch msg.Type {
+	case shared.MessageTypeBeep:
+		response.Type = ChatResponseTypeBeep
+		response.Content = "" // Content für Beep leer lassen
+	case shared.MessageTypeSpeak:
+		// Im Chat-Modus keine speechId erforderlich, da keine SAY_DONE-Kommunikation
+		response.Type = ChatResponseTypeSpeak
+	case shared.MessageTypeEvil: // MCP Evil effect - dramatic noise increase
+		response.Type = ChatResponseTypeEvil
+		response.Content = "" // Content für Evil effect leer lassen
+	default: // shared.MessageTypeText und andere
+		response.Type = ChatResponseTypeText
+	}
+	return response
+}
+
+// sendChatResponse serialisiert response und sendet sie an den Chat-Client
+func sendChatResponse(client *Client, response ChatResponse) {
+	jsonMsg, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	client.Send(jsonMsg)
+}
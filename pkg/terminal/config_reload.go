@@ -0,0 +1,88 @@
+package terminal
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/logger"
+)
+
+// ConfigReloadResult beschreibt das Ergebnis eines Konfigurations-Reloads
+type ConfigReloadResult struct {
+	Applied         []string  `json:"applied"`          // Geänderte Schlüssel, sofort aktiv
+	RestartRequired []string  `json:"restart_required"` // Geänderte Schlüssel, die erst nach einem Neustart wirken
+	ReloadedAt      time.Time `json:"reloaded_at"`
+}
+
+// ReloadConfiguration liest settings.cfg neu ein, ohne Verbindungen zu trennen.
+// source beschreibt den Auslöser (z.B. "SIGHUP") für das Log.
+func ReloadConfiguration(source string) (*ConfigReloadResult, error) {
+	changed, err := configuration.Reload()
+	if err != nil {
+		logger.ConfigError("Configuration reload (%s) failed, keeping previous values: %v", source, err)
+		return nil, err
+	}
+
+	result := &ConfigReloadResult{
+		Applied:         []string{},
+		RestartRequired: []string{},
+		ReloadedAt:      time.Now(),
+	}
+	for _, name := range changed {
+		section, key := configuration.SplitKey(name)
+		if configuration.RequiresRestart(section, key) {
+			result.RestartRequired = append(result.RestartRequired, name)
+		} else {
+			result.Applied = append(result.Applied, name)
+		}
+	}
+
+	logger.ConfigInfo("Configuration reloaded (%s): %d key(s) applied %v", source, len(result.Applied), result.Applied)
+	if len(result.RestartRequired) > 0 {
+		logger.ConfigWarn("Configuration reload (%s): %v only take effect after a restart", source, result.RestartRequired)
+	}
+	return result, nil
+}
+
+// proxyHeaders setzt ein Reverse-Proxy. Hinter einem Proxy auf demselben Host
+// kommt jede Anfrage über Loopback, auch die aus dem Internet.
+var proxyHeaders = []string{"X-Forwarded-For", "Forwarded", "X-Real-IP"}
+
+// HandleConfigReload lädt die Konfiguration neu (POST /api/admin/reload-config).
+// Der Endpunkt ist nur über Loopback erreichbar. Anfragen mit Proxy-Headern werden
+// abgewiesen, damit ein lokaler Reverse-Proxy den Endpunkt nicht öffentlich macht.
+func (h *TerminalHandler) HandleConfigReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		logger.SecurityWarn("Rejected configuration reload request from %s", r.RemoteAddr)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	for _, header := range proxyHeaders {
+		if r.Header.Get(header) != "" {
+			logger.SecurityWarn("Rejected proxied configuration reload request (%s: %s)", header, r.Header.Get(header))
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	result, err := ReloadConfiguration("HTTP " + host)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,49 @@
+package terminal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestHandleConfigReloadRefusesProxiedRequests checks that only direct
+// loopback requests may reload. A reverse proxy on the same host connects
+// over loopback as well, but adds its headers.
+func TestHandleConfigReloadRefusesProxiedRequests(t *testing.T) {
+	h := &TerminalHandler{}
+	for _, tc := range []struct {
+		name   string
+		method string
+		remote string
+		header string
+		value  string
+		want   int
+	}{
+		{"wrong method", http.MethodGet, "127.0.0.1:4711", "", "", http.StatusMethodNotAllowed},
+		{"remote client", http.MethodPost, "203.0.113.5:4711", "", "", http.StatusForbidden},
+		{"X-Forwarded-For", http.MethodPost, "127.0.0.1:4711", "X-Forwarded-For", "203.0.113.5", http.StatusForbidden},
+		{"Forwarded", http.MethodPost, "[::1]:4711", "Forwarded", "for=203.0.113.5", http.StatusForbidden},
+		{"X-Real-IP", http.MethodPost, "127.0.0.1:4711", "X-Real-IP", "203.0.113.5", http.StatusForbidden},
+	} {
+		r := httptest.NewRequest(tc.method, "/api/admin/reload-config", nil)
+		r.RemoteAddr = tc.remote
+		if tc.header != "" {
+			r.Header.Set(tc.header, tc.value)
+		}
+		w := httptest.NewRecorder()
+		h.HandleConfigReload(w, r)
+		if w.Code != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.name, w.Code, tc.want)
+		}
+	}
+
+	// A direct local request gets to the reload, which fails without a
+	// loaded configuration
+	r := httptest.NewRequest(http.MethodPost, "/api/admin/reload-config", nil)
+	r.RemoteAddr = "127.0.0.1:4711"
+	w := httptest.NewRecorder()
+	h.HandleConfigReload(w, r)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("direct loopback request: status %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
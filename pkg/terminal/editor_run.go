@@ -0,0 +1,98 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/antibyte/retroterm/pkg/editor"
+	"github.com/antibyte/retroterm/pkg/shared"
+	"github.com/antibyte/retroterm/pkg/tinyos"
+)
+
+// finishEditor räumt einen beendeten Editor auf. Wurde er per Save & Run verlassen,
+// bleibt er suspendiert und das Programm wird gestartet.
+func (h *TerminalHandler) finishEditor(client *Client, ed *editor.Editor) {
+	if ed.TakeRunRequest() {
+		editor.GetEditorManager().SuspendEditor(client.sessionID)
+		go h.runEditorProgram(client, ed.GetFilename())
+		return
+	}
+	editor.GetEditorManager().CloseEditor(client.sessionID)
+}
+
+// runEditorProgram führt die im Editor gespeicherte Datei aus (CTRL+R).
+// Der Editor wurde vorher suspendiert; das Programm läuft in einer frischen,
+// an die Session gebundenen TinyBASIC-Instanz. Bei einem Fehler kehrt der
+// Benutzer in den Editor zurück, die Fehlerzeile wird markiert.
+func (h *TerminalHandler) runEditorProgram(client *Client, filename string) {
+	sessionID := client.sessionID
+	editorManager := editor.GetEditorManager()
+
+	if h.os != nil && !h.os.StartBasicSession(sessionID) {
+		editorManager.ResumeEditor(sessionID, 0, tinyos.SessionLimitMessage)
+		return
+	}
+
+	// Alte Instanz verwerfen, damit kein Zustand aus früheren Läufen übrig bleibt
+	h.cleanupBasicInstanceForAutorun(sessionID)
+	basic := h.getBasicInstance(sessionID)
+	basic.SetTerminalDimensions(client.cols, client.rows)
+
+	client.mode = ModeBasic
+	h.sendMessageToClient(client, shared.Message{Type: shared.MessageTypeMode, Content: "basic"})
+
+	basic.Execute(fmt.Sprintf(`LOAD "%s"`, filename))
+	h.processBasicOutputForSession(sessionID, basic)
+	if !basic.HasProgram() {
+		h.returnToEditorAfterRun(client, 0, "No program lines in "+filename)
+		return
+	}
+
+	basic.SetOnProgramEnd(func() {
+		line, message := basic.LastError()
+		if line > 0 || message != "" {
+			h.returnToEditorAfterRun(client, line, message)
+			return
+		}
+		editorManager.DiscardSuspendedEditor(sessionID)
+		h.returnToOSAfterProgram(client, "Program completed. Back to TinyOS.")
+	})
+
+	log.Printf("[EDITOR-RUN] Running %s for session %s", filename, sessionID)
+	for _, message := range basic.Execute("RUN") {
+		h.sendMessageToClient(client, message)
+	}
+	h.processBasicOutputForSession(sessionID, basic)
+}
+
+// returnToEditorAfterRun beendet die BASIC-Sitzung und stellt den suspendierten Editor wieder her
+func (h *TerminalHandler) returnToEditorAfterRun(client *Client, line int, message string) {
+	sessionID := client.sessionID
+
+	h.cleanupBasicInstanceForAutorun(sessionID)
+	if h.os != nil {
+		h.os.EndBasicSession(sessionID)
+	}
+	client.mode = ModeOS
+	h.sendMessageToClient(client, shared.Message{Type: shared.MessageTypeMode, Content: "os"})
+
+	if editor.GetEditorManager().ResumeEditor(sessionID, line, message) == nil {
+		// Editor ist nicht mehr vorhanden (z.B. Session beendet) - zurück zur Shell
+		h.sendMessageToClient(client, shared.Message{Type: shared.MessageTypeInputControl, Content: "enable"})
+		return
+	}
+	if h.os != nil {
+		h.os.SetInputMode(sessionID, tinyos.InputModeEditor)
+	}
+}
+
+// sendMessageToClient serialisiert eine einzelne Nachricht und sendet sie an den Client
+func (h *TerminalHandler) sendMessageToClient(client *Client, message shared.Message) {
+	jsonMsg, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshalling message: %v", err)
+		return
+	}
+	h.SendToClient(client, jsonMsg)
+}
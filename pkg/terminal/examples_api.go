@@ -0,0 +1,74 @@
+package terminal
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/tinyos"
+)
+
+// HandleExamplesList liefert die Liste der Beispielprogramme als JSON (GET /api/examples).
+// Der Endpunkt ist öffentlich und nur lesend, daher wird er pro IP rate-limitiert.
+func (h *TerminalHandler) HandleExamplesList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Wie beim Verbindungslimit: ohne Port und X-Forwarded-For nur von vertrauenswürdigen Proxys
+	ipAddress := tinyos.ClientIP(r)
+	if !h.allowExamplesRequest(ipAddress) {
+		logger.SecurityWarn("Example gallery rate limit exceeded for %s", ipAddress)
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "Too many requests", http.StatusTooManyRequests)
+		return
+	}
+
+	examples, err := h.os.ListExamples()
+	if err != nil {
+		logger.Error(logger.AreaFileSystem, "Failed to list examples: %v", err)
+		http.Error(w, "Examples not available", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"examples": examples,
+		"count":    len(examples),
+	})
+}
+
+// allowExamplesRequest prüft das Rate-Limit für /api/examples (gleitendes Fenster von einer Minute)
+func (h *TerminalHandler) allowExamplesRequest(ipAddress string) bool {
+	limit := configuration.GetInt("Network", "examples_requests_per_minute", 30)
+	now := time.Now()
+	windowStart := now.Add(-time.Minute)
+
+	h.examplesRateMutex.Lock()
+	defer h.examplesRateMutex.Unlock()
+
+	// Alte Einträge aller IPs entfernen, damit die Map nicht unbegrenzt wächst
+	for ip, requests := range h.examplesRequests {
+		valid := requests[:0]
+		for _, t := range requests {
+			if t.After(windowStart) {
+				valid = append(valid, t)
+			}
+		}
+		if len(valid) == 0 {
+			delete(h.examplesRequests, ip)
+		} else {
+			h.examplesRequests[ip] = valid
+		}
+	}
+
+	if len(h.examplesRequests[ipAddress]) >= limit {
+		return false
+	}
+	h.examplesRequests[ipAddress] = append(h.examplesRequests[ipAddress], now)
+	return true
+}
@@ -0,0 +1,42 @@
+package terminal
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/tinyos"
+)
+
+// TestExamplesRateLimitIgnoresPort sends requests from one IP on different
+// ports; they must share one limit
+func TestExamplesRateLimitIgnoresPort(t *testing.T) {
+	h := &TerminalHandler{examplesRequests: make(map[string][]time.Time)}
+	first := httptest.NewRequest("GET", "/api/examples", nil)
+	first.RemoteAddr = "203.0.113.7:40001"
+	second := httptest.NewRequest("GET", "/api/examples", nil)
+	second.RemoteAddr = "203.0.113.7:40002"
+
+	if a, b := tinyos.ClientIP(first), tinyos.ClientIP(second); a != "203.0.113.7" || a != b {
+		t.Fatalf("ClientIP = %q and %q, want 203.0.113.7 for both", a, b)
+	}
+	for i := 0; i < 30; i++ {
+		if !h.allowExamplesRequest(tinyos.ClientIP(first)) {
+			t.Fatalf("request %d refused below the limit", i+1)
+		}
+	}
+	if h.allowExamplesRequest(tinyos.ClientIP(second)) {
+		t.Error("a new port of the same IP got a fresh limit")
+	}
+	// Without trusted_proxies a spoofed X-Forwarded-For changes nothing
+	second.Header.Set("X-Forwarded-For", "198.51.100.1")
+	if h.allowExamplesRequest(tinyos.ClientIP(second)) {
+		t.Error("a spoofed X-Forwarded-For got a fresh limit")
+	}
+
+	ipv6 := httptest.NewRequest("GET", "/api/examples", nil)
+	ipv6.RemoteAddr = "[2001:db8::1]:443"
+	if got := tinyos.ClientIP(ipv6); got != "2001:db8::1" {
+		t.Errorf("ClientIP(IPv6) = %q", got)
+	}
+}
@@ -0,0 +1,59 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/tinyos"
+)
+
+// HandleMetrics liefert Zähler und Sitzungszahlen im Prometheus-Textformat
+// (GET /metrics). Es gibt keine Anmeldung, nur IPs aus [Metrics] allowed_ips
+// dürfen lesen; X-Forwarded-For wird wie beim Config-Reload ignoriert.
+func (h *TerminalHandler) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !tinyos.IsMetricsClientAllowed(r.RemoteAddr) {
+		logger.SecurityWarn("Rejected metrics request from %s", r.RemoteAddr)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	// Die Client-Maps werden nur für len() gesperrt
+	h.mutex.RLock()
+	connections := len(h.clients)
+	h.mutex.RUnlock()
+	h.chatMutex.RLock()
+	chatConnections := len(h.chatClients)
+	h.chatMutex.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetrics(w, h.os.CollectMetrics(), connections, chatConnections)
+}
+
+// writeMetrics schreibt die Werte im Prometheus-Textformat
+func writeMetrics(w io.Writer, m tinyos.Metrics, connections, chatConnections int) {
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	metric("retroterm_sessions", "gauge", "Sessions known to TinyOS, guests included.")
+	fmt.Fprintf(w, "retroterm_sessions %d\n", m.Sessions)
+	metric("retroterm_connections", "gauge", "Open terminal WebSocket connections.")
+	fmt.Fprintf(w, "retroterm_connections %d\n", connections)
+	metric("retroterm_chat_connections", "gauge", "Open MCP chat WebSocket connections.")
+	fmt.Fprintf(w, "retroterm_chat_connections %d\n", chatConnections)
+	metric("retroterm_basic_sessions", "gauge", "Sessions in BASIC mode.")
+	fmt.Fprintf(w, "retroterm_basic_sessions %d\n", m.BasicSessions)
+	metric("retroterm_telnet_sessions", "gauge", "Sessions with an open telnet connection.")
+	fmt.Fprintf(w, "retroterm_telnet_sessions %d\n", m.TelnetSessions)
+	metric("retroterm_logins_total", "counter", "Password logins by result.")
+	fmt.Fprintf(w, "retroterm_logins_total{result=\"success\"} %d\n", m.LoginSuccesses)
+	fmt.Fprintf(w, "retroterm_logins_total{result=\"failure\"} %d\n", m.LoginFailures)
+	metric("retroterm_chat_requests_total", "counter", "Questions sent to the MCP chat.")
+	fmt.Fprintf(w, "retroterm_chat_requests_total %d\n", m.ChatRequests)
+}
@@ -0,0 +1,65 @@
+package terminal
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/logger"
+)
+
+// defaultAllowedOrigins wird verwendet, wenn [WebSocket] allowed_origins nicht gesetzt ist
+const defaultAllowedOrigins = "http://localhost:8080,http://127.0.0.1:8080"
+
+// checkWebSocketOrigin ist die CheckOrigin-Funktion für den WebSocket-Upgrader (/ws und /chat).
+// Verbindungen ohne oder mit nicht erlaubtem Origin werden abgelehnt und protokolliert.
+func checkWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		logger.SecurityWarn("WebSocket request without Origin header rejected from %s", r.RemoteAddr)
+		return false
+	}
+
+	allowedOrigins := configuration.GetString("WebSocket", "allowed_origins", defaultAllowedOrigins)
+	if isOriginAllowed(origin, allowedOrigins) {
+		return true
+	}
+
+	logger.SecurityWarn("WebSocket request from disallowed origin rejected: %s (remote %s)", origin, r.RemoteAddr)
+	return false
+}
+
+// isOriginAllowed prüft einen Origin gegen eine komma-separierte Allowlist.
+// Unterstützt werden exakte Einträge, "*" (alle Origins, nur für Entwicklung)
+// sowie Subdomain-Wildcards wie "https://*.example.com".
+func isOriginAllowed(origin, allowedOrigins string) bool {
+	origin = strings.ToLower(strings.TrimSpace(origin))
+	if origin == "" {
+		return false
+	}
+
+	for _, allowed := range strings.Split(allowedOrigins, ",") {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		switch {
+		case allowed == "":
+			continue
+		case allowed == "*":
+			return true
+		case strings.Contains(allowed, "://*."):
+			// Wildcard gilt nur für Subdomains, nicht für die Basisdomain selbst
+			scheme, host, _ := strings.Cut(allowed, "://*.")
+			prefix := scheme + "://"
+			if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, "."+host) ||
+				len(origin) <= len(prefix)+len(host)+1 {
+				continue
+			}
+			subdomain := origin[len(prefix) : len(origin)-len(host)-1]
+			if !strings.ContainsAny(subdomain, "/:@") {
+				return true
+			}
+		case origin == allowed:
+			return true
+		}
+	}
+	return false
+}
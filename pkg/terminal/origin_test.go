@@ -0,0 +1,62 @@
+package terminal
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsOriginAllowed(t *testing.T) {
+	allowlist := "http://localhost:8080, https://retroterm.example.com,https://*.retro.example"
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"http://localhost:8080", true},
+		{"https://retroterm.example.com", true},
+		{"HTTPS://RetroTerm.Example.com", true},
+		{"https://www.retro.example", true},
+		{"https://a.b.retro.example", true},
+		{"https://retro.example", false},           // Wildcard covers subdomains only
+		{"http://www.retro.example", false},        // Scheme must match
+		{"https://evil.com/.retro.example", false}, // Not a valid subdomain
+		{"https://evil.example.com", false},
+		{"http://localhost:9090", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isOriginAllowed(tt.origin, allowlist); got != tt.allowed {
+			t.Errorf("isOriginAllowed(%q) = %v, want %v", tt.origin, got, tt.allowed)
+		}
+	}
+}
+
+func TestIsOriginAllowedWildcard(t *testing.T) {
+	if !isOriginAllowed("https://anything.example", "*") {
+		t.Error("Wildcard '*' should allow any origin")
+	}
+	if isOriginAllowed("https://anything.example", "") {
+		t.Error("Empty allowlist should deny all origins")
+	}
+}
+
+func TestCheckWebSocketOrigin(t *testing.T) {
+	// Without a loaded configuration the default allowlist applies
+	allowedReq := httptest.NewRequest("GET", "/ws", nil)
+	allowedReq.Header.Set("Origin", "http://localhost:8080")
+	if !checkWebSocketOrigin(allowedReq) {
+		t.Error("Expected default origin http://localhost:8080 to be allowed")
+	}
+
+	deniedReq := httptest.NewRequest("GET", "/ws", nil)
+	deniedReq.Header.Set("Origin", "https://attacker.example")
+	if checkWebSocketOrigin(deniedReq) {
+		t.Error("Expected cross-site origin to be rejected")
+	}
+
+	missingReq := httptest.NewRequest("GET", "/chat", nil)
+	if checkWebSocketOrigin(missingReq) {
+		t.Error("Expected request without Origin header to be rejected")
+	}
+}
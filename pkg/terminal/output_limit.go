@@ -0,0 +1,87 @@
+package terminal
+
+import (
+	"sync"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+)
+
+// outputThrottledNotice wird einmal gesendet, wenn die Drosselung einsetzt
+const outputThrottledNotice = "*** OUTPUT THROTTLED ***"
+
+// outputLimiter begrenzt die BASIC-Ausgaben pro Session mit einem Token-Bucket.
+// Gedrosselte Nachrichten bleiben im Ausgabekanal des Interpreters, der das
+// Programm anhält, sobald der Kanal volläuft - es geht also nichts verloren.
+type outputLimiter struct {
+	mu        sync.Mutex
+	perSecond func() int // wird bei jeder Nachricht gelesen, 0 schaltet die Drosselung ab
+	buckets   map[string]*outputBucket
+}
+
+// outputBucket ist das Kontingent einer Session
+type outputBucket struct {
+	tokens    float64
+	last      time.Time
+	throttled bool // Hinweis für die laufende Drosselung bereits gesendet
+}
+
+// outputMessagesPerSecond liest das aktuelle Limit aus der Konfiguration
+func outputMessagesPerSecond() int {
+	return configuration.GetInt("Terminal", "max_output_messages_per_second", 2000)
+}
+
+// newOutputLimiter erzeugt den Limiter. perSecond liefert das aktuelle Limit,
+// damit ein Reload der Konfiguration sofort wirkt.
+func newOutputLimiter(perSecond func() int) *outputLimiter {
+	return &outputLimiter{perSecond: perSecond, buckets: make(map[string]*outputBucket)}
+}
+
+// allow verbraucht ein Token der Session. notice ist true, wenn die Session
+// gerade in die Drosselung geraten ist und der Hinweis gesendet werden soll.
+func (l *outputLimiter) allow(sessionID string) (allowed bool, notice bool) {
+	if l == nil {
+		return true, false
+	}
+	perSecond := float64(l.perSecond())
+	if perSecond <= 0 {
+		return true, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	bucket, exists := l.buckets[sessionID]
+	if !exists {
+		bucket = &outputBucket{tokens: perSecond, last: now}
+		l.buckets[sessionID] = bucket
+	}
+	bucket.tokens += now.Sub(bucket.last).Seconds() * perSecond
+	if bucket.tokens > perSecond {
+		bucket.tokens = perSecond
+	}
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		notice = !bucket.throttled
+		bucket.throttled = true
+		return false, notice
+	}
+	bucket.tokens--
+	// Erst ein halb gefülltes Kontingent beendet die Drosselung, damit der
+	// Hinweis nicht bei jedem Token erneut erscheint
+	if bucket.throttled && bucket.tokens >= perSecond/2 {
+		bucket.throttled = false
+	}
+	return true, false
+}
+
+// remove vergisst das Kontingent einer beendeten Session
+func (l *outputLimiter) remove(sessionID string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	delete(l.buckets, sessionID)
+	l.mu.Unlock()
+}
@@ -0,0 +1,34 @@
+package terminal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// handlePingReply wertet einen vom Client zurückgeschickten Ping aus: meldet
+// die Laufzeit und hält die Session am Leben
+func (h *TerminalHandler) handlePingReply(c *Client, token string) {
+	rtt, ok := shared.PingRoundTrip(token, time.Now(), getPongWait())
+	if !ok {
+		logger.Debug(logger.AreaWebSocket, "Ignoring invalid ping reply %q from %s", token, c.ipAddress)
+		return
+	}
+	if c.sessionID != "" {
+		h.os.UpdateSessionActivity(c.sessionID)
+	}
+	h.SendMessagesToClient(c, []shared.Message{{
+		Type:    shared.MessageTypeText,
+		Content: fmt.Sprintf("Reply from server: time=%s", formatRoundTrip(rtt)),
+	}})
+}
+
+// formatRoundTrip zeigt kurze Laufzeiten mit einer Nachkommastelle
+func formatRoundTrip(rtt time.Duration) string {
+	if rtt < 10*time.Millisecond {
+		return fmt.Sprintf("%.1f ms", float64(rtt)/float64(time.Millisecond))
+	}
+	return fmt.Sprintf("%d ms", rtt.Milliseconds())
+}
@@ -0,0 +1,156 @@
+package terminal
+
+import (
+	"sync"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// maxScreenBytes begrenzt den gemerkten Text einer Session zusätzlich zur
+// Zeilenzahl, damit einzelne sehr lange Ausgaben den Speicher nicht füllen
+const maxScreenBytes = 32 * 1024
+
+// shellPromptSymbol entspricht promptSymbol in retroconsole.js; das Frontend
+// zeigt eingegebene Befehle selbst an, der Server sieht nur die Eingabe
+const shellPromptSymbol = "> "
+
+// screenBuffer merkt sich die letzten Textzeilen der Shell pro Session. Beim
+// Start einer Telnet-Sitzung wird der Stand eingefroren und nach dem Ende
+// wieder an den Client geschickt, so dass die Shell wie vorher aussieht.
+type screenBuffer struct {
+	mu       sync.Mutex
+	maxLines func() int // wird bei jeder Nachricht gelesen, 0 schaltet die Wiederherstellung ab
+	screens  map[string]*sessionScreen
+}
+
+// sessionScreen ist der gemerkte Bildschirm einer Session
+type sessionScreen struct {
+	lines    []shared.Message
+	bytes    int
+	password bool // Passworteingabe läuft, Eingaben nicht aufzeichnen
+	telnet   bool // Telnet-Sitzung läuft, lines ist der eingefrorene Stand
+}
+
+// telnetRestoreLines liest die aktuelle Zeilenzahl aus der Konfiguration
+func telnetRestoreLines() int {
+	return configuration.GetInt("Terminal", "telnet_restore_lines", 200)
+}
+
+// newScreenBuffer erzeugt den Puffer. maxLines liefert die aktuelle Zeilenzahl,
+// damit ein Reload der Konfiguration sofort wirkt.
+func newScreenBuffer(maxLines func() int) *screenBuffer {
+	return &screenBuffer{maxLines: maxLines, screens: make(map[string]*sessionScreen)}
+}
+
+// screen liefert den Bildschirm einer Session. Muss unter b.mu aufgerufen werden.
+func (b *screenBuffer) screen(sessionID string) *sessionScreen {
+	s, exists := b.screens[sessionID]
+	if !exists {
+		s = &sessionScreen{}
+		b.screens[sessionID] = s
+	}
+	return s
+}
+
+// enabled gibt an, ob Zeilen gemerkt werden
+func (b *screenBuffer) enabled() bool {
+	return b != nil && b.maxLines() > 0
+}
+
+// record übernimmt eine an den Client gesendete Nachricht
+func (b *screenBuffer) record(sessionID string, msg shared.Message) {
+	if !b.enabled() || sessionID == "" {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := b.screen(sessionID)
+	switch msg.Type {
+	case shared.MessageTypeTelnet:
+		if msg.Content == "start" {
+			s.telnet = true
+		}
+	case shared.MessageTypeInputControl:
+		switch msg.Content {
+		case "password_mode_on":
+			s.password = true
+		case "password_mode_off":
+			s.password = false
+		}
+	case shared.MessageTypeClear:
+		if !s.telnet {
+			s.lines, s.bytes = nil, 0
+		}
+	case shared.MessageTypeText:
+		if !s.telnet {
+			b.appendLine(s, shared.Message{Type: shared.MessageTypeText, Content: msg.Content, NoNewline: msg.NoNewline, Inverse: msg.Inverse})
+		}
+	}
+}
+
+// recordInput übernimmt eine eingegebene Befehlszeile so, wie das Frontend
+// sie angezeigt hat. Passworteingaben werden nie gespeichert.
+func (b *screenBuffer) recordInput(sessionID, input string) {
+	if !b.enabled() || sessionID == "" {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := b.screen(sessionID)
+	if s.password || s.telnet {
+		return
+	}
+	b.appendLine(s, shared.Message{Type: shared.MessageTypeText, Content: shellPromptSymbol + input})
+}
+
+// appendLine hängt eine Zeile an und verwirft die ältesten, bis beide
+// Grenzen eingehalten sind. Muss unter b.mu aufgerufen werden.
+func (b *screenBuffer) appendLine(s *sessionScreen, line shared.Message) {
+	if len(line.Content) > maxScreenBytes {
+		line.Content = line.Content[:maxScreenBytes]
+	}
+	s.lines = append(s.lines, line)
+	s.bytes += len(line.Content)
+	maxLines := b.maxLines()
+	drop := 0
+	for len(s.lines)-drop > maxLines || s.bytes > maxScreenBytes {
+		s.bytes -= len(s.lines[drop].Content)
+		drop++
+	}
+	// append legt bei voller Kapazität ein neues Array nur mit den
+	// verbliebenen Zeilen an, der Speicher bleibt also begrenzt
+	s.lines = s.lines[drop:]
+}
+
+// restore beendet die Telnet-Sitzung im Puffer und liefert die Nachrichten,
+// die den Bildschirm von vorher wieder aufbauen. Nach dem ersten Aufruf
+// liefert es nil, weil das Ende einer Telnet-Sitzung mehrfach gemeldet wird.
+func (b *screenBuffer) restore(sessionID string) []shared.Message {
+	if !b.enabled() {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, exists := b.screens[sessionID]
+	if !exists || !s.telnet {
+		return nil
+	}
+	s.telnet = false
+	messages := make([]shared.Message, 0, len(s.lines)+1)
+	messages = append(messages, shared.Message{Type: shared.MessageTypeClear})
+	return append(messages, s.lines...)
+}
+
+// remove vergisst den Bildschirm einer beendeten Session
+func (b *screenBuffer) remove(sessionID string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	delete(b.screens, sessionID)
+	b.mu.Unlock()
+}
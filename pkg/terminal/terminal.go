@@ -47,10 +47,17 @@ type TerminalHandler struct {
 	sessionRequests map[string][]time.Time // IP -> Liste der Session-Anfrage-Zeitstempel
 	bannedIPs       map[string]time.Time   // IP -> Ban-Zeitstempel
 	rateLimitMutex  sync.Mutex             // Mutex für Rate-Limiting Maps
+	// Rate-Limiting für /api/examples
+	examplesRequests  map[string][]time.Time // IP -> Zeitstempel der Anfragen
+	examplesRateMutex sync.Mutex
 	// Sicherheits-Komponenten
 	clientManager     *ClientManager
 	jsonValidator     *JSONValidator
 	securityValidator *SecurityValidator
+	// Drosselung der BASIC-Ausgaben pro Session
+	outputLimiter *outputLimiter
+	// Gemerkter Shell-Bildschirm für die Wiederherstellung nach Telnet
+	screens *screenBuffer
 }
 
 // Client repräsentiert einen verbundenen WebSocket-Client
@@ -65,6 +72,19 @@ type Client struct {
 	lastPong  time.Time
 	sessionID string
 	shutdown  chan struct{} // Channel for graceful shutdown
+	// Strukturiertes Protokoll (?protocol=structured): Shell-Ausgaben kommen als CommandResult
+	structured bool
+	// Client stellt farbigen Text dar (?features=color)
+	color bool
+	// Neue Gast-Sessions starten ohne Beispielprogramme (?scratch=1)
+	scratch bool
+	// Zuletzt gesendetes Prompt-Symbol, leer bis zum ersten Prompt ("> " im Frontend)
+	promptSymbol string
+	// autorun.bas, das gleich nach dem Start mit einem Fehler abbrach und nicht mehr gestartet wird
+	autorunFailed string
+	// Laufende MCP-Antwort eines Chat-Clients, chatCancel bricht sie ab
+	chatMutex  sync.Mutex
+	chatCancel context.CancelFunc
 }
 
 // Send sendet eine Nachricht an den Client über den send-Kanal - DEADLOCK FIX
@@ -145,67 +165,41 @@ func NewTerminalHandler(os *tinyos.TinyOS) *TerminalHandler {
 		clients:        make(map[*Client]bool), chatClients: make(map[*Client]bool),
 		sessionRequests:   make(map[string][]time.Time), // Rate-Limiting für Session-Anfragen
 		bannedIPs:         make(map[string]time.Time),   // Gesperrte IPs
+		examplesRequests:  make(map[string][]time.Time), // Rate-Limiting für /api/examples
 		clientManager:     NewClientManager(),           // Sicherheits-Manager
 		jsonValidator:     NewJSONValidator(),           // JSON-Validator
 		securityValidator: NewSecurityValidator(),       // Security-Validator
+		outputLimiter:     newOutputLimiter(outputMessagesPerSecond),
+		screens:           newScreenBuffer(telnetRestoreLines),
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  configuration.GetInt("WebSocket", "read_buffer_size", 16384),
 			WriteBufferSize: configuration.GetInt("WebSocket", "write_buffer_size", 16384),
-			CheckOrigin: func(r *http.Request) bool {
-				// Strict Origin checking to prevent CSRF attacks
-				origin := r.Header.Get("Origin")
-
-				// Check if Origin header exists
-				if origin == "" {
-					log.Printf("WebSocket request without Origin header rejected")
-					return false
-				}
-
-				// Get allowed origins from configuration
-				allowedOriginsStr := configuration.GetString("WebSocket", "allowed_origins", "http://localhost:8080,http://127.0.0.1:8080")
-				allowedOrigins := strings.Split(allowedOriginsStr, ",")
-
-				// Trim whitespace from each origin
-				for i, origin := range allowedOrigins {
-					allowedOrigins[i] = strings.TrimSpace(origin)
-				}
-
-				// Check if origin is in the list of allowed origins
-				for _, allowed := range allowedOrigins {
-					if origin == allowed {
-						return true
-					}
-				}
-
-				// Deny access and log
-				log.Printf("WebSocket request from disallowed origin rejected: %s", origin)
-				return false
-			},
+			CheckOrigin:     checkWebSocketOrigin,
 		},
 	}
 
 	// Set the callback function for sending messages to clients
-	os.SendToClientCallback = h.clientManager.SendToClient
+	os.SendToClientCallback = h.sendToClient
+	os.RebootCallback = h.rebootSession
+	os.StopProgramCallback = h.stopSessionProgram
 	// Starte die Goroutine, um BASIC-Ausgaben zu verarbeiten
 	go h.processBasicOutput()
 
 	// Starte die Goroutine für den Ping-Mechanismus
 	go h.pingClients()
 
+	// Verlassene BASIC-Sitzungen von Gästen freigeben (0 schaltet das ab)
+	if os != nil {
+		go h.reclaimIdleGuestBasicSessions()
+	}
+
 	return h
 }
 
 // HandleWebSocket verarbeitet eingehende WebSocket-Verbindungen - SICHERHEIT ERHÖHT
 func (h *TerminalHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
-	// IP-Adresse des Clients ermitteln
-	ipAddress := r.RemoteAddr
-	forwardedFor := r.Header.Get("X-Forwarded-For")
-	if forwardedFor != "" {
-		parts := strings.Split(forwardedFor, ",")
-		if len(parts) > 0 {
-			ipAddress = strings.TrimSpace(parts[0])
-		}
-	}
+	// IP-Adresse des Clients ermitteln (X-Forwarded-For nur von vertrauenswürdigen Proxys)
+	ipAddress := tinyos.ClientIP(r)
 
 	log.Printf("[WEBSOCKET] New WebSocket connection attempt from %s", ipAddress)
 	log.Printf("[WEBSOCKET] Request details - URL: %s, Host: %s, Origin: %s", r.URL.String(), r.Host, r.Header.Get("Origin"))
@@ -217,6 +211,15 @@ func (h *TerminalHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Verbindungen pro IP begrenzen (vertrauenswürdige IPs sind ausgenommen)
+	if h.os != nil {
+		if err := h.os.CheckIPConnectionLimit(ipAddress); err != nil {
+			logger.SecurityWarn("WebSocket connection from %s rejected: %v", ipAddress, err)
+			http.Error(w, tinyos.TooManyConnectionsMessage(), http.StatusTooManyRequests)
+			return
+		}
+	}
+
 	// Prüfe Client-Limits bevor Upgrade
 	if len(h.clients) >= MaxClientsDefault {
 		log.Printf("[SECURITY] Maximale Anzahl Clients erreicht, Verbindung abgelehnt: %s", ipAddress)
@@ -260,6 +263,10 @@ func (h *TerminalHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request
 		mode:      ModeOS,
 		lastPong:  time.Now(),
 		shutdown:  make(chan struct{}),
+
+		structured: r.URL.Query().Get("protocol") == shared.ProtocolStructured,
+		color:      hasFeature(r.URL.Query().Get("features"), shared.FeatureColor),
+		scratch:    isTrueParam(r.URL.Query().Get("scratch")),
 	}
 
 	// EMERGENCY DEBUG: Client created debug
@@ -278,6 +285,16 @@ func (h *TerminalHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request
 		conn.Close()
 		return
 	}
+
+	// Verbindung zählen; bis hierher können parallele Verbindungen das Limit überschritten haben
+	if h.os != nil {
+		if err := h.os.RegisterIPConnection(client.sessionID, ipAddress); err != nil {
+			jsonMsg, _ := json.Marshal(shared.Message{Type: shared.MessageTypeText, Content: tinyos.TooManyConnectionsMessage()})
+			conn.WriteMessage(websocket.TextMessage, jsonMsg)
+			conn.Close()
+			return
+		}
+	}
 	log.Printf("[WEBSOCKET] Session established for %s - SessionID: %s", ipAddress, client.sessionID) // CRITICAL: Avoid force reset during WebSocket reconnections to prevent deadlocks
 	// The existing telnet session cleanup mechanisms will handle dead sessions
 	if h.os != nil {
@@ -307,36 +324,18 @@ func (h *TerminalHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request
 	go func() {
 		// Small delay to ensure connection is fully established
 		time.Sleep(100 * time.Millisecond) // Reduced from 1500ms to 100ms
-		welcomeMsg := shared.Message{Type: shared.MessageTypeText, Content: "Welcome to Skynet Systems"}
-		jsonMsg, err := json.Marshal(welcomeMsg)
-		if err != nil {
-			log.Printf("Error marshalling welcome message: %v", err)
-		} else {
+		fullBoot := h.os == nil || h.os.BootModeForSession(client.sessionID) == tinyos.BootFull
+		for _, msg := range h.bootBanner(client.sessionID) {
+			jsonMsg, err := json.Marshal(msg)
+			if err != nil {
+				log.Printf("Error marshalling welcome message: %v", err)
+				continue
+			}
 			h.SendToClient(client, jsonMsg)
-		}
-
-		// Anzahl der online Benutzer anzeigen
-		userCount := h.getOnlineUserCount()
-		var userCountMsg string
-		if userCount == 1 {
-			userCountMsg = "1 user online"
-		} else {
-			userCountMsg = fmt.Sprintf("%d users online", userCount)
-		}
-
-		onlineMsg := shared.Message{Type: shared.MessageTypeText, Content: userCountMsg}
-		jsonOnlineMsg, err := json.Marshal(onlineMsg)
-		if err != nil {
-			log.Printf("Error marshalling online users message: %v", err)
-		} else {
-			h.SendToClient(client, jsonOnlineMsg)
-		}
-		infoMsg := shared.Message{Type: shared.MessageTypeText, Content: "Type 'help' for help"}
-		jsonInfoMsg, err := json.Marshal(infoMsg)
-		if err != nil {
-			log.Printf("Error marshalling info message: %v", err)
-		} else {
-			h.SendToClient(client, jsonInfoMsg)
+			// Der volle Startvorgang erscheint Zeile für Zeile
+			if fullBoot && msg.Type == shared.MessageTypeText {
+				time.Sleep(bootLineDelay)
+			}
 		}
 	}()
 }
@@ -402,8 +401,7 @@ func (h *TerminalHandler) handleClientSession(client *Client, r *http.Request) e
 					}
 				} else {
 					// Restore guest session
-					_, err := h.os.CreateGuestSession(sessionID, client.ipAddress)
-					if err != nil {
+					if err := h.newGuestSession(client, sessionID); err != nil {
 						return h.createGuestSession(client)
 					}
 				}
@@ -451,6 +449,26 @@ func (h *TerminalHandler) handleClientSession(client *Client, r *http.Request) e
 	return nil
 }
 
+// newGuestSession legt im TinyOS eine Gast-Session an; Clients mit ?scratch=1
+// erhalten ein leeres Home ohne Beispielprogramme
+func (h *TerminalHandler) newGuestSession(client *Client, sessionID string) error {
+	if client.scratch {
+		_, err := h.os.CreateScratchGuestSession(sessionID, client.ipAddress)
+		return err
+	}
+	_, err := h.os.CreateGuestSession(sessionID, client.ipAddress)
+	return err
+}
+
+// isTrueParam wertet einen Query-Parameter wie 1, true oder yes als gesetzt
+func isTrueParam(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
 // createGuestSession erstellt eine neue Gast-Session mit Rate-Limiting
 func (h *TerminalHandler) createGuestSession(client *Client) error {
 	// Rate-Limiting prüfen
@@ -460,11 +478,12 @@ func (h *TerminalHandler) createGuestSession(client *Client) error {
 
 	// Neue Gast-Session erstellen
 	guestSessionID := tinyos.GenerateSessionID()
-	_, err := h.os.CreateGuestSession(guestSessionID, client.ipAddress)
-	if err != nil {
+	if err := h.newGuestSession(client, guestSessionID); err != nil {
 		return fmt.Errorf("failed to create guest session: %w", err)
 	}
 
+	// Eine bereits gezählte Verbindung wechselt zur neuen Session
+	h.os.MoveIPConnections(client.sessionID, guestSessionID)
 	client.sessionID = guestSessionID
 
 	// Session-Info an Client senden
@@ -627,7 +646,7 @@ func (h *TerminalHandler) ProcessInput(input string, sessionID string) {
 		// Normale Befehle über TinyOS ausführen
 		// WICHTIG: Erstelle Kontext mit der SessionID statt context.Background()!
 		ctx := auth.NewContextWithSessionID(context.Background(), sessionID)
-		responses := h.os.ExecuteWithContext(ctx, input)
+		responses := h.os.ExecuteWithTimeout(ctx, input)
 
 		// Antworten verarbeiten und senden
 		for _, msg := range responses {
@@ -659,7 +678,7 @@ func (h *TerminalHandler) ProcessInputWithSession(input string, sessionID string
 	ctx := auth.NewContextWithSessionID(context.Background(), sessionID)
 	log.Printf("[DEBUG-TERMINAL] About to call ExecuteWithContext with sessionID: '%s', input: '%s'", sessionID, input)
 	// Verwende TinyOS für die Verarbeitung mit dem korrekten Kontext
-	result := h.os.ExecuteWithContext(ctx, input)
+	result := h.os.ExecuteWithTimeout(ctx, input)
 	log.Printf("[DEBUG-TERMINAL] ExecuteWithContext returned %d messages", len(result))
 	return result
 }
@@ -669,7 +688,7 @@ func (h *TerminalHandler) ProcessMessageFromClient(msg *shared.Message, sessionI
 	// Erstelle einen Kontext mit der SessionID
 	ctx := auth.NewContextWithSessionID(context.Background(), sessionID)
 	// Input mit TinyOS verarbeiten
-	return h.os.ExecuteWithContext(ctx, msg.Content)
+	return h.os.ExecuteWithTimeout(ctx, msg.Content)
 }
 
 // ProcessBasicInput verarbeitet Eingaben im BASIC-Modus
@@ -733,6 +752,9 @@ func (h *TerminalHandler) ProcessBasicInput(client *Client, input string) {
 			}
 		}
 	}
+	if h.os != nil {
+		h.os.TouchBasicSession(client.sessionID)
+	}
 	// Die Terminal-Dimensionen an den BASIC-Interpreter übergeben
 	basic.SetTerminalDimensions(client.cols, client.rows) // Spezialbefehl __BREAK__ zur Beendigung des BASIC-Programms
 	log.Printf("[DEBUG-BREAK-CHECK] Checking input: '%s', length: %d", input, len(input))
@@ -867,66 +889,7 @@ func (h *TerminalHandler) startAutorun(client *Client, filename string) {
 	// Get the session-specific TinyBASIC instance
 	basic := h.getBasicInstance(client.sessionID) // Set callback to return to TinyOS when program ends
 	basic.SetOnProgramEnd(func() {
-		// Clean up graphics and restore text mode
-		clearMsg := shared.Message{Type: shared.MessageTypeClear, Command: "CLS"}
-		jsonClearMsg, _ := json.Marshal(clearMsg)
-		h.SendToClient(client, jsonClearMsg)
-		// Clear any remaining graphics
-		clearGfxMsg := shared.Message{
-			Type:    shared.MessageTypeGraphics,
-			Command: "CLEAR_GRAPHICS",
-		}
-		jsonClearGfxMsg, _ := json.Marshal(clearGfxMsg)
-		h.SendToClient(client, jsonClearGfxMsg)
-		// Explicitly stop any music to ensure clean sound state for next autorun
-		musicStopMsg := shared.Message{
-			Type: shared.MessageTypeSound,
-			Params: map[string]interface{}{
-				"action": "music_stop",
-			},
-		}
-		jsonMusicStopMsg, _ := json.Marshal(musicStopMsg)
-		h.SendToClient(client, jsonMusicStopMsg)
-
-		// Small delay to ensure frontend processes the stop command
-		time.Sleep(100 * time.Millisecond)
-
-		// Send an additional sound reset to ensure clean state
-		soundResetMsg := shared.Message{
-			Type: shared.MessageTypeSound,
-			Params: map[string]interface{}{
-				"action": "reset",
-			},
-		}
-		jsonSoundResetMsg, _ := json.Marshal(soundResetMsg)
-		h.SendToClient(client, jsonSoundResetMsg)
-
-		// Clean up the BASIC instance to ensure fresh state for next autorun
-		h.cleanupBasicInstanceForAutorun(client.sessionID)
-
-		// Switch back to OS mode
-		client.mode = ModeOS
-
-		// End BASIC session
-		if h.os != nil {
-			h.os.EndBasicSession(client.sessionID)
-			log.Printf("[BASIC-SESSION] Session %s exited BASIC mode after autorun, cleaned up session tracking", client.sessionID)
-		}
-
-		// Send mode switch message to frontend
-		modeMsg := shared.Message{Type: shared.MessageTypeMode, Content: "os"}
-		jsonModeMsg, _ := json.Marshal(modeMsg)
-		h.SendToClient(client, jsonModeMsg)
-
-		// Send confirmation message
-		confirmMsg := shared.Message{Type: shared.MessageTypeText, Content: "Program completed. Back to TinyOS."}
-		jsonConfirmMsg, _ := json.Marshal(confirmMsg)
-		h.SendToClient(client, jsonConfirmMsg)
-
-		// Re-enable input
-		enableInputMsg := shared.Message{Type: shared.MessageTypeInputControl, Content: "enable"}
-		jsonEnableMsg, _ := json.Marshal(enableInputMsg)
-		h.SendToClient(client, jsonEnableMsg)
+		h.returnToOSAfterProgram(client, "Program completed. Back to TinyOS.")
 	})
 	// Execute LOAD command first - filename must be quoted for TinyBASIC
 	loadCmd := fmt.Sprintf(`LOAD "%s"`, filename)
@@ -967,6 +930,71 @@ func (h *TerminalHandler) startAutorun(client *Client, filename string) {
 	// Programs that need user input (like invaders.bas) will handle input control automatically
 }
 
+// returnToOSAfterProgram räumt nach einem automatisch gestarteten Programm auf
+// (Grafik, Sound, BASIC-Instanz) und schaltet den Client zurück in den OS-Modus.
+func (h *TerminalHandler) returnToOSAfterProgram(client *Client, confirmText string) {
+	// Clean up graphics and restore text mode
+	clearMsg := shared.Message{Type: shared.MessageTypeClear, Command: "CLS"}
+	jsonClearMsg, _ := json.Marshal(clearMsg)
+	h.SendToClient(client, jsonClearMsg)
+	// Clear any remaining graphics
+	clearGfxMsg := shared.Message{
+		Type:    shared.MessageTypeGraphics,
+		Command: "CLEAR_GRAPHICS",
+	}
+	jsonClearGfxMsg, _ := json.Marshal(clearGfxMsg)
+	h.SendToClient(client, jsonClearGfxMsg)
+	// Explicitly stop any music to ensure clean sound state for next autorun
+	musicStopMsg := shared.Message{
+		Type: shared.MessageTypeSound,
+		Params: map[string]interface{}{
+			"action": "music_stop",
+		},
+	}
+	jsonMusicStopMsg, _ := json.Marshal(musicStopMsg)
+	h.SendToClient(client, jsonMusicStopMsg)
+
+	// Small delay to ensure frontend processes the stop command
+	time.Sleep(100 * time.Millisecond)
+
+	// Send an additional sound reset to ensure clean state
+	soundResetMsg := shared.Message{
+		Type: shared.MessageTypeSound,
+		Params: map[string]interface{}{
+			"action": "reset",
+		},
+	}
+	jsonSoundResetMsg, _ := json.Marshal(soundResetMsg)
+	h.SendToClient(client, jsonSoundResetMsg)
+
+	// Clean up the BASIC instance to ensure fresh state for next autorun
+	h.cleanupBasicInstanceForAutorun(client.sessionID)
+
+	// Switch back to OS mode
+	client.mode = ModeOS
+
+	// End BASIC session
+	if h.os != nil {
+		h.os.EndBasicSession(client.sessionID)
+		log.Printf("[BASIC-SESSION] Session %s exited BASIC mode after autorun, cleaned up session tracking", client.sessionID)
+	}
+
+	// Send mode switch message to frontend
+	modeMsg := shared.Message{Type: shared.MessageTypeMode, Content: "os"}
+	jsonModeMsg, _ := json.Marshal(modeMsg)
+	h.SendToClient(client, jsonModeMsg)
+
+	// Send confirmation message
+	confirmMsg := shared.Message{Type: shared.MessageTypeText, Content: confirmText}
+	jsonConfirmMsg, _ := json.Marshal(confirmMsg)
+	h.SendToClient(client, jsonConfirmMsg)
+
+	// Re-enable input
+	enableInputMsg := shared.Message{Type: shared.MessageTypeInputControl, Content: "enable"}
+	jsonEnableMsg, _ := json.Marshal(enableInputMsg)
+	h.SendToClient(client, jsonEnableMsg)
+}
+
 // cleanupBasicInstanceForAutorun removes the TinyBASIC instance for a session after autorun
 // This ensures a clean state for the next autorun execution
 func (h *TerminalHandler) cleanupBasicInstanceForAutorun(sessionID string) {
@@ -992,6 +1020,7 @@ func (h *TerminalHandler) cleanupBasicInstanceForAutorun(sessionID string) {
 
 		// Remove the instance so a fresh one is created next time
 		delete(h.basicInstances, sessionID)
+		h.outputLimiter.remove(sessionID)
 		log.Printf("[BASIC-AUTORUN] Cleaned up BASIC instance for session %s after autorun", sessionID)
 	}
 }
@@ -1028,12 +1057,28 @@ func (h *TerminalHandler) processAllBasicOutputs() {
 // processBasicOutputForSession verarbeitet Ausgaben für eine spezifische Session
 func (h *TerminalHandler) processBasicOutputForSession(sessionID string, basic *tinybasic.TinyBASIC) {
 	outputChan := basic.GetOutputChannel()
+	if len(outputChan) == 0 {
+		return
+	}
+	// Wartet das Programm auf INPUT, wird nicht gedrosselt
+	interactive := basic.IsWaitingForInput()
+
 	// Verarbeite maximal 100 Nachrichten pro Aufruf um Backlog zu vermeiden
 	messagesProcessed := 0
 	maxMessagesPerCycle := 100
 
 	// Nicht-blockierendes Lesen von Nachrichten
-	for messagesProcessed < maxMessagesPerCycle {
+	for messagesProcessed < maxMessagesPerCycle && len(outputChan) > 0 {
+		if !interactive {
+			allowed, notice := h.outputLimiter.allow(sessionID)
+			if notice {
+				h.sendBasicOutputToClient(sessionID, shared.Message{Type: shared.MessageTypeText, Content: outputThrottledNotice})
+			}
+			if !allowed {
+				// Nachrichten bleiben im Kanal; läuft er voll, pausiert das Programm
+				return
+			}
+		}
 		select {
 		case msg := <-outputChan:
 			h.sendBasicOutputToClient(sessionID, msg)
@@ -1077,6 +1122,7 @@ func (h *TerminalHandler) sendBasicOutputToClient(sessionID string, msg shared.M
 	// Nachricht senden außerhalb des Mutex
 	if targetClient != nil {
 		targetClient.Send(jsonMsg)
+		h.screens.record(sessionID, msg)
 	} else {
 		log.Printf("[CRITICAL-WARNING] No BASIC client found for session %s (checked %d clients total)", sessionID, clientsChecked)
 	}
@@ -1190,23 +1236,113 @@ func (h *TerminalHandler) SendMessagesToClient(client *Client, messages []shared
 		}
 
 		h.SendToClient(client, jsonMsg)
-		// Check for mode change messages
-		if message.Type == shared.MessageTypeMode {
-			if message.Content == "basic" {
-				client.mode = "basic"
-			} else if strings.HasPrefix(message.Content, "basic-autorun:") {
-				// Extract filename from basic-autorun:filename
-				filename := strings.TrimPrefix(message.Content, "basic-autorun:")
-				client.mode = "basic"
-
-				// Start autorun for this client's BASIC instance
-				go h.startAutorun(client, filename)
-			} else if message.Content == "os" {
-				client.mode = "os"
+		h.trackModeChange(client, message)
+		h.screens.record(client.sessionID, message)
+		// Moduswechsel können auch im Ergebnis-Umschlag stecken
+		if message.Result != nil {
+			for _, inner := range message.Result.Output {
+				h.trackModeChange(client, inner)
+				h.screens.record(client.sessionID, inner)
+			}
+		}
+	}
+
+	// autorun.bas erst nach den Startmeldungen von BASIC ausführen
+	if autorun := autorunRequest(messages); autorun != "" {
+		go h.runUserAutorun(client, autorun)
+	}
+}
+
+// trackModeChange übernimmt einen Moduswechsel aus einer gesendeten Nachricht
+func (h *TerminalHandler) trackModeChange(client *Client, message shared.Message) {
+	if message.Type != shared.MessageTypeMode {
+		return
+	}
+	if message.Content == "basic" {
+		client.mode = "basic"
+	} else if strings.HasPrefix(message.Content, "basic-autorun:") {
+		// Extract filename from basic-autorun:filename
+		filename := strings.TrimPrefix(message.Content, "basic-autorun:")
+		client.mode = "basic"
+
+		// Start autorun for this client's BASIC instance
+		go h.startAutorun(client, filename)
+	} else if message.Content == "os" {
+		client.mode = "os"
+	}
+}
+
+// sendCommandResult sendet die Ausgabe eines Shell-Befehls. Clients mit
+// strukturiertem Protokoll erhalten sie als CommandResult mit Status und
+// Fehlercode, alle anderen unverändert. Clients ohne Farbdarstellung
+// erhalten den Text ohne Farbangaben.
+func (h *TerminalHandler) sendCommandResult(client *Client, command string, messages []shared.Message) {
+	if !client.color {
+		messages = withoutColor(messages)
+	}
+	if !client.structured {
+		h.SendMessagesToClient(client, messages)
+	} else {
+		h.SendMessagesToClient(client, []shared.Message{shared.NewResultMessage(command, messages)})
+	}
+	h.refreshPrompt(client, messages)
+}
+
+// refreshPrompt rendert nach einem Shell-Befehl das Prompt der Session neu,
+// da sich Pfad und Uhrzeit darin ändern können. Gesendet wird es nur, wenn
+// es sich geändert hat und der Befehl keinen eigenen Prompt oder Modus setzt.
+func (h *TerminalHandler) refreshPrompt(client *Client, messages []shared.Message) {
+	for _, msg := range messages {
+		switch msg.Type {
+		case shared.MessageTypePrompt:
+			if msg.PromptSymbol != "" {
+				client.promptSymbol = msg.PromptSymbol
 			}
+			return
+		case shared.MessageTypeMode, shared.MessageTypeInputControl, shared.MessageTypeEditor,
+			shared.MessageTypePager, shared.MessageTypeTelnet, shared.MessageTypeChat:
+			return
+		}
+	}
+	if h.os == nil || client.mode != ModeOS {
+		return
+	}
+	symbol := h.os.GetPromptForSession(client.sessionID)
+	current := client.promptSymbol
+	if current == "" {
+		current = "> "
+	}
+	if symbol == current {
+		return
+	}
+	client.promptSymbol = symbol
+	h.SendMessagesToClient(client, []shared.Message{{Type: shared.MessageTypePrompt, PromptSymbol: symbol}})
+}
+
+// hasFeature prüft, ob die kommagetrennte Liste features das Merkmal name enthält
+func hasFeature(features, name string) bool {
+	for _, feature := range strings.Split(features, ",") {
+		if strings.EqualFold(strings.TrimSpace(feature), name) {
+			return true
 		}
 	}
+	return false
+}
 
+// withoutColor liefert die Nachrichten ohne Farbangaben; die Eingabe bleibt unverändert
+func withoutColor(messages []shared.Message) []shared.Message {
+	for i, msg := range messages {
+		if msg.Color == 0 {
+			continue
+		}
+		plain := make([]shared.Message, len(messages))
+		copy(plain, messages)
+		for j := i; j < len(plain); j++ {
+			plain[j].Color = 0
+		}
+		return plain
+	}
+	return messages
 }
 
 // Broadcast sendet eine Nachricht an alle verbundenen Terminal-Clients
@@ -1227,12 +1363,11 @@ func (h *TerminalHandler) BroadcastChat(message []byte) {
 	}
 }
 
-// pingClients sendet alle 30 Sekunden einen Ping an alle verbundenen Clients
-// und überprüft, ob sie innerhalb von 10 Sekunden antworten
+// pingClients prüft im Ping-Abstand, ob alle Clients noch Pongs senden. Das
+// ist nur ein Rückfallnetz zur Lesefrist in readPump: Clients, die länger als
+// pong_timeout plus einen Ping-Abstand stumm sind, werden getrennt.
 func (h *TerminalHandler) pingClients() {
-	// Verwende einen längeren Ping-Intervall von 50 Sekunden anstatt 30 Sekunden
-	// Dies verhindert Konflikte mit dem pingPeriod in websocket.go
-	ticker := time.NewTicker(50 * time.Second)
+	ticker := time.NewTicker(getPingPeriod())
 	defer ticker.Stop()
 	for range ticker.C {
 		h.mutex.Lock()
@@ -1245,17 +1380,14 @@ func (h *TerminalHandler) pingClients() {
 		}
 		h.mutex.Unlock()
 		// Check each client outside the mutex lock to avoid blocking
+		staleAfter := getPongWait() + getPingPeriod()
 		for _, client := range clientsToCheck {
-			// Use the lastPong time from the client without overriding it
-			// The pong handler in readPump already updates lastPong
-
-			// Updated: Use the new timeout value from configuration (120 seconds + buffer)
-			// This ensures that a client is not prematurely marked as disconnected
-			if time.Since(client.lastPong) > 130*time.Second { // Slightly higher than config pong_timeout
-				logger.Warn(logger.AreaWebSocket, "No pong response from client %s for more than 130 seconds, disconnecting", client.conn.RemoteAddr())
-				h.mutex.Lock()
-				h.cleanupClient(client)
-				h.mutex.Unlock()
+			// The pong handler in readPump updates lastPong
+			if time.Since(client.lastPong) > staleAfter {
+				logger.Warn(logger.AreaWebSocket, "No pong response from client %s for more than %v, disconnecting", client.conn.RemoteAddr(), staleAfter)
+				// Closing the connection ends readPump, which cleans up the
+				// client and its session resources exactly once
+				client.conn.Close()
 			}
 		}
 	}
@@ -1283,6 +1415,7 @@ func (h *TerminalHandler) cleanupClientUnsafe(client *Client) {
 
 	// Client-Manager informieren
 	h.clientManager.RemoveClient(client.sessionID)
+	h.screens.remove(client.sessionID)
 	// BASIC-Programm beenden falls aktiv
 	if client.mode == "basic" {
 		logger.Debug(logger.AreaTerminal, "Client %s disconnected, stopping BASIC program for session %s",
@@ -1344,6 +1477,7 @@ func (h *TerminalHandler) cleanupBasicInstanceUnsafe(sessionID string) {
 		}()
 
 		delete(h.basicInstances, sessionID)
+		h.outputLimiter.remove(sessionID)
 	}
 
 	// BASIC Session aus Tracking entfernen
@@ -1366,6 +1500,11 @@ func (h *TerminalHandler) getBasicInstance(sessionID string) *tinybasic.TinyBASI
 	// Erstelle eine neue TinyBASIC-Instanz für diese Session
 	basic := tinybasic.NewTinyBASIC(h.os)
 	basic.SetSessionID(sessionID)
+	basic.SetPrintZoneWidth(configuration.GetInt("BasicPrograms", "print_zone_width", tinybasic.DefaultPrintZoneWidth))
+	basic.SetMaxFileLineLength(configuration.GetInt("BasicPrograms", "max_file_line_length", tinybasic.DefaultMaxFileLineLength))
+	basic.SetStepBudgets(
+		configuration.GetInt("BasicPrograms", "max_steps_guest", tinybasic.DefaultMaxStepsGuest),
+		configuration.GetInt("BasicPrograms", "max_steps_user", tinybasic.DefaultMaxStepsUser))
 	h.basicInstances[sessionID] = basic
 	h.mutex.Unlock() // WICHTIG: Mutex früh freigeben!
 
@@ -1395,6 +1534,7 @@ func (h *TerminalHandler) cleanupBasicInstance(sessionID string) {
 		}()
 
 		delete(h.basicInstances, sessionID)
+		h.outputLimiter.remove(sessionID)
 
 	}
 
@@ -1598,6 +1738,88 @@ func (h *TerminalHandler) sendWebSocketMessage(msg shared.Message, sessionID str
 	}
 }
 
+// bootSequence sind die Startmeldungen des vollen Startvorgangs (boot full)
+var bootSequence = []string{
+	"SKYNET SYSTEMS BIOS V1.0",
+	"MEMORY TEST: 65536K OK",
+	"DETECTING DRIVES... DONE",
+	"LOADING TINYOS...",
+	"",
+}
+
+// bootLineDelay ist die Pause zwischen den Zeilen des vollen Startvorgangs beim Verbinden
+const bootLineDelay = 150 * time.Millisecond
+
+// bootBanner liefert die Begrüßung, die nach dem Verbinden und nach reboot angezeigt wird.
+// Benutzer mit "boot fast" erhalten nur die kurze Begrüßung ohne Startmeldungen.
+func (h *TerminalHandler) bootBanner(sessionID string) []shared.Message {
+	var messages []shared.Message
+	fullBoot := true
+	if h.os != nil {
+		messages = append(messages, h.os.ThemeMessageForSession(sessionID))
+		fullBoot = h.os.BootModeForSession(sessionID) == tinyos.BootFull
+	}
+
+	// Anzahl der online Benutzer anzeigen
+	userCount := h.getOnlineUserCount()
+	userCountMsg := fmt.Sprintf("%d users online", userCount)
+	if userCount == 1 {
+		userCountMsg = "1 user online"
+	}
+
+	if !fullBoot {
+		return append(messages,
+			shared.Message{Type: shared.MessageTypeText, Content: "Welcome back to Skynet Systems"},
+			shared.Message{Type: shared.MessageTypeText, Content: userCountMsg})
+	}
+	for _, line := range bootSequence {
+		messages = append(messages, shared.Message{Type: shared.MessageTypeText, Content: line})
+	}
+	return append(messages,
+		shared.Message{Type: shared.MessageTypeText, Content: "Welcome to Skynet Systems"},
+		shared.Message{Type: shared.MessageTypeText, Content: userCountMsg},
+		shared.Message{Type: shared.MessageTypeText, Content: "Type 'help' for help"})
+}
+
+// rebootSession verwirft die BASIC-Instanz einer Session für den reboot-Befehl
+func (h *TerminalHandler) rebootSession(sessionID string) []shared.Message {
+	h.cleanupBasicInstance(sessionID)
+	return h.bootBanner(sessionID)
+}
+
+// stopSessionProgram bricht das laufende BASIC-Programm einer Session ab, wie
+// __BREAK__ in deren Tab. Wird vom kill-Befehl einer anderen Session aufgerufen.
+func (h *TerminalHandler) stopSessionProgram(sessionID string) bool {
+	h.mutex.Lock()
+	basic, exists := h.basicInstances[sessionID]
+	h.mutex.Unlock()
+	if !exists || !basic.IsRunning() {
+		return false
+	}
+	for _, msg := range basic.StopExecution() {
+		h.sendWebSocketMessage(msg, sessionID)
+	}
+	return true
+}
+
+// sendToClient ist der direkte Weg von TinyOS zum Client. Endet eine
+// Telnet-Sitzung, folgt auf das "end" der Shell-Bildschirm von vorher.
+func (h *TerminalHandler) sendToClient(sessionID string, message shared.Message) error {
+	var restored []shared.Message
+	if message.Type == shared.MessageTypeTelnet && message.Content == "end" {
+		restored = h.screens.restore(sessionID)
+	}
+	if err := h.clientManager.SendToClient(sessionID, message); err != nil {
+		return err
+	}
+	for _, msg := range restored {
+		if err := h.clientManager.SendToClient(sessionID, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // getOnlineUserCount zählt die Anzahl der einzigartigen online Benutzer
 func (h *TerminalHandler) getOnlineUserCount() int {
 	h.mutex.Lock()
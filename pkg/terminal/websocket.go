@@ -14,6 +14,8 @@ import (
 	"github.com/antibyte/retroterm/pkg/editor"
 	"github.com/antibyte/retroterm/pkg/logger"
 	"github.com/antibyte/retroterm/pkg/shared"
+	"github.com/antibyte/retroterm/pkg/tinybasic"
+	"github.com/antibyte/retroterm/pkg/tinyos"
 
 	"github.com/gorilla/websocket"
 )
@@ -30,9 +32,16 @@ func getPongWait() time.Duration {
 	return configuration.GetDuration("Network", "pong_timeout", 90*time.Second)
 }
 
+// getPingPeriod liefert den Abstand zwischen zwei Pings. Ohne ping_interval
+// oder bei einem Wert, der nicht unter pong_timeout liegt, gilt 9/10 von
+// pong_timeout, damit der Pong vor Ablauf der Lesefrist eintreffen kann.
 func getPingPeriod() time.Duration {
 	pongWait := getPongWait()
-	return (pongWait * 9) / 10
+	interval := configuration.GetDuration("Network", "ping_interval", 0)
+	if interval <= 0 || interval >= pongWait {
+		return (pongWait * 9) / 10
+	}
+	return interval
 }
 
 func getMaxMessageSize() int64 {
@@ -54,14 +63,22 @@ func getMaxMessagesPerSecond() int {
 // Chat-spezifische Konstanten
 const (
 	chatRoleSystem       = "system"
-	chatCmdExit          = "exit" // ChatResponseTypeText entspricht shared.MessageTypeText
-	ChatResponseTypeText = 0      // Normal text
+	chatRoleAI           = "ai"
+	chatRequestCancel    = "cancel" // Bricht die laufende Antwort ab
+	chatCmdExit          = "exit"   // ChatResponseTypeText entspricht shared.MessageTypeText
+	ChatResponseTypeText = 0        // Normal text
 	// ChatResponseTypeBeep entspricht shared.MessageTypeBeep
 	ChatResponseTypeBeep = 2 // Beep sound
 	// ChatResponseTypeSpeak entspricht shared.MessageTypeSpeak
 	ChatResponseTypeSpeak = 3 // TTS output
 	// ChatResponseTypeEvil entspricht shared.MessageTypeEvil
 	ChatResponseTypeEvil = 26 // Evil effect - dramatic noise increase for MCP
+	// ChatResponseTypeChunk ist ein Stück einer gestreamten Antwort, das an
+	// die aktuelle Zeile angehängt wird
+	ChatResponseTypeChunk = 40
+	// ChatResponseTypeDone beendet eine gestreamte Antwort, Content ist
+	// "cancelled" bei Abbruch
+	ChatResponseTypeDone = 41
 )
 
 var newline = []byte{'\n'} // Korrigiert: Zeilenumbruch für WebSocket-Nachrichten
@@ -89,6 +106,26 @@ func convertKeyToBasicFormat(jsKey string) string {
 		return " " // Leertaste
 	case "Tab":
 		return "\t" // Tab-Taste
+	case "F1":
+		return tinybasic.KeyF1 // Funktionstasten für KEY und ON KEY
+	case "F2":
+		return tinybasic.KeyF2
+	case "F3":
+		return tinybasic.KeyF3
+	case "F4":
+		return tinybasic.KeyF4
+	case "F5":
+		return tinybasic.KeyF5
+	case "F6":
+		return tinybasic.KeyF6
+	case "F7":
+		return tinybasic.KeyF7
+	case "F8":
+		return tinybasic.KeyF8
+	case "F9":
+		return tinybasic.KeyF9
+	case "F10":
+		return tinybasic.KeyF10
 	default:
 		// Für normale Zeichen, verwende das Zeichen direkt
 		if len(jsKey) == 1 {
@@ -127,15 +164,10 @@ type ChatResponse struct {
 
 // HandleChatWebSocket handles WebSocket connections for chat mode - ENHANCED SECURITY
 func (h *TerminalHandler) HandleChatWebSocket(w http.ResponseWriter, r *http.Request) {
-	// Get client IP address
-	ipAddress := r.RemoteAddr
-	forwardedFor := r.Header.Get("X-Forwarded-For")
-	if forwardedFor != "" {
-		parts := strings.Split(forwardedFor, ",")
-		if len(parts) > 0 {
-			ipAddress = strings.TrimSpace(parts[0])
-		}
-	} // Check if IP is banned
+	// Get client IP address (X-Forwarded-For only from trusted proxies)
+	ipAddress := tinyos.ClientIP(r)
+
+	// Check if IP is banned
 	if h.isIPBanned(ipAddress) {
 		logger.SecurityWarn("Chat connection from banned IP rejected: %s", ipAddress)
 		http.Error(w, "Access denied", http.StatusForbidden)
@@ -367,6 +399,8 @@ func (h *TerminalHandler) cleanupChatClient(client *Client) {
 // chatReadPump liest Nachrichten vom Chat-WebSocket
 func (h *TerminalHandler) chatReadPump(client *Client) {
 	defer func() {
+		// Eine laufende Antwort wird mit der Verbindung abgebrochen
+		client.cancelChatResponse()
 		h.cleanupChatClient(client)
 	}()
 	client.conn.SetReadLimit(getMaxMessageSize()) // Konfigurierbare Nachrichtengröße
@@ -503,6 +537,14 @@ func (h *TerminalHandler) chatReadPump(client *Client) {
 			continue
 		}
 
+		// Abbruch der laufenden Antwort (Ctrl+C im Chat)
+		if request.Type == chatRequestCancel {
+			if client.cancelChatResponse() {
+				log.Printf("[CHAT] User %s cancelled the MCP response", h.os.GetUsernameForSession(client.sessionID))
+			}
+			continue
+		}
+
 		// SICHERHEIT: Additional guest user check for chat messages
 		username := h.os.GetUsernameForSession(client.sessionID)
 		if username == "guest" || strings.HasPrefix(username, "guest-") {
@@ -569,6 +611,16 @@ func (h *TerminalHandler) chatReadPump(client *Client) {
 		isRateLimitExempt := strings.TrimSpace(request.Content) == "" ||
 			strings.ToLower(request.Content) == chatCmdExit
 
+		// Es läuft immer nur eine Antwort, die nächste Frage wartet auf sie
+		if !isRateLimitExempt && client.chatResponseRunning() {
+			sendChatResponse(client, ChatResponse{
+				Role:  chatRoleSystem,
+				Error: "MCP is still answering. Press Ctrl+C to cancel.",
+				Type:  ChatResponseTypeText,
+			})
+			continue
+		}
+
 		if !isRateLimitExempt {
 			// SICHERHEIT: Rate-Limiting auch für Chat
 			if err := h.clientManager.CheckRateLimit(client.ipAddress); err != nil {
@@ -585,6 +637,34 @@ func (h *TerminalHandler) chatReadPump(client *Client) {
 				time.Sleep(1 * time.Second)
 				continue
 			}
+
+			// Chat-Rate-Limit und Zeitlimits gelten für jede Frage, nicht nur beim Verbinden
+			isLimited, shouldBan, _ := h.os.CheckChatRateLimit(username, client.ipAddress)
+			if shouldBan {
+				h.os.BanUserAndIP(username, client.ipAddress, 24*time.Hour)
+				sendChatResponse(client, ChatResponse{
+					Role:  chatRoleSystem,
+					Error: "You have been banned for 24 hours due to abuse.",
+					Type:  ChatResponseTypeText,
+				})
+				break
+			}
+			if isLimited {
+				sendChatResponse(client, ChatResponse{
+					Role:  chatRoleSystem,
+					Error: "Chat rate limit exceeded. Please try again later.",
+					Type:  ChatResponseTypeText,
+				})
+				continue
+			}
+			if timeLimited, limitMsg := h.os.CheckChatTimeLimits(username); timeLimited {
+				sendChatResponse(client, ChatResponse{
+					Role:  chatRoleSystem,
+					Error: limitMsg,
+					Type:  ChatResponseTypeText,
+				})
+				continue
+			}
 		}
 
 		if strings.TrimSpace(request.Content) == "" {
@@ -608,36 +688,7 @@ func (h *TerminalHandler) chatReadPump(client *Client) {
 		chatUsername := h.os.GetUsernameForSession(client.sessionID)
 		log.Printf("[CHAT-AUDIT] User %s from IP %s sent message (length: %d)", chatUsername, client.ipAddress, len(request.Content))
 
-		messages := h.os.AskDeepSeek(sanitizedContent, client.sessionID)
-
-		for _, msg := range messages {
-			response := ChatResponse{
-				Role:    "ai", // "ai" könnte auch eine Konstante sein
-				Content: msg.Content,
-			}
-			switch msg.Type {
-			case shared.MessageTypeBeep: // Korrigiert von MessageTypeSound für Beep
-				response.Type = ChatResponseTypeBeep
-				response.Content = "" // Content für Beep leer lassen
-			case shared.MessageTypeSpeak: // Korrigiert von MessageTypeSay
-				response.Type = ChatResponseTypeSpeak
-				// Im Chat-Modus keine speechId erforderlich, da keine SAY_DONE-Kommunikation
-			case shared.MessageTypeEvil: // MCP Evil effect - dramatic noise increase
-				response.Type = ChatResponseTypeEvil
-				response.Content = "" // Content für Evil effect leer lassen
-			// Optional: Behandlung für shared.MessageTypeSound (nicht-Beep)
-			// case shared.MessageTypeSound:
-			// response.Type = ChatResponseTypeText // Oder eine andere spezifische Behandlung
-			// response.Content = "[Sound played]" // Oder msg.Content, falls es Text ist
-			default: // shared.MessageTypeText und andere
-				response.Type = ChatResponseTypeText
-			}
-			jsonMsg, err := json.Marshal(response)
-			if err != nil {
-				continue
-			}
-			client.Send(jsonMsg)
-		}
+		h.startChatResponse(client, sanitizedContent)
 	}
 }
 
@@ -686,6 +737,10 @@ func (c *Client) readPump() {
 		logger.Debug(logger.AreaTerminal, "Received pong from client %s", c.conn.RemoteAddr())
 		c.conn.SetReadDeadline(time.Now().Add(getPongWait()))
 		c.lastPong = time.Now()
+		// Eine offene, aber stille Verbindung soll die Session nicht ablaufen lassen
+		if c.sessionID != "" {
+			c.handler.os.UpdateSessionActivity(c.sessionID)
+		}
 		return nil
 	})
 
@@ -719,6 +774,12 @@ func (c *Client) readPump() {
 					logger.Debug(logger.AreaTerminal, "DEBUG-KEEPALIVE Received keepalive from client %s", c.conn.RemoteAddr())
 					// Keepalive-Nachrichten verwerfen - keine weitere Verarbeitung nötig
 					continue
+				}
+				// Antwort auf einen ping: vor Validierung und Rate-Limiting auswerten
+				if msgType, ok := testJSON["type"].(float64); ok && int(msgType) == int(shared.MessageTypePing) {
+					token, _ := testJSON["content"].(string)
+					c.handler.handlePingReply(c, token)
+					continue
 				} // SICHERHEIT: JSON-Validierung nur im Terminal-Modus, NICHT im BASIC-Modus oder Telnet-Modus
 				// BASIC-Kommandos und Telnet-Eingaben sollen nicht durch die Sicherheitsvalidierung blockiert werden				// Check if this is a telnet session to bypass sanitization
 				isTelnetSession := c.handler.os.IsTelnetSessionActive(c.sessionID)
@@ -889,6 +950,7 @@ func (c *Client) readPump() {
 					}
 
 					// Update session ID after successful transfer/addition
+					c.handler.os.MoveIPConnections(oldSessionID, request.SessionID)
 					c.sessionID = request.SessionID
 					logger.Debug(logger.AreaTerminal, "Session ID updated for client %s: %s", c.ipAddress, c.sessionID)
 				} else {
@@ -914,6 +976,7 @@ func (c *Client) readPump() {
 						key := convertKeyToBasicFormat(request.Key)
 						basicInstance.SetKeyPressed(key)
 					}
+					c.handler.os.TouchBasicSession(c.sessionID)
 				}
 				// Note: Pager input is handled in main text input processing, not here
 				continue
@@ -1078,7 +1141,7 @@ func (c *Client) readPump() {
 						log.Printf("[EDITOR-WEBSOCKET] Editor was closed by command")
 						// Process output again for stop command
 						c.handler.processEditorOutputForSession(c.sessionID, activeEditor.GetOutputChannel()) // Editor has ended - cleanup
-						editor.GetEditorManager().CloseEditor(c.sessionID)
+						c.handler.finishEditor(c, activeEditor)
 					} else {
 						log.Printf("[EDITOR-WEBSOCKET] Processing editor output...")
 						// Process editor output
@@ -1089,7 +1152,7 @@ func (c *Client) readPump() {
 					// Text-Input für Editor
 					if !activeEditor.ProcessInput(input) {
 						// Editor wurde beendet - cleanup
-						editor.GetEditorManager().CloseEditor(c.sessionID)
+						c.handler.finishEditor(c, activeEditor)
 					} else {
 						// Editor-Ausgaben verarbeiten
 						c.handler.processEditorOutputForSession(c.sessionID, activeEditor.GetOutputChannel())
@@ -1099,11 +1162,12 @@ func (c *Client) readPump() {
 			} else {
 				// PRIORITY 4: Normal terminal mode
 				// For normal terminal sessions, use the standard processing
+				c.handler.screens.recordInput(c.sessionID, input)
 				messages = c.handler.ProcessInputWithSession(input, c.sessionID)
 			}
 
 			// Sende alle Antwortnachrichten zurück an den Client
-			c.handler.SendMessagesToClient(c, messages)
+			c.handler.sendCommandResult(c, input, messages)
 		}
 	}
 }
@@ -1140,6 +1204,10 @@ func (c *Client) writePump() {
 			for i := 0; i < n; i++ {
 				select {
 				case additionalMsg := <-c.send:
+					// Frist pro Nachricht erneuern: lange BASIC-Ausgaben landen
+					// im selben Frame und dürfen nur scheitern, wenn eine
+					// einzelne Nachricht hängt
+					c.conn.SetWriteDeadline(time.Now().Add(getWriteWait()))
 					w.Write(newline)
 					w.Write(additionalMsg)
 				case <-timeout.C:
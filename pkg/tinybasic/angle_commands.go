@@ -0,0 +1,84 @@
+package tinybasic
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// parseOption splits "OPTION ANGLE DEGREES" style arguments into the option
+// name and its value, both uppercased
+func parseOption(args string) (string, string, bool) {
+	fields := strings.Fields(strings.ToUpper(args))
+	if len(fields) != 2 {
+		return "", "", false
+	}
+	return fields[0], fields[1], true
+}
+
+// applyOption sets an OPTION for the running program. Shared by the
+// interpreter and the bytecode VM.
+func (b *TinyBASIC) applyOption(name, value string) error {
+	switch name {
+	case "ANGLE":
+		switch value {
+		case "DEGREES", "DEG":
+			b.angleDegrees = true
+		case "RADIANS", "RAD":
+			b.angleDegrees = false
+		default:
+			return fmt.Errorf("unknown angle mode %s", value)
+		}
+		return nil
+	case "COMPARE":
+		switch value {
+		case "TEXT":
+			b.compareText = true
+		case "BINARY":
+			b.compareText = false
+		default:
+			return fmt.Errorf("unknown compare mode %s", value)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown option %s", name)
+	}
+}
+
+// cmdOption executes OPTION ANGLE DEGREES|RADIANS and OPTION COMPARE
+// BINARY|TEXT. Assumes lock is held.
+func (b *TinyBASIC) cmdOption(args string) error {
+	name, value, ok := parseOption(args)
+	if !ok || b.applyOption(name, value) != nil {
+		return NewBASICError(ErrCategorySyntax, "UNEXPECTED_TOKEN", b.currentLine == 0, b.currentLine).
+			WithCommand("OPTION").WithUsageHint("OPTION ANGLE DEGREES | RADIANS, OPTION COMPARE BINARY | TEXT")
+	}
+	return nil
+}
+
+// angleToRadians converts a trig argument from the current angle mode
+func (b *TinyBASIC) angleToRadians(x float64) float64 {
+	if b.angleDegrees {
+		return x * math.Pi / 180
+	}
+	return x
+}
+
+// angleFromRadians converts an inverse trig result to the current angle mode
+func (b *TinyBASIC) angleFromRadians(x float64) float64 {
+	if b.angleDegrees {
+		return x * 180 / math.Pi
+	}
+	return x
+}
+
+// handleOption executes OP_OPTION (Operand1 = option name, Operand2 = value)
+func (vm *BytecodeVM) handleOption(inst *Instruction) error {
+	name, _ := inst.Operand1.(string)
+	value, _ := inst.Operand2.(string)
+	if err := vm.tinybasic.applyOption(name, value); err != nil {
+		return err
+	}
+	vm.pc++
+	return nil
+}
@@ -0,0 +1,73 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestAngleModeProgram runs trig functions in both angle modes with the
+// bytecode VM and the interpreter
+func TestAngleModeProgram(t *testing.T) {
+	program := []string{
+		`10 LET R = SIN(90)`,
+		`20 OPTION ANGLE DEGREES`,
+		`30 LET S = SIN(90)`,
+		`40 LET C = COS(180)`,
+		`50 LET A = ATAN2(1, -1)`,
+		`60 OPTION ANGLE RADIANS`,
+		`70 LET Q = ATAN2(0, -1)`,
+		`80 PRINT INT(R * 1000); S; C; A; INT(Q * 1000)`,
+	}
+
+	compiler := NewBytecodeCompiler()
+	lines := map[int]string{}
+	var order []int
+	for i, line := range program {
+		lines[(i+1)*10] = line[strings.Index(line, " ")+1:]
+		order = append(order, (i+1)*10)
+	}
+	if _, err := compiler.CompileProgram(lines, order); err != nil {
+		t.Fatalf("program should compile to bytecode: %v", err)
+	}
+
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode, program...)
+		for _, want := range []string{"893", "1", "-1", "135", "3141"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("bytecode=%v: output %q does not contain %q", bytecode, out, want)
+			}
+		}
+	}
+}
+
+// TestAngleModeDirect checks that SIN(90) is 1 in degree mode, the radian
+// default is unchanged and RUN resets the mode
+func TestAngleModeDirect(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	if out := directOutput(b, "PRINT INT(SIN(90) * 1000)"); !strings.Contains(out, "893") {
+		t.Errorf("radian SIN(90) printed %q", out)
+	}
+	b.Execute("OPTION ANGLE DEGREES")
+	if out := directOutput(b, "PRINT SIN(90) * 1000"); !strings.Contains(out, "1000") {
+		t.Errorf("degree SIN(90) * 1000 printed %q, want 1000", out)
+	}
+	if err := b.cmdOption("ANGLE GRADS"); err == nil {
+		t.Error("OPTION ANGLE GRADS should be rejected")
+	}
+
+	b.Execute("10 END")
+	b.Execute("RUN")
+	waitUntilStopped(t, b)
+	if b.angleDegrees {
+		t.Error("RUN should reset OPTION ANGLE")
+	}
+}
+
+// directOutput executes a direct-mode statement and returns its text
+func directOutput(b *TinyBASIC, stmt string) string {
+	var sb strings.Builder
+	for _, msg := range b.Execute(stmt) {
+		sb.WriteString(msg.Content)
+	}
+	return sb.String()
+}
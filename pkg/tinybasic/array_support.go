@@ -4,6 +4,7 @@ package tinybasic
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 )
 
@@ -44,6 +45,137 @@ func splitRespectingParentheses(input string) []string {
 	return result
 }
 
+// Arrays are stored flattened in the variable map: element A(1,2) under the
+// key "A(1,2)", the upper bounds of a DIMensioned array under "A(SIZE" (one
+// dimension) or "A(SIZE1" and "A(SIZE2" (two dimensions) and the number of
+// dimensions under "A(DIMS". The helpers below are the only place that builds
+// these keys, for the interpreter as well as for the bytecode VM.
+
+// maxArrayDimensions is the number of subscripts an array may have
+const maxArrayDimensions = 2
+
+// arrayElementKey returns the variable map key of an array element
+func arrayElementKey(name string, indices []int) string {
+	var sb strings.Builder
+	sb.WriteString(name)
+	sb.WriteByte('(')
+	for i, index := range indices {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.Itoa(index))
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
+
+// arrayDims returns the upper bound of each dimension of the array name, or
+// nil if it was never DIMensioned
+func arrayDims(vars map[string]BASICValue, name string) []int {
+	if size, ok := vars[name+"(SIZE"]; ok {
+		return []int{int(size.NumValue)}
+	}
+	size1, ok1 := vars[name+"(SIZE1"]
+	size2, ok2 := vars[name+"(SIZE2"]
+	if ok1 && ok2 {
+		return []int{int(size1.NumValue), int(size2.NumValue)}
+	}
+	return nil
+}
+
+// dimArray creates the array name with the given upper bounds and all
+// elements set to 0 or "". An array of the same name is replaced.
+func dimArray(vars map[string]BASICValue, name string, sizes []int) {
+	prefix := name + "("
+	for key := range vars {
+		if strings.HasPrefix(key, prefix) {
+			delete(vars, key)
+		}
+	}
+
+	zero := BASICValue{NumValue: 0, IsNumeric: true}
+	if strings.HasSuffix(name, "$") {
+		zero = BASICValue{StrValue: "", IsNumeric: false}
+	}
+	vars[prefix+"DIMS"] = BASICValue{NumValue: float64(len(sizes)), IsNumeric: true}
+	if len(sizes) == 1 {
+		vars[prefix+"SIZE"] = BASICValue{NumValue: float64(sizes[0]), IsNumeric: true}
+		for i := 0; i <= sizes[0]; i++ {
+			vars[arrayElementKey(name, []int{i})] = zero
+		}
+		return
+	}
+	vars[prefix+"SIZE1"] = BASICValue{NumValue: float64(sizes[0]), IsNumeric: true}
+	vars[prefix+"SIZE2"] = BASICValue{NumValue: float64(sizes[1]), IsNumeric: true}
+	for i := 0; i <= sizes[0]; i++ {
+		for j := 0; j <= sizes[1]; j++ {
+			vars[arrayElementKey(name, []int{i, j})] = zero
+		}
+	}
+}
+
+// subscriptsInRange reports whether indices address an element of the array
+// name: one subscript per DIMensioned dimension, each between 0 and its upper
+// bound. Arrays used without DIM take any number of non-negative subscripts.
+func subscriptsInRange(vars map[string]BASICValue, name string, indices []int) bool {
+	if len(indices) == 0 || len(indices) > maxArrayDimensions {
+		return false
+	}
+	dims := arrayDims(vars, name)
+	if dims != nil && len(dims) != len(indices) {
+		return false
+	}
+	for i, index := range indices {
+		if index < 0 || (dims != nil && index > dims[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// badSubscriptError is the error for indices outside the array name. The
+// usage hint shows the DIM of the array.
+func badSubscriptError(vars map[string]BASICValue, name string, indices []int, directMode bool, line int) *BASICError {
+	err := NewBASICError(ErrCategoryRuntime, "BAD_SUBSCRIPT", directMode, line).WithCommand(arrayElementKey(name, indices))
+	if dims := arrayDims(vars, name); dims != nil {
+		err = err.WithUsageHint("DIM " + arrayElementKey(name, dims))
+	}
+	return err
+}
+
+// arrayElement returns the variable key of name(indices) after checking the
+// subscripts. Assumes lock is held.
+func (b *TinyBASIC) arrayElement(name string, indices []int) (string, error) {
+	if !subscriptsInRange(b.variables, name, indices) {
+		return "", badSubscriptError(b.variables, name, indices, b.currentLine == 0, b.currentLine)
+	}
+	return arrayElementKey(name, indices), nil
+}
+
+// arrayValue returns the element name(indices), 0 or "" if it was never set.
+// Assumes lock is held.
+func (b *TinyBASIC) arrayValue(name string, indices []int) (BASICValue, error) {
+	key, err := b.arrayElement(name, indices)
+	if err != nil {
+		return BASICValue{}, err
+	}
+	if value, ok := b.variables[key]; ok {
+		return value, nil
+	}
+	if strings.HasSuffix(name, "$") {
+		return BASICValue{StrValue: "", IsNumeric: false}, nil
+	}
+	return BASICValue{NumValue: 0, IsNumeric: true}, nil
+}
+
+// subscript converts an evaluated array index to an int, rounding as needed
+func subscript(value BASICValue) (int, bool) {
+	if !value.IsNumeric {
+		return 0, false
+	}
+	return int(math.Round(value.NumValue)), true
+}
+
 // cmdDim implementiert den DIM-Befehl zum Erstellen von Arrays
 func (b *TinyBASIC) cmdDim(args string) error {
 	// Prüfe, ob Argumente vorhanden sind
@@ -55,353 +187,246 @@ func (b *TinyBASIC) cmdDim(args string) error {
 	arrayDefs := splitRespectingParentheses(args)
 
 	for _, def := range arrayDefs {
-		def = strings.TrimSpace(def)
-
-		// Finde die öffnende Klammer
-		openParenPos := strings.IndexByte(def, '(')
-		if openParenPos <= 0 {
-			return NewBASICError(ErrCategorySyntax, "INVALID_DIM_STATEMENT", b.currentLine == 0, b.currentLine).WithCommand("DIM")
-		}
-
-		// Finde die schließende Klammer
-		closeParenPos := strings.IndexByte(def, ')')
-		if closeParenPos <= openParenPos || closeParenPos != len(def)-1 {
+		varName, dimensions, ok := splitArrayDef(def)
+		if !ok {
 			return NewBASICError(ErrCategorySyntax, "INVALID_DIM_STATEMENT", b.currentLine == 0, b.currentLine).WithCommand("DIM")
 		}
 
-		// Extrahiere den Variablennamen
-		varName := strings.TrimSpace(def[:openParenPos])
-		varName = strings.ToUpper(varName) // Variablennamen in Großbuchstabenin Großbuchstaben
-
 		// Validiere den Variablennamen
 		if !isValidVarName(varName) {
 			return NewBASICError(ErrCategorySyntax, "INVALID_VARIABLE_NAME", b.currentLine == 0, b.currentLine).WithCommand("DIM")
 		}
-		// Extrahiere die Array-Dimensionen
-		dimensionsStr := strings.TrimSpace(def[openParenPos+1 : closeParenPos])
-
-		// Parse Dimensionen (kann 1D oder 2D sein)
-		dimensions := strings.Split(dimensionsStr, ",")
-		if len(dimensions) > 2 {
+		if len(dimensions) > maxArrayDimensions {
 			return NewBASICError(ErrCategorySyntax, "TOO_MANY_DIMENSIONS", b.currentLine == 0, b.currentLine).WithCommand("DIM").WithUsageHint("Maximum 2 dimensions supported")
 		}
 
-		// Erste Dimension auswerten
-		dimValue1, err := b.evalExpression(strings.TrimSpace(dimensions[0]))
-		if err != nil {
-			return NewBASICError(ErrCategoryEvaluation, "INVALID_ARRAY_DIMENSION_EXPRESSION", b.currentLine == 0, b.currentLine).WithCommand("DIM")
-		}
-		if !dimValue1.IsNumeric {
-			return NewBASICError(ErrCategoryEvaluation, "INVALID_ARRAY_DIMENSION_TYPE", b.currentLine == 0, b.currentLine).WithCommand("DIM")
-		}
-
-		size1 := int(math.Round(dimValue1.NumValue))
-		if size1 < 0 {
-			return NewBASICError(ErrCategoryEvaluation, "INVALID_ARRAY_INDEX", b.currentLine == 0, b.currentLine).WithCommand("DIM")
-		}
-
-		// Prüfe auf 2D-Array
-		var size2 int = -1 // -1 bedeutet 1D-Array
-		if len(dimensions) == 2 {
-			dimValue2, err := b.evalExpression(strings.TrimSpace(dimensions[1]))
+		// Dimensionen auswerten
+		sizes := make([]int, 0, len(dimensions))
+		for _, dimension := range dimensions {
+			dimValue, err := b.evalExpression(dimension)
 			if err != nil {
 				return NewBASICError(ErrCategoryEvaluation, "INVALID_ARRAY_DIMENSION_EXPRESSION", b.currentLine == 0, b.currentLine).WithCommand("DIM")
 			}
-			if !dimValue2.IsNumeric {
+			size, ok := subscript(dimValue)
+			if !ok {
 				return NewBASICError(ErrCategoryEvaluation, "INVALID_ARRAY_DIMENSION_TYPE", b.currentLine == 0, b.currentLine).WithCommand("DIM")
 			}
-
-			size2 = int(math.Round(dimValue2.NumValue))
-			if size2 < 0 {
+			if size < 0 {
 				return NewBASICError(ErrCategoryEvaluation, "INVALID_ARRAY_INDEX", b.currentLine == 0, b.currentLine).WithCommand("DIM")
 			}
+			sizes = append(sizes, size)
 		}
-		// Bestimme, ob es sich um ein String- oder numerisches Array handelt
-		isString := strings.HasSuffix(varName, "$")
-
-		// Speichere die Array-Definition
-		arrayKey := varName + "("
-
-		if size2 == -1 {
-			// 1D-Array
-			if isString {
-				// Für String-Arrays setzen wir einen leeren String als Standardwert
-				b.variables[arrayKey+"SIZE"] = BASICValue{NumValue: float64(size1), IsNumeric: true}
-				for i := 0; i <= size1; i++ {
-					b.variables[fmt.Sprintf("%s%d)", varName, i)] = BASICValue{StrValue: "", IsNumeric: false}
-				}
-			} else {
-				// Für numerische Arrays setzen wir 0 als Standardwert
-				b.variables[arrayKey+"SIZE"] = BASICValue{NumValue: float64(size1), IsNumeric: true}
-				for i := 0; i <= size1; i++ {
-					b.variables[fmt.Sprintf("%s%d)", varName, i)] = BASICValue{NumValue: 0, IsNumeric: true}
-				}
-			}
-		} else {
-			// 2D-Array
-			b.variables[arrayKey+"DIMS"] = BASICValue{NumValue: 2, IsNumeric: true}
-			b.variables[arrayKey+"SIZE1"] = BASICValue{NumValue: float64(size1), IsNumeric: true}
-			b.variables[arrayKey+"SIZE2"] = BASICValue{NumValue: float64(size2), IsNumeric: true}
-
-			if isString {
-				// Für String-Arrays setzen wir einen leeren String als Standardwert
-				for i := 0; i <= size1; i++ {
-					for j := 0; j <= size2; j++ {
-						b.variables[fmt.Sprintf("%s%d,%d)", varName, i, j)] = BASICValue{StrValue: "", IsNumeric: false}
-					}
-				}
-			} else {
-				// Für numerische Arrays setzen wir 0 als Standardwert
-				for i := 0; i <= size1; i++ {
-					for j := 0; j <= size2; j++ {
-						b.variables[fmt.Sprintf("%s%d,%d)", varName, i, j)] = BASICValue{NumValue: 0, IsNumeric: true}
-					}
-				}
-			}
-		}
+		dimArray(b.variables, varName, sizes)
 	}
 
 	return nil
 }
 
-// parseStringArrayReference handles string array references like W$(I) for 1D arrays
-// and W$(I,J) for 2D arrays
-// It assumes the identifier token has already been consumed
-func (p *exprParser) parseStringArrayReference(arrayName string) (BASICValue, error) {
-	b := p.tb
-
-	// Expect opening parenthesis
-	_, err := p.expect(tokLParen)
-	if err != nil {
-		return BASICValue{}, err
-	}
-
-	// Parse the first index expression inside the parentheses
-	index1Val, err := p.parseComparison()
-	if err != nil {
-		return BASICValue{}, fmt.Errorf("invalid array index: %w", err)
+// splitArrayDef splits "A(10, N+1)" into the uppercased name and the
+// subscript expressions. ok is false if def is not of that form.
+func splitArrayDef(def string) (name string, subscripts []string, ok bool) {
+	def = strings.TrimSpace(def)
+	openParenPos := strings.IndexByte(def, '(')
+	if openParenPos <= 0 || !strings.HasSuffix(def, ")") {
+		return "", nil, false
 	}
-
-	// Check that the index is numeric
-	if !index1Val.IsNumeric {
-		return BASICValue{}, NewBASICError(ErrCategoryEvaluation, "ARRAY_INDEX_NOT_NUMERIC", b.currentLine == 0, b.currentLine)
+	name = strings.ToUpper(strings.TrimSpace(def[:openParenPos]))
+	subscripts = splitRespectingParentheses(def[openParenPos+1 : len(def)-1])
+	if len(subscripts) == 0 {
+		return "", nil, false
 	}
-
-	// Check if this is a 2D array (comma follows)
-	if p.peek().typ == tokComma {
-		p.next() // Consume comma
-
-		// Parse the second index expression
-		index2Val, err := p.parseComparison()
-		if err != nil {
-			return BASICValue{}, fmt.Errorf("invalid second array index: %w", err)
-		}
-
-		// Check that the second index is numeric
-		if !index2Val.IsNumeric {
-			return BASICValue{}, NewBASICError(ErrCategoryEvaluation, "ARRAY_INDEX_NOT_NUMERIC", b.currentLine == 0, b.currentLine)
-		}
-
-		// Expect closing parenthesis
-		_, err = p.expect(tokRParen)
-		if err != nil {
-			return BASICValue{}, err
-		}
-
-		// Convert indices to int (rounding as needed)
-		index1 := int(math.Round(index1Val.NumValue))
-		index2 := int(math.Round(index2Val.NumValue))
-
-		// Create the 2D variable name in the form "NAME(INDEX1,INDEX2)"
-		indexedName := fmt.Sprintf("%s(%d,%d)", arrayName, index1, index2)
-
-		// Look up the indexed variable
-		value, ok := p.tb.variables[indexedName]
-		if !ok {
-			// If not found, return empty string (default value for string variables)
-			return BASICValue{StrValue: "", IsNumeric: false}, nil
+	for _, sub := range subscripts {
+		if sub == "" {
+			return "", nil, false
 		}
-
-		return value, nil
-	}
-
-	// 1D array - expect closing parenthesis
-	_, err = p.expect(tokRParen)
-	if err != nil {
-		return BASICValue{}, err
 	}
+	return name, subscripts, true
+}
 
-	// Convert index to int (rounding as needed)
-	index := int(math.Round(index1Val.NumValue))
-
-	// Create the variable name in the form "NAME(INDEX)"
-	// This is the format used internally to store array elements
-	indexedName := fmt.Sprintf("%s(%d)", arrayName, index)
-
-	// Look up the indexed variable
-	value, ok := p.tb.variables[indexedName]
-	if !ok {
-		// If not found, return empty string (default value for string variables)
-		return BASICValue{StrValue: "", IsNumeric: false}, nil
+// splitArrayAssignment splits an assignment without LET to an array element,
+// "A(I,J) = expr", into target and expression
+func splitArrayAssignment(stmt string) (target, expr string, ok bool) {
+	depth := 0
+	inQuote := false
+	for i := 0; i < len(stmt); i++ {
+		switch c := stmt[i]; {
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == '=' && depth == 0:
+			target = strings.TrimSpace(stmt[:i])
+			expr = strings.TrimSpace(stmt[i+1:])
+			name, _, isArray := splitArrayDef(target)
+			return target, expr, isArray && expr != "" && isValidVarName(name)
+		}
 	}
-
-	return value, nil
+	return "", "", false
 }
 
 // parseVariableWithIndex parses a variable name with an index like "W$(I)" or "A(I,J)" and returns
 // the variable name and index values. If it's not an array expression, it returns
 // the original expression as baseName, empty indices slice, and no error.
 func (b *TinyBASIC) parseVariableWithIndex(varExpr string) (string, []int, error) {
-	// Find the opening parenthesis
-	openParenIndex := strings.Index(varExpr, "(")
-	// Find the closing parenthesis
-	closeParenIndex := strings.LastIndex(varExpr, ")")
-
-	// Check if it looks like an array (both parentheses found and in correct order)
-	if openParenIndex != -1 && closeParenIndex != -1 && closeParenIndex > openParenIndex {
-		// Extract the base variable name and the index expression
-		baseName := strings.TrimSpace(varExpr[:openParenIndex])
-		indexExpr := strings.TrimSpace(varExpr[openParenIndex+1 : closeParenIndex])
-
-		// Validate the base name
-		if !isValidVarName(baseName) {
-			return "", nil, fmt.Errorf("%w: invalid variable name '%s' for array", ErrSyntaxError, baseName)
-		}
-
-		// Check if this is a 2D array (contains comma)
-		if strings.Contains(indexExpr, ",") {
-			// Parse 2D array indices
-			indexParts := strings.Split(indexExpr, ",")
-			if len(indexParts) != 2 {
-				return "", nil, fmt.Errorf("invalid 2D array syntax: expected exactly 2 indices, got %d", len(indexParts))
-			}
-
-			indices := make([]int, 2)
-			for i, part := range indexParts {
-				indexVal, err := b.evalExpression(strings.TrimSpace(part))
-				if err != nil {
-					return "", nil, fmt.Errorf("invalid array index expression '%s': %w", strings.TrimSpace(part), err)
-				}
-
-				// Check that the index is numeric
-				if !indexVal.IsNumeric {
-					return "", nil, fmt.Errorf("%w: array index must be numeric, got '%v'", ErrTypeMismatch, indexVal)
-				}
-
-				// Convert to int and store
-				indices[i] = int(math.Round(indexVal.NumValue))
-			}
-			return strings.ToUpper(baseName), indices, nil
-		} else {
-			// Parse 1D array index (original logic)
-			indexVal, err := b.evalExpression(indexExpr)
-			if err != nil {
-				return "", nil, fmt.Errorf("invalid array index expression '%s': %w", indexExpr, err)
-			}
+	varExpr = strings.TrimSpace(varExpr)
+	if !strings.Contains(varExpr, "(") {
+		// Not an array expression, treat as a simple variable
+		return varExpr, []int{}, nil
+	}
 
-			// Check that the index is numeric
-			if !indexVal.IsNumeric {
-				return "", nil, fmt.Errorf("%w: array index must be numeric, got '%v'", ErrTypeMismatch, indexVal)
-			}
+	baseName, subscripts, ok := splitArrayDef(varExpr)
+	if !ok || !isValidVarName(baseName) || len(subscripts) > maxArrayDimensions {
+		return "", nil, NewBASICError(ErrCategorySyntax, "SYNTAX_ERROR", b.currentLine == 0, b.currentLine)
+	}
 
-			// Convert to int and return as single-element slice
-			index := int(math.Round(indexVal.NumValue))
-			return strings.ToUpper(baseName), []int{index}, nil
+	indices := make([]int, len(subscripts))
+	for i, part := range subscripts {
+		indexVal, err := b.evalExpression(part)
+		if err != nil {
+			return "", nil, err
+		}
+		index, ok := subscript(indexVal)
+		if !ok {
+			return "", nil, NewBASICError(ErrCategoryEvaluation, "ARRAY_INDEX_NOT_NUMERIC", b.currentLine == 0, b.currentLine)
 		}
+		indices[i] = index
 	}
-
-	// Not an array expression, return original (trimmed) expression as baseName and empty indices
-	// This indicates it should be treated as a simple variable.
-	return strings.TrimSpace(varExpr), []int{}, nil
+	return baseName, indices, nil
 }
 
 // parseArrayReference handles array references like A(I) or A$(I) for 1D arrays,
 // and A(I,J) or A$(I,J) for 2D arrays
-// It determines whether it's a string or numeric array and calls the appropriate handler
 // It assumes the identifier token has already been consumed
 func (p *exprParser) parseArrayReference(arrayName string) (BASICValue, error) {
-	// Check if this is a string array (name ending with $)
-	isStringArray := strings.HasSuffix(arrayName, "$")
-
-	// For string arrays, handle as W$(I) or W$(I,J)
-	if isStringArray {
-		return p.parseStringArrayReference(arrayName)
+	b := p.tb
+	if _, err := p.expect(tokLParen); err != nil {
+		return BASICValue{}, err
 	}
 
-	// For numeric arrays, handle as A(I) or A(I,J)
-	_, err := p.expect(tokLParen) // Consume '('
-	if err != nil {
+	var indices []int
+	for {
+		indexVal, err := p.parseComparison()
+		if err != nil {
+			return BASICValue{}, fmt.Errorf("invalid array index: %w", err)
+		}
+		index, ok := subscript(indexVal)
+		if !ok {
+			return BASICValue{}, NewBASICError(ErrCategoryEvaluation, "ARRAY_INDEX_NOT_NUMERIC", b.currentLine == 0, b.currentLine)
+		}
+		indices = append(indices, index)
+		if p.peek().typ != tokComma {
+			break
+		}
+		p.next() // Consume comma
+	}
+	if _, err := p.expect(tokRParen); err != nil {
 		return BASICValue{}, err
 	}
 
-	// Parse the first index expression inside the parentheses
-	index1Val, err := p.parseComparison()
-	if err != nil {
-		return BASICValue{}, fmt.Errorf("invalid array index: %w", err)
-	}
+	return b.arrayValue(arrayName, indices)
+}
 
-	// Check that the index is numeric
-	if !index1Val.IsNumeric {
-		return BASICValue{}, NewBASICError(ErrCategoryEvaluation, "ARRAY_INDEX_NOT_NUMERIC", p.tb.currentLine == 0, p.tb.currentLine)
+// hasArrayElements reports whether any element of the array name is set
+func hasArrayElements(vars map[string]BASICValue, name string) bool {
+	prefix := name + "("
+	for key := range vars {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
 	}
+	return false
+}
 
-	// Check if this is a 2D array (comma follows)
-	if p.peek().typ == tokComma {
-		p.next() // Consume comma
-
-		// Parse the second index expression
-		index2Val, err := p.parseComparison()
+// popSubscripts pops count array indices from the VM stack, the last index
+// on top
+func (vm *BytecodeVM) popSubscripts(count int, line int) ([]int, error) {
+	indices := make([]int, count)
+	for i := count - 1; i >= 0; i-- {
+		value, err := vm.stack.Pop()
 		if err != nil {
-			return BASICValue{}, fmt.Errorf("invalid second array index: %w", err)
+			return nil, err
 		}
-
-		// Check that the second index is numeric
-		if !index2Val.IsNumeric {
-			return BASICValue{}, NewBASICError(ErrCategoryEvaluation, "ARRAY_INDEX_NOT_NUMERIC", p.tb.currentLine == 0, p.tb.currentLine)
+		index, ok := subscript(value)
+		if !ok {
+			return nil, NewBASICError(ErrCategoryEvaluation, "ARRAY_INDEX_NOT_NUMERIC", false, line)
 		}
+		indices[i] = index
+	}
+	return indices, nil
+}
 
-		// Expect closing parenthesis
-		_, err = p.expect(tokRParen)
-		if err != nil {
-			return BASICValue{}, err
+// loadArrayElement pushes the array element name(indices) for a call the VM
+// does not know as a function. handled is false, with the stack unchanged,
+// if name is neither DIMensioned nor has any element set, which is more
+// likely a misspelled function.
+func (vm *BytecodeVM) loadArrayElement(name string, argCount int) (handled bool, err error) {
+	if argCount < 1 || argCount > maxArrayDimensions || !isValidVarName(name) {
+		return false, nil
+	}
+	args := make([]BASICValue, argCount)
+	for i := argCount - 1; i >= 0; i-- {
+		if args[i], err = vm.stack.Pop(); err != nil {
+			return true, err
 		}
+	}
 
-		// Convert indices to int (rounding as needed)
-		index1 := int(math.Round(index1Val.NumValue))
-		index2 := int(math.Round(index2Val.NumValue))
-
-		// Create the 2D variable name in the form "NAME(INDEX1,INDEX2)"
-		indexedName := fmt.Sprintf("%s(%d,%d)", arrayName, index1, index2)
-
-		// Look up the indexed variable
-		value, ok := p.tb.variables[indexedName]
-		if !ok {
-			// If not found, return 0 (default value for numeric variables)
-			return BASICValue{NumValue: 0, IsNumeric: true}, nil
+	indices := make([]int, argCount)
+	numeric := true
+	for i, arg := range args {
+		indices[i], numeric = subscript(arg)
+		if !numeric {
+			break
+		}
+	}
+	var value BASICValue
+	set := false
+	if numeric {
+		value, set = vm.variables[arrayElementKey(name, indices)]
+	}
+	if !set && arrayDims(vm.variables, name) == nil && !hasArrayElements(vm.variables, name) {
+		for _, arg := range args {
+			vm.stack.Push(arg)
 		}
+		return false, nil
+	}
 
-		return value, nil
+	line := vm.CurrentLine()
+	if !numeric {
+		return true, NewBASICError(ErrCategoryEvaluation, "ARRAY_INDEX_NOT_NUMERIC", false, line)
+	}
+	if !subscriptsInRange(vm.variables, name, indices) {
+		return true, badSubscriptError(vm.variables, name, indices, false, line)
+	}
+	if !set {
+		value = BASICValue{NumValue: 0, IsNumeric: true}
+		if strings.HasSuffix(name, "$") {
+			value = BASICValue{StrValue: "", IsNumeric: false}
+		}
 	}
+	vm.stack.Push(value)
+	return true, nil
+}
 
-	// 1D array - expect closing parenthesis
-	_, err = p.expect(tokRParen)
+// handleStoreArray assigns an array element. Operand1 is the array name,
+// Operand2 the number of indices; the indices and then the value are on the
+// stack.
+func (vm *BytecodeVM) handleStoreArray(inst *Instruction) error {
+	name := inst.Operand1.(string)
+	value, err := vm.stack.Pop()
 	if err != nil {
-		return BASICValue{}, err
+		return err
 	}
-
-	// Convert index to int (rounding as needed)
-	index := int(math.Round(index1Val.NumValue))
-
-	// Create the variable name in the form "NAME(INDEX)"
-	// This is the format used internally to store array elements
-	indexedName := fmt.Sprintf("%s(%d)", arrayName, index)
-
-	// Look up the indexed variable
-	value, ok := p.tb.variables[indexedName]
-	if !ok {
-		// If not found, return 0 (default value for numeric variables)
-		return BASICValue{NumValue: 0, IsNumeric: true}, nil
+	indices, err := vm.popSubscripts(inst.Operand2.(int), inst.LineNum)
+	if err != nil {
+		return err
 	}
-
-	return value, nil
+	if !subscriptsInRange(vm.variables, name, indices) {
+		return badSubscriptError(vm.variables, name, indices, false, inst.LineNum)
+	}
+	vm.variables[arrayElementKey(name, indices)] = vm.convertForVariable(name, value)
+	vm.pc++
+	return nil
 }
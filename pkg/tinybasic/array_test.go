@@ -0,0 +1,113 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+// runArrayProgram runs a program and fails the test if the bytecode run fell
+// back to the interpreter because the program did not compile
+func runArrayProgram(t *testing.T, bytecode bool, lines ...string) string {
+	t.Helper()
+	if bytecode {
+		requireBytecode(t, lines...)
+	}
+	return runTrapProgram(t, bytecode, lines...)
+}
+
+// TestArray2DFillAndReadBack fills a 2D array in nested loops and reads
+// every element back
+func TestArray2DFillAndReadBack(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		out := runArrayProgram(t, bytecode,
+			`10 LET N = 4`,
+			`20 DIM A(3, N), B$(2,2)`,
+			`30 FOR I = 0 TO 3`,
+			`40 FOR J = 0 TO N`,
+			`50 A(I, J) = I * 10 + J`,
+			`60 NEXT J`,
+			`70 NEXT I`,
+			`80 LET S = 0`,
+			`90 FOR I = 0 TO 3`,
+			`100 FOR J = 0 TO N`,
+			`110 IF A(I, J) <> I * 10 + J THEN PRINT "WRONG"; I; J`,
+			`120 LET S = S + A(I, J)`,
+			`130 NEXT J`,
+			`140 NEXT I`,
+			`150 LET B$(1, 2) = "X" + "23"`,
+			`160 PRINT "SUM"; S`,
+			`170 PRINT A(3, 4); A(INT(1.4), 1 + 1); B$(1, 2); B$(2, 1); "END"`,
+		)
+		if strings.Contains(out, "WRONG") || strings.Contains(out, "ERROR") {
+			t.Errorf("bytecode=%v: elements not read back, output %q", bytecode, out)
+		}
+		flat := strings.NewReplacer(" ", "", "\n", "").Replace(out)
+		if !strings.Contains(flat, "SUM340") {
+			t.Errorf("bytecode=%v: wrong sum, output %q", bytecode, out)
+		}
+		if !strings.Contains(flat, "3412X23END") {
+			t.Errorf("bytecode=%v: wrong elements, output %q", bytecode, out)
+		}
+	}
+}
+
+// TestArrayBadSubscript expects reads and writes outside the DIM to stop
+// the program with BAD SUBSCRIPT in the failing line
+func TestArrayBadSubscript(t *testing.T) {
+	programs := map[string][]string{
+		"write second index": {`10 DIM A(3,4)`, `20 A(1,5) = 1`, `30 PRINT "NOT REACHED"`},
+		"read first index":   {`10 DIM A(3,4)`, `20 PRINT A(4,0)`, `30 PRINT "NOT REACHED"`},
+		"negative index":     {`10 DIM A(3,4)`, `20 LET X = A(-1,0)`, `30 PRINT "NOT REACHED"`},
+		"index count":        {`10 DIM A(3,4)`, `20 A(1) = 1`, `30 PRINT "NOT REACHED"`},
+		"string array":       {`10 DIM W$(2,2)`, `20 LET W$(3,0) = "X"`, `30 PRINT "NOT REACHED"`},
+	}
+	for name, lines := range programs {
+		for _, bytecode := range []bool{false, true} {
+			out := runArrayProgram(t, bytecode, lines...)
+			if !strings.Contains(out, "IN LINE 20: BAD SUBSCRIPT") {
+				t.Errorf("%s, bytecode=%v: no BAD SUBSCRIPT in line 20, output %q", name, bytecode, out)
+			}
+			if strings.Contains(out, "NOT REACHED") {
+				t.Errorf("%s, bytecode=%v: program continued, output %q", name, bytecode, out)
+			}
+		}
+	}
+}
+
+// TestArrayBadSubscriptIsTrappable checks that ON ERROR sees error 9 and
+// the line of a bad subscript
+func TestArrayBadSubscriptIsTrappable(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		out := runArrayProgram(t, bytecode,
+			`10 ON ERROR GOTO 100`,
+			`20 DIM A(2,2)`,
+			`30 A(2,3) = 1`,
+			`40 END`,
+			`100 PRINT "ERR"; ERR; "ERL"; ERL`,
+		)
+		if !strings.Contains(strings.NewReplacer(" ", "", "\n", "").Replace(out), "ERR9ERL30") {
+			t.Errorf("bytecode=%v: bad subscript not trapped as error 9, output %q", bytecode, out)
+		}
+	}
+}
+
+// TestArrayElementKey checks that DIM and element access agree on the keys
+func TestArrayElementKey(t *testing.T) {
+	vars := map[string]BASICValue{}
+	dimArray(vars, "A", []int{1, 2})
+	for _, key := range []string{"A(0,0)", "A(1,2)"} {
+		if _, ok := vars[key]; !ok {
+			t.Errorf("DIM A(1,2) did not create %s", key)
+		}
+	}
+	if got := arrayElementKey("A", []int{1, 2}); got != "A(1,2)" {
+		t.Errorf("arrayElementKey = %q", got)
+	}
+	dimArray(vars, "A", []int{3})
+	if _, ok := vars["A(1,2)"]; ok {
+		t.Error("DIM A(3) kept the elements of the old 2D array")
+	}
+	if !subscriptsInRange(vars, "A", []int{3}) || subscriptsInRange(vars, "A", []int{1, 1}) {
+		t.Error("subscripts not checked against the new DIM")
+	}
+}
@@ -0,0 +1,44 @@
+package tinybasic
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// cmdBench runs the program like RUN and reports how long it took when it
+// ends. Takes the same arguments as RUN.
+func (b *TinyBASIC) cmdBench(args string) (string, error) {
+	return b.startRun("BENCH", args)
+}
+
+// reportBenchmark prints the summary of a program started with BENCH. vm is
+// the bytecode VM that ran the program, nil for the interpreter. Does
+// nothing after a plain RUN.
+func (b *TinyBASIC) reportBenchmark(vm *BytecodeVM) {
+	b.mu.Lock()
+	start := b.benchStart
+	b.benchStart = time.Time{}
+	b.mu.Unlock()
+	if start.IsZero() {
+		return
+	}
+
+	elapsed := time.Since(start)
+	summary := fmt.Sprintf("BENCH: %v", elapsed.Round(time.Millisecond))
+	if vm == nil {
+		summary += " (interpreted)"
+	} else if cache, ok := vm.GetPerformanceStats()["instruction_cache"].(map[string]interface{}); ok {
+		hits, _ := cache["hits"].(int64)
+		misses, _ := cache["misses"].(int64)
+		hitRate, _ := cache["hit_rate"].(float64)
+		instructions := hits + misses
+		summary += fmt.Sprintf(", %d instructions", instructions)
+		if seconds := elapsed.Seconds(); seconds > 0 {
+			summary += fmt.Sprintf(" (%.0f/s)", float64(instructions)/seconds)
+		}
+		summary += fmt.Sprintf(", cache hit rate %.1f%%", hitRate*100)
+	}
+	b.sendMessageWrapped(shared.MessageTypeText, summary)
+}
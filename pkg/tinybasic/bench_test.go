@@ -0,0 +1,87 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// runUntilOK executes command and collects the text output up to the OK that
+// ends the program
+func runUntilOK(t *testing.T, b *TinyBASIC, command string) []string {
+	t.Helper()
+	b.Execute(command)
+
+	var out []string
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg := <-b.OutputChan:
+			if msg.Type != shared.MessageTypeText {
+				continue
+			}
+			if msg.Content == "OK" {
+				return out
+			}
+			out = append(out, msg.Content)
+		case <-timeout:
+			t.Fatalf("%s did not end in time, output so far: %q", command, out)
+		}
+	}
+}
+
+// TestBench checks the BENCH summary with the bytecode VM and the
+// interpreter, and that RUN afterwards prints none
+func TestBench(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		b.useBytecode = bytecode
+		b.Execute("10 LET S = 0")
+		b.Execute("20 FOR I = 1 TO 100")
+		b.Execute("30 LET S = S + I")
+		b.Execute("40 NEXT I")
+		b.Execute("50 PRINT S")
+		for len(b.OutputChan) > 0 {
+			<-b.OutputChan
+		}
+
+		out := runUntilOK(t, b, "BENCH")
+		if len(out) < 2 || !strings.Contains(strings.Join(out[:len(out)-1], "\n"), "5050") {
+			t.Fatalf("bytecode=%v: BENCH changed the program output: %q", bytecode, out)
+		}
+		summary := out[len(out)-1]
+		if !strings.HasPrefix(summary, "BENCH: ") {
+			t.Fatalf("bytecode=%v: expected summary last, got %q", bytecode, out)
+		}
+		if bytecode && (!strings.Contains(summary, " instructions") || !strings.Contains(summary, "cache hit rate")) {
+			t.Errorf("bytecode=%v: summary %q lacks the VM statistics", bytecode, summary)
+		}
+		if !bytecode && !strings.Contains(summary, "(interpreted)") {
+			t.Errorf("bytecode=%v: summary %q does not say interpreted", bytecode, summary)
+		}
+
+		out = runUntilOK(t, b, "RUN")
+		for _, line := range out {
+			if strings.HasPrefix(line, "BENCH") {
+				t.Errorf("bytecode=%v: RUN printed %q", bytecode, line)
+			}
+		}
+	}
+}
+
+// TestBenchArguments checks that BENCH reports errors like RUN does
+func TestBenchArguments(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	if _, err := b.cmdBench(""); err == nil {
+		t.Error("expected an error for BENCH without a program")
+	}
+	b.Execute("10 PRINT 1")
+	if _, err := b.cmdBench("99"); err == nil {
+		t.Error("expected an error for BENCH with a missing line")
+	}
+	if !b.benchStart.IsZero() {
+		t.Error("a failed BENCH must not leave the timer running")
+	}
+}
@@ -0,0 +1,110 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitUntilStopped waits for the asynchronous RUN goroutine to finish
+func waitUntilStopped(t *testing.T, b *TinyBASIC) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		b.mu.Lock()
+		running := b.running || (b.bytecodeVM != nil && b.bytecodeVM.IsRunning())
+		b.mu.Unlock()
+		if !running {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("program did not stop in time")
+}
+
+// drainUntilOK reads output until a finished run sends its final OK
+func drainUntilOK(t *testing.T, b *TinyBASIC) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg := <-b.OutputChan:
+			if msg.Content == "OK" {
+				return
+			}
+		case <-timeout:
+			t.Fatal("interrupted run did not finish")
+		}
+	}
+}
+
+// TestBreakInNestedLoopResetsState breaks out of nested FOR/GOSUB and checks that
+// neither the interpreter nor the bytecode VM keep stale frames for the next RUN.
+func TestBreakInNestedLoopResetsState(t *testing.T) {
+	b := NewTinyBASIC(nil)
+
+	program := []string{
+		"5 LET X = 0",
+		"10 FOR I = 1 TO 1000000",
+		"20 FOR J = 1 TO 1000",
+		"30 GOSUB 100",
+		"40 NEXT J",
+		"50 NEXT I",
+		"60 END",
+		"100 LET X = X + 1",
+		"110 RETURN",
+	}
+	requireBytecode(t, program...)
+	for _, line := range program {
+		b.Execute(line)
+	}
+	b.Execute("RUN")
+	time.Sleep(50 * time.Millisecond)
+
+	b.StopExecution()
+	waitUntilStopped(t, b)
+
+	b.mu.Lock()
+	if len(b.forLoops) != 0 || len(b.gosubStack) != 0 || len(b.forLoopIndexMap) != 0 {
+		t.Errorf("interpreter state not cleared: forLoops=%d gosubStack=%d forLoopIndexMap=%d",
+			len(b.forLoops), len(b.gosubStack), len(b.forLoopIndexMap))
+	}
+	if vm := b.bytecodeVM; vm == nil {
+		t.Error("the program did not run on the bytecode VM")
+	} else if len(vm.forLoops) != 0 || len(vm.callStack) != 0 || !vm.stack.IsEmpty() {
+		t.Errorf("bytecode VM state not cleared: forLoops=%d callStack=%d stackEmpty=%v",
+			len(vm.forLoops), len(vm.callStack), vm.stack.IsEmpty())
+	}
+	b.mu.Unlock()
+
+	// A fresh program must run cleanly afterwards
+	b.Execute("NEW")
+	for _, line := range []string{
+		"5 LET S = 0",
+		"10 FOR K = 1 TO 3",
+		"20 GOSUB 100",
+		"30 NEXT K",
+		"40 END",
+		"100 LET S = S + K",
+		"110 RETURN",
+	} {
+		b.Execute(line)
+	}
+	// The interrupted run is over once it has printed its final OK
+	drainUntilOK(t, b)
+	b.Execute("RUN")
+	waitUntilStopped(t, b)
+
+	for len(b.OutputChan) > 0 {
+		msg := <-b.OutputChan
+		if strings.Contains(msg.Content, "ERROR") {
+			t.Errorf("unexpected error after BREAK: %s", msg.Content)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s := b.variables["S"]; !s.IsNumeric || s.NumValue != 6 {
+		t.Errorf("expected S = 6 after clean RUN, got %+v", s)
+	}
+}
@@ -66,7 +66,7 @@ const (
 	OP_NOISE         // Play noise
 	OP_BEEP          // Play beep
 	OP_CLS           // Clear screen
-	OP_MUSIC         // Music command
+	OP_MUSIC         // Music command (Operand1 = subcommand)
 	OP_SPEAK         // Speak command
 	OP_PLOT          // Plot pixel
 	OP_LINE          // Draw line
@@ -78,12 +78,12 @@ const (
 	OP_CYLINDER      // Cylinder command
 	OP_SAY           // Say command
 	OP_LOCATE        // Locate cursor
-	OP_COLOR         // Set color
-	OP_KEY           // Key input
+	OP_COLOR         // Set color (unused, COLOR does not compile)
+	OP_KEY           // KEY n, text$ (key number and text on the stack)
 	OP_DATA          // Data statement
 	OP_READ          // Read data
 	OP_DIM           // Dimension arrays
-	OP_TEXTGFX       // Text graphics
+	OP_TEXTGFX       // Text graphics (Operand1 = number of arguments on the stack)
 	OP_CLEARGRAPHICS // Clear graphics
 	OP_INVERSE       // Inverse text
 	OP_RANDOMIZE     // Randomize seed
@@ -96,6 +96,54 @@ const (
 	OP_STR_CONCAT // String concatenation
 	OP_STR_LEN    // String length
 	OP_STR_MID    // String substring
+
+	// Error handling
+	OP_ON_ERROR // Install error handler (Operand1 = line, 0 = none)
+	OP_RESUME   // Leave error handler (Operand1 = resume mode)
+
+	// String assignment
+	OP_MID_ASSIGN // MID$(var, start[, length]) = value (Operand1 = variable, Operand2 = has length)
+
+	// Program options
+	OP_OPTION // OPTION name value (Operand1 = name, Operand2 = value)
+
+	// Compiler-internal control flow
+	OP_JUMP_ADDR // Unconditional jump to the instruction address in Operand1
+
+	// Pause for CONT
+	OP_STOP // Stop execution, keeping the state CONT needs to resume
+
+	// Sound scheduling
+	OP_SOUND_WAIT // SOUND WAIT ON|OFF (Operand1 = on)
+
+	// DATA pointer
+	OP_RESTORE // RESTORE [line] (Operand1 = line as written, "" for the first item)
+
+	// Delay that keeps key presses and can be interrupted
+	OP_SLEEP // SLEEP ms (duration on the stack)
+
+	// Graphics double buffering
+	OP_SCREEN // SCREEN DOUBLE|SINGLE|SWAP and FLIP (Operand1 = mode)
+
+	// Array element assignment
+	OP_STORE_ARRAY // A(I,J) = value (Operand1 = array, Operand2 = number of indices; indices and value on the stack)
+
+	// Chained programs
+	OP_COMMON // COMMON var, ... (Operand1 = names, arrays as "A()")
+	OP_CHAIN  // CHAIN "file" (Operand1 = filename expression)
+
+	// Function key trapping
+	OP_ON_KEY // ON KEY(n) GOSUB line (Operand1 = key number, Operand2 = line, 0 = none)
+
+	// Fixed-width string fields
+	OP_JUSTIFY // LSET/RSET var = value (Operand1 = variable, Operand2 = right-justify)
+
+	// Event trapping
+	OP_ON_EVENT // ON TIMER(n)/ON INKEY GOSUB line (Operand1 = seconds, 0 = INKEY; Operand2 = line, 0 = none)
+	OP_TIMER    // TIMER ON|OFF (Operand1 = on)
+
+	// Text screen width
+	OP_WIDTH // WIDTH n (columns on the stack)
 )
 
 // Bytecode instruction with opcode and operands
@@ -112,14 +160,16 @@ type BytecodeProgram struct {
 	Constants    []interface{}  // Constant pool for literals
 	Labels       map[int]int    // Line number to instruction index mapping
 	OriginalCode map[int]string // Original BASIC code for debugging
+	Statements   []int          // Instruction index of each statement start, ascending
 	mutex        sync.RWMutex   // Protects concurrent access
 }
 
 // Bytecode virtual machine stack
 type VMStack struct {
-	data []BASICValue
-	top  int
-	size int
+	data       []BASICValue
+	top        int
+	size       int
+	overflowed bool // A push found the stack full, see Overflowed
 }
 
 // StringInterningSystem provides thread-safe string interning with memory management
@@ -301,25 +351,36 @@ func ReturnVMStack(stack *VMStack) {
 	}
 }
 
-// Push value onto stack (optimized)
+// Push value onto stack (optimized). Many handlers ignore the error, so an
+// overflow is also remembered for Overflowed.
 func (s *VMStack) Push(value BASICValue) error {
 	if s.top >= s.size-1 {
-		return fmt.Errorf("stack overflow")
+		s.overflowed = true
+		return fmt.Errorf("%w: %d values", ErrStackOverflow, s.size)
 	}
 	s.top++
 	s.data[s.top] = value
 	return nil
 }
 
-// FastPush value onto stack with minimal bounds checking (optimized but safe)
+// FastPush value onto stack with a single bounds check. On a full stack the
+// value is dropped and the overflow is remembered; the VM checks Overflowed
+// after every instruction and stops with a runtime error instead of
+// panicking in the middle of a handler.
 func (s *VMStack) FastPush(value BASICValue) {
-	s.top++
-	if s.top >= s.size {
-		panic(fmt.Sprintf("stack overflow: attempted to push at index %d, stack size %d", s.top, s.size))
+	if s.top >= s.size-1 {
+		s.overflowed = true
+		return
 	}
+	s.top++
 	s.data[s.top] = value
 }
 
+// Overflowed reports whether a push was dropped since the last Clear
+func (s *VMStack) Overflowed() bool {
+	return s.overflowed
+}
+
 // Pop value from stack (optimized)
 func (s *VMStack) Pop() (BASICValue, error) {
 	if s.top < 0 {
@@ -366,7 +427,7 @@ func (s *VMStack) HasItems(n int) bool {
 // SafeFastPush pushes value if there's space, panics otherwise
 func (s *VMStack) SafeFastPush(value BASICValue) {
 	if !s.HasSpace(1) {
-		panic(fmt.Sprintf("stack overflow: no space for push, current size %d/%d", s.top+1, s.size))
+		panic(fmt.Sprintf("%v: no space for push, current size %d/%d", ErrStackOverflow, s.top+1, s.size))
 	}
 	s.FastPush(value)
 }
@@ -384,9 +445,10 @@ func (s *VMStack) Size() int {
 	return s.top + 1
 }
 
-// Clear empties the stack
+// Clear empties the stack and forgets an overflow
 func (s *VMStack) Clear() {
 	s.top = -1
+	s.overflowed = false
 }
 
 // Bytecode compiler state
@@ -397,6 +459,8 @@ type BytecodeCompiler struct {
 	constants    []interface{}
 	labels       map[int]int
 	originalCode map[int]string
+	statements   []int
+	noFolding    bool // Compile constant expressions as written, for comparisons with the folded code
 }
 
 // NewBytecodeCompiler creates a new bytecode compiler
@@ -457,6 +521,7 @@ func (c *BytecodeCompiler) CompileProgram(program map[int]string, programLines [
 	c.constants = make([]interface{}, 0)
 	c.labels = make(map[int]int)
 	c.originalCode = make(map[int]string)
+	c.statements = make([]int, 0, len(programLines))
 
 	// Store original code
 	for _, lineNum := range programLines {
@@ -485,6 +550,7 @@ func (c *BytecodeCompiler) CompileProgram(program map[int]string, programLines [
 	c.program.Constants = c.constants
 	c.program.Labels = c.labels
 	c.program.OriginalCode = c.originalCode
+	c.program.Statements = c.statements
 
 	return c.program, nil
 }
@@ -494,10 +560,16 @@ func (c *BytecodeCompiler) compileLine(line string) error {
 	// The line already has the line number removed by the compiler
 	// since it comes from the program map which stores just the code part
 
-	// Split line into statements by colon
-	statements := c.splitStatements(line)
+	// Split line into statements by colon. A line starting with IF...THEN is
+	// one statement, the colons belong to its THEN and ELSE parts.
+	statements := []string{line}
+	if !ifThenPattern.MatchString(strings.TrimSpace(line)) {
+		statements = c.splitStatements(line)
+	}
 
 	for _, stmt := range statements {
+		// Remember where each statement starts so RESUME can find it again
+		c.statements = append(c.statements, len(c.instructions))
 		err := c.compileStatement(stmt)
 		if err != nil {
 			return err
@@ -509,7 +581,7 @@ func (c *BytecodeCompiler) compileLine(line string) error {
 
 // compileStatement compiles a single BASIC statement to bytecode
 func (c *BytecodeCompiler) compileStatement(stmt string) error {
-	stmt = strings.TrimSpace(stmt)
+	stmt = expandPrintShorthand(strings.TrimSpace(stmt))
 	if stmt == "" {
 		return nil
 	}
@@ -519,6 +591,11 @@ func (c *BytecodeCompiler) compileStatement(stmt string) error {
 		return nil
 	}
 
+	// MID$ on the left of "=" is an assignment, not a function call
+	if m, ok := parseMidAssignment(stmt); ok {
+		return c.compileMidAssign(m)
+	}
+
 	command := strings.ToUpper(parts[0])
 	args := ""
 	if len(parts) > 1 {
@@ -537,6 +614,9 @@ func (c *BytecodeCompiler) compileStatement(stmt string) error {
 	case "LET":
 		return c.compileLet(stmt)
 
+	case "LSET", "RSET":
+		return c.compileJustify(command, args)
+
 	case "PRINT", "PR.", "?":
 		return c.compilePrint(args)
 
@@ -561,15 +641,62 @@ func (c *BytecodeCompiler) compileStatement(stmt string) error {
 	case "NEXT":
 		return c.compileNext(args)
 
-	case "END", "STOP":
+	case "END":
 		c.Emit(OP_HALT)
 
+	case "STOP":
+		c.Emit(OP_STOP)
+
 	case "SOUND":
 		return c.compileSound(args)
 
 	case "WAIT":
 		return c.compileWait(args)
 
+	case "SLEEP":
+		if args == "" {
+			return fmt.Errorf("SLEEP requires duration argument")
+		}
+		if err := c.compileExpression(args); err != nil {
+			return fmt.Errorf("error compiling SLEEP duration: %v", err)
+		}
+		c.Emit(OP_SLEEP)
+
+	case "SCREEN":
+		mode := parseScreenMode(args)
+		if mode == "" {
+			return fmt.Errorf("SCREEN requires DOUBLE, SINGLE or SWAP")
+		}
+		c.Emit(OP_SCREEN, mode)
+
+	case "FLIP":
+		if strings.TrimSpace(args) != "" {
+			return fmt.Errorf("FLIP does not take any arguments")
+		}
+		c.Emit(OP_SCREEN, "SWAP")
+
+	case "WIDTH":
+		if strings.TrimSpace(args) == "" {
+			return fmt.Errorf("WIDTH requires a column count")
+		}
+		if err := c.compileExpression(args); err != nil {
+			return fmt.Errorf("error compiling WIDTH columns: %v", err)
+		}
+		c.Emit(OP_WIDTH)
+
+	case "COMMON":
+		names, errCode := parseCommonList(args)
+		if errCode != "" {
+			return fmt.Errorf("COMMON requires a list of variables")
+		}
+		c.Emit(OP_COMMON, names)
+
+	case "CHAIN":
+		if strings.TrimSpace(args) == "" {
+			return fmt.Errorf("CHAIN requires a filename")
+		}
+		c.Emit(OP_CHAIN, strings.TrimSpace(args))
+
 	case "NOISE":
 		return c.compileNoise(args)
 
@@ -597,6 +724,12 @@ func (c *BytecodeCompiler) compileStatement(stmt string) error {
 	case "CIRCLE":
 		return c.compileCircle(args)
 
+	case "PAINT":
+		return c.compilePaint(args)
+
+	case "DRAW":
+		return c.compileDraw(args)
+
 	case "SPRITE":
 		return c.compileSprite(args)
 
@@ -627,6 +760,13 @@ func (c *BytecodeCompiler) compileStatement(stmt string) error {
 	case "KEY":
 		return c.compileKey(args)
 
+	case "TIMER":
+		on, ok := parseTimerSwitch(args)
+		if !ok {
+			return fmt.Errorf("invalid TIMER statement: %s", stmt)
+		}
+		c.Emit(OP_TIMER, on)
+
 	case "DATA":
 		return c.compileData(args)
 
@@ -651,7 +791,59 @@ func (c *BytecodeCompiler) compileStatement(stmt string) error {
 	case "PHYSICS":
 		return c.compilePhysics(args)
 
+	case "ON":
+		if isOnKey(args) {
+			key, line, ok := parseOnKeyGosub(args)
+			if !ok {
+				return fmt.Errorf("invalid ON KEY statement: %s", stmt)
+			}
+			c.Emit(OP_ON_KEY, key, line)
+			break
+		}
+		if isOnEvent(args) {
+			interval, line, ok := parseOnEvent(args)
+			if !ok {
+				return fmt.Errorf("invalid ON TIMER/INKEY statement: %s", stmt)
+			}
+			c.Emit(OP_ON_EVENT, interval, line)
+			break
+		}
+		line, ok := parseOnErrorGoto(args)
+		if !ok {
+			return fmt.Errorf("invalid ON statement: %s", stmt)
+		}
+		c.Emit(OP_ON_ERROR, line)
+
+	case "OPTION":
+		name, value, ok := parseOption(args)
+		if !ok {
+			return fmt.Errorf("invalid OPTION statement: %s", stmt)
+		}
+		c.Emit(OP_OPTION, name, value)
+
+	case "RESUME":
+		mode, ok := parseResumeMode(args)
+		if !ok {
+			return fmt.Errorf("invalid RESUME statement: %s", stmt)
+		}
+		c.Emit(OP_RESUME, mode)
+
+	case "RESTORE":
+		c.Emit(OP_RESTORE, strings.TrimSpace(args))
+
+	case "SAVEVAR":
+		return c.compileSaveVar(args)
+
+	case "SAVE":
+		// SAVE may ask before overwriting, which only the interpreter can
+		// wait for, so programs using it run there
+		return fmt.Errorf("SAVE is not supported by the bytecode VM")
+
 	default:
+		// A(I,J) = value without LET
+		if target, expression, ok := splitArrayAssignment(stmt); ok {
+			return c.compileArrayStore(target, expression)
+		}
 		// Unknown command - emit as function call
 		return c.compileFunction(command, args)
 	}
@@ -678,6 +870,10 @@ func (c *BytecodeCompiler) compileLet(stmt string) error {
 	varName := strings.TrimSpace(strings.ToUpper(parts[0]))
 	expression := strings.TrimSpace(parts[1])
 
+	if strings.Contains(varName, "(") {
+		return c.compileArrayStore(varName, expression)
+	}
+
 	// Validate variable name
 	if !c.isValidVariableName(varName) {
 		return fmt.Errorf("invalid variable name: %s", varName)
@@ -695,8 +891,75 @@ func (c *BytecodeCompiler) compileLet(stmt string) error {
 	return nil
 }
 
+// compileArrayStore compiles A(I,J) = expression: the indices, then the
+// value, then OP_STORE_ARRAY
+func (c *BytecodeCompiler) compileArrayStore(target, expression string) error {
+	name, indices, ok := splitArrayDef(target)
+	if !ok || !c.isValidVariableName(name) || len(indices) > maxArrayDimensions {
+		return fmt.Errorf("invalid array element: %s", target)
+	}
+	for _, index := range indices {
+		if err := c.compileExpression(index); err != nil {
+			return fmt.Errorf("error compiling array index '%s': %v", index, err)
+		}
+	}
+	if err := c.compileExpression(expression); err != nil {
+		return fmt.Errorf("error compiling expression '%s': %v", expression, err)
+	}
+	c.Emit(OP_STORE_ARRAY, name, len(indices))
+	return nil
+}
+
+// compileMidAssign compiles MID$(var, start[, length]) = value. Array elements
+// are left to the interpreter.
+func (c *BytecodeCompiler) compileMidAssign(m midAssignment) error {
+	target := strings.ToUpper(m.target)
+	if m.start == "" || m.value == "" || !c.isValidVariableName(target) || !strings.HasSuffix(target, "$") {
+		return fmt.Errorf("invalid MID$ assignment: MID$(%s, %s) = %s", m.target, m.start, m.value)
+	}
+	if err := c.compileExpression(m.start); err != nil {
+		return fmt.Errorf("error compiling MID$ start '%s': %v", m.start, err)
+	}
+	if m.length != "" {
+		if err := c.compileExpression(m.length); err != nil {
+			return fmt.Errorf("error compiling MID$ length '%s': %v", m.length, err)
+		}
+	}
+	if err := c.compileExpression(m.value); err != nil {
+		return fmt.Errorf("error compiling expression '%s': %v", m.value, err)
+	}
+	c.Emit(OP_MID_ASSIGN, target, m.length != "")
+	return nil
+}
+
+// compileJustify compiles LSET var = value and RSET var = value. Array
+// elements are left to the interpreter.
+func (c *BytecodeCompiler) compileJustify(command, args string) error {
+	target, value, ok := splitJustify(args)
+	target = strings.ToUpper(target)
+	if !ok || value == "" || !c.isValidVariableName(target) || !strings.HasSuffix(target, "$") {
+		return fmt.Errorf("invalid %s statement: %s %s", command, command, args)
+	}
+	if err := c.compileExpression(value); err != nil {
+		return fmt.Errorf("error compiling expression '%s': %v", value, err)
+	}
+	c.Emit(OP_JUSTIFY, target, command == "RSET")
+	return nil
+}
+
 // compilePrint compiles PRINT statements
 func (c *BytecodeCompiler) compilePrint(args string) error {
+	// PRINT AT(x,y) compiles to a LOCATE followed by the remaining items
+	if coords, rest, ok := splitPrintAt(args); ok {
+		if err := c.compileLocate(coords); err != nil {
+			return fmt.Errorf("error compiling PRINT AT: %v", err)
+		}
+		if rest == "" {
+			return nil
+		}
+		args = rest
+	}
+
 	if args == "" {
 		// Empty PRINT - just print newline
 		c.Emit(OP_PRINT_NL)
@@ -734,9 +997,8 @@ func (c *BytecodeCompiler) compilePrint(args string) error {
 		// Handle separator between items
 		if i < len(items)-1 {
 			if item.separator == "," {
-				// Tab to next print zone (approximate with spaces)
-				c.EmitConstant("    ") // 4 spaces for tab
-				c.Emit(OP_PRINT)
+				// Tab to the next print zone, computed from the cursor column at runtime
+				c.Emit(OP_CALL_FUNC, "PRINT_ZONE", 0)
 			}
 			// Semicolon means no space/newline between items
 		}
@@ -745,9 +1007,8 @@ func (c *BytecodeCompiler) compilePrint(args string) error {
 	// Handle final separator
 	if endsWithSeparator {
 		if lastChar == "," {
-			// Trailing comma - add tab spacing
-			c.EmitConstant("    ")
-			c.Emit(OP_PRINT)
+			// Trailing comma - tab to the next print zone
+			c.Emit(OP_CALL_FUNC, "PRINT_ZONE", 0)
 		}
 		// Trailing semicolon means no newline
 	} else {
@@ -769,12 +1030,20 @@ func (c *BytecodeCompiler) parsePrintItems(args string) []BytecodePrintItem {
 	items := make([]BytecodePrintItem, 0)
 	current := ""
 	inString := false
+	depth := 0 // Commas inside A(I,J) or MID$(S$,1,2) do not separate items
 
 	for _, char := range args {
 		if char == '"' {
 			inString = !inString
 			current += string(char)
-		} else if !inString && (char == ';' || char == ',') {
+		} else if !inString && (char == '(' || char == ')') {
+			if char == '(' {
+				depth++
+			} else {
+				depth--
+			}
+			current += string(char)
+		} else if !inString && depth == 0 && (char == ';' || char == ',') {
 			if strings.TrimSpace(current) != "" {
 				items = append(items, BytecodePrintItem{
 					expression: strings.TrimSpace(current),
@@ -800,109 +1069,114 @@ func (c *BytecodeCompiler) parsePrintItems(args string) []BytecodePrintItem {
 
 // compileInput compiles INPUT statements
 func (c *BytecodeCompiler) compileInput(args string) error {
-	// Simple INPUT variable
-	varName := strings.TrimSpace(args)
-	if varName == "" {
-		return fmt.Errorf("INPUT requires variable name")
+	st, errCode := parseInputStatement(args)
+	if errCode != "" {
+		return fmt.Errorf("invalid INPUT (%s), usage: %s", errCode, inputUsage)
+	}
+	// The default is evaluated when INPUT runs, so it goes on the stack
+	if st.defaultExpr != "" {
+		if err := c.compileExpression(st.defaultExpr); err != nil {
+			return fmt.Errorf("error compiling INPUT default: %v", err)
+		}
 	}
-
-	c.Emit(OP_INPUT, varName)
+	c.Emit(OP_INPUT, st)
 	return nil
 }
 
-// compileIf compiles IF statements
+// compileIf compiles IF condition THEN statements [ELSE statements].
+// The ELSE part may be another IF, so "ELSE IF" chains compile to one
+// JUMP_UNLESS per condition, and every taken branch leaves the chain with
+// a JUMP_ADDR past the last ELSE.
 func (c *BytecodeCompiler) compileIf(args string) error {
-	// Parse IF condition THEN statement [ELSE statement]
 	args = strings.TrimSpace(args)
 
-	// Find THEN keyword
-	thenIdx := -1
-	upperArgs := strings.ToUpper(args)
-	for i := 0; i < len(upperArgs)-4; i++ {
-		if upperArgs[i:i+5] == " THEN" && (i+5 >= len(upperArgs) || upperArgs[i+5] == ' ') {
-			thenIdx = i + 1
-			break
-		}
-	}
-
+	thenIdx := findKeywordOutsideStrings(args, "THEN")
 	if thenIdx == -1 {
 		return fmt.Errorf("IF statement missing THEN keyword")
 	}
+	condition := strings.TrimSpace(args[:thenIdx])
+	thenPart := strings.TrimSpace(args[thenIdx+4:]) // Skip "THEN"
 
-	condition := strings.TrimSpace(args[:thenIdx-1])
-	remaining := strings.TrimSpace(args[thenIdx+4:]) // Skip "THEN"
-
-	// Check for ELSE
-	elseIdx := -1
-	upperRemaining := strings.ToUpper(remaining)
-	for i := 0; i < len(upperRemaining)-4; i++ {
-		if upperRemaining[i:i+5] == " ELSE" && (i+5 >= len(upperRemaining) || upperRemaining[i+5] == ' ') {
-			elseIdx = i + 1
-			break
-		}
-	}
-
-	var thenPart, elsePart string
-	if elseIdx != -1 {
-		thenPart = strings.TrimSpace(remaining[:elseIdx-1])
-		elsePart = strings.TrimSpace(remaining[elseIdx+4:]) // Skip "ELSE"
-	} else {
-		thenPart = remaining
+	// The first ELSE belongs to this IF, a nested IF gets the rest
+	elsePart := ""
+	if elseIdx := findKeywordOutsideStrings(thenPart, "ELSE"); elseIdx != -1 {
+		elsePart = strings.TrimSpace(thenPart[elseIdx+4:]) // Skip "ELSE"
+		thenPart = strings.TrimSpace(thenPart[:elseIdx])
 	}
 
-	// Compile condition
-	err := c.compileExpression(condition)
-	if err != nil {
+	if err := c.compileExpression(condition); err != nil {
 		return fmt.Errorf("error compiling IF condition '%s': %v", condition, err)
 	}
 
-	// Check if THEN part is a line number (GOTO) or statement
+	// IF condition THEN linenum [ELSE statements]
 	if lineNum, err := strconv.Atoi(thenPart); err == nil {
-		// THEN line number - conditional jump
+		c.Emit(OP_JUMP_IF, lineNum)
 		if elsePart != "" {
-			// IF condition THEN linenum ELSE statement
-			c.Emit(OP_JUMP_IF, lineNum)
-			return c.compileStatement(elsePart)
-		} else {
-			// IF condition THEN linenum
-			c.Emit(OP_JUMP_IF, lineNum)
+			return c.compileIfBranch(elsePart)
 		}
-	} else {
-		// THEN statement - conditional execution
-		jumpAddr := len(c.instructions)
-		c.Emit(OP_JUMP_UNLESS, 0) // Placeholder address
-
-		// Compile the THEN statement
-		err = c.compileStatement(thenPart)
-		if err != nil {
-			return fmt.Errorf("error compiling THEN statement '%s': %v", thenPart, err)
-		}
-
-		if elsePart != "" {
-			// Jump over ELSE part
-			elseJumpAddr := len(c.instructions)
-			c.Emit(OP_JUMP, 0) // Placeholder address
+		return nil
+	}
 
-			// Update JUMP_UNLESS to point here (start of ELSE)
-			c.instructions[jumpAddr].Operand1 = len(c.instructions)
+	jumpAddr := len(c.instructions)
+	c.Emit(OP_JUMP_UNLESS, 0) // Patched below
+	if err := c.compileIfBranch(thenPart); err != nil {
+		return fmt.Errorf("error compiling THEN statement '%s': %v", thenPart, err)
+	}
+	if elsePart == "" {
+		c.instructions[jumpAddr].Operand1 = len(c.instructions)
+		return nil
+	}
 
-			// Compile ELSE statement
-			err = c.compileStatement(elsePart)
-			if err != nil {
-				return fmt.Errorf("error compiling ELSE statement '%s': %v", elsePart, err)
-			}
+	endJumpAddr := len(c.instructions)
+	c.Emit(OP_JUMP_ADDR, 0) // Patched below
+	c.instructions[jumpAddr].Operand1 = len(c.instructions)
+	if err := c.compileIfBranch(elsePart); err != nil {
+		return fmt.Errorf("error compiling ELSE statement '%s': %v", elsePart, err)
+	}
+	c.instructions[endJumpAddr].Operand1 = len(c.instructions)
+	return nil
+}
 
-			// Update ELSE jump to point after ELSE
-			c.instructions[elseJumpAddr].Operand1 = len(c.instructions)
-		} else {
-			// Update JUMP_UNLESS to point after THEN statement
-			c.instructions[jumpAddr].Operand1 = len(c.instructions)
+// compileIfBranch compiles the THEN or ELSE part of an IF: either a line
+// number to jump to or colon separated statements
+func (c *BytecodeCompiler) compileIfBranch(part string) error {
+	if lineNum, err := strconv.Atoi(part); err == nil {
+		c.Emit(OP_JUMP, lineNum)
+		return nil
+	}
+	// A nested IF takes everything up to the end of the line, like the interpreter
+	if ifThenPattern.MatchString(part) {
+		return c.compileStatement(part)
+	}
+	for _, stmt := range c.splitStatements(part) {
+		if err := c.compileStatement(stmt); err != nil {
+			return err
 		}
 	}
-
 	return nil
 }
 
+// findKeywordOutsideStrings returns the index of keyword as a separate word
+// (preceded by a space, followed by a space or the end) outside of string
+// literals, or -1
+func findKeywordOutsideStrings(s, keyword string) int {
+	upper := strings.ToUpper(s)
+	inString := false
+	for i := 0; i < len(upper); i++ {
+		if upper[i] == '"' {
+			inString = !inString
+			continue
+		}
+		if inString || i == 0 || upper[i-1] != ' ' || !strings.HasPrefix(upper[i:], keyword) {
+			continue
+		}
+		if end := i + len(keyword); end == len(upper) || upper[end] == ' ' {
+			return i
+		}
+	}
+	return -1
+}
+
 // compileGoto compiles GOTO statements
 func (c *BytecodeCompiler) compileGoto(args string) error {
 	lineNum, err := strconv.Atoi(strings.TrimSpace(args))
@@ -1022,6 +1296,14 @@ func (c *BytecodeCompiler) compileSound(args string) error {
 		return fmt.Errorf("SOUND requires frequency and duration arguments")
 	}
 
+	if on, isWait, valid := parseSoundWait(args); isWait {
+		if !valid {
+			return fmt.Errorf("SOUND WAIT requires ON or OFF")
+		}
+		c.Emit(OP_SOUND_WAIT, on)
+		return nil
+	}
+
 	// Parse SOUND frequency, duration
 	parts := strings.Split(args, ",")
 	if len(parts) != 2 {
@@ -1124,21 +1406,37 @@ func (c *BytecodeCompiler) compileCls(args string) error {
 	return nil
 }
 
-// compileMusic compiles MUSIC statements
+// compileMusic compiles MUSIC statements. The subcommand becomes Operand1,
+// the filename of OPEN, the number of TUNE and the ON/OFF of SCOPE are
+// compiled onto the stack.
 func (c *BytecodeCompiler) compileMusic(args string) error {
-	// MUSIC command takes a string argument
-	if args == "" {
-		return fmt.Errorf("MUSIC requires a filename argument")
-	}
-
-	// Compile the filename expression
-	err := c.compileExpression(args)
-	if err != nil {
-		return fmt.Errorf("error compiling MUSIC filename: %v", err)
+	subcommand, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	subcommand = strings.ToUpper(subcommand)
+	rest = strings.TrimSpace(rest)
+
+	switch subcommand {
+	case "OPEN", "TUNE":
+		if rest == "" {
+			return fmt.Errorf("MUSIC %s requires an argument", subcommand)
+		}
+		if err := c.compileExpression(rest); err != nil {
+			return fmt.Errorf("error compiling MUSIC %s argument: %v", subcommand, err)
+		}
+	case "SCOPE":
+		mode := strings.ToUpper(rest)
+		if mode != "ON" && mode != "OFF" {
+			return fmt.Errorf("MUSIC SCOPE requires ON or OFF")
+		}
+		c.EmitConstant(mode)
+	case "PLAY", "PAUSE", "RESUME", "STOP":
+		if rest != "" {
+			return fmt.Errorf("MUSIC %s takes no arguments", subcommand)
+		}
+	default:
+		return fmt.Errorf("unknown MUSIC subcommand %q", subcommand)
 	}
 
-	// Emit MUSIC instruction
-	c.Emit(OP_MUSIC)
+	c.Emit(OP_MUSIC, subcommand)
 	return nil
 }
 
@@ -1269,6 +1567,50 @@ func (c *BytecodeCompiler) compileCircle(args string) error {
 	return nil
 }
 
+// compilePaint compiles PAINT x, y, color [, border] as a call of the builtin PAINT
+func (c *BytecodeCompiler) compilePaint(args string) error {
+	parts := splitRespectingParentheses(strings.TrimSpace(args))
+	if len(parts) < 3 || len(parts) > 4 {
+		return fmt.Errorf("PAINT requires 3 or 4 arguments: x, y, color [, border]")
+	}
+
+	for i, part := range parts {
+		if err := c.compileExpression(strings.TrimSpace(part)); err != nil {
+			return fmt.Errorf("error compiling parameter %d: %v", i+1, err)
+		}
+	}
+
+	c.Emit(OP_CALL_FUNC, "PAINT", len(parts))
+	return nil
+}
+
+// compileSaveVar compiles SAVEVAR key$, value as a call of the builtin SAVEVAR
+func (c *BytecodeCompiler) compileSaveVar(args string) error {
+	parts := splitTopLevelArgs(strings.TrimSpace(args))
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("SAVEVAR requires a key and a value")
+	}
+	for i, part := range parts {
+		if err := c.compileExpression(part); err != nil {
+			return fmt.Errorf("error compiling SAVEVAR parameter %d: %v", i+1, err)
+		}
+	}
+	c.Emit(OP_CALL_FUNC, "SAVEVAR", 2)
+	return nil
+}
+
+// compileDraw compiles DRAW commands$; the VM runs the string
+func (c *BytecodeCompiler) compileDraw(args string) error {
+	if strings.TrimSpace(args) == "" {
+		return fmt.Errorf("DRAW requires a command string")
+	}
+	if err := c.compileExpression(strings.TrimSpace(args)); err != nil {
+		return fmt.Errorf("error compiling DRAW string: %v", err)
+	}
+	c.Emit(OP_CALL_FUNC, "DRAW", 1)
+	return nil
+}
+
 // compileSprite compiles SPRITE statements
 func (c *BytecodeCompiler) compileSprite(args string) error {
 	if args == "" {
@@ -1416,36 +1758,23 @@ func (c *BytecodeCompiler) compileLocate(args string) error {
 	return nil
 }
 
-// compileColor compiles COLOR statements
+// compileColor rejects COLOR: the interpreter has no such command either,
+// so the program runs there and reports it in the line it is used
 func (c *BytecodeCompiler) compileColor(args string) error {
-	if args == "" {
-		return fmt.Errorf("COLOR requires color argument")
-	}
-
-	// Compile color expression
-	err := c.compileExpression(args)
-	if err != nil {
-		return fmt.Errorf("error compiling COLOR argument: %v", err)
-	}
-
-	// Emit COLOR instruction
-	c.Emit(OP_COLOR)
-	return nil
+	return fmt.Errorf("COLOR is not supported")
 }
 
-// compileKey compiles KEY statements
+// compileKey compiles KEY n, text$
 func (c *BytecodeCompiler) compileKey(args string) error {
-	if args == "" {
-		return fmt.Errorf("KEY requires arguments")
+	params := splitRespectingParentheses(args)
+	if len(params) != 2 {
+		return fmt.Errorf("KEY requires a key number and a text")
 	}
-
-	// For KEY, compile the entire argument string
-	err := c.compileExpression(fmt.Sprintf("\"%s\"", args))
-	if err != nil {
-		return fmt.Errorf("error compiling KEY arguments: %v", err)
+	for _, param := range params {
+		if err := c.compileExpression(strings.TrimSpace(param)); err != nil {
+			return fmt.Errorf("error compiling KEY argument '%s': %v", param, err)
+		}
 	}
-
-	// Emit KEY instruction
 	c.Emit(OP_KEY)
 	return nil
 }
@@ -1484,37 +1813,41 @@ func (c *BytecodeCompiler) compileRead(args string) error {
 	return nil
 }
 
-// compileDim compiles DIM statements
+// compileDim compiles DIM statements. Each array gets its own OP_DIM with
+// the upper bounds on the stack.
 func (c *BytecodeCompiler) compileDim(args string) error {
 	if args == "" {
 		return fmt.Errorf("DIM requires array declaration")
 	}
 
-	// For DIM, compile the entire argument string as a string literal
-	err := c.compileExpression(fmt.Sprintf("\"%s\"", args))
-	if err != nil {
-		return fmt.Errorf("error compiling DIM arguments: %v", err)
+	for _, def := range splitRespectingParentheses(args) {
+		name, dimensions, ok := splitArrayDef(def)
+		if !ok || !c.isValidVariableName(name) || len(dimensions) > maxArrayDimensions {
+			return fmt.Errorf("invalid DIM declaration: %s", def)
+		}
+		for _, dimension := range dimensions {
+			if err := c.compileExpression(dimension); err != nil {
+				return fmt.Errorf("error compiling DIM size '%s': %v", dimension, err)
+			}
+		}
+		c.Emit(OP_DIM, name, len(dimensions))
 	}
-
-	// Emit DIM instruction
-	c.Emit(OP_DIM)
 	return nil
 }
 
-// compileTextGfx compiles TEXTGFX statements
+// compileTextGfx compiles TEXTGFX x, y, text [, color [, size]]. The
+// arguments are evaluated in the VM, so variables come from the program.
 func (c *BytecodeCompiler) compileTextGfx(args string) error {
-	if args == "" {
-		return fmt.Errorf("TEXTGFX requires arguments")
+	params := splitRespectingParentheses(args)
+	if len(params) < 3 || len(params) > 5 {
+		return fmt.Errorf("TEXTGFX requires 3 to 5 arguments")
 	}
-
-	// For TEXTGFX, compile the entire argument string as a string literal
-	err := c.compileExpression(fmt.Sprintf("\"%s\"", args))
-	if err != nil {
-		return fmt.Errorf("error compiling TEXTGFX arguments: %v", err)
+	for _, param := range params {
+		if err := c.compileExpression(strings.TrimSpace(param)); err != nil {
+			return fmt.Errorf("error compiling TEXTGFX argument '%s': %v", param, err)
+		}
 	}
-
-	// Emit TEXTGFX instruction
-	c.Emit(OP_TEXTGFX)
+	c.Emit(OP_TEXTGFX, len(params))
 	return nil
 }
 
@@ -1548,8 +1881,8 @@ func (c *BytecodeCompiler) compileInverse(args string) error {
 
 // compileRandomize compiles RANDOMIZE statements
 func (c *BytecodeCompiler) compileRandomize(args string) error {
-	if args == "" {
-		// RANDOMIZE without argument - use current time
+	if args == "" || strings.EqualFold(strings.TrimSpace(args), "TIMER") {
+		// RANDOMIZE without argument or RANDOMIZE TIMER - use current time
 		c.Emit(OP_RANDOMIZE)
 		return nil
 	}
@@ -1571,7 +1904,6 @@ func (c *BytecodeCompiler) compileFunction(command, args string) error {
 	extendedCommands := map[string]bool{
 		"CALL":   true,
 		"LOAD":   true,
-		"SAVE":   true,
 		"OPEN":   true,
 		"CLOSE":  true,
 		"MCP":    true,
@@ -1690,7 +2022,7 @@ func (inst Instruction) String() string {
 		return fmt.Sprintf("%s %v", inst.OpCode, inst.Operand1)
 	case OP_LOAD_VAR, OP_STORE_VAR:
 		return fmt.Sprintf("%s %s", inst.OpCode, inst.Operand1)
-	case OP_JUMP, OP_JUMP_IF, OP_JUMP_UNLESS, OP_CALL:
+	case OP_JUMP, OP_JUMP_IF, OP_JUMP_UNLESS, OP_JUMP_ADDR, OP_CALL:
 		return fmt.Sprintf("%s %v", inst.OpCode, inst.Operand1)
 	default:
 		return string(inst.OpCode)
@@ -1722,6 +2054,23 @@ func (op OpCode) String() string {
 		"PRINT", "PRINT_NL", "INPUT",
 		"HALT", "NOP", "SOUND", "WAIT", "NOISE", "BEEP", "CLS", "MUSIC", "SPEAK", "PLOT", "LINE", "RECT", "CIRCLE", "SPRITE", "VECTOR", "SAY", "LOCATE", "COLOR", "KEY", "DATA", "READ", "DIM", "TEXTGFX", "CLEARGRAPHICS", "INVERSE", "RANDOMIZE", "DEBUG",
 		"CALL_FUNC", "STR_CONCAT", "STR_LEN", "STR_MID",
+		"ON_ERROR", "RESUME",
+		"MID_ASSIGN",
+		"OPTION",
+		"JUMP_ADDR",
+		"STOP",
+		"SOUND_WAIT",
+		"RESTORE",
+		"SLEEP",
+		"SCREEN",
+		"STORE_ARRAY",
+		"COMMON",
+		"CHAIN",
+		"ON_KEY",
+		"JUSTIFY",
+		"ON_EVENT",
+		"TIMER",
+		"WIDTH",
 	}
 
 	if int(op) < len(names) {
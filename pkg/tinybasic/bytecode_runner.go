@@ -10,9 +10,17 @@ import (
 
 // runBytecodeProgram executes the compiled bytecode program
 func (b *TinyBASIC) runBytecodeProgram() {
+	// Set when CHAIN hands the run to the interpreter, which then finishes it
+	handedOff := false
 	defer func() {
+		if handedOff {
+			return
+		}
 		b.mu.Lock()
 		b.running = false
+		b.endScreenBuffer()
+		b.resetMusic()
+		b.resetWidth()
 		wasEnableSent := b.inputControlEnableSent
 		callback := b.onProgramEnd // Get callback reference before unlocking
 		b.mu.Unlock()
@@ -30,6 +38,7 @@ func (b *TinyBASIC) runBytecodeProgram() {
 		if !wasEnableSent {
 			b.sendInputControl("enable")
 		}
+		b.reportBenchmark(b.bytecodeVM)
 		// Send OK when program execution is complete
 		b.sendMessageWrapped(shared.MessageTypeText, "OK")
 		// Call the callback if set (for autorun mode to return to TinyOS)
@@ -42,6 +51,10 @@ func (b *TinyBASIC) runBytecodeProgram() {
 		}
 	}()
 
+	// A run stopped by BREAK may still be unwinding; never load a program
+	// into a VM that is executing
+	b.bytecodeVM.Wait()
+
 	// Ensure we have a compiled program
 	b.mu.Lock()
 	if b.compiledProgram == nil {
@@ -61,6 +74,14 @@ func (b *TinyBASIC) runBytecodeProgram() {
 	}
 
 	b.bytecodeVM.LoadProgram(b.compiledProgram)
+	// RUN <line> leaves the start line in currentLine
+	if b.currentLine != 0 {
+		if err := b.bytecodeVM.StartAt(b.currentLine); err != nil {
+			b.mu.Unlock()
+			b.sendMessageWrapped(shared.MessageTypeText, fmt.Sprintf("RUNTIME ERROR: %v", err))
+			return
+		}
+	}
 
 	// Copy variables from interpreter to VM
 	varCount := 0
@@ -81,8 +102,17 @@ func (b *TinyBASIC) runBytecodeProgram() {
 	for varName, value := range vmVariables {
 		b.variables[varName] = value
 	}
+	handOff := b.chainHandOff
+	b.chainHandOff = false
 	b.mu.Unlock()
 
+	if err == nil && handOff {
+		// CHAIN loaded a program the VM cannot run
+		handedOff = true
+		b.runProgramInternal(b.ctx)
+		return
+	}
+
 	if err != nil {
 		// Handle bytecode execution errors
 		if err == context.Canceled {
@@ -90,6 +120,11 @@ func (b *TinyBASIC) runBytecodeProgram() {
 		} else {
 			tinyBasicDebugLog("Bytecode execution error: %v", err)
 			b.sendMessageWrapped(shared.MessageTypeText, fmt.Sprintf("RUNTIME ERROR: %v", err))
+			b.playErrorSFX()
+			b.mu.Lock()
+			b.lastErrorLine = b.bytecodeVM.CurrentLine()
+			b.lastErrorMessage = err.Error()
+			b.mu.Unlock()
 		}
 	}
 }
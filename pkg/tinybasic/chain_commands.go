@@ -0,0 +1,164 @@
+package tinybasic
+
+import (
+	"strings"
+)
+
+// commonUsage is the usage hint for COMMON
+const commonUsage = "COMMON var[, var...]  (arrays as A())"
+
+// parseCommonList splits the variable list of COMMON into uppercased names,
+// arrays keep their "()". errCode is the BASIC error code if args are not
+// valid, empty otherwise.
+func parseCommonList(args string) (names []string, errCode string) {
+	if strings.TrimSpace(args) == "" {
+		return nil, "EXPECTED_VARIABLE"
+	}
+	for _, part := range strings.Split(args, ",") {
+		name := strings.ToUpper(strings.Join(strings.Fields(part), ""))
+		if !isValidVarName(strings.TrimSuffix(name, "()")) {
+			return nil, "EXPECTED_VARIABLE"
+		}
+		names = append(names, name)
+	}
+	return names, ""
+}
+
+// cmdCommon implements COMMON: the listed variables keep their values when
+// CHAIN starts another program. Assumes lock is held.
+func (b *TinyBASIC) cmdCommon(args string) error {
+	names, errCode := parseCommonList(args)
+	if errCode != "" {
+		return NewBASICError(ErrCategorySyntax, errCode, b.currentLine == 0, b.currentLine).
+			WithCommand("COMMON").WithUsageHint(commonUsage)
+	}
+	b.declareCommon(names)
+	return nil
+}
+
+// declareCommon marks names as kept by CHAIN. Assumes lock is held.
+func (b *TinyBASIC) declareCommon(names []string) {
+	if b.commonVars == nil {
+		b.commonVars = make(map[string]bool)
+	}
+	for _, name := range names {
+		b.commonVars[name] = true
+	}
+}
+
+// commonVariables returns the variables declared with COMMON. An array
+// brings all its elements and its DIM bounds. Assumes lock is held.
+func (b *TinyBASIC) commonVariables() map[string]BASICValue {
+	kept := make(map[string]BASICValue)
+	for name := range b.commonVars {
+		if base, isArray := strings.CutSuffix(name, "()"); isArray {
+			for key, value := range b.variables {
+				if strings.HasPrefix(key, base+"(") {
+					kept[key] = value
+				}
+			}
+			continue
+		}
+		if value, ok := b.variables[name]; ok {
+			kept[name] = value
+		}
+	}
+	return kept
+}
+
+// cmdChain implements CHAIN "file" in a running program. The run loop goes
+// on with the first line of the chained program. Assumes lock is held.
+func (b *TinyBASIC) cmdChain(args string) error {
+	if !b.running {
+		return NewBASICError(ErrCategoryExecution, "COMMAND_NOT_IN_DIRECT", true, 0).
+			WithCommand("CHAIN").WithUsageHint(`Use RUN "filename" to start a program`)
+	}
+	if err := b.chain(args); err != nil {
+		return err
+	}
+	b.chained = true
+	return nil
+}
+
+// chain replaces the running program with the program file args names.
+// Only the COMMON variables are kept; loops, subroutines, DATA, ON ERROR,
+// ON KEY, OPTION settings and open files start fresh as with RUN; KEY
+// macros stay defined. If the file cannot be loaded the running program is
+// left as it was. Assumes lock is held.
+func (b *TinyBASIC) chain(args string) error {
+	program, err := b.readProgramFile(args, "CHAIN")
+	if err != nil {
+		return err
+	}
+	if len(program) == 0 {
+		return NewBASICError(ErrCategoryExecution, "NO_PROGRAM_LINES", false, b.currentLine).WithCommand("CHAIN")
+	}
+
+	kept := b.commonVariables()
+	b.program = program
+	b.rebuildProgramLines()
+	b.rebuildData()
+	b.variables = kept
+	b.initializeKeyConstants()
+	b.gosubStack = b.gosubStack[:0]
+	b.forLoops = b.forLoops[:0]
+	b.forLoopIndexMap = make(map[string]int)
+	b.resumeSubStatementIndex = 0
+	b.errTrap.reset()
+	b.fnKeys.resetTraps()
+	b.events.reset()
+	b.angleDegrees = false
+	b.compareText = false
+	b.commonVars = nil
+	b.closeAllFiles()
+	clearExpressionCache()
+	b.currentLine = b.programLines[0]
+	return nil
+}
+
+// handleCommon is the bytecode version of COMMON. Operand1 holds the names
+// as returned by parseCommonList.
+func (vm *BytecodeVM) handleCommon(inst *Instruction) error {
+	if vm.tinybasic != nil {
+		vm.tinybasic.mu.Lock()
+		vm.tinybasic.declareCommon(inst.Operand1.([]string))
+		vm.tinybasic.mu.Unlock()
+	}
+	vm.pc++
+	return nil
+}
+
+// handleChain is the bytecode version of CHAIN. Operand1 is the filename
+// expression. A chained program that compiles goes on in the VM; any other
+// is handed to the interpreter when Run returns.
+func (vm *BytecodeVM) handleChain(inst *Instruction) error {
+	b := vm.tinybasic
+	if b == nil {
+		return NewBASICError(ErrCategoryFileSystem, "FILE_SYSTEM_ERROR", false, inst.LineNum).WithCommand("CHAIN")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// The filename and the COMMON values come from the VM variables
+	b.variables = vm.GetVariables()
+	b.currentLine = inst.LineNum
+	if err := b.chain(inst.Operand1.(string)); err != nil {
+		return err
+	}
+	vm.variables = make(map[string]BASICValue, len(b.variables))
+	for name, value := range b.variables {
+		vm.variables[name] = value
+	}
+
+	if err := b.compileProgramIfNeeded(); err != nil {
+		tinyBasicDebugLog("CHAIN: chained program does not compile, handing over to the interpreter: %v", err)
+		b.chainHandOff = true
+		vm.running = false
+		return nil
+	}
+	vm.LoadProgram(b.compiledProgram)
+	vm.resetExecutionStacks()
+	vm.errTrap.reset()
+	vm.running = true
+	return nil
+}
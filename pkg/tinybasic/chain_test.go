@@ -0,0 +1,116 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+// runChainProgram runs a program that can CHAIN to the files in fs and
+// returns its text output without spaces and line breaks. With bytecode the
+// program must start in the VM.
+func runChainProgram(t *testing.T, bytecode bool, fs memoryFS, lines ...string) string {
+	t.Helper()
+	b := NewTinyBASIC(nil)
+	b.fs = fs
+	startSleepProgram(b, bytecode, lines...)
+	waitUntilStopped(t, b)
+	if bytecode && b.compiledProgram == nil {
+		t.Fatal("program did not run in the bytecode VM")
+	}
+	return strings.NewReplacer(" ", "", "\n", "").Replace(textOutput(b))
+}
+
+// TestChainKeepsCommonVariables chains to a program the VM can run and to
+// one it cannot; both see only the COMMON variables
+func TestChainKeepsCommonVariables(t *testing.T) {
+	fs := memoryFS{
+		"part2.bas": "10 PRINT \"S\"; S; \"N\"; N$; \"A\"; A(2)\n20 ON ERROR GOTO 40\n30 PRINT \"T\"; T\n40 PRINT \"END\"\n",
+		"part3.bas": "10 X = S + 1\n20 PRINT \"X\"; X; \"A\"; A(2)\n",
+	}
+	for _, next := range []string{"part2", "part3.bas"} {
+		for _, bytecode := range []bool{false, true} {
+			out := runChainProgram(t, bytecode, fs,
+				`10 DIM A(2)`,
+				`20 LET A(2) = 7`,
+				`30 LET S = 42: LET N$ = "ANN": LET T = 5`,
+				`40 COMMON S, N$, A()`,
+				`50 LET F$ = "`+next+`"`,
+				`60 CHAIN F$`,
+				`70 PRINT "NOT REACHED"`,
+			)
+			want := "S42NANNA7"
+			if next == "part3.bas" {
+				want = "X43A7"
+			}
+			if !strings.Contains(out, want) || strings.Contains(out, "T5") ||
+				strings.Contains(out, "NOTREACHED") || strings.Contains(out, "ERROR") {
+				t.Errorf("CHAIN %s, bytecode=%v: output %q, want %q", next, bytecode, out, want)
+			}
+		}
+	}
+}
+
+// TestChainStartsAtFirstLine chains to a program whose first line has the
+// number of the CHAIN line
+func TestChainStartsAtFirstLine(t *testing.T) {
+	fs := memoryFS{"same.bas": "30 PRINT \"FIRST\"; S\n40 PRINT \"SECOND\"\n"}
+	for _, bytecode := range []bool{false, true} {
+		out := runChainProgram(t, bytecode, fs,
+			`10 LET S = 1`,
+			`20 COMMON S`,
+			`30 CHAIN "same": PRINT "NOT REACHED"`,
+		)
+		if !strings.Contains(out, "FIRST1SECOND") || strings.Contains(out, "NOTREACHED") {
+			t.Errorf("bytecode=%v: output %q", bytecode, out)
+		}
+	}
+}
+
+// TestChainMissingFile expects FILE NOT FOUND, which ON ERROR can trap
+func TestChainMissingFile(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		out := runChainProgram(t, bytecode, memoryFS{},
+			`10 CHAIN "NOWHERE"`,
+			`20 PRINT "NOT REACHED"`,
+		)
+		if !strings.Contains(out, "INLINE10:FILENOTFOUND") || strings.Contains(out, "NOTREACHED") {
+			t.Errorf("bytecode=%v: output %q", bytecode, out)
+		}
+
+		out = runChainProgram(t, bytecode, memoryFS{},
+			`10 ON ERROR GOTO 100`,
+			`20 LET S = 3`,
+			`30 CHAIN "NOWHERE"`,
+			`40 END`,
+			`100 PRINT "ERR"; ERR; "ERL"; ERL; "S"; S`,
+		)
+		if !strings.Contains(out, "ERR53ERL30S3") {
+			t.Errorf("bytecode=%v: missing file not trapped, output %q", bytecode, out)
+		}
+	}
+}
+
+// TestChainNotInDirectMode expects CHAIN at the prompt to be refused
+func TestChainNotInDirectMode(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	b.fs = memoryFS{"part2.bas": "10 PRINT \"RAN\"\n"}
+	b.mu.Lock()
+	err := b.cmdChain(`"part2"`)
+	b.mu.Unlock()
+	if err == nil || !strings.Contains(err.Error(), "DIRECT MODE") {
+		t.Errorf("CHAIN in direct mode: got %v", err)
+	}
+}
+
+// TestParseCommonList checks the variable list of COMMON
+func TestParseCommonList(t *testing.T) {
+	names, errCode := parseCommonList(" s, name$ , map( ) ")
+	if errCode != "" || strings.Join(names, " ") != "S NAME$ MAP()" {
+		t.Errorf("parseCommonList = %v, %q", names, errCode)
+	}
+	for _, args := range []string{"", "A,", "1X", "A(1)"} {
+		if _, errCode := parseCommonList(args); errCode == "" {
+			t.Errorf("parseCommonList(%q) accepted", args)
+		}
+	}
+}
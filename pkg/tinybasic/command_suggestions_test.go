@@ -0,0 +1,47 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestUnknownCommandSuggestion checks the "did you mean" hint for typos in
+// direct mode and in programs
+func TestUnknownCommandSuggestion(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	if out := directOutput(b, `PRNT "HI"`); !strings.Contains(out, "DID YOU MEAN PRINT?") {
+		t.Errorf("direct mode: no suggestion in %q", out)
+	}
+	if out := directOutput(b, `XYZZY 1`); strings.Contains(out, "DID YOU MEAN") {
+		t.Errorf("direct mode: unexpected suggestion in %q", out)
+	}
+
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode, `10 GOSUBB 100`, `100 RETURN`)
+		if !strings.Contains(out, "DID YOU MEAN GOSUB?") {
+			t.Errorf("bytecode=%v: no suggestion in %q", bytecode, out)
+		}
+	}
+}
+
+// TestCommandSuggestions checks which statements are offered
+func TestCommandSuggestions(t *testing.T) {
+	tests := []struct {
+		typo string
+		want string
+	}{
+		{"PIRNT", "PRINT"},
+		{"GOTOO", "GOTO"},
+		{"RESOTRE", "RESTORE"},
+		{"PRT", ""},           // "PR." is an abbreviation, not a suggestion
+		{"Q", ""},             // too short to guess
+		{"PRINT", ""},         // known commands get no suggestion
+		{"COMPLETELYOFF", ""}, // too far from anything
+	}
+	for _, tt := range tests {
+		got := strings.Join(commandSuggestions(tt.typo), ",")
+		if tt.want == "" && got != "" || tt.want != "" && !strings.HasPrefix(got, tt.want) {
+			t.Errorf("commandSuggestions(%q) = %q, want %q first", tt.typo, got, tt.want)
+		}
+	}
+}
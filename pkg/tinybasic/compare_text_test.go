@@ -0,0 +1,52 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestOptionCompareText checks with the bytecode VM and the interpreter that
+// "apple" < "Banana" is false byte-wise and true after OPTION COMPARE TEXT
+func TestOptionCompareText(t *testing.T) {
+	program := []string{
+		`10 A$ = "apple"`,
+		`20 B$ = "Banana"`,
+		`30 IF A$ < B$ THEN PRINT "BINARY LESS" ELSE PRINT "BINARY GREATER"`,
+		`40 OPTION COMPARE TEXT`,
+		`50 IF A$ < B$ THEN PRINT "TEXT LESS" ELSE PRINT "TEXT GREATER"`,
+		`60 IF "HELLO" = "hello" THEN PRINT "TEXT EQUAL"`,
+		`70 OPTION COMPARE BINARY`,
+		`80 IF "HELLO" <> "hello" THEN PRINT "BINARY DIFFERENT"`,
+		`90 OPTION COMPARE TEXT`,
+	}
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode, program...)
+		for _, want := range []string{"BINARY GREATER", "TEXT LESS", "TEXT EQUAL", "BINARY DIFFERENT"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("bytecode=%v: output %q does not contain %q", bytecode, out, want)
+			}
+		}
+	}
+}
+
+// TestOptionCompareResetByRun checks that RUN starts with binary comparison
+func TestOptionCompareResetByRun(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	b.Execute("OPTION COMPARE TEXT")
+	if out := directOutput(b, `IF "apple" < "Banana" THEN PRINT "LESS"`); !strings.Contains(out, "LESS") {
+		t.Errorf("text comparison printed %q, want LESS", out)
+	}
+	if err := b.cmdOption("COMPARE NOCASE"); err == nil {
+		t.Error("OPTION COMPARE NOCASE should be rejected")
+	}
+
+	b.Execute("10 END")
+	b.Execute("RUN")
+	waitUntilStopped(t, b)
+	if b.compareText {
+		t.Error("RUN should reset OPTION COMPARE")
+	}
+	if out := directOutput(b, `IF "apple" < "Banana" THEN PRINT "LESS"`); strings.Contains(out, "LESS") {
+		t.Errorf("binary comparison printed %q, want nothing", out)
+	}
+}
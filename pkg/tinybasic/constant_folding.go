@@ -0,0 +1,88 @@
+package tinybasic
+
+import "math"
+
+// emitOperator emits an arithmetic operator, or folds it into a single
+// PUSH_NUM if its operands are constants. The parser emits postfix code, so
+// an operand ending in PUSH_NUM is exactly that constant and precedence is
+// already resolved: X+2*3 folds to X+6, while X+2+3 stays (X+2)+3.
+func (p *ExpressionParser) emitOperator(op OpCode) {
+	c := p.compiler
+	if !c.noFolding {
+		if op == OP_NEG {
+			if a, ok := c.constantAt(len(c.instructions)-1, p.start); ok {
+				c.replaceConstants(1, -a)
+				return
+			}
+		} else if b, ok := c.constantAt(len(c.instructions)-1, p.start); ok {
+			if a, ok := c.constantAt(len(c.instructions)-2, p.start); ok {
+				if result, ok := foldArithmetic(op, a, b); ok {
+					c.replaceConstants(2, result)
+					return
+				}
+			}
+		}
+	}
+	c.Emit(op)
+}
+
+// constantAt returns the number pushed by instruction i if it is a PUSH_NUM
+// of the expression that started at instruction start
+func (c *BytecodeCompiler) constantAt(i, start int) (float64, bool) {
+	if i < start || i < 0 || c.instructions[i].OpCode != OP_PUSH_NUM {
+		return 0, false
+	}
+	index, ok := c.instructions[i].Operand1.(int)
+	if !ok || index < 0 || index >= len(c.constants) {
+		return 0, false
+	}
+	value, ok := c.constants[index].(float64)
+	return value, ok
+}
+
+// replaceConstants replaces the last n PUSH_NUM instructions with one that
+// pushes value. The pool slot of the first one is reused.
+func (c *BytecodeCompiler) replaceConstants(n int, value float64) {
+	first := len(c.instructions) - n
+	c.constants[c.instructions[first].Operand1.(int)] = value
+	c.instructions = c.instructions[:first+1]
+}
+
+// foldArithmetic computes a op b like the VM handlers do. Operations the VM
+// reports as errors, such as 1/0, are not folded, so the error still comes
+// up at run time in the right line; neither are results that are not finite.
+func foldArithmetic(op OpCode, a, b float64) (float64, bool) {
+	var result float64
+	switch op {
+	case OP_ADD:
+		result = a + b
+	case OP_SUB:
+		result = a - b
+	case OP_MUL:
+		result = a * b
+	case OP_DIV:
+		if b == 0 {
+			return 0, false
+		}
+		result = a / b
+	case OP_MOD:
+		if b == 0 {
+			return 0, false
+		}
+		result = math.Mod(a, b)
+	case OP_POW:
+		if (a == 0 && b < 0) || (a < 0 && b == 0.5) {
+			return 0, false
+		}
+		result = math.Pow(a, b)
+	default:
+		return 0, false
+	}
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return 0, false
+	}
+	if result == 0 {
+		result = 0 // No -0: the VM shortcuts give 0 for 0 * -3 and 0 / -3
+	}
+	return result, true
+}
@@ -0,0 +1,102 @@
+package tinybasic
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// compileOps compiles expr and returns its instructions as text
+func compileOps(t *testing.T, expr string) string {
+	t.Helper()
+	c := NewBytecodeCompiler()
+	if err := c.CompileExpression(expr); err != nil {
+		t.Fatalf("CompileExpression(%q): %v", expr, err)
+	}
+	ops := make([]string, len(c.instructions))
+	for i, inst := range c.instructions {
+		switch inst.OpCode {
+		case OP_PUSH_NUM:
+			ops[i] = "PUSH " + strconv.FormatFloat(c.constants[inst.Operand1.(int)].(float64), 'g', -1, 64)
+		case OP_LOAD_VAR:
+			ops[i] = "LOAD " + inst.Operand1.(string)
+		default:
+			ops[i] = inst.OpCode.String()
+		}
+	}
+	return strings.Join(ops, ", ")
+}
+
+// TestConstantFolding expects constant subexpressions next to variables to
+// be compiled into one PUSH_NUM, following operator precedence
+func TestConstantFolding(t *testing.T) {
+	tests := []struct{ expr, want string }{
+		{"X + 2 * 3", "LOAD X, PUSH 6, ADD"},
+		{"X * (2 + 3)", "LOAD X, PUSH 5, MUL"},
+		{"(1 + 2) * X - 8 / 2", "PUSH 3, LOAD X, MUL, PUSH 4, SUB"},
+		{"X * -3", "LOAD X, PUSH -3, MUL"},
+		{"X - 2 - 3", "LOAD X, PUSH 2, SUB, PUSH 3, SUB"},
+		{"X + 1 / 0", "LOAD X, PUSH 1, PUSH 0, DIV, ADD"},
+		{"X + 5 MOD 0", "LOAD X, PUSH 5, PUSH 0, MOD, ADD"},
+		{"X + 0 ^ -1", "LOAD X, PUSH 0, PUSH -1, POW, ADD"},
+	}
+	for _, tt := range tests {
+		if got := compileOps(t, tt.expr); got != tt.want {
+			t.Errorf("%s compiles to %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+// TestConstantFoldingResults runs folded expressions in both engines and
+// expects a division by zero to still fail at run time
+func TestConstantFoldingResults(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode,
+			`10 LET X = 4`,
+			`20 IF X + 2 * 3 = 10 AND X * (2 + 3) = 20 AND X * -3 = -12 AND X - 2 - 3 = -1 THEN PRINT "FOLDED"`,
+			`30 PRINT X + 1 / 0`,
+			`40 PRINT "AFTER"`,
+		)
+		if !strings.Contains(out, "FOLDED") {
+			t.Errorf("bytecode=%v: output %q does not contain the folded results", bytecode, out)
+		}
+		if strings.Contains(out, "AFTER") || !strings.Contains(out, "ERROR") {
+			t.Errorf("bytecode=%v: 1/0 did not fail at run time: %q", bytecode, out)
+		}
+	}
+}
+
+// BenchmarkConstantFolding runs a loop with constant subexpressions with and
+// without folding and reports the instructions of the compiled program
+func BenchmarkConstantFolding(b *testing.B) {
+	program := map[int]string{
+		10: "FOR I = 1 TO 1000",
+		20: "LET A = I * (60 * 60) + 24 / 2",
+		30: "LET B = A - 3.14159 * 2 ^ 2 + I * -1",
+		40: "NEXT I",
+	}
+	lines := []int{10, 20, 30, 40}
+	for _, mode := range []struct {
+		name      string
+		noFolding bool
+	}{{"folded", false}, {"unfolded", true}} {
+		b.Run(mode.name, func(b *testing.B) {
+			c := NewBytecodeCompiler()
+			c.noFolding = mode.noFolding
+			compiled, err := c.CompileProgram(program, lines)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				vm := NewBytecodeVM(nil)
+				vm.LoadProgram(compiled)
+				if err := vm.Run(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(compiled.Instructions)), "instructions")
+		})
+	}
+}
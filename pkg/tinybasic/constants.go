@@ -12,6 +12,12 @@ const (
 	// MaxGosubDepth defines the maximum nesting level for GOSUB calls.
 	MaxGosubDepth   = 100 // MaxForLoopDepth defines the maximum nesting level for FOR loops.
 	MaxForLoopDepth = 200
+	// MaxStringLength limits strings built by SPACE$ and STRING$.
+	MaxStringLength = 32767
+	// DefaultPrintZoneWidth is the width of the print zones a comma in PRINT advances to.
+	DefaultPrintZoneWidth = 14
+	// DefaultMaxFileLineLength is the longest line LOAD, INPUT # and LINE INPUT # accept.
+	DefaultMaxFileLineLength = 8192
 )
 
 type token struct {
@@ -40,4 +46,16 @@ const (
 	KeyCurDown  = "\x1B[B" // Pfeil nach unten
 	KeyCurRight = "\x1B[C" // Pfeil nach rechts
 	KeyCurLeft  = "\x1B[D" // Pfeil nach links
+
+	// Funktionstasten F1-F10 wie bei xterm
+	KeyF1  = "\x1BOP"
+	KeyF2  = "\x1BOQ"
+	KeyF3  = "\x1BOR"
+	KeyF4  = "\x1BOS"
+	KeyF5  = "\x1B[15~"
+	KeyF6  = "\x1B[17~"
+	KeyF7  = "\x1B[18~"
+	KeyF8  = "\x1B[19~"
+	KeyF9  = "\x1B[20~"
+	KeyF10 = "\x1B[21~"
 )
@@ -0,0 +1,163 @@
+package tinybasic
+
+import (
+	"fmt"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// pausedRun is everything CONT needs to resume a program after STOP or BREAK.
+// It is a copy taken when the program paused, so the live FOR/GOSUB stacks
+// can still be cleared by BREAK and RUN as before. The bytecode VM keeps its
+// own snapshot (vmPause) until CONT takes it.
+type pausedRun struct {
+	line            int            // Interpreter: line to resume at (0 = program end)
+	subIndex        int            // Interpreter: statement within line to resume at
+	forLoops        []ForLoopInfo  // Interpreter FOR frames
+	forLoopIndexMap map[string]int // Interpreter FOR lookup table
+	gosubStack      []int          // Interpreter GOSUB return lines
+	vm              *vmPause       // Bytecode VM registers, nil for the interpreter
+	programHash     string         // Program the pause belongs to; any edit rules out CONT
+}
+
+// maxPauseSteps bounds how many instructions a BREAK may still run to reach
+// the end of the current statement
+const maxPauseSteps = 1000
+
+// vmPause holds the VM registers at the start of a statement
+type vmPause struct {
+	pc        int
+	stack     []BASICValue
+	callStack []int
+	forLoops  []VMForLoop
+}
+
+// snapshot copies the registers CONT needs
+func (vm *BytecodeVM) snapshot() *vmPause {
+	p := &vmPause{
+		pc:        vm.pc,
+		callStack: append([]int(nil), vm.callStack...),
+		forLoops:  append([]VMForLoop(nil), vm.forLoops...),
+	}
+	if vm.stack != nil {
+		p.stack = append([]BASICValue(nil), vm.stack.data[:vm.stack.Size()]...)
+	}
+	return p
+}
+
+// pauseAtStatement finishes the statement a BREAK interrupted and snapshots
+// the registers. Resuming in mid-statement would store operands evaluated
+// before the user changed variables in direct mode. Returns nil if the
+// statement does not finish within maxPauseSteps instructions.
+func (vm *BytecodeVM) pauseAtStatement() *vmPause {
+	for steps := 0; vm.pc < len(vm.program.Instructions) && vm.statementStart(vm.pc) != vm.pc; steps++ {
+		if steps == maxPauseSteps || vm.executeInstruction() != nil {
+			return nil
+		}
+	}
+	return vm.snapshot()
+}
+
+// restore puts the registers of a snapshot back
+func (vm *BytecodeVM) restore(p *vmPause) {
+	vm.resetExecutionStacks()
+	for _, value := range p.stack {
+		vm.stack.FastPush(value)
+	}
+	vm.callStack = append(vm.callStack, p.callStack...)
+	vm.forLoops = append(vm.forLoops, p.forLoops...)
+	vm.pc = p.pc
+}
+
+// ResumeFrom makes the next Run continue from a snapshot instead of starting over
+func (vm *BytecodeVM) ResumeFrom(p *vmPause) {
+	vm.resume = p
+}
+
+// TakePause returns the snapshot of the last STOP or BREAK, if any, and forgets it
+func (vm *BytecodeVM) TakePause() *vmPause {
+	p := vm.pause
+	vm.pause = nil
+	return p
+}
+
+// discardPause rules out CONT until the next STOP or BREAK. Assumes lock is held.
+func (b *TinyBASIC) discardPause() {
+	b.paused = nil
+	if b.bytecodeVM != nil {
+		b.bytecodeVM.TakePause()
+	}
+}
+
+// runsOnVM reports whether RUN executes the current program on the bytecode
+// VM, i.e. the compiled program is up to date. Assumes lock is held.
+func (b *TinyBASIC) runsOnVM() bool {
+	return b.useBytecode && b.compiledProgram != nil && b.compiledHash == b.calculateProgramHash()
+}
+
+// pauseInterpreter records where CONT resumes the interpreter and copies the
+// FOR/GOSUB stacks. Assumes lock is held.
+func (b *TinyBASIC) pauseInterpreter(line, subIndex int) {
+	indexMap := make(map[string]int, len(b.forLoopIndexMap))
+	for name, index := range b.forLoopIndexMap {
+		indexMap[name] = index
+	}
+	b.paused = &pausedRun{
+		line:            line,
+		subIndex:        subIndex,
+		forLoops:        append([]ForLoopInfo(nil), b.forLoops...),
+		forLoopIndexMap: indexMap,
+		gosubStack:      append([]int(nil), b.gosubStack...),
+		programHash:     b.calculateProgramHash(),
+	}
+}
+
+// cmdStop ends the program like END but lets CONT resume after the STOP
+// statement. Assumes lock is held.
+func (b *TinyBASIC) cmdStop() (int, error) {
+	if !b.running || b.currentLine == 0 {
+		return 0, nil
+	}
+	line, subIndex := b.currentLine, b.currentSubStatementIndex+1
+	if subIndex >= len(b.splitStatementsByColon(b.program[line])) {
+		line, _ = b.findNextLine(line)
+		subIndex = 0
+	}
+	b.pauseInterpreter(line, subIndex)
+	b.running = false // Also ends the run from inside IF ... THEN STOP
+	b.sendMessageWrapped(shared.MessageTypeText, fmt.Sprintf("BREAK IN %d", b.currentLine))
+	return 0, nil
+}
+
+// cmdCont resumes a program paused by STOP or BREAK with its variables and
+// FOR/GOSUB stacks intact. Any change to the program since the pause makes
+// CONT fail, as in classic BASIC. Assumes lock is held.
+func (b *TinyBASIC) cmdCont(args string) error {
+	p := b.paused
+	if p == nil && b.bytecodeVM != nil {
+		if vmp := b.bytecodeVM.TakePause(); vmp != nil && b.runsOnVM() {
+			p = &pausedRun{vm: vmp, programHash: b.compiledHash}
+		}
+	}
+	b.discardPause()
+	if args != "" || b.running || p == nil || p.programHash != b.calculateProgramHash() {
+		return NewBASICError(ErrCategoryRuntime, "CANT_CONTINUE", true, 0).WithCommand("CONT")
+	}
+
+	b.running = true
+	b.inputControlEnableSent = false
+	b.sendInputControl("run_mode")
+
+	if p.vm != nil {
+		b.bytecodeVM.ResumeFrom(p.vm)
+		go b.runBytecodeProgram()
+		return nil
+	}
+	b.forLoops = p.forLoops
+	b.forLoopIndexMap = p.forLoopIndexMap
+	b.gosubStack = p.gosubStack
+	b.currentLine = p.line
+	b.resumeSubStatementIndex = p.subIndex
+	go b.runProgramInternal(b.ctx)
+	return nil
+}
@@ -0,0 +1,167 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// stopProgram stops inside a subroutine called from a FOR loop, so CONT has
+// to restore both stacks
+var stopProgram = []string{
+	`10 LET S = 0`,
+	`20 FOR I = 1 TO 3`,
+	`30 GOSUB 100`,
+	`40 NEXT I`,
+	`50 PRINT "TOTAL"; S`,
+	`60 END`,
+	`100 LET S = S + I`,
+	`110 IF I = 2 THEN STOP`,
+	`120 RETURN`,
+}
+
+// drainText collects the text output sent so far
+func drainText(b *TinyBASIC) string {
+	var sb strings.Builder
+	for len(b.OutputChan) > 0 {
+		msg := <-b.OutputChan
+		if msg.Type == shared.MessageTypeText {
+			sb.WriteString(msg.Content)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// messagesText joins the text of directly returned messages
+func messagesText(msgs []shared.Message) string {
+	var sb strings.Builder
+	for _, msg := range msgs {
+		sb.WriteString(msg.Content)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// TestStopAndCont stops a program, changes a variable in direct mode and
+// continues with the FOR and GOSUB frames of the stopped run
+func TestStopAndCont(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode, stopProgram...)
+		if !strings.Contains(out, "BREAK IN 110") || strings.Contains(out, "TOTAL") {
+			t.Fatalf("bytecode=%v: STOP did not pause the program: %q", bytecode, out)
+		}
+	}
+
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		b.useBytecode = bytecode
+		for _, line := range stopProgram {
+			b.Execute(line)
+		}
+		b.Execute("RUN")
+		waitUntilStopped(t, b)
+		drainText(b)
+
+		b.Execute("LET S = S + 100")
+		b.Execute("CONT")
+		waitUntilStopped(t, b)
+		out := drainText(b)
+		if strings.Contains(out, "ERROR") {
+			t.Errorf("bytecode=%v: CONT failed: %q", bytecode, out)
+		}
+
+		b.mu.Lock()
+		s := b.variables["S"]
+		b.mu.Unlock()
+		if !s.IsNumeric || s.NumValue != 106 {
+			t.Errorf("bytecode=%v: expected S = 106 after CONT, got %+v (output %q)", bytecode, s, out)
+		}
+
+		// The program has ended, so there is nothing left to continue
+		if msgs := messagesText(b.Execute("CONT")); !strings.Contains(msgs, "CAN'T CONTINUE") {
+			t.Errorf("bytecode=%v: second CONT should fail, got %q", bytecode, msgs)
+		}
+	}
+}
+
+// TestContAfterEdit checks that changing the program rules out CONT
+func TestContAfterEdit(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		b.useBytecode = bytecode
+		for _, line := range stopProgram {
+			b.Execute(line)
+		}
+		b.Execute("RUN")
+		waitUntilStopped(t, b)
+		drainText(b)
+
+		b.Execute(`55 PRINT "NEW LINE"`)
+		if msgs := messagesText(b.Execute("CONT")); !strings.Contains(msgs, "CAN'T CONTINUE") {
+			t.Errorf("bytecode=%v: CONT after editing should fail, got %q", bytecode, msgs)
+		}
+		if b.IsRunning() {
+			t.Errorf("bytecode=%v: program runs after a refused CONT", bytecode)
+			b.StopExecution()
+			waitUntilStopped(t, b)
+		}
+	}
+}
+
+// TestContAfterBreak breaks a counting loop, moves the counter in direct
+// mode and continues. A restart would reset N to 0, a lost FOR frame would
+// end in NEXT WITHOUT FOR.
+func TestContAfterBreak(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		b.useBytecode = bytecode
+		for _, line := range []string{
+			`10 LET N = 0`,
+			`20 FOR I = 1 TO 1000000000`,
+			`30 LET N = N + 1`,
+			`40 NEXT I`,
+		} {
+			b.Execute(line)
+		}
+		b.Execute("RUN")
+		breakAfter(t, b, 50*time.Millisecond)
+
+		// BREAK still leaves no live frames behind
+		b.mu.Lock()
+		if len(b.forLoops) != 0 || len(b.gosubStack) != 0 {
+			t.Errorf("bytecode=%v: BREAK kept live frames: forLoops=%d gosubStack=%d", bytecode, len(b.forLoops), len(b.gosubStack))
+		}
+		b.mu.Unlock()
+		drainText(b)
+
+		b.Execute("LET N = -1000000000000")
+		if msgs := messagesText(b.Execute("CONT")); strings.Contains(msgs, "ERROR") {
+			t.Fatalf("bytecode=%v: CONT after BREAK refused: %q", bytecode, msgs)
+		}
+		breakAfter(t, b, 50*time.Millisecond)
+		if out := drainText(b); strings.Contains(out, "ERROR") {
+			t.Errorf("bytecode=%v: CONT after BREAK failed: %q", bytecode, out)
+		}
+
+		b.mu.Lock()
+		n, i := b.variables["N"], b.variables["I"]
+		b.mu.Unlock()
+		if n.NumValue >= 0 || i.NumValue < 2 {
+			t.Errorf("bytecode=%v: program did not resume: N=%v I=%v", bytecode, n.NumValue, i.NumValue)
+		}
+	}
+}
+
+// breakAfter lets the program run for d, sends BREAK and waits until the
+// run has wound down
+func breakAfter(t *testing.T, b *TinyBASIC, d time.Duration) {
+	t.Helper()
+	time.Sleep(d)
+	b.StopExecution()
+	waitUntilStopped(t, b)
+	// The VM saves its state in the RUN goroutine just after it stops
+	time.Sleep(20 * time.Millisecond)
+}
@@ -0,0 +1,66 @@
+package tinybasic
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// dataProgram has DATA before, between and after the code, entered out of
+// order, with a colon line holding two DATA statements and a quoted colon
+var dataProgram = []string{
+	`500 DATA "E:F", 6`,
+	`10 DATA 1, 2`,
+	`20 FOR I = 1 TO 6`,
+	`30 READ A$`,
+	`40 PRINT "ITEM "; A$`,
+	`50 NEXT I`,
+	`60 RESTORE 300`,
+	`70 READ A$`,
+	`80 PRINT "AGAIN "; A$`,
+	`90 END`,
+	`300 PRINT "NOT DATA": DATA 4, "FIVE"`,
+	`200 DATA 3`,
+}
+
+// TestRebuildDataOrder checks that DATA items are collected in line order
+// including DATA after a colon
+func TestRebuildDataOrder(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	for _, line := range dataProgram {
+		b.Execute(line)
+	}
+	b.mu.Lock()
+	b.rebuildData()
+	got := append([]string(nil), b.data...)
+	b.mu.Unlock()
+
+	want := []string{"1", "2", "3", "4", `"FIVE"`, `"E:F"`, "6"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("data = %q, want %q", got, want)
+	}
+}
+
+// TestReadInterleavedData reads interleaved DATA and restores to a line on
+// both backends
+func TestReadInterleavedData(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		_, out := runFromLine(t, bytecode, "RUN", dataProgram...)
+		// The VM sends the parts of PRINT "ITEM "; A$ as separate messages
+		got := strings.Join(strings.Fields(out), " ")
+		want := "ITEM 1 ITEM 2 ITEM 3 ITEM 4 ITEM FIVE ITEM E:F AGAIN 4"
+		if !strings.Contains(got, want) {
+			t.Errorf("bytecode=%v: output %q does not contain %q", bytecode, got, want)
+		}
+	}
+}
+
+// TestRestoreToMissingLine checks RESTORE with a line that does not exist
+func TestRestoreToMissingLine(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		_, out := runFromLine(t, bytecode, "RUN", `10 DATA 1`, `20 RESTORE 15`, `30 PRINT "AFTER"`)
+		if !strings.Contains(strings.Join(strings.Fields(out), " "), "LINE NOT FOUND") || strings.Contains(out, "AFTER") {
+			t.Errorf("bytecode=%v: RESTORE to a missing line should fail, got %q", bytecode, out)
+		}
+	}
+}
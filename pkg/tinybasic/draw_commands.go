@@ -0,0 +1,264 @@
+package tinybasic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// DRAW "commands" moves a pen over the graphics screen like GW-BASIC's
+// DRAW and sends every stroke as a LINE. The grammar, separated by spaces
+// or semicolons where needed:
+//
+//	U n, D n, L n, R n   up, down, left, right by n pixels (n defaults to 1)
+//	E n, F n, G n, H n   diagonally up-right, down-right, down-left, up-left
+//	M x,y                to the point (x,y); a sign before x (M +10,-5)
+//	                     moves relative to the pen instead
+//	B                    prefix: the next move does not draw (pen up)
+//	N                    prefix: the next move returns the pen afterwards
+//	C n                  brightness 0-15 of the following strokes
+//
+// The pen starts in the middle of the screen. Its position and brightness
+// stay from one DRAW to the next until CLS.
+
+// maxDrawDistance limits the numbers of a DRAW string
+const maxDrawDistance = 10000
+
+// drawUsage is the usage hint for DRAW
+const drawUsage = `DRAW "U10 R10 D10 L10" (U D L R E F G H n, M x,y, B, N, C 0-15)`
+
+// drawPen is the DRAW pen. The zero value is the pen after CLS: in the
+// middle of the screen with the default color.
+type drawPen struct {
+	x, y       int
+	placed     bool // x and y are set; false means the middle of the screen
+	brightness int  // Brightness set with C
+	colored    bool // brightness is set; false means the default LINE color
+}
+
+// position returns where the pen is
+func (p *drawPen) position() (int, int) {
+	if !p.placed {
+		return graphicsWidth / 2, graphicsHeight / 2
+	}
+	return p.x, p.y
+}
+
+// drawStep is one command of a DRAW string
+type drawStep struct {
+	color      bool // C: set the brightness to n
+	n          int
+	dx, dy     int  // Move by (dx,dy), or to (dx,dy) if absolute
+	absolute   bool // M x,y without a sign
+	penUp      bool // B prefix
+	returnBack bool // N prefix
+}
+
+// drawDirections maps the direction letters to their unit steps
+var drawDirections = map[byte][2]int{
+	'U': {0, -1}, 'D': {0, 1}, 'L': {-1, 0}, 'R': {1, 0},
+	'E': {1, -1}, 'F': {1, 1}, 'G': {-1, 1}, 'H': {-1, -1},
+}
+
+// drawScanner walks through a DRAW string
+type drawScanner struct {
+	s   string
+	pos int
+}
+
+// skipSpace skips blanks and semicolons
+func (sc *drawScanner) skipSpace() {
+	for sc.pos < len(sc.s) && (sc.s[sc.pos] == ' ' || sc.s[sc.pos] == ';' || sc.s[sc.pos] == '\t') {
+		sc.pos++
+	}
+}
+
+// number reads an optional sign and digits. It reports whether there was a
+// number and whether it had a sign.
+func (sc *drawScanner) number() (int, bool, bool, error) {
+	sc.skipSpace()
+	start := sc.pos
+	signed := sc.pos < len(sc.s) && (sc.s[sc.pos] == '+' || sc.s[sc.pos] == '-')
+	if signed {
+		sc.pos++
+	}
+	digits := sc.pos
+	for sc.pos < len(sc.s) && sc.s[sc.pos] >= '0' && sc.s[sc.pos] <= '9' {
+		sc.pos++
+	}
+	if sc.pos == digits {
+		if signed {
+			return 0, false, false, fmt.Errorf("number expected after %q at position %d", sc.s[start], start+1)
+		}
+		return 0, false, false, nil
+	}
+	n, err := strconv.Atoi(sc.s[start:sc.pos])
+	if err != nil || n > maxDrawDistance || n < -maxDrawDistance {
+		return 0, false, false, fmt.Errorf("number at position %d must be between -%d and %d", start+1, maxDrawDistance, maxDrawDistance)
+	}
+	return n, true, signed, nil
+}
+
+// parseDraw splits a DRAW string into its steps. Errors name the position
+// of the offending character, counted from 1.
+func parseDraw(s string) ([]drawStep, error) {
+	sc := &drawScanner{s: strings.ToUpper(s)}
+	var steps []drawStep
+	var prefix drawStep // B and N waiting for their move
+	prefixPos := 0
+	for {
+		sc.skipSpace()
+		if sc.pos >= len(sc.s) {
+			break
+		}
+		cmdPos := sc.pos
+		cmd := sc.s[sc.pos]
+		sc.pos++
+
+		switch {
+		case cmd == 'B' || cmd == 'N':
+			if prefixPos == 0 {
+				prefixPos = cmdPos + 1
+			}
+			if cmd == 'B' {
+				prefix.penUp = true
+			} else {
+				prefix.returnBack = true
+			}
+			continue
+
+		case cmd == 'C':
+			n, ok, signed, err := sc.number()
+			if err != nil {
+				return nil, err
+			}
+			if !ok || signed || n > 15 {
+				return nil, fmt.Errorf("C at position %d needs a brightness from 0 to 15", cmdPos+1)
+			}
+			if prefixPos != 0 {
+				return nil, fmt.Errorf("B or N at position %d must be followed by a move", prefixPos)
+			}
+			steps = append(steps, drawStep{color: true, n: n})
+			continue
+
+		case cmd == 'M':
+			x, ok, relative, err := sc.number()
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				sc.skipSpace()
+				ok = sc.pos < len(sc.s) && sc.s[sc.pos] == ','
+				sc.pos++
+			}
+			var y int
+			if ok {
+				if y, ok, _, err = sc.number(); err != nil {
+					return nil, err
+				}
+			}
+			if !ok {
+				return nil, fmt.Errorf("M at position %d needs x,y", cmdPos+1)
+			}
+			step := prefix
+			step.dx, step.dy, step.absolute = x, y, !relative
+			steps = append(steps, step)
+
+		default:
+			dir, known := drawDirections[cmd]
+			if !known {
+				return nil, fmt.Errorf("unknown command %q at position %d", cmd, cmdPos+1)
+			}
+			n, ok, signed, err := sc.number()
+			if err != nil {
+				return nil, err
+			}
+			if signed {
+				return nil, fmt.Errorf("%c at position %d takes a distance without a sign", cmd, cmdPos+1)
+			}
+			if !ok {
+				n = 1
+			}
+			step := prefix
+			step.dx, step.dy = dir[0]*n, dir[1]*n
+			steps = append(steps, step)
+		}
+		prefix, prefixPos = drawStep{}, 0
+	}
+	if prefixPos != 0 {
+		return nil, fmt.Errorf("B or N at position %d must be followed by a move", prefixPos)
+	}
+	return steps, nil
+}
+
+// cmdDraw implements DRAW string. Assumes lock is held.
+func (b *TinyBASIC) cmdDraw(args string) error {
+	args = strings.TrimSpace(args)
+	if args == "" {
+		return NewBASICError(ErrCategorySyntax, "EXPECTED_EXPRESSION", b.currentLine == 0, b.currentLine).
+			WithCommand("DRAW").WithUsageHint(drawUsage)
+	}
+	val, err := b.evalExpression(args)
+	if err != nil {
+		return WrapError(err, "DRAW", b.currentLine == 0, b.currentLine)
+	}
+	return b.draw(val)
+}
+
+// draw runs a DRAW string. It is also called by the bytecode VM with the
+// evaluated argument. The steps are checked before the first stroke, so a
+// bad string draws nothing.
+func (b *TinyBASIC) draw(val BASICValue) error {
+	if val.IsNumeric {
+		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", b.currentLine == 0, b.currentLine).
+			WithCommand("DRAW").WithUsageHint(drawUsage)
+	}
+	steps, err := parseDraw(val.StrValue)
+	if err != nil {
+		return NewBASICError(ErrCategorySyntax, "INVALID_ARGUMENT", b.currentLine == 0, b.currentLine).
+			WithCommand("DRAW").WithUsageHint(err.Error())
+	}
+
+	pen := &b.pen
+	for _, step := range steps {
+		if step.color {
+			pen.brightness, pen.colored = step.n, true
+			continue
+		}
+		x1, y1 := pen.position()
+		x2, y2 := x1+step.dx, y1+step.dy
+		if step.absolute {
+			x2, y2 = step.dx, step.dy
+		}
+		if !step.penUp && !b.sendDrawLine(x1, y1, x2, y2) {
+			return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", b.currentLine == 0, b.currentLine).WithCommand("DRAW")
+		}
+		if !step.returnBack {
+			pen.x, pen.y, pen.placed = x2, y2, true
+		}
+	}
+	return nil
+}
+
+// sendDrawLine sends one stroke of DRAW as a LINE in the pen's color
+func (b *TinyBASIC) sendDrawLine(x1, y1, x2, y2 int) bool {
+	params := map[string]interface{}{
+		"x1":    x1,
+		"y1":    y1,
+		"x2":    x2,
+		"y2":    y2,
+		"color": "#5FFF5F",
+	}
+	if b.pen.colored {
+		hexVal := fmt.Sprintf("%02x", b.pen.brightness*17)
+		params["color"] = "#" + hexVal + hexVal + hexVal
+		params["brightness"] = b.pen.brightness
+	}
+	return b.sendMessageObject(shared.Message{
+		Type:    shared.MessageTypeGraphics,
+		Command: "LINE",
+		Params:  params,
+	})
+}
@@ -0,0 +1,113 @@
+package tinybasic
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// drawLines collects the LINE messages sent so far as "x1,y1-x2,y2"
+func drawLines(b *TinyBASIC) []string {
+	var lines []string
+	for len(b.OutputChan) > 0 {
+		msg := <-b.OutputChan
+		if msg.Type == shared.MessageTypeGraphics && msg.Command == "LINE" {
+			lines = append(lines, fmt.Sprintf("%v,%v-%v,%v", msg.Params["x1"], msg.Params["y1"], msg.Params["x2"], msg.Params["y2"]))
+		}
+	}
+	return lines
+}
+
+// TestDrawStrokes checks the moves, the B and N prefixes and that the pen
+// keeps its place between DRAW calls until CLS
+func TestDrawStrokes(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	for _, cmd := range []string{`"BM100,100 R10 D10"`, `"NL5 E2 BM+0,-20 U"`} {
+		if err := b.cmdDraw(cmd); err != nil {
+			t.Fatalf("DRAW %s failed: %v", cmd, err)
+		}
+	}
+	want := []string{"100,100-110,100", "110,100-110,110", "110,110-105,110", "110,110-112,108", "112,88-112,87"}
+	if got := drawLines(b); !reflect.DeepEqual(got, want) {
+		t.Errorf("lines %v, want %v", got, want)
+	}
+
+	if err := b.cmdCls(""); err != nil {
+		t.Fatalf("CLS failed: %v", err)
+	}
+	if err := b.cmdDraw(`"r"`); err != nil {
+		t.Fatalf("DRAW failed: %v", err)
+	}
+	if got, want := drawLines(b), []string{"320,240-321,240"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after CLS: lines %v, want %v", got, want)
+	}
+}
+
+// TestDrawColor checks that C sets the brightness of the following strokes
+// and that it stays for the next DRAW
+func TestDrawColor(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	if err := b.cmdDraw(`"R1 C3 R1"`); err != nil {
+		t.Fatalf("DRAW failed: %v", err)
+	}
+	if err := b.cmdDraw(`"R1"`); err != nil {
+		t.Fatalf("DRAW failed: %v", err)
+	}
+	var colors []string
+	for len(b.OutputChan) > 0 {
+		if msg := <-b.OutputChan; msg.Command == "LINE" {
+			colors = append(colors, fmt.Sprint(msg.Params["color"]))
+		}
+	}
+	if want := []string{"#5FFF5F", "#333333", "#333333"}; !reflect.DeepEqual(colors, want) {
+		t.Errorf("colors %v, want %v", colors, want)
+	}
+}
+
+// TestDrawRejectsBadStrings expects a message naming the problem and no
+// stroke from a string with an error
+func TestDrawRejectsBadStrings(t *testing.T) {
+	for input, want := range map[string]string{
+		"R10 X5":    `unknown command 'X' at position 5`,
+		"C16":       "C at position 1",
+		"M10":       "M at position 1 needs x,y",
+		"U-5":       "without a sign",
+		"R10 B":     "B or N at position 5",
+		"BC3":       "B or N at position 1",
+		"R99999999": "between",
+	} {
+		_, err := parseDraw(input)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: error %v, want %q", input, err, want)
+		}
+	}
+
+	b := NewTinyBASIC(nil)
+	for _, cmd := range []string{`"R10 X5"`, `5`, ``} {
+		if err := b.cmdDraw(cmd); err == nil {
+			t.Errorf("expected error for DRAW %s", cmd)
+		}
+	}
+	if got := drawLines(b); len(got) != 0 {
+		t.Errorf("bad strings drew %v", got)
+	}
+}
+
+// TestDrawInProgram runs DRAW in both engines
+func TestDrawInProgram(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startSleepProgram(b, bytecode,
+			`10 A$ = "R5"`,
+			`20 DRAW "BM0,0" + A$`,
+			`30 END`,
+		)
+		waitUntilStopped(t, b)
+		if got, want := drawLines(b), []string{"0,0-5,0"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("bytecode=%v: lines %v, want %v", bytecode, got, want)
+		}
+	}
+}
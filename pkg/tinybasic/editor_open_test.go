@@ -0,0 +1,34 @@
+package tinybasic
+
+import (
+	"testing"
+
+	"github.com/antibyte/retroterm/pkg/editor"
+	"github.com/antibyte/retroterm/pkg/virtualfs"
+)
+
+// TestOpenEditorWithContent checks that generated code opens as an unsaved
+// buffer that asks for a filename on save
+func TestOpenEditorWithContent(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	b.sessionID = "open-editor-test"
+	b.fs = virtualfs.New(nil)
+	defer editor.GetEditorManager().CloseEditor(b.sessionID)
+
+	code := "10 PRINT \"HELLO\"\n20 END"
+	b.openEditor("", "<new file>", code)
+
+	e := editor.GetEditorManager().GetEditor(b.sessionID)
+	if e == nil {
+		t.Fatal("editor was not started")
+	}
+	if got := e.GetContent(); got != code {
+		t.Errorf("editor content = %q, want %q", got, code)
+	}
+	if got := e.GetFilename(); got != "<new file>" {
+		t.Errorf("editor filename = %q, want <new file>", got)
+	}
+	if !e.IsModified() {
+		t.Error("generated code should be marked as unsaved")
+	}
+}
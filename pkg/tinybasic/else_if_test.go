@@ -0,0 +1,82 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+// elseIfProgram prints exactly one of FIRST, SECOND or THIRD for X = 1, 2, 3
+var elseIfProgram = []string{
+	`10 FOR X = 1 TO 3`,
+	`20 IF X = 1 THEN PRINT "FIRST" ELSE IF X = 2 THEN PRINT "SECOND" ELSE PRINT "THIRD"`,
+	`30 NEXT X`,
+	`40 PRINT "DONE"`,
+}
+
+// TestElseIfCompiles makes sure the three-way branch runs on the VM and
+// does not fall back to the interpreter
+func TestElseIfCompiles(t *testing.T) {
+	program := make(map[int]string)
+	var order []int
+	for i, line := range elseIfProgram {
+		num := (i + 1) * 10
+		program[num] = strings.TrimSpace(strings.TrimPrefix(line, strings.Fields(line)[0]))
+		order = append(order, num)
+	}
+	compiled, err := NewBytecodeCompiler().CompileProgram(program, order)
+	if err != nil {
+		t.Fatalf("ELSE IF program does not compile: %v", err)
+	}
+
+	// Every jump inside the IF line must stay inside the program
+	for i, inst := range compiled.Instructions {
+		if inst.OpCode != OP_JUMP_UNLESS && inst.OpCode != OP_JUMP_ADDR {
+			continue
+		}
+		if addr := inst.Operand1.(int); addr <= i || addr >= len(compiled.Instructions) {
+			t.Errorf("instruction %d (%s) jumps to invalid address %d", i, inst, addr)
+		}
+	}
+}
+
+// TestElseIfBranches checks that each condition reaches its own branch and
+// that only one branch runs per pass
+func TestElseIfBranches(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode, elseIfProgram...)
+		for _, want := range []string{"FIRST", "SECOND", "THIRD", "DONE"} {
+			if n := strings.Count(out, want); n != 1 {
+				t.Errorf("bytecode=%v: %q printed %d times in %q", bytecode, want, n, out)
+			}
+		}
+		if first, second, third := strings.Index(out, "FIRST"), strings.Index(out, "SECOND"), strings.Index(out, "THIRD"); !(first < second && second < third) {
+			t.Errorf("bytecode=%v: branches ran in the wrong order: %q", bytecode, out)
+		}
+	}
+}
+
+// TestIfBranchStatements checks that colons after THEN and ELSE belong to
+// the branch and that ELSE inside a string is not a keyword
+func TestIfBranchStatements(t *testing.T) {
+	program := []string{
+		`10 LET A = 0`,
+		`20 IF A = 1 THEN PRINT "SKIPPED": PRINT "SKIPPED TOO" ELSE PRINT "E1": PRINT "E2"`,
+		`30 IF A = 0 THEN PRINT "SAY ELSE": PRINT "T2" ELSE PRINT "NOT HERE"`,
+		`40 IF A = 5 THEN 100 ELSE PRINT "NO JUMP"`,
+		`50 END`,
+		`100 PRINT "LINE 100"`,
+	}
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode, program...)
+		for _, want := range []string{"E1", "E2", "SAY ELSE", "T2", "NO JUMP"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("bytecode=%v: output %q does not contain %q", bytecode, out, want)
+			}
+		}
+		for _, notWant := range []string{"SKIPPED", "NOT HERE", "LINE 100"} {
+			if strings.Contains(out, notWant) {
+				t.Errorf("bytecode=%v: output %q contains %q", bytecode, out, notWant)
+			}
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package tinybasic
+
+// environ implements ENVIRON$(name$) for the interpreter and the bytecode VM.
+// TinyOS decides what the session may read, so guests only get their own
+// session variables. Without an OS every variable is empty.
+func (b *TinyBASIC) environ(name string) string {
+	if b.os == nil {
+		return ""
+	}
+	return b.os.LookupEnv(b.sessionID, name)
+}
@@ -0,0 +1,30 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestEnvironWithoutOS checks with the bytecode VM and the interpreter that
+// ENVIRON$ is empty when no TinyOS is attached and needs a string argument
+func TestEnvironWithoutOS(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode,
+			`10 IF ENVIRON$("USER") = "" THEN PRINT "EMPTY"`,
+		)
+		if !strings.Contains(out, "EMPTY") {
+			t.Errorf("bytecode=%v: output %q does not contain EMPTY", bytecode, out)
+		}
+
+		out = runTrapProgram(t, bytecode,
+			`10 ON ERROR GOTO 100`,
+			`20 LET A$ = ENVIRON$(1)`,
+			`30 PRINT "NO ERROR"`,
+			`40 END`,
+			`100 PRINT "TRAPPED"`,
+		)
+		if !strings.Contains(out, "TRAPPED") {
+			t.Errorf("bytecode=%v: numeric argument not rejected, output %q", bytecode, out)
+		}
+	}
+}
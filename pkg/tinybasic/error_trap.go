@@ -0,0 +1,397 @@
+package tinybasic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxErrorRetries limits how often RESUME may re-execute a statement that keeps
+// failing in the same line before the error is reported instead of trapped.
+const maxErrorRetries = 100
+
+// Modes of a RESUME statement (any positive value is a target line number)
+const (
+	resumeRetry = 0  // RESUME: re-execute the failing statement
+	resumeNext  = -1 // RESUME NEXT: continue after the failing statement
+)
+
+// Error codes returned by ERR, modelled after the classic Microsoft BASIC numbers
+const (
+	ErrCodeNextWithoutFor     = 1
+	ErrCodeSyntax             = 2
+	ErrCodeReturnWithoutGosub = 3
+	ErrCodeOutOfData          = 4
+	ErrCodeIllegalFunction    = 5
+	ErrCodeOverflow           = 6
+	ErrCodeOutOfMemory        = 7
+	ErrCodeUndefinedLine      = 8
+	ErrCodeSubscriptRange     = 9
+	ErrCodeRedimensioned      = 10
+	ErrCodeDivisionByZero     = 11
+	ErrCodeTypeMismatch       = 13
+	ErrCodeResumeWithoutError = 20
+	ErrCodeLineBufferOverflow = 23
+	ErrCodeInternal           = 51
+	ErrCodeBadFileNumber      = 52
+	ErrCodeFileNotFound       = 53
+	ErrCodeBadFileMode        = 54
+	ErrCodeFileAlreadyOpen    = 55
+	ErrCodeDeviceIO           = 57
+	ErrCodeFileExists         = 58
+	ErrCodeInputPastEnd       = 62
+	ErrCodePermissionDenied   = 70
+)
+
+// errorCodeTable maps BASICError detail codes to ERR values
+var errorCodeTable = map[string]int{
+	"NEXT_WITHOUT_FOR":      ErrCodeNextWithoutFor,
+	"FOR_NEXT_MISMATCH":     ErrCodeNextWithoutFor,
+	"RETURN_WITHOUT_GOSUB":  ErrCodeReturnWithoutGosub,
+	"OUT_OF_DATA":           ErrCodeOutOfData,
+	"INVALID_ARGUMENT":      ErrCodeIllegalFunction,
+	"INVALID_VALUE":         ErrCodeIllegalFunction,
+	"OUT_OF_RANGE":          ErrCodeIllegalFunction,
+	"NEGATIVE_SQRT":         ErrCodeIllegalFunction,
+	"INVALID_LOG":           ErrCodeIllegalFunction,
+	"OVERFLOW":              ErrCodeOverflow,
+	"MEMORY_FULL":           ErrCodeOutOfMemory,
+	"STACK_OVERFLOW":        ErrCodeOutOfMemory,
+	"GOSUB_DEPTH":           ErrCodeOutOfMemory,
+	"FOR_DEPTH":             ErrCodeOutOfMemory,
+	"LINE_NOT_FOUND":        ErrCodeUndefinedLine,
+	"ARRAY_OUT_OF_BOUNDS":   ErrCodeSubscriptRange,
+	"BAD_SUBSCRIPT":         ErrCodeSubscriptRange,
+	"INVALID_ARRAY_INDEX":   ErrCodeSubscriptRange,
+	"ARRAY_ALREADY_DIM":     ErrCodeRedimensioned,
+	"DIVISION_BY_ZERO":      ErrCodeDivisionByZero,
+	"TYPE_MISMATCH":         ErrCodeTypeMismatch,
+	"RESUME_WITHOUT_ERROR":  ErrCodeResumeWithoutError,
+	"LINE_TOO_LONG":         ErrCodeLineBufferOverflow,
+	"INVALID_FILE_HANDLE":   ErrCodeBadFileNumber,
+	"FILE_NOT_OPEN":         ErrCodeBadFileNumber,
+	"FILE_NOT_FOUND":        ErrCodeFileNotFound,
+	"FILE_NOT_IN_INPUT":     ErrCodeBadFileMode,
+	"FILE_NOT_IN_OUTPUT":    ErrCodeBadFileMode,
+	"WRONG_FILE_MODE":       ErrCodeBadFileMode,
+	"FILE_HANDLE_IN_USE":    ErrCodeFileAlreadyOpen,
+	"FILE_ALREADY_OPEN":     ErrCodeFileAlreadyOpen,
+	"IO_ERROR":              ErrCodeDeviceIO,
+	"FILE_IO_ERROR":         ErrCodeDeviceIO,
+	"FILE_READ_ERROR":       ErrCodeDeviceIO,
+	"FILE_WRITE_ERROR":      ErrCodeDeviceIO,
+	"FILE_EXISTS":           ErrCodeFileExists,
+	"FILE_ALREADY_EXISTS":   ErrCodeFileExists,
+	"END_OF_FILE":           ErrCodeInputPastEnd,
+	"PERMISSION_DENIED":     ErrCodePermissionDenied,
+	"FILE_LOCKED":           ErrCodePermissionDenied,
+	"UNKNOWN_VARIABLE":      ErrCodeIllegalFunction,
+	"INVALID_EXPRESSION":    ErrCodeSyntax,
+	"UNKNOWN_COMMAND":       ErrCodeSyntax,
+	"SYNTAX_ERROR":          ErrCodeSyntax,
+	"INTERNAL_ERROR":        ErrCodeInternal,
+	"UNDEFINED_VARIABLE":    ErrCodeIllegalFunction,
+	"READ_MISSING_VARIABLE": ErrCodeSyntax,
+}
+
+// errorMessageCodes maps fragments of plain error texts, as produced by the
+// bytecode VM, to ERR values. Checked in order, first match wins.
+var errorMessageCodes = []struct {
+	fragment string
+	code     int
+}{
+	{"division by zero", ErrCodeDivisionByZero},
+	{"type mismatch", ErrCodeTypeMismatch},
+	{"undefined line", ErrCodeUndefinedLine},
+	{"return without gosub", ErrCodeReturnWithoutGosub},
+	{"next without for", ErrCodeNextWithoutFor},
+	{"stack overflow", ErrCodeOutOfMemory},
+	{"gosub depth", ErrCodeOutOfMemory},
+	{"out of data", ErrCodeOutOfData},
+	{"out of bounds", ErrCodeSubscriptRange},
+	{"bad subscript", ErrCodeSubscriptRange},
+	{"file not found", ErrCodeFileNotFound},
+	{"overflow", ErrCodeOverflow},
+}
+
+// errorCode returns the ERR value for err
+func errorCode(err error) int {
+	var be *BASICError
+	if errors.As(err, &be) {
+		if code, ok := errorCodeTable[be.Detail]; ok {
+			return code
+		}
+		if be.Category == ErrCategorySyntax {
+			return ErrCodeSyntax
+		}
+	}
+
+	switch {
+	case errors.Is(err, ErrDivisionByZero):
+		return ErrCodeDivisionByZero
+	case errors.Is(err, ErrTypeMismatch):
+		return ErrCodeTypeMismatch
+	case errors.Is(err, ErrFileNotFound):
+		return ErrCodeFileNotFound
+	case errors.Is(err, ErrOutOfData):
+		return ErrCodeOutOfData
+	}
+
+	text := strings.ToLower(err.Error())
+	for _, entry := range errorMessageCodes {
+		if strings.Contains(text, entry.fragment) {
+			return entry.code
+		}
+	}
+	return ErrCodeInternal
+}
+
+// isTrappableError reports whether ON ERROR GOTO may handle err. BREAK, EXIT,
+// the step budget and cancelled executions always end the program.
+func isTrappableError(err error) bool {
+	if err == nil || errors.Is(err, ErrExit) || errors.Is(err, ErrExecutionLimit) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var be *BASICError
+	if errors.As(err, &be) && be.Detail == "EXECUTION_CANCELLED" {
+		return false
+	}
+	return true
+}
+
+// errorTrap holds the ON ERROR GOTO state of a running program. The interpreter
+// and the bytecode VM each keep one.
+type errorTrap struct {
+	handlerLine int  // Target of ON ERROR GOTO, 0 = no handler installed
+	active      bool // Handler is running; errors inside it end the program
+	code        int  // Value of ERR
+	line        int  // Value of ERL
+	retried     bool // Last RESUME re-executed the failing statement
+	retries     int  // Consecutive retries that failed again in the same line
+}
+
+// reset removes the handler and forgets the last error
+func (t *errorTrap) reset() {
+	*t = errorTrap{}
+}
+
+// trap records an error in line and reports whether the handler should run.
+// Errors inside the handler and statements that keep failing after RESUME are
+// not trapped, so a broken handler cannot loop forever.
+func (t *errorTrap) trap(code, line int) bool {
+	if t.handlerLine == 0 || t.active {
+		return false
+	}
+	if t.retried && line == t.line {
+		t.retries++
+		if t.retries > maxErrorRetries {
+			return false
+		}
+	} else {
+		t.retries = 0
+	}
+	t.code = code
+	t.line = line
+	t.active = true
+	t.retried = false
+	return true
+}
+
+// resume leaves the handler. mode is resumeRetry, resumeNext or a line number.
+func (t *errorTrap) resume(mode int) bool {
+	if !t.active {
+		return false
+	}
+	t.active = false
+	t.retried = mode == resumeRetry
+	return true
+}
+
+// parseResumeMode parses the argument of RESUME: nothing or 0, NEXT, or a line number
+func parseResumeMode(args string) (int, bool) {
+	args = strings.ToUpper(strings.TrimSpace(args))
+	switch args {
+	case "", "0":
+		return resumeRetry, true
+	case "NEXT":
+		return resumeNext, true
+	}
+	line, err := strconv.Atoi(args)
+	if err != nil || line <= 0 {
+		return 0, false
+	}
+	return line, true
+}
+
+// parseOnErrorGoto parses "ERROR GOTO line" (the arguments of ON) and returns
+// the handler line, 0 to disable the handler
+func parseOnErrorGoto(args string) (int, bool) {
+	fields := strings.Fields(strings.ToUpper(args))
+	if len(fields) != 3 || fields[0] != "ERROR" || fields[1] != "GOTO" {
+		return 0, false
+	}
+	if fields[2] == "0" {
+		return 0, true
+	}
+	line, err := strconv.Atoi(fields[2])
+	if err != nil || line <= 0 {
+		return 0, false
+	}
+	return line, true
+}
+
+// cmdOnError handles ON ERROR GOTO line. args is the text after ON. Assumes lock is held.
+func (b *TinyBASIC) cmdOnError(args string) error {
+	line, ok := parseOnErrorGoto(args)
+	if !ok {
+		return NewBASICError(ErrCategorySyntax, "UNEXPECTED_TOKEN", b.currentLine == 0, b.currentLine).WithCommand("ON")
+	}
+	if line != 0 {
+		if _, exists := b.program[line]; !exists {
+			return NewBASICError(ErrCategoryExecution, "LINE_NOT_FOUND", b.currentLine == 0, b.currentLine).WithCommand("ON")
+		}
+	}
+	b.errTrap.handlerLine = line
+	return nil
+}
+
+// cmdResume leaves the error handler and continues at the failed statement,
+// the statement after it or a given line. Assumes lock is held.
+func (b *TinyBASIC) cmdResume(args string) error {
+	mode, ok := parseResumeMode(args)
+	if !ok {
+		return NewBASICError(ErrCategorySyntax, "INVALID_LINE_NUMBER", b.currentLine == 0, b.currentLine).WithCommand("RESUME")
+	}
+	if mode > 0 {
+		if _, exists := b.program[mode]; !exists {
+			return NewBASICError(ErrCategoryExecution, "LINE_NOT_FOUND", b.currentLine == 0, b.currentLine).WithCommand("RESUME")
+		}
+	}
+	if !b.errTrap.resume(mode) {
+		return NewBASICError(ErrCategoryRuntime, "RESUME_WITHOUT_ERROR", b.currentLine == 0, b.currentLine).WithCommand("RESUME")
+	}
+
+	switch mode {
+	case resumeRetry:
+		b.currentLine = b.errorResumeLine
+		b.resumeSubStatementIndex = b.errorResumeIndex
+	case resumeNext:
+		b.currentLine = b.errorResumeLine
+		b.resumeSubStatementIndex = b.errorResumeIndex + 1
+	default:
+		b.currentLine = mode
+	}
+	return nil
+}
+
+// trapError hands a runtime error in line to the ON ERROR GOTO handler.
+// Returns false if the error has to end the program.
+func (b *TinyBASIC) trapError(err error, line int) bool {
+	if !isTrappableError(err) {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.running || !b.errTrap.trap(errorCode(err), line) {
+		return false
+	}
+	if _, exists := b.program[b.errTrap.handlerLine]; !exists {
+		// Handler line was deleted after ON ERROR GOTO
+		return false
+	}
+
+	b.errorResumeLine = line
+	b.errorResumeIndex = b.currentSubStatementIndex
+	b.resumeSubStatementIndex = 0
+	b.variables["ERR"] = BASICValue{NumValue: float64(b.errTrap.code), IsNumeric: true}
+	b.variables["ERL"] = BASICValue{NumValue: float64(b.errTrap.line), IsNumeric: true}
+	b.currentLine = b.errTrap.handlerLine
+	return true
+}
+
+// trapError hands a runtime error to the ON ERROR GOTO handler of the
+// compiled program. Returns false if the error has to end the program.
+func (vm *BytecodeVM) trapError(err error) bool {
+	if !vm.running || !isTrappableError(err) || (vm.ctx != nil && vm.ctx.Err() != nil) {
+		return false
+	}
+	if !vm.errTrap.trap(errorCode(err), vm.CurrentLine()) {
+		return false
+	}
+	handlerPC, exists := vm.program.Labels[vm.errTrap.handlerLine]
+	if !exists {
+		return false
+	}
+
+	vm.errorPC = vm.pc
+	vm.stack.Clear()
+	vm.variables["ERR"] = BASICValue{NumValue: float64(vm.errTrap.code), IsNumeric: true}
+	vm.variables["ERL"] = BASICValue{NumValue: float64(vm.errTrap.line), IsNumeric: true}
+	vm.pc = handlerPC
+	return true
+}
+
+// handleOnError installs or removes the error handler (ON ERROR GOTO)
+func (vm *BytecodeVM) handleOnError(inst *Instruction) error {
+	line := inst.Operand1.(int)
+	if line != 0 {
+		if _, exists := vm.program.Labels[line]; !exists {
+			return fmt.Errorf("undefined line number %d", line)
+		}
+	}
+	vm.errTrap.handlerLine = line
+	vm.pc++
+	return nil
+}
+
+// handleResume leaves the error handler (RESUME, RESUME NEXT, RESUME line)
+func (vm *BytecodeVM) handleResume(inst *Instruction) error {
+	mode := inst.Operand1.(int)
+	if !vm.errTrap.active {
+		return NewBASICError(ErrCategoryRuntime, "RESUME_WITHOUT_ERROR", false, inst.LineNum).WithCommand("RESUME")
+	}
+
+	var target int
+	switch mode {
+	case resumeRetry:
+		target = vm.statementStart(vm.errorPC)
+	case resumeNext:
+		target = vm.nextStatementStart(vm.errorPC)
+	default:
+		addr, exists := vm.program.Labels[mode]
+		if !exists {
+			return fmt.Errorf("undefined line number %d", mode)
+		}
+		target = addr
+	}
+
+	vm.errTrap.resume(mode)
+	vm.pc = target
+	return nil
+}
+
+// statementStart returns the first instruction of the statement containing pc
+func (vm *BytecodeVM) statementStart(pc int) int {
+	starts := vm.program.Statements
+	i := sort.SearchInts(starts, pc+1) - 1
+	if i < 0 {
+		return 0
+	}
+	return starts[i]
+}
+
+// nextStatementStart returns the first instruction after the statement
+// containing pc, or the final HALT if it was the last statement
+func (vm *BytecodeVM) nextStatementStart(pc int) int {
+	starts := vm.program.Statements
+	i := sort.SearchInts(starts, pc+1)
+	if i < len(starts) {
+		return starts[i]
+	}
+	return len(vm.program.Instructions) - 1
+}
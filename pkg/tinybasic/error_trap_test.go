@@ -0,0 +1,137 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// runTrapProgram loads lines, runs them and returns the text output
+func runTrapProgram(t *testing.T, bytecode bool, lines ...string) string {
+	t.Helper()
+	b := NewTinyBASIC(nil)
+	b.useBytecode = bytecode
+	for _, line := range lines {
+		b.Execute(line)
+	}
+	for len(b.OutputChan) > 0 {
+		<-b.OutputChan
+	}
+	b.Execute("RUN")
+	waitUntilStopped(t, b)
+
+	var sb strings.Builder
+	for len(b.OutputChan) > 0 {
+		msg := <-b.OutputChan
+		if msg.Type == shared.MessageTypeText {
+			sb.WriteString(msg.Content)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// requireBytecode fails the test if lines do not compile to bytecode, so a
+// bytecode run cannot silently fall back to the interpreter
+func requireBytecode(t *testing.T, lines ...string) {
+	t.Helper()
+	b := NewTinyBASIC(nil)
+	for _, line := range lines {
+		b.Execute(line)
+	}
+	b.mu.Lock()
+	err := b.compileProgramIfNeeded()
+	b.mu.Unlock()
+	if err != nil {
+		t.Fatalf("program does not compile to bytecode: %v", err)
+	}
+}
+
+// TestOnErrorGoto runs each program with the bytecode VM and the interpreter
+func TestOnErrorGoto(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "resume next",
+			program: []string{
+				"10 ON ERROR GOTO 100",
+				"20 LET X = 1 / 0: PRINT \"NEXT\"",
+				"30 PRINT \"AFTER\"",
+				"40 END",
+				"100 PRINT \"TRAP\"",
+				"105 PRINT ERR * 1000 + ERL",
+				"110 RESUME NEXT",
+			},
+			want: []string{"TRAP", "11020", "NEXT", "AFTER"},
+		},
+		{
+			name: "resume retries after fix",
+			program: []string{
+				"10 ON ERROR GOTO 100",
+				"20 LET D = 0",
+				"30 PRINT 10 / D",
+				"40 END",
+				"100 LET D = 2: RESUME",
+			},
+			want: []string{"5"},
+		},
+		{
+			name: "error in handler ends program",
+			program: []string{
+				"10 ON ERROR GOTO 100",
+				"20 LET X = 1 / 0",
+				"30 PRINT \"AFTER\"",
+				"40 END",
+				"100 LET Y = 1 / 0",
+				"110 RESUME NEXT",
+			},
+			notWant: []string{"AFTER"},
+		},
+		{
+			name: "endless retry ends program",
+			program: []string{
+				"10 ON ERROR GOTO 100",
+				"20 LET X = 1 / 0",
+				"30 PRINT \"AFTER\"",
+				"40 END",
+				"100 RESUME",
+			},
+			notWant: []string{"AFTER"},
+		},
+	}
+
+	for _, tt := range tests {
+		requireBytecode(t, tt.program...)
+		for _, bytecode := range []bool{true, false} {
+			out := runTrapProgram(t, bytecode, tt.program...)
+			for _, want := range tt.want {
+				if !strings.Contains(out, want+"\n") {
+					t.Errorf("%s (bytecode=%v): output %q lacks %q", tt.name, bytecode, out, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out, notWant) {
+					t.Errorf("%s (bytecode=%v): output %q contains %q", tt.name, bytecode, out, notWant)
+				}
+			}
+		}
+	}
+}
+
+// TestErrorCode checks the mapping of runtime errors to ERR values
+func TestErrorCode(t *testing.T) {
+	if got := errorCode(NewBASICError(ErrCategoryFileSystem, "FILE_NOT_FOUND", false, 10)); got != ErrCodeFileNotFound {
+		t.Errorf("FILE_NOT_FOUND: got %d, want %d", got, ErrCodeFileNotFound)
+	}
+	if got := errorCode(&VMError{Message: "DIV: division by zero"}); got != ErrCodeDivisionByZero {
+		t.Errorf("VM division by zero: got %d, want %d", got, ErrCodeDivisionByZero)
+	}
+	if isTrappableError(ErrExit) {
+		t.Error("EXIT must not be trappable")
+	}
+}
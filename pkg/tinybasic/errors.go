@@ -43,9 +43,13 @@ var (
 	ErrHelpNotFound           = errors.New("no help found for this command")
 	ErrGosubDepthExceeded     = errors.New("GOSUB depth exceeded")
 	ErrForLoopDepthExceeded   = errors.New("FOR loop depth exceeded")
+	ErrStackOverflow          = errors.New("stack overflow")           // Expression stack of the bytecode VM is full
+	ErrExecutionLimit         = errors.New("execution limit exceeded") // Step budget of the run is used up
 	ErrInvalidColor           = errors.New("invalid color value (must be 1-16)")
 	ErrInvalidCoordinates     = errors.New("invalid coordinates")
 	ErrInvalidArguments       = errors.New("invalid arguments")         // Generic argument error
+	ErrUnknownService         = errors.New("unknown SYS$ service")      // SYS$ only calls whitelisted services
+	ErrNoSession              = errors.New("no OS session")             // SYS$ outside a TinyOS session
 	ErrSayTextTooLong         = errors.New("say text too long")         // Neuer Fehler für SAY-Textlänge
 	ErrRateLimitExceeded      = errors.New("rate limit exceeded")       // Neuer Fehler für SAY Rate Limiting
 	ErrNoiseRateLimitExceeded = errors.New("noise rate limit exceeded") // Neuer Fehler für NOISE Rate Limiting
@@ -62,6 +66,8 @@ type BASICError struct {
 	LineNumber int    // Zeilennummer im Programm (0 für Direktmodus)
 	DirectMode bool   // Ob der Fehler im Direktmodus aufgetreten ist
 	Detail     string // Detaillierte Fehlerbeschreibung (für spezifische Fehlercodes)
+	// Vermutlich gemeinte Befehle bei einem Tippfehler (höchstens drei)
+	Suggestions []string
 }
 
 // Error implementiert das error-Interface
@@ -82,6 +88,9 @@ func (be *BASICError) Error() string {
 	} else {
 		msg = be.Category + ": " + friendly
 	}
+	if hint := shared.DidYouMean(be.Suggestions); hint != "" {
+		msg += "\n" + strings.ToUpper(hint)
+	}
 	// Entfernt: Logausgabe für Fehlerobjekt (Fehler werden ohnehin als Rückgabe behandelt)
 	return msg
 }
@@ -113,6 +122,25 @@ func (be *BASICError) WithUsageHint(hint string) *BASICError {
 	return be
 }
 
+// WithSuggestions hängt Vorschläge für einen falsch geschriebenen Befehl an
+func (be *BASICError) WithSuggestions(suggestions []string) *BASICError {
+	be.Suggestions = suggestions
+	return be
+}
+
+// commandSuggestions sucht die Anweisungen, die bei einem unbekannten Befehl
+// vermutlich gemeint waren. Wird nur im Fehlerfall aufgerufen.
+func commandSuggestions(cmd string) []string {
+	candidates := make([]string, 0, len(knownCommands))
+	for _, known := range knownCommands {
+		// Mehrteilige Formen wie "SPRITE ON" und Kürzel wie "?" oder "PR." nicht vorschlagen
+		if len(known) > 1 && !strings.ContainsAny(known, " #?") && !strings.HasSuffix(known, ".") {
+			candidates = append(candidates, known)
+		}
+	}
+	return shared.Suggest(cmd, candidates)
+}
+
 // Fehlerkategorien
 const (
 	// ErrCategorySyntax kennzeichnet Syntaxfehler.
@@ -188,10 +216,13 @@ var FriendlyErrorTexts = map[string]map[string]string{
 		"FOR_LOOP_DEPTH_EXCEEDED": "MAXIMUM FOR LOOP NESTING DEPTH EXCEEDED",
 		"MISSING_NEXT":            "MISSING NEXT STATEMENT FOR FOR LOOP",
 		"NEXT_VARIABLE_MISMATCH":  "NEXT VARIABLE DOES NOT MATCH FOR VARIABLE",
+		"BAD_SUBSCRIPT":           "BAD SUBSCRIPT",
 		"ARRAY_OUT_OF_BOUNDS":     "ARRAY INDEX OUT OF BOUNDS", "FOR_DEPTH": "FOR LOOP STACK OVERFLOW (TOO MANY NESTED LOOPS)",
-		"GOSUB_DEPTH":        "GOSUB STACK OVERFLOW (TOO MANY NESTED CALLS)",
-		"GOTO_INFINITE_LOOP": "INTERPRETER DEADLOCK DETECTED (EXCESSIVE GOTO LOOP ITERATIONS)",
-		"FOR_NEXT_DEADLOCK":  "FOR/NEXT DEADLOCK DETECTED (GOTO SKIPS FOR LOOPS)",
+		"GOSUB_DEPTH":          "GOSUB STACK OVERFLOW (TOO MANY NESTED CALLS)",
+		"GOTO_INFINITE_LOOP":   "INTERPRETER DEADLOCK DETECTED (EXCESSIVE GOTO LOOP ITERATIONS)",
+		"FOR_NEXT_DEADLOCK":    "FOR/NEXT DEADLOCK DETECTED (GOTO SKIPS FOR LOOPS)",
+		"RESUME_WITHOUT_ERROR": "RESUME OUTSIDE OF AN ON ERROR HANDLER",
+		"CANT_CONTINUE":        "CAN'T CONTINUE (NO STOPPED PROGRAM OR PROGRAM CHANGED)",
 	}, ErrCategoryEvaluation: {
 		"INVALID_EXPRESSION":               "EXPRESSION CANNOT BE EVALUATED",
 		"TYPE_MISMATCH":                    "TYPE MISMATCH IN EXPRESSION OR ASSIGNMENT",
@@ -203,6 +234,7 @@ var FriendlyErrorTexts = map[string]map[string]string{
 		"NEGATIVE_SQRT":                    "NEGATIVE VALUE IN SQUARE ROOT",
 		"INVALID_LOG":                      "NON-POSITIVE VALUE IN LOGARITHM",
 		"INVALID_VALUE":                    "INVALID VALUE FOR OPERATION",
+		"INVALID_PARAMETER_VALUE":          "INVALID PARAMETER VALUE",
 		"VECTOR_ID_PARAM_ERROR":            "VECTOR ID PARAMETER ERROR",
 		"VECTOR_ID_TYPE_ERROR":             "VECTOR ID TYPE ERROR",
 		"VECTOR_ID_RANGE_ERROR":            "VECTOR ID OUT OF RANGE",
@@ -241,10 +273,12 @@ var FriendlyErrorTexts = map[string]map[string]string{
 		"END_OF_FILE":         "ATTEMPTED TO READ PAST THE END OF FILE",
 		"FILE_READ_ERROR":     "ERROR READING FROM FILE",
 		"FILE_WRITE_ERROR":    "ERROR WRITING TO FILE",
+		"LINE_TOO_LONG":       "LINE IN FILE EXCEEDS MAXIMUM LENGTH",
 		"FILE_NOT_FOUND":      "FILE NOT FOUND",
 		"FILE_SYSTEM_ERROR":   "FILE SYSTEM OPERATION FAILED",
 		"FILE_ALREADY_EXISTS": "FILE ALREADY EXISTS",
 		"PERMISSION_DENIED":   "PERMISSION DENIED",
+		"FILE_LOCKED":         "FILE IS LOCKED",
 	},
 	ErrCategoryCommand: {
 		"HELP_NOT_FOUND": "NO HELP AVAILABLE FOR THE SPECIFIED COMMAND",
@@ -271,6 +305,7 @@ var FriendlyErrorTexts = map[string]map[string]string{
 		"FILE_NOT_OPEN":       "FILE NOT OPEN WITH THIS HANDLE",
 		"WRONG_FILE_MODE":     "FILE NOT OPEN IN REQUIRED MODE (INPUT/OUTPUT)",
 		"END_OF_FILE":         "END OF FILE REACHED",
+		"LINE_TOO_LONG":       "LINE IN FILE EXCEEDS MAXIMUM LENGTH",
 		"IO_ERROR":            "INPUT/OUTPUT ERROR",
 		"INVALID_FILE_HANDLE": "INVALID FILE HANDLE",
 		"FILE_EXISTS":         "FILE ALREADY EXISTS",
@@ -278,6 +313,7 @@ var FriendlyErrorTexts = map[string]map[string]string{
 		"FILE_IO_ERROR":       "FILE INPUT/OUTPUT ERROR",
 		"INVALID_FILENAME":    "INVALID FILENAME",
 		"FILE_SYSTEM_ERROR":   "FILE SYSTEM ERROR",
+		"FILE_LOCKED":         "FILE IS LOCKED",
 	},
 	ErrCategorySystem: {
 		"INTERNAL_ERROR":      "AN INTERNAL SYSTEM ERROR OCCURRED",
@@ -356,30 +392,47 @@ var specificErrorMessages = map[string]map[string]string{
 
 // Tabelle mit Syntaxhinweisen für Befehle
 var commandUsageHints = map[string]string{
-	"PRINT":      "PRINT [expr][,|;]... or PRINT \"text\"",
+	"PRINT":      "PRINT [AT(x,y);] [expr][,|;]... or PRINT \"text\"",
 	"LET":        "LET var = expr",
+	"LSET":       "LSET A$ = value",
+	"RSET":       "RSET A$ = value",
 	"IF":         "IF condition THEN statement",
 	"FOR":        "FOR var = start TO end [STEP value]",
 	"NEXT":       "NEXT var",
-	"INPUT":      "INPUT [\"prompt\";] var",
+	"INPUT":      "INPUT [\"prompt\";|,] [default;] var",
 	"GOTO":       "GOTO lineNumber",
 	"GOSUB":      "GOSUB lineNumber",
 	"RETURN":     "RETURN",
+	"ON":         "ON ERROR GOTO lineNumber | ON KEY(n) GOSUB lineNumber | ON TIMER(s) GOSUB lineNumber | ON INKEY GOSUB lineNumber",
+	"TIMER":      "TIMER ON | TIMER OFF",
+	"KEY":        "KEY n, text$ (n = 1-10)",
+	"TEXTGFX":    "TEXTGFX x, y, text, [color], [size]",
+	"RESUME":     "RESUME [NEXT|lineNumber]",
+	"OPTION":     "OPTION ANGLE DEGREES | RADIANS",
 	"END":        "END",
+	"STOP":       "STOP",
+	"CONT":       "CONT",
 	"REM":        "REM comment",
 	"BEEP":       "BEEP",
-	"SOUND":      "SOUND frequency, duration",
+	"SOUND":      "SOUND frequency, duration | SOUND WAIT ON|OFF",
 	"SAY":        "SAY \"text\" or SAY stringVar$",
 	"SPEAK":      "SPEAK \"text\" or SPEAK stringVar$",
+	"MUSIC":      "MUSIC OPEN \"file.sid\" | PLAY | PAUSE | RESUME | STOP | TUNE n | SCOPE ON|OFF",
 	"CLS":        "CLS",
 	"LOAD":       "LOAD \"filename\"",
-	"SAVE":       "SAVE \"filename\"",
+	"SAVE":       "SAVE \"filename\" [, F | , A]",
+	"SAVEVAR":    "SAVEVAR \"key\", value",
+	"CHAIN":      "CHAIN \"filename\"",
+	"COMMON":     "COMMON var[, var...]",
 	"DIR":        "DIR",
 	"LIST":       "LIST [startLine][-endLine]",
+	"DELETE":     "DELETE line | start-end | start- | -end",
 	"RUN":        "RUN",
+	"BENCH":      "BENCH [line | \"filename\"]",
 	"PLOT":       "PLOT x, y",
-	"DRAW":       "DRAW x1, y1, x2, y2",
+	"DRAW":       "DRAW \"U10 R10 D10 L10\"",
 	"CIRCLE":     "CIRCLE x, y, radius",
+	"PAINT":      "PAINT x, y, color [, border]",
 	"RECT":       "RECT x, y, width, height",
 	"FILL":       "FILL x, y",
 	"INK":        "INK color",
@@ -390,6 +443,11 @@ var commandUsageHints = map[string]string{
 	"DATA":       "DATA item1, item2, ...",
 	"READ":       "READ var1, var2, ...",
 	"RESTORE":    "RESTORE",
+	"SLEEP":      "SLEEP milliseconds",
+	"RANDOMIZE":  "RANDOMIZE [seed|TIMER]",
+	"SCREEN":     "SCREEN DOUBLE|SINGLE|SWAP",
+	"FLIP":       "FLIP",
+	"WIDTH":      "WIDTH 40|80",
 }
 
 // GetFriendlyErrorText retrieves a user-friendly error message.
@@ -498,7 +556,10 @@ var ErrorTexts = map[string]string{
 	"OUT_OF_DATA":             "OUT OF DATA",
 	"NEXT_WITHOUT_FOR":        "NEXT WITHOUT FOR",
 	"RETURN_WITHOUT_GOSUB":    "RETURN WITHOUT GOSUB",
+	"RESUME_WITHOUT_ERROR":    "RESUME WITHOUT ERROR",
+	"CANT_CONTINUE":           "CAN'T CONTINUE",
 	"ARRAY_OUT_OF_BOUNDS":     "ARRAY INDEX OUT OF BOUNDS",
+	"BAD_SUBSCRIPT":           "BAD SUBSCRIPT",
 	"UNKNOWN_VARIABLE":        "UNKNOWN VARIABLE",
 	"LINE_NOT_FOUND":          "PROGRAM LINE NOT FOUND",
 	"FOR_NEXT_MISMATCH":       "FOR/NEXT VARIABLE MISMATCH",
@@ -530,6 +591,7 @@ var ErrorTexts = map[string]string{
 	"INVALID_FILE_HANDLE": "INVALID FILE HANDLE",
 	"FILE_READ_ERROR":     "ERROR READING FROM FILE",
 	"FILE_WRITE_ERROR":    "ERROR WRITING TO FILE",
+	"LINE_TOO_LONG":       "LINE TOO LONG",
 	"PERMISSION_DENIED":   "PERMISSION DENIED",
 
 	// Graphics Errors
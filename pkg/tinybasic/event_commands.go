@@ -0,0 +1,294 @@
+package tinybasic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Limits of the ON TIMER interval in seconds
+const (
+	minTimerInterval = 0.05
+	maxTimerInterval = 86400
+)
+
+// eventTraps holds the ON TIMER and ON INKEY handlers of a program. The
+// interpreter and the bytecode VM share it. Like functionKeys it has its
+// own lock, key presses arrive while the VM runs without b.mu.
+//
+// Only one event handler runs at a time: events that come due while it runs
+// wait until it has returned, and after it returns the main program always
+// gets one line before the next handler starts, so a timer shorter than its
+// handler cannot starve the program.
+type eventTraps struct {
+	mu            sync.Mutex
+	timerLine     int           // ON TIMER(n) GOSUB line, 0 = none
+	timerInterval time.Duration // n of ON TIMER(n)
+	timerOn       bool          // TIMER OFF pauses the timer without removing the handler
+	timerDue      time.Time     // Next call of the timer handler
+	inkeyLine     int           // ON INKEY GOSUB line, 0 = none
+	keyPressed    bool          // A key was pressed since the INKEY handler last started
+	handlerDepth  int           // GOSUB depth inside the running handler, 0 = none
+	yield         bool          // A handler has just returned, the program runs a line first
+	armed         atomic.Bool   // A handler is installed; checked before each line
+}
+
+// reset removes both handlers
+func (e *eventTraps) reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.timerLine, e.timerInterval, e.timerOn = 0, 0, false
+	e.inkeyLine, e.keyPressed = 0, false
+	e.handlerDepth, e.yield = 0, false
+	e.armed.Store(false)
+}
+
+// updateArmedWithoutLock records whether takeEvent has anything to look for
+func (e *eventTraps) updateArmedWithoutLock() {
+	e.armed.Store((e.timerLine != 0 && e.timerOn) || e.inkeyLine != 0)
+}
+
+// setTimer installs the ON TIMER handler at line, called every interval
+// seconds from now on; line 0 removes it
+func (e *eventTraps) setTimer(interval float64, line int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.timerLine = line
+	e.timerInterval = time.Duration(interval * float64(time.Second))
+	e.timerOn = line != 0
+	e.timerDue = time.Now().Add(e.timerInterval)
+	e.updateArmedWithoutLock()
+}
+
+// switchTimer implements TIMER ON and TIMER OFF. Switching the timer on
+// starts a full interval, calls missed while it was off are not made up.
+func (e *eventTraps) switchTimer(on bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if on && !e.timerOn {
+		e.timerDue = time.Now().Add(e.timerInterval)
+	}
+	e.timerOn = on
+	e.updateArmedWithoutLock()
+}
+
+// setInkey installs the ON INKEY handler at line, 0 removes it
+func (e *eventTraps) setInkey(line int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.inkeyLine = line
+	e.keyPressed = false
+	e.updateArmedWithoutLock()
+}
+
+// press remembers a key press for the ON INKEY handler
+func (e *eventTraps) press() {
+	if !e.armed.Load() {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.inkeyLine != 0 {
+		e.keyPressed = true
+	}
+}
+
+// takeEvent returns the handler line of a pending key press or a due
+// timer, the key press first. gosubDepth is the current GOSUB depth; the
+// running handler has returned once it drops below the depth it started at.
+func (e *eventTraps) takeEvent(gosubDepth int, now time.Time) (int, bool) {
+	if !e.armed.Load() {
+		return 0, false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.handlerDepth > 0 {
+		if gosubDepth >= e.handlerDepth {
+			return 0, false
+		}
+		e.handlerDepth = 0
+		e.yield = true
+	}
+	if e.yield {
+		e.yield = false
+		return 0, false
+	}
+
+	line := 0
+	switch {
+	case e.inkeyLine != 0 && e.keyPressed:
+		e.keyPressed = false
+		line = e.inkeyLine
+	case e.timerLine != 0 && e.timerOn && !now.Before(e.timerDue):
+		// The next interval starts now, a slow handler does not pile up calls
+		e.timerDue = now.Add(e.timerInterval)
+		line = e.timerLine
+	}
+	if line == 0 {
+		return 0, false
+	}
+	e.handlerDepth = gosubDepth + 1
+	return line, true
+}
+
+// isOnEvent reports whether the arguments of ON start an ON TIMER or ON
+// INKEY statement
+func isOnEvent(args string) bool {
+	upper := strings.ToUpper(strings.TrimSpace(args))
+	return strings.HasPrefix(upper, "TIMER") || strings.HasPrefix(upper, "INKEY")
+}
+
+// parseOnEvent parses "TIMER(n) GOSUB line" and "INKEY GOSUB line" (the
+// arguments of ON). interval is n in seconds, 0 for ON INKEY; line 0
+// removes the handler.
+func parseOnEvent(args string) (float64, int, bool) {
+	upper := strings.ToUpper(strings.TrimSpace(args))
+	interval := 0.0
+	rest, isInkey := strings.CutPrefix(upper, "INKEY")
+	if !isInkey {
+		var ok bool
+		if rest, ok = strings.CutPrefix(upper, "TIMER"); !ok {
+			return 0, 0, false
+		}
+		if rest, ok = strings.CutPrefix(strings.TrimSpace(rest), "("); !ok {
+			return 0, 0, false
+		}
+		var number string
+		if number, rest, ok = strings.Cut(rest, ")"); !ok {
+			return 0, 0, false
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+		if err != nil || n < minTimerInterval || n > maxTimerInterval {
+			return 0, 0, false
+		}
+		interval = n
+	}
+	fields := strings.Fields(rest)
+	if len(fields) != 2 || fields[0] != "GOSUB" {
+		return 0, 0, false
+	}
+	line, err := strconv.Atoi(fields[1])
+	if err != nil || line < 0 {
+		return 0, 0, false
+	}
+	return interval, line, true
+}
+
+// parseTimerSwitch parses the argument of TIMER ON and TIMER OFF
+func parseTimerSwitch(args string) (bool, bool) {
+	switch strings.ToUpper(strings.TrimSpace(args)) {
+	case "ON":
+		return true, true
+	case "OFF":
+		return false, true
+	}
+	return false, false
+}
+
+// cmdOnEvent handles ON TIMER(n) GOSUB line and ON INKEY GOSUB line. args is
+// the text after ON. Assumes lock is held.
+func (b *TinyBASIC) cmdOnEvent(args string) error {
+	interval, line, ok := parseOnEvent(args)
+	if !ok {
+		return NewBASICError(ErrCategorySyntax, "UNEXPECTED_TOKEN", b.currentLine == 0, b.currentLine).
+			WithCommand("ON").WithUsageHint(fmt.Sprintf("ON TIMER(seconds) GOSUB lineNumber (%g-%d s) | ON INKEY GOSUB lineNumber", minTimerInterval, maxTimerInterval))
+	}
+	if line != 0 {
+		if _, exists := b.program[line]; !exists {
+			return NewBASICError(ErrCategoryExecution, "LINE_NOT_FOUND", b.currentLine == 0, b.currentLine).WithCommand("ON")
+		}
+	}
+	if interval == 0 {
+		b.events.setInkey(line)
+	} else {
+		b.events.setTimer(interval, line)
+	}
+	return nil
+}
+
+// cmdTimer handles TIMER ON and TIMER OFF. Assumes lock is held.
+func (b *TinyBASIC) cmdTimer(args string) error {
+	on, ok := parseTimerSwitch(args)
+	if !ok {
+		return NewBASICError(ErrCategorySyntax, "UNEXPECTED_TOKEN", b.currentLine == 0, b.currentLine).
+			WithCommand("TIMER").WithUsageHint("TIMER ON | TIMER OFF")
+	}
+	b.events.switchTimer(on)
+	return nil
+}
+
+// startEventTrap calls a due ON TIMER or ON INKEY handler like a GOSUB
+// before the next line runs. Assumes lock is held.
+func (b *TinyBASIC) startEventTrap() {
+	if !b.events.armed.Load() || b.currentLine == 0 || b.resumeSubStatementIndex != 0 ||
+		len(b.gosubStack) >= MaxGosubDepth {
+		return
+	}
+	line, ok := b.events.takeEvent(len(b.gosubStack), time.Now())
+	if !ok {
+		return
+	}
+	if _, exists := b.program[line]; !exists {
+		return
+	}
+	b.gosubStack = append(b.gosubStack, b.currentLine)
+	b.currentLine = line
+}
+
+// handleOnEvent installs or removes an ON TIMER or ON INKEY handler.
+// Operand1 is the timer interval in seconds, 0 for ON INKEY; Operand2 the
+// handler line.
+func (vm *BytecodeVM) handleOnEvent(inst *Instruction) error {
+	interval := inst.Operand1.(float64)
+	line := inst.Operand2.(int)
+	if line != 0 {
+		if _, exists := vm.program.Labels[line]; !exists {
+			return fmt.Errorf("undefined line number %d", line)
+		}
+	}
+	if vm.tinybasic != nil {
+		if interval == 0 {
+			vm.tinybasic.events.setInkey(line)
+		} else {
+			vm.tinybasic.events.setTimer(interval, line)
+		}
+	}
+	vm.pc++
+	return nil
+}
+
+// handleTimer is the bytecode version of TIMER ON and TIMER OFF
+func (vm *BytecodeVM) handleTimer(inst *Instruction) error {
+	if vm.tinybasic != nil {
+		vm.tinybasic.events.switchTimer(inst.Operand1.(bool))
+	}
+	vm.pc++
+	return nil
+}
+
+// startEventTrap is the bytecode version of the event call: at the first
+// instruction of a line a due handler is called like a GOSUB
+func (vm *BytecodeVM) startEventTrap() {
+	b := vm.tinybasic
+	if b == nil || !b.events.armed.Load() || len(vm.callStack) >= MaxGosubDepth {
+		return
+	}
+	instructions := vm.program.Instructions
+	if vm.pc >= len(instructions) || instructions[vm.pc].LineNum == 0 ||
+		(vm.pc > 0 && instructions[vm.pc-1].LineNum == instructions[vm.pc].LineNum) {
+		return
+	}
+	line, ok := b.events.takeEvent(len(vm.callStack), time.Now())
+	if !ok {
+		return
+	}
+	addr, exists := vm.program.Labels[line]
+	if !exists {
+		return
+	}
+	vm.callStack = append(vm.callStack, vm.pc)
+	vm.pc = addr
+}
@@ -0,0 +1,138 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestOnTimerGosub expects the timer handler to run repeatedly and TIMER OFF
+// to stop it
+func TestOnTimerGosub(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startKeyProgram(t, b, bytecode,
+			`10 LET C = 0`,
+			`15 LET N = 0`,
+			`20 ON TIMER(0.05) GOSUB 100`,
+			`25 LET N = N + 1`,
+			`30 IF C < 3 THEN GOTO 25`,
+			`40 TIMER OFF`,
+			`50 LET D = C`,
+			`60 SLEEP 200`,
+			`70 IF C = D AND C >= 3 THEN PRINT "STOPPED"`,
+			`80 END`,
+			`100 LET C = C + 1`,
+			`110 RETURN`,
+		)
+		waitUntilStopped(t, b)
+		if out := textOutput(b); !strings.Contains(out, "STOPPED") {
+			t.Errorf("bytecode=%v: output %q", bytecode, out)
+		}
+	}
+}
+
+// TestOnTimerNoReentry uses a handler slower than the timer: it must not
+// be entered again while it runs, and the main program must keep running
+func TestOnTimerNoReentry(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startKeyProgram(t, b, bytecode,
+			`10 LET H = 0`,
+			`15 LET M = 0`,
+			`17 LET D = 0`,
+			`20 ON TIMER(0.05) GOSUB 100`,
+			`30 LET M = M + 1`,
+			`40 IF H < 4 THEN GOTO 30`,
+			`50 IF M >= 3 THEN PRINT "MAIN RAN"`,
+			`60 END`,
+			`100 LET D = D + 1`,
+			`110 IF D > 1 THEN PRINT "NESTED"`,
+			`120 SLEEP 80`,
+			`130 LET H = H + 1`,
+			`140 LET D = D - 1`,
+			`150 RETURN`,
+		)
+		waitUntilStopped(t, b)
+		out := textOutput(b)
+		if !strings.Contains(out, "MAIN RAN") || strings.Contains(out, "NESTED") {
+			t.Errorf("bytecode=%v: output %q", bytecode, out)
+		}
+	}
+}
+
+// TestOnInkeyGosub presses a key and expects the handler to see it with INKEY$
+func TestOnInkeyGosub(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startKeyProgram(t, b, bytecode,
+			`10 LET H = 0`,
+			`15 LET N = 0`,
+			`20 ON INKEY GOSUB 100`,
+			`25 LET N = N + 1`,
+			`30 IF H = 0 THEN GOTO 25`,
+			`40 PRINT "BACK"`,
+			`50 END`,
+			`100 LET K$ = INKEY$`,
+			`110 PRINT "KEY "; K$`,
+			`115 LET H = 1`,
+			`120 RETURN`,
+		)
+		time.Sleep(100 * time.Millisecond)
+		b.SetKeyPressed("x")
+		waitUntilStopped(t, b)
+		b.SetKeyReleased("x")
+
+		// The VM sends the parts of a PRINT line as messages of their own
+		out := strings.ReplaceAll(textOutput(b), "\n", "")
+		for _, want := range []string{"KEY x", "BACK"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("bytecode=%v: output %q does not contain %q", bytecode, out, want)
+			}
+		}
+	}
+}
+
+// TestTakeEventYields checks that the main program gets a line between two
+// handlers and that a key press waits while a handler runs
+func TestTakeEventYields(t *testing.T) {
+	var e eventTraps
+	e.setInkey(100)
+	e.press()
+	if line, ok := e.takeEvent(0, time.Now()); !ok || line != 100 {
+		t.Fatalf("takeEvent = %d, %v, want 100", line, ok)
+	}
+	e.press()
+	if _, ok := e.takeEvent(1, time.Now()); ok {
+		t.Error("handler started while the previous one runs")
+	}
+	if _, ok := e.takeEvent(0, time.Now()); ok {
+		t.Error("handler started without a line of the main program in between")
+	}
+	if line, ok := e.takeEvent(0, time.Now()); !ok || line != 100 {
+		t.Errorf("waiting key press: takeEvent = %d, %v, want 100", line, ok)
+	}
+}
+
+func TestParseOnEvent(t *testing.T) {
+	tests := []struct {
+		args     string
+		interval float64
+		line     int
+		ok       bool
+	}{
+		{"TIMER(1) GOSUB 100", 1, 100, true},
+		{"timer (0.5) gosub 200", 0.5, 200, true},
+		{"TIMER(0) GOSUB 100", 0, 0, false},
+		{"TIMER(1) GOTO 100", 0, 0, false},
+		{"INKEY GOSUB 300", 0, 300, true},
+		{"INKEY GOSUB 0", 0, 0, true},
+		{"INKEY GOSUB", 0, 0, false},
+	}
+	for _, tt := range tests {
+		interval, line, ok := parseOnEvent(tt.args)
+		if ok != tt.ok || (ok && (interval != tt.interval || line != tt.line)) {
+			t.Errorf("parseOnEvent(%q) = %v, %d, %v", tt.args, interval, line, ok)
+		}
+	}
+}
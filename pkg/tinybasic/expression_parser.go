@@ -35,6 +35,7 @@ const (
 	TOKEN_NOT
 	TOKEN_COMMA
 	TOKEN_SEMICOLON
+	TOKEN_ILLEGAL // Malformed literal, Value holds the reason
 )
 
 // ExprToken represents a lexical token for expression parsing
@@ -203,6 +204,16 @@ func (l *ExpressionLexer) NextToken() ExprToken {
 	case '"':
 		str := l.readString()
 		return ExprToken{Type: TOKEN_STRING, Value: `"` + str + `"`, StrVal: str}
+	case '&':
+		numVal, end, err := scanRadixLiteral(l.input, l.pos-1)
+		numStr := l.input[l.pos-1 : end]
+		for l.pos-1 < end {
+			l.readChar()
+		}
+		if err != nil {
+			return ExprToken{Type: TOKEN_ILLEGAL, Value: err.Error()}
+		}
+		return ExprToken{Type: TOKEN_NUMBER, Value: numStr, NumVal: numVal}
 	default:
 		if unicode.IsDigit(rune(l.char)) {
 			numStr, numVal := l.readNumber()
@@ -240,6 +251,7 @@ type ExpressionParser struct {
 
 	// Compiler reference for emitting instructions
 	compiler *BytecodeCompiler
+	start    int // First instruction of this expression, folding stops there
 }
 
 // NewExpressionParser creates a new expression parser
@@ -247,6 +259,7 @@ func NewExpressionParser(input string, compiler *BytecodeCompiler) *ExpressionPa
 	p := &ExpressionParser{
 		lexer:    NewExpressionLexer(input),
 		compiler: compiler,
+		start:    len(compiler.instructions),
 	}
 
 	// Read two tokens to initialize current and peek
@@ -411,14 +424,14 @@ func (p *ExpressionParser) parseAdditiveExpression() error {
 			if err != nil {
 				return err
 			}
-			p.compiler.Emit(OP_ADD)
+			p.emitOperator(OP_ADD)
 		case TOKEN_MINUS:
 			p.nextToken()
 			err := p.parseMultiplicativeExpression()
 			if err != nil {
 				return err
 			}
-			p.compiler.Emit(OP_SUB)
+			p.emitOperator(OP_SUB)
 		default:
 			return nil
 		}
@@ -440,21 +453,21 @@ func (p *ExpressionParser) parseMultiplicativeExpression() error {
 			if err != nil {
 				return err
 			}
-			p.compiler.Emit(OP_MUL)
+			p.emitOperator(OP_MUL)
 		case TOKEN_DIVIDE:
 			p.nextToken()
 			err := p.parsePowerExpression()
 			if err != nil {
 				return err
 			}
-			p.compiler.Emit(OP_DIV)
+			p.emitOperator(OP_DIV)
 		case TOKEN_MOD:
 			p.nextToken()
 			err := p.parsePowerExpression()
 			if err != nil {
 				return err
 			}
-			p.compiler.Emit(OP_MOD)
+			p.emitOperator(OP_MOD)
 		default:
 			return nil
 		}
@@ -475,7 +488,7 @@ func (p *ExpressionParser) parsePowerExpression() error {
 		if err != nil {
 			return err
 		}
-		p.compiler.Emit(OP_POW)
+		p.emitOperator(OP_POW)
 	}
 
 	return nil
@@ -490,7 +503,7 @@ func (p *ExpressionParser) parseUnaryExpression() error {
 		if err != nil {
 			return err
 		}
-		p.compiler.Emit(OP_NEG)
+		p.emitOperator(OP_NEG)
 		return nil
 	case TOKEN_PLUS:
 		p.nextToken()
@@ -540,9 +553,11 @@ func (p *ExpressionParser) parsePrimaryExpression() error {
 		if err != nil {
 			return err
 		}
-		if !p.expectPeek(TOKEN_RPAREN) {
+		// Like the argument parser, the inner expression stops on the ')'
+		if !p.currentTokenIs(TOKEN_RPAREN) {
 			return fmt.Errorf("expected ')'")
 		}
+		p.nextToken()
 		return nil
 
 	default:
@@ -575,9 +590,11 @@ func (p *ExpressionParser) parseFunctionCall(funcName string) error {
 		}
 	}
 
-	if !p.expectPeek(TOKEN_RPAREN) {
+	// The argument parser stops on the closing parenthesis
+	if !p.currentTokenIs(TOKEN_RPAREN) {
 		return fmt.Errorf("expected ')' after function arguments")
 	}
+	p.nextToken()
 
 	// Emit function call instruction
 	p.compiler.Emit(OP_CALL_FUNC, funcName, argCount)
@@ -592,7 +609,7 @@ func (c *BytecodeCompiler) CompileExpression(expr string) error {
 	}
 
 	// Try constant folding optimization first
-	if folded, ok := c.tryConstantFold(expr); ok {
+	if folded, ok := c.tryConstantFold(expr); ok && !c.noFolding {
 		if folded.IsNumeric {
 			c.EmitConstant(folded.NumValue)
 		} else {
@@ -993,6 +1010,9 @@ func (p *ConstantExpressionParser) parseConstantFunction() (float64, error) {
 	p.nextToken() // Skip )
 
 	switch funcName {
+	case "SIN", "COS", "TAN", "ASIN", "ACOS", "ATAN":
+		// The result depends on OPTION ANGLE, which is only known at run time
+		return 0, fmt.Errorf("%s cannot be folded", funcName)
 	case "ABS":
 		if arg < 0 {
 			return -arg, nil
@@ -1000,18 +1020,6 @@ func (p *ConstantExpressionParser) parseConstantFunction() (float64, error) {
 		return arg, nil
 	case "INT":
 		return float64(int64(arg)), nil
-	case "SIN":
-		return math.Sin(arg), nil
-	case "COS":
-		return math.Cos(arg), nil
-	case "TAN":
-		return math.Tan(arg), nil
-	case "ASIN":
-		return math.Asin(arg), nil
-	case "ACOS":
-		return math.Acos(arg), nil
-	case "ATAN":
-		return math.Atan(arg), nil
 	case "LOG":
 		if arg <= 0 {
 			return 0, fmt.Errorf("logarithm of non-positive number")
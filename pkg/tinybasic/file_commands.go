@@ -1,75 +1,125 @@
 package tinybasic
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/antibyte/retroterm/pkg/shared"
+	"github.com/antibyte/retroterm/pkg/virtualfs"
 )
 
 // cmdLoad loads a program. Assumes lock is held.
 func (b *TinyBASIC) cmdLoad(args string) error {
+	program, err := b.readProgramFile(args, "LOAD")
+	if err != nil {
+		return err
+	}
+	b.program = program
+	b.variables = make(map[string]BASICValue)
+	b.discardPause()
+
+	// Tastaturkonstanten nach Reset wiederherstellen
+	b.initializeKeyConstants()
+
+	b.gosubStack = b.gosubStack[:0]
+	b.forLoops = b.forLoops[:0]
+	b.data = make([]string, 0)
+	b.dataPointer = 0
+	b.currentLine = 0
+	b.running = false
+	b.inputVar = ""
+	b.closeAllFiles()
+
+	b.rebuildProgramLines()
+	b.rebuildData()
+	b.sendMessage(shared.MessageTypeText, fmt.Sprintf("Loaded %d lines.", len(program)))
+	return nil
+}
+
+// readProgramFile evaluates the filename of LOAD or CHAIN and returns the
+// lines of that program file. command names the statement in errors.
+// Assumes lock is held.
+func (b *TinyBASIC) readProgramFile(args, command string) (map[int]string, error) {
 	filenameExpr := strings.TrimSpace(args)
 	if filenameExpr == "" {
-		return NewBASICError(ErrCategorySyntax, "MISSING_FILENAME", b.currentLine == 0, b.currentLine).WithCommand("LOAD")
+		return nil, NewBASICError(ErrCategorySyntax, "MISSING_FILENAME", b.currentLine == 0, b.currentLine).WithCommand(command)
 	}
 	filenameVal, err := b.evalExpression(filenameExpr)
 	if err != nil || filenameVal.IsNumeric {
-		return NewBASICError(ErrCategoryEvaluation, "INVALID_EXPRESSION", b.currentLine == 0, b.currentLine).WithCommand("LOAD")
+		return nil, NewBASICError(ErrCategoryEvaluation, "INVALID_EXPRESSION", b.currentLine == 0, b.currentLine).WithCommand(command)
 	}
 	filename := filenameVal.StrValue
 	if !strings.Contains(filename, ".") {
 		filename += ".bas"
 	}
 	if b.fs == nil {
-		return NewBASICError(ErrCategoryFileSystem, "FILE_SYSTEM_ERROR", b.currentLine == 0, b.currentLine).WithCommand("LOAD")
+		return nil, NewBASICError(ErrCategoryFileSystem, "FILE_SYSTEM_ERROR", b.currentLine == 0, b.currentLine).WithCommand(command)
 	}
 	content, err := b.fs.ReadFile(filename, b.sessionID)
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "not found") {
-			return NewBASICError(ErrCategoryFileSystem, "FILE_NOT_FOUND", b.currentLine == 0, b.currentLine).WithCommand("LOAD")
+			return nil, NewBASICError(ErrCategoryFileSystem, "FILE_NOT_FOUND", b.currentLine == 0, b.currentLine).WithCommand(command)
 		}
-		return NewBASICError(ErrCategoryFileSystem, "FILE_READ_ERROR", b.currentLine == 0, b.currentLine).WithCommand("LOAD")
+		return nil, NewBASICError(ErrCategoryFileSystem, "FILE_READ_ERROR", b.currentLine == 0, b.currentLine).WithCommand(command)
 	}
-	b.program = make(map[int]string)
-	b.variables = make(map[string]BASICValue)
-
-	// Tastaturkonstanten nach Reset wiederherstellen
-	b.initializeKeyConstants()
-
-	b.gosubStack = b.gosubStack[:0]
-	b.forLoops = b.forLoops[:0]
-	b.data = make([]string, 0)
-	b.dataPointer = 0
-	b.currentLine = 0
-	b.running = false
-	b.inputVar = ""
-	b.closeAllFiles()
 
 	// Clean the content to remove non-printable characters that could cause parsing issues
 	content = cleanCodeForLoading(content)
 
 	lines := strings.Split(content, "\n")
-	linesLoaded := 0
+	for _, line := range lines {
+		if b.lineTooLong(line) {
+			return nil, NewBASICError(ErrCategoryFileSystem, "LINE_TOO_LONG", b.currentLine == 0, b.currentLine).WithCommand(command)
+		}
+	}
+	program := make(map[int]string)
 	for _, line := range lines {
 		if num, code, isLine := parseProgramLine(line); isLine && code != "" {
-			b.program[num] = code
-			linesLoaded++
+			program[num] = code
 		}
 	}
-	b.rebuildProgramLines()
-	b.rebuildData()
-	b.sendMessage(shared.MessageTypeText, fmt.Sprintf("Loaded %d lines.", linesLoaded))
-	return nil
+	return program, nil
+}
+
+// lineTooLong reports whether a line read from a file exceeds the configured maximum.
+// Assumes lock is held.
+func (b *TinyBASIC) lineTooLong(line string) bool {
+	limit := b.maxFileLineLength
+	if limit < 1 {
+		limit = DefaultMaxFileLineLength
+	}
+	return len(line) > limit
+}
+
+// saveUsage is the usage hint for SAVE
+const saveUsage = `SAVE "filename" [, F | , A]`
+
+// pendingSave is a SAVE waiting for the answer whether to overwrite its file
+type pendingSave struct {
+	filename string
+	content  string
+	lines    int
 }
 
-// cmdSave saves the current program. Assumes lock is held.
+// cmdSave saves the current program. An existing file is only replaced after
+// a y/n question, SAVE "name", F replaces it without asking and SAVE "name", A
+// appends the program to it. Assumes lock is held.
 func (b *TinyBASIC) cmdSave(args string) error {
-	filenameExpr := strings.TrimSpace(args)
+	parts := splitTopLevelArgs(strings.TrimSpace(args))
+	filenameExpr := parts[0]
 	if filenameExpr == "" {
 		return NewBASICError(ErrCategorySyntax, "MISSING_FILENAME", b.currentLine == 0, b.currentLine).WithCommand("SAVE")
 	}
+	mode := ""
+	if len(parts) == 2 {
+		mode = strings.ToUpper(parts[1])
+	}
+	if len(parts) > 2 || (len(parts) == 2 && mode != "F" && mode != "A") {
+		return NewBASICError(ErrCategorySyntax, "UNEXPECTED_TOKEN", b.currentLine == 0, b.currentLine).
+			WithCommand("SAVE").WithUsageHint(saveUsage)
+	}
 	filenameVal, err := b.evalExpression(filenameExpr)
 	if err != nil || filenameVal.IsNumeric {
 		return NewBASICError(ErrCategorySyntax, "INVALID_EXPRESSION", b.currentLine == 0, b.currentLine).WithCommand("SAVE")
@@ -85,15 +135,115 @@ func (b *TinyBASIC) cmdSave(args string) error {
 	for _, num := range b.programLines {
 		sb.WriteString(fmt.Sprintf("%d %s\n", num, b.program[num]))
 	}
-	content := sb.String()
-	err = b.fs.WriteFile(filename, content, b.sessionID)
+	save := &pendingSave{filename: filename, content: sb.String(), lines: len(b.programLines)}
+
+	switch {
+	case mode == "A":
+		return b.appendSave(save)
+	case mode == "F" || !b.fs.Exists(filename, b.sessionID):
+		return b.writeSave(save)
+	}
+
+	// Ask like INPUT: a running program waits for the answer here, in
+	// direct mode ExecuteInputResponse finishes the SAVE
+	select {
+	case <-b.inputChan:
+	default:
+	}
+	b.pendingSave = save
+	b.sendInputControl("enable")
+	b.sendMessageWrapped(shared.MessageTypeText, filename+" exists. Overwrite (y/n)? ")
+	if !b.running {
+		return nil
+	}
+	ctx := b.ctx
+	b.mu.Unlock()
+	answer, ok := b.awaitInput(ctx)
+	b.mu.Lock()
+	if !ok {
+		b.pendingSave = nil
+		return nil
+	}
+	return b.completeSave(save, answer.StrValue)
+}
+
+// answerSave passes the answer to an overwrite question of SAVE on. Called by
+// ExecuteInputResponse with the lock held, unlocks it before returning.
+func (b *TinyBASIC) answerSave(input string) []shared.Message {
+	save := b.pendingSave
+	b.pendingSave = nil
+
+	if b.running {
+		b.sendInputControl("run_mode")
+		select {
+		case b.inputChan <- newStringBASICValue(input):
+		default:
+		}
+		b.mu.Unlock()
+		return nil
+	}
+
+	err := b.completeSave(save, input)
+	b.mu.Unlock()
 	if err != nil {
-		return NewBASICError(ErrCategoryFileSystem, "FILE_WRITE_ERROR", b.currentLine == 0, b.currentLine).WithCommand("SAVE")
+		return FormatErrorAsMessages(err)
+	}
+	b.sendMessageWrapped(shared.MessageTypeText, "OK")
+	return nil
+}
+
+// completeSave writes save if answer is yes. Assumes lock is held.
+func (b *TinyBASIC) completeSave(save *pendingSave, answer string) error {
+	b.pendingSave = nil
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return b.writeSave(save)
 	}
-	b.sendMessage(shared.MessageTypeText, fmt.Sprintf("Saved %d lines.", len(b.programLines)))
+	b.sendMessage(shared.MessageTypeText, "Not saved.")
 	return nil
 }
 
+// writeSave writes the program to its file, replacing what was there.
+// Assumes lock is held.
+func (b *TinyBASIC) writeSave(save *pendingSave) error {
+	if err := b.fs.WriteFile(save.filename, save.content, b.sessionID); err != nil {
+		return NewBASICError(ErrCategoryFileSystem, writeErrorCode(err, "FILE_WRITE_ERROR"), b.currentLine == 0, b.currentLine).WithCommand("SAVE")
+	}
+	b.sendMessage(shared.MessageTypeText, fmt.Sprintf("Saved %d lines.", save.lines))
+	return nil
+}
+
+// appendSave adds the program to the end of its file, which is created if
+// needed. On LOAD, appended lines replace earlier ones with the same number.
+// Assumes lock is held.
+func (b *TinyBASIC) appendSave(save *pendingSave) error {
+	content := save.content
+	if b.fs.Exists(save.filename, b.sessionID) {
+		existing, err := b.fs.ReadFile(save.filename, b.sessionID)
+		if err != nil {
+			return NewBASICError(ErrCategoryFileSystem, "FILE_READ_ERROR", b.currentLine == 0, b.currentLine).WithCommand("SAVE")
+		}
+		if existing != "" && !strings.HasSuffix(existing, "\n") {
+			existing += "\n"
+		}
+		content = existing + content
+	}
+	if err := b.fs.WriteFile(save.filename, content, b.sessionID); err != nil {
+		return NewBASICError(ErrCategoryFileSystem, writeErrorCode(err, "FILE_WRITE_ERROR"), b.currentLine == 0, b.currentLine).WithCommand("SAVE")
+	}
+	b.sendMessage(shared.MessageTypeText, fmt.Sprintf("Appended %d lines to %s.", save.lines, save.filename))
+	return nil
+}
+
+// writeErrorCode returns the BASIC error code for a failed WriteFile:
+// FILE_LOCKED for a file locked with the lock command, fallback otherwise
+func writeErrorCode(err error, fallback string) string {
+	if errors.Is(err, virtualfs.ErrReadOnly) {
+		return "FILE_LOCKED"
+	}
+	return fallback
+}
+
 // cmdDir lists all files. Returns listing string. Assumes lock is held.
 func (b *TinyBASIC) cmdDir(args string) (string, error) {
 	if args != "" {
@@ -209,7 +359,7 @@ func (b *TinyBASIC) cmdClose(args string) error {
 		err := b.fs.WriteFile(of.Name, content, b.sessionID)
 		if err != nil {
 			delete(b.openFiles, handle)
-			return NewBASICError(ErrCategoryIO, "FILE_SYSTEM_ERROR", b.currentLine == 0, b.currentLine).WithCommand("CLOSE")
+			return NewBASICError(ErrCategoryIO, writeErrorCode(err, "FILE_SYSTEM_ERROR"), b.currentLine == 0, b.currentLine).WithCommand("CLOSE")
 		}
 	}
 
@@ -276,6 +426,9 @@ func (b *TinyBASIC) cmdInputFile(args string) error {
 		if !foundLine {
 			return NewBASICError(ErrCategoryIO, "END_OF_FILE", b.currentLine == 0, b.currentLine).WithCommand("INPUT #")
 		}
+		if b.lineTooLong(line) {
+			return NewBASICError(ErrCategoryIO, "LINE_TOO_LONG", b.currentLine == 0, b.currentLine).WithCommand("INPUT #")
+		}
 
 		// Assign value (handle type conversion).
 		trimmedLine := strings.TrimSpace(line)
@@ -333,6 +486,9 @@ func (b *TinyBASIC) cmdLineInputFile(args string) error {
 
 	line := of.Lines[of.Pos]
 	of.Pos++ // Consume the line.
+	if b.lineTooLong(line) {
+		return NewBASICError(ErrCategoryIO, "LINE_TOO_LONG", b.currentLine == 0, b.currentLine).WithCommand("LINE INPUT #")
+	}
 
 	varNameUpper := strings.ToUpper(varName)
 	b.variables[varNameUpper] = BASICValue{StrValue: line, IsNumeric: false}
@@ -0,0 +1,68 @@
+package tinybasic
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// memoryFS is an in-memory FileSystem for file command tests
+type memoryFS map[string]string
+
+func (m memoryFS) ReadFile(path string, sessionID string) (string, error) {
+	content, ok := m[path]
+	if !ok {
+		return "", errors.New("file not found")
+	}
+	return content, nil
+}
+
+func (m memoryFS) WriteFile(path, content string, sessionID string) error {
+	m[path] = content
+	return nil
+}
+
+func (m memoryFS) Exists(path string, sessionID string) bool {
+	_, ok := m[path]
+	return ok
+}
+
+func (m memoryFS) ListDirProgramFiles(sessionID string) ([]string, error) { return nil, nil }
+func (m memoryFS) ListDirAllFiles(sessionID string) ([]string, error)     { return nil, nil }
+
+// TestMaxFileLineLength checks that overlong lines are rejected by INPUT #, LINE INPUT # and LOAD
+func TestMaxFileLineLength(t *testing.T) {
+	fs := memoryFS{
+		"data.txt": "SHORT\n" + strings.Repeat("X", 40) + "\n",
+		"prog.bas": "10 PRINT \"OK\"\n20 REM " + strings.Repeat("Y", 40) + "\n",
+	}
+	b := NewTinyBASIC(nil)
+	b.fs = fs
+	b.SetMaxFileLineLength(20)
+
+	if err := b.cmdOpen(`"data.txt" FOR INPUT AS #1`); err != nil {
+		t.Fatalf("OPEN failed: %v", err)
+	}
+	if err := b.cmdLineInputFile("#1, A$"); err != nil {
+		t.Fatalf("LINE INPUT # of a short line failed: %v", err)
+	}
+	if got := b.variables["A$"].StrValue; got != "SHORT" {
+		t.Errorf("A$ = %q, want %q", got, "SHORT")
+	}
+	err := b.cmdInputFile("#1, B$")
+	if err == nil || !strings.Contains(err.Error(), "EXCEEDS MAXIMUM LENGTH") {
+		t.Errorf("INPUT # of an overlong line: got %v, want line length error", err)
+	}
+	if code := errorCode(err); code != ErrCodeLineBufferOverflow {
+		t.Errorf("ERR for overlong line = %d, want %d", code, ErrCodeLineBufferOverflow)
+	}
+
+	if err := b.cmdLoad(`"prog.bas"`); err == nil || !strings.Contains(err.Error(), "EXCEEDS MAXIMUM LENGTH") {
+		t.Errorf("LOAD of an overlong line: got %v, want line length error", err)
+	}
+
+	b.SetMaxFileLineLength(0) // Default allows normal programs
+	if err := b.cmdLoad(`"prog.bas"`); err != nil {
+		t.Errorf("LOAD with the default limit failed: %v", err)
+	}
+}
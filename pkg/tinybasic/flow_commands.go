@@ -772,6 +772,9 @@ func (b *TinyBASIC) cmdIf(args string) error {
 			if err != nil {
 				return WrapError(err, "IF", b.currentLine == 0, originalLine)
 			}
+			if originalLine != 0 && !b.running {
+				break // STOP
+			}
 		}
 	} else if result.hasElse {
 		// FIXED: Gleiches Problem beim ELSE-Teil - verwende splitStatementsByColon
@@ -786,6 +789,9 @@ func (b *TinyBASIC) cmdIf(args string) error {
 			if err != nil {
 				return WrapError(err, "IF", b.currentLine == 0, originalLine)
 			}
+			if originalLine != 0 && !b.running {
+				break // STOP
+			}
 		}
 	}
 	// Bemerkung: Wenn die Bedingung falsch ist und es KEINEN ELSE-Teil gibt,
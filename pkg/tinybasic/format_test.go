@@ -0,0 +1,83 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestBasicFormat checks the supported specifiers with width and precision
+func TestBasicFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		args   []BASICValue
+		want   string
+	}{
+		{"%d ITEMS", []BASICValue{{NumValue: 42, IsNumeric: true}}, "42 ITEMS"},
+		{"[%5d]", []BASICValue{{NumValue: 42, IsNumeric: true}}, "[   42]"},
+		{"[%-5d]", []BASICValue{{NumValue: 42, IsNumeric: true}}, "[42   ]"},
+		{"[%05d]", []BASICValue{{NumValue: -42, IsNumeric: true}}, "[-0042]"},
+		{"%d", []BASICValue{{NumValue: 2.5, IsNumeric: true}}, "3"},
+		{"%f", []BASICValue{{NumValue: 1.5, IsNumeric: true}}, "1.500000"},
+		{"[%8.2f]", []BASICValue{{NumValue: 3.14159, IsNumeric: true}}, "[    3.14]"},
+		{"%.0f", []BASICValue{{NumValue: 2.7, IsNumeric: true}}, "3"},
+		{"[%-6s]", []BASICValue{{StrValue: "AB"}}, "[AB    ]"},
+		{"[%6s]", []BASICValue{{StrValue: "AB"}}, "[    AB]"},
+		{"%.3s", []BASICValue{{StrValue: "ABCDEF"}}, "ABC"},
+		{"100%%", nil, "100%"},
+		{"%s=%d", []BASICValue{{StrValue: "X"}, {NumValue: 7, IsNumeric: true}}, "X=7"},
+	}
+	for _, tt := range tests {
+		got, err := basicFormat(tt.format, tt.args)
+		if err != nil {
+			t.Errorf("basicFormat(%q) failed: %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("basicFormat(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+// TestBasicFormatErrors checks argument count, type and specifier errors
+func TestBasicFormatErrors(t *testing.T) {
+	num := BASICValue{NumValue: 1, IsNumeric: true}
+	str := BASICValue{StrValue: "A"}
+	tests := []struct {
+		format string
+		args   []BASICValue
+	}{
+		{"%d %d", []BASICValue{num}},
+		{"%d", []BASICValue{num, num}},
+		{"%d", []BASICValue{str}},
+		{"%s", []BASICValue{num}},
+		{"%x", []BASICValue{num}},
+		{"%05s", []BASICValue{str}},
+		{"%5", []BASICValue{num}},
+		{"50%", nil},
+		{"%999d", []BASICValue{num}},
+	}
+	for _, tt := range tests {
+		if got, err := basicFormat(tt.format, tt.args); err == nil {
+			t.Errorf("basicFormat(%q) = %q, want an error", tt.format, got)
+		}
+	}
+}
+
+// TestFormatProgram runs FORMAT$ with the bytecode VM and the interpreter
+func TestFormatProgram(t *testing.T) {
+	program := []string{
+		`10 N$ = "BOB"`,
+		`20 S$ = FORMAT$("%-5s|%4d|%6.2f|", N$, 7, 2.5)`,
+		`30 PRINT S$`,
+		`40 PRINT FORMAT$("%d", 1, 2)`,
+	}
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode, program...)
+		if !strings.Contains(out, "BOB  |   7|  2.50|") {
+			t.Errorf("bytecode=%v: output %q does not contain the formatted line", bytecode, out)
+		}
+		if !strings.Contains(out, "ERROR") {
+			t.Errorf("bytecode=%v: too many arguments should stop the program, got %q", bytecode, out)
+		}
+	}
+}
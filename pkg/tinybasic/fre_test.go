@@ -0,0 +1,37 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestFreProgram checks with the bytecode VM and the interpreter that FRE
+// accepts any argument and drops when a long string is stored
+func TestFreProgram(t *testing.T) {
+	program := []string{
+		`10 LET A = FRE(0)`,
+		`20 LET S$ = STRING$(1000, "X")`,
+		`30 LET B = FRE("")`,
+		`40 IF FRE(-99999) = FRE() AND FRE() = FRE(S$) THEN PRINT "SAME"`,
+		`50 IF A > B + 999 THEN PRINT "DROPS"`,
+		`60 IF A <= 65536 AND B > 0 THEN PRINT "RANGE"`,
+	}
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode, program...)
+		for _, want := range []string{"DROPS", "RANGE", "SAME"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("bytecode=%v: output %q does not contain %q", bytecode, out, want)
+			}
+		}
+	}
+}
+
+// TestFreeMemoryNeverNegative checks that FRE reports 0 once the emulated
+// memory is used up
+func TestFreeMemoryNeverNegative(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	vars := map[string]BASICValue{"S$": {StrValue: strings.Repeat("X", emulatedMemoryBytes)}}
+	if free := b.freeMemory(nil, vars); free != 0 {
+		t.Errorf("freeMemory = %v, want 0", free)
+	}
+}
@@ -388,6 +388,103 @@ func (b *TinyBASIC) cmdCircle(args string) error {
 	return nil
 }
 
+// Auflösung der 2D-Grafikebene im Frontend (GRAPHICS_WIDTH/GRAPHICS_HEIGHT in js/config.js)
+const (
+	graphicsWidth  = 640
+	graphicsHeight = 480
+)
+
+// cmdPaint implementiert den PAINT-Befehl: PAINT x, y, color, [border]
+// Füllt ab dem Startpunkt (x,y) eine Fläche mit color (Flood Fill im Frontend).
+// Ohne border wird die zusammenhängende Fläche in der Farbe des Startpunkts gefüllt,
+// mit border breitet sich die Füllung bis zu Pixeln in der Randfarbe aus.
+func (b *TinyBASIC) cmdPaint(args string) error {
+	params := splitRespectingParentheses(strings.TrimSpace(args))
+	if len(params) < 3 || len(params) > 4 {
+		return NewBASICError(ErrCategorySyntax, "INVALID_PARAMETER_COUNT", b.currentLine == 0, b.currentLine).
+			WithCommand("PAINT").
+			WithUsageHint("PAINT x, y, color, [border]")
+	}
+
+	values := make([]BASICValue, len(params))
+	paramNames := []string{"X", "Y", "color", "border"}
+	for i, param := range params {
+		val, err := b.evalExpression(strings.TrimSpace(param))
+		if err != nil {
+			return NewBASICError(ErrCategoryEvaluation, "INVALID_EXPRESSION", b.currentLine == 0, b.currentLine).
+				WithCommand("PAINT").
+				WithUsageHint(fmt.Sprintf("Error in %s parameter", paramNames[i]))
+		}
+		values[i] = val
+	}
+
+	var border *BASICValue
+	if len(values) > 3 {
+		border = &values[3]
+	}
+	return b.paint(values[0], values[1], values[2], border)
+}
+
+// paint prüft die PAINT-Parameter und sendet den Füllbefehl an das Frontend.
+// Wird auch von der Bytecode-VM mit bereits ausgewerteten Werten aufgerufen.
+func (b *TinyBASIC) paint(xVal, yVal, colorVal BASICValue, borderVal *BASICValue) error {
+	if !xVal.IsNumeric || !yVal.IsNumeric {
+		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", b.currentLine == 0, b.currentLine).
+			WithCommand("PAINT").
+			WithUsageHint("X and Y must be numeric")
+	}
+	x, _ := basicValueToInt(xVal)
+	y, _ := basicValueToInt(yVal)
+	if x < 0 || x >= graphicsWidth || y < 0 || y >= graphicsHeight {
+		return NewBASICError(ErrCategoryEvaluation, "OUT_OF_RANGE", b.currentLine == 0, b.currentLine).
+			WithCommand("PAINT").
+			WithUsageHint(fmt.Sprintf("X must be 0-%d and Y 0-%d", graphicsWidth-1, graphicsHeight-1))
+	}
+
+	paintParams := map[string]interface{}{
+		"x": x,
+		"y": y,
+	}
+	if err := b.addPaintColor(paintParams, "color", colorVal); err != nil {
+		return err
+	}
+	if borderVal != nil {
+		if err := b.addPaintColor(paintParams, "border", *borderVal); err != nil {
+			return err
+		}
+	}
+
+	paintMsg := shared.Message{
+		Type:    shared.MessageTypeGraphics,
+		Command: "PAINT",
+		Params:  paintParams,
+	}
+	if !b.sendMessageObject(paintMsg) {
+		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", b.currentLine == 0, b.currentLine).WithCommand("PAINT")
+	}
+	return nil
+}
+
+// addPaintColor trägt eine PAINT-Farbe ein: numerisch als Helligkeit 0-15, sonst als Farbstring
+func (b *TinyBASIC) addPaintColor(params map[string]interface{}, key string, val BASICValue) error {
+	if val.IsNumeric {
+		brightness, err := convertValueToBrightness(val)
+		if err != nil {
+			return err
+		}
+		hexVal := fmt.Sprintf("%02x", brightness*17)
+		params[key] = "#" + hexVal + hexVal + hexVal
+		return nil
+	}
+	if !strings.HasPrefix(val.StrValue, "#") {
+		return NewBASICError(ErrCategorySyntax, "INVALID_COLOR_FORMAT", b.currentLine == 0, b.currentLine).
+			WithCommand("PAINT").
+			WithUsageHint("Color must be a string (e.g., \"#FF0000\") or a numeric brightness (0-15)")
+	}
+	params[key] = val.StrValue
+	return nil
+}
+
 // cmdTextGFX implementiert den TEXTGFX-Befehl: TEXTGFX x, y, text, [color], [size]
 // Zeichnet Text an den angegebenen Koordinaten mit der angegebenen Farbe und Größe
 func (b *TinyBASIC) cmdTextGFX(args string) error {
@@ -398,95 +495,71 @@ func (b *TinyBASIC) cmdTextGFX(args string) error {
 			WithUsageHint("TEXTGFX x, y, text, [color], [size]")
 	}
 
-	// Position auswerten
-	xExpr := strings.TrimSpace(params[0])
-	xVal, err := b.evalExpression(xExpr)
-	if err != nil {
-		return NewBASICError(ErrCategoryEvaluation, "INVALID_EXPRESSION", b.currentLine == 0, b.currentLine).
-			WithCommand("TEXTGFX").
-			WithUsageHint("Error in X parameter")
+	paramNames := []string{"X", "Y", "text", "color", "size"}
+	values := make([]BASICValue, len(params))
+	for i, param := range params {
+		val, err := b.evalExpression(strings.TrimSpace(param))
+		if err != nil {
+			return NewBASICError(ErrCategoryEvaluation, "INVALID_EXPRESSION", b.currentLine == 0, b.currentLine).
+				WithCommand("TEXTGFX").
+				WithUsageHint("Error in " + paramNames[i] + " parameter")
+		}
+		values[i] = val
 	}
+	return b.drawTextGFX(values, b.currentLine)
+}
 
-	x, err := basicValueToInt(xVal)
+// drawTextGFX prüft die ausgewerteten Argumente von TEXTGFX und sendet den
+// Text an das Frontend. Wird vom Interpreter und von der Bytecode-VM genutzt.
+func (b *TinyBASIC) drawTextGFX(values []BASICValue, line int) error {
+	x, err := basicValueToInt(values[0])
 	if err != nil {
-		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", b.currentLine == 0, b.currentLine).
+		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", line == 0, line).
 			WithCommand("TEXTGFX").
 			WithUsageHint("X must be numeric")
 	}
 
-	yExpr := strings.TrimSpace(params[1])
-	yVal, err := b.evalExpression(yExpr)
-	if err != nil {
-		return NewBASICError(ErrCategoryEvaluation, "INVALID_EXPRESSION", b.currentLine == 0, b.currentLine).
-			WithCommand("TEXTGFX").
-			WithUsageHint("Error in Y parameter")
-	}
-
-	y, err := basicValueToInt(yVal)
+	y, err := basicValueToInt(values[1])
 	if err != nil {
-		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", b.currentLine == 0, b.currentLine).WithCommand("TEXTGFX").WithUsageHint("Y must be numeric")
+		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", line == 0, line).WithCommand("TEXTGFX").WithUsageHint("Y must be numeric")
 	}
 
 	// Text auswerten
-	textExpr := strings.TrimSpace(params[2])
-	textVal, err := b.evalExpression(textExpr)
+	textToDraw, err := basicValueToString(values[2])
 	if err != nil {
-		return NewBASICError(ErrCategoryEvaluation, "INVALID_EXPRESSION", b.currentLine == 0, b.currentLine).
-			WithCommand("TEXTGFX").
-			WithUsageHint("Error in text parameter")
-	}
-	textToDraw, err := basicValueToString(textVal) // textToDraw hier definieren
-	if err != nil {
-		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", b.currentLine == 0, b.currentLine).
+		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", line == 0, line).
 			WithCommand("TEXTGFX").
 			WithUsageHint("Text parameter must be a string")
 	}
 
-	if textToDraw == "" { // textToDraw hier verwenden
-		return NewBASICError(ErrCategorySyntax, "INVALID_PARAMETER_VALUE", b.currentLine == 0, b.currentLine).
+	if textToDraw == "" {
+		return NewBASICError(ErrCategorySyntax, "INVALID_PARAMETER_VALUE", line == 0, line).
 			WithCommand("TEXTGFX").
 			WithUsageHint("Text parameter cannot be empty")
 	}
 	// Farb-Parameter auswerten (optional)
 	color := "#5FFF5F" // Standardfarbe (grün)
-	brightness := -1   // Kennzeichnet, dass keine numerische Helligkeit verwendet wurde
-	if len(params) > 3 {
-		colorExpr := strings.TrimSpace(params[3])
-		colorVal, err := b.evalExpression(colorExpr)
-		if err != nil {
-			return NewBASICError(ErrCategoryEvaluation, "INVALID_EXPRESSION", b.currentLine == 0, b.currentLine).
-				WithCommand("TEXTGFX").
-				WithUsageHint("Error in color parameter")
-		}
-		color, err = basicValueToString(colorVal)
+	if len(values) > 3 {
+		color, err = basicValueToString(values[3])
 		if err != nil {
 			// Versuche, als Zahl zu interpretieren (für Abwärtskompatibilität mit Helligkeit)
-			brightnessVal, bErr := convertValueToBrightness(colorVal)
-			if bErr == nil {
-				brightness = brightnessVal
-				hexVal := fmt.Sprintf("%02x", brightness*17)
-				color = "#" + hexVal + hexVal + hexVal
-			} else {
-				return NewBASICError(ErrCategorySyntax, "INVALID_COLOR_FORMAT", b.currentLine == 0, b.currentLine).
+			brightness, bErr := convertValueToBrightness(values[3])
+			if bErr != nil {
+				return NewBASICError(ErrCategorySyntax, "INVALID_COLOR_FORMAT", line == 0, line).
 					WithCommand("TEXTGFX").
 					WithUsageHint("Color must be a string (e.g., \"#FF0000\") or a numeric brightness (0-15)")
 			}
+			hexVal := fmt.Sprintf("%02x", brightness*17)
+			color = "#" + hexVal + hexVal + hexVal
 		}
 	}
 
 	// Größen-Parameter auswerten (optional)
 	size := 1 // Standardgröße
-	if len(params) > 4 {
-		sizeExpr := strings.TrimSpace(params[4])
-		sizeVal, err := b.evalExpression(sizeExpr)
-		if err != nil {
-			return NewBASICError(ErrCategoryEvaluation, "INVALID_EXPRESSION", b.currentLine == 0, b.currentLine).
-				WithCommand("TEXTGFX").
-				WithUsageHint("Error in size parameter")
-		}
-		size, err = basicValueToInt(sizeVal)
+	if len(values) > 4 {
+		size, err = basicValueToInt(values[4])
 		if err != nil {
-			return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", b.currentLine == 0, b.currentLine).
+			return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", line == 0, line).
 				WithCommand("TEXTGFX").
 				WithUsageHint("Size must be numeric")
 		}
@@ -503,7 +576,7 @@ func (b *TinyBASIC) cmdTextGFX(args string) error {
 			"size":  size},
 	}
 	if !b.sendMessageObject(textGfxMsg) {
-		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", b.currentLine == 0, b.currentLine).WithCommand("TEXTGFX")
+		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", line == 0, line).WithCommand("TEXTGFX")
 	}
 	return nil
 }
@@ -526,6 +599,7 @@ func (b *TinyBASIC) cmdCls(args string) error {
 		// Für Konsistenz geben wir einen Fehler zurück.
 		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", b.currentLine == 0, b.currentLine).WithCommand("CLS")
 	}
+	b.pen = drawPen{} // DRAW beginnt wieder in der Bildmitte
 	return nil
 }
 
@@ -576,28 +650,34 @@ func convertValueToBrightness(val BASICValue) (int, error) {
 // cmdLocate implementiert den LOCATE x,y Befehl.
 // Setzt die Cursor-Position für nachfolgende PRINT-Ausgaben.
 func (b *TinyBASIC) cmdLocate(args string) error {
+	return b.locateCursor("LOCATE", args)
+}
+
+// locateCursor setzt den Cursor auf x,y (1-basiert) für LOCATE und PRINT AT.
+// command erscheint in Fehlermeldungen.
+func (b *TinyBASIC) locateCursor(command, args string) error {
 	if args == "" {
 		return NewBASICError(ErrCategorySyntax, "INVALID_PARAMETER_COUNT", b.currentLine == 0, b.currentLine).
-			WithCommand("LOCATE").
-			WithUsageHint("LOCATE requires X,Y coordinates")
+			WithCommand(command).
+			WithUsageHint(command + " requires X,Y coordinates")
 	}
 	// Parse Argumente: LOCATE x,y
 	parts := splitRespectingParentheses(strings.TrimSpace(args))
 	if len(parts) != 2 {
 		return NewBASICError(ErrCategorySyntax, "INVALID_PARAMETER_COUNT", b.currentLine == 0, b.currentLine).
-			WithCommand("LOCATE").
-			WithUsageHint("LOCATE requires X,Y coordinates")
+			WithCommand(command).
+			WithUsageHint(command + " requires X,Y coordinates")
 	}
 	// X-Koordinate evaluieren
 	xVal, err := b.evalExpression(strings.TrimSpace(parts[0]))
 	if err != nil {
 		return NewBASICError(ErrCategoryEvaluation, "INVALID_EXPRESSION", b.currentLine == 0, b.currentLine).
-			WithCommand("LOCATE").
+			WithCommand(command).
 			WithUsageHint("Error evaluating X coordinate")
 	}
 	if !xVal.IsNumeric {
 		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", b.currentLine == 0, b.currentLine).
-			WithCommand("LOCATE").
+			WithCommand(command).
 			WithUsageHint("X coordinate must be numeric")
 	}
 
@@ -605,12 +685,12 @@ func (b *TinyBASIC) cmdLocate(args string) error {
 	yVal, err := b.evalExpression(strings.TrimSpace(parts[1]))
 	if err != nil {
 		return NewBASICError(ErrCategoryEvaluation, "INVALID_EXPRESSION", b.currentLine == 0, b.currentLine).
-			WithCommand("LOCATE").
+			WithCommand(command).
 			WithUsageHint("Error evaluating Y coordinate")
 	}
 	if !yVal.IsNumeric {
 		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", b.currentLine == 0, b.currentLine).
-			WithCommand("LOCATE").
+			WithCommand(command).
 			WithUsageHint("Y coordinate must be numeric")
 	}
 
@@ -620,12 +700,12 @@ func (b *TinyBASIC) cmdLocate(args string) error {
 	// Validiere Koordinaten (1-basiert für Benutzer, aber intern 0-basiert)
 	if x < 1 || y < 1 {
 		return NewBASICError(ErrCategoryEvaluation, "INVALID_PARAMETER_VALUE", b.currentLine == 0, b.currentLine).
-			WithCommand("LOCATE").
+			WithCommand(command).
 			WithUsageHint("Coordinates must be >= 1")
 	}
 	if x > b.termCols || y > b.termRows {
 		return NewBASICError(ErrCategoryEvaluation, "INVALID_PARAMETER_VALUE", b.currentLine == 0, b.currentLine).
-			WithCommand("LOCATE").
+			WithCommand(command).
 			WithUsageHint(fmt.Sprintf("Coordinates out of range (max %dx%d)", b.termCols, b.termRows))
 	}
 
@@ -639,7 +719,7 @@ func (b *TinyBASIC) cmdLocate(args string) error {
 	}
 
 	if !b.sendMessageObject(locateMsg) {
-		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", b.currentLine == 0, b.currentLine).WithCommand("LOCATE")
+		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", b.currentLine == 0, b.currentLine).WithCommand(command)
 	}
 
 	return nil
@@ -0,0 +1,39 @@
+package tinybasic
+
+import (
+	"testing"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// TestPaintCommand checks the PAINT message payload and the coordinate validation
+func TestPaintCommand(t *testing.T) {
+	b := NewTinyBASIC(nil)
+
+	if err := b.cmdPaint(`100, 50, 15, "#FF0000"`); err != nil {
+		t.Fatalf("PAINT failed: %v", err)
+	}
+
+	var paintMsg *shared.Message
+	for len(b.OutputChan) > 0 {
+		msg := <-b.OutputChan
+		if msg.Type == shared.MessageTypeGraphics && msg.Command == "PAINT" {
+			paintMsg = &msg
+		}
+	}
+	if paintMsg == nil {
+		t.Fatal("no PAINT graphics message sent")
+	}
+	if paintMsg.Params["x"] != 100 || paintMsg.Params["y"] != 50 {
+		t.Errorf("unexpected coordinates: %v", paintMsg.Params)
+	}
+	if paintMsg.Params["color"] != "#ffffff" || paintMsg.Params["border"] != "#FF0000" {
+		t.Errorf("unexpected colors: %v", paintMsg.Params)
+	}
+
+	for _, args := range []string{`-1, 0, 15`, `640, 0, 15`, `0, 480, 15`, `0, 0`, `0, 0, "red"`} {
+		if err := b.cmdPaint(args); err == nil {
+			t.Errorf("expected error for PAINT %s", args)
+		}
+	}
+}
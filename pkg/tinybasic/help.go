@@ -54,12 +54,12 @@ func GetOverviewHelpText() []shared.Message {
 
 	// All available commands in a compact list
 	commands := []string{
-		"REM", "END", "DATA", "READ", "RESTORE", "LET", "DIM", "PRINT", "INPUT",
-		"CLS", "LOCATE", "INVERSE", "IF", "GOTO", "GOSUB", "RETURN", "FOR", "NEXT",
-		"RUN", "LIST", "NEW", "LOAD", "SAVE", "DIR", "EDITOR", "VARS", "PLOT",
-		"LINE", "RECT", "CIRCLE", "BEEP", "SOUND", "NOISE", "SAY", "MUSIC",
+		"REM", "END", "DATA", "READ", "RESTORE", "LET", "LSET", "RSET", "DIM", "PRINT", "INPUT",
+		"CLS", "LOCATE", "INVERSE", "WIDTH", "IF", "GOTO", "GOSUB", "RETURN", "FOR", "NEXT",
+		"ON", "RESUME", "OPTION", "STOP", "CONT", "RUN", "BENCH", "LIST", "DELETE", "NEW", "LOAD", "SAVE", "SAVEVAR", "CHAIN", "COMMON", "DIR", "EDITOR", "VARS", "PLOT",
+		"LINE", "RECT", "CIRCLE", "PAINT", "DRAW", "SCREEN", "FLIP", "BEEP", "SOUND", "NOISE", "SAY", "MUSIC",
 		"SPRITE", "VECTOR", "OPEN", "CLOSE", "PRINT#", "INPUT#", "LINE INPUT#",
-		"EOF", "WAIT", "MCP", "EXIT", "HELP",
+		"EOF", "WAIT", "SLEEP", "KEY", "TIMER", "RANDOMIZE", "MCP", "EXIT", "HELP",
 	}
 
 	// Display commands in rows of 8 for compact display
@@ -135,11 +135,13 @@ var helpTexts = map[string]string{
 - End with semicolon to prevent line break
 - Expressions can be strings or numbers
 - String literals must be enclosed in quotes
+- AT(x,y) first moves the cursor like LOCATE
 
 Examples:
   PRINT "Hello, World!"
   PRINT A, B, C
-  PRINT "The answer is"; A`,
+  PRINT "The answer is"; A
+  PRINT AT(10,5); "SCORE:"; S`,
 
 	"LET": `Assigns a value to a variable.
 - Variable name must start with a letter
@@ -152,15 +154,39 @@ Examples:
   B = A * 2
   LET NAME$ = "John"`,
 
+	"LSET": `Left-justifies a string in a fixed-width field.
+- The field is the current length of the string variable
+- Shorter values are padded with spaces on the right
+- Longer values are cut off on the right
+- RSET justifies to the right instead
+
+Example:
+  LET N$ = SPACE$(10)
+  LSET N$ = "NAME"`,
+
+	"RSET": `Right-justifies a string in a fixed-width field.
+- The field is the current length of the string variable
+- Shorter values are padded with spaces on the left
+- Longer values are cut off on the right
+- LSET justifies to the left instead
+
+Example:
+  LET S$ = SPACE$(6)
+  RSET S$ = STR$(1234)`,
+
 	"INPUT": `Reads user input into a variable.
 - Can display an optional prompt
+- ";" after the prompt adds "? ", "," shows it as is
+- A default before ";var" is used when Enter is
+  pressed without typing; it is shown in brackets
 - String variables receive text as entered
 - Numeric variables require valid numbers
 
 Examples:
   INPUT A
   INPUT "Enter your name"; NAME$
-  INPUT "Value"; X`,
+  INPUT "Value: ", X
+  INPUT "Age", 18; AGE`,
 
 	"GOTO": `Jumps execution to specified line number.
 - Program continues from that line
@@ -205,12 +231,186 @@ Example:
 Example:
   RETURN`,
 
+	"ON": `Installs an error, function key or event handler.
+- ON ERROR GOTO line jumps to line when a runtime error occurs
+- ERR holds the error code, ERL the line of the error
+- ON ERROR GOTO 0 removes the handler
+- An error inside the handler ends the program
+- BREAK cannot be trapped
+- ON KEY(n) GOSUB line calls line when F1-F10 is pressed,
+  before the next program line; ON KEY(n) GOSUB 0 removes it
+- ON TIMER(s) GOSUB line calls line every s seconds,
+  ON INKEY GOSUB line when a key is pressed (read it with INKEY$)
+- Event handlers do not interrupt themselves; TIMER OFF pauses
+
+Example:
+  10 ON ERROR GOTO 1000
+  20 ON KEY(1) GOSUB 2000
+  30 ON TIMER(0.5) GOSUB 3000
+  1000 PRINT "ERROR"; ERR; "IN LINE"; ERL: RESUME NEXT`,
+
+	"KEY": `Defines a function key macro.
+- KEY n, text$ makes F1-F10 type text$ while the program runs
+- INKEY$ then returns the text one character per call
+- KEY n, "" removes the macro, INKEY$ returns KEYFn again
+- RUN removes all macros
+
+Example:
+  10 KEY 1, "FIRE"`,
+
+	"TIMER": `Switches the ON TIMER handler on or off.
+- TIMER OFF pauses it, TIMER ON starts a new interval
+- Calls missed while it was off are not made up
+- ON TIMER(s) GOSUB 0 removes the handler
+
+Example:
+  10 ON TIMER(1) GOSUB 500
+  20 TIMER OFF`,
+
+	"RESUME": `Leaves an ON ERROR handler.
+- RESUME repeats the statement that failed
+- RESUME NEXT continues after the failed statement
+- RESUME line continues at the given line
+
+Example:
+  RESUME NEXT`,
+
+	"ERR": `Error code of the last trapped error.
+- 11 = division by zero, 53 = file not found, 62 = input past end
+- 5 = illegal function call, 9 = subscript out of range, 13 = type mismatch
+
+Example:
+  IF ERR = 53 THEN PRINT "FILE NOT FOUND"`,
+
+	"OPTION": `Sets a program option until the next RUN or NEW.
+- OPTION ANGLE DEGREES: SIN, COS and TAN take degrees,
+  ATN and ATAN2 return degrees
+- OPTION ANGLE RADIANS: back to the default
+- OPTION COMPARE TEXT: =, <>, <, >, <= and >= ignore the case
+  of strings, e.g. for sorting names
+- OPTION COMPARE BINARY: byte-wise comparison again (default)
+
+Example:
+  OPTION ANGLE DEGREES
+  PRINT SIN(90)`,
+
+	"ATAN2": `Angle of the point (x, y) from the x axis: ATAN2(y, x).
+- Result between -PI and PI (or -180 and 180 degrees)
+- Works in all four quadrants, also for x = 0
+
+Example:
+  PRINT ATAN2(1, -1)`,
+
+	"FORMAT$": `Builds a string from a format and values, like printf.
+- %d number as integer (rounded), %f number with decimals,
+  %s string, %% a percent sign
+- Width and precision: %5d, %-10s (left), %05d (zeros),
+  %8.2f (2 decimals), %.3s (first 3 characters)
+- One value per specifier, numbers for %d and %f, strings for %s
+
+Example:
+  PRINT FORMAT$("%-8s %5.2f", N$, P)`,
+
+	"ENVIRON$": `Value of an environment variable of your session.
+- USER, HOME, PWD, COLUMNS and LINES are always there
+- Logged-in users also read the system variables, secrets stay hidden
+- Unknown or hidden variables give ""
+
+Example:
+  PRINT "HELLO "; ENVIRON$("USER")`,
+
+	"SYS$": `Asks the OS for a fact about your session.
+- SYS$("USER") your username, SYS$("FILES") your files
+  separated by commas
+- SYS$("DATE") is YYYY-MM-DD, SYS$("TIME") is HH:MM:SS
+- Any other name stops with an error; there is no shell access
+
+Example:
+  PRINT "FILES OF "; SYS$("USER"); ": "; SYS$("FILES")`,
+
+	"LOADVAR$": `Reads a value saved with SAVEVAR as a string.
+- LOADVAR$("KEY", "DEFAULT") gives the default for a key
+  never saved, without a default it gives ""
+- Saved numbers come back as text like STR$
+- LOADVAR("KEY", 0) reads numbers as numbers
+
+Example:
+  10 N$ = LOADVAR$("PLAYER", "NOBODY")
+  20 HI = LOADVAR("HISCORE", 0)
+  30 PRINT "HIGH SCORE "; HI; " BY "; N$`,
+
+	"LOADVAR": `Reads a number saved with SAVEVAR.
+- LOADVAR("KEY", 100) gives 100 for a key never saved,
+  without a default it gives 0
+- A key holding a string stops with a type mismatch,
+  read it with LOADVAR$
+
+Example:
+  PRINT "BEST: "; LOADVAR("HISCORE", 0)`,
+
+	"REPEAT$": `Repeats a whole string.
+- REPEAT$("AB", 3) is "ABABAB"
+- STRING$ repeats only a single character
+- The count must not be negative
+
+Example:
+  PRINT REPEAT$("-=", 20)`,
+
+	"HEX$": `Hexadecimal digits of a number, e.g. HEX$(255) is "FF".
+- The number is rounded; OCT$ and BIN$ give octal and binary
+- Negative numbers use 32-bit two's complement: HEX$(-1)
+  is "FFFFFFFF"
+- &H and &O write hex and octal numbers: &HFF is 255, &O17 is 15
+
+Example:
+  PRINT HEX$(&HC000 + 16)`,
+
+	"OCT$": `Octal digits of a number, e.g. OCT$(8) is "10".
+- Works like HEX$, see HELP HEX$
+
+Example:
+  PRINT OCT$(&O755)`,
+
+	"BIN$": `Binary digits of a number, e.g. BIN$(5) is "101".
+- Works like HEX$, see HELP HEX$
+
+Example:
+  PRINT BIN$(&HF0)`,
+
+	"FRE": `Free bytes of the emulated BASIC memory.
+- The memory is a pretend 64 KB shared by program text and variables
+- The value is an estimate, not the real memory of the server
+- Any argument works; FRE("") also empties the expression cache
+
+Example:
+  PRINT FRE(0); " BYTES FREE"`,
+
+	"ERL": `Line number of the last trapped error.
+
+Example:
+  PRINT "ERROR IN LINE"; ERL`,
+
 	"END": `Terminates program execution.
 - Can appear anywhere in program
 
 Example:
   END`,
 
+	"STOP": `Stops the program like END and prints the line.
+- Variables, FOR loops and GOSUBs are kept
+- CONT continues after the STOP
+
+Example:
+  STOP`,
+
+	"CONT": `Continues a program stopped by STOP or Ctrl+C.
+- Continues with the variables as they are now,
+  so they can be inspected or changed first
+- Not possible after the program was edited, NEW or LOAD
+
+Example:
+  CONT`,
+
 	"REM": `Inserts a comment (remark) in the program.
 - Has no effect on execution
 - Used for documenting code
@@ -227,12 +427,35 @@ Examples:
   LIST 100
   LIST 100-200`,
 
-	"RUN": `Executes the program from the beginning.
+	"DELETE": `Removes program lines (direct mode only).
+- One line, a range or an open range
+- Shows how many lines were removed
+- DELETE without a range is refused, use NEW
+
+Examples:
+  DELETE 100
+  DELETE 100-200
+  DELETE 500-`,
+
+	"RUN": `Executes the program from the beginning or from a given line.
 - Starts with the lowest line number
-- Clears previous results but not variables
+- RUN line starts at that line, e.g. to test a subroutine
+- Clears all variables before starting
 
-Example:
-  RUN`,
+Examples:
+  RUN
+  RUN 200`,
+
+	"BENCH": `Runs the program like RUN and reports its timing.
+- Takes the same arguments as RUN
+- When the program ends, prints the elapsed time and, for
+  bytecode programs, the instructions executed and the
+  instruction cache hit rate
+- The program itself runs exactly as with RUN
+
+Examples:
+  BENCH
+  BENCH "primes.bas"`,
 
 	"NEW": `Clears the current program and variables.
 - Use with caution - data can't be recovered
@@ -248,12 +471,47 @@ Examples:
   LOAD "GAME"
   LOAD "PROGRAM.BAS"`,
 
+	"CHAIN": `Loads another program and runs it from its first line.
+- Keeps only the variables declared with COMMON
+- Loops, subroutines, DATA, ON ERROR and open files start fresh
+- FILE NOT FOUND if the program does not exist; the running program goes on
+  with ON ERROR, otherwise it stops
+- Only in a running program; use RUN "file" at the prompt
+
+Examples:
+  CHAIN "PART2"
+  CHAIN NEXT$`,
+
+	"COMMON": `Marks variables to keep when CHAIN starts another program.
+- Arrays are written with empty parentheses, e.g. A()
+- All other variables are cleared by CHAIN
+
+Example:
+  10 COMMON SCORE, NAME$, MAP()
+  20 CHAIN "LEVEL2"`,
+
 	"SAVE": `Saves the current program to storage.
 - Automatically adds .bas extension if none specified
+- Asks before overwriting an existing file (y/n)
+- SAVE "name", F overwrites without asking, e.g. in programs
+- SAVE "name", A appends the program to the file
 
 Examples:
   SAVE "MYPROG"
-  SAVE "BACKUP.BAS"`,
+  SAVE "BACKUP.BAS", F
+  SAVE "PARTS", A`,
+
+	"SAVEVAR": `Keeps a value for later runs, e.g. a high score.
+- The value may be a number or a string and keeps its type
+- Keys are up to 32 letters, digits, _, - or . and ignore case
+- Up to 64 keys per user, strings up to 256 characters
+- Logged-in users keep their values; for guests they
+  last until the session ends
+- Read them back with LOADVAR$ and LOADVAR
+
+Examples:
+  SAVEVAR "HISCORE", SCORE
+  SAVEVAR "PLAYER", N$`,
 
 	"DIR": `Lists BASIC program files available.
 - Shows files with .bas extension
@@ -300,9 +558,11 @@ Example:
 
 	"RESTORE": `Resets the DATA pointer to first DATA item.
 - Allows re-reading data
+- RESTORE line continues with the first DATA at or after that line
 
 Example:
-  RESTORE`,
+  RESTORE
+  RESTORE 500`,
 
 	"CLS": `Clears the screen.
 - Removes all text but keeps program running
@@ -318,9 +578,13 @@ Example:
 	"SOUND": `Generates a tone with specified frequency and duration.
 - Frequency in Hz
 - Duration in milliseconds
+- SOUND WAIT ON queues SOUND and NOISE so each note starts
+  when the previous one has ended; SOUND WAIT OFF plays
+  them at once again. BREAK drops queued notes.
 
 Example:
-  SOUND 440, 500 (A note for half a second)`,
+  SOUND 440, 500 (A note for half a second)
+  SOUND WAIT ON: FOR I = 1 TO 8: SOUND 200 + I * 50, 150: NEXT I`,
 
 	"SAY": `Outputs text as computer speech.
 - Same as SPEAK command
@@ -345,12 +609,64 @@ Example:
 Example:
   CIRCLE 160, 100, 50`,
 
+	"PAINT": `Flood-fills an area of the graphics screen.
+- Starts at (x,y), which must lie within 0-639 / 0-479
+- Color is a brightness (0-15) or a string like "#FF0000"
+- Without border, the connected area of the start
+  pixel's color is filled
+- With border, the fill spreads until it reaches
+  pixels of the border color
+
+Example:
+  CIRCLE 160, 100, 50
+  PAINT 160, 100, 10`,
+
+	"DRAW": `Draws with a pen from a command string.
+- U D L R n: up, down, left, right n pixels
+- E F G H n: diagonal up-right, down-right,
+  down-left, up-left (n defaults to 1)
+- M x,y: to point x,y; M +x,-y moves relative
+- B before a move: move without drawing
+- N before a move: draw, then return
+- C n: brightness 0-15 of the next strokes
+- The pen starts in the middle of the screen
+  and keeps its place between DRAWs until CLS
+
+Example:
+  DRAW "BM100,100 C15 R50 D50 L50 U50"`,
+
 	"RECT": `Draws a rectangle.
 - Requires top-left corner (x,y) and dimensions (width,height)
 
 Example:
   RECT 50, 50, 100, 80`,
 
+	"SCREEN": `Switches double buffering of the 2D graphics.
+- SCREEN DOUBLE: PLOT, LINE, RECT, CIRCLE, PAINT etc.
+  draw on a hidden page; the screen keeps the last frame
+- SCREEN SWAP: shows the hidden page (same as FLIP)
+- SCREEN SINGLE: draws directly on the screen again
+- The hidden page keeps its content after a flip, so
+  clear it with CLS before drawing the next frame
+- Sprites, vectors, images and particles are not buffered
+- When the program ends or is stopped, SINGLE mode
+  returns and the last shown frame stays visible
+
+Example:
+  10 SCREEN DOUBLE
+  20 FOR X = 0 TO 600 STEP 4
+  30 CLS: CIRCLE X, 240, 40: FLIP: SLEEP 20
+  40 NEXT X`,
+
+	"FLIP": `Shows the hidden graphics page in SCREEN DOUBLE mode.
+- The whole frame appears at once, without flicker
+- Without SCREEN DOUBLE, FLIP does nothing and prints
+  a warning once per run
+
+Example:
+  10 SCREEN DOUBLE
+  20 CLS: RECT 10, 10, 100, 50: FLIP`,
+
 	"EXIT": `Exits BASIC and returns to system.
 - Closes all open files
 
@@ -369,11 +685,14 @@ Examples:
 See HELP PRINT for full details.`,
 
 	"MCP": `Access the Master Control Program AI assistant.
-- CREATE: Generate a new BASIC program
+- CREATE: Generate a new BASIC program and save it to a file
+- EDITOR: Generate a new BASIC program and open it in the editor
+  for review; it is only saved when you save it there (^S)
 - EDIT: Modify an existing program file
 
 Examples:
   MCP CREATE a program that draws a sine wave
+  MCP EDITOR a program that draws a sine wave
   MCP EDIT sine.bas add a coordinate system`,
 
 	"DIM": `Declares array variables with specified dimensions.
@@ -386,11 +705,23 @@ Examples:
 
 	"LOCATE": `Positions the cursor at specified screen coordinates.
 - Uses text coordinates (1-based)
-- Screen is 80 columns by 24 rows
+- Screen is 80 columns by 24 rows (40 after WIDTH 40)
 
 Example:
   LOCATE 10, 5     ' Column 10, Row 5`,
 
+	"WIDTH": `Sets the number of text columns.
+- WIDTH 40: wide characters, 40 per line
+- WIDTH 80: normal characters, 80 per line
+- PRINT wraps and the comma zones end at the new width
+- Changing the width clears the screen
+- When the program ends or is stopped, the terminal
+  returns to its own width
+
+Example:
+  10 WIDTH 40
+  20 PRINT "BIG LETTERS"`,
+
 	"INVERSE": `Controls inverse text display mode.
 - ON: Text appears in reverse video
 - OFF: Normal text display
@@ -415,10 +746,18 @@ Example:
   NOISE 1000, 100, 500`,
 
 	"MUSIC": `Plays SID music files.
+- MUSIC OPEN "file.sid": load and start the default tune
+- MUSIC PAUSE / MUSIC RESUME: hold and continue
+- MUSIC TUNE n: switch to tune n (1 to the number of tunes)
+- MUSIC PLAY / MUSIC STOP: start again, stop
+- MUSIC SCOPE ON / OFF: send the play position to the
+  screen's music scope (off by default)
+- Controls without music only print a warning
 - Available files: ull.sid, sensory.sid, deep.sid
 
 Example:
-  MUSIC "ull.sid"`,
+  MUSIC OPEN "ull.sid"
+  MUSIC TUNE 2`,
 
 	"SPRITE": `Controls 32x32 pixel sprite graphics.
 - Define: SPRITE id, pixelData$
@@ -454,10 +793,27 @@ Examples:
 Example:
   VARS`,
 
+	"RANDOMIZE": `Seeds the random numbers returned by RND.
+- RANDOMIZE or RANDOMIZE TIMER: seed from the clock
+- RANDOMIZE seed: the same seed always gives the
+  same sequence of numbers
+
+Example:
+  RANDOMIZE 42
+  PRINT RND(0)     ' Same value on every run`,
 	"WAIT": `Pauses program execution for specified milliseconds.
 
 Example:
   WAIT 1000        ' Wait 1 second`,
+	"SLEEP": `Pauses for specified milliseconds (0-60000) like WAIT,
+but stays responsive:
+- Stopping the program (Ctrl+C) ends the pause at once
+- A key tapped during the pause is kept for the next INKEY$
+
+Example:
+  10 SLEEP 50: K$ = INKEY$
+  20 IF K$ = "" THEN GOTO 10
+  30 PRINT "Key: "; K$`,
 	"EOF": `Tests if file is at end-of-file.
 - Returns true if no more data to read
 - Used with file input operations
@@ -0,0 +1,227 @@
+package tinybasic
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// inputUsage is the usage hint for INPUT
+const inputUsage = `INPUT ["prompt";|,] [default;] var`
+
+// inputStatement is a parsed INPUT ["prompt";|,] [default;] var. As in
+// classic BASIC a ";" after the prompt adds "? ", a "," shows the prompt as
+// written. The default is used when the answer is empty.
+type inputStatement struct {
+	prompt      string
+	question    bool   // "? " follows the prompt
+	defaultExpr string // "" for no default
+	varName     string // uppercased
+}
+
+// parseInputStatement splits the arguments of INPUT. errCode is the BASIC
+// error code if args are not valid, empty otherwise.
+func parseInputStatement(args string) (st inputStatement, errCode string) {
+	rest := strings.TrimSpace(args)
+	st.question = true
+
+	if strings.HasPrefix(rest, `"`) {
+		end := strings.Index(rest[1:], `"`)
+		if end == -1 {
+			return st, "SYNTAX_ERROR"
+		}
+		st.prompt = rest[1 : end+1]
+		rest = strings.TrimSpace(rest[end+2:])
+		switch {
+		case rest == "":
+			return st, "EXPECTED_VARIABLE"
+		case rest[0] == ';':
+		case rest[0] == ',':
+			st.question = false
+		default:
+			return st, "UNEXPECTED_TOKEN"
+		}
+		rest = strings.TrimSpace(rest[1:])
+	}
+
+	if idx := lastSemicolonOutsideQuotes(rest); idx != -1 {
+		st.defaultExpr = strings.TrimSpace(rest[:idx])
+		rest = strings.TrimSpace(rest[idx+1:])
+		if st.defaultExpr == "" {
+			return st, "EXPECTED_EXPRESSION"
+		}
+	}
+
+	if rest == "" {
+		return st, "EXPECTED_VARIABLE"
+	}
+	// Only one variable per INPUT
+	if strings.Contains(rest, ",") {
+		return st, "UNEXPECTED_TOKEN"
+	}
+	st.varName = getCachedVarName(rest)
+	if !isValidVarName(st.varName) {
+		return st, "EXPECTED_VARIABLE"
+	}
+	return st, ""
+}
+
+// lastSemicolonOutsideQuotes returns the index of the last ';' that is not
+// part of a string literal, or -1
+func lastSemicolonOutsideQuotes(s string) int {
+	inQuote := false
+	last := -1
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '"':
+			inQuote = !inQuote
+		case s[i] == ';' && !inQuote:
+			last = i
+		}
+	}
+	return last
+}
+
+// text returns what INPUT shows before the answer. A default is shown in
+// brackets.
+func (st inputStatement) text(def *BASICValue) string {
+	text := st.prompt
+	if def != nil {
+		shown := def.StrValue
+		if def.IsNumeric {
+			shown = formatBasicFloat(def.NumValue)
+		}
+		if text != "" {
+			text += " "
+		}
+		text += "[" + shown + "]"
+	}
+	if st.question {
+		text += "? "
+	} else if def != nil {
+		text += " "
+	}
+	return text
+}
+
+// cmdInput implements INPUT. In a running program it waits for the answer
+// like SLEEP waits for its time; in direct mode ExecuteInputResponse assigns
+// the answer later. Assumes lock is held.
+func (b *TinyBASIC) cmdInput(args string) error {
+	st, errCode := parseInputStatement(args)
+	if errCode != "" {
+		return NewBASICError(ErrCategorySyntax, errCode, b.currentLine == 0, b.currentLine).
+			WithCommand("INPUT").WithUsageHint(inputUsage)
+	}
+	var def *BASICValue
+	if st.defaultExpr != "" {
+		val, err := b.evalExpression(st.defaultExpr)
+		if err != nil {
+			return err
+		}
+		def = &val
+	}
+	if err := b.beginInput(st, def); err != nil {
+		return err
+	}
+	if !b.running {
+		return nil
+	}
+
+	ctx := b.ctx
+	b.mu.Unlock()
+	value, ok := b.awaitInput(ctx)
+	b.mu.Lock()
+	if ok {
+		b.variables[st.varName] = value
+	}
+	// A cancelled INPUT just returns; the run loop sees the context and stops
+	return nil
+}
+
+// beginInput shows the prompt and marks the interpreter as waiting for the
+// answer to st. Assumes lock is held.
+func (b *TinyBASIC) beginInput(st inputStatement, def *BASICValue) error {
+	if def != nil && def.IsNumeric == strings.HasSuffix(st.varName, "$") {
+		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", b.currentLine == 0, b.currentLine).
+			WithCommand("INPUT").WithUsageHint("The default must have the type of the variable")
+	}
+	// An answer left over from a cancelled INPUT must not be taken
+	select {
+	case <-b.inputChan:
+	default:
+	}
+	b.inputVar = st.varName
+	b.inputDefault = def
+	b.sendInputControl("enable")
+	b.sendMessageWrapped(shared.MessageTypeText, st.text(def))
+	return nil
+}
+
+// awaitInput waits for the answer passed on by ExecuteInputResponse. ok is
+// false if ctx was cancelled first. Must be called without b.mu.
+func (b *TinyBASIC) awaitInput(ctx context.Context) (value BASICValue, ok bool) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	select {
+	case value = <-b.inputChan:
+		return value, true
+	case <-ctx.Done():
+		return BASICValue{}, false
+	}
+}
+
+// inputValue converts the answer to INPUT into a value for varName. An empty
+// answer gives the default, if there is one. ok is false for a numeric
+// variable and an answer that is not a number. Assumes lock is held.
+func (b *TinyBASIC) inputValue(varName, input string) (value BASICValue, ok bool) {
+	if strings.TrimSpace(input) == "" && b.inputDefault != nil {
+		return *b.inputDefault, true
+	}
+	if strings.HasSuffix(varName, "$") {
+		return BASICValue{StrValue: input, IsNumeric: false}, true
+	}
+	val, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+	if err != nil {
+		return BASICValue{}, false
+	}
+	return BASICValue{NumValue: val, IsNumeric: true}, true
+}
+
+// handleInput is the bytecode version of INPUT. Operand1 is the parsed
+// statement; with a default its value is on the stack.
+func (vm *BytecodeVM) handleInput(inst *Instruction) error {
+	st := inst.Operand1.(inputStatement)
+	var def *BASICValue
+	if st.defaultExpr != "" {
+		val, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		def = &val
+	}
+
+	b := vm.tinybasic
+	b.mu.Lock()
+	err := b.beginInput(st, def)
+	b.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("INPUT: the default must have the type of %s", st.varName)
+	}
+
+	value, ok := b.awaitInput(vm.ctx)
+	if !ok {
+		// Stay on INPUT with the stack as it was, so CONT asks again
+		if def != nil {
+			vm.stack.Push(*def)
+		}
+		return nil
+	}
+	vm.variables[st.varName] = value
+	vm.pc++
+	return nil
+}
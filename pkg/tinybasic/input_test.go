@@ -0,0 +1,113 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// answerInput waits until the program asks for input and answers it
+func answerInput(t *testing.T, b *TinyBASIC, answer string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !b.IsWaitingForInput() {
+		if time.Now().After(deadline) {
+			t.Fatal("program did not wait for INPUT")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	b.ExecuteInputResponse(answer)
+}
+
+// TestParseInputStatement checks prompt, question mark and default
+func TestParseInputStatement(t *testing.T) {
+	tests := []struct {
+		args string
+		want inputStatement
+	}{
+		{`A`, inputStatement{question: true, varName: "A"}},
+		{`"Name"; N$`, inputStatement{prompt: "Name", question: true, varName: "N$"}},
+		{`"Name: ", N$`, inputStatement{prompt: "Name: ", varName: "N$"}},
+		{`"Age", 18; a`, inputStatement{prompt: "Age", defaultExpr: "18", varName: "A"}},
+		{`"City"; "Berlin;Mitte"; C$`, inputStatement{prompt: "City", question: true, defaultExpr: `"Berlin;Mitte"`, varName: "C$"}},
+	}
+	for _, tt := range tests {
+		got, errCode := parseInputStatement(tt.args)
+		if errCode != "" || got != tt.want {
+			t.Errorf("parseInputStatement(%q) = %+v, %q, want %+v", tt.args, got, errCode, tt.want)
+		}
+	}
+	for _, args := range []string{``, `"Name"`, `"Name" N$`, `A, B`, `; A`} {
+		if _, errCode := parseInputStatement(args); errCode == "" {
+			t.Errorf("parseInputStatement(%q) accepted", args)
+		}
+	}
+}
+
+// TestInputPromptText checks what INPUT shows before the answer
+func TestInputPromptText(t *testing.T) {
+	def := newNumericBASICValue(18)
+	tests := []struct {
+		args string
+		def  *BASICValue
+		want string
+	}{
+		{`A`, nil, "? "},
+		{`"Name"; N$`, nil, "Name? "},
+		{`"Name: ", N$`, nil, "Name: "},
+		{`"Age"; 18; A`, &def, "Age [18]? "},
+		{`"Age", 18; A`, &def, "Age [18] "},
+	}
+	for _, tt := range tests {
+		st, _ := parseInputStatement(tt.args)
+		if got := st.text(tt.def); got != tt.want {
+			t.Errorf("INPUT %s shows %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+// TestInputEmptyAnswerUsesDefault answers INPUT with just Enter, which the
+// terminal passes on as a single space, and expects the default
+func TestInputEmptyAnswerUsesDefault(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startSleepProgram(b, bytecode,
+			`10 INPUT "Age", 18; A`,
+			`20 INPUT "Name"; "Joe"; N$`,
+			`30 PRINT "RESULT "; A * 2; " "; N$`,
+		)
+		answerInput(t, b, " ")
+		answerInput(t, b, "")
+		waitUntilStopped(t, b)
+
+		out := textOutput(b)
+		if !strings.Contains(out, "Age [18] ") || !strings.Contains(out, "Name [Joe]? ") {
+			t.Errorf("bytecode=%v: prompts missing in %q", bytecode, out)
+		}
+		if !strings.Contains(strings.ReplaceAll(out, "\n", ""), "RESULT 36 Joe") {
+			t.Errorf("bytecode=%v: default not used, output %q", bytecode, out)
+		}
+	}
+}
+
+// TestInputAnswerOverridesDefault expects a typed answer to win over the
+// default and a non-number to be asked again
+func TestInputAnswerOverridesDefault(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startSleepProgram(b, bytecode,
+			`10 INPUT "Age"; 18; A: PRINT "RESULT"; A`,
+		)
+		answerInput(t, b, "old")
+		answerInput(t, b, "42")
+		waitUntilStopped(t, b)
+
+		out := textOutput(b)
+		if !strings.Contains(out, "?REDO FROM START") {
+			t.Errorf("bytecode=%v: non-number accepted, output %q", bytecode, out)
+		}
+		if !strings.Contains(strings.ReplaceAll(out, "\n", ""), "RESULT42") {
+			t.Errorf("bytecode=%v: answer not used, output %q", bytecode, out)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/antibyte/retroterm/pkg/logger"
 	"github.com/antibyte/retroterm/pkg/shared"
@@ -46,6 +47,17 @@ func (b *TinyBASIC) cmdPrint(args string) error {
 	// Debug-Log für PRINT-Befehl
 	logger.Debug(logger.AreaTinyBasic, "[PRINT] cmdPrint called with args: '%s'", args)
 
+	// PRINT AT(x,y) setzt den Cursor wie LOCATE, bevor die Elemente ausgegeben werden
+	if coords, rest, ok := splitPrintAt(args); ok {
+		if err := b.locateCursor("PRINT AT", coords); err != nil {
+			return err
+		}
+		if rest == "" {
+			return nil
+		}
+		args = rest
+	}
+
 	// PRINT ohne Argumente gibt nur eine leere Zeile aus
 	if strings.TrimSpace(args) == "" {
 		if b.printCursorOnSameLine {
@@ -54,11 +66,13 @@ func (b *TinyBASIC) cmdPrint(args string) error {
 			b.sendTextToClient("", false) // Send newline for empty PRINT
 		}
 		b.printCursorOnSameLine = false // Reset cursor state
+		b.cursorX = 0
 		return nil
 	}
 	// Prüfe ob der gesamte PRINT-Befehl mit einem Trennzeichen endet
 	args = strings.TrimSpace(args)
 	endsWithSeparator := false
+	endsWithComma := false
 
 	if strings.HasSuffix(args, ";") {
 		endsWithSeparator = true
@@ -66,6 +80,7 @@ func (b *TinyBASIC) cmdPrint(args string) error {
 		args = strings.TrimSpace(args)
 	} else if strings.HasSuffix(args, ",") {
 		endsWithSeparator = true
+		endsWithComma = true
 		args = args[:len(args)-1]
 		args = strings.TrimSpace(args)
 	}
@@ -144,23 +159,18 @@ func (b *TinyBASIC) cmdPrint(args string) error {
 	}
 	// Baue die Ausgabe basierend auf den Trennzeichen auf (reuse the pooled string builder)
 	sb.Reset() // Clear any previous content
-	for _, item := range items {
+	column := b.cursorX
+	for i, item := range items {
 		sb.WriteString(item.Value)
+		column = b.advancePrintColumn(column, item.Value)
 
-		// Füge entsprechende Abstände hinzu
-		if item.Separator == ',' {
-			// Komma: Tab-Abstand (14 Zeichen in klassischem BASIC)
-			currentLen := len(item.Value)
-			tabSize := 14
-			spacesToAdd := tabSize - (currentLen % tabSize)
-			if spacesToAdd == 0 {
-				spacesToAdd = tabSize
-			}
-			sb.WriteString(strings.Repeat(" ", spacesToAdd))
-		} else if item.Separator == ';' {
-			// Semikolon: kein zusätzlicher Abstand
+		// Komma: zur nächsten Druckzone springen, auch nach dem letzten Element bei abschließendem Komma
+		if item.Separator == ',' || (endsWithComma && i == len(items)-1) {
+			padding := b.printZonePadding(column)
+			sb.WriteString(padding)
+			column = b.advancePrintColumn(column, padding)
 		}
-		// Kein Separator (Ende): kein zusätzlicher Abstand
+		// Semikolon / Ende: kein zusätzlicher Abstand
 	}
 	outputText := sb.String()
 	logger.Debug(logger.AreaTinyBasic, "[PRINT] Final output text: '%s' (length: %d)", outputText, len(outputText))
@@ -180,49 +190,76 @@ func (b *TinyBASIC) cmdPrint(args string) error {
 
 	// Set cursor state for next PRINT statement
 	b.printCursorOnSameLine = endsWithSeparator
+	if endsWithSeparator {
+		b.cursorX = column
+	} else {
+		b.cursorX = 0
+	}
 
 	return nil
 }
 
-// cmdInput handles console input. Assumes lock is held.
-func (b *TinyBASIC) cmdInput(args string) error {
-	// Syntax: INPUT [prompt_string;] var1 [, var2...]
-	prompt := "? "
-	varListStr := strings.TrimSpace(args)
-
-	// Check for optional prompt string.
-	if strings.HasPrefix(varListStr, "\"") {
-		endQuote := strings.Index(varListStr[1:], "\"")
-		if endQuote != -1 {
-			sep := endQuote + 2 // Position after closing quote.
-			if sep < len(varListStr) && varListStr[sep] == ';' {
-				prompt = varListStr[1 : endQuote+1]                // Extract prompt.
-				varListStr = strings.TrimSpace(varListStr[sep+1:]) // Get variables after ';'.
+// splitPrintAt trennt eine AT(x,y)-Angabe am Anfang der PRINT-Argumente von den
+// folgenden Elementen. Liefert die Koordinaten ohne Klammern und den Rest ohne
+// das optionale Trennzeichen nach der Klammer.
+func splitPrintAt(args string) (string, string, bool) {
+	args = strings.TrimSpace(args)
+	if len(args) < 2 || !strings.EqualFold(args[:2], "AT") {
+		return "", "", false
+	}
+	clause := strings.TrimLeft(args[2:], " \t")
+	if !strings.HasPrefix(clause, "(") {
+		return "", "", false // z.B. ATN(X) oder eine Variable ATX
+	}
+
+	depth := 0
+	inString := false
+	for i, ch := range clause {
+		switch {
+		case ch == '"':
+			inString = !inString
+		case inString:
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+			if depth == 0 {
+				rest := strings.TrimSpace(clause[i+1:])
+				if strings.HasPrefix(rest, ";") || strings.HasPrefix(rest, ",") {
+					rest = strings.TrimSpace(rest[1:])
+				}
+				return clause[1:i], rest, true
 			}
-			// If no semicolon, the whole thing might be just a prompt? No, standard needs var.
 		}
 	}
+	return "", "", false
+}
 
-	if varListStr == "" {
-		return NewBASICError(ErrCategorySyntax, "EXPECTED_VARIABLE", b.currentLine == 0, b.currentLine).WithCommand("INPUT")
+// printZonePadding liefert die Leerzeichen bis zum Beginn der nächsten PRINT-Zone.
+// Passt die nächste Zone nicht mehr in die Zeile, geht es am Anfang der nächsten Zeile weiter.
+func (b *TinyBASIC) printZonePadding(column int) string {
+	zone := b.printZoneWidth
+	if zone < 1 {
+		zone = DefaultPrintZoneWidth
+	}
+	spaces := zone - column%zone
+	if b.termCols > 0 && column+spaces >= b.termCols {
+		return "\n"
 	}
+	return strings.Repeat(" ", spaces)
+}
 
-	// For simplicity, handle only one variable per INPUT for now.
-	// TODO: Extend INPUT to handle multiple comma-separated variables if needed.
-	varName := getCachedVarName(strings.TrimSpace(varListStr))
-	if strings.Contains(varName, ",") {
-		return NewBASICError(ErrCategorySyntax, "UNEXPECTED_TOKEN", b.currentLine == 0, b.currentLine).WithCommand("INPUT")
+// advancePrintColumn berechnet die Cursor-Spalte, nachdem text ab column ausgegeben wurde
+func (b *TinyBASIC) advancePrintColumn(column int, text string) int {
+	if idx := strings.LastIndex(text, "\n"); idx >= 0 {
+		column = 0
+		text = text[idx+1:]
 	}
-	if !isValidVarName(varName) {
-		return NewBASICError(ErrCategorySyntax, "EXPECTED_VARIABLE", b.currentLine == 0, b.currentLine).WithCommand("INPUT")
+	column += utf8.RuneCountInString(text)
+	if b.termCols > 0 {
+		column %= b.termCols // Automatischer Zeilenumbruch am Terminalrand
 	}
-
-	b.inputVar = varName                                 // Set flag indicating interpreter is waiting.
-	b.sendInputControl("disable")                        // Signal frontend to disable normal input handling.
-	b.sendMessageWrapped(shared.MessageTypeText, prompt) // Send prompt.
-
-	// Execution pauses here; runProgramInternal returns because inputVar is set.
-	return nil
+	return column
 }
 
 // sendTextToClient ersetzt durch eine sicherere Version, die mit verschiedenen Typen umgehen kann
@@ -136,8 +136,8 @@ func (jla *JITLoopAnalyzer) shouldJITCompile(info *LoopInfo) bool {
 // JITForLoopExecution integrates JIT compilation into FOR loop execution
 func (b *TinyBASIC) JITForLoopExecution(forLoop *ForLoopInfo) (bool, error) {
 	// Check if JIT is enabled
-	if b.jitCompiler == nil || !b.jitCompiler.enabled {
-		return false, nil // JIT disabled, use interpreter
+	if b.jitCompiler == nil || !b.jitCompiler.enabled || b.angleDegrees {
+		return false, nil // JIT disabled (or generated code would assume radians), use interpreter
 	}
 	
 	// Generate signature for this loop
@@ -0,0 +1,100 @@
+package tinybasic
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// justifyField places value in a field as wide as field, like LSET (left)
+// and RSET (right) do: a shorter value is padded with spaces, a longer one
+// loses characters on the right. The result always has the width of field.
+func justifyField(field, value string, right bool) string {
+	width := utf8.RuneCountInString(field)
+	runes := []rune(value)
+	if len(runes) >= width {
+		return string(runes[:width])
+	}
+	padding := strings.Repeat(" ", width-len(runes))
+	if right {
+		return padding + value
+	}
+	return value + padding
+}
+
+// repeatString builds the result of REPEAT$: s repeated n times
+func repeatString(s string, n int) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("count must not be negative, got %d", n)
+	}
+	if n > 0 && utf8.RuneCountInString(s) > MaxStringLength/n {
+		return "", fmt.Errorf("result longer than %d characters", MaxStringLength)
+	}
+	return strings.Repeat(s, n), nil
+}
+
+// splitJustify splits the "var = value" part of LSET and RSET
+func splitJustify(args string) (string, string, bool) {
+	eqIdx := strings.Index(args, "=")
+	if eqIdx == -1 {
+		return "", "", false
+	}
+	return strings.TrimSpace(args[:eqIdx]), strings.TrimSpace(args[eqIdx+1:]), true
+}
+
+// cmdJustify executes LSET var = value and RSET var = value. Assumes lock is held.
+func (b *TinyBASIC) cmdJustify(command, args string) error {
+	isDirect := b.currentLine == 0
+	target, expr, ok := splitJustify(args)
+	if !ok || target == "" {
+		return NewBASICError(ErrCategorySyntax, "EXPECTED_EQUALS", isDirect, b.currentLine).
+			WithCommand(command).WithUsageHint(command + " A$ = value")
+	}
+	if expr == "" {
+		return NewBASICError(ErrCategorySyntax, "EXPECTED_EXPRESSION", isDirect, b.currentLine).WithCommand(command)
+	}
+
+	baseName, indices, err := b.parseVariableWithIndex(target)
+	if err != nil {
+		return WrapError(err, command, isDirect, b.currentLine)
+	}
+	baseName = getCachedVarName(baseName)
+	if !isValidVarName(baseName) || !strings.HasSuffix(baseName, "$") {
+		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", isDirect, b.currentLine).WithCommand(command)
+	}
+	key := baseName
+	if len(indices) > 0 {
+		if key, err = b.arrayElement(baseName, indices); err != nil {
+			return err
+		}
+	}
+
+	value, err := b.evalExpression(expr)
+	if err != nil {
+		return WrapError(err, command, isDirect, b.currentLine)
+	}
+	if value.IsNumeric {
+		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", isDirect, b.currentLine).WithCommand(command)
+	}
+
+	b.variables[key] = BASICValue{StrValue: justifyField(b.variables[key].StrValue, value.StrValue, command == "RSET"), IsNumeric: false}
+	return nil
+}
+
+// handleJustify executes OP_JUSTIFY: pops the value and fits it into the
+// variable named by Operand1, right-justified if Operand2 is true
+func (vm *BytecodeVM) handleJustify(inst *Instruction) error {
+	varName := strings.ToUpper(inst.Operand1.(string))
+	right, _ := inst.Operand2.(bool)
+
+	value, err := vm.stack.Pop()
+	if err != nil {
+		return err
+	}
+	if value.IsNumeric {
+		return fmt.Errorf("LSET and RSET need a string value")
+	}
+	vm.variables[varName] = BASICValue{StrValue: InternString(justifyField(vm.variables[varName].StrValue, value.StrValue, right)), IsNumeric: false}
+	vm.pc++
+	return nil
+}
@@ -0,0 +1,87 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJustifyField(t *testing.T) {
+	tests := []struct {
+		field, value string
+		right        bool
+		want         string
+	}{
+		{"12345", "AB", false, "AB   "},
+		{"12345", "AB", true, "   AB"},
+		{"12345", "TOOLONG", false, "TOOLO"},
+		{"12345", "TOOLONG", true, "TOOLO"},
+		{"123", "ABC", true, "ABC"},
+		{"", "AB", false, ""},
+		{"ÄÖÜ", "ß", true, "  ß"},
+	}
+	for _, tt := range tests {
+		if got := justifyField(tt.field, tt.value, tt.right); got != tt.want {
+			t.Errorf("justifyField(%q, %q, %v) = %q, want %q", tt.field, tt.value, tt.right, got, tt.want)
+		}
+	}
+}
+
+func TestRepeatString(t *testing.T) {
+	if got, err := repeatString("-=", 3); err != nil || got != "-=-=-=" {
+		t.Errorf("repeatString(\"-=\", 3) = %q, %v", got, err)
+	}
+	if got, err := repeatString("AB", 0); err != nil || got != "" {
+		t.Errorf("repeatString(\"AB\", 0) = %q, %v", got, err)
+	}
+	if _, err := repeatString("AB", -1); err == nil {
+		t.Error("expected an error for a negative count")
+	}
+	if _, err := repeatString("AB", MaxStringLength); err == nil {
+		t.Error("expected an error for a result over MaxStringLength")
+	}
+}
+
+// TestJustifyStatements runs LSET, RSET and REPEAT$ in the interpreter and
+// the bytecode VM, including an array element and a truncated value
+func TestJustifyStatements(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode,
+			`10 LET A$ = "12345"`,
+			`20 LSET A$ = "AB"`,
+			`30 PRINT "<"; A$; ">"`,
+			`40 RSET A$ = "AB"`,
+			`50 PRINT "<"; A$; ">"`,
+			`60 LSET A$ = "TOOLONG"`,
+			`70 PRINT "<"; A$; ">"`,
+			`80 DIM B$(2)`,
+			`90 LET B$(1) = "----"`,
+			`100 RSET B$(1) = "X"`,
+			`110 PRINT "<"; B$(1); ">"`,
+			`120 PRINT REPEAT$("-=", 3)`,
+		)
+		for _, want := range []string{"<AB   >", "<   AB>", "<TOOLO>", "<   X>", "-=-=-="} {
+			if !strings.Contains(out, want) {
+				t.Errorf("bytecode=%v: missing %q in output %q", bytecode, want, out)
+			}
+		}
+	}
+}
+
+// TestJustifyRejectsNumbers checks that LSET needs a string variable and value
+func TestJustifyRejectsNumbers(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		for _, stmt := range []string{`LSET A = "X"`, `RSET A$ = 5`, `LSET A$ "X"`} {
+			out := runTrapProgram(t, bytecode,
+				`10 ON ERROR GOTO 100`,
+				`20 LET A$ = "ABC"`,
+				`30 `+stmt,
+				`40 PRINT "NO ERROR"`,
+				`50 END`,
+				`100 PRINT "TRAPPED"`,
+			)
+			if !strings.Contains(out, "TRAPPED") || strings.Contains(out, "NO ERROR") {
+				t.Errorf("bytecode=%v %s: output %q", bytecode, stmt, out)
+			}
+		}
+	}
+}
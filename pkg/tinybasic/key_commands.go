@@ -0,0 +1,337 @@
+package tinybasic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// numFunctionKeys is the number of function keys KEY and ON KEY know, F1 to F10
+const numFunctionKeys = 10
+
+// maxKeyMacroLength limits the text of one KEY definition
+const maxKeyMacroLength = 255
+
+// maxTypedKeys limits the macro text waiting for INKEY$, so a held function
+// key cannot make the buffer grow without end
+const maxTypedKeys = 1024
+
+// functionKeyCodes are the INKEY$ codes of F1 to F10
+var functionKeyCodes = [numFunctionKeys]string{KeyF1, KeyF2, KeyF3, KeyF4, KeyF5, KeyF6, KeyF7, KeyF8, KeyF9, KeyF10}
+
+// isKeyConstant reports whether name is one of the key constants like
+// KEYLEFT or KEYF1, strings whose names have no $ suffix
+func isKeyConstant(name string) bool {
+	return strings.HasPrefix(name, "KEY")
+}
+
+// functionKeys holds the KEY macros and ON KEY traps of a program. The
+// interpreter and the bytecode VM share it. It has its own lock because key
+// presses arrive from the connection while the VM runs without b.mu.
+type functionKeys struct {
+	mu           sync.Mutex
+	macros       [numFunctionKeys]string
+	traps        [numFunctionKeys]int  // ON KEY(n) GOSUB line, 0 = no trap
+	pressed      [numFunctionKeys]bool // Trapped key was pressed, handler not started yet
+	typed        []rune                // Macro text INKEY$ has not returned yet
+	handlerDepth int                   // GOSUB depth inside the running key handler, 0 = none
+	pending      atomic.Bool           // A trapped key was pressed; checked before each line
+}
+
+// functionKeyIndex returns the index of the function key with the INKEY$
+// code key, -1 if it is no function key
+func functionKeyIndex(key string) int {
+	for i, code := range functionKeyCodes {
+		if key == code {
+			return i
+		}
+	}
+	return -1
+}
+
+// reset removes all macros and traps and drops macro text not read yet
+func (k *functionKeys) reset() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.macros = [numFunctionKeys]string{}
+	k.typed = nil
+	k.resetTrapsWithoutLock()
+}
+
+// resetTraps removes the ON KEY traps, which name lines of the program
+func (k *functionKeys) resetTraps() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.resetTrapsWithoutLock()
+}
+
+func (k *functionKeys) resetTrapsWithoutLock() {
+	k.traps = [numFunctionKeys]int{}
+	k.pressed = [numFunctionKeys]bool{}
+	k.handlerDepth = 0
+	k.pending.Store(false)
+}
+
+// define sets the macro of function key n (1-10), "" removes it
+func (k *functionKeys) define(n int, text string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.macros[n-1] = text
+}
+
+// setTrap installs the ON KEY(n) handler at line, 0 removes it
+func (k *functionKeys) setTrap(n, line int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.traps[n-1] = line
+	if line == 0 {
+		k.pressed[n-1] = false
+	}
+}
+
+// press handles a key press. A function key with a trap is remembered for
+// the run loop, one with a macro types the macro text. Returns true if the
+// key was used up this way and must not be reported by INKEY$ itself.
+func (k *functionKeys) press(key string) bool {
+	i := functionKeyIndex(key)
+	if i < 0 {
+		return false
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.traps[i] != 0 {
+		k.pressed[i] = true
+		k.pending.Store(true)
+		return true
+	}
+	if k.macros[i] == "" {
+		return false
+	}
+	macro := []rune(k.macros[i])
+	if len(k.typed)+len(macro) <= maxTypedKeys {
+		k.typed = append(k.typed, macro...)
+	}
+	return true
+}
+
+// nextTyped returns the next character of the typed macro text
+func (k *functionKeys) nextTyped() (string, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.typed) == 0 {
+		return "", false
+	}
+	r := k.typed[0]
+	k.typed = k.typed[1:]
+	return string(r), true
+}
+
+// takeTrap returns the handler line for a trapped key press. gosubDepth is
+// the current GOSUB depth; while a key handler runs, that is until it
+// returns below the depth it was started at, further presses wait.
+func (k *functionKeys) takeTrap(gosubDepth int) (int, bool) {
+	if !k.pending.Load() {
+		return 0, false
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.handlerDepth > 0 && gosubDepth >= k.handlerDepth {
+		return 0, false
+	}
+	line := 0
+	waiting := false
+	for i, pressed := range k.pressed {
+		switch {
+		case pressed && line == 0:
+			k.pressed[i] = false
+			line = k.traps[i]
+		case pressed:
+			waiting = true
+		}
+	}
+	k.pending.Store(waiting)
+	if line == 0 {
+		k.handlerDepth = 0
+		return 0, false
+	}
+	k.handlerDepth = gosubDepth + 1
+	return line, true
+}
+
+// keyNumber checks the key number of KEY and ON KEY
+func keyNumber(value BASICValue) (int, bool) {
+	if !value.IsNumeric || value.NumValue != float64(int(value.NumValue)) {
+		return 0, false
+	}
+	n := int(value.NumValue)
+	return n, n >= 1 && n <= numFunctionKeys
+}
+
+// isOnKey reports whether the arguments of ON start an ON KEY statement
+func isOnKey(args string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(args)), "KEY")
+}
+
+// parseOnKeyGosub parses "KEY(n) GOSUB line" (the arguments of ON) and
+// returns the key number and the handler line, 0 to remove the handler
+func parseOnKeyGosub(args string) (int, int, bool) {
+	rest, ok := strings.CutPrefix(strings.ToUpper(strings.TrimSpace(args)), "KEY")
+	if !ok {
+		return 0, 0, false
+	}
+	rest, ok = strings.CutPrefix(strings.TrimSpace(rest), "(")
+	if !ok {
+		return 0, 0, false
+	}
+	number, rest, ok := strings.Cut(rest, ")")
+	if !ok {
+		return 0, 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(number))
+	if err != nil || n < 1 || n > numFunctionKeys {
+		return 0, 0, false
+	}
+	fields := strings.Fields(rest)
+	if len(fields) != 2 || fields[0] != "GOSUB" {
+		return 0, 0, false
+	}
+	line, err := strconv.Atoi(fields[1])
+	if err != nil || line < 0 {
+		return 0, 0, false
+	}
+	return n, line, true
+}
+
+// cmdKey implements KEY n, text$: pressing Fn while the program runs types
+// text$ for INKEY$. Assumes lock is held.
+func (b *TinyBASIC) cmdKey(args string) error {
+	params := splitRespectingParentheses(strings.TrimSpace(args))
+	if len(params) != 2 {
+		return NewBASICError(ErrCategorySyntax, "INVALID_PARAMETER_COUNT", b.currentLine == 0, b.currentLine).
+			WithCommand("KEY").WithUsageHint("KEY n, text$ (n = 1-10)")
+	}
+	numberVal, err := b.evalExpression(strings.TrimSpace(params[0]))
+	if err != nil {
+		return err
+	}
+	textVal, err := b.evalExpression(strings.TrimSpace(params[1]))
+	if err != nil {
+		return err
+	}
+	return b.defineKey(numberVal, textVal, b.currentLine)
+}
+
+// defineKey checks and stores a KEY definition for the interpreter and the VM
+func (b *TinyBASIC) defineKey(numberVal, textVal BASICValue, line int) error {
+	n, ok := keyNumber(numberVal)
+	if !ok {
+		return NewBASICError(ErrCategoryEvaluation, "OUT_OF_RANGE", line == 0, line).
+			WithCommand("KEY").WithUsageHint("Key number must be 1 to 10 (F1-F10)")
+	}
+	if textVal.IsNumeric {
+		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", line == 0, line).
+			WithCommand("KEY").WithUsageHint("KEY n, text$ (n = 1-10)")
+	}
+	if len([]rune(textVal.StrValue)) > maxKeyMacroLength {
+		return NewBASICError(ErrCategoryCommand, "TEXT_TOO_LONG", line == 0, line).
+			WithCommand("KEY").WithUsageHint(fmt.Sprintf("A key text can have up to %d characters", maxKeyMacroLength))
+	}
+	b.fnKeys.define(n, textVal.StrValue)
+	return nil
+}
+
+// cmdOnKey handles ON KEY(n) GOSUB line. args is the text after ON. Assumes
+// lock is held.
+func (b *TinyBASIC) cmdOnKey(args string) error {
+	n, line, ok := parseOnKeyGosub(args)
+	if !ok {
+		return NewBASICError(ErrCategorySyntax, "UNEXPECTED_TOKEN", b.currentLine == 0, b.currentLine).
+			WithCommand("ON").WithUsageHint("ON KEY(n) GOSUB lineNumber (n = 1-10)")
+	}
+	if line != 0 {
+		if _, exists := b.program[line]; !exists {
+			return NewBASICError(ErrCategoryExecution, "LINE_NOT_FOUND", b.currentLine == 0, b.currentLine).WithCommand("ON")
+		}
+	}
+	b.fnKeys.setTrap(n, line)
+	return nil
+}
+
+// startKeyTrap calls the ON KEY handler of a pressed function key like a
+// GOSUB before the next line runs. Assumes lock is held.
+func (b *TinyBASIC) startKeyTrap() {
+	if !b.fnKeys.pending.Load() || b.currentLine == 0 || b.resumeSubStatementIndex != 0 ||
+		len(b.gosubStack) >= MaxGosubDepth {
+		return
+	}
+	line, ok := b.fnKeys.takeTrap(len(b.gosubStack))
+	if !ok {
+		return
+	}
+	if _, exists := b.program[line]; !exists {
+		return
+	}
+	b.gosubStack = append(b.gosubStack, b.currentLine)
+	b.currentLine = line
+}
+
+// handleKey is the bytecode version of KEY n, text$
+func (vm *BytecodeVM) handleKey(inst *Instruction) error {
+	textVal, err := vm.stack.Pop()
+	if err != nil {
+		return err
+	}
+	numberVal, err := vm.stack.Pop()
+	if err != nil {
+		return err
+	}
+	if vm.tinybasic != nil {
+		if err := vm.tinybasic.defineKey(numberVal, textVal, inst.LineNum); err != nil {
+			return err
+		}
+	}
+	vm.pc++
+	return nil
+}
+
+// handleOnKey installs or removes an ON KEY handler. Operand1 is the key
+// number, Operand2 the handler line.
+func (vm *BytecodeVM) handleOnKey(inst *Instruction) error {
+	line := inst.Operand2.(int)
+	if line != 0 {
+		if _, exists := vm.program.Labels[line]; !exists {
+			return fmt.Errorf("undefined line number %d", line)
+		}
+	}
+	if vm.tinybasic != nil {
+		vm.tinybasic.fnKeys.setTrap(inst.Operand1.(int), line)
+	}
+	vm.pc++
+	return nil
+}
+
+// startKeyTrap is the bytecode version of the ON KEY call: at the first
+// instruction of a line the handler is called like a GOSUB
+func (vm *BytecodeVM) startKeyTrap() {
+	b := vm.tinybasic
+	if b == nil || !b.fnKeys.pending.Load() || len(vm.callStack) >= MaxGosubDepth {
+		return
+	}
+	instructions := vm.program.Instructions
+	if vm.pc >= len(instructions) || instructions[vm.pc].LineNum == 0 ||
+		(vm.pc > 0 && instructions[vm.pc-1].LineNum == instructions[vm.pc].LineNum) {
+		return
+	}
+	line, ok := b.fnKeys.takeTrap(len(vm.callStack))
+	if !ok {
+		return
+	}
+	addr, exists := vm.program.Labels[line]
+	if !exists {
+		return
+	}
+	vm.callStack = append(vm.callStack, vm.pc)
+	vm.pc = addr
+}
@@ -0,0 +1,154 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// startKeyProgram starts a program like startSleepProgram and fails the test
+// if the bytecode run would fall back to the interpreter
+func startKeyProgram(t *testing.T, b *TinyBASIC, bytecode bool, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		b.Execute(line)
+	}
+	if bytecode {
+		b.mu.Lock()
+		err := b.compileProgramIfNeeded()
+		b.mu.Unlock()
+		if err != nil {
+			t.Fatalf("program does not compile to bytecode: %v", err)
+		}
+	}
+	startSleepProgram(b, bytecode)
+}
+
+// TestKeyMacroExpandsOnInkey defines a KEY macro and expects INKEY$ to
+// return its text one character per call when the function key is pressed
+func TestKeyMacroExpandsOnInkey(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startKeyProgram(t, b, bytecode,
+			`10 KEY 3, "AB"`,
+			`20 LET K$ = INKEY$`,
+			`30 IF K$ = "" THEN GOTO 20`,
+			`40 LET L$ = INKEY$`,
+			`50 LET M$ = INKEY$`,
+			`60 LET R$ = "GOT " + K$ + L$`,
+			`70 IF M$ = "" THEN PRINT R$`,
+		)
+		time.Sleep(100 * time.Millisecond)
+		b.SetKeyPressed(KeyF3)
+		waitUntilStopped(t, b)
+		b.SetKeyReleased(KeyF3)
+
+		if out := textOutput(b); !strings.Contains(out, "GOT AB") {
+			t.Errorf("bytecode=%v: output %q does not contain %q", bytecode, out, "GOT AB")
+		}
+	}
+}
+
+// TestKeyWithoutMacro expects an undefined function key to reach INKEY$ as
+// its key code, which KEYF1 to KEYF10 name
+func TestKeyWithoutMacro(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startKeyProgram(t, b, bytecode,
+			`10 KEY 1, "X"`,
+			`20 KEY 1, ""`,
+			`30 LET K$ = INKEY$`,
+			`40 IF K$ = "" THEN GOTO 30`,
+			`50 IF K$ = KEYF1 THEN PRINT "F1"`,
+		)
+		time.Sleep(100 * time.Millisecond)
+		b.SetKeyPressed(KeyF1)
+		waitUntilStopped(t, b)
+		b.SetKeyReleased(KeyF1)
+
+		if out := textOutput(b); !strings.Contains(out, "F1") {
+			t.Errorf("bytecode=%v: output %q does not contain F1", bytecode, out)
+		}
+	}
+}
+
+// TestOnKeyGosub presses a trapped function key and expects the handler to
+// run once like a GOSUB and the program to go on where it was
+func TestOnKeyGosub(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startKeyProgram(t, b, bytecode,
+			`10 LET H = 0`,
+			`15 LET N = 0`,
+			`20 ON KEY(2) GOSUB 100`,
+			`25 LET N = N + 1`,
+			`30 IF H = 0 THEN GOTO 25`,
+			`40 IF INKEY$ = "" THEN PRINT "NO KEY"`,
+			`50 IF H = 1 THEN PRINT "BACK"`,
+			`60 END`,
+			`100 LET H = H + 1`,
+			`110 PRINT "TRAPPED"`,
+			`120 RETURN`,
+		)
+		time.Sleep(100 * time.Millisecond)
+		b.SetKeyPressed(KeyF2)
+		waitUntilStopped(t, b)
+		b.SetKeyReleased(KeyF2)
+
+		out := textOutput(b)
+		for _, want := range []string{"TRAPPED", "NO KEY", "BACK"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("bytecode=%v: output %q does not contain %q", bytecode, out, want)
+			}
+		}
+		if strings.Count(out, "TRAPPED") != 1 {
+			t.Errorf("bytecode=%v: handler ran %d times, want 1", bytecode, strings.Count(out, "TRAPPED"))
+		}
+	}
+}
+
+// TestKeyErrors checks that KEY, ON KEY and COLOR report errors instead of
+// being ignored
+func TestKeyErrors(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		for _, program := range [][]string{
+			{`10 KEY 11, "X"`, `20 PRINT "AFTER"`},
+			{`10 KEY 1, 5`, `20 PRINT "AFTER"`},
+			{`10 ON KEY(1) GOSUB 500`, `20 PRINT "AFTER"`},
+			{`10 COLOR 3`, `20 PRINT "AFTER"`},
+		} {
+			out := runTrapProgram(t, bytecode, program...)
+			if strings.Contains(out, "AFTER") || !strings.Contains(out, "ERROR") {
+				t.Errorf("bytecode=%v: %s: got %q, want an error", bytecode, program[0], out)
+			}
+		}
+	}
+}
+
+// TestTextGfxInVM runs TEXTGFX with a string literal and a variable in the
+// bytecode VM, which used to evaluate the arguments as one string
+func TestTextGfxInVM(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startKeyProgram(t, b, bytecode,
+			`10 LET X = 40`,
+			`20 TEXTGFX X, 20, "HI", 15, 2`,
+		)
+		waitUntilStopped(t, b)
+
+		var found *shared.Message
+		for len(b.OutputChan) > 0 {
+			if msg := <-b.OutputChan; msg.Command == "TEXTGFX" {
+				found = &msg
+			}
+		}
+		if found == nil {
+			t.Fatalf("bytecode=%v: no TEXTGFX message sent", bytecode)
+		}
+		if found.Params["x"] != 40 || found.Params["text"] != "HI" || found.Params["size"] != 2 {
+			t.Errorf("bytecode=%v: unexpected TEXTGFX params %v", bytecode, found.Params)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseListRange checks the accepted LIST argument forms
+func TestParseListRange(t *testing.T) {
+	tests := []struct {
+		args       string
+		start, end int
+	}{
+		{"100", 100, 100},
+		{"100-200", 100, 200},
+		{"100-", 100, 2147483647},
+		{"-200", 0, 200},
+		{"200-100", 100, 200},
+		{" 100 , 200 ", 100, 200},
+	}
+	for _, tt := range tests {
+		start, end, err := parseListRange(tt.args)
+		if err != nil {
+			t.Errorf("parseListRange(%q) failed: %v", tt.args, err)
+			continue
+		}
+		if start != tt.start || end != tt.end {
+			t.Errorf("parseListRange(%q) = %d, %d, want %d, %d", tt.args, start, end, tt.start, tt.end)
+		}
+	}
+	if _, _, err := parseListRange("ABC"); err == nil {
+		t.Error("parseListRange(\"ABC\") should fail")
+	}
+}
+
+// TestFormatListLine checks that only code outside strings and REM is uppercased
+func TestFormatListLine(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{`print "Hello": a = 1`, `PRINT "Hello": A = 1`},
+		{`rem Keep this`, `REM Keep this`},
+		{`x = 1: rem Mixed "case"`, `X = 1: REM Mixed "case"`},
+		{`print "rem": remark = 2`, `PRINT "rem": REMARK = 2`},
+	}
+	for _, tt := range tests {
+		if got := formatListLine(tt.in); got != tt.want {
+			t.Errorf("formatListLine(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestListRanges runs LIST with ranges in direct mode
+func TestListRanges(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	for _, line := range []string{"10 PRINT 1", "20 PRINT 2", "30 PRINT 3", "40 PRINT 4"} {
+		b.Execute(line)
+	}
+	tests := []struct {
+		args    string
+		want    []string
+		notWant []string
+	}{
+		{"20", []string{"20 PRINT 2"}, []string{"10 ", "30 "}},
+		{"20-30", []string{"20 ", "30 "}, []string{"10 ", "40 "}},
+		{"30-", []string{"30 ", "40 "}, []string{"10 ", "20 "}},
+		{"30-20", []string{"20 ", "30 "}, []string{"10 ", "40 "}},
+		{"25", []string{"No lines found"}, []string{"PRINT"}},
+	}
+	for _, tt := range tests {
+		out := directOutput(b, "LIST "+tt.args)
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("LIST %s: output %q does not contain %q", tt.args, out, want)
+			}
+		}
+		for _, notWant := range tt.notWant {
+			if strings.Contains(out, notWant) {
+				t.Errorf("LIST %s: output %q should not contain %q", tt.args, out, notWant)
+			}
+		}
+	}
+}
+
+// TestDeleteRanges removes lines with DELETE and checks what is left
+func TestDeleteRanges(t *testing.T) {
+	tests := []struct {
+		args    string
+		message string
+		left    []string
+		gone    []string
+	}{
+		{"20", "1 line deleted", []string{"10 ", "30 ", "40 "}, []string{"20 "}},
+		{"20-30", "2 lines deleted", []string{"10 ", "40 "}, []string{"20 ", "30 "}},
+		{"30-", "2 lines deleted", []string{"10 ", "20 "}, []string{"30 ", "40 "}},
+		{"-20", "2 lines deleted", []string{"30 ", "40 "}, []string{"10 ", "20 "}},
+		{"25", "No lines found", []string{"10 ", "20 ", "30 ", "40 "}, nil},
+		{"", "SYNTAX ERROR", []string{"10 ", "20 ", "30 ", "40 "}, nil},
+		{"ABC", "SYNTAX ERROR", []string{"10 ", "20 ", "30 ", "40 "}, nil},
+	}
+	for _, tt := range tests {
+		b := NewTinyBASIC(nil)
+		for _, line := range []string{"10 PRINT 1", "20 DATA 2", "30 PRINT 3", "40 DATA 4"} {
+			b.Execute(line)
+		}
+		if out := directOutput(b, "DELETE "+tt.args); !strings.Contains(out, tt.message) {
+			t.Errorf("DELETE %s: output %q does not contain %q", tt.args, out, tt.message)
+		}
+		listing := directOutput(b, "LIST")
+		for _, want := range tt.left {
+			if !strings.Contains(listing, want) {
+				t.Errorf("DELETE %s: line %q missing from %q", tt.args, want, listing)
+			}
+		}
+		for _, notWant := range tt.gone {
+			if strings.Contains(listing, notWant) {
+				t.Errorf("DELETE %s: line %q still in %q", tt.args, notWant, listing)
+			}
+		}
+	}
+}
+
+// TestDeleteRebuildsData checks that DATA of deleted lines is no longer read
+func TestDeleteRebuildsData(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	for _, line := range []string{`10 READ A`, `20 PRINT A * 100`, `30 DATA 1`, `40 DATA 2`} {
+		b.Execute(line)
+	}
+	directOutput(b, "DELETE 30")
+	b.Execute("RUN")
+	waitUntilStopped(t, b)
+	var out strings.Builder
+	for len(b.OutputChan) > 0 {
+		out.WriteString((<-b.OutputChan).Content + "\n")
+	}
+	if !strings.Contains(out.String(), "200") {
+		t.Errorf("program read deleted DATA: %q", out.String())
+	}
+}
+
+// TestDeleteInProgram refuses DELETE while a program runs
+func TestDeleteInProgram(t *testing.T) {
+	out := runTrapProgram(t, false, `10 DELETE 20`, `20 PRINT "STILL HERE"`)
+	if !strings.Contains(out, "NOT ALLOWED IN PROGRAM MODE") {
+		t.Errorf("DELETE ran inside a program: %q", out)
+	}
+}
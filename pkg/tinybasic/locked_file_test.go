@@ -0,0 +1,61 @@
+package tinybasic
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/antibyte/retroterm/pkg/virtualfs"
+)
+
+// lockedFS is a memoryFS whose files in locked refuse writes like files
+// locked with the lock command
+type lockedFS struct {
+	memoryFS
+	locked map[string]bool
+}
+
+func (l lockedFS) WriteFile(path, content string, sessionID string) error {
+	if l.locked[path] {
+		return fmt.Errorf("%w: %s", virtualfs.ErrReadOnly, path)
+	}
+	return l.memoryFS.WriteFile(path, content, sessionID)
+}
+
+// TestLockedFile expects LOAD to read a locked file while SAVE and writing
+// it with OPEN ... FOR OUTPUT fail with FILE IS LOCKED
+func TestLockedFile(t *testing.T) {
+	fs := lockedFS{
+		memoryFS: memoryFS{"game.bas": "10 PRINT \"GAME\"\n", "data.txt": "OLD\n"},
+		locked:   map[string]bool{"game.bas": true, "data.txt": true},
+	}
+	b := NewTinyBASIC(nil)
+	b.fs = fs
+
+	if err := b.cmdLoad(`"game"`); err != nil {
+		t.Fatalf("LOAD of a locked file failed: %v", err)
+	}
+	err := b.cmdSave(`"game", F`)
+	if err == nil || !strings.Contains(err.Error(), "FILE IS LOCKED") {
+		t.Errorf("SAVE over a locked file: got %v, want FILE IS LOCKED", err)
+	}
+	if code := errorCode(err); code != ErrCodePermissionDenied {
+		t.Errorf("ERR for a locked file = %d, want %d", code, ErrCodePermissionDenied)
+	}
+	if fs.memoryFS["game.bas"] != "10 PRINT \"GAME\"\n" {
+		t.Errorf("locked file changed to %q", fs.memoryFS["game.bas"])
+	}
+
+	if err := b.cmdOpen(`"data.txt" FOR OUTPUT AS #1`); err != nil {
+		t.Fatalf("OPEN failed: %v", err)
+	}
+	if err := b.cmdPrintFile(`#1, "NEW"`); err != nil {
+		t.Fatalf("PRINT # failed: %v", err)
+	}
+	if err := b.cmdClose("#1"); err == nil || !strings.Contains(err.Error(), "FILE IS LOCKED") {
+		t.Errorf("CLOSE of a locked output file: got %v, want FILE IS LOCKED", err)
+	}
+	if fs.memoryFS["data.txt"] != "OLD\n" {
+		t.Errorf("locked file changed to %q", fs.memoryFS["data.txt"])
+	}
+}
@@ -39,6 +39,8 @@ func (b *TinyBASIC) cmdMCP(args string) error {
 	switch subCommand {
 	case "CREATE":
 		return b.cmdMCPCreate(strings.Join(parts[1:], " "))
+	case "EDITOR":
+		return b.cmdMCPEditor(strings.Join(parts[1:], " "))
 	case "EDIT":
 		if len(parts) < 2 {
 			return NewBASICError(ErrCategorySyntax, "MISSING_FILENAME", b.currentLine == 0, b.currentLine).WithCommand("MCP EDIT")
@@ -53,16 +55,69 @@ func (b *TinyBASIC) cmdMCP(args string) error {
 
 // cmdMCPCreate handles "MCP CREATE <description>" command
 func (b *TinyBASIC) cmdMCPCreate(description string) error {
+	response, err := b.generateMCPProgram("MCP CREATE", description)
+	if err != nil || response == "" {
+		return err
+	}
+
+	logger.Debug(logger.AreaTinyBasic, "[MCP] Sending generated code messages") // Ask for filename and show generated code
+	logger.Debug(logger.AreaTinyBasic, "[MCP] About to send first message")
+	b.sendMessageWrapped(shared.MessageTypeText, "Generated BASIC program:")
+	logger.Debug(logger.AreaTinyBasic, "[MCP] First message sent")
+
+	// Small delay to prevent client overload
+	time.Sleep(50 * time.Millisecond)
+
+	logger.Debug(logger.AreaTinyBasic, "[MCP] About to send generated code (length: %d)", len(response))
+	b.sendMessageWrapped(shared.MessageTypeText, response)
+	logger.Debug(logger.AreaTinyBasic, "[MCP] Generated code sent")
+	// Small delay to prevent client overload
+	time.Sleep(50 * time.Millisecond)
+
+	logger.Debug(logger.AreaTinyBasic, "[MCP] About to send filename prompt")
+	b.sendMessageWrapped(shared.MessageTypeText, "Enter filename to save program:")
+
+	// Send empty input for CREATE operation
+	inputMsg := shared.Message{
+		Type:      shared.MessageTypeInput,
+		InputStr:  "",
+		CursorPos: 0,
+		SessionID: b.sessionID,
+	}
+	b.sendMessageObject(inputMsg)
+
+	logger.Debug(logger.AreaTinyBasic, "[MCP] Filename prompt sent")
+
+	logger.Debug(logger.AreaTinyBasic, "[MCP] Setting MCP state variables")
+	// Store the generated code and set input flag - do this AFTER sending messages
+	// Note: mutex is already held by the calling function (executeStatement)
+	logger.Debug(logger.AreaTinyBasic, "[MCP] Setting variables without additional mutex lock")
+	b.pendingMCPCode = response
+	b.waitingForMCPInput = true
+	b.pendingMCPFilename = "" // No default filename for CREATE operation
+	logger.Debug(logger.AreaTinyBasic, "[MCP] Set variables: pendingMCPCode length=%d, waitingForMCPInput=%v", len(b.pendingMCPCode), b.waitingForMCPInput)
+
+	// DEBUG: Log that messages were sent
+	logger.Debug(logger.AreaTinyBasic, "[MCP] All messages sent successfully")
+
+	return nil
+}
+
+// generateMCPProgram asks the MCP for a new program matching description and
+// returns the cleaned code. It enforces the daily MCP limit and counts the
+// request. An empty result means the user has already been told why no code
+// was generated.
+func (b *TinyBASIC) generateMCPProgram(command, description string) (string, error) {
 	// DEBUG: Log function entry
-	logger.Debug(logger.AreaTinyBasic, "[MCP] cmdMCPCreate called with description: %s", description)
+	logger.Debug(logger.AreaTinyBasic, "[MCP] %s called with description: %s", command, description)
 	if description == "" {
-		return NewBASICError(ErrCategorySyntax, "MISSING_DESCRIPTION", b.currentLine == 0, b.currentLine).WithCommand("MCP CREATE")
+		return "", NewBASICError(ErrCategorySyntax, "MISSING_DESCRIPTION", b.currentLine == 0, b.currentLine).WithCommand(command)
 	}
 
 	// Check description length (max 512 characters)
 	if len(description) > 512 {
 		b.sendMessageWrapped(shared.MessageTypeText, "Your request is too long (max 512 chars)")
-		return nil
+		return "", nil
 	}
 
 	// Get username
@@ -72,12 +127,12 @@ func (b *TinyBASIC) cmdMCPCreate(description string) error {
 	allowed, message, _ := b.checkMCPRateLimit(username)
 	if !allowed {
 		b.sendMessageWrapped(shared.MessageTypeText, message)
-		return nil
+		return "", nil
 	} // Show thinking message
 	b.sendMessageWrapped(shared.MessageTypeText, "MCP is thinking, please wait...")
 	// Get the MCP CREATE prompt from PromptManager with template data
 	if b.os == nil || b.os.PromptManager == nil {
-		return NewBASICError(ErrCategorySystem, "SYSTEM_ERROR", b.currentLine == 0, b.currentLine).WithCommand("MCP CREATE")
+		return "", NewBASICError(ErrCategorySystem, "SYSTEM_ERROR", b.currentLine == 0, b.currentLine).WithCommand(command)
 	}
 
 	templateData := shared.TemplateData{
@@ -87,7 +142,7 @@ func (b *TinyBASIC) cmdMCPCreate(description string) error {
 
 	prompt, err := b.os.PromptManager.GetMCPCreatePrompt(templateData)
 	if err != nil {
-		return NewBASICError(ErrCategorySystem, "TEMPLATE_ERROR", b.currentLine == 0, b.currentLine).WithCommand("MCP CREATE")
+		return "", NewBASICError(ErrCategorySystem, "TEMPLATE_ERROR", b.currentLine == 0, b.currentLine).WithCommand(command)
 	}
 
 	// DEBUG: Log before DeepSeek call
@@ -95,7 +150,7 @@ func (b *TinyBASIC) cmdMCPCreate(description string) error {
 
 	// Send to DeepSeek using existing chat infrastructure
 	if b.os == nil {
-		return NewBASICError(ErrCategorySystem, "SYSTEM_ERROR", b.currentLine == 0, b.currentLine).WithCommand("MCP CREATE")
+		return "", NewBASICError(ErrCategorySystem, "SYSTEM_ERROR", b.currentLine == 0, b.currentLine).WithCommand(command)
 	}
 
 	// Release the lock before the potentially long-running network call.
@@ -106,7 +161,7 @@ func (b *TinyBASIC) cmdMCPCreate(description string) error {
 
 	if len(responses) == 0 {
 		b.sendMessageWrapped(shared.MessageTypeText, "Error: Failed to generate code")
-		return nil
+		return "", nil
 	}
 
 	// Extract text response
@@ -120,7 +175,7 @@ func (b *TinyBASIC) cmdMCPCreate(description string) error {
 	// Check if the response is an error message from the API
 	if strings.Contains(response, "*System Error*") {
 		b.sendMessageWrapped(shared.MessageTypeText, response)
-		return nil
+		return "", nil
 	}
 
 	// Apply the same sanitization as used in TinyOS chat
@@ -136,7 +191,7 @@ func (b *TinyBASIC) cmdMCPCreate(description string) error {
 	if response == "" {
 		logger.Debug(logger.AreaTinyBasic, "[MCP] Error: Empty response from DeepSeek")
 		b.sendMessageWrapped(shared.MessageTypeText, "Error: No code generated")
-		return nil
+		return "", nil
 	}
 
 	logger.Debug(logger.AreaTinyBasic, "[MCP] About to record MCP usage")
@@ -150,7 +205,7 @@ func (b *TinyBASIC) cmdMCPCreate(description string) error {
 		logger.Debug(logger.AreaTinyBasic, "[MCP] DeepSeek aborted due to complexity (usage counted)")
 		b.sendMessageWrapped(shared.MessageTypeText, fmt.Sprintf("mcp usage today: %d out of %d times", newUsage, MCPUserDailyLimit))
 		b.sendMessageWrapped(shared.MessageTypeText, "Requested program too complex, request aborted.")
-		return nil
+		return "", nil
 	}
 
 	// Check for declined request signal AFTER recording usage
@@ -162,52 +217,25 @@ func (b *TinyBASIC) cmdMCPCreate(description string) error {
 		}
 		b.sendMessageWrapped(shared.MessageTypeText, fmt.Sprintf("mcp usage today: %d out of %d times", newUsage, MCPUserDailyLimit))
 		b.sendMessageWrapped(shared.MessageTypeText, declineMessage)
-		return nil
+		return "", nil
 	}
 
 	logger.Debug(logger.AreaTinyBasic, "[MCP] Recorded usage, sending usage info") // Show usage information
 	b.sendMessageWrapped(shared.MessageTypeText, fmt.Sprintf("mcp usage today: %d out of %d times", newUsage, MCPUserDailyLimit))
+	return response, nil
+}
 
-	logger.Debug(logger.AreaTinyBasic, "[MCP] Sending generated code messages") // Ask for filename and show generated code
-	logger.Debug(logger.AreaTinyBasic, "[MCP] About to send first message")
-	b.sendMessageWrapped(shared.MessageTypeText, "Generated BASIC program:")
-	logger.Debug(logger.AreaTinyBasic, "[MCP] First message sent")
-
-	// Small delay to prevent client overload
-	time.Sleep(50 * time.Millisecond)
-
-	logger.Debug(logger.AreaTinyBasic, "[MCP] About to send generated code (length: %d)", len(response))
-	b.sendMessageWrapped(shared.MessageTypeText, response)
-	logger.Debug(logger.AreaTinyBasic, "[MCP] Generated code sent")
-	// Small delay to prevent client overload
-	time.Sleep(50 * time.Millisecond)
-
-	logger.Debug(logger.AreaTinyBasic, "[MCP] About to send filename prompt")
-	b.sendMessageWrapped(shared.MessageTypeText, "Enter filename to save program:")
-
-	// Send empty input for CREATE operation
-	inputMsg := shared.Message{
-		Type:      shared.MessageTypeInput,
-		InputStr:  "",
-		CursorPos: 0,
-		SessionID: b.sessionID,
+// cmdMCPEditor handles "MCP EDITOR <description>" command. Unlike MCP CREATE
+// the generated program is not saved but opened in the editor for review; it
+// is only written when the user saves it there.
+func (b *TinyBASIC) cmdMCPEditor(description string) error {
+	response, err := b.generateMCPProgram("MCP EDITOR", description)
+	if err != nil || response == "" {
+		return err
 	}
-	b.sendMessageObject(inputMsg)
-
-	logger.Debug(logger.AreaTinyBasic, "[MCP] Filename prompt sent")
-
-	logger.Debug(logger.AreaTinyBasic, "[MCP] Setting MCP state variables")
-	// Store the generated code and set input flag - do this AFTER sending messages
-	// Note: mutex is already held by the calling function (executeStatement)
-	logger.Debug(logger.AreaTinyBasic, "[MCP] Setting variables without additional mutex lock")
-	b.pendingMCPCode = response
-	b.waitingForMCPInput = true
-	b.pendingMCPFilename = "" // No default filename for CREATE operation
-	logger.Debug(logger.AreaTinyBasic, "[MCP] Set variables: pendingMCPCode length=%d, waitingForMCPInput=%v", len(b.pendingMCPCode), b.waitingForMCPInput)
-
-	// DEBUG: Log that messages were sent
-	logger.Debug(logger.AreaTinyBasic, "[MCP] All messages sent successfully")
 
+	logger.Debug(logger.AreaTinyBasic, "[MCP] Opening generated code in editor (length: %d)", len(response))
+	b.openEditor("", "<new file>", response)
 	return nil
 }
 
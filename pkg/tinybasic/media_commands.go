@@ -1,12 +1,15 @@
 package tinybasic
 
 import (
+	"fmt"
 	"log"
+	"math"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/antibyte/retroterm/pkg/shared"
+	"github.com/antibyte/retroterm/pkg/tinyos"
 )
 
 // cmdBeep sends a beep signal. No lock needed.
@@ -25,7 +28,14 @@ func (b *TinyBASIC) cmdBeep(args string) error {
 
 // cmdSound evaluates arguments for SOUND and sends a sound message. Assumes lock is held.
 func (b *TinyBASIC) cmdSound(args string) error {
-	// Syntax: SOUND <freq_expr>, <duration_expr>
+	// Syntax: SOUND <freq_expr>, <duration_expr> | SOUND WAIT ON|OFF
+	if on, isWait, valid := parseSoundWait(args); isWait {
+		if !valid {
+			return NewBASICError(ErrCategorySyntax, "UNEXPECTED_TOKEN", b.currentLine == 0, b.currentLine).WithCommand("SOUND").WithUsageHint("SOUND WAIT ON|OFF")
+		}
+		b.soundWait = on
+		return nil
+	}
 	parts := strings.SplitN(args, ",", 2)
 	if len(parts) != 2 {
 		return NewBASICError(ErrCategorySyntax, "UNEXPECTED_TOKEN", b.currentLine == 0, b.currentLine).WithCommand("SOUND")
@@ -49,6 +59,10 @@ func (b *TinyBASIC) cmdSound(args string) error {
 			"duration":  durVal.NumValue,
 		},
 	}
+	if b.soundWait {
+		b.queueSound(soundMsg, time.Duration(math.Max(durVal.NumValue, 0))*time.Millisecond)
+		return nil
+	}
 	if !b.sendMessageObject(soundMsg) {
 		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", b.currentLine == 0, b.currentLine).WithCommand("SOUND")
 	}
@@ -91,6 +105,10 @@ func (b *TinyBASIC) cmdNoise(args string) error {
 			"decay":  decayVal.NumValue,
 		},
 	}
+	if b.soundWait {
+		b.queueSound(noiseMsg, noiseDuration(attackVal.NumValue, decayVal.NumValue))
+		return nil
+	}
 	if !b.sendMessageObject(noiseMsg) {
 		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", b.currentLine == 0, b.currentLine).WithCommand("NOISE")
 	}
@@ -274,33 +292,65 @@ func truncateString(s string, maxLen int) string {
 	return s[:maxLen] + "..."
 }
 
-// cmdMusic handles all MUSIC commands for SID file playback
+// maxSIDSongs is the highest tune number a SID file can have
+const maxSIDSongs = 256
+
+// musicOpenDelay gives the frontend time to stop the previous SID file
+// before MUSIC OPEN loads the next one
+const musicOpenDelay = 200 * time.Millisecond
+
+// musicUsage is the usage hint for MUSIC
+const musicUsage = `MUSIC OPEN "file.sid" | MUSIC PLAY | MUSIC PAUSE | MUSIC RESUME | MUSIC STOP | MUSIC TUNE n | MUSIC SCOPE ON|OFF`
+
+// musicState is what the program knows about its SID music. The frontend
+// plays it; the state is used to check tune numbers and to skip controls
+// that would do nothing, and for the play position of MUSIC SCOPE.
+type musicState struct {
+	filename string        // Opened SID file, "" = none
+	songs    int           // Number of tunes in the file, 0 = unknown
+	paused   bool          // Stopped with MUSIC PAUSE, MUSIC RESUME continues
+	tune     int           // Tune chosen with MUSIC TUNE, 0 = default tune
+	played   time.Duration // Play time of the tune before started
+	started  time.Time     // Start of the current play stretch, zero while paused
+}
+
+// cmdMusic handles all MUSIC commands for SID file playback. Assumes lock is held.
 // Syntax: MUSIC OPEN "filename.sid"
 //
 //	MUSIC PLAY
-//	MUSIC STOP
 //	MUSIC PAUSE
+//	MUSIC RESUME
+//	MUSIC STOP
+//	MUSIC TUNE n
+//	MUSIC SCOPE ON|OFF
 func (b *TinyBASIC) cmdMusic(args string) error {
-	args = strings.TrimSpace(args)
-	if args == "" {
-		return NewBASICError(ErrCategorySyntax, "UNEXPECTED_TOKEN", b.currentLine == 0, b.currentLine).WithCommand("MUSIC").WithUsageHint("MUSIC OPEN \"filename.sid\" | MUSIC PLAY | MUSIC STOP | MUSIC PAUSE")
-	}
-
-	// Parse subcommand
-	parts := strings.SplitN(args, " ", 2)
-	subcommand := strings.ToUpper(strings.TrimSpace(parts[0]))
+	subcommand, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	subcommand = strings.ToUpper(subcommand)
+	rest = strings.TrimSpace(rest)
 
 	switch subcommand {
 	case "OPEN":
-		return b.cmdMusicOpen(strings.TrimSpace(parts[1]))
-	case "PLAY":
-		return b.cmdMusicPlay()
-	case "STOP":
-		return b.cmdMusicStop()
-	case "PAUSE":
-		return b.cmdMusicPause()
+		return b.cmdMusicOpen(rest)
+	case "TUNE":
+		if rest == "" {
+			return NewBASICError(ErrCategorySyntax, "MISSING_ARGUMENT", b.currentLine == 0, b.currentLine).WithCommand("MUSIC TUNE").WithUsageHint("MUSIC TUNE n")
+		}
+		tune, err := b.evalExpression(rest)
+		if err != nil {
+			return err
+		}
+		return b.musicControl(subcommand, tune, b.currentLine)
+	case "SCOPE":
+		return b.musicControl(subcommand, BASICValue{StrValue: strings.ToUpper(rest)}, b.currentLine)
+	case "PLAY", "PAUSE", "RESUME", "STOP":
+		if rest != "" {
+			return NewBASICError(ErrCategorySyntax, "UNEXPECTED_TOKEN", b.currentLine == 0, b.currentLine).WithCommand("MUSIC " + subcommand).WithUsageHint(musicUsage)
+		}
+		return b.musicControl(subcommand, BASICValue{}, b.currentLine)
+	case "":
+		return NewBASICError(ErrCategorySyntax, "UNEXPECTED_TOKEN", b.currentLine == 0, b.currentLine).WithCommand("MUSIC").WithUsageHint(musicUsage)
 	default:
-		return NewBASICError(ErrCategorySyntax, "UNEXPECTED_TOKEN", b.currentLine == 0, b.currentLine).WithCommand("MUSIC").WithUsageHint("Valid subcommands: OPEN, PLAY, STOP, PAUSE")
+		return NewBASICError(ErrCategorySyntax, "UNEXPECTED_TOKEN", b.currentLine == 0, b.currentLine).WithCommand("MUSIC").WithUsageHint("Valid subcommands: OPEN, PLAY, PAUSE, RESUME, STOP, TUNE, SCOPE")
 	}
 }
 
@@ -315,89 +365,171 @@ func (b *TinyBASIC) cmdMusicOpen(args string) error {
 	if err != nil || filenameVal.IsNumeric {
 		return NewBASICError(ErrCategorySyntax, "INVALID_EXPRESSION", b.currentLine == 0, b.currentLine).WithCommand("MUSIC OPEN").WithUsageHint("Filename must be a string expression.")
 	}
-	filename := filenameVal.StrValue
-	if filename == "" {
+	if filenameVal.StrValue == "" {
 		return NewBASICError(ErrCategorySyntax, "INVALID_ARGUMENT", b.currentLine == 0, b.currentLine).WithCommand("MUSIC OPEN").WithUsageHint("Filename cannot be empty.")
 	}
 	// Always stop any previous music before opening a new file
 	// This ensures clean state for the frontend sound system
-	stopMsg := shared.Message{
-		Type: shared.MessageTypeSound,
-		Params: map[string]interface{}{
-			"action": "music_stop",
-		},
-	}
-	b.sendMessageObject(stopMsg) // Don't check return value, continue even if stop fails
+	b.sendMusicAction("music_stop", nil) // Don't check return value, continue even if stop fails
 
 	// Release the lock before sleeping to avoid blocking the interpreter.
 	b.mu.Unlock()
-	// Small delay to allow frontend to process the stop command
-	// This works around potential frontend sound system state issues
-	time.Sleep(200 * time.Millisecond)
+	time.Sleep(musicOpenDelay)
 	// Re-acquire the lock after sleeping.
 	b.mu.Lock()
 
-	musicMsg := shared.Message{
-		Type: shared.MessageTypeSound,
-		Params: map[string]interface{}{
-			"action":   "music_open",
-			"filename": filename,
-		},
-	}
+	return b.openMusic(filenameVal.StrValue, b.currentLine)
+}
+
+// openMusic tells the frontend to load filename and remembers how many tunes
+// it has. The frontend starts the default tune right away. The previous music
+// must already be stopped.
+func (b *TinyBASIC) openMusic(filename string, line int) error {
 	log.Printf("[DEBUG-MUSIC] Sending MUSIC OPEN command: %s", filename)
-	if !b.sendMessageObject(musicMsg) {
+	if !b.sendMusicAction("music_open", map[string]interface{}{"filename": filename}) {
 		log.Printf("[DEBUG-MUSIC] MUSIC OPEN failed to send message")
-		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", b.currentLine == 0, b.currentLine).WithCommand("MUSIC OPEN")
+		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", line == 0, line).WithCommand("MUSIC OPEN")
 	}
-	log.Printf("[DEBUG-MUSIC] MUSIC OPEN message sent successfully")
-
+	b.music = musicState{filename: filename, songs: b.sidSongCount(filename), started: time.Now()}
+	b.updateMusicProgress()
 	return nil
 }
 
-// cmdMusicPlay starts or resumes SID music playback
-func (b *TinyBASIC) cmdMusicPlay() error {
-	musicMsg := shared.Message{
-		Type: shared.MessageTypeSound,
-		Params: map[string]interface{}{
-			"action": "music_play",
-		},
-	}
-	log.Printf("[DEBUG-MUSIC] Sending MUSIC PLAY command")
-	if !b.sendMessageObject(musicMsg) {
-		log.Printf("[DEBUG-MUSIC] MUSIC PLAY failed to send message")
-		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", b.currentLine == 0, b.currentLine).WithCommand("MUSIC PLAY")
+// sidSongCount reads the number of tunes from the header of a SID file. It
+// returns 0 if the file cannot be read here; the frontend also finds the
+// SID files of the examples.
+func (b *TinyBASIC) sidSongCount(filename string) int {
+	if b.fs == nil {
+		return 0
+	}
+	for _, name := range []string{filename, filename + ".sid"} {
+		content, err := b.fs.ReadFile(name, b.sessionID)
+		if err != nil {
+			continue
+		}
+		info, err := tinyos.ParseSIDHeader([]byte(content))
+		if err != nil {
+			return 0
+		}
+		return info.Songs
 	}
-	log.Printf("[DEBUG-MUSIC] MUSIC PLAY message sent successfully")
-
-	return nil
+	return 0
 }
 
-// cmdMusicStop stops SID music playback
-func (b *TinyBASIC) cmdMusicStop() error {
-	musicMsg := shared.Message{
-		Type: shared.MessageTypeSound,
-		Params: map[string]interface{}{
-			"action": "music_stop",
-		},
-	}
-	if !b.sendMessageObject(musicMsg) {
-		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", b.currentLine == 0, b.currentLine).WithCommand("MUSIC STOP")
+// musicControl carries out PLAY, PAUSE, RESUME, STOP, TUNE and SCOPE for the
+// interpreter and the VM. Controls that would do nothing, like PAUSE without
+// music, only print a warning so a program does not stop because of them.
+// arg is the number of TUNE or the ON/OFF of SCOPE.
+func (b *TinyBASIC) musicControl(subcommand string, arg BASICValue, line int) error {
+	var params map[string]interface{}
+	action := "music_" + strings.ToLower(subcommand)
+	ignored := ""
+	now := time.Now()
+
+	switch subcommand {
+	case "PLAY":
+		if b.music.filename == "" {
+			ignored = "no music opened"
+		} else if b.music.paused {
+			b.music.started = now // PLAY continues paused music like RESUME
+		}
+		b.music.paused = false
+	case "PAUSE":
+		if b.music.filename == "" || b.music.paused {
+			ignored = "no music is playing"
+			break
+		}
+		b.music.played = b.music.position(now)
+		b.music.started = time.Time{}
+		b.music.paused = true
+	case "RESUME":
+		if b.music.filename == "" || !b.music.paused {
+			ignored = "no music is paused"
+			break
+		}
+		b.music.started = now
+		b.music.paused = false
+	case "STOP":
+		if b.music.filename == "" {
+			ignored = "no music is playing"
+		}
+		b.resetMusic()
+	case "SCOPE":
+		if arg.StrValue != "ON" && arg.StrValue != "OFF" {
+			return NewBASICError(ErrCategorySyntax, "INVALID_ARGUMENT", line == 0, line).WithCommand("MUSIC SCOPE").WithUsageHint("MUSIC SCOPE ON|OFF")
+		}
+		b.setMusicScope(arg.StrValue == "ON")
+		return nil
+	case "TUNE":
+		n := int(arg.NumValue)
+		if !arg.IsNumeric || arg.NumValue != float64(n) || n < 1 || n > maxSIDSongs {
+			return NewBASICError(ErrCategoryEvaluation, "OUT_OF_RANGE", line == 0, line).
+				WithCommand("MUSIC TUNE").WithUsageHint(fmt.Sprintf("Tune must be a number from 1 to %d", maxSIDSongs))
+		}
+		if b.music.filename == "" {
+			ignored = "no music opened"
+			break
+		}
+		if b.music.songs > 0 && n > b.music.songs {
+			return NewBASICError(ErrCategoryEvaluation, "OUT_OF_RANGE", line == 0, line).
+				WithCommand("MUSIC TUNE").WithUsageHint(fmt.Sprintf("%s has tunes 1 to %d", b.music.filename, b.music.songs))
+		}
+		params = map[string]interface{}{"tune": n}
+		b.music = musicState{filename: b.music.filename, songs: b.music.songs, tune: n, started: now}
+	default:
+		return fmt.Errorf("unknown MUSIC subcommand %s", subcommand)
 	}
 
+	if ignored != "" {
+		b.sendMessageWrapped(shared.MessageTypeText, "WARNING: MUSIC "+subcommand+" ignored, "+ignored)
+		return nil
+	}
+	b.updateMusicProgress()
+	if !b.sendMusicAction(action, params) {
+		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", line == 0, line).WithCommand("MUSIC " + subcommand)
+	}
 	return nil
 }
 
-// cmdMusicPause pauses SID music playback
-func (b *TinyBASIC) cmdMusicPause() error {
-	musicMsg := shared.Message{
-		Type: shared.MessageTypeSound,
-		Params: map[string]interface{}{
-			"action": "music_pause",
-		},
+// sendMusicAction sends a SID music action with its parameters to the frontend
+func (b *TinyBASIC) sendMusicAction(action string, params map[string]interface{}) bool {
+	msgParams := map[string]interface{}{"action": action}
+	for key, value := range params {
+		msgParams[key] = value
 	}
-	if !b.sendMessageObject(musicMsg) {
-		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", b.currentLine == 0, b.currentLine).WithCommand("MUSIC PAUSE")
+	return b.sendMessageObject(shared.Message{
+		Type:   shared.MessageTypeSound,
+		Params: msgParams,
+	})
+}
+
+// handleMusic is the bytecode version of MUSIC. Operand1 is the subcommand;
+// OPEN, TUNE and SCOPE take their argument from the stack.
+func (vm *BytecodeVM) handleMusic(inst *Instruction) error {
+	subcommand, _ := inst.Operand1.(string)
+	var arg BASICValue
+	if subcommand == "OPEN" || subcommand == "TUNE" || subcommand == "SCOPE" {
+		value, err := vm.stack.Pop()
+		if err != nil {
+			return fmt.Errorf("MUSIC %s: missing argument", subcommand)
+		}
+		arg = value
 	}
 
+	if b := vm.tinybasic; b != nil {
+		if subcommand == "OPEN" {
+			if arg.IsNumeric || arg.StrValue == "" {
+				return NewBASICError(ErrCategorySyntax, "INVALID_EXPRESSION", false, inst.LineNum).WithCommand("MUSIC OPEN").WithUsageHint("Filename must be a string expression.")
+			}
+			b.sendMusicAction("music_stop", nil)
+			time.Sleep(musicOpenDelay) // The VM runs without the interpreter lock
+			if err := b.openMusic(arg.StrValue, inst.LineNum); err != nil {
+				return err
+			}
+		} else if err := b.musicControl(subcommand, arg, inst.LineNum); err != nil {
+			return err
+		}
+	}
+	vm.pc++
 	return nil
 }
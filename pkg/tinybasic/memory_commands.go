@@ -0,0 +1,45 @@
+package tinybasic
+
+// emulatedMemoryBytes is the size of the pretend BASIC memory FRE() reports
+// from. Programs and variables live in Go maps, so the number only follows
+// the classic home computers in spirit.
+const emulatedMemoryBytes = 65536
+
+// Estimated cost of program lines and variables in the emulated memory
+const (
+	lineOverheadBytes   = 4 // Line number and link to the next line
+	numericValueBytes   = 8
+	stringOverheadBytes = 3 // Length and pointer of the string descriptor
+)
+
+// memoryUsed estimates how much of the emulated memory the program text and
+// the variables take
+func memoryUsed(program map[int]string, variables map[string]BASICValue) int {
+	used := 0
+	for _, line := range program {
+		used += len(line) + lineOverheadBytes
+	}
+	for name, value := range variables {
+		used += len(name)
+		if value.IsNumeric {
+			used += numericValueBytes
+		} else {
+			used += len(value.StrValue) + stringOverheadBytes
+		}
+	}
+	return used
+}
+
+// freeMemory implements FRE(x) for the interpreter and the bytecode VM. Any
+// argument is accepted; a string argument, as in FRE(""), first empties the
+// expression cache the way old BASICs collected string garbage.
+func (b *TinyBASIC) freeMemory(args []BASICValue, variables map[string]BASICValue) float64 {
+	if len(args) > 0 && !args[0].IsNumeric && b.exprTokenCache != nil {
+		b.exprTokenCache.Clear()
+	}
+	free := emulatedMemoryBytes - memoryUsed(b.program, variables)
+	if free < 0 {
+		return 0
+	}
+	return float64(free)
+}
@@ -0,0 +1,217 @@
+package tinybasic
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// midAssignment is the parsed form of MID$(target, start[, length]) = value
+type midAssignment struct {
+	target string // String variable or array element to modify
+	start  string // Expression for the 1-based start position
+	length string // Expression for the maximum number of characters, "" if omitted
+	value  string // Expression for the replacement string
+}
+
+// parseMidAssignment recognizes the MID$ assignment statement (with or without LET).
+// ok is false for anything else, so MID$ used as a function is not affected.
+func parseMidAssignment(stmt string) (midAssignment, bool) {
+	s := strings.TrimSpace(stmt)
+	if len(s) > 4 && strings.EqualFold(s[:3], "LET") && (s[3] == ' ' || s[3] == '\t') {
+		s = strings.TrimSpace(s[4:])
+	}
+	if len(s) < 4 || !strings.EqualFold(s[:4], "MID$") {
+		return midAssignment{}, false
+	}
+	rest := strings.TrimSpace(s[4:])
+	if !strings.HasPrefix(rest, "(") {
+		return midAssignment{}, false
+	}
+
+	// Find the parenthesis closing the argument list, skipping string literals
+	depth, inQuote, closeIdx := 0, false, -1
+	for i := 0; i < len(rest) && closeIdx == -1; i++ {
+		switch {
+		case rest[i] == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case rest[i] == '(':
+			depth++
+		case rest[i] == ')':
+			depth--
+			if depth == 0 {
+				closeIdx = i
+			}
+		}
+	}
+	if closeIdx == -1 {
+		return midAssignment{}, false
+	}
+	after := strings.TrimSpace(rest[closeIdx+1:])
+	if !strings.HasPrefix(after, "=") {
+		return midAssignment{}, false
+	}
+
+	args := splitTopLevelArgs(rest[1:closeIdx])
+	m := midAssignment{value: strings.TrimSpace(after[1:])}
+	if len(args) > 0 {
+		m.target = args[0]
+	}
+	if len(args) > 1 {
+		m.start = args[1]
+	}
+	if len(args) > 2 {
+		m.length = args[2]
+	}
+	if len(args) < 2 || len(args) > 3 {
+		m.start = "" // Reported as a syntax error by the caller
+	}
+	return m, true
+}
+
+// splitTopLevelArgs splits an argument list at commas outside of parentheses and strings
+func splitTopLevelArgs(list string) []string {
+	var args []string
+	depth, inQuote, start := 0, false, 0
+	for i := 0; i < len(list); i++ {
+		switch {
+		case list[i] == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case list[i] == '(':
+			depth++
+		case list[i] == ')':
+			depth--
+		case list[i] == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(list[start:i]))
+			start = i + 1
+		}
+	}
+	return append(args, strings.TrimSpace(list[start:]))
+}
+
+// replaceMid overwrites s from the 1-based position start with at most length
+// characters of repl (all of repl if length is negative). The result always
+// has the length of s; a replacement that does not fit is truncated.
+func replaceMid(s string, start, length int, repl string) (string, error) {
+	if start < 1 || start > len(s) {
+		return "", fmt.Errorf("MID$ start position %d outside of string (1-%d)", start, len(s))
+	}
+	count := len(repl)
+	if length >= 0 && length < count {
+		count = length
+	}
+	if room := len(s) - start + 1; count > room {
+		count = room
+	}
+	return s[:start-1] + repl[:count] + s[start-1+count:], nil
+}
+
+// cmdMidAssign executes MID$(target, start[, length]) = value. Assumes lock is held.
+func (b *TinyBASIC) cmdMidAssign(m midAssignment) error {
+	isDirect := b.currentLine == 0
+	if m.target == "" || m.start == "" {
+		return NewBASICError(ErrCategorySyntax, "INVALID_PARAMETER_COUNT", isDirect, b.currentLine).
+			WithCommand("MID$").WithUsageHint("MID$(A$, start[, length]) = B$")
+	}
+	if m.value == "" {
+		return NewBASICError(ErrCategorySyntax, "EXPECTED_EXPRESSION", isDirect, b.currentLine).WithCommand("MID$")
+	}
+
+	baseName, indices, err := b.parseVariableWithIndex(m.target)
+	if err != nil {
+		return WrapError(err, "MID$", isDirect, b.currentLine)
+	}
+	baseName = getCachedVarName(baseName)
+	if !isValidVarName(baseName) || !strings.HasSuffix(baseName, "$") {
+		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", isDirect, b.currentLine).WithCommand("MID$")
+	}
+	key := baseName
+	if len(indices) > 0 {
+		if key, err = b.arrayElement(baseName, indices); err != nil {
+			return err
+		}
+	}
+
+	start, err := b.evalMidNumber(m.start)
+	if err != nil {
+		return err
+	}
+	length := -1
+	if m.length != "" {
+		if length, err = b.evalMidNumber(m.length); err != nil {
+			return err
+		}
+		if length < 0 {
+			return NewBASICError(ErrCategoryEvaluation, "OUT_OF_RANGE", isDirect, b.currentLine).WithCommand("MID$")
+		}
+	}
+	value, err := b.evalExpression(m.value)
+	if err != nil {
+		return WrapError(err, "MID$", isDirect, b.currentLine)
+	}
+	if value.IsNumeric {
+		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", isDirect, b.currentLine).WithCommand("MID$")
+	}
+
+	result, err := replaceMid(b.variables[key].StrValue, start, length, value.StrValue)
+	if err != nil {
+		return NewBASICError(ErrCategoryEvaluation, "OUT_OF_RANGE", isDirect, b.currentLine).WithCommand("MID$")
+	}
+	b.variables[key] = BASICValue{StrValue: result, IsNumeric: false}
+	return nil
+}
+
+// evalMidNumber evaluates a numeric MID$ argument and rounds it to an integer
+func (b *TinyBASIC) evalMidNumber(expr string) (int, error) {
+	value, err := b.evalExpression(expr)
+	if err != nil {
+		return 0, WrapError(err, "MID$", b.currentLine == 0, b.currentLine)
+	}
+	if !value.IsNumeric {
+		return 0, NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", b.currentLine == 0, b.currentLine).WithCommand("MID$")
+	}
+	return int(math.Round(value.NumValue)), nil
+}
+
+// handleMidAssign executes OP_MID_ASSIGN: pops the replacement, the optional
+// length and the start position, then updates the variable named by Operand1
+func (vm *BytecodeVM) handleMidAssign(inst *Instruction) error {
+	varName := strings.ToUpper(inst.Operand1.(string))
+	hasLength, _ := inst.Operand2.(bool)
+
+	value, err := vm.stack.Pop()
+	if err != nil {
+		return err
+	}
+	if value.IsNumeric {
+		return fmt.Errorf("MID$ replacement must be a string")
+	}
+	length := -1
+	if hasLength {
+		lengthArg, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		if !lengthArg.IsNumeric || lengthArg.NumValue < 0 {
+			return fmt.Errorf("MID$ length must be a non-negative number")
+		}
+		length = int(math.Round(lengthArg.NumValue))
+	}
+	startArg, err := vm.stack.Pop()
+	if err != nil {
+		return err
+	}
+	if !startArg.IsNumeric {
+		return fmt.Errorf("MID$ start position must be numeric")
+	}
+
+	result, err := replaceMid(vm.variables[varName].StrValue, int(math.Round(startArg.NumValue)), length, value.StrValue)
+	if err != nil {
+		return err
+	}
+	vm.variables[varName] = BASICValue{StrValue: InternString(result), IsNumeric: false}
+	vm.pc++
+	return nil
+}
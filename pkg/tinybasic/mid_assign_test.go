@@ -0,0 +1,93 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestReplaceMid checks classic MID$ assignment semantics at the string boundaries
+func TestReplaceMid(t *testing.T) {
+	tests := []struct {
+		s      string
+		start  int
+		length int
+		repl   string
+		want   string
+	}{
+		{"ABCDEF", 3, 2, "XY", "ABXYEF"},
+		{"ABCDEF", 3, -1, "XY", "ABXYEF"},
+		{"ABCDEF", 1, -1, "XY", "XYCDEF"},
+		{"ABCDEF", 6, -1, "XYZ", "ABCDEX"},
+		{"ABCDEF", 5, -1, "XYZ", "ABCDXY"},
+		{"ABCDEF", 2, 1, "XYZ", "AXCDEF"},
+		{"ABCDEF", 2, 10, "XY", "AXYDEF"},
+		{"ABCDEF", 3, 0, "XY", "ABCDEF"},
+		{"ABCDEF", 1, -1, "", "ABCDEF"},
+		{"ABC", 1, -1, "VERY LONG", "VER"},
+	}
+	for _, tt := range tests {
+		got, err := replaceMid(tt.s, tt.start, tt.length, tt.repl)
+		if err != nil {
+			t.Errorf("replaceMid(%q, %d, %d, %q) failed: %v", tt.s, tt.start, tt.length, tt.repl, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("replaceMid(%q, %d, %d, %q) = %q, want %q", tt.s, tt.start, tt.length, tt.repl, got, tt.want)
+		}
+	}
+
+	for _, start := range []int{0, 7} {
+		if _, err := replaceMid("ABCDEF", start, -1, "X"); err == nil {
+			t.Errorf("replaceMid with start %d should fail", start)
+		}
+	}
+}
+
+// TestParseMidAssignment checks that only the assignment form is recognized
+func TestParseMidAssignment(t *testing.T) {
+	m, ok := parseMidAssignment(`LET MID$(A$, 2 + 1, LEN("X,Y")) = "(=)"`)
+	if !ok {
+		t.Fatal("assignment not recognized")
+	}
+	if m.target != "A$" || m.start != "2 + 1" || m.length != `LEN("X,Y")` || m.value != `"(=)"` {
+		t.Errorf("unexpected parse result: %+v", m)
+	}
+
+	for _, stmt := range []string{`PRINT MID$(A$, 2)`, `B$ = MID$(A$, 2)`, `MID$(A$, 2)`, `MIDX(A$, 2) = "X"`} {
+		if _, ok := parseMidAssignment(stmt); ok {
+			t.Errorf("%s should not be a MID$ assignment", stmt)
+		}
+	}
+}
+
+// TestMidAssignProgram runs MID$ assignments with the bytecode VM and the interpreter
+func TestMidAssignProgram(t *testing.T) {
+	program := []string{
+		`10 LET A$ = "HELLO WORLD"`,
+		`20 MID$(A$, 7, 2) = "XYZ"`,
+		`30 PRINT A$`,
+		`40 LET B$ = "ABC": MID$(B$, 2) = "123456"`,
+		`50 PRINT B$`,
+	}
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode, program...)
+		for _, want := range []string{"HELLO XYRLD", "A12"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("bytecode=%v: output %q does not contain %q", bytecode, out, want)
+			}
+		}
+		if strings.Contains(out, "A123") {
+			t.Errorf("bytecode=%v: replacement grew the string: %q", bytecode, out)
+		}
+	}
+
+	out := runTrapProgram(t, false, `10 DIM C$(2): C$(1) = "12345": MID$(C$(1), 5) = "X": PRINT C$(1)`)
+	if !strings.Contains(out, "1234X") {
+		t.Errorf("array element: output %q does not contain %q", out, "1234X")
+	}
+
+	out = runTrapProgram(t, false, `10 A$ = "ABC": MID$(A$, 4) = "X"`)
+	if !strings.Contains(out, "OUT OF RANGE") {
+		t.Errorf("start past the end should report an error, got %q", out)
+	}
+}
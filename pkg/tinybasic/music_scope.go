@@ -0,0 +1,106 @@
+package tinybasic
+
+import (
+	"sync"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// musicProgressInterval is how often MUSIC SCOPE ON reports the play position
+const musicProgressInterval = 250 * time.Millisecond
+
+// musicProgress sends "music_progress" sound messages while SID music plays,
+// so the frontend can draw a scope next to the music. It is off until the
+// session turns it on with MUSIC SCOPE ON and stays on across RUN and NEW.
+type musicProgress struct {
+	mu      sync.Mutex
+	enabled bool
+	stop    chan struct{} // Closed to end the running reporter, nil = none
+}
+
+// position returns how long the current tune has played, without pauses
+func (m *musicState) position(now time.Time) time.Duration {
+	if m.started.IsZero() {
+		return m.played
+	}
+	return m.played + now.Sub(m.started)
+}
+
+// setMusicScope turns the progress messages of the session on or off
+func (b *TinyBASIC) setMusicScope(enabled bool) {
+	b.musicProgress.mu.Lock()
+	b.musicProgress.enabled = enabled
+	b.musicProgress.mu.Unlock()
+	b.updateMusicProgress()
+}
+
+// resetMusic forgets the SID music and ends its progress messages
+func (b *TinyBASIC) resetMusic() {
+	b.music = musicState{}
+	b.updateMusicProgress()
+}
+
+// updateMusicProgress restarts the progress reporter after the music state
+// changed. It only runs while music plays and the scope is on, so PAUSE,
+// STOP, BREAK and the end of the program all end it.
+func (b *TinyBASIC) updateMusicProgress() {
+	p := &b.musicProgress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+	if !p.enabled || b.music.filename == "" || b.music.started.IsZero() {
+		return
+	}
+	p.stop = make(chan struct{})
+	go b.reportMusicProgress(p.stop, b.music)
+}
+
+// reportMusicProgress sends the play position of music until stop is closed
+func (b *TinyBASIC) reportMusicProgress(stop <-chan struct{}, music musicState) {
+	ticker := time.NewTicker(musicProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if !b.sendMusicProgress(stop, music, now) {
+				return
+			}
+		}
+	}
+}
+
+// sendMusicProgress sends one progress message unless stop was closed
+// meanwhile, so none arrives after the MUSIC PAUSE or STOP that ended it.
+// Returns false once stopped.
+func (b *TinyBASIC) sendMusicProgress(stop <-chan struct{}, music musicState, now time.Time) bool {
+	b.musicProgress.mu.Lock()
+	defer b.musicProgress.mu.Unlock()
+
+	select {
+	case <-stop:
+		return false
+	default:
+	}
+	// Progress is only decoration: it never takes room that the sound
+	// actions and the program output need
+	if len(b.OutputChan) < outputLowWater {
+		b.sendMessageObject(shared.Message{
+			Type: shared.MessageTypeSound,
+			Params: map[string]interface{}{
+				"action":   "music_progress",
+				"filename": music.filename,
+				"tune":     music.tune,
+				"position": music.position(now).Milliseconds(),
+			},
+		})
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+package tinybasic
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// sidFile returns a minimal PSID v2 file with the given number of tunes
+func sidFile(songs int) string {
+	data := make([]byte, 0x7C+2)
+	copy(data, "PSID")
+	binary.BigEndian.PutUint16(data[0x04:], 2)
+	binary.BigEndian.PutUint16(data[0x06:], 0x7C)
+	binary.BigEndian.PutUint16(data[0x0A:], 0x1000)
+	binary.BigEndian.PutUint16(data[0x0C:], 0x1003)
+	binary.BigEndian.PutUint16(data[0x0E:], uint16(songs))
+	binary.BigEndian.PutUint16(data[0x10:], 1)
+	return string(data)
+}
+
+// runMusicProgram runs a program with tunes.sid (3 tunes) in the file system
+// and returns the music actions it sent and its text output
+func runMusicProgram(t *testing.T, bytecode bool, lines ...string) ([]string, string) {
+	t.Helper()
+	b := NewTinyBASIC(nil)
+	b.fs = memoryFS{"tunes.sid": sidFile(3)}
+	startKeyProgram(t, b, bytecode, lines...)
+	waitUntilStopped(t, b)
+
+	var actions []string
+	var text strings.Builder
+	for len(b.OutputChan) > 0 {
+		msg := <-b.OutputChan
+		switch {
+		case msg.Type == shared.MessageTypeText:
+			text.WriteString(msg.Content + "\n")
+		case msg.Type == shared.MessageTypeSound && msg.Params["action"] != nil:
+			action := msg.Params["action"].(string)
+			if tune, ok := msg.Params["tune"]; ok {
+				action = fmt.Sprintf("%s %v", action, tune)
+			}
+			actions = append(actions, action)
+		}
+	}
+	return actions, text.String()
+}
+
+// TestMusicControls expects PAUSE, RESUME, TUNE and STOP to reach the
+// frontend as music actions and controls without music to only warn
+func TestMusicControls(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		actions, out := runMusicProgram(t, bytecode,
+			`10 MUSIC PAUSE`,
+			`20 MUSIC OPEN "tunes.sid"`,
+			`30 MUSIC TUNE 2`,
+			`40 MUSIC PAUSE`,
+			`50 MUSIC RESUME`,
+			`60 MUSIC RESUME`,
+			`70 MUSIC STOP`,
+			`80 PRINT "DONE"`,
+		)
+		want := []string{"music_stop", "music_open", "music_tune 2", "music_pause", "music_resume", "music_stop"}
+		if len(actions) < len(want) || strings.Join(actions[:len(want)], ",") != strings.Join(want, ",") {
+			t.Errorf("bytecode=%v: actions %v, want %v first", bytecode, actions, want)
+		}
+		for _, warning := range []string{"MUSIC PAUSE ignored", "MUSIC RESUME ignored"} {
+			if !strings.Contains(out, warning) {
+				t.Errorf("bytecode=%v: output %q does not contain %q", bytecode, out, warning)
+			}
+		}
+		if !strings.Contains(out, "DONE") {
+			t.Errorf("bytecode=%v: program stopped early: %q", bytecode, out)
+		}
+	}
+}
+
+// TestMusicTuneRange expects MUSIC TUNE to reject tunes the SID file does
+// not have
+func TestMusicTuneRange(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		for _, tune := range []string{"4", "0", "1.5"} {
+			actions, out := runMusicProgram(t, bytecode,
+				`10 MUSIC OPEN "tunes.sid"`,
+				`20 MUSIC TUNE `+tune,
+				`30 PRINT "AFTER"`,
+			)
+			if strings.Contains(out, "AFTER") || !strings.Contains(out, "ERROR") {
+				t.Errorf("bytecode=%v: MUSIC TUNE %s: got %q, want an error", bytecode, tune, out)
+			}
+			for _, action := range actions {
+				if strings.HasPrefix(action, "music_tune") {
+					t.Errorf("bytecode=%v: MUSIC TUNE %s was sent to the frontend", bytecode, tune)
+				}
+			}
+		}
+	}
+}
+
+// TestMusicScope expects play position updates only with MUSIC SCOPE ON,
+// only while music plays and none after MUSIC PAUSE or STOP
+func TestMusicScope(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		actions, out := runMusicProgram(t, bytecode,
+			`10 MUSIC OPEN "tunes.sid"`,
+			`20 SLEEP 400`,
+			`30 MUSIC SCOPE ON`,
+			`40 MUSIC TUNE 3`,
+			`50 SLEEP 700`,
+			`60 MUSIC PAUSE`,
+			`70 SLEEP 400`,
+			`80 MUSIC STOP`,
+			`90 PRINT "DONE"`,
+		)
+		if !strings.Contains(out, "DONE") {
+			t.Fatalf("bytecode=%v: program stopped early: %q", bytecode, out)
+		}
+		var before, during, after int
+		phase := &before
+		for _, action := range actions {
+			switch {
+			case action == "music_tune 3":
+				phase = &during
+			case action == "music_pause":
+				phase = &after
+			case action == "music_progress 3", action == "music_progress 0":
+				*phase++
+			}
+		}
+		if before != 0 || after != 0 {
+			t.Errorf("bytecode=%v: progress outside playback with the scope on: %v", bytecode, actions)
+		}
+		if during < 2 {
+			t.Errorf("bytecode=%v: got %d progress messages while tune 3 played, want at least 2", bytecode, during)
+		}
+	}
+}
+
+// TestMusicScopePosition expects the position to leave out pauses
+func TestMusicScopePosition(t *testing.T) {
+	start := time.Now()
+	m := musicState{filename: "tunes.sid", started: start}
+	if got := m.position(start.Add(time.Second)); got != time.Second {
+		t.Errorf("position after 1s = %v", got)
+	}
+	m = musicState{filename: "tunes.sid", played: 2 * time.Second}
+	if got := m.position(start.Add(time.Hour)); got != 2*time.Second {
+		t.Errorf("paused position = %v, want 2s", got)
+	}
+	m.started = start
+	if got := m.position(start.Add(500 * time.Millisecond)); got != 2500*time.Millisecond {
+		t.Errorf("resumed position = %v, want 2.5s", got)
+	}
+}
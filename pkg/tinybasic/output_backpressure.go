@@ -0,0 +1,37 @@
+package tinybasic
+
+import (
+	"context"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+const (
+	// outputHighWater is the output channel fill level at which a running
+	// program is paused. sendMessageObject drops messages once the channel is
+	// full, so the program has to stop well before that.
+	outputHighWater = OutputChannelBufferSize * 3 / 4
+	// outputLowWater is the fill level at which a paused program resumes
+	outputLowWater = OutputChannelBufferSize / 4
+	// outputPollInterval is how often a paused program checks the channel
+	outputPollInterval = 10 * time.Millisecond
+)
+
+// waitForOutputRoom pauses the run loop while the terminal drains the output
+// channel slower than the program fills it, e.g. because the terminal
+// throttles a PRINT loop. Returns false if ctx was cancelled while waiting.
+func waitForOutputRoom(ctx context.Context, out chan shared.Message) bool {
+	if len(out) < outputHighWater {
+		return true
+	}
+	tinyBasicDebugLog("Output channel at %d messages, pausing program", len(out))
+	for len(out) > outputLowWater {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(outputPollInterval):
+		}
+	}
+	return true
+}
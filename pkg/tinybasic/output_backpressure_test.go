@@ -0,0 +1,57 @@
+package tinybasic
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestOutputBackpressure lets a program print more lines than the output
+// channel holds while nobody reads it. The program must pause instead of
+// dropping lines, and every line must arrive once the channel is drained.
+func TestOutputBackpressure(t *testing.T) {
+	const lines = OutputChannelBufferSize + 2000
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		b.useBytecode = bytecode
+		b.Execute("10 FOR I = 1 TO " + strconv.Itoa(lines))
+		b.Execute("20 PRINT I")
+		b.Execute("30 NEXT I")
+		b.Execute("RUN")
+
+		deadline := time.Now().Add(5 * time.Second)
+		for len(b.OutputChan) < outputHighWater && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if !b.IsRunning() {
+			t.Fatalf("bytecode=%v: program finished although nobody read its output", bytecode)
+		}
+		if n := len(b.OutputChan); n >= OutputChannelBufferSize {
+			t.Fatalf("bytecode=%v: output channel ran full (%d messages)", bytecode, n)
+		}
+
+		seen := make(map[int]bool)
+		for time.Now().Before(deadline.Add(5 * time.Second)) {
+			select {
+			case msg := <-b.OutputChan:
+				if n, err := strconv.Atoi(strings.TrimSpace(msg.Content)); err == nil {
+					seen[n] = true
+				}
+				continue
+			default:
+			}
+			if !b.IsRunning() && len(b.OutputChan) == 0 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		for i := 1; i <= lines; i++ {
+			if !seen[i] {
+				t.Errorf("bytecode=%v: line %d was dropped (%d of %d arrived)", bytecode, i, len(seen), lines)
+				break
+			}
+		}
+	}
+}
@@ -4,7 +4,6 @@ package tinybasic
 import (
 	"fmt"
 	"math"
-	"math/rand"
 	"strconv"
 	"strings"
 	"sync"
@@ -52,8 +51,8 @@ func (b *TinyBASIC) evalExpression(expr string) (BASICValue, error) {
 		return BASICValue{}, NewBASICError(ErrCategorySyntax, "EXPECTED_EXPRESSION", true, 0)
 	}
 	
-	// Try Expression-Level JIT first (safe, non-invasive)
-	if b.expressionJIT != nil {
+	// Try Expression-Level JIT first (safe, non-invasive); its SIN pattern assumes radians
+	if b.expressionJIT != nil && !b.angleDegrees {
 		if result, found := b.expressionJIT.TryEvaluate(expr, b.variables); found {
 			return result, nil
 		}
@@ -141,7 +140,7 @@ func isConstantExpression(expr string) bool {
 	// Check if expression contains only constants, operators, and functions
 	// For now, simple heuristic: no single letters that aren't part of known functions
 	expr = strings.ToUpper(expr)
-	knownFunctions := []string{"ABS", "SGN", "SIN", "COS", "TAN", "ATN", "LOG", "EXP", "SQR", "INT", "RND"}
+	knownFunctions := []string{"ABS", "SGN", "SIN", "COS", "TAN", "ATN", "ATAN2", "LOG", "EXP", "SQR", "INT", "RND"}
 	
 	for _, fn := range knownFunctions {
 		if strings.Contains(expr, fn+"(") {
@@ -435,6 +434,13 @@ func (p *exprParser) tokenize() error {
 				default:
 					p.tokens = append(p.tokens, token{typ: tokIdent, val: identVal, pos: identStart})
 				}
+			case c == '&': // &H hex or &O octal literal.
+				value, end, err := scanRadixLiteral(s, i)
+				if err != nil {
+					return err
+				}
+				p.tokens = append(p.tokens, token{typ: tokNumber, val: strconv.FormatFloat(value, 'f', -1, 64), pos: startPos})
+				i = end
 			default:
 				return fmt.Errorf("unexpected character '%c' at position %d", c, startPos)
 			}
@@ -567,7 +573,7 @@ func (p *exprParser) parseComparison() (BASICValue, error) {
 			return BASICValue{}, err
 		}
 
-		result, err := compareValues(left, right, op)
+		result, err := compareValues(left, right, op, p.tb.compareText)
 		if err != nil {
 			return BASICValue{}, err
 		}
@@ -734,8 +740,8 @@ func (p *exprParser) parsePrimary() (BASICValue, error) {
 		identName := tok.val                         // Keep original case for errors if needed.
 		identNameUpper := strings.ToUpper(identName) // Check if we have an array reference with parentheses
 		if p.peek().typ == tokLParen {               // Hier liegt ein Ausdruck mit Klammern vor - entweder ein Funktionsaufruf oder ein Array-Zugriff
-			knownFunctions := []string{"ABS", "ATN", "COS", "EXP", "INT", "LOG", "RND", "SGN", "SIN", "SQR", "TAN",
-				"CHR$", "LEFT$", "MID$", "RIGHT$", "STR$", "LEN", "ASC", "VAL", "EOF", "KEYSTATE", "KEYPRESSED", "COLLISION"}
+			knownFunctions := []string{"ABS", "ATN", "ATAN2", "COS", "EXP", "INT", "LOG", "RND", "SGN", "SIN", "SQR", "TAN",
+				"CHR$", "LEFT$", "MID$", "RIGHT$", "STR$", "LEN", "ASC", "VAL", "INSTR", "SPACE$", "STRING$", "REPEAT$", "FORMAT$", "HEX$", "OCT$", "BIN$", "ENVIRON$", "SYS$", "LOADVAR$", "LOADVAR", "EOF", "FRE", "KEYSTATE", "KEYPRESSED", "COLLISION"}
 
 			// Bessere Erkennung für String-Funktionen
 			isFunction := false
@@ -786,7 +792,7 @@ func (p *exprParser) parsePrimary() (BASICValue, error) {
 		// Spezielle Behandlung für INKEY$ - lock-free Zugriff
 		if identNameUpper == "INKEY$" {
 			// Direkter Zugriff ohne Locks - String-Zugriffe sind in Go atomisch
-			return BASICValue{StrValue: p.tb.currentKey, IsNumeric: false}, nil
+			return BASICValue{StrValue: p.tb.inkey(), IsNumeric: false}, nil
 		}
 		// Variable Normalisierung: Verwende gecachte Großbuchstaben-Version für bessere Performance
 		identNameUpper = getCachedVarName(identName)
@@ -898,22 +904,30 @@ func (b *TinyBASIC) evalBuiltinFunction(funcNameUpper string, args []BASICValue,
 		if argCount != 1 || !args[0].IsNumeric {
 			return BASICValue{}, errNumArg(1)
 		}
-		return BASICValue{NumValue: math.Sin(args[0].NumValue), IsNumeric: true}, nil
+		return BASICValue{NumValue: math.Sin(b.angleToRadians(args[0].NumValue)), IsNumeric: true}, nil
 	case "COS":
 		if argCount != 1 || !args[0].IsNumeric {
 			return BASICValue{}, errNumArg(1)
 		}
-		return BASICValue{NumValue: math.Cos(args[0].NumValue), IsNumeric: true}, nil
+		return BASICValue{NumValue: math.Cos(b.angleToRadians(args[0].NumValue)), IsNumeric: true}, nil
 	case "TAN":
 		if argCount != 1 || !args[0].IsNumeric {
 			return BASICValue{}, errNumArg(1)
 		}
-		return BASICValue{NumValue: math.Tan(args[0].NumValue), IsNumeric: true}, nil
+		return BASICValue{NumValue: math.Tan(b.angleToRadians(args[0].NumValue)), IsNumeric: true}, nil
 	case "ATN":
 		if argCount != 1 || !args[0].IsNumeric {
 			return BASICValue{}, errNumArg(1)
 		}
-		return BASICValue{NumValue: math.Atan(args[0].NumValue), IsNumeric: true}, nil
+		return BASICValue{NumValue: b.angleFromRadians(math.Atan(args[0].NumValue)), IsNumeric: true}, nil
+	case "ATAN2":
+		if argCount != 2 || !args[0].IsNumeric || !args[1].IsNumeric {
+			return BASICValue{}, errNumArg(2)
+		}
+		return BASICValue{NumValue: b.angleFromRadians(math.Atan2(args[0].NumValue, args[1].NumValue)), IsNumeric: true}, nil
+	case "FRE":
+		// Never an error: FRE(0), FRE(""), FRE() and any other argument work
+		return BASICValue{NumValue: b.freeMemory(args, b.variables), IsNumeric: true}, nil
 	case "EXP":
 		if argCount != 1 || !args[0].IsNumeric {
 			return BASICValue{}, errNumArg(1)
@@ -942,7 +956,7 @@ func (b *TinyBASIC) evalBuiltinFunction(funcNameUpper string, args []BASICValue,
 		}
 		if argCount == 0 {
 			// RND ohne Argument: Zufallszahl 0..1
-			return BASICValue{NumValue: rand.Float64(), IsNumeric: true}, nil
+			return BASICValue{NumValue: b.random().Float64(), IsNumeric: true}, nil
 		}
 		if !args[0].IsNumeric {
 			return BASICValue{}, errNumArg(1)
@@ -950,14 +964,14 @@ func (b *TinyBASIC) evalBuiltinFunction(funcNameUpper string, args []BASICValue,
 		param := args[0].NumValue
 		if param > 0 {
 			// Zufallszahl 0..param-1 (ganzzahlig)
-			return BASICValue{NumValue: float64(rand.Intn(int(param))), IsNumeric: true}, nil
+			return BASICValue{NumValue: float64(b.random().Intn(int(param))), IsNumeric: true}, nil
 		} else if param < 0 {
 			// Negativer Parameter - setze neuen Seed und gib Zufallszahl zurück
-			rand.Seed(int64(math.Abs(param)))
-			return BASICValue{NumValue: rand.Float64(), IsNumeric: true}, nil
+			b.seedRandom(int64(math.Abs(param)))
+			return BASICValue{NumValue: b.random().Float64(), IsNumeric: true}, nil
 		} else {
 			// param == 0: Zufallszahl 0..1
-			return BASICValue{NumValue: rand.Float64(), IsNumeric: true}, nil
+			return BASICValue{NumValue: b.random().Float64(), IsNumeric: true}, nil
 		}
 
 	// String Functions
@@ -1062,6 +1076,100 @@ func (b *TinyBASIC) evalBuiltinFunction(funcNameUpper string, args []BASICValue,
 			return BASICValue{}, errNumArg(1)
 		}
 		return BASICValue{StrValue: formatBasicFloat(args[0].NumValue), IsNumeric: false}, nil
+	case "INSTR":
+		// INSTR([start,] haystack$, needle$) - 1-based position, 0 if not found
+		start := 1
+		if argCount == 3 {
+			if !args[0].IsNumeric {
+				return BASICValue{}, errArgsRange(2, 3, "[number,] string, string")
+			}
+			start = int(math.Round(args[0].NumValue))
+			args = args[1:]
+		}
+		if len(args) != 2 || args[0].IsNumeric || args[1].IsNumeric {
+			return BASICValue{}, errArgsRange(2, 3, "[number,] string, string")
+		}
+		if start < 1 {
+			return BASICValue{}, fmt.Errorf("%w: INSTR start position must be >= 1 at pos %d", ErrInvalidExpression, namePos)
+		}
+		pos := basicInstr(start, args[0].StrValue, args[1].StrValue)
+		return BASICValue{NumValue: float64(pos), IsNumeric: true}, nil
+	case "HEX$", "OCT$", "BIN$":
+		if argCount != 1 || !args[0].IsNumeric {
+			return BASICValue{}, errNumArg(1)
+		}
+		str, err := basicRadix(args[0].NumValue, radixFunctionBases[funcNameUpper])
+		if err != nil {
+			return BASICValue{}, fmt.Errorf("%w: %s %v at pos %d", ErrInvalidExpression, funcNameUpper, err, namePos)
+		}
+		return BASICValue{StrValue: str, IsNumeric: false}, nil
+	case "SPACE$":
+		if argCount != 1 || !args[0].IsNumeric {
+			return BASICValue{}, errNumArg(1)
+		}
+		str, err := repeatBasicChar(int(math.Round(args[0].NumValue)), ' ')
+		if err != nil {
+			return BASICValue{}, fmt.Errorf("%w: SPACE$ %v at pos %d", ErrInvalidExpression, err, namePos)
+		}
+		return BASICValue{StrValue: str, IsNumeric: false}, nil
+	case "STRING$":
+		// STRING$(n, code) or STRING$(n, "x") - the first character of a string is used
+		if argCount != 2 || !args[0].IsNumeric {
+			return BASICValue{}, errArgs("number, number or string")
+		}
+		var ch rune
+		if args[1].IsNumeric {
+			ch = rune(int(math.Round(args[1].NumValue)))
+		} else {
+			if args[1].StrValue == "" {
+				return BASICValue{}, fmt.Errorf("%w: STRING$ needs a non-empty character string at pos %d", ErrInvalidExpression, namePos)
+			}
+			ch, _ = utf8.DecodeRuneInString(args[1].StrValue)
+		}
+		str, err := repeatBasicChar(int(math.Round(args[0].NumValue)), ch)
+		if err != nil {
+			return BASICValue{}, fmt.Errorf("%w: STRING$ %v at pos %d", ErrInvalidExpression, err, namePos)
+		}
+		return BASICValue{StrValue: str, IsNumeric: false}, nil
+	case "REPEAT$":
+		// REPEAT$(s$, n) - the whole string n times, unlike STRING$
+		if argCount != 2 || args[0].IsNumeric || !args[1].IsNumeric {
+			return BASICValue{}, errArgs("a string and a number")
+		}
+		str, err := repeatString(args[0].StrValue, int(math.Round(args[1].NumValue)))
+		if err != nil {
+			return BASICValue{}, fmt.Errorf("%w: REPEAT$ %v at pos %d", ErrInvalidExpression, err, namePos)
+		}
+		return BASICValue{StrValue: str, IsNumeric: false}, nil
+	case "FORMAT$":
+		if argCount < 1 || args[0].IsNumeric {
+			return BASICValue{}, errArgs("a format string and its arguments")
+		}
+		str, err := basicFormat(args[0].StrValue, args[1:])
+		if err != nil {
+			return BASICValue{}, fmt.Errorf("%w: FORMAT$ %v at pos %d", ErrInvalidExpression, err, namePos)
+		}
+		return BASICValue{StrValue: str, IsNumeric: false}, nil
+	case "ENVIRON$":
+		if argCount != 1 || args[0].IsNumeric {
+			return BASICValue{}, errStrArg(1)
+		}
+		return BASICValue{StrValue: b.environ(args[0].StrValue), IsNumeric: false}, nil
+	case "SYS$":
+		if argCount != 1 || args[0].IsNumeric {
+			return BASICValue{}, errStrArg(1)
+		}
+		result, err := b.sysCall(args[0].StrValue)
+		if err != nil {
+			return BASICValue{}, fmt.Errorf("%w at pos %d", err, namePos)
+		}
+		return BASICValue{StrValue: result, IsNumeric: false}, nil
+	case "LOADVAR$", "LOADVAR":
+		result, err := b.loadVar(args, funcNameUpper == "LOADVAR")
+		if err != nil {
+			return BASICValue{}, fmt.Errorf("%w at pos %d", err, namePos)
+		}
+		return result, nil
 	case "VAL":
 		if argCount != 1 || args[0].IsNumeric {
 			return BASICValue{}, errStrArg(1)
@@ -1282,7 +1390,8 @@ func parseListRange(args string) (int, int, error) {
 		endLine = startLine
 	}
 	if endLine < startLine {
-		return 0, 0, fmt.Errorf("%w: LIST: End line (%d) cannot be before start line (%d)", ErrSyntaxError, endLine, startLine)
+		// LIST 200-100 lists the same lines as LIST 100-200
+		startLine, endLine = endLine, startLine
 	}
 	return startLine, endLine, nil
 }
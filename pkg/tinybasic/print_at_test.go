@@ -0,0 +1,64 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// TestSplitPrintAt checks which PRINT arguments start with an AT clause
+func TestSplitPrintAt(t *testing.T) {
+	tests := []struct {
+		args, coords, rest string
+		ok                 bool
+	}{
+		{`AT(10,5); "HI"`, "10,5", `"HI"`, true},
+		{`AT (X+1, Y) "HI"; A`, "X+1, Y", `"HI"; A`, true},
+		{`AT(LEN("(,)"), 2)`, `LEN("(,)"), 2`, "", true},
+		{`ATN(1)`, "", "", false},
+		{`ATX; "HI"`, "", "", false},
+		{`"AT(1,1)"`, "", "", false},
+		{`AT(1,2`, "", "", false},
+	}
+	for _, tt := range tests {
+		coords, rest, ok := splitPrintAt(tt.args)
+		if ok != tt.ok || coords != tt.coords || rest != tt.rest {
+			t.Errorf("splitPrintAt(%q) = %q, %q, %v; want %q, %q, %v", tt.args, coords, rest, ok, tt.coords, tt.rest, tt.ok)
+		}
+	}
+}
+
+// TestPrintAt checks that PRINT AT positions the cursor before the text and
+// rejects coordinates LOCATE would reject
+func TestPrintAt(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		b.useBytecode = bytecode
+		b.Execute(`10 LET S = 42`)
+		b.Execute(`20 PRINT AT(10,5); "SCORE:"; S`)
+		b.Execute("RUN")
+		waitUntilStopped(t, b)
+
+		locate, text := -1, -1
+		for i := 0; len(b.OutputChan) > 0; i++ {
+			msg := <-b.OutputChan
+			switch {
+			case msg.Type == shared.MessageTypeLocate && msg.Content == "9,4":
+				locate = i
+			case msg.Type == shared.MessageTypeText && strings.Contains(msg.Content, "SCORE:"):
+				text = i
+			}
+		}
+		if locate < 0 || text < locate {
+			t.Errorf("bytecode=%v: expected LOCATE 9,4 before the text, got locate=%d text=%d", bytecode, locate, text)
+		}
+	}
+
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode, `10 PRINT AT(0,1); "X"`)
+		if !strings.Contains(out, "INVALID PARAMETER VALUE") {
+			t.Errorf("bytecode=%v: PRINT AT(0,1) should fail like LOCATE 0,1: %q", bytecode, out)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestPrintShorthandDirect checks "?" with and without a space in direct mode
+func TestPrintShorthandDirect(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"? 2+2", "4"},
+		{"?2+2", "4"},
+		{"?\"HI\"", "HI"},
+		{"A$ = \"?\": ?A$", "?"},
+	}
+	for _, tt := range tests {
+		b := NewTinyBASIC(nil)
+		var sb strings.Builder
+		for _, msg := range b.Execute(tt.input) {
+			sb.WriteString(msg.Content)
+		}
+		if !strings.Contains(sb.String(), tt.want) {
+			t.Errorf("%s: output %q does not contain %q", tt.input, sb.String(), tt.want)
+		}
+	}
+}
+
+// TestPrintShorthandProgram checks "?" inside programs for both execution paths
+func TestPrintShorthandProgram(t *testing.T) {
+	program := []string{
+		"10 ?\"X\";:?5",
+		"20 A$ = \"WHY?\": ? A$",
+		"30 IF 1 = 1 THEN ?\"YES\"",
+	}
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode, program...)
+		for _, want := range []string{"X", "5", "WHY?", "YES"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("bytecode=%v: output %q does not contain %q", bytecode, out, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// printOutput runs cmdPrint and returns the concatenated text it produced
+func printOutput(t *testing.T, b *TinyBASIC, args string) string {
+	t.Helper()
+	if err := b.cmdPrint(args); err != nil {
+		t.Fatalf("PRINT %s failed: %v", args, err)
+	}
+	var sb strings.Builder
+	for len(b.OutputChan) > 0 {
+		msg := <-b.OutputChan
+		if msg.Type == shared.MessageTypeText {
+			sb.WriteString(msg.Content)
+		}
+	}
+	return sb.String()
+}
+
+// TestPrintZones checks that commas in PRINT advance to the next print zone
+func TestPrintZones(t *testing.T) {
+	b := NewTinyBASIC(nil)
+
+	got := strings.TrimRight(printOutput(t, b, `1, 22, "ABC"`), "\n")
+	if want := "1             22            ABC"; got != want {
+		t.Errorf("default zones: got %q, want %q", got, want)
+	}
+
+	// An item longer than one zone skips to the zone after the one it overran
+	got = printOutput(t, b, `"LONGER TEXT HERE", 5,`)
+	if want := "LONGER TEXT HERE            5             "; got != want {
+		t.Errorf("trailing comma: got %q, want %q", got, want)
+	}
+	if b.cursorX != 42 {
+		t.Errorf("cursorX after trailing comma = %d, want 42", b.cursorX)
+	}
+	printOutput(t, b, "")
+
+	b.SetPrintZoneWidth(8)
+	got = strings.TrimRight(printOutput(t, b, `"A", "BB", "CCC"`), "\n")
+	if want := "A       BB      CCC"; got != want {
+		t.Errorf("custom zones: got %q, want %q", got, want)
+	}
+}
@@ -2,6 +2,7 @@ package tinybasic
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,9 +24,13 @@ func (b *TinyBASIC) cmdNew(args string) error {
 	b.forLoops = b.forLoops[:0]
 	b.data = make([]string, 0)
 	b.dataPointer = 0
+	b.angleDegrees = false
+	b.compareText = false
 	b.currentLine = 0
 	b.running = false
 	b.inputVar = ""
+	b.discardPause()
+	b.soundQueue.flush()
 	b.closeAllFiles()
 
 	return nil
@@ -34,18 +39,27 @@ func (b *TinyBASIC) cmdNew(args string) error {
 // cmdRun starts asynchronous program execution.
 // Optionally accepts a filename: RUN "filename.bas"
 func (b *TinyBASIC) cmdRun(args string) (string, error) {
-	// If filename provided, load it first
-	if args != "" {
+	return b.startRun("RUN", args)
+}
+
+// startRun implements RUN and BENCH, which only differ in the summary BENCH
+// prints when the program ends
+func (b *TinyBASIC) startRun(command, args string) (string, error) {
+	// RUN <line> starts at that line, anything else is a filename to load first
+	startLine := 0
+	if lineNum, err := strconv.Atoi(strings.TrimSpace(args)); err == nil {
+		startLine = lineNum
+	} else if args != "" {
 		// Parse filename argument
 		filenameExpr := strings.TrimSpace(args)
 		filenameVal, err := b.evalExpression(filenameExpr)
 		if err != nil || filenameVal.IsNumeric {
-			return "", NewBASICError(ErrCategoryEvaluation, "INVALID_EXPRESSION", b.currentLine == 0, b.currentLine).WithCommand("RUN").WithUsageHint("Usage: RUN or RUN \"filename.bas\"")
+			return "", NewBASICError(ErrCategoryEvaluation, "INVALID_EXPRESSION", b.currentLine == 0, b.currentLine).WithCommand(command).WithUsageHint("Usage: " + command + ", " + command + " line or " + command + " \"filename.bas\"")
 		}
 
 		filename := filenameVal.StrValue
 		if filename == "" {
-			return "", NewBASICError(ErrCategorySyntax, "MISSING_FILENAME", b.currentLine == 0, b.currentLine).WithCommand("RUN")
+			return "", NewBASICError(ErrCategorySyntax, "MISSING_FILENAME", b.currentLine == 0, b.currentLine).WithCommand(command)
 		}
 
 		// Load the file first
@@ -58,17 +72,24 @@ func (b *TinyBASIC) cmdRun(args string) (string, error) {
 	// Nur Ausführungszustand zurücksetzen, nicht das geladene Programm
 	b.ResetExecutionState()
 	if len(b.program) == 0 {
-		return "", NewBASICError(ErrCategoryExecution, "NO_PROGRAM_LOADED", true, 0).WithCommand("RUN")
+		return "", NewBASICError(ErrCategoryExecution, "NO_PROGRAM_LOADED", true, 0).WithCommand(command)
 	}
 	b.rebuildProgramLines()
 	if len(b.programLines) == 0 {
-		return "", NewBASICError(ErrCategoryExecution, "NO_PROGRAM_LINES", true, 0).WithCommand("RUN")
+		return "", NewBASICError(ErrCategoryExecution, "NO_PROGRAM_LINES", true, 0).WithCommand(command)
 	} // Rebuild DATA statements before running
 	b.rebuildData()
 
 	b.currentLine = b.programLines[0]
+	if startLine != 0 {
+		if _, exists := b.program[startLine]; !exists {
+			return "", NewBASICError(ErrCategoryRuntime, "LINE_NOT_FOUND", true, 0).WithCommand(command)
+		}
+		b.currentLine = startLine
+	}
 	b.running = true // Reset cursor state at start of program
 	b.printCursorOnSameLine = false
+	b.cursorX = 0
 
 	// Resetze INPUT_CONTROL enable Flag für neuen RUN
 	b.inputControlEnableSent = false
@@ -79,6 +100,14 @@ func (b *TinyBASIC) cmdRun(args string) (string, error) {
 	// Send empty line to separate RUN command from program output
 	b.sendEmptyLine() // Send empty line for separation
 
+	b.steps = b.newStepBudget()
+
+	// BENCH times the run from here, after loading and resetting
+	b.benchStart = time.Time{}
+	if command == "BENCH" {
+		b.benchStart = time.Now()
+	}
+
 	// Try bytecode execution first, fall back to interpreted if needed
 	if b.useBytecode {
 		err := b.compileProgramIfNeeded()
@@ -105,34 +134,53 @@ func (b *TinyBASIC) cmdList(args string) error {
 		b.sendMessageWrapped(shared.MessageTypeText, "Program empty.")
 		return nil
 	}
-	startLine, endLine, _ := parseListRange(args)
-	// parseListRange returns always nil error in current implementation
+	startLine, endLine, err := parseListRange(args)
+	if err != nil {
+		return NewBASICError(ErrCategorySyntax, "INVALID_LINE_NUMBER", b.currentLine == 0, b.currentLine).
+			WithCommand("LIST").WithUsageHint("LIST [line] | [start]-[end]")
+	}
+
+	var listing []string
+	for _, lineNum := range b.programLines {
+		if lineNum > endLine {
+			break
+		}
+		if lineNum >= startLine {
+			listing = append(listing, fmt.Sprintf("%d %s", lineNum, formatListLine(b.program[lineNum])))
+		}
+	}
+	if len(listing) == 0 {
+		if args != "" {
+			b.sendMessageWrapped(shared.MessageTypeText, "No lines found in specified range.")
+		}
+		return nil
+	}
+
+	// Im Direktmodus blättert der Pager des OS durch lange Listings
+	if b.currentLine == 0 && b.os != nil && b.sessionID != "" {
+		for _, msg := range b.os.PageLines(b.sessionID, "LIST", listing) {
+			b.sendMessageObject(msg)
+		}
+		return nil
+	}
 
 	// Sammle alle Zeilen in einem Buffer und sende sie in Blöcken
 	var outputBuffer strings.Builder
-	linesListed := 0
 	linesSinceLastSend := 0
 	const linesPerBatch = 50 // Sende in 50-Zeilen-Blöcken
 
-	for _, lineNum := range b.programLines {
-		if lineNum >= startLine && lineNum <= endLine {
-			lineStr := fmt.Sprintf("%d %s\n", lineNum, b.program[lineNum])
-			outputBuffer.WriteString(lineStr)
-			linesListed++
-			linesSinceLastSend++
-
-			// Sende in Blöcken von 50 Zeilen
-			if linesSinceLastSend >= linesPerBatch {
-				b.sendMessage(shared.MessageTypeText, strings.TrimSuffix(outputBuffer.String(), "\n"))
-				outputBuffer.Reset()
-				linesSinceLastSend = 0
-
-				// Kurze Pause zwischen Blöcken, um WebSocket nicht zu überlasten
-				time.Sleep(10 * time.Millisecond)
-			}
-		}
-		if lineNum > endLine {
-			break
+	for _, line := range listing {
+		outputBuffer.WriteString(line + "\n")
+		linesSinceLastSend++
+
+		// Sende in Blöcken von 50 Zeilen
+		if linesSinceLastSend >= linesPerBatch {
+			b.sendMessage(shared.MessageTypeText, strings.TrimSuffix(outputBuffer.String(), "\n"))
+			outputBuffer.Reset()
+			linesSinceLastSend = 0
+
+			// Kurze Pause zwischen Blöcken, um WebSocket nicht zu überlasten
+			time.Sleep(10 * time.Millisecond)
 		}
 	}
 
@@ -140,9 +188,48 @@ func (b *TinyBASIC) cmdList(args string) error {
 	if outputBuffer.Len() > 0 {
 		b.sendMessage(shared.MessageTypeText, strings.TrimSuffix(outputBuffer.String(), "\n"))
 	}
+	return nil
+}
+
+// cmdDelete removes the program lines in a range: DELETE 100, DELETE 100-200,
+// DELETE 100- or DELETE -200. Only allowed in direct mode. Assumes lock is held.
+func (b *TinyBASIC) cmdDelete(args string) error {
+	if b.running || b.currentLine != 0 {
+		return NewBASICError(ErrCategoryExecution, "COMMAND_NOT_IN_PROG", b.currentLine == 0, b.currentLine).WithCommand("DELETE")
+	}
+	// A bare DELETE or "DELETE -" would wipe the whole program, NEW does that
+	if strings.Trim(args, " -,") == "" {
+		return NewBASICError(ErrCategorySyntax, "MISSING_ARGUMENT", true, 0).
+			WithCommand("DELETE").WithUsageHint("DELETE line | start-end | start- | -end")
+	}
+	startLine, endLine, err := parseListRange(args)
+	if err != nil {
+		return NewBASICError(ErrCategorySyntax, "INVALID_LINE_NUMBER", true, 0).
+			WithCommand("DELETE").WithUsageHint("DELETE line | start-end | start- | -end")
+	}
 
-	if linesListed == 0 && args != "" {
+	deleted := 0
+	for _, lineNum := range b.programLines {
+		if lineNum > endLine {
+			break
+		}
+		if lineNum >= startLine {
+			delete(b.program, lineNum)
+			deleted++
+		}
+	}
+	if deleted > 0 {
+		b.rebuildProgramLines()
+		b.rebuildData()
+	}
+
+	switch deleted {
+	case 0:
 		b.sendMessageWrapped(shared.MessageTypeText, "No lines found in specified range.")
+	case 1:
+		b.sendMessageWrapped(shared.MessageTypeText, "1 line deleted.")
+	default:
+		b.sendMessageWrapped(shared.MessageTypeText, fmt.Sprintf("%d lines deleted.", deleted))
 	}
 	return nil
 }
@@ -184,6 +271,8 @@ func (b *TinyBASIC) cmdExit(args string) error {
 		},
 	}
 	b.sendMessageObject(musicStopMsg) // Don't check return value, as we're exiting anyway
+	b.resetMusic()
+	b.resetWidth() // A WIDTH typed in direct mode must not outlive BASIC
 
 	// Signal to the caller (Execute method) that EXIT was called.
 	// The Execute method will then be responsible for sending appropriate messages
@@ -222,6 +311,19 @@ func (b *TinyBASIC) cmdVars(args string) error {
 func (b *TinyBASIC) cmdEditor(args string) error {
 	filename := strings.TrimSpace(args)
 
+	// Handle special case: if no filename specified, edit the current program
+	if filename == "" {
+		b.openEditor("", "<current program>", b.getProgramAsText())
+	} else {
+		b.openEditor(filename, "", "")
+	}
+	return nil
+}
+
+// openEditor starts the full-screen editor for filename. If title is not
+// empty, the editor starts with content instead of the file and shows title
+// as its name; saving then asks for a filename.
+func (b *TinyBASIC) openEditor(filename, title, content string) {
 	// Get the editor manager
 	editorManager := editor.GetEditorManager()
 
@@ -240,12 +342,10 @@ func (b *TinyBASIC) cmdEditor(args string) error {
 
 	editorInstance := editorManager.StartEditor(config)
 
-	// Handle special case: if no filename specified, edit the current program
-	if filename == "" {
-		// Load current program into editor AFTER initialization
-		programText := b.getProgramAsText()
-		editorInstance.SetContent(programText)
-		editorInstance.SetFilename("<current program>")
+	if title != "" {
+		// Load content into editor AFTER initialization
+		editorInstance.SetContent(content)
+		editorInstance.SetFilename(title)
 	}
 
 	// Send editor start message to frontend
@@ -301,8 +401,6 @@ func (b *TinyBASIC) cmdEditor(args string) error {
 			}
 		}
 	}()
-
-	return nil
 }
 
 // getProgramAsText returns the current BASIC program as text
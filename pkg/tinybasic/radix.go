@@ -0,0 +1,51 @@
+package tinybasic
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// radixFunctionBases maps HEX$, OCT$ and BIN$ to the base they write
+var radixFunctionBases = map[string]int{"HEX$": 16, "OCT$": 8, "BIN$": 2}
+
+// basicRadix implements HEX$, OCT$ and BIN$: n is rounded to an integer and
+// written in base without a prefix. Negative numbers are written in 32-bit
+// two's complement like the contents of a 32-bit register, so HEX$(-1) is
+// FFFFFFFF. Classic 16-bit BASICs did the same with 16 bits (FFFF).
+func basicRadix(n float64, base int) (string, error) {
+	v := math.Round(n)
+	if math.IsNaN(v) || v < math.MinInt32 || v > math.MaxUint32 {
+		return "", fmt.Errorf("value must be between %d and %d, got %s", math.MinInt32, uint32(math.MaxUint32), formatBasicFloat(n))
+	}
+	return strings.ToUpper(strconv.FormatUint(uint64(uint32(int64(v))), base)), nil
+}
+
+// scanRadixLiteral reads an &H (hex) or &O (octal) literal starting with the
+// '&' at s[start] and returns its value and the index behind it. Literals are
+// unsigned 32-bit values: &HFFFFFFFF is 4294967295, write -&H1 for -1.
+func scanRadixLiteral(s string, start int) (float64, int, error) {
+	i := start + 1
+	base := 0
+	if i < len(s) {
+		switch s[i] {
+		case 'H', 'h':
+			base = 16
+		case 'O', 'o':
+			base = 8
+		}
+	}
+	if base == 0 {
+		return 0, i, fmt.Errorf("expected &H or &O literal at position %d", start)
+	}
+	end := i + 1
+	for end < len(s) && (isAlphaNum(s[end]) || s[end] == '_') {
+		end++
+	}
+	value, err := strconv.ParseUint(s[i+1:end], base, 32)
+	if err != nil {
+		return 0, end, fmt.Errorf("invalid number '%s' at position %d", s[start:end], start)
+	}
+	return float64(value), end, nil
+}
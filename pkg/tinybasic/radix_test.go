@@ -0,0 +1,100 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRadixFunctions tests HEX$, OCT$ and BIN$ across bases, with negative
+// numbers in 32-bit two's complement
+func TestRadixFunctions(t *testing.T) {
+	basic := NewTestBasic()
+
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{`HEX$(255)`, "FF"},
+		{`HEX$(0)`, "0"},
+		{`HEX$(4095.6)`, "1000"},
+		{`HEX$(-1)`, "FFFFFFFF"},
+		{`HEX$(-256)`, "FFFFFF00"},
+		{`HEX$(4294967295)`, "FFFFFFFF"},
+		{`HEX$(-2147483648)`, "80000000"},
+		{`OCT$(8)`, "10"},
+		{`OCT$(511)`, "777"},
+		{`OCT$(-1)`, "37777777777"},
+		{`BIN$(5)`, "101"},
+		{`BIN$(0)`, "0"},
+		{`BIN$(-2)`, "11111111111111111111111111111110"},
+		{`"&H" + HEX$(48879)`, "&HBEEF"},
+	}
+	for _, test := range tests {
+		result, err := basic.evalExpression(test.expr)
+		if err != nil {
+			t.Fatalf("Unexpected error for '%s': %v", test.expr, err)
+		}
+		if result.IsNumeric || result.StrValue != test.expected {
+			t.Errorf("For '%s': expected %q, got %+v", test.expr, test.expected, result)
+		}
+	}
+
+	for _, expr := range []string{`HEX$("A")`, `HEX$(1, 2)`, `HEX$(4294967296)`, `OCT$(-2147483649)`, `BIN$(SQR(-1))`} {
+		if _, err := basic.evalExpression(expr); err == nil {
+			t.Errorf("Expected error for '%s', but got none", expr)
+		}
+	}
+}
+
+// TestRadixLiterals tests &H and &O literals in expressions
+func TestRadixLiterals(t *testing.T) {
+	basic := NewTestBasic()
+
+	tests := []struct {
+		expr     string
+		expected float64
+	}{
+		{`&HFF`, 255},
+		{`&hff`, 255},
+		{`&H10 + 1`, 17},
+		{`&O17`, 15},
+		{`&o777`, 511},
+		{`-&H1`, -1},
+		{`&HFFFFFFFF`, 4294967295},
+		{`&H0F * &O10`, 120},
+		{`LEN(CHR$(&H41))`, 1},
+	}
+	for _, test := range tests {
+		result, err := basic.evalExpression(test.expr)
+		if err != nil {
+			t.Fatalf("Unexpected error for '%s': %v", test.expr, err)
+		}
+		if !result.IsNumeric || result.NumValue != test.expected {
+			t.Errorf("For '%s': expected %v, got %+v", test.expr, test.expected, result)
+		}
+	}
+
+	for _, expr := range []string{`&HFG`, `&O8`, `&H`, `&X1`, `&H100000000`, `1 & 2`} {
+		if _, err := basic.evalExpression(expr); err == nil {
+			t.Errorf("Expected error for '%s', but got none", expr)
+		}
+	}
+}
+
+// TestRadixProgram runs the functions and literals with the bytecode VM and
+// the interpreter
+func TestRadixProgram(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode,
+			`10 A = &HFF + &O10`,
+			`20 PRINT HEX$(A); " "; OCT$(A); " "; BIN$(A)`,
+			`30 PRINT HEX$(-1); " "; HEX$(&HCAFE)`,
+			`40 PRINT HEX$(4294967296)`,
+		)
+		for _, want := range []string{"107 407 100000111", "FFFFFFFF CAFE", "ERROR"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("bytecode=%v: output %q does not contain %q", bytecode, out, want)
+			}
+		}
+	}
+}
@@ -9,16 +9,17 @@ import (
 )
 
 // cmdRandomize initializes the random number generator with a new seed.
-// With no argument, uses the current time as seed.
-// With an argument, uses the provided value as seed.
+// With no argument or TIMER, uses the current time as seed.
+// With an argument, uses the provided value as seed, so RND repeats the same
+// sequence for the same seed.
 // Assumes lock is held.
 func (b *TinyBASIC) cmdRandomize(args string) error {
 	args = strings.TrimSpace(args)
 
 	var seed int64
 
-	if args == "" {
-		// Wenn kein Argument angegeben ist, verwende aktuelle Zeit als Seed
+	if args == "" || strings.EqualFold(args, "TIMER") {
+		// Wenn kein Argument oder TIMER angegeben ist, verwende aktuelle Zeit als Seed
 		seed = time.Now().UnixNano()
 		log.Printf("[INFO] RANDOMIZE using current time as seed: %d", seed)
 	} else {
@@ -35,8 +36,22 @@ func (b *TinyBASIC) cmdRandomize(args string) error {
 		seed = int64(val.NumValue)
 		log.Printf("[INFO] RANDOMIZE using provided seed: %d", seed)
 	}
-	// Setze den neuen Seed für den Zufallszahlengenerator
-	rand.Seed(seed)
+	// Setze den neuen Seed für den Zufallszahlengenerator dieser Sitzung
+	b.seedRandom(seed)
 
 	return nil
 }
+
+// seedRandom restarts the session's random source with seed
+func (b *TinyBASIC) seedRandom(seed int64) {
+	b.rng = rand.New(rand.NewSource(seed))
+}
+
+// random returns the session's random source. It is never shared with other
+// sessions, so one program's RANDOMIZE does not change another's sequence.
+func (b *TinyBASIC) random() *rand.Rand {
+	if b.rng == nil {
+		b.seedRandom(time.Now().UnixNano())
+	}
+	return b.rng
+}
@@ -0,0 +1,57 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRandomizeSeedIsReproducible runs the same program twice with a fixed
+// seed and expects the same numbers, with the bytecode VM and the interpreter
+func TestRandomizeSeedIsReproducible(t *testing.T) {
+	program := []string{
+		"10 RANDOMIZE 42",
+		"20 FOR I = 1 TO 5",
+		"30 PRINT RND(0)",
+		"40 NEXT I",
+	}
+	for _, bytecode := range []bool{false, true} {
+		first := runTrapProgram(t, bytecode, program...)
+		second := runTrapProgram(t, bytecode, program...)
+		if strings.Count(first, "\n") < 5 || strings.Contains(first, "ERROR") {
+			t.Fatalf("bytecode=%v: unexpected output %q", bytecode, first)
+		}
+		if first != second {
+			t.Errorf("bytecode=%v: RANDOMIZE 42 gave different sequences:\n%s\n%s", bytecode, first, second)
+		}
+	}
+}
+
+// TestRandomizeIsPerSession checks that seeding one session does not change
+// the sequence of another
+func TestRandomizeIsPerSession(t *testing.T) {
+	a := NewTinyBASIC(nil)
+	b := NewTinyBASIC(nil)
+	a.Execute("RANDOMIZE 7")
+	b.Execute("RANDOMIZE 7")
+	first := directOutput(a, "PRINT RND(1000000)")
+	a.Execute("RANDOMIZE 99")
+	if second := directOutput(b, "PRINT RND(1000000)"); second != first {
+		t.Errorf("session b got %q after RANDOMIZE 7, want %q", second, first)
+	}
+}
+
+// TestRandomizeTimer checks that RANDOMIZE TIMER seeds from the clock instead
+// of a variable called TIMER
+func TestRandomizeTimer(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode,
+			"10 RANDOMIZE 0",
+			"20 A = RND(1000000)",
+			"30 RANDOMIZE TIMER",
+			"40 IF RND(1000000) <> A THEN PRINT \"TIMER\"",
+		)
+		if !strings.HasPrefix(out, "TIMER\n") {
+			t.Errorf("bytecode=%v: RANDOMIZE TIMER output %q", bytecode, out)
+		}
+	}
+}
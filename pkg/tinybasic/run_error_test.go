@@ -0,0 +1,35 @@
+package tinybasic
+
+import "testing"
+
+// TestLastErrorReportsFailingLine checks that LastError points at the line that
+// terminated RUN and is cleared by the next clean run.
+func TestLastErrorReportsFailingLine(t *testing.T) {
+	b := NewTinyBASIC(nil)
+
+	for _, line := range []string{
+		"10 A = 1",
+		"20 B = A + UNDEFINED",
+		"30 END",
+	} {
+		b.Execute(line)
+	}
+	b.Execute("RUN")
+	waitUntilStopped(t, b)
+
+	line, message := b.LastError()
+	if line != 20 {
+		t.Errorf("expected error in line 20, got %d (%q)", line, message)
+	}
+	if message == "" {
+		t.Error("expected an error message")
+	}
+
+	b.Execute("20 B = A + 1")
+	b.Execute("RUN")
+	waitUntilStopped(t, b)
+
+	if line, message := b.LastError(); line != 0 || message != "" {
+		t.Errorf("expected no error after clean run, got line %d (%q)", line, message)
+	}
+}
@@ -0,0 +1,59 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+)
+
+// runFromLine loads the program, sets X and executes the given RUN command
+func runFromLine(t *testing.T, bytecode bool, run string, lines ...string) (*TinyBASIC, string) {
+	t.Helper()
+	b := NewTinyBASIC(nil)
+	b.useBytecode = bytecode
+	for _, line := range lines {
+		b.Execute(line)
+	}
+	b.Execute("LET X = 99")
+	for len(b.OutputChan) > 0 {
+		<-b.OutputChan
+	}
+	var sb strings.Builder
+	for _, msg := range b.Execute(run) {
+		sb.WriteString(msg.Content + "\n")
+	}
+	waitUntilStopped(t, b)
+	for len(b.OutputChan) > 0 {
+		sb.WriteString((<-b.OutputChan).Content + "\n")
+	}
+	return b, sb.String()
+}
+
+// TestRunFromLine starts a program in the middle with both backends
+func TestRunFromLine(t *testing.T) {
+	program := []string{
+		`10 PRINT "START"`,
+		`20 END`,
+		`200 PRINT "SUB"`,
+		`210 PRINT "AFTER"`,
+	}
+	for _, bytecode := range []bool{false, true} {
+		b, out := runFromLine(t, bytecode, "RUN 200", program...)
+		if strings.Contains(out, "START") {
+			t.Errorf("bytecode=%v: RUN 200 executed line 10: %q", bytecode, out)
+		}
+		for _, want := range []string{"SUB", "AFTER"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("bytecode=%v: output %q does not contain %q", bytecode, out, want)
+			}
+		}
+		// Variables are cleared like with a plain RUN
+		if _, exists := b.variables["X"]; exists {
+			t.Errorf("bytecode=%v: RUN 200 kept variable X", bytecode)
+		}
+
+		_, out = runFromLine(t, bytecode, "RUN 150", program...)
+		if !strings.Contains(out, "LINE NOT FOUND") || strings.Contains(out, "START") {
+			t.Errorf("bytecode=%v: RUN to a missing line should fail, got %q", bytecode, out)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSaveOverwrite checks that SAVE asks before replacing a file in direct
+// mode, and that F and new files skip the question
+func TestSaveOverwrite(t *testing.T) {
+	fs := memoryFS{"game.bas": "10 PRINT \"OLD\"\n"}
+	b := NewTinyBASIC(nil)
+	b.fs = fs
+	b.Execute(`10 PRINT "NEW"`)
+	drainText(b)
+
+	out := messagesText(b.Execute(`SAVE "game"`))
+	if !strings.Contains(out, "Overwrite (y/n)?") || strings.Contains(out, "OK") {
+		t.Fatalf("expected the overwrite question without OK, got %q", out)
+	}
+	if !b.IsWaitingForInput() {
+		t.Fatal("SAVE should wait for the answer")
+	}
+	b.Execute("n")
+	if out := drainText(b); !strings.Contains(out, "Not saved.") || !strings.Contains(out, "OK") {
+		t.Errorf("answer n: got %q", out)
+	}
+	if fs["game.bas"] != "10 PRINT \"OLD\"\n" {
+		t.Errorf("answer n changed the file to %q", fs["game.bas"])
+	}
+
+	b.Execute(`SAVE "game"`)
+	b.Execute("Y")
+	if out := drainText(b); !strings.Contains(out, "Saved 1 lines.") {
+		t.Errorf("answer y: got %q", out)
+	}
+	if fs["game.bas"] != "10 PRINT \"NEW\"\n" {
+		t.Errorf("answer y: file is %q", fs["game.bas"])
+	}
+
+	fs["game.bas"] = "OLD"
+	if out := messagesText(b.Execute(`SAVE "game", f`)); strings.Contains(out, "Overwrite") || !strings.Contains(out, "OK") {
+		t.Errorf("SAVE with F asked: %q", out)
+	}
+	if fs["game.bas"] != "10 PRINT \"NEW\"\n" {
+		t.Errorf("SAVE with F: file is %q", fs["game.bas"])
+	}
+	if out := messagesText(b.Execute(`SAVE "other"`)); strings.Contains(out, "Overwrite") || fs["other.bas"] == "" {
+		t.Errorf("SAVE of a new file asked or did not write: %q", out)
+	}
+
+	if err := b.cmdSave(`"game", X`); err == nil {
+		t.Error("expected an error for an unknown SAVE mode")
+	}
+}
+
+// TestSaveAppend checks SAVE "name", A
+func TestSaveAppend(t *testing.T) {
+	fs := memoryFS{"parts.bas": "10 PRINT \"A\""}
+	b := NewTinyBASIC(nil)
+	b.fs = fs
+	b.Execute(`20 PRINT "B"`)
+	drainText(b)
+
+	if out := messagesText(b.Execute(`SAVE "parts", A`)); !strings.Contains(out, "Appended 1 lines to parts.bas.") {
+		t.Errorf("unexpected output %q", out)
+	}
+	if want := "10 PRINT \"A\"\n20 PRINT \"B\"\n"; fs["parts.bas"] != want {
+		t.Errorf("file is %q, want %q", fs["parts.bas"], want)
+	}
+	b.Execute(`SAVE "new", A`)
+	if fs["new.bas"] != "20 PRINT \"B\"\n" {
+		t.Errorf("append to a new file gave %q", fs["new.bas"])
+	}
+}
+
+// TestSaveInProgram answers the overwrite question while a program waits for it
+func TestSaveInProgram(t *testing.T) {
+	for _, answer := range []string{"y", "n"} {
+		fs := memoryFS{"out.bas": "OLD"}
+		b := NewTinyBASIC(nil)
+		b.fs = fs
+		b.Execute(`10 SAVE "out"`)
+		b.Execute(`20 PRINT "DONE"`)
+		drainText(b)
+
+		b.Execute("RUN")
+		deadline := time.Now().Add(5 * time.Second)
+		for !b.IsWaitingForInput() {
+			if time.Now().After(deadline) {
+				t.Fatal("program did not ask")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		b.ExecuteInputResponse(answer)
+		waitUntilStopped(t, b)
+		time.Sleep(20 * time.Millisecond)
+
+		out := drainText(b)
+		if !strings.Contains(out, "Overwrite (y/n)?") || !strings.Contains(out, "DONE") {
+			t.Errorf("answer %s: unexpected output %q", answer, out)
+		}
+		saved := fs["out.bas"] != "OLD"
+		if saved != (answer == "y") {
+			t.Errorf("answer %s: file is %q", answer, fs["out.bas"])
+		}
+	}
+}
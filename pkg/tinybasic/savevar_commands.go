@@ -0,0 +1,103 @@
+package tinybasic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/tinyos"
+)
+
+// SAVEVAR "key", value and LOADVAR$("key") / LOADVAR("key") keep small
+// values like high scores between runs. TinyOS stores them per user; guests
+// keep them only until their session ends. A value remembers whether it is
+// a number or a string.
+
+// saveVarUsage is the usage hint for SAVEVAR
+const saveVarUsage = `SAVEVAR "key", value`
+
+// cmdSaveVar implements SAVEVAR key$, value. Assumes lock is held.
+func (b *TinyBASIC) cmdSaveVar(args string) error {
+	parts := splitTopLevelArgs(strings.TrimSpace(args))
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return NewBASICError(ErrCategorySyntax, "INVALID_PARAMETER_COUNT", b.currentLine == 0, b.currentLine).
+			WithCommand("SAVEVAR").WithUsageHint(saveVarUsage)
+	}
+	key, err := b.evalExpression(parts[0])
+	if err != nil {
+		return WrapError(err, "SAVEVAR", b.currentLine == 0, b.currentLine)
+	}
+	value, err := b.evalExpression(parts[1])
+	if err != nil {
+		return WrapError(err, "SAVEVAR", b.currentLine == 0, b.currentLine)
+	}
+	return b.saveVar(key, value)
+}
+
+// saveVar stores value under key. It is also called by the bytecode VM with
+// the evaluated arguments.
+func (b *TinyBASIC) saveVar(key, value BASICValue) error {
+	if key.IsNumeric {
+		return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", b.currentLine == 0, b.currentLine).
+			WithCommand("SAVEVAR").WithUsageHint("The key must be a string: " + saveVarUsage)
+	}
+	if b.os == nil || b.sessionID == "" {
+		return NewBASICError(ErrCategoryExecution, "GENERAL_ERROR", b.currentLine == 0, b.currentLine).
+			WithCommand("SAVEVAR").WithUsageHint("SAVEVAR needs a TinyOS session")
+	}
+
+	err := b.os.SaveBasicVar(b.sessionID, key.StrValue, tinyos.BasicVar{Numeric: value.IsNumeric, Num: value.NumValue, Str: value.StrValue})
+	if err == nil {
+		return nil
+	}
+	category, code := ErrCategorySyntax, "INVALID_ARGUMENT"
+	switch {
+	case errors.Is(err, tinyos.ErrBasicVarTooLong):
+		category, code = ErrCategoryCommand, "TEXT_TOO_LONG"
+	case errors.Is(err, tinyos.ErrBasicVarLimit):
+		category, code = ErrCategoryResource, "MEMORY_FULL"
+	case errors.Is(err, tinyos.ErrBasicVarStore):
+		category, code = ErrCategoryFileSystem, "FILE_WRITE_ERROR"
+	}
+	return NewBASICError(category, code, b.currentLine == 0, b.currentLine).
+		WithCommand("SAVEVAR").WithUsageHint(err.Error())
+}
+
+// loadVar implements LOADVAR$(key$[, default$]) and, with numeric,
+// LOADVAR(key$[, default]) for the interpreter and the bytecode VM. A key
+// that was never saved gives the default, "" or 0 without one. LOADVAR$
+// also reads numbers as text; LOADVAR only reads numbers.
+func (b *TinyBASIC) loadVar(args []BASICValue, numeric bool) (BASICValue, error) {
+	name, kind := "LOADVAR$", "string"
+	if numeric {
+		name, kind = "LOADVAR", "number"
+	}
+	if len(args) < 1 || len(args) > 2 || args[0].IsNumeric {
+		return BASICValue{}, fmt.Errorf("%w: %s needs a key string and an optional default", ErrInvalidArguments, name)
+	}
+	result := BASICValue{IsNumeric: numeric}
+	if len(args) == 2 {
+		if args[1].IsNumeric != numeric {
+			return BASICValue{}, fmt.Errorf("%w: the default of %s must be a %s", ErrTypeMismatch, name, kind)
+		}
+		result = args[1]
+	}
+	if b.os == nil || b.sessionID == "" {
+		return BASICValue{}, fmt.Errorf("%w: %s needs a TinyOS session", ErrNoSession, name)
+	}
+
+	stored, found, err := b.os.LoadBasicVar(b.sessionID, args[0].StrValue)
+	switch {
+	case err != nil:
+		return BASICValue{}, fmt.Errorf("%s: %w", name, err)
+	case !found:
+		return result, nil
+	case numeric && !stored.Numeric:
+		return BASICValue{}, fmt.Errorf("%w: %q holds a string, use LOADVAR$", ErrTypeMismatch, args[0].StrValue)
+	case numeric:
+		return BASICValue{NumValue: stored.Num, IsNumeric: true}, nil
+	case stored.Numeric:
+		return BASICValue{StrValue: formatBasicFloat(stored.Num)}, nil
+	}
+	return BASICValue{StrValue: stored.Str}, nil
+}
@@ -0,0 +1,85 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/antibyte/retroterm/pkg/tinyos"
+)
+
+// TestSaveVarRoundTrip saves a number and a string in one run and reads them
+// back in the next, with both engines. The empty TinyOS treats the session
+// as a guest, whose values stay in memory.
+func TestSaveVarRoundTrip(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		os := &tinyos.TinyOS{}
+		b := NewTinyBASIC(nil)
+		b.os, b.sessionID = os, "savevar-session"
+
+		startSleepProgram(b, bytecode,
+			`10 SAVEVAR "HiScore", 1234.5`,
+			`20 SAVEVAR "PLAYER", "ADA" + "!"`,
+			`30 END`,
+		)
+		waitUntilStopped(t, b)
+		textOutput(b)
+
+		b.Execute("NEW")
+		startSleepProgram(b, bytecode,
+			`10 PRINT LOADVAR("HISCORE") + 1`,
+			`20 PRINT LOADVAR$("player")`,
+			`30 PRINT LOADVAR$("HISCORE")`,
+			`40 PRINT LOADVAR("LEVEL", 3) + LOADVAR("NONE")`,
+			`50 A$ = LOADVAR$("NAME")`,
+			`60 PRINT LEN(A$)`,
+			`70 PRINT LOADVAR$("NAME", "NOBODY")`,
+			`80 END`,
+		)
+		waitUntilStopped(t, b)
+		out := textOutput(b)
+		for _, want := range []string{"1235.5\n", "ADA!\n", "1234.5\n", "3\n", "0\n", "NOBODY\n"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("bytecode=%v: output %q lacks %q", bytecode, out, want)
+			}
+		}
+	}
+}
+
+// TestSaveVarErrors expects type mismatches and bad keys to stop the program
+func TestSaveVarErrors(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		for _, stmt := range []string{
+			`SAVEVAR 5, 1`,
+			`SAVEVAR "BAD KEY", 1`,
+			`SAVEVAR "K", STRING$(300, "X")`,
+			`A = LOADVAR("NAME")`,
+			`A$ = LOADVAR$("K", 1)`,
+		} {
+			b := NewTinyBASIC(nil)
+			b.os, b.sessionID = &tinyos.TinyOS{}, "savevar-session"
+			startSleepProgram(b, bytecode,
+				`10 SAVEVAR "NAME", "ADA"`,
+				`20 ON ERROR GOTO 100`,
+				`30 `+stmt,
+				`40 PRINT "NO ERROR"`,
+				`50 END`,
+				`100 PRINT "TRAPPED"`,
+			)
+			waitUntilStopped(t, b)
+			if out := textOutput(b); !strings.Contains(out, "TRAPPED") || strings.Contains(out, "NO ERROR") {
+				t.Errorf("bytecode=%v %s: output %q", bytecode, stmt, out)
+			}
+		}
+	}
+}
+
+// TestSaveVarNeedsSession checks that without TinyOS nothing is stored
+func TestSaveVarNeedsSession(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	if err := b.cmdSaveVar(`"K", 1`); err == nil {
+		t.Error("SAVEVAR without a session succeeded")
+	}
+	if _, err := b.loadVar([]BASICValue{{StrValue: "K"}}, false); err == nil {
+		t.Error("LOADVAR$ without a session succeeded")
+	}
+}
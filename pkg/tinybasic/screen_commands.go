@@ -0,0 +1,119 @@
+package tinybasic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// Double buffering of the 2D graphics layer. In SCREEN DOUBLE mode PLOT,
+// LINE, RECT, CIRCLE and the other 2D commands draw on a hidden page while
+// the screen keeps showing the last presented frame; FLIP (or SCREEN SWAP)
+// copies the hidden page to the screen in one step. The hidden page is not
+// cleared by FLIP, so a program that redraws everything clears it itself.
+// Sprites, vectors, images and particles are not buffered.
+//
+// When the program ends, is stopped or BREAKs, the mode falls back to
+// SCREEN SINGLE and the last presented frame stays visible.
+
+// screenUsage is the usage hint for SCREEN
+const screenUsage = "SCREEN DOUBLE|SINGLE|SWAP"
+
+// flipNotBufferedWarning is printed once per run when FLIP is used without
+// SCREEN DOUBLE
+const flipNotBufferedWarning = "WARNING: FLIP without SCREEN DOUBLE has no effect"
+
+// parseScreenMode returns the normalized SCREEN argument, or "" if it is not
+// a known mode
+func parseScreenMode(args string) string {
+	switch strings.ToUpper(strings.TrimSpace(args)) {
+	case "DOUBLE", "BUFFERED":
+		return "DOUBLE"
+	case "SINGLE":
+		return "SINGLE"
+	case "SWAP", "FLIP":
+		return "SWAP"
+	}
+	return ""
+}
+
+// cmdScreen implements SCREEN DOUBLE|SINGLE|SWAP. Assumes lock is held.
+func (b *TinyBASIC) cmdScreen(args string) error {
+	mode := parseScreenMode(args)
+	if mode == "" {
+		return NewBASICError(ErrCategorySyntax, "INVALID_PARAMETER_VALUE", b.currentLine == 0, b.currentLine).
+			WithCommand("SCREEN").WithUsageHint(screenUsage)
+	}
+	return b.setScreenMode(mode)
+}
+
+// cmdFlip implements FLIP. Assumes lock is held.
+func (b *TinyBASIC) cmdFlip(args string) error {
+	if strings.TrimSpace(args) != "" {
+		return NewBASICError(ErrCategorySyntax, "INVALID_PARAMETER_COUNT", b.currentLine == 0, b.currentLine).
+			WithCommand("FLIP").WithUsageHint("FLIP does not take any arguments")
+	}
+	return b.setScreenMode("SWAP")
+}
+
+// setScreenMode switches buffering on or off or presents the hidden page.
+// mode is one of the values returned by parseScreenMode. Assumes lock is held.
+func (b *TinyBASIC) setScreenMode(mode string) error {
+	switch mode {
+	case "DOUBLE", "SINGLE":
+		buffered := mode == "DOUBLE"
+		if buffered == b.screenBuffered {
+			return nil
+		}
+		b.screenBuffered = buffered
+		return b.sendScreenMessage("SCREEN_MODE", map[string]interface{}{"buffered": buffered})
+	case "SWAP":
+		if !b.screenBuffered {
+			// Not an error, so a program written for double buffering still
+			// runs; the warning is only shown once per run
+			if !b.flipWarned {
+				b.flipWarned = true
+				b.sendMessageWrapped(shared.MessageTypeText, flipNotBufferedWarning)
+			}
+			return nil
+		}
+		return b.sendScreenMessage("FLIP", nil)
+	}
+	return fmt.Errorf("unknown screen mode %q", mode)
+}
+
+// sendScreenMessage sends a double buffering command to the frontend
+func (b *TinyBASIC) sendScreenMessage(command string, params map[string]interface{}) error {
+	msg := shared.Message{
+		Type:    shared.MessageTypeGraphics,
+		Command: command,
+		Params:  params,
+	}
+	if !b.sendMessageObject(msg) {
+		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", b.currentLine == 0, b.currentLine).WithCommand("SCREEN")
+	}
+	return nil
+}
+
+// endScreenBuffer returns to SCREEN SINGLE at the end of a run. The frontend
+// keeps showing the last presented frame, so the visible page stays what the
+// program last FLIPped, also after BREAK. Assumes lock is held.
+func (b *TinyBASIC) endScreenBuffer() {
+	b.flipWarned = false
+	if b.screenBuffered {
+		b.setScreenMode("SINGLE")
+	}
+}
+
+// handleScreen is the bytecode version of SCREEN and FLIP. Operand1 is the
+// mode as returned by parseScreenMode.
+func (vm *BytecodeVM) handleScreen(inst *Instruction) error {
+	if vm.tinybasic != nil {
+		if err := vm.tinybasic.setScreenMode(inst.Operand1.(string)); err != nil {
+			return err
+		}
+	}
+	vm.pc++
+	return nil
+}
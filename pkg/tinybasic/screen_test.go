@@ -0,0 +1,118 @@
+package tinybasic
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// screenOutput collects the double buffering messages and the text output
+// sent so far
+func screenOutput(b *TinyBASIC) (screen []string, text string) {
+	var sb strings.Builder
+	for len(b.OutputChan) > 0 {
+		msg := <-b.OutputChan
+		switch {
+		case msg.Type == shared.MessageTypeGraphics && msg.Command == "SCREEN_MODE":
+			screen = append(screen, fmt.Sprintf("SCREEN_MODE %v", msg.Params["buffered"]))
+		case msg.Type == shared.MessageTypeGraphics && msg.Command == "FLIP":
+			screen = append(screen, "FLIP")
+		case msg.Type == shared.MessageTypeText:
+			sb.WriteString(msg.Content + "\n")
+		}
+	}
+	return screen, sb.String()
+}
+
+// TestScreenDoubleAndFlip checks the messages of SCREEN DOUBLE, FLIP and
+// SCREEN SWAP and that the end of the program switches back to single mode
+func TestScreenDoubleAndFlip(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startSleepProgram(b, bytecode,
+			`10 SCREEN DOUBLE`,
+			`20 PLOT 10, 10`,
+			`30 FLIP`,
+			`40 SCREEN SWAP`,
+			`50 END`,
+		)
+		waitUntilStopped(t, b)
+
+		screen, _ := screenOutput(b)
+		want := []string{"SCREEN_MODE true", "FLIP", "FLIP", "SCREEN_MODE false"}
+		if !reflect.DeepEqual(screen, want) {
+			t.Errorf("bytecode=%v: screen messages %v, want %v", bytecode, screen, want)
+		}
+	}
+}
+
+// TestFlipWithoutDoubleWarnsOnce expects FLIP outside SCREEN DOUBLE to send
+// nothing to the frontend and to warn only once per run
+func TestFlipWithoutDoubleWarnsOnce(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startSleepProgram(b, bytecode,
+			`10 FLIP`,
+			`20 FLIP`,
+			`30 PRINT "DONE"`,
+		)
+		waitUntilStopped(t, b)
+
+		screen, text := screenOutput(b)
+		if len(screen) != 0 {
+			t.Errorf("bytecode=%v: unexpected screen messages %v", bytecode, screen)
+		}
+		if n := strings.Count(text, flipNotBufferedWarning); n != 1 {
+			t.Errorf("bytecode=%v: warning shown %d times, output %q", bytecode, n, text)
+		}
+		if !strings.Contains(text, "DONE") {
+			t.Errorf("bytecode=%v: program stopped at FLIP, output %q", bytecode, text)
+		}
+	}
+}
+
+// TestBreakEndsDoubleBuffering stops a program in SCREEN DOUBLE mode and
+// expects single mode to be restored exactly once
+func TestBreakEndsDoubleBuffering(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startSleepProgram(b, bytecode,
+			`10 SCREEN DOUBLE`,
+			`20 SLEEP 60000`,
+		)
+		time.Sleep(50 * time.Millisecond)
+		b.StopExecution()
+		waitUntilStopped(t, b)
+		time.Sleep(50 * time.Millisecond)
+
+		screen, _ := screenOutput(b)
+		want := []string{"SCREEN_MODE true", "SCREEN_MODE false"}
+		if !reflect.DeepEqual(screen, want) {
+			t.Errorf("bytecode=%v: screen messages %v, want %v", bytecode, screen, want)
+		}
+		b.mu.Lock()
+		buffered := b.screenBuffered
+		b.mu.Unlock()
+		if buffered {
+			t.Errorf("bytecode=%v: still in SCREEN DOUBLE after BREAK", bytecode)
+		}
+	}
+}
+
+// TestScreenRejectsUnknownMode checks that a bad SCREEN argument is a
+// trappable error
+func TestScreenRejectsUnknownMode(t *testing.T) {
+	out := runTrapProgram(t, false,
+		`10 ON ERROR GOTO 100`,
+		`20 SCREEN TRIPLE`,
+		`30 END`,
+		`100 PRINT "BAD"`,
+	)
+	if !strings.Contains(out, "BAD") {
+		t.Errorf("SCREEN TRIPLE accepted, output %q", out)
+	}
+}
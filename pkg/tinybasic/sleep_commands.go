@@ -0,0 +1,116 @@
+package tinybasic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxSleepMillis is the longest SLEEP, the same limit as WAIT
+const maxSleepMillis = 60000
+
+// keyEventBuffer is the number of key presses SetKeyPressed queues for SLEEP.
+// Further presses are dropped until a SLEEP drains the channel.
+const keyEventBuffer = 32
+
+// cmdSleep implements SLEEP ms for the interpreter. Unlike WAIT it ends early
+// when the program is stopped and keeps key presses for INKEY$. Assumes lock
+// is held.
+func (b *TinyBASIC) cmdSleep(args string) error {
+	args = strings.TrimSpace(args)
+	if args == "" {
+		return NewBASICError(ErrCategorySyntax, "EXPECTED_EXPRESSION", b.currentLine == 0, b.currentLine).WithCommand("SLEEP")
+	}
+	val, err := b.evalExpression(args)
+	if err != nil || !val.IsNumeric || val.NumValue < 0 || val.NumValue > maxSleepMillis {
+		return NewBASICError(ErrCategorySyntax, "INVALID_NUMBER", b.currentLine == 0, b.currentLine).
+			WithCommand("SLEEP").WithUsageHint("SLEEP milliseconds (0-60000)")
+	}
+	ctx := b.ctx
+
+	b.mu.Unlock()
+	b.sleepPollingKeys(ctx, time.Duration(val.NumValue)*time.Millisecond)
+	b.mu.Lock()
+	// A cancelled sleep just returns; the run loop sees the context and stops
+	return nil
+}
+
+// sleepPollingKeys waits for d or until ctx is cancelled. Key presses that
+// arrive meanwhile are taken from keyChannel; if the key is released before
+// the program looks, the last one is kept for the next INKEY$, so a short
+// tap during the delay is not lost. Must be called without b.mu.
+func (b *TinyBASIC) sleepPollingKeys(ctx context.Context, d time.Duration) {
+	// Presses from before the sleep were visible to INKEY$ already
+	for drained := false; !drained; {
+		select {
+		case <-b.keyChannel:
+		default:
+			drained = true
+		}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	var tapped string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case key := <-b.keyChannel:
+			tapped = key
+		case <-timer.C:
+			if tapped != "" && b.currentKey == "" {
+				b.tappedKey = tapped
+			}
+			return
+		}
+	}
+}
+
+// inkey returns the value of INKEY$: the next character a KEY macro typed,
+// the key held down right now, otherwise a key tapped during the last SLEEP,
+// which is reported once
+func (b *TinyBASIC) inkey() string {
+	if typed, ok := b.fnKeys.nextTyped(); ok {
+		return typed
+	}
+	if key := b.currentKey; key != "" {
+		return key
+	}
+	key := b.tappedKey
+	b.tappedKey = ""
+	return key
+}
+
+// handleSleep is the bytecode version of SLEEP. The VM runs without b.mu, so
+// it can wait directly on the VM context.
+func (vm *BytecodeVM) handleSleep(inst *Instruction) error {
+	duration, err := vm.stack.Pop()
+	if err != nil {
+		return err
+	}
+	if !duration.IsNumeric || duration.NumValue < 0 || duration.NumValue > maxSleepMillis {
+		return fmt.Errorf("SLEEP: duration must be 0 to %d milliseconds", maxSleepMillis)
+	}
+	d := time.Duration(duration.NumValue) * time.Millisecond
+	if vm.tinybasic != nil {
+		vm.tinybasic.sleepPollingKeys(vm.ctx, d)
+	} else if vm.ctx != nil {
+		select {
+		case <-vm.ctx.Done():
+		case <-time.After(d):
+		}
+	} else {
+		time.Sleep(d)
+	}
+	if vm.ctx != nil && vm.ctx.Err() != nil {
+		// Stay on SLEEP; the run loop pauses here and CONT sleeps again
+		return nil
+	}
+	vm.pc++
+	return nil
+}
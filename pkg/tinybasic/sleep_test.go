@@ -0,0 +1,94 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// startSleepProgram loads the program and starts RUN without waiting
+func startSleepProgram(b *TinyBASIC, bytecode bool, lines ...string) {
+	b.useBytecode = bytecode
+	for _, line := range lines {
+		b.Execute(line)
+	}
+	for len(b.OutputChan) > 0 {
+		<-b.OutputChan
+	}
+	b.Execute("RUN")
+}
+
+// textOutput collects the text messages the program sent so far
+func textOutput(b *TinyBASIC) string {
+	var sb strings.Builder
+	for len(b.OutputChan) > 0 {
+		if msg := <-b.OutputChan; msg.Type == shared.MessageTypeText {
+			sb.WriteString(msg.Content + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// TestSleepKeepsTappedKey presses and releases a key during SLEEP and expects
+// INKEY$ to report it exactly once afterwards
+func TestSleepKeepsTappedKey(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startSleepProgram(b, bytecode,
+			`10 SLEEP 300`,
+			`20 LET K$ = INKEY$`,
+			`30 LET L$ = INKEY$`,
+			`40 IF K$ = "A" THEN PRINT "TAPPED"`,
+			`50 IF L$ = "" THEN PRINT "ONCE"`,
+		)
+		time.Sleep(100 * time.Millisecond)
+		b.SetKeyPressed("A")
+		b.SetKeyReleased("A")
+		waitUntilStopped(t, b)
+
+		out := textOutput(b)
+		for _, want := range []string{"TAPPED", "ONCE"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("bytecode=%v: output %q does not contain %q", bytecode, out, want)
+			}
+		}
+	}
+}
+
+// TestSleepIsInterruptible stops a program in the middle of a long SLEEP
+func TestSleepIsInterruptible(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startSleepProgram(b, bytecode,
+			`10 SLEEP 60000`,
+			`20 PRINT "AFTER"`,
+		)
+		time.Sleep(50 * time.Millisecond)
+		start := time.Now()
+		b.StopExecution()
+		waitUntilStopped(t, b)
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("bytecode=%v: stopping took %v", bytecode, elapsed)
+		}
+		if out := textOutput(b); strings.Contains(out, "AFTER") {
+			t.Errorf("bytecode=%v: program continued after SLEEP: %q", bytecode, out)
+		}
+	}
+}
+
+// TestSleepRejectsInvalidDuration checks the range shared with WAIT
+func TestSleepRejectsInvalidDuration(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		out := runTrapProgram(t, bytecode,
+			`10 ON ERROR GOTO 100`,
+			`20 SLEEP -1`,
+			`30 END`,
+			`100 PRINT "BAD"`,
+		)
+		if !strings.Contains(out, "BAD") {
+			t.Errorf("bytecode=%v: SLEEP -1 accepted, output %q", bytecode, out)
+		}
+	}
+}
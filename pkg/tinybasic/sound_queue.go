@@ -0,0 +1,110 @@
+package tinybasic
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// maxQueuedNotes bounds the SOUND WAIT queue. Like the SAY and NOISE rate
+// limits, notes beyond it are dropped silently.
+const maxQueuedNotes = 256
+
+// soundQueue schedules the notes of SOUND WAIT mode one after another. Each
+// note is sent when the previous one has ended, so a fast loop of SOUND or
+// NOISE statements plays a melody instead of a cluster of overlapping tones.
+type soundQueue struct {
+	mu      sync.Mutex
+	end     time.Time            // When the last queued note stops playing
+	pending map[*time.Timer]bool // Notes not sent yet
+}
+
+// enqueue schedules send to run once all earlier notes have played and
+// reserves d for the note. Returns false if the queue is full.
+func (q *soundQueue) enqueue(d time.Duration, send func()) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending == nil {
+		q.pending = make(map[*time.Timer]bool)
+	}
+	if len(q.pending) >= maxQueuedNotes {
+		return false
+	}
+
+	now := time.Now()
+	start := q.end
+	if start.Before(now) {
+		start = now
+	}
+	q.end = start.Add(d)
+
+	// The callback needs q.mu, so it cannot see t before it is registered
+	var t *time.Timer
+	t = time.AfterFunc(start.Sub(now), func() {
+		q.mu.Lock()
+		scheduled := q.pending[t]
+		delete(q.pending, t)
+		q.mu.Unlock()
+		if scheduled {
+			send()
+		}
+	})
+	q.pending[t] = true
+	return true
+}
+
+// flush drops every note that has not been sent yet
+func (q *soundQueue) flush() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for t := range q.pending {
+		t.Stop()
+	}
+	q.pending = nil
+	q.end = time.Time{}
+}
+
+// queueSound sends msg through the SOUND WAIT queue. The session ID is fixed
+// now because the note is sent from a timer without the lock.
+func (b *TinyBASIC) queueSound(msg shared.Message, d time.Duration) {
+	if msg.SessionID == "" {
+		msg.SessionID = b.sessionID
+	}
+	b.soundQueue.enqueue(d, func() {
+		b.sendMessageObject(msg)
+	})
+}
+
+// noiseDuration is how long the frontend plays NOISE pitch, attack, decay:
+// attack and decay are clamped to 1-255 and count 10 ms each
+func noiseDuration(attack, decay float64) time.Duration {
+	clamp := func(v, def float64) float64 {
+		if v == 0 {
+			return def
+		}
+		if v < 1 {
+			return 1
+		}
+		if v > 255 {
+			return 255
+		}
+		return v
+	}
+	return time.Duration((clamp(attack, 10)+clamp(decay, 50))*10) * time.Millisecond
+}
+
+// parseSoundWait recognizes SOUND WAIT ON|OFF. isWait is false if args is
+// not a WAIT clause at all, so the caller can read it as frequency, duration;
+// valid is false for a WAIT clause without ON or OFF.
+func parseSoundWait(args string) (on, isWait, valid bool) {
+	fields := strings.Fields(strings.ToUpper(args))
+	if len(fields) == 0 || fields[0] != "WAIT" {
+		return false, false, false
+	}
+	if len(fields) != 2 || (fields[1] != "ON" && fields[1] != "OFF") {
+		return false, true, false
+	}
+	return fields[1] == "ON", true, true
+}
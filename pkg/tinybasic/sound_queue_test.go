@@ -0,0 +1,100 @@
+package tinybasic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// countSounds counts SOUND messages with a frequency in msgs and the
+// drained output channel
+func countSounds(b *TinyBASIC, msgs ...shared.Message) int {
+	for len(b.OutputChan) > 0 {
+		msgs = append(msgs, <-b.OutputChan)
+	}
+	n := 0
+	for _, msg := range msgs {
+		if msg.Type == shared.MessageTypeSound && msg.Params["frequency"] != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// TestSoundQueueOrder checks that queued notes start one after another
+func TestSoundQueueOrder(t *testing.T) {
+	var q soundQueue
+	sent := make(chan time.Time, 3)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		q.enqueue(40*time.Millisecond, func() { sent <- time.Now() })
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case at := <-sent:
+			if min := time.Duration(i) * 40 * time.Millisecond; at.Sub(start) < min {
+				t.Errorf("note %d sent after %v, expected at least %v", i, at.Sub(start), min)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("note %d was never sent", i)
+		}
+	}
+}
+
+// TestSoundWait runs SOUND WAIT on both backends: the program finishes
+// before its notes, which then arrive one by one
+func TestSoundWait(t *testing.T) {
+	program := []string{
+		`10 SOUND WAIT ON`,
+		`20 FOR I = 1 TO 3`,
+		`30 SOUND 200 + I * 100, 60`,
+		`40 NEXT I`,
+	}
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		b.useBytecode = bytecode
+		for _, line := range program {
+			b.Execute(line)
+		}
+		b.Execute("RUN")
+		waitUntilStopped(t, b)
+		first := countSounds(b)
+		if first > 1 {
+			t.Errorf("bytecode=%v: %d notes sent at once in SOUND WAIT mode", bytecode, first)
+		}
+
+		time.Sleep(250 * time.Millisecond)
+		if n := first + countSounds(b); n != 3 {
+			t.Errorf("bytecode=%v: expected 3 queued notes to play, got %d", bytecode, n)
+		}
+		b.Execute("SOUND WAIT OFF")
+		if n := countSounds(b, b.Execute("SOUND 440, 60")...); n != 1 {
+			t.Errorf("bytecode=%v: SOUND WAIT OFF should send at once, got %d notes", bytecode, n)
+		}
+	}
+}
+
+// TestBreakFlushesSoundQueue checks that BREAK drops notes still queued
+func TestBreakFlushesSoundQueue(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	for _, line := range []string{
+		`10 SOUND WAIT ON`,
+		`20 FOR I = 1 TO 5`,
+		`30 SOUND 300, 100`,
+		`40 NEXT I`,
+		`50 GOTO 50`,
+	} {
+		b.Execute(line)
+	}
+	b.Execute("RUN")
+	time.Sleep(50 * time.Millisecond)
+	b.StopExecution()
+	waitUntilStopped(t, b)
+	countSounds(b)
+
+	time.Sleep(250 * time.Millisecond)
+	if n := countSounds(b); n != 0 {
+		t.Errorf("%d queued notes were sent after BREAK", n)
+	}
+}
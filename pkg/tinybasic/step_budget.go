@@ -0,0 +1,66 @@
+package tinybasic
+
+import "fmt"
+
+// Default step budgets of a run. A step is one bytecode instruction or one
+// line in the interpreter. The limits are far above what real programs need
+// and only end runaway loops, which would otherwise use the server CPU
+// until the session ends.
+const (
+	DefaultMaxStepsGuest = 100_000_000
+	DefaultMaxStepsUser  = 1_000_000_000
+)
+
+// stepBudget counts the steps of a run against its limit
+type stepBudget struct {
+	limit int // Steps the run may take, 0 = unlimited
+	used  int
+}
+
+// take counts one step and returns ErrExecutionLimit once the budget is used up
+func (s *stepBudget) take() error {
+	if s.limit <= 0 {
+		return nil
+	}
+	s.used++
+	if s.used > s.limit {
+		return fmt.Errorf("%w: the program ran more than %d steps", ErrExecutionLimit, s.limit)
+	}
+	return nil
+}
+
+// SetStepBudgets sets how many steps a run of a guest and of a registered
+// user may take. 0 means unlimited, negative values restore the default.
+func (b *TinyBASIC) SetStepBudgets(guest, user int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if guest < 0 {
+		guest = DefaultMaxStepsGuest
+	}
+	if user < 0 {
+		user = DefaultMaxStepsUser
+	}
+	b.maxStepsGuest = guest
+	b.maxStepsUser = user
+}
+
+// newStepBudget returns the budget for a new run of the session. Without an
+// OS the session is treated like a guest.
+func (b *TinyBASIC) newStepBudget() stepBudget {
+	b.mu.Lock()
+	sessionID, guest, user := b.sessionID, b.maxStepsGuest, b.maxStepsUser
+	b.mu.Unlock()
+
+	if b.os != nil && !b.os.IsGuestSession(sessionID) {
+		return stepBudget{limit: user}
+	}
+	return stepBudget{limit: guest}
+}
+
+// countStep takes one step of the run's budget
+func (vm *BytecodeVM) countStep() error {
+	if vm.tinybasic == nil {
+		return nil
+	}
+	return vm.tinybasic.steps.take()
+}
@@ -0,0 +1,94 @@
+package tinybasic
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// TestStepBudget ends an endless loop with the bytecode VM and the
+// interpreter, even with an ON ERROR GOTO handler installed, and checks
+// that input is enabled again and the next RUN gets a fresh budget
+func TestStepBudget(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		b.useBytecode = bytecode
+		b.SetStepBudgets(500, 0)
+		b.Execute("10 ON ERROR GOTO 100")
+		b.Execute("20 PRINT \"START\"")
+		b.Execute("30 REM LOOP")
+		b.Execute("40 GOTO 30")
+		b.Execute("50 END")
+		b.Execute("100 PRINT \"TRAPPED\"")
+		for len(b.OutputChan) > 0 {
+			<-b.OutputChan
+		}
+
+		for run := 0; run < 2; run++ {
+			b.Execute("RUN")
+			var text []string
+			enabled := false
+			timeout := time.After(5 * time.Second)
+		collect:
+			for {
+				select {
+				case msg := <-b.OutputChan:
+					if msg.Type == shared.MessageTypeInputControl && msg.Content == "enable" {
+						enabled = true
+					}
+					if msg.Type == shared.MessageTypeText {
+						if msg.Content == "OK" {
+							break collect
+						}
+						text = append(text, msg.Content)
+					}
+				case <-timeout:
+					t.Fatalf("bytecode=%v: the step budget did not end the program", bytecode)
+				}
+			}
+
+			out := strings.Join(text, "\n")
+			if !strings.Contains(out, "START") || !strings.Contains(out, "execution limit exceeded") {
+				t.Errorf("bytecode=%v run %d: unexpected output %q", bytecode, run, out)
+			}
+			if strings.Contains(out, "TRAPPED") {
+				t.Errorf("bytecode=%v run %d: ON ERROR GOTO caught the step budget", bytecode, run)
+			}
+			if !enabled {
+				t.Errorf("bytecode=%v run %d: input was not enabled again", bytecode, run)
+			}
+		}
+	}
+}
+
+// TestStepBudgetLimits checks that a program within its budget runs
+// normally and that 0 turns the budget off
+func TestStepBudgetLimits(t *testing.T) {
+	budget := stepBudget{limit: 3}
+	for i := 0; i < 3; i++ {
+		if err := budget.take(); err != nil {
+			t.Fatalf("step %d: unexpected error %v", i+1, err)
+		}
+	}
+	if err := budget.take(); err == nil {
+		t.Error("expected an error after the last step")
+	}
+
+	unlimited := stepBudget{}
+	for i := 0; i < 1000; i++ {
+		if err := unlimited.take(); err != nil {
+			t.Fatalf("unlimited budget failed at step %d: %v", i+1, err)
+		}
+	}
+
+	b := NewTinyBASIC(nil)
+	b.SetStepBudgets(-1, -1)
+	if b.maxStepsGuest != DefaultMaxStepsGuest || b.maxStepsUser != DefaultMaxStepsUser {
+		t.Errorf("negative budgets must restore the defaults, got %d and %d", b.maxStepsGuest, b.maxStepsUser)
+	}
+	if got := b.newStepBudget().limit; got != DefaultMaxStepsGuest {
+		t.Errorf("a session without OS must get the guest budget, got %d", got)
+	}
+}
@@ -0,0 +1,133 @@
+package tinybasic
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// maxFormatWidth limits width and precision of a FORMAT$ specifier
+const maxFormatWidth = 255
+
+// basicFormat implements FORMAT$(fmt$, args...). Supported specifiers:
+//
+//	%d  number rounded to an integer
+//	%f  number with precision decimals (default 6)
+//	%s  string, precision cuts it to that many characters
+//	%%  a percent sign
+//
+// Each specifier may have the flags - (left align) and 0 (pad numbers with
+// zeros), a width and a .precision, e.g. %-10s, %05d or %8.2f. Every
+// specifier takes exactly one argument of the matching type.
+func basicFormat(format string, args []BASICValue) (string, error) {
+	var sb strings.Builder
+	next := 0
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(format) {
+			return "", fmt.Errorf("format ends with %%")
+		}
+		if format[i] == '%' {
+			sb.WriteByte('%')
+			continue
+		}
+
+		start := i
+		for i < len(format) && (format[i] == '-' || format[i] == '0') {
+			i++
+		}
+		flags := format[start:i]
+		var width int
+		var err error
+		width, i, err = formatNumber(format, i)
+		if err != nil {
+			return "", err
+		}
+		precision := -1
+		if i < len(format) && format[i] == '.' {
+			precision, i, err = formatNumber(format, i+1)
+			if err != nil {
+				return "", err
+			}
+			if precision < 0 {
+				precision = 0 // "%.f" like printf
+			}
+		}
+		if i >= len(format) {
+			return "", fmt.Errorf("incomplete specifier %%%s", format[start:])
+		}
+		verb := format[i]
+		spec := format[start-1 : i+1]
+		if verb != 'd' && verb != 'f' && verb != 's' {
+			return "", fmt.Errorf("unknown specifier %s, use %%d, %%f or %%s", spec)
+		}
+		if next >= len(args) {
+			return "", fmt.Errorf("%s has no argument, %d given", spec, len(args))
+		}
+		arg := args[next]
+		next++
+
+		goSpec := "%" + flags
+		if width >= 0 {
+			goSpec += fmt.Sprint(width)
+		}
+		switch verb {
+		case 'd':
+			if !arg.IsNumeric {
+				return "", fmt.Errorf("%s needs a number for argument %d", spec, next)
+			}
+			sb.WriteString(fmt.Sprintf(goSpec+"d", int64(math.Round(arg.NumValue))))
+		case 'f':
+			if !arg.IsNumeric {
+				return "", fmt.Errorf("%s needs a number for argument %d", spec, next)
+			}
+			if precision >= 0 {
+				goSpec += fmt.Sprintf(".%d", precision)
+			}
+			sb.WriteString(fmt.Sprintf(goSpec+"f", arg.NumValue))
+		case 's':
+			if arg.IsNumeric {
+				return "", fmt.Errorf("%s needs a string for argument %d", spec, next)
+			}
+			if strings.Contains(flags, "0") {
+				return "", fmt.Errorf("flag 0 is only allowed for numbers in %s", spec)
+			}
+			if precision >= 0 {
+				goSpec += fmt.Sprintf(".%d", precision)
+			}
+			sb.WriteString(fmt.Sprintf(goSpec+"s", arg.StrValue))
+		}
+		if sb.Len() > MaxStringLength {
+			return "", fmt.Errorf("result longer than %d characters", MaxStringLength)
+		}
+	}
+	if next < len(args) {
+		return "", fmt.Errorf("%d arguments given but the format uses %d", len(args), next)
+	}
+	if sb.Len() > MaxStringLength {
+		return "", fmt.Errorf("result longer than %d characters", MaxStringLength)
+	}
+	return sb.String(), nil
+}
+
+// formatNumber reads the width or precision at format[i:]. It returns -1 if
+// there are no digits, and the index after the number.
+func formatNumber(format string, i int) (int, int, error) {
+	n := -1
+	for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+		if n < 0 {
+			n = 0
+		}
+		n = n*10 + int(format[i]-'0')
+		if n > maxFormatWidth {
+			return 0, i, fmt.Errorf("width and precision may be at most %d", maxFormatWidth)
+		}
+		i++
+	}
+	return n, i, nil
+}
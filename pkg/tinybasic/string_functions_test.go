@@ -0,0 +1,128 @@
+package tinybasic
+
+import (
+	"context"
+	"testing"
+)
+
+// TestInstrFunction tests INSTR in its 2- and 3-argument forms
+func TestInstrFunction(t *testing.T) {
+	basic := NewTestBasic()
+
+	tests := []struct {
+		name     string
+		expr     string
+		expected float64
+	}{
+		{"found at start", `INSTR("HELLO WORLD", "HELLO")`, 1},
+		{"found in middle", `INSTR("HELLO WORLD", "WORLD")`, 7},
+		{"not found", `INSTR("HELLO WORLD", "XYZ")`, 0},
+		{"needle longer than haystack", `INSTR("HI", "HELLO")`, 0},
+		{"empty haystack", `INSTR("", "A")`, 0},
+		{"empty needle", `INSTR("ABC", "")`, 1},
+		{"empty needle with start", `INSTR(2, "ABC", "")`, 2},
+		{"empty needle start beyond length", `INSTR(5, "ABC", "")`, 0},
+		{"start skips first match", `INSTR(2, "ABCABC", "A")`, 4},
+		{"start at match", `INSTR(4, "ABCABC", "ABC")`, 4},
+		{"start beyond length", `INSTR(10, "ABC", "A")`, 0},
+		{"start at last char", `INSTR(3, "ABC", "C")`, 3},
+		{"case sensitive", `INSTR("abc", "B")`, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := basic.evalExpression(test.expr)
+			if err != nil {
+				t.Fatalf("Unexpected error for '%s': %v", test.expr, err)
+			}
+			if !result.IsNumeric || result.NumValue != test.expected {
+				t.Errorf("For '%s': expected %v, got %+v", test.expr, test.expected, result)
+			}
+		})
+	}
+
+	for _, expr := range []string{`INSTR("ABC")`, `INSTR(0, "ABC", "A")`, `INSTR(1, 2, "A")`, `INSTR("A", "B", "C")`} {
+		if _, err := basic.evalExpression(expr); err == nil {
+			t.Errorf("Expected error for '%s', but got none", expr)
+		}
+	}
+}
+
+// TestSpaceAndStringFunctions tests SPACE$ and STRING$
+func TestSpaceAndStringFunctions(t *testing.T) {
+	basic := NewTestBasic()
+
+	tests := []struct {
+		name     string
+		expr     string
+		expected string
+	}{
+		{"space", `SPACE$(3)`, "   "},
+		{"space zero", `SPACE$(0)`, ""},
+		{"string with code", `STRING$(4, 42)`, "****"},
+		{"string with char", `STRING$(3, "-")`, "---"},
+		{"string uses first char", `STRING$(2, "AB")`, "AA"},
+		{"string zero", `STRING$(0, "X")`, ""},
+		{"padding concat", `"A" + SPACE$(2) + "B"`, "A  B"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := basic.evalExpression(test.expr)
+			if err != nil {
+				t.Fatalf("Unexpected error for '%s': %v", test.expr, err)
+			}
+			if result.IsNumeric || result.StrValue != test.expected {
+				t.Errorf("For '%s': expected %q, got %+v", test.expr, test.expected, result)
+			}
+		})
+	}
+
+	for _, expr := range []string{`SPACE$(-1)`, `SPACE$("A")`, `STRING$(-1, "A")`, `STRING$(3, "")`, `STRING$(100000, "A")`} {
+		if _, err := basic.evalExpression(expr); err == nil {
+			t.Errorf("Expected error for '%s', but got none", expr)
+		}
+	}
+}
+
+// TestStringFunctionsBytecode checks that the bytecode VM agrees with the interpreter
+func TestStringFunctionsBytecode(t *testing.T) {
+	vm := NewBytecodeVM(nil)
+	vm.ctx = context.Background()
+
+	vm.stack.Push(newNumericBASICValue(3))
+	vm.stack.Push(newStringBASICValue("ABCABC"))
+	vm.stack.Push(newStringBASICValue("C"))
+	if err := vm.callBuiltinFunction("INSTR", 3); err != nil {
+		t.Fatalf("INSTR failed: %v", err)
+	}
+	if v, _ := vm.stack.Pop(); v.NumValue != 3 {
+		t.Errorf("INSTR(3, \"ABCABC\", \"C\"): expected 3, got %v", v.NumValue)
+	}
+
+	vm.stack.Push(newStringBASICValue("ABC"))
+	vm.stack.Push(newStringBASICValue("X"))
+	if err := vm.callBuiltinFunction("INSTR", 2); err != nil {
+		t.Fatalf("INSTR failed: %v", err)
+	}
+	if v, _ := vm.stack.Pop(); v.NumValue != 0 {
+		t.Errorf("INSTR(\"ABC\", \"X\"): expected 0, got %v", v.NumValue)
+	}
+
+	vm.stack.Push(newNumericBASICValue(2))
+	if err := vm.callBuiltinFunction("SPACE$", 1); err != nil {
+		t.Fatalf("SPACE$ failed: %v", err)
+	}
+	if v, _ := vm.stack.Pop(); v.StrValue != "  " {
+		t.Errorf("SPACE$(2): expected 2 spaces, got %q", v.StrValue)
+	}
+
+	vm.stack.Push(newNumericBASICValue(3))
+	vm.stack.Push(newNumericBASICValue(61))
+	if err := vm.callBuiltinFunction("STRING$", 2); err != nil {
+		t.Fatalf("STRING$ failed: %v", err)
+	}
+	if v, _ := vm.stack.Pop(); v.StrValue != "===" {
+		t.Errorf("STRING$(3, 61): expected \"===\", got %q", v.StrValue)
+	}
+}
@@ -0,0 +1,51 @@
+package tinybasic
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// sysServices are the OS services a program may call with SYS$. Only these
+// names are accepted: SYS$ gives programs a few facts about their session,
+// not a way into the shell. Each service gets the session's username.
+var sysServices = map[string]func(b *TinyBASIC, username string) (string, error){
+	"USER": func(b *TinyBASIC, username string) (string, error) {
+		return username, nil
+	},
+	"FILES": func(b *TinyBASIC, username string) (string, error) {
+		if b.fs == nil {
+			return "", ErrNilFileSystem
+		}
+		files, err := b.listAllFiles()
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(files, ","), nil
+	},
+	"DATE": func(b *TinyBASIC, username string) (string, error) {
+		return time.Now().Format("2006-01-02"), nil
+	},
+	"TIME": func(b *TinyBASIC, username string) (string, error) {
+		return time.Now().Format("15:04:05"), nil
+	},
+}
+
+// sysCall implements SYS$(service$) for the interpreter and the bytecode VM.
+// It needs the TinyOS session the program runs in and rejects every service
+// that is not in sysServices.
+func (b *TinyBASIC) sysCall(service string) (string, error) {
+	name := strings.ToUpper(strings.TrimSpace(service))
+	call, ok := sysServices[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownService, service)
+	}
+	if b.os == nil || b.sessionID == "" {
+		return "", fmt.Errorf("%w: SYS$ needs a TinyOS session", ErrNoSession)
+	}
+	username := b.os.GetUsernameForSession(b.sessionID)
+	if username == "" {
+		return "", fmt.Errorf("%w: SYS$ needs a TinyOS session", ErrNoSession)
+	}
+	return call(b, username)
+}
@@ -0,0 +1,40 @@
+package tinybasic
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestSysRejects checks with the bytecode VM and the interpreter that SYS$
+// refuses services outside the whitelist and needs a session
+func TestSysRejects(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		for _, service := range []string{`"LS"`, `"USER; rm -rf"`, `"USER"`, `1`} {
+			out := runTrapProgram(t, bytecode,
+				`10 ON ERROR GOTO 100`,
+				`20 LET A$ = SYS$(`+service+`)`,
+				`30 PRINT "NO ERROR"`,
+				`40 END`,
+				`100 PRINT "TRAPPED"`,
+			)
+			if !strings.Contains(out, "TRAPPED") || strings.Contains(out, "NO ERROR") {
+				t.Errorf("bytecode=%v SYS$(%s): output %q", bytecode, service, out)
+			}
+		}
+	}
+}
+
+// TestSysWhitelist checks that unknown names fail before the session is
+// looked at, and known names only fail for the missing session
+func TestSysWhitelist(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	if _, err := b.sysCall("shell"); !errors.Is(err, ErrUnknownService) {
+		t.Errorf("expected ErrUnknownService, got %v", err)
+	}
+	for name := range sysServices {
+		if _, err := b.sysCall(strings.ToLower(name)); !errors.Is(err, ErrNoSession) {
+			t.Errorf("%s: expected ErrNoSession, got %v", name, err)
+		}
+	}
+}
@@ -273,18 +273,34 @@ func (b *TinyBASIC) ResetExecutionState() {
 	b.waitingForMCPInput = false // Clear MCP input flag
 	b.pendingMCPCode = ""        // Clear pending MCP code
 	b.pendingMCPFilename = ""    // Clear pending MCP filename
+	b.pendingSave = nil
 	b.gosubStack = b.gosubStack[:0]
 	b.forLoops = b.forLoops[:0]
 	b.forLoopIndexMap = make(map[string]int) // Clear loop index map
+	b.lastErrorLine = 0
+	b.lastErrorMessage = ""
+	b.inputDefault = nil
+	b.errTrap.reset()
+	b.fnKeys.reset()
+	b.events.reset()
+	b.resetMusic()
+	b.angleDegrees = false
+	b.compareText = false
+	b.commonVars = nil
+	b.chained = false
+	b.chainHandOff = false
 	// Clear any cached expressions when resetting execution state
 	clearExpressionCache()
 	// Reset performance counters
 	b.loopIterationCount = 0
 	b.dataPointer = 0
 	b.printCursorOnSameLine = false
+	b.cursorX = 0
 	b.inputControlEnableSent = false
 	// GOTO Cleanup Counter zurücksetzen
 	b.gotoCleanupCount = make(map[string]int)
+	b.discardPause()
+	b.soundQueue.flush()
 	// Reset bytecode VM state
 	if b.bytecodeVM != nil {
 		b.bytecodeVM.Reset()
@@ -316,19 +332,30 @@ type TinyBASIC struct {
 	compiledHash             string                // Hash of compiled program to detect changes
 	currentLine              int                   // The line number currently being executed (0 if not running).
 	inputVar                 string                // Name of the variable waiting for INPUT, empty otherwise.
-	inputPC                  int                   // Program counter for bytecode VM to resume after INPUT
+	inputDefault             *BASICValue           // Default of the waiting INPUT, nil if none.
+	inputChan                chan BASICValue       // Passes the answer to an INPUT in a running program.
 	forLoops                 []ForLoopInfo         // Stack for tracking active FOR loops.
 	forLoopIndexMap          map[string]int        // Maps variable names to forLoops indices for O(1) lookup
 	gosubStack               []int                 // Stack for tracking GOSUB return points (renamed from runningStack).
 	data                     []string              // Stores DATA statement values, populated by rebuildData.
 	dataPointer              int                   // Current position within the data items for READ.
+	dataLines                []dataLine            // First data item of each line with DATA, for RESTORE <line>.
 	openFiles                map[int]*OpenFile     // Map of active file handles (handle number -> OpenFile).
 	nextHandle               int                   // Next available file handle number (auto-incrementing).
 	running                  bool                  // Flag indicating if a program is currently executing via RUN.
 	sessionID                string                // Identifier for filesystem operations (e.g., user session).
 	termCols                 int                   // Terminal width (for PRINT formatting).
 	termRows                 int                   // Terminal height.
+	screenCols               int                   // Width of the client terminal; termCols returns to it when a run ends
+	widthSet                 bool                  // WIDTH changed termCols away from screenCols
 	printCursorOnSameLine    bool                  // Flag indicating if cursor should stay on same line (for semicolon behavior)
+	printZoneWidth           int                   // Column width of the PRINT zones used by comma separators
+	maxFileLineLength        int                   // Longest line accepted when reading files (LOAD, INPUT #)
+	maxStepsGuest            int                   // Step budget of a run for guests, 0 = unlimited
+	maxStepsUser             int                   // Step budget of a run for registered users, 0 = unlimited
+	steps                    stepBudget            // Steps of the current run, reset by RUN
+	angleDegrees             bool                  // OPTION ANGLE DEGREES: trig functions work in degrees
+	compareText              bool                  // OPTION COMPARE TEXT: string comparisons ignore case
 	currentSubStatementIndex int                   // Current index in colon-separated statements for FOR-NEXT loops
 	debugFP                  *os.File              // File pointer for debug logging
 
@@ -347,6 +374,16 @@ type TinyBASIC struct {
 	sayWaitChan       chan struct{} // Channel für Synchronisation
 	// Index für den Neustart der Sub-Statement-Verarbeitung innerhalb einer Zeile
 	resumeSubStatementIndex int
+	// ON ERROR GOTO: Zustand und Position des abgefangenen Fehlers für RESUME
+	errTrap          errorTrap
+	errorResumeLine  int
+	errorResumeIndex int
+	// STOP/BREAK: Zustand, an dem CONT fortsetzt (nil = CONT nicht möglich)
+	paused *pausedRun
+	// COMMON/CHAIN: variables kept by CHAIN and the switch to the chained program
+	commonVars   map[string]bool // Names declared with COMMON, arrays as "A()"
+	chained      bool            // CHAIN replaced the program; the run loop goes on at currentLine
+	chainHandOff bool            // The chained program does not compile; the interpreter runs it
 	
 	// Expression Token Caching for Performance Optimization
 	exprTokenCache *ExpressionTokenCache // Cache for tokenized expressions
@@ -359,12 +396,16 @@ type TinyBASIC struct {
 	sayID        int64            // Fortlaufende Nummer für SAY/SAY_DONE (alt)
 	waitingSayID int64            // ID, auf die aktuell gewartet wird (alt)	// INKEY$ Support - Channel-basierte thread-safe Implementierung
 	currentKey   string           // Aktuell gedrückte Taste (nur für interne Verwendung)
-	keyChannel   chan string      // Channel für Key-Updates
+	keyChannel   chan string      // Channel für Key-Updates, wird von SLEEP geleert
+	tappedKey    string           // Während SLEEP getippte Taste, die INKEY$ einmal liefert
 	keyRequests  chan chan string // Channel für INKEY$-Anfragen
 
 	// Erweiterte Tastaturstatus-Tracking für Spielsteuerung
 	keyStates    map[string]bool // Status aller Tasten (gedrückt/nicht gedrückt)
 	lastKeyEvent time.Time       // Zeitstempel des letzten Tastenereignisses
+	fnKeys       functionKeys    // KEY-Makros und ON KEY-Handler der Funktionstasten
+	events       eventTraps      // ON TIMER- und ON INKEY-Handler
+	pen          drawPen         // Stift von DRAW, CLS setzt ihn zurück
 
 	// Rate Limiting für SAY-Befehle
 	sayCommandTimestamps []time.Time
@@ -373,9 +414,27 @@ type TinyBASIC struct {
 	// GOTO Cleanup Protection
 	gotoCleanupCount map[string]int // Counter für GOTO-Cleanup-Aufrufe zur Endlosschleifen-Erkennung
 
+	// Zufallszahlen für RND, RANDOMIZE setzt den Seed
+	rng *rand.Rand
+
 	// Rate Limiting für NOISE-Befehle
 	noiseCommandTimestamps []time.Time
 	maxNoiseRatePerSecond  int
+
+	// SOUND WAIT: SOUND und NOISE nacheinander statt sofort abspielen
+	soundWait  bool
+	soundQueue soundQueue
+
+	// SCREEN DOUBLE: 2D-Grafik auf verdeckte Seite zeichnen, FLIP zeigt sie an
+	screenBuffered bool
+	flipWarned     bool // FLIP-Warnung ohne SCREEN DOUBLE wurde in diesem Lauf schon gezeigt
+
+	// SID-Musik: geöffnete Datei, Anzahl der Stücke, Pause
+	music musicState
+	// MUSIC SCOPE: Abspielposition an das Frontend melden
+	musicProgress musicProgress
+	// BENCH: Startzeit des laufenden Programms, Null bei RUN
+	benchStart time.Time
 	
 	// Performance optimization counters
 	loopIterationCount       int                   // Count iterations since last context check
@@ -395,7 +454,7 @@ type TinyBASIC struct {
 	pendingMCPCode     string                 // Temporarily stores generated MCP code until filename is provided
 	pendingMCPFilename string                 // Stores the original filename for MCP edit operations
 	waitingForMCPInput bool                   // Flag indicating if we're waiting for MCP filename input
-	waitingInput       bool                   // Flag indicating if we're waiting for any user input
+	pendingSave        *pendingSave           // SAVE waiting for y/n before overwriting a file
 
 	// Sprite Batching System for Performance
 	spriteBatch      []shared.Message // Batch of sprite updates to send together
@@ -405,6 +464,10 @@ type TinyBASIC struct {
 
 	// Autorun callback for returning to TinyOS after program completion
 	onProgramEnd func() // Optional callback executed when program ends
+
+	// Last runtime error of RUN (used by the editor's save & run)
+	lastErrorLine    int    // Line number of the error, 0 if the last run ended cleanly
+	lastErrorMessage string // Error text as shown to the user
 }
 
 // MCP Rate-Limiting Konstanten
@@ -448,7 +511,12 @@ func NewTinyBASIC(osys *tinyos.TinyOS) *TinyBASIC {
 		data:         make([]string, 0),
 		OutputChan:   make(chan shared.Message, OutputChannelBufferSize), termCols: DefaultTermCols,
 		termRows:                DefaultTermRows,
+		screenCols:              DefaultTermCols,
 		printCursorOnSameLine:   false, // Initially cursor is at start of line
+		printZoneWidth:          DefaultPrintZoneWidth,
+		maxFileLineLength:       DefaultMaxFileLineLength,
+		maxStepsGuest:           DefaultMaxStepsGuest,
+		maxStepsUser:            DefaultMaxStepsUser,
 		nextHandle:              1,     // File handles start from 1
 		ctx:                     ctx,
 		cancel:                  cancel,
@@ -462,6 +530,8 @@ func NewTinyBASIC(osys *tinyos.TinyOS) *TinyBASIC {
 		inverseTextMode:        false,                        // Normaler Text-Modus
 		inputControlEnableSent: false,                        // Initialwert für das Flag
 		keyStates:              make(map[string]bool),        // Initialisiere die Tastaturstatus-Map
+		keyChannel:             make(chan string, keyEventBuffer),
+		inputChan:              make(chan BASICValue, 1),
 		debugFP:                debugFile,                    // Assign the file pointer		gotoCleanupCount:       make(map[string]int),         // Initialize GOTO Cleanup Protection
 		mcpUserUsage:           make(map[string][]time.Time), // Initialize MCP User Usage
 		mcpSystemUsage:         make([]time.Time, 0),         // Initialize MCP System Usage		pendingMCPCode:         "",                           // Initialize MCP pending code
@@ -486,9 +556,8 @@ func NewTinyBASIC(osys *tinyos.TinyOS) *TinyBASIC {
 		expressionJIT:          NewExpressionJIT(), // Safe expression-level JIT compiler
 	}
 
-	// Seed the random number generator once
-	//nolint:staticcheck // Using Seed is acceptable for simple non-crypto random numbers needed here.
-	rand.Seed(time.Now().UnixNano())
+	// Each session gets its own random source so RANDOMIZE is reproducible
+	b.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
 
 	b.noiseCommandTimestamps = make([]time.Time, 0) // Initialisieren für NOISE
 	b.maxNoiseRatePerSecond = 10                    // Maximal 10 NOISE-Befehle pro Sekunde
@@ -524,15 +593,41 @@ func (b *TinyBASIC) SetLastSayDoneID(id int64) {
 	b.mu.Unlock()
 }
 
+// SetPrintZoneWidth sets the column width of the PRINT zones (comma separator).
+// Values below 1 restore the default.
+func (b *TinyBASIC) SetPrintZoneWidth(width int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if width < 1 {
+		width = DefaultPrintZoneWidth
+	}
+	b.printZoneWidth = width
+}
+
+// SetMaxFileLineLength sets the longest line LOAD, INPUT # and LINE INPUT # accept.
+// Values below 1 restore the default.
+func (b *TinyBASIC) SetMaxFileLineLength(length int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if length < 1 {
+		length = DefaultMaxFileLineLength
+	}
+	b.maxFileLineLength = length
+}
+
 // SetTerminalDimensions updates the interpreter's knowledge of the terminal size.
 func (b *TinyBASIC) SetTerminalDimensions(cols, rows int) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	// Basic validation for terminal dimensions
 	if cols > 0 {
-		b.termCols = cols
+		b.screenCols = cols
 	} else {
-		b.termCols = DefaultTermCols // Fallback to default if invalid
+		b.screenCols = DefaultTermCols // Fallback to default if invalid
+	}
+	// A width set by WIDTH stays until the run ends
+	if !b.widthSet {
+		b.termCols = b.screenCols
 	}
 	if rows > 0 {
 		b.termRows = rows
@@ -571,11 +666,26 @@ func (b *TinyBASIC) IsRunning() bool {
 	return b.running
 }
 
+// HasProgram reports whether a program is loaded.
+func (b *TinyBASIC) HasProgram() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.program) > 0
+}
+
+// LastError returns the line number and message of the runtime error that
+// terminated the last RUN. The line is 0 if the program ended without error.
+func (b *TinyBASIC) LastError() (int, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastErrorLine, b.lastErrorMessage
+}
+
 // IsWaitingForInput safely checks if the interpreter is paused waiting for user input.
 func (b *TinyBASIC) IsWaitingForInput() bool {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	waiting := b.inputVar != "" || b.waitingForMCPInput
+	waiting := b.inputVar != "" || b.waitingForMCPInput || b.pendingSave != nil
 	if waiting {
 		tinyBasicDebugLog("[WAITING] IsWaitingForInput=true, inputVar='%s', waitingForMCPInput=%v", b.inputVar, b.waitingForMCPInput)
 	}
@@ -593,19 +703,38 @@ func (b *TinyBASIC) StopExecution() []shared.Message {
 	}
 	// Reset execution state immediately
 	wasRunning := b.running
+	// The interpreter pauses at the line about to run; the VM saves its own
+	// state once Run notices the cancellation
+	if wasRunning && b.currentLine != 0 && !b.runsOnVM() {
+		b.pauseInterpreter(b.currentLine, 0)
+	}
 	b.running = false
 	b.currentLine = 0
 	b.inputVar = ""                 // Clear pending input
+	b.inputDefault = nil
 	b.waitingForMCPInput = false    // Clear MCP input flag
 	b.pendingMCPCode = ""           // Clear pending MCP code
 	b.pendingMCPFilename = ""       // Clear pending MCP filename
+	b.pendingSave = nil             // Forget an unanswered overwrite question
 	b.gosubStack = b.gosubStack[:0] // Clear stacks
 	b.forLoops = b.forLoops[:0]
+	b.forLoopIndexMap = make(map[string]int)
+	b.loopIterationCount = 0
+	// Stop the bytecode VM as well; its FOR/GOSUB frames are dropped when Run
+	// observes the cancelled context and again at the start of the next RUN.
+	if b.bytecodeVM != nil {
+		b.bytecodeVM.Stop()
+	}
 
 	// Create a new context for potential future RUN commands
 	b.ctx, b.cancel = context.WithCancel(context.Background())
 	// Ensure files opened during the run are closed
 	b.closeAllFiles() // Assumes lock is held
+	// Queued SOUND WAIT notes stop with the program
+	b.soundQueue.flush()
+	// The last FLIPped frame stays on screen
+	b.endScreenBuffer()
+	b.resetMusic()
 
 	var messages []shared.Message
 
@@ -660,6 +789,8 @@ func (b *TinyBASIC) Reset() {
 	b.forLoops = b.forLoops[:0]
 	b.data = make([]string, 0)
 	b.dataPointer = 0
+	b.angleDegrees = false
+	b.compareText = false
 
 	// Close files and reset file handling state
 	b.closeAllFiles() // Assumes lock is held
@@ -714,7 +845,7 @@ func (b *TinyBASIC) Execute(input string) []shared.Message {
 	b.mu.Lock() // Lock for state checks and modifications
 
 	// If waiting for input, delegate to ExecuteInputResponse.
-	if b.inputVar != "" {
+	if b.inputVar != "" || b.pendingSave != nil {
 		// ExecuteInputResponse handles locking/unlocking and message sending.
 		b.mu.Unlock()
 		return b.ExecuteInputResponse(input) // Returns nil, sends messages via channel.
@@ -759,6 +890,7 @@ func (b *TinyBASIC) Execute(input string) []shared.Message {
 	// Restore the original output channel
 	b.mu.Lock()
 	b.OutputChan = originalOutputChan
+	asking := b.pendingSave != nil
 	b.mu.Unlock()
 
 	// Collect all messages from the temporary channel
@@ -788,8 +920,13 @@ func (b *TinyBASIC) Execute(input string) []shared.Message {
 	// since RUN executes asynchronously and will send OK when finished
 	// Also exclude LOAD commands since they have their own OK handling
 	inputUpper := strings.ToUpper(strings.TrimSpace(input))
-	if inputUpper == "RUN" || strings.HasPrefix(inputUpper, "LOAD ") {
-		return collectedMessages // No immediate OK for RUN or LOAD command
+	if inputUpper == "RUN" || strings.HasPrefix(inputUpper, "RUN ") || inputUpper == "BENCH" || strings.HasPrefix(inputUpper, "BENCH ") ||
+		inputUpper == "CONT" || strings.HasPrefix(inputUpper, "LOAD ") {
+		return collectedMessages // No immediate OK for RUN, BENCH, CONT or LOAD command
+	}
+	// SAVE asking whether to overwrite sends OK once answered
+	if asking {
+		return collectedMessages
 	}
 
 	// Combine collected messages with success message
@@ -807,6 +944,10 @@ func (b *TinyBASIC) ExecuteInputResponse(input string) []shared.Message {
 	if b.waitingForMCPInput {
 		return b.processMCPFilenameInput(input) // This will unlock mutex
 	}
+	// SAVE asked whether to overwrite a file
+	if b.pendingSave != nil {
+		return b.answerSave(input) // Unlocks the mutex
+	}
 
 	if b.inputVar == "" {
 		b.mu.Unlock()
@@ -815,83 +956,33 @@ func (b *TinyBASIC) ExecuteInputResponse(input string) []shared.Message {
 	}
 
 	varName := b.inputVar
-	// Clear the input request flag *before* potentially resuming or erroring.
-	b.inputVar = ""
-
-	// Assign the input value to the variable.
-	var assignErr error
-	if strings.HasSuffix(varName, "$") { // String variable
-		b.variables[strings.ToUpper(varName)] = BASICValue{StrValue: input, IsNumeric: false}
-	} else { // Numeric variable
-		// Attempt to parse the input as a float.
-		val, err := strconv.ParseFloat(input, 64)
-		if err != nil {
-			// Invalid numeric input: Restore inputVar and prompt again.
-			b.inputVar = varName // Restore the input request flag
-			assignErr = WrapError(ErrInvalidExpression, "INPUT", false, b.currentLine)
-		} else {
-			b.variables[strings.ToUpper(varName)] = BASICValue{NumValue: val, IsNumeric: true}
-		}
-	}
-
-	if assignErr != nil {
-		// Failed assignment (invalid number) - prompt user again.
+	value, ok := b.inputValue(varName, input)
+	if !ok {
+		// Invalid numeric input: keep waiting and prompt again.
 		b.mu.Unlock()                                                    // Unlock before sending message.
 		b.sendMessageWrapped(shared.MessageTypeText, "?REDO FROM START") // Classic BASIC message
 		return nil
 	}
+	b.inputVar = ""
+	b.inputDefault = nil
 
-	// Input processed successfully, re-enable terminal input.
-	b.sendInputControl("enable") // Assumes lock held
-
-	// Check if a program was running and waiting for this input.
+	// A running program waits in INPUT for the value
 	if b.running {
-		// Check if we're using bytecode execution and need to resume VM
-		if b.useBytecode && b.bytecodeVM != nil && b.inputPC > 0 {
-			// Resume bytecode VM execution from stored PC
-			resumePC := b.inputPC
-			b.inputPC = 0 // Clear stored PC
-			b.mu.Unlock() // Unlock before resuming VM
-			
-			// Convert input to BASICValue
-			var inputValue BASICValue
-			if strings.HasSuffix(varName, "$") {
-				inputValue = newStringBASICValue(input)
-			} else {
-				if val, err := strconv.ParseFloat(input, 64); err == nil {
-					inputValue = newNumericBASICValue(val)
-				} else {
-					inputValue = newNumericBASICValue(0) // Default to 0 for invalid input
-				}
-			}
-			
-			// Resume VM execution
-			go func() {
-				err := b.bytecodeVM.Resume(resumePC, inputValue, varName)
-				if err != nil {
-					b.sendMessageWrapped(shared.MessageTypeText, fmt.Sprintf("Runtime error: %s", err.Error()))
-				}
-			}()
-			return nil
-		}
-		
-		// Traditional interpreted execution
-		prevLine := b.currentLine
-		nextLine, found := b.findNextLine(prevLine) // Assumes lock held
-		if !found {
-			b.running = false // Mark as not running
-			b.currentLine = 0 // Set line to 0
-			b.mu.Unlock()
-			b.sendMessageWrapped(shared.MessageTypeText, "Program finished.")
-			return nil
+		b.sendInputControl("run_mode")
+		select {
+		case b.inputChan <- value:
+		default:
 		}
-		b.currentLine = nextLine
-		b.mu.Unlock() // Unlock to allow the run loop goroutine to proceed.
-	} else {
-		b.mu.Unlock() // Unlock the state.
-		b.sendMessageWrapped(shared.MessageTypeText, "OK")
+		b.mu.Unlock()
+		return nil
 	}
 
+	// INPUT in direct mode
+	b.variables[strings.ToUpper(varName)] = value
+	b.sendInputControl("enable") // Assumes lock held
+	b.mu.Unlock()
+	b.sendMessageWrapped(shared.MessageTypeText, "OK")
+
 	return nil // All output/status sent via channel.
 }
 
@@ -967,6 +1058,13 @@ func (b *TinyBASIC) findNextLine(currentLine int) (int, bool) {
 	return 0, false // No line found after currentLine.
 }
 
+// playErrorSFX plays the error buzz at the volume of the session
+func (b *TinyBASIC) playErrorSFX() {
+	if b.os != nil {
+		b.sendMessageObject(b.os.SFXMessage(b.sessionID, tinyos.SFXError))
+	}
+}
+
 // runProgramInternal is the core asynchronous execution loop for RUN.
 // It executes statements line by line, handling control flow and context cancellation.
 // Assumes initial state (running=true, currentLine set) is prepared by cmdRun.
@@ -974,6 +1072,9 @@ func (b *TinyBASIC) runProgramInternal(ctx context.Context) {
 	defer func() {
 		b.mu.Lock()
 		b.running = false
+		b.endScreenBuffer()
+		b.resetMusic()
+		b.resetWidth()
 		wasEnableSent := b.inputControlEnableSent
 		callback := b.onProgramEnd // Get callback reference before unlocking
 		b.mu.Unlock()
@@ -991,6 +1092,7 @@ func (b *TinyBASIC) runProgramInternal(ctx context.Context) {
 		if !wasEnableSent {
 			b.sendInputControl("enable")
 		}
+		b.reportBenchmark(nil)
 		// Send OK when program execution is complete
 		b.sendMessageWrapped(shared.MessageTypeText, "OK")
 		// Call the callback if set (for autorun mode to return to TinyOS)
@@ -1047,6 +1149,10 @@ func (b *TinyBASIC) runProgramInternal(ctx context.Context) {
 			b.mu.Unlock()
 			break
 		}
+		// Gedrückte Funktionstaste mit ON KEY-Handler: wie GOSUB vor der nächsten Zeile
+		b.startKeyTrap()
+		// Fälliger ON TIMER- oder ON INKEY-Handler, ebenfalls wie GOSUB
+		b.startEventTrap()
 		currentLine := b.currentLine
 		code, ok := b.program[currentLine]
 		b.mu.Unlock()
@@ -1065,8 +1171,20 @@ func (b *TinyBASIC) runProgramInternal(ctx context.Context) {
 			break
 		} // Store the original line before execution for comparison
 		originalLineBeforeExecution := currentLine
-		nextLine, err := b.executeStatement(code, nil)
+		// Pause instead of dropping output the terminal cannot take yet
+		if !waitForOutputRoom(ctx, b.OutputChan) {
+			continue
+		}
+		// A used up step budget ends the run like an error in the line
+		nextLine, err := 0, b.steps.take()
+		if err == nil {
+			nextLine, err = b.executeStatement(code, nil)
+		}
 		if err != nil {
+			// Let an ON ERROR GOTO handler take over if one is installed
+			if b.trapError(err, originalLineBeforeExecution) {
+				continue
+			}
 			b.mu.Lock()
 			b.running = false
 			terminatedLine := b.currentLine
@@ -1075,12 +1193,14 @@ func (b *TinyBASIC) runProgramInternal(ctx context.Context) {
 			b.waitingForMCPInput = false
 			b.pendingMCPCode = ""
 			b.pendingMCPFilename = ""
+			b.pendingSave = nil
 			b.mu.Unlock()
 
 			// Display program termination message with error
 			if !errors.Is(err, ErrExit) {
 				terminationMsg := fmt.Sprintf("PROGRAM TERMINATED IN LINE %d", terminatedLine)
 				b.sendMessageWrapped(shared.MessageTypeText, terminationMsg)
+				b.playErrorSFX()
 
 				// Display the actual error message
 				if basicErr, ok := err.(*BASICError); ok {
@@ -1088,6 +1208,11 @@ func (b *TinyBASIC) runProgramInternal(ctx context.Context) {
 				} else {
 					b.sendMessageWrapped(shared.MessageTypeText, err.Error())
 				}
+
+				b.mu.Lock()
+				b.lastErrorLine = terminatedLine
+				b.lastErrorMessage = err.Error()
+				b.mu.Unlock()
 			}
 			break
 		}
@@ -1127,7 +1252,12 @@ func (b *TinyBASIC) runProgramInternal(ctx context.Context) {
 		nextLineToUse := 0
 
 		// Check if currentLine was changed by the command (e.g., GOTO, IF with jump)
-		if b.currentLine != originalLineBeforeExecution {
+		if b.chained {
+			// CHAIN starts the new program at its first line, even if that
+			// has the number of the CHAIN line
+			b.chained = false
+			nextLineToUse = b.currentLine
+		} else if b.currentLine != originalLineBeforeExecution {
 			// Command changed the current line, use it
 			nextLineToUse = b.currentLine
 		} else if nextLine != originalLineBeforeExecution {
@@ -1209,8 +1339,13 @@ func (b *TinyBASIC) executeStatement(line string, ctx context.Context) (int, err
 			break
 		}
 		finalNextLine = nextLine
-		// Check if NEXT command set resumeSubStatementIndex (FOR loop continuation)
-		if b.resumeSubStatementIndex > 0 {
+		// STOP ended the run; CONT picks up the rest of the line
+		if originalCurrentLine != 0 && !b.running {
+			break
+		}
+		// Check if NEXT command set resumeSubStatementIndex (FOR loop continuation).
+		// RESUME sets it for another line, which is handled by the jump check below.
+		if b.resumeSubStatementIndex > 0 && b.currentLine == originalCurrentLine {
 			// Restart the loop from the specified index
 			startIndex = b.resumeSubStatementIndex
 			b.resumeSubStatementIndex = 0
@@ -1221,7 +1356,7 @@ func (b *TinyBASIC) executeStatement(line string, ctx context.Context) (int, err
 		// Wenn sich b.currentLine (gesetzt durch GOTO, GOSUB, IF-THEN-GOTO etc. in executeSingleStatementInternal)
 		// von der originalCurrentLine (der Zeilennummer der gesamten BASIC-Zeile) unterscheidet,
 		// bedeutet das, dass ein Sprung stattgefunden hat und wir die Verarbeitung der aktuellen Multi-Statement-Zeile abbrechen müssen.
-		if b.currentLine != originalCurrentLine {
+		if b.currentLine != originalCurrentLine || b.chained {
 			finalNextLine = b.currentLine // Der GOSUB/GOTO hat die nächste Zeile bereits gesetzt.
 			break
 		}
@@ -1254,7 +1389,7 @@ func (b *TinyBASIC) executeStatement(line string, ctx context.Context) (int, err
 // Wichtig: Diese Funktion kann die b.mu-Sperre freigeben und wiedererlangen, insbesondere für Befehle wie SAY.
 func (b *TinyBASIC) executeSingleStatementInternal(statement string, ctx context.Context) (int, error) {
 	var err error // Variable für Fehlerbehandlung
-	trimmedStatement := strings.TrimSpace(statement)
+	trimmedStatement := expandPrintShorthand(strings.TrimSpace(statement))
 	if trimmedStatement == "" {
 		// Leere Anweisung, zur nächsten Zeile gehen
 		nl, _ := b.findNextLine(b.currentLine)
@@ -1290,6 +1425,9 @@ func (b *TinyBASIC) executeSingleStatementInternal(statement string, ctx context
 	case "LET":
 		err := b.cmdLet(trimmedStatement)
 		return physicalNextLine, err
+	case "LSET", "RSET":
+		err := b.cmdJustify(command, args)
+		return physicalNextLine, err
 	case "PRINT", "PR.", "?":
 		err := b.cmdPrint(args)
 		return physicalNextLine, err
@@ -1323,6 +1461,32 @@ func (b *TinyBASIC) executeSingleStatementInternal(statement string, ctx context
 			return 0, err
 		}
 		return b.currentLine, nil // RETURN setzt b.currentLine
+	case "ON":
+		if isOnKey(args) {
+			err := b.cmdOnKey(args)
+			return physicalNextLine, err
+		}
+		if isOnEvent(args) {
+			err := b.cmdOnEvent(args)
+			return physicalNextLine, err
+		}
+		err := b.cmdOnError(args)
+		return physicalNextLine, err
+	case "KEY":
+		err := b.cmdKey(args)
+		return physicalNextLine, err
+	case "TIMER":
+		err := b.cmdTimer(args)
+		return physicalNextLine, err
+	case "OPTION":
+		err := b.cmdOption(args)
+		return physicalNextLine, err
+	case "RESUME":
+		err := b.cmdResume(args)
+		if err != nil {
+			return 0, err
+		}
+		return b.currentLine, nil // RESUME setzt b.currentLine
 	case "FOR":
 		nextSubStatementIndex, _ := b.getCurrentSubStatementInfo(trimmedStatement)
 		err = b.cmdFor(args, nextSubStatementIndex)
@@ -1336,11 +1500,19 @@ func (b *TinyBASIC) executeSingleStatementInternal(statement string, ctx context
 			return 0, err
 		}
 		return b.currentLine, nil
-	case "END", "STOP":
+	case "END":
 		return 0, nil
+	case "STOP":
+		return b.cmdStop()
+	case "CONT":
+		err := b.cmdCont(args)
+		return b.currentLine, err
 	case "LIST":
 		err := b.cmdList(args)
 		return physicalNextLine, err
+	case "DELETE":
+		err := b.cmdDelete(args)
+		return physicalNextLine, err
 	case "EDITOR":
 		err := b.cmdEditor(args)
 		return physicalNextLine, err
@@ -1355,6 +1527,14 @@ func (b *TinyBASIC) executeSingleStatementInternal(statement string, ctx context
 			return 0, err
 		}
 		return b.currentLine, nil
+	case "BENCH":
+		b.mu.Unlock()
+		_, err := b.cmdBench(args)
+		b.mu.Lock()
+		if err != nil {
+			return 0, err
+		}
+		return b.currentLine, nil
 	case "NEW":
 		b.cmdNew("")
 		return physicalNextLine, nil
@@ -1367,6 +1547,9 @@ func (b *TinyBASIC) executeSingleStatementInternal(statement string, ctx context
 	case "SAVE":
 		err := b.cmdSave(args)
 		return physicalNextLine, err
+	case "SAVEVAR":
+		err := b.cmdSaveVar(args)
+		return physicalNextLine, err
 	case "DIR":
 		listing, err := b.cmdDir(args)
 		if err != nil {
@@ -1458,6 +1641,12 @@ func (b *TinyBASIC) executeSingleStatementInternal(statement string, ctx context
 	case "CIRCLE":
 		err := b.cmdCircle(args)
 		return physicalNextLine, err
+	case "PAINT":
+		err := b.cmdPaint(args)
+		return physicalNextLine, err
+	case "DRAW":
+		err := b.cmdDraw(args)
+		return physicalNextLine, err
 	// FILL, INK, PAPER, MODE sind noch nicht in gfx_commands.go implementiert
 	// case "FILL":
 	// 	err := b.cmdFill(args)
@@ -1505,6 +1694,32 @@ func (b *TinyBASIC) executeSingleStatementInternal(statement string, ctx context
 	case "WAIT":
 		err := b.cmdWait(args)
 		return physicalNextLine, err
+	case "SLEEP":
+		err := b.cmdSleep(args)
+		return physicalNextLine, err
+	case "TEXTGFX":
+		err := b.cmdTextGFX(args)
+		return physicalNextLine, err
+	case "SCREEN":
+		err := b.cmdScreen(args)
+		return physicalNextLine, err
+	case "WIDTH":
+		err := b.cmdWidth(args)
+		return physicalNextLine, err
+	case "FLIP":
+		err := b.cmdFlip(args)
+		return physicalNextLine, err
+	case "COMMON":
+		err := b.cmdCommon(args)
+		return physicalNextLine, err
+	case "CHAIN":
+		if err := b.cmdChain(args); err != nil {
+			return 0, err
+		}
+		return b.currentLine, nil
+	case "RANDOMIZE":
+		err := b.cmdRandomize(args)
+		return physicalNextLine, err
 	case "DIM":
 		err := b.cmdDim(args)
 		return physicalNextLine, err
@@ -1556,24 +1771,28 @@ func (b *TinyBASIC) executeSingleStatementInternal(statement string, ctx context
 			return physicalNextLine, err
 		}
 		return 0, NewBASICError(ErrCategorySyntax, "UNKNOWN_COMMAND", b.currentLine == 0, b.currentLine).
-			WithCommand(command)
+			WithCommand(command).
+			WithSuggestions(commandSuggestions(command))
 
 	}
 }
 
+// knownCommands sind die Anweisungen, die executeSingleStatementInternal kennt.
+// Diese Liste sollte mit den Kommandos dort synchronisiert werden.
+var knownCommands = []string{
+	"REM", "LET", "LSET", "RSET", "PRINT", "PR.", "?", "INPUT", "IF", "GOTO", "GOSUB", "RETURN", "FOR", "NEXT",
+	"ON", "RESUME", "OPTION", "END", "STOP", "CONT", "CLS", "LIST", "DELETE", "EDITOR", "RUN", "BENCH", "NEW", "LOAD", "SAVE", "SAVEVAR", "DIR", "DEL", "HELP", "QUIT", "EXIT", "MCP",
+	"PLOT", "LINE", "RECT", "CIRCLE", "PAINT", "DRAW", "FILL", "INK", "PAPER", "MODE", "BEEP", "SOUND", "SAY", "SPEAK", "NOISE",
+	"OPEN", "CLOSE", "PRINT#", "INPUT#", "LINE INPUT#", "EOF", "DATA", "READ", "RESTORE",
+	"DIM", "SPRITE", "SPRITE ON", "SPRITE OFF", "SPRITE AT", "SPRITE COLOR", "SPRITE DEL", "SPRITE LOAD", "SPRITE SAVE",
+	"VECTOR", "VECTOR.SCALE", "VECTOR.HIDE", "VECTOR.SHOW", "VECTOR ON", "VECTOR OFF", "VECTOR AT", "VECTOR COLOR", "VECTOR DEL", "VECTOR LOAD", "VECTOR SAVE",
+	"SYSTEM", "SYS", "WAIT", "SLEEP", "RANDOMIZE", "SCREEN", "FLIP", "WIDTH", "IMAGE", "PARTICLE", "PLAYSFX", "PHYSICS",
+	"COMMON", "CHAIN", "KEY", "TIMER", "TEXTGFX",
+}
+
 // Hilfsfunktion, um zu prüfen, ob ein Kommando bekannt ist (um Zuweisungen wie A=B von Kommandos zu unterscheiden)
 func isKnownCommand(cmd string) bool {
-	// Diese Liste sollte mit den Kommandos in executeSingleStatementInternal synchronisiert werden
-	knownCmds := []string{
-		"REM", "LET", "PRINT", "PR.", "?", "INPUT", "IF", "GOTO", "GOSUB", "RETURN", "FOR", "NEXT",
-		"END", "CLS", "LIST", "EDITOR", "RUN", "NEW", "LOAD", "SAVE", "DIR", "DEL", "HELP", "QUIT", "EXIT", "MCP",
-		"PLOT", "LINE", "RECT", "CIRCLE", "FILL", "INK", "PAPER", "MODE", "BEEP", "SOUND", "SAY", "SPEAK", "NOISE",
-		"OPEN", "CLOSE", "PRINT#", "INPUT#", "LINE INPUT#", "EOF", "DATA", "READ", "RESTORE",
-		"DIM", "SPRITE", "SPRITE ON", "SPRITE OFF", "SPRITE AT", "SPRITE COLOR", "SPRITE DEL", "SPRITE LOAD", "SPRITE SAVE",
-		"VECTOR", "VECTOR.SCALE", "VECTOR.HIDE", "VECTOR.SHOW", "VECTOR ON", "VECTOR OFF", "VECTOR AT", "VECTOR COLOR", "VECTOR DEL", "VECTOR LOAD", "VECTOR SAVE",
-		"SYSTEM", "SYS", "WAIT", "IMAGE", "PARTICLE", "PLAYSFX", "PHYSICS",
-	}
-	for _, known := range knownCmds {
+	for _, known := range knownCommands {
 		if cmd == known {
 			return true
 		}
@@ -1891,14 +2110,26 @@ func (b *TinyBASIC) getCurrentSubStatementInfo(statement string) (int, int) {
 
 // SetKeyPressed setzt die aktuell gedrückte Taste für INKEY$ Abfrage (lock-free)
 func (b *TinyBASIC) SetKeyPressed(key string) {
-	// Einfache String-Zuweisung - sollte atomisch sein bei Strings in Go
-	b.currentKey = key
-
 	// Erweiterte Zustandsverfolgung (thread-safe)
 	b.mu.Lock()
 	b.keyStates[key] = true
 	b.lastKeyEvent = time.Now()
 	b.mu.Unlock()
+
+	// Funktionstasten mit ON KEY-Handler oder KEY-Makro liefert INKEY$ nicht selbst
+	if b.fnKeys.press(key) {
+		return
+	}
+	b.events.press()
+
+	// Einfache String-Zuweisung - sollte atomisch sein bei Strings in Go
+	b.currentKey = key
+
+	// Für SLEEP merken, ohne zu blockieren, wenn gerade niemand wartet
+	select {
+	case b.keyChannel <- key:
+	default:
+	}
 }
 
 // SetKeyReleased löscht eine bestimmte Taste oder alle Tasten (setzt INKEY$ auf leer) (lock-free)
@@ -1973,9 +2204,18 @@ func (b *TinyBASIC) initializeKeyConstants() {
 	b.variables["KEYUP"] = BASICValue{StrValue: KeyCurUp, IsNumeric: false}       // Alias für Hoch
 	b.variables["KEYDOWN"] = BASICValue{StrValue: KeyCurDown, IsNumeric: false}   // Alias für Runter
 
+	// Funktionstasten KEYF1 bis KEYF10
+	for i, code := range functionKeyCodes {
+		b.variables[fmt.Sprintf("KEYF%d", i+1)] = BASICValue{StrValue: code, IsNumeric: false}
+	}
+
 	// INKEY$ Variable initialisieren (leer)
 	b.variables["INKEY$"] = BASICValue{StrValue: "", IsNumeric: false}
 
+	// Fehlercode und -zeile für ON ERROR GOTO
+	b.variables["ERR"] = BASICValue{NumValue: 0, IsNumeric: true}
+	b.variables["ERL"] = BASICValue{NumValue: 0, IsNumeric: true}
+
 	// Debug-Ausgabe für erweiterte Konstanten
 	tinyBasicDebugLog("KEYLEFT re-initialized = '%s' (len=%d)", b.variables["KEYLEFT"].StrValue, len(b.variables["KEYLEFT"].StrValue))
 	tinyBasicDebugLog("KEYSPACE re-initialized = '%s' (len=%d)", b.variables["KEYSPACE"].StrValue, len(b.variables["KEYSPACE"].StrValue))
@@ -217,6 +217,36 @@ func parseBasicVal(s string) (float64, error) {
 	return strconv.ParseFloat(strings.TrimSpace(s), 64)
 }
 
+// basicInstr implements INSTR: it returns the 1-based character position of
+// needle in haystack, searching from the 1-based position start, or 0 if
+// needle does not occur. An empty needle matches at start as long as start
+// lies within the string.
+func basicInstr(start int, haystack, needle string) int {
+	if start < 1 {
+		start = 1
+	}
+	runes := []rune(haystack)
+	if start > len(runes) {
+		return 0
+	}
+	if needle == "" {
+		return start
+	}
+	idx := strings.Index(string(runes[start-1:]), needle)
+	if idx < 0 {
+		return 0
+	}
+	return start + utf8.RuneCountInString(string(runes[start-1:])[:idx])
+}
+
+// repeatBasicChar builds the result of SPACE$ and STRING$: ch repeated n times.
+func repeatBasicChar(n int, ch rune) (string, error) {
+	if n < 0 || n > MaxStringLength {
+		return "", fmt.Errorf("string length must be between 0 and %d, got %d", MaxStringLength, n)
+	}
+	return strings.Repeat(string(ch), n), nil
+}
+
 func splitRespectingQuotes(s string) []string {
 	var res []string
 	inQuote := false
@@ -254,6 +284,47 @@ func upperOutsideQuotes(s string) string {
 	return b.String()
 }
 
+// formatListLine prepares a stored program line for LIST: keywords and
+// variables are uppercased, string literals and REM comments keep their case
+func formatListLine(code string) string {
+	inQuote := false
+	atStatementStart := true
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case c == '"':
+			inQuote = !inQuote
+			atStatementStart = false
+		case inQuote:
+		case c == ':':
+			atStatementStart = true
+		case c == ' ' || c == '\t':
+		case atStatementStart && isRemAt(code, i):
+			return upperOutsideQuotes(code[:i]) + "REM" + code[i+3:]
+		default:
+			atStatementStart = false
+		}
+	}
+	return upperOutsideQuotes(code)
+}
+
+// isRemAt reports whether a REM keyword (not a name like REMARK) starts at i
+func isRemAt(code string, i int) bool {
+	if len(code) < i+3 || !strings.EqualFold(code[i:i+3], "REM") {
+		return false
+	}
+	return len(code) == i+3 || code[i+3] == ' ' || code[i+3] == '\t'
+}
+
+// expandPrintShorthand separates a leading "?" from its arguments so that
+// "?A$" and "?2+2" are dispatched like "? A$" and "? 2+2" (classic PRINT shorthand).
+func expandPrintShorthand(stmt string) string {
+	if len(stmt) > 1 && stmt[0] == '?' && stmt[1] != ' ' && stmt[1] != '\t' {
+		return "? " + stmt[1:]
+	}
+	return stmt
+}
+
 // --- Expression Parser Helpers ---
 func isComparisonOperator(op string) bool {
 	switch op {
@@ -281,7 +352,9 @@ func isLogicalOperator(op string) bool {
 	return false
 }
 
-func compareValues(left, right BASICValue, op string) (bool, error) {
+// compareValues applies a relational operator. With compareText (OPTION
+// COMPARE TEXT) strings are compared ignoring case.
+func compareValues(left, right BASICValue, op string, compareText bool) (bool, error) {
 	if left.IsNumeric != right.IsNumeric {
 		return false, fmt.Errorf("%w: cannot compare number with string using '%s'", ErrTypeMismatch, op)
 	}
@@ -303,6 +376,9 @@ func compareValues(left, right BASICValue, op string) (bool, error) {
 		}
 	} else {
 		l, r := left.StrValue, right.StrValue
+		if compareText {
+			l, r = strings.ToUpper(l), strings.ToUpper(r)
+		}
 		switch op {
 		case "=":
 			return l == r, nil
@@ -2,6 +2,7 @@ package tinybasic
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -13,6 +14,9 @@ func (b *TinyBASIC) cmdLet(args string) error {
 	if len(cleanedArgs) > 4 && strings.EqualFold(cleanedArgs[:3], "LET") && (cleanedArgs[3] == ' ' || cleanedArgs[3] == '\t') {
 		cleanedArgs = strings.TrimSpace(cleanedArgs[4:])
 	}
+	if m, ok := parseMidAssignment(cleanedArgs); ok {
+		return b.cmdMidAssign(m)
+	}
 	eqIdx := strings.Index(cleanedArgs, "=")
 	if eqIdx == -1 {
 		return NewBASICError(ErrCategorySyntax, "EXPECTED_EQUALS", b.currentLine == 0, b.currentLine).WithCommand("LET").WithUsageHint("LET var = expr")
@@ -43,12 +47,8 @@ func (b *TinyBASIC) cmdLet(args string) error {
 			finalVarNameToValidate = getCachedVarName(originalVarNameWithCase)
 		}
 	} else {
-		// An error from parseVariableWithIndex that is not nil indicates a syntax problem,
-		// e.g. "A(B" or "A(1+)"
-
-		// In this case, you could choose to validate the original varName (already ToUpper)
-		// or return the error directly. Here we validate the ToUpper name.
-		// return WrapError(errPVWI, "LET", b.currentLine == 0, b.currentLine) // Option: return error directly
+		// A malformed index like "A(1+)" or an index that cannot be evaluated
+		return WrapError(errPVWI, "LET", b.currentLine == 0, b.currentLine)
 	}
 	if !isValidVarName(finalVarNameToValidate) {
 		return NewBASICError(ErrCategorySyntax, "INVALID_VARIABLE_NAME", b.currentLine == 0, b.currentLine).WithCommand("LET")
@@ -77,18 +77,11 @@ func (b *TinyBASIC) cmdLet(args string) error {
 	}
 	if len(arrayIndices) > 0 {
 		// Array-Zugriff
-		var arrayVarName string
-		if len(arrayIndices) == 1 {
-			// 1D-Array
-			arrayVarName = fmt.Sprintf("%s(%d)", finalVarNameToValidate, arrayIndices[0])
-		} else if len(arrayIndices) == 2 {
-			// 2D-Array
-			arrayVarName = fmt.Sprintf("%s(%d,%d)", finalVarNameToValidate, arrayIndices[0], arrayIndices[1])
-		} else {
-			return NewBASICError(ErrCategorySyntax, "INVALID_ARRAY_DIMENSIONS", b.currentLine == 0, b.currentLine).WithCommand("LET")
+		arrayVarName, err := b.arrayElement(finalVarNameToValidate, arrayIndices)
+		if err != nil {
+			return err
 		}
-		// Variable Normalisierung: Speichere alle Variablen in Großbuchstaben  
-		b.variables[getCachedVarName(arrayVarName)] = value
+		b.variables[arrayVarName] = value
 	} else {
 		// Variable Normalisierung: Verwende immer Großbuchstaben für konsistente Speicherung
 		b.variables[getCachedVarName(finalVarNameToValidate)] = value
@@ -138,74 +131,102 @@ func (b *TinyBASIC) cmdRead(args string) error {
 			return NewBASICError(ErrCategorySyntax, "INVALID_VARIABLE_NAME", b.currentLine == 0, b.currentLine).WithCommand("READ")
 		}
 
-		if strings.HasSuffix(varName, "$") {
-			if len(arrayIndices) > 0 {
-				var arrayVarName string
-				if len(arrayIndices) == 1 {
-					// 1D-Array
-					arrayVarName = fmt.Sprintf("%s(%d)", varName, arrayIndices[0])
-				} else if len(arrayIndices) == 2 {
-					// 2D-Array
-					arrayVarName = fmt.Sprintf("%s(%d,%d)", varName, arrayIndices[0], arrayIndices[1])
-				} else {
-					b.dataPointer--
-					return NewBASICError(ErrCategorySyntax, "INVALID_ARRAY_DIMENSIONS", b.currentLine == 0, b.currentLine).WithCommand("READ")
-				}
-				b.variables[arrayVarName] = BASICValue{StrValue: dataValueStr, IsNumeric: false}
-			} else {
-				b.variables[varName] = BASICValue{StrValue: dataValueStr, IsNumeric: false}
+		targetName := varName
+		if len(arrayIndices) > 0 {
+			key, err := b.arrayElement(varName, arrayIndices)
+			if err != nil {
+				b.dataPointer--
+				return err
 			}
+			targetName = key
+		}
+
+		if strings.HasSuffix(varName, "$") {
+			b.variables[targetName] = BASICValue{StrValue: dataValueStr, IsNumeric: false}
 		} else {
 			f, err := strconv.ParseFloat(dataValueStr, 64)
 			if err != nil {
 				b.dataPointer--
 				return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", b.currentLine == 0, b.currentLine).WithCommand("READ")
 			}
-			if len(arrayIndices) > 0 {
-				var arrayVarName string
-				if len(arrayIndices) == 1 {
-					// 1D-Array
-					arrayVarName = fmt.Sprintf("%s(%d)", varName, arrayIndices[0])
-				} else if len(arrayIndices) == 2 {
-					// 2D-Array
-					arrayVarName = fmt.Sprintf("%s(%d,%d)", varName, arrayIndices[0], arrayIndices[1])
-				} else {
-					b.dataPointer--
-					return NewBASICError(ErrCategorySyntax, "INVALID_ARRAY_DIMENSIONS", b.currentLine == 0, b.currentLine).WithCommand("READ")
-				}
-				b.variables[arrayVarName] = BASICValue{NumValue: f, IsNumeric: true}
-			} else {
-				b.variables[varName] = BASICValue{NumValue: f, IsNumeric: true}
-			}
+			b.variables[targetName] = BASICValue{NumValue: f, IsNumeric: true}
 		}
 	}
 	return nil
 }
 
-// cmdRestore resets the DATA pointer. Assumes lock is held.
+// dataLine records where the DATA items of a program line start in b.data
+type dataLine struct {
+	line  int
+	index int
+}
+
+// cmdRestore resets the DATA pointer, either to the first DATA item or, with
+// RESTORE <line>, to the first item at or after that line. Assumes lock is held.
 func (b *TinyBASIC) cmdRestore(args string) error {
-	if args != "" {
-		return NewBASICError(ErrCategorySyntax, "UNEXPECTED_TOKEN", b.currentLine == 0, b.currentLine).WithCommand("RESTORE")
+	args = strings.TrimSpace(args)
+	if args == "" {
+		b.dataPointer = 0
+		return nil
+	}
+	lineNum, err := strconv.Atoi(args)
+	if err != nil || lineNum <= 0 {
+		return NewBASICError(ErrCategorySyntax, "INVALID_LINE_NUMBER", b.currentLine == 0, b.currentLine).WithCommand("RESTORE")
+	}
+	if _, exists := b.program[lineNum]; !exists {
+		return NewBASICError(ErrCategoryRuntime, "LINE_NOT_FOUND", b.currentLine == 0, b.currentLine).WithCommand("RESTORE")
+	}
+	b.dataPointer = len(b.data)
+	for _, dl := range b.dataLines {
+		if dl.line >= lineNum {
+			b.dataPointer = dl.index
+			break
+		}
 	}
-	b.dataPointer = 0
 	return nil
 }
 
-// rebuildData reconstructs the data list from all DATA statements in the program.
+// rebuildData reconstructs the data list from all DATA statements in the program,
+// in line number order. DATA may follow other statements on a line after a colon.
 // It should be called when the program is loaded or modified.
 func (b *TinyBASIC) rebuildData() {
 	b.data = make([]string, 0)
+	b.dataLines = b.dataLines[:0]
 	b.dataPointer = 0 // Reset data pointer
-	// b.programLines is already sorted, so we can iterate over it directly.
-	for _, lineNum := range b.programLines { // Iterate in sorted order
+
+	lines := make([]int, 0, len(b.program))
+	for lineNum := range b.program {
+		lines = append(lines, lineNum)
+	}
+	sort.Ints(lines)
+
+	for _, lineNum := range lines {
 		line := b.program[lineNum]
-		trimmedLine := strings.TrimSpace(line)
-		if strings.HasPrefix(strings.ToUpper(trimmedLine), "DATA ") {
-			dataStr := strings.TrimSpace(trimmedLine[5:]) // Get the part after "DATA "
-			items := parseDataItems(dataStr)              // Use the new local helper
-			b.data = append(b.data, items...)
+		if !strings.Contains(strings.ToUpper(line), "DATA") {
+			continue
 		}
+		start := len(b.data)
+		for _, stmt := range b.splitStatementsByColon(line) {
+			if dataStr, ok := dataStatementArgs(stmt); ok {
+				b.data = append(b.data, parseDataItems(dataStr)...)
+			}
+		}
+		if len(b.data) > start {
+			b.dataLines = append(b.dataLines, dataLine{line: lineNum, index: start})
+		}
+	}
+}
+
+// dataStatementArgs returns the item list of a DATA statement
+func dataStatementArgs(stmt string) (string, bool) {
+	stmt = strings.TrimSpace(stmt)
+	if len(stmt) < 4 || !strings.EqualFold(stmt[:4], "DATA") {
+		return "", false
+	}
+	if len(stmt) > 4 && stmt[4] != ' ' && stmt[4] != '\t' {
+		return "", false
 	}
+	return strings.TrimSpace(stmt[4:]), true
 }
 
 // parseDataItems splits a string from a DATA statement into individual items.
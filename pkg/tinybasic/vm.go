@@ -10,6 +10,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/antibyte/retroterm/pkg/shared"
 )
@@ -173,6 +174,7 @@ type ErrorContext struct {
 type VMError struct {
 	Message string
 	Context ErrorContext
+	Cause   error // Original error returned by the instruction handler
 }
 
 // Error implements the error interface
@@ -181,11 +183,17 @@ func (e *VMError) Error() string {
 		e.Context.LineNumber, e.Message, e.Context.Instruction, e.Context.OriginalCode, e.Context.PC, e.Context.StackSize)
 }
 
+// Unwrap returns the original error so errors.As/Is can inspect it
+func (e *VMError) Unwrap() error {
+	return e.Cause
+}
+
 // BytecodeVM represents the virtual machine for executing bytecode
 type BytecodeVM struct {
 	tinybasic *TinyBASIC            // Reference to TinyBASIC interpreter
 	program   *BytecodeProgram      // Compiled bytecode program
 	pc        int                   // Program counter (instruction pointer)
+	startPC   int                   // Instruction Run starts at (RUN <line>)
 	stack     *VMStack              // Execution stack
 	callStack []int                 // Call stack for GOSUB/RETURN
 	forLoops  []VMForLoop           // FOR loop stack
@@ -193,6 +201,12 @@ type BytecodeVM struct {
 	running   bool                  // Execution state
 	ctx       context.Context       // Execution context
 	cache     *InstructionCache     // Instruction cache for optimization
+	errTrap   errorTrap             // ON ERROR GOTO state
+	errorPC   int                   // Instruction that raised the trapped error
+	pause     *vmPause              // State saved by the last STOP or BREAK
+	resume    *vmPause              // State the next Run continues from (CONT)
+	runMu     sync.Mutex            // Held while Run or Resume executes
+	active    atomic.Bool           // Run or Resume has not returned yet
 }
 
 // VMForLoop represents a FOR loop in the virtual machine with optimization hints
@@ -224,6 +238,7 @@ func NewBytecodeVM(tb *TinyBASIC) *BytecodeVM {
 func (vm *BytecodeVM) LoadProgram(program *BytecodeProgram) {
 	vm.program = program
 	vm.pc = 0
+	vm.startPC = 0
 	vm.running = false
 	
 	// Clear instruction cache when loading new program
@@ -232,9 +247,22 @@ func (vm *BytecodeVM) LoadProgram(program *BytecodeProgram) {
 	}
 }
 
+// StartAt makes the next Run begin with the first instruction of lineNum
+func (vm *BytecodeVM) StartAt(lineNum int) error {
+	addr, exists := vm.program.Labels[lineNum]
+	if !exists {
+		return fmt.Errorf("undefined line number %d", lineNum)
+	}
+	vm.startPC = addr
+	return nil
+}
+
 // Reset resets the VM state with memory optimization and cache invalidation
 func (vm *BytecodeVM) Reset() {
 	vm.pc = 0
+	vm.startPC = 0
+	vm.pause = nil
+	vm.resume = nil
 	
 	// Return current stack to pool and get a fresh one
 	if vm.stack != nil {
@@ -265,9 +293,22 @@ func (vm *BytecodeVM) Run(ctx context.Context) error {
 		return fmt.Errorf("no program loaded")
 	}
 
+	vm.enter()
+	defer vm.leave()
+
 	vm.ctx = ctx
 	vm.running = true
-	vm.pc = 0
+	vm.pause = nil
+	if vm.resume != nil {
+		// CONT carries on with the registers saved when the program paused
+		vm.restore(vm.resume)
+		vm.resume = nil
+	} else {
+		// Never inherit FOR/GOSUB frames from a previous, interrupted run
+		vm.resetExecutionStacks()
+		vm.pc = vm.startPC
+		vm.errTrap.reset()
+	}
 
 	tinyBasicDebugLog("[BYTECODE-VM] Starting execution with %d instructions", len(vm.program.Instructions))
 
@@ -277,6 +318,8 @@ func (vm *BytecodeVM) Run(ctx context.Context) error {
 		case <-ctx.Done():
 			tinyBasicDebugLog("[BYTECODE-VM] Context cancelled at PC=%d", vm.pc)
 			vm.running = false
+			vm.pause = vm.pauseAtStatement()
+			vm.resetExecutionStacks()
 			return ctx.Err()
 		default:
 		}
@@ -287,9 +330,23 @@ func (vm *BytecodeVM) Run(ctx context.Context) error {
 			tinyBasicDebugLog("[BYTECODE-VM] PC=%d: Executing OpCode=%d (%s)", vm.pc, int(inst.OpCode), inst.String())
 		}
 
+		// Pause instead of dropping output the terminal cannot take yet
+		if vm.tinybasic != nil && !waitForOutputRoom(ctx, vm.tinybasic.OutputChan) {
+			continue
+		}
+		if err := vm.countStep(); err != nil {
+			vm.running = false
+			return err
+		}
+		vm.startKeyTrap()
+		vm.startEventTrap()
+
 		// Execute current instruction
 		err := vm.executeInstruction()
 		if err != nil {
+			if vm.trapError(err) {
+				continue
+			}
 			tinyBasicDebugLog("[BYTECODE-VM] Execution error at PC=%d: %v", vm.pc, err)
 			vm.running = false
 			return err
@@ -302,14 +359,58 @@ func (vm *BytecodeVM) Run(ctx context.Context) error {
 	}
 
 	tinyBasicDebugLog("[BYTECODE-VM] Execution finished. Final PC=%d, running=%v", vm.pc, vm.running)
+	if ctx.Err() != nil && vm.pause == nil {
+		// Stop() ended the loop between two instructions
+		vm.pause = vm.pauseAtStatement()
+		vm.resetExecutionStacks()
+	}
 	return nil
 }
 
-// Stop stops VM execution
+// Stop stops VM execution. Run only notices it between two instructions,
+// so Wait is needed to know that the VM has let go of its state.
 func (vm *BytecodeVM) Stop() {
 	vm.running = false
 }
 
+// Wait blocks until a Run or Resume in another goroutine has returned
+func (vm *BytecodeVM) Wait() {
+	vm.runMu.Lock()
+	vm.runMu.Unlock()
+}
+
+// enter marks the start of Run or Resume
+func (vm *BytecodeVM) enter() {
+	vm.runMu.Lock()
+	vm.active.Store(true)
+}
+
+// leave marks the end of Run or Resume
+func (vm *BytecodeVM) leave() {
+	vm.active.Store(false)
+	vm.runMu.Unlock()
+}
+
+// CurrentLine returns the BASIC line number of the instruction at the program
+// counter, or 0 if no instruction is being executed.
+func (vm *BytecodeVM) CurrentLine() int {
+	if vm.program == nil || vm.pc < 0 || vm.pc >= len(vm.program.Instructions) {
+		return 0
+	}
+	return vm.program.Instructions[vm.pc].LineNum
+}
+
+// resetExecutionStacks clears the program counter, the value stack and all
+// pending FOR/GOSUB frames while keeping variables and the loaded program.
+func (vm *BytecodeVM) resetExecutionStacks() {
+	vm.pc = 0
+	if vm.stack != nil {
+		vm.stack.Clear()
+	}
+	vm.callStack = vm.callStack[:0]
+	vm.forLoops = vm.forLoops[:0]
+}
+
 // InstructionHandler defines the signature for instruction handlers
 type InstructionHandler func(*BytecodeVM, *Instruction) error
 
@@ -378,6 +479,24 @@ var instructionHandlers = [...]InstructionHandler{
 	OP_STR_CONCAT:    (*BytecodeVM).handleStrConcat,
 	OP_STR_LEN:       (*BytecodeVM).handleStrLen,
 	OP_STR_MID:       (*BytecodeVM).handleStrMid,
+	OP_ON_ERROR:      (*BytecodeVM).handleOnError,
+	OP_RESUME:        (*BytecodeVM).handleResume,
+	OP_MID_ASSIGN:    (*BytecodeVM).handleMidAssign,
+	OP_OPTION:        (*BytecodeVM).handleOption,
+	OP_JUMP_ADDR:     (*BytecodeVM).handleJumpAddr,
+	OP_STOP:          (*BytecodeVM).handleStop,
+	OP_SOUND_WAIT:    (*BytecodeVM).handleSoundWait,
+	OP_RESTORE:       (*BytecodeVM).handleRestore,
+	OP_SLEEP:         (*BytecodeVM).handleSleep,
+	OP_SCREEN:        (*BytecodeVM).handleScreen,
+	OP_STORE_ARRAY:   (*BytecodeVM).handleStoreArray,
+	OP_COMMON:        (*BytecodeVM).handleCommon,
+	OP_CHAIN:         (*BytecodeVM).handleChain,
+	OP_ON_KEY:        (*BytecodeVM).handleOnKey,
+	OP_JUSTIFY:       (*BytecodeVM).handleJustify,
+	OP_ON_EVENT:      (*BytecodeVM).handleOnEvent,
+	OP_TIMER:         (*BytecodeVM).handleTimer,
+	OP_WIDTH:         (*BytecodeVM).handleWidth,
 }
 
 // createErrorContext creates detailed error context for debugging
@@ -414,6 +533,13 @@ func (vm *BytecodeVM) createErrorContext(inst *Instruction, message string) *VME
 	}
 }
 
+// wrapError adds debugging context to an error returned by an instruction
+func (vm *BytecodeVM) wrapError(inst *Instruction, err error) *VMError {
+	vmErr := vm.createErrorContext(inst, err.Error())
+	vmErr.Cause = err
+	return vmErr
+}
+
 // executeInstruction executes a single bytecode instruction using jump table with caching
 func (vm *BytecodeVM) executeInstruction() error {
 	if vm.pc >= len(vm.program.Instructions) {
@@ -429,11 +555,11 @@ func (vm *BytecodeVM) executeInstruction() error {
 		if err := handler(vm, &inst); err != nil {
 			// Wrap error with context if not already a VMError
 			if _, ok := err.(*VMError); !ok {
-				return vm.createErrorContext(&inst, err.Error())
+				return vm.wrapError(&inst, err)
 			}
 			return err
 		}
-		return nil
+		return vm.checkStackOverflow(&inst)
 	}
 
 	// Use jump table for O(1) dispatch
@@ -441,9 +567,9 @@ func (vm *BytecodeVM) executeInstruction() error {
 		tinyBasicDebugLog("[BYTECODE-VM] Unknown opcode: %d (array length: %d) - falling back to legacy", inst.OpCode, len(instructionHandlers))
 		// Fall back to legacy implementation for unknown opcodes
 		if err := vm.executeInstructionLegacy(); err != nil {
-			return vm.createErrorContext(&inst, err.Error())
+			return vm.wrapError(&inst, err)
 		}
-		return nil
+		return vm.checkStackOverflow(&inst)
 	}
 
 	handler := instructionHandlers[inst.OpCode]
@@ -454,12 +580,22 @@ func (vm *BytecodeVM) executeInstruction() error {
 	if err := handler(vm, &inst); err != nil {
 		// Wrap error with context if not already a VMError
 		if _, ok := err.(*VMError); !ok {
-			return vm.createErrorContext(&inst, err.Error())
+			return vm.wrapError(&inst, err)
 		}
 		return err
 	}
 	
-	return nil
+	return vm.checkStackOverflow(&inst)
+}
+
+// checkStackOverflow turns a push the value stack had to drop into a runtime
+// error. Handlers push without checking, so this one test after each
+// instruction keeps their fast path.
+func (vm *BytecodeVM) checkStackOverflow(inst *Instruction) error {
+	if !vm.stack.Overflowed() {
+		return nil
+	}
+	return vm.wrapError(inst, fmt.Errorf("%w: expression needs more than %d values", ErrStackOverflow, vm.stack.size))
 }
 
 // Optimized instruction handlers using inline operations and fast stack access
@@ -775,10 +911,10 @@ func (vm *BytecodeVM) handleEq(inst *Instruction) error {
 		result = a.NumValue == b.NumValue
 	} else if !a.IsNumeric && !b.IsNumeric {
 		// Fast string comparison
-		result = a.StrValue == b.StrValue
+		result = vm.compareString(a) == vm.compareString(b)
 	} else {
 		// Mixed type comparison (requires toString conversion)
-		result = vm.toString(a) == vm.toString(b)
+		result = vm.compareString(a) == vm.compareString(b)
 	}
 
 	// Use pre-allocated boolean values for better performance
@@ -801,10 +937,10 @@ func (vm *BytecodeVM) handleNe(inst *Instruction) error {
 		result = a.NumValue != b.NumValue
 	} else if !a.IsNumeric && !b.IsNumeric {
 		// Fast string comparison
-		result = a.StrValue != b.StrValue
+		result = vm.compareString(a) != vm.compareString(b)
 	} else {
 		// Mixed type comparison (requires toString conversion)
-		result = vm.toString(a) != vm.toString(b)
+		result = vm.compareString(a) != vm.compareString(b)
 	}
 
 	// Use pre-allocated boolean values for better performance
@@ -822,7 +958,7 @@ func (vm *BytecodeVM) handleLt(inst *Instruction) error {
 		if a.IsNumeric && b.IsNumeric {
 			result = a.NumValue < b.NumValue
 		} else {
-			result = vm.toString(a) < vm.toString(b)
+			result = vm.compareString(a) < vm.compareString(b)
 		}
 
 		vm.stack.FastPush(BASICValue{
@@ -837,7 +973,7 @@ func (vm *BytecodeVM) handleLt(inst *Instruction) error {
 		if a.IsNumeric && b.IsNumeric {
 			return a.NumValue < b.NumValue
 		}
-		return vm.toString(a) < vm.toString(b)
+		return vm.compareString(a) < vm.compareString(b)
 	})
 }
 
@@ -850,7 +986,7 @@ func (vm *BytecodeVM) handleLe(inst *Instruction) error {
 		if a.IsNumeric && b.IsNumeric {
 			result = a.NumValue <= b.NumValue
 		} else {
-			result = vm.toString(a) <= vm.toString(b)
+			result = vm.compareString(a) <= vm.compareString(b)
 		}
 
 		vm.stack.FastPush(BASICValue{
@@ -865,7 +1001,7 @@ func (vm *BytecodeVM) handleLe(inst *Instruction) error {
 		if a.IsNumeric && b.IsNumeric {
 			return a.NumValue <= b.NumValue
 		}
-		return vm.toString(a) <= vm.toString(b)
+		return vm.compareString(a) <= vm.compareString(b)
 	})
 }
 
@@ -878,7 +1014,7 @@ func (vm *BytecodeVM) handleGt(inst *Instruction) error {
 		if a.IsNumeric && b.IsNumeric {
 			result = a.NumValue > b.NumValue
 		} else {
-			result = vm.toString(a) > vm.toString(b)
+			result = vm.compareString(a) > vm.compareString(b)
 		}
 
 		vm.stack.FastPush(BASICValue{
@@ -893,7 +1029,7 @@ func (vm *BytecodeVM) handleGt(inst *Instruction) error {
 		if a.IsNumeric && b.IsNumeric {
 			return a.NumValue > b.NumValue
 		}
-		return vm.toString(a) > vm.toString(b)
+		return vm.compareString(a) > vm.compareString(b)
 	})
 }
 
@@ -906,7 +1042,7 @@ func (vm *BytecodeVM) handleGe(inst *Instruction) error {
 		if a.IsNumeric && b.IsNumeric {
 			result = a.NumValue >= b.NumValue
 		} else {
-			result = vm.toString(a) >= vm.toString(b)
+			result = vm.compareString(a) >= vm.compareString(b)
 		}
 
 		vm.stack.FastPush(BASICValue{
@@ -921,7 +1057,7 @@ func (vm *BytecodeVM) handleGe(inst *Instruction) error {
 		if a.IsNumeric && b.IsNumeric {
 			return a.NumValue >= b.NumValue
 		}
-		return vm.toString(a) >= vm.toString(b)
+		return vm.compareString(a) >= vm.compareString(b)
 	})
 }
 
@@ -1032,6 +1168,12 @@ func (vm *BytecodeVM) handlePushStr(inst *Instruction) error {
 // Native instruction handlers - optimized implementations
 func (vm *BytecodeVM) handleLoadVar(inst *Instruction) error {
 	varName := strings.ToUpper(inst.Operand1.(string))
+	if varName == "INKEY$" && vm.tinybasic != nil {
+		// Read the live key state, the copied variable never changes
+		vm.stack.FastPush(newStringBASICValue(vm.tinybasic.inkey()))
+		vm.pc++
+		return nil
+	}
 	if value, exists := vm.variables[varName]; exists {
 		// Validate that the stored type matches the expected type
 		isStringVar := strings.HasSuffix(varName, "$")
@@ -1043,7 +1185,7 @@ func (vm *BytecodeVM) handleLoadVar(inst *Instruction) error {
 			}
 			vm.variables[varName] = convertedValue // Update stored value
 			vm.stack.FastPush(convertedValue)
-		} else if !isStringVar && !value.IsNumeric {
+		} else if !isStringVar && !value.IsNumeric && !isKeyConstant(varName) {
 			// Convert string to numeric if accessing numeric variable
 			if numVal, err := strconv.ParseFloat(value.StrValue, 64); err == nil {
 				convertedValue := BASICValue{
@@ -1088,35 +1230,32 @@ func (vm *BytecodeVM) handleLoadVar(inst *Instruction) error {
 
 func (vm *BytecodeVM) handleStoreVar(inst *Instruction) error {
 	varName := strings.ToUpper(inst.Operand1.(string))
-	value := vm.stack.FastPop()
+	vm.variables[varName] = vm.convertForVariable(varName, vm.stack.FastPop())
+	vm.pc++
+	return nil
+}
 
-	// Validate and convert type if necessary
+// convertForVariable converts value to the type of the variable or array
+// varName: numbers become strings for $ names, strings become numbers (0 if
+// they are not a number) otherwise
+func (vm *BytecodeVM) convertForVariable(varName string, value BASICValue) BASICValue {
 	isStringVar := strings.HasSuffix(varName, "$")
 	if isStringVar && value.IsNumeric {
 		// Convert numeric to string for string variable
-		value = BASICValue{
+		return BASICValue{
 			StrValue:  InternString(vm.toString(value)),
 			IsNumeric: false,
 		}
-	} else if !isStringVar && !value.IsNumeric {
-		// Convert string to numeric for numeric variable
-		if numVal, err := strconv.ParseFloat(value.StrValue, 64); err == nil {
-			value = BASICValue{
-				NumValue:  numVal,
-				IsNumeric: true,
-			}
-		} else {
-			// Invalid conversion - store as 0
-			value = BASICValue{
-				NumValue:  0,
-				IsNumeric: true,
-			}
+	}
+	if !isStringVar && !value.IsNumeric {
+		// Convert string to numeric for numeric variable; invalid conversions store 0
+		numVal, _ := strconv.ParseFloat(value.StrValue, 64)
+		return BASICValue{
+			NumValue:  numVal,
+			IsNumeric: true,
 		}
 	}
-
-	vm.variables[varName] = value
-	vm.pc++
-	return nil
+	return value
 }
 
 func (vm *BytecodeVM) handlePop(inst *Instruction) error {
@@ -1163,8 +1302,39 @@ func (vm *BytecodeVM) handleJumpUnless(inst *Instruction) error {
 	return nil
 }
 
+func (vm *BytecodeVM) handleJumpAddr(inst *Instruction) error {
+	vm.pc = inst.Operand1.(int)
+	return nil
+}
+
+// handleStop ends the run like END but keeps a snapshot for CONT
+func (vm *BytecodeVM) handleStop(inst *Instruction) error {
+	vm.pc++
+	vm.pause = vm.snapshot()
+	vm.running = false
+	vm.tinybasic.sendMessageWrapped(shared.MessageTypeText, fmt.Sprintf("BREAK IN %d", inst.LineNum))
+	return nil
+}
+
+func (vm *BytecodeVM) handleSoundWait(inst *Instruction) error {
+	vm.tinybasic.soundWait = inst.Operand1.(bool)
+	vm.pc++
+	return nil
+}
+
+func (vm *BytecodeVM) handleRestore(inst *Instruction) error {
+	if err := vm.tinybasic.cmdRestore(inst.Operand1.(string)); err != nil {
+		return err
+	}
+	vm.pc++
+	return nil
+}
+
 func (vm *BytecodeVM) handleCall(inst *Instruction) error {
 	lineNum := inst.Operand1.(int)
+	if len(vm.callStack) >= MaxGosubDepth {
+		return ErrGosubDepthExceeded
+	}
 	// Push return address
 	vm.callStack = append(vm.callStack, vm.pc+1)
 	// Jump to subroutine
@@ -1340,6 +1510,7 @@ func (vm *BytecodeVM) handlePrint(inst *Instruction) error {
 	// Send to TinyBASIC output
 	if vm.tinybasic != nil {
 		vm.tinybasic.sendMessageWrapped(shared.MessageTypeText, output)
+		vm.tinybasic.cursorX = vm.tinybasic.advancePrintColumn(vm.tinybasic.cursorX, output)
 	}
 
 	vm.pc++
@@ -1347,7 +1518,6 @@ func (vm *BytecodeVM) handlePrint(inst *Instruction) error {
 	return nil
 }
 func (vm *BytecodeVM) handlePrintNL(inst *Instruction) error { return vm.handleLegacyInstruction(inst) }
-func (vm *BytecodeVM) handleInput(inst *Instruction) error   { return vm.handleLegacyInstruction(inst) }
 func (vm *BytecodeVM) handleHalt(inst *Instruction) error    { return vm.handleLegacyInstruction(inst) }
 func (vm *BytecodeVM) handleNop(inst *Instruction) error     { return vm.handleLegacyInstruction(inst) }
 func (vm *BytecodeVM) handleSound(inst *Instruction) error   { return vm.handleLegacyInstruction(inst) }
@@ -1428,7 +1598,6 @@ func (vm *BytecodeVM) handleNoise(inst *Instruction) error {
 }
 func (vm *BytecodeVM) handleBeep(inst *Instruction) error    { return vm.handleLegacyInstruction(inst) }
 func (vm *BytecodeVM) handleCls(inst *Instruction) error     { return vm.handleLegacyInstruction(inst) }
-func (vm *BytecodeVM) handleMusic(inst *Instruction) error   { return vm.handleLegacyInstruction(inst) }
 func (vm *BytecodeVM) handleSpeak(inst *Instruction) error   { return vm.handleLegacyInstruction(inst) }
 func (vm *BytecodeVM) handlePlot(inst *Instruction) error    { return vm.handleLegacyInstruction(inst) }
 func (vm *BytecodeVM) handleLine(inst *Instruction) error    { return vm.handleLegacyInstruction(inst) }
@@ -1438,113 +1607,56 @@ func (vm *BytecodeVM) handleSprite(inst *Instruction) error  { return vm.handleL
 func (vm *BytecodeVM) handleVector(inst *Instruction) error  { return vm.handleLegacyInstruction(inst) }
 func (vm *BytecodeVM) handleSay(inst *Instruction) error     { return vm.handleLegacyInstruction(inst) }
 func (vm *BytecodeVM) handleLocate(inst *Instruction) error  { return vm.handleLegacyInstruction(inst) }
-func (vm *BytecodeVM) handleColor(inst *Instruction) error   { return vm.handleLegacyInstruction(inst) }
-func (vm *BytecodeVM) handleKey(inst *Instruction) error     { return vm.handleLegacyInstruction(inst) }
+
+// handleColor rejects COLOR instead of dropping it; the compiler never emits it
+func (vm *BytecodeVM) handleColor(inst *Instruction) error {
+	return NewBASICError(ErrCategorySyntax, "UNKNOWN_COMMAND", false, inst.LineNum).WithCommand("COLOR")
+}
+
 func (vm *BytecodeVM) handleData(inst *Instruction) error    { return vm.handleLegacyInstruction(inst) }
 func (vm *BytecodeVM) handleRead(inst *Instruction) error    { return vm.handleLegacyInstruction(inst) }
-// Native DIM handler with optimized array allocation
+// handleDim creates an array. Operand1 is the name, Operand2 the number of
+// dimensions, whose upper bounds are on the stack.
 func (vm *BytecodeVM) handleDim(inst *Instruction) error {
-	// Get array specification from instruction operand
-	arraySpec := inst.Operand1.(string)
-	
-	// Parse array specification (e.g., "A(10)" or "B$(5,5)")
-	openParen := strings.IndexByte(arraySpec, '(')
-	if openParen == -1 {
-		return fmt.Errorf("DIM: invalid array specification")
-	}
-	
-	arrayName := strings.ToUpper(strings.TrimSpace(arraySpec[:openParen]))
-	dimensionsStr := strings.TrimSpace(arraySpec[openParen+1:len(arraySpec)-1])
-	
-	// Parse dimensions
-	dimensions := strings.Split(dimensionsStr, ",")
-	if len(dimensions) > 2 {
-		return fmt.Errorf("DIM: maximum 2 dimensions supported")
-	}
-	
-	// Evaluate dimension expressions
-	var sizes []int
-	for _, dimStr := range dimensions {
-		dimVal, err := vm.evaluateDimensionExpression(strings.TrimSpace(dimStr))
+	name := inst.Operand1.(string)
+	sizes := make([]int, inst.Operand2.(int))
+	for i := len(sizes) - 1; i >= 0; i-- {
+		value, err := vm.stack.Pop()
 		if err != nil {
-			return fmt.Errorf("DIM: invalid dimension expression: %v", err)
+			return err
 		}
-		if dimVal < 0 {
-			return fmt.Errorf("DIM: negative array size not allowed")
+		size, ok := subscript(value)
+		if !ok {
+			return NewBASICError(ErrCategoryEvaluation, "TYPE_MISMATCH", false, inst.LineNum).WithCommand("DIM")
 		}
-		sizes = append(sizes, dimVal)
-	}
-	
-	// Determine array type
-	isStringArray := strings.HasSuffix(arrayName, "$")
-	
-	// Optimized array allocation
-	if len(sizes) == 1 {
-		// 1D array - linear allocation
-		vm.allocate1DArray(arrayName, sizes[0], isStringArray)
-	} else {
-		// 2D array - matrix allocation
-		vm.allocate2DArray(arrayName, sizes[0], sizes[1], isStringArray)
+		if size < 0 {
+			return NewBASICError(ErrCategoryEvaluation, "INVALID_ARRAY_INDEX", false, inst.LineNum).WithCommand("DIM")
+		}
+		sizes[i] = size
 	}
-	
+	dimArray(vm.variables, name, sizes)
 	vm.pc++
 	return nil
 }
 
-// evaluateDimensionExpression evaluates array dimension expressions
-func (vm *BytecodeVM) evaluateDimensionExpression(expr string) (int, error) {
-	// For now, simple numeric parsing - could be enhanced for complex expressions
-	if val, err := strconv.ParseFloat(expr, 64); err == nil {
-		return int(val), nil
-	}
-	
-	// Could evaluate variables or expressions here
-	return 0, fmt.Errorf("complex dimension expressions not yet supported")
-}
-
-// allocate1DArray optimizes 1D array allocation
-func (vm *BytecodeVM) allocate1DArray(name string, size int, isString bool) {
-	arrayKey := name + "("
-	
-	// Store array metadata
-	vm.variables[arrayKey+"SIZE"] = BASICValue{NumValue: float64(size), IsNumeric: true}
-	vm.variables[arrayKey+"DIMS"] = BASICValue{NumValue: 1, IsNumeric: true}
-	
-	// Pre-allocate array elements with default values
-	defaultValue := BASICValue{NumValue: 0, IsNumeric: true}
-	if isString {
-		defaultValue = BASICValue{StrValue: "", IsNumeric: false}
-	}
-	
-	// Bulk allocation for better performance
-	for i := 0; i <= size; i++ {
-		vm.variables[fmt.Sprintf("%s%d)", name, i)] = defaultValue
-	}
-}
-
-// allocate2DArray optimizes 2D array allocation
-func (vm *BytecodeVM) allocate2DArray(name string, size1, size2 int, isString bool) {
-	arrayKey := name + "("
-	
-	// Store array metadata
-	vm.variables[arrayKey+"SIZE1"] = BASICValue{NumValue: float64(size1), IsNumeric: true}
-	vm.variables[arrayKey+"SIZE2"] = BASICValue{NumValue: float64(size2), IsNumeric: true}
-	vm.variables[arrayKey+"DIMS"] = BASICValue{NumValue: 2, IsNumeric: true}
-	
-	// Pre-allocate array elements with default values
-	defaultValue := BASICValue{NumValue: 0, IsNumeric: true}
-	if isString {
-		defaultValue = BASICValue{StrValue: "", IsNumeric: false}
+// handleTextGfx draws TEXTGFX text with the arguments on the stack
+func (vm *BytecodeVM) handleTextGfx(inst *Instruction) error {
+	values := make([]BASICValue, inst.Operand1.(int))
+	for i := len(values) - 1; i >= 0; i-- {
+		value, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		values[i] = value
 	}
-	
-	// Bulk allocation for better performance
-	for i := 0; i <= size1; i++ {
-		for j := 0; j <= size2; j++ {
-			vm.variables[fmt.Sprintf("%s%d,%d)", name, i, j)] = defaultValue
+	if vm.tinybasic != nil {
+		if err := vm.tinybasic.drawTextGFX(values, inst.LineNum); err != nil {
+			return err
 		}
 	}
+	vm.pc++
+	return nil
 }
-func (vm *BytecodeVM) handleTextGfx(inst *Instruction) error { return vm.handleLegacyInstruction(inst) }
 func (vm *BytecodeVM) handleClearGraphics(inst *Instruction) error {
 	return vm.handleLegacyInstruction(inst)
 }
@@ -1663,7 +1775,7 @@ func (vm *BytecodeVM) executeInstructionLegacyInternal(inst Instruction) error {
 			if a.IsNumeric && b.IsNumeric {
 				return a.NumValue == b.NumValue
 			}
-			return vm.toString(a) == vm.toString(b)
+			return vm.compareString(a) == vm.compareString(b)
 		})
 
 	case OP_NE:
@@ -1671,7 +1783,7 @@ func (vm *BytecodeVM) executeInstructionLegacyInternal(inst Instruction) error {
 			if a.IsNumeric && b.IsNumeric {
 				return a.NumValue != b.NumValue
 			}
-			return vm.toString(a) != vm.toString(b)
+			return vm.compareString(a) != vm.compareString(b)
 		})
 
 	case OP_LT:
@@ -1679,7 +1791,7 @@ func (vm *BytecodeVM) executeInstructionLegacyInternal(inst Instruction) error {
 			if a.IsNumeric && b.IsNumeric {
 				return a.NumValue < b.NumValue
 			}
-			return vm.toString(a) < vm.toString(b)
+			return vm.compareString(a) < vm.compareString(b)
 		})
 
 	case OP_LE:
@@ -1687,7 +1799,7 @@ func (vm *BytecodeVM) executeInstructionLegacyInternal(inst Instruction) error {
 			if a.IsNumeric && b.IsNumeric {
 				return a.NumValue <= b.NumValue
 			}
-			return vm.toString(a) <= vm.toString(b)
+			return vm.compareString(a) <= vm.compareString(b)
 		})
 
 	case OP_GT:
@@ -1695,7 +1807,7 @@ func (vm *BytecodeVM) executeInstructionLegacyInternal(inst Instruction) error {
 			if a.IsNumeric && b.IsNumeric {
 				return a.NumValue > b.NumValue
 			}
-			return vm.toString(a) > vm.toString(b)
+			return vm.compareString(a) > vm.compareString(b)
 		})
 
 	case OP_GE:
@@ -1703,7 +1815,7 @@ func (vm *BytecodeVM) executeInstructionLegacyInternal(inst Instruction) error {
 			if a.IsNumeric && b.IsNumeric {
 				return a.NumValue >= b.NumValue
 			}
-			return vm.toString(a) >= vm.toString(b)
+			return vm.compareString(a) >= vm.compareString(b)
 		})
 
 	// Logical operations
@@ -1861,6 +1973,9 @@ func (vm *BytecodeVM) executeInstructionLegacyInternal(inst Instruction) error {
 
 	case OP_CALL:
 		lineNum := inst.Operand1.(int)
+		if len(vm.callStack) >= MaxGosubDepth {
+			return ErrGosubDepthExceeded
+		}
 		// Push return address
 		vm.callStack = append(vm.callStack, vm.pc+1)
 		// Jump to subroutine
@@ -2033,32 +2148,14 @@ func (vm *BytecodeVM) executeInstructionLegacyInternal(inst Instruction) error {
 
 		text := vm.toString(value)
 		vm.tinybasic.sendMessageWrapped(shared.MessageTypeText, text)
+		vm.tinybasic.cursorX = vm.tinybasic.advancePrintColumn(vm.tinybasic.cursorX, text)
 		vm.pc++
 
 	case OP_PRINT_NL:
 		vm.tinybasic.sendMessageWrapped(shared.MessageTypeText, "\n")
+		vm.tinybasic.cursorX = 0
 		vm.pc++
 
-	case OP_INPUT:
-		varName := strings.ToUpper(inst.Operand1.(string))
-
-		// Pause VM execution and delegate to TinyBASIC input handling
-		vm.running = false
-
-		// Set up input state in TinyBASIC
-		vm.tinybasic.mu.Lock()
-		vm.tinybasic.inputVar = varName
-		vm.tinybasic.waitingInput = true
-		vm.tinybasic.inputPC = vm.pc + 1 // Store where to resume after input
-		vm.tinybasic.mu.Unlock()
-
-		// Send input prompt
-		vm.tinybasic.sendInputControl("enable")
-		vm.tinybasic.sendMessageWrapped(shared.MessageTypeText, "? ")
-
-		// VM will be resumed by TinyBASIC when input is received
-		return nil
-
 	// Special operations
 	case OP_HALT:
 		vm.running = false
@@ -2182,29 +2279,7 @@ func (vm *BytecodeVM) executeInstructionLegacyInternal(inst Instruction) error {
 		vm.pc++
 
 	case OP_MUSIC:
-		// Get filename from stack
-		filename, err := vm.stack.Pop()
-		if err != nil {
-			return fmt.Errorf("MUSIC: missing filename argument")
-		}
-
-		// Convert to string if numeric
-		var filenameStr string
-		if filename.IsNumeric {
-			filenameStr = vm.toString(filename)
-		} else {
-			filenameStr = filename.StrValue
-		}
-
-		// Execute MUSIC command through TinyBASIC interpreter
-		if vm.tinybasic != nil {
-			err := vm.tinybasic.cmdMusic(filenameStr)
-			if err != nil {
-				return fmt.Errorf("MUSIC execution error: %v", err)
-			}
-		}
-
-		vm.pc++
+		return vm.handleMusic(&inst)
 
 	case OP_SPEAK:
 		// Get text from stack
@@ -2502,47 +2577,10 @@ func (vm *BytecodeVM) executeInstructionLegacyInternal(inst Instruction) error {
 		vm.pc++
 
 	case OP_COLOR:
-		// Get color from stack
-		color, err := vm.stack.Pop()
-		if err != nil {
-			return fmt.Errorf("COLOR: missing color argument")
-		}
-		if !color.IsNumeric {
-			return fmt.Errorf("COLOR: color must be numeric")
-		}
-
-		// Execute COLOR command through TinyBASIC interpreter
-		if vm.tinybasic != nil {
-			// COLOR command doesn't exist in media_commands, skip for now
-			// This would need to be implemented in the TinyBASIC interpreter
-			_ = color.NumValue // Use the value to avoid unused variable warning
-		}
-
-		vm.pc++
+		return vm.handleColor(&inst)
 
 	case OP_KEY:
-		// Get arguments from stack
-		args, err := vm.stack.Pop()
-		if err != nil {
-			return fmt.Errorf("KEY: missing arguments")
-		}
-
-		// Convert to string
-		var argsStr string
-		if args.IsNumeric {
-			argsStr = vm.toString(args)
-		} else {
-			argsStr = args.StrValue
-		}
-
-		// Execute KEY command through TinyBASIC interpreter
-		if vm.tinybasic != nil {
-			// KEY command would need to be implemented in the TinyBASIC interpreter
-			// For now, just continue
-			_ = argsStr // Use the value to avoid unused variable warning
-		}
-
-		vm.pc++
+		return vm.handleKey(&inst)
 
 	case OP_DATA:
 		// Get data from stack
@@ -2584,9 +2622,14 @@ func (vm *BytecodeVM) executeInstructionLegacyInternal(inst Instruction) error {
 			varsStr = vars.StrValue
 		}
 
-		// Execute READ command through TinyBASIC interpreter
+		// Execute READ command through TinyBASIC interpreter. It reads and
+		// assigns the VM's variables, so array indices and targets are the
+		// ones the program sees.
 		if vm.tinybasic != nil {
+			saved := vm.tinybasic.variables
+			vm.tinybasic.variables = vm.variables
 			err := vm.tinybasic.cmdRead(varsStr)
+			vm.tinybasic.variables = saved
 			if err != nil {
 				return fmt.Errorf("READ execution error: %v", err)
 			}
@@ -2620,29 +2663,7 @@ func (vm *BytecodeVM) executeInstructionLegacyInternal(inst Instruction) error {
 		vm.pc++
 
 	case OP_TEXTGFX:
-		// Get arguments from stack
-		args, err := vm.stack.Pop()
-		if err != nil {
-			return fmt.Errorf("TEXTGFX: missing arguments")
-		}
-
-		// Convert to string
-		var argsStr string
-		if args.IsNumeric {
-			argsStr = vm.toString(args)
-		} else {
-			argsStr = args.StrValue
-		}
-
-		// Execute TEXTGFX command through TinyBASIC interpreter
-		if vm.tinybasic != nil {
-			err := vm.tinybasic.cmdTextGFX(argsStr)
-			if err != nil {
-				return fmt.Errorf("TEXTGFX execution error: %v", err)
-			}
-		}
-
-		vm.pc++
+		return vm.handleTextGfx(&inst)
 
 	case OP_CLEARGRAPHICS:
 		// Execute CLEARGRAPHICS command through TinyBASIC interpreter
@@ -2690,7 +2711,8 @@ func (vm *BytecodeVM) executeInstructionLegacyInternal(inst Instruction) error {
 		if vm.tinybasic != nil {
 			var args string
 			if seed != nil {
-				args = fmt.Sprintf("%g", seed.NumValue)
+				// %g would round large seeds to six digits
+				args = strconv.FormatFloat(seed.NumValue, 'f', -1, 64)
 			}
 			err := vm.tinybasic.cmdRandomize(args)
 			if err != nil {
@@ -2829,6 +2851,16 @@ func (vm *BytecodeVM) execComparison(cmp func(a, b BASICValue) bool) error {
 	return nil
 }
 
+// compareString converts a value for a string comparison, in upper case
+// after OPTION COMPARE TEXT
+func (vm *BytecodeVM) compareString(value BASICValue) string {
+	s := vm.toString(value)
+	if vm.tinybasic != nil && vm.tinybasic.compareText {
+		return strings.ToUpper(s)
+	}
+	return s
+}
+
 // toString converts a BASICValue to string with optimized integer handling
 func (vm *BytecodeVM) toString(value BASICValue) string {
 	if value.IsNumeric {
@@ -2943,8 +2975,14 @@ func (vm *BytecodeVM) callBuiltinFunction(funcName string, argCount int) error {
 				return err
 			}
 		}
-		// Generate random number between 0 and 1
-		result := rand.Float64()
+		// Generate random number between 0 and 1 from the session's source,
+		// so RANDOMIZE with a fixed seed is reproducible
+		var result float64
+		if vm.tinybasic != nil {
+			result = vm.tinybasic.random().Float64()
+		} else {
+			result = rand.Float64()
+		}
 		vm.stack.Push(newNumericBASICValue(result))
 		return nil
 
@@ -3040,7 +3078,7 @@ func (vm *BytecodeVM) callBuiltinFunction(funcName string, argCount int) error {
 		if !arg.IsNumeric {
 			return fmt.Errorf("SIN requires numeric argument")
 		}
-		result := math.Sin(arg.NumValue)
+		result := math.Sin(vm.tinybasic.angleToRadians(arg.NumValue))
 		vm.stack.Push(newNumericBASICValue(result))
 		return nil
 
@@ -3055,7 +3093,7 @@ func (vm *BytecodeVM) callBuiltinFunction(funcName string, argCount int) error {
 		if !arg.IsNumeric {
 			return fmt.Errorf("COS requires numeric argument")
 		}
-		result := math.Cos(arg.NumValue)
+		result := math.Cos(vm.tinybasic.angleToRadians(arg.NumValue))
 		vm.stack.Push(newNumericBASICValue(result))
 		return nil
 
@@ -3070,7 +3108,7 @@ func (vm *BytecodeVM) callBuiltinFunction(funcName string, argCount int) error {
 		if !arg.IsNumeric {
 			return fmt.Errorf("TAN requires numeric argument")
 		}
-		result := math.Tan(arg.NumValue)
+		result := math.Tan(vm.tinybasic.angleToRadians(arg.NumValue))
 		vm.stack.Push(newNumericBASICValue(result))
 		return nil
 
@@ -3088,7 +3126,7 @@ func (vm *BytecodeVM) callBuiltinFunction(funcName string, argCount int) error {
 		if arg.NumValue < -1 || arg.NumValue > 1 {
 			return fmt.Errorf("ASIN argument must be between -1 and 1")
 		}
-		result := math.Asin(arg.NumValue)
+		result := vm.tinybasic.angleFromRadians(math.Asin(arg.NumValue))
 		vm.stack.Push(newNumericBASICValue(result))
 		return nil
 
@@ -3106,7 +3144,7 @@ func (vm *BytecodeVM) callBuiltinFunction(funcName string, argCount int) error {
 		if arg.NumValue < -1 || arg.NumValue > 1 {
 			return fmt.Errorf("ACOS argument must be between -1 and 1")
 		}
-		result := math.Acos(arg.NumValue)
+		result := vm.tinybasic.angleFromRadians(math.Acos(arg.NumValue))
 		vm.stack.Push(newNumericBASICValue(result))
 		return nil
 
@@ -3121,7 +3159,38 @@ func (vm *BytecodeVM) callBuiltinFunction(funcName string, argCount int) error {
 		if !arg.IsNumeric {
 			return fmt.Errorf("ATAN requires numeric argument")
 		}
-		result := math.Atan(arg.NumValue)
+		result := vm.tinybasic.angleFromRadians(math.Atan(arg.NumValue))
+		vm.stack.Push(newNumericBASICValue(result))
+		return nil
+
+	case "FRE":
+		args := make([]BASICValue, argCount)
+		for i := argCount - 1; i >= 0; i-- {
+			arg, err := vm.stack.Pop()
+			if err != nil {
+				return err
+			}
+			args[i] = arg
+		}
+		vm.stack.Push(newNumericBASICValue(vm.tinybasic.freeMemory(args, vm.variables)))
+		return nil
+
+	case "ATAN2":
+		if argCount != 2 {
+			return fmt.Errorf("ATAN2 requires 2 arguments, got %d", argCount)
+		}
+		x, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		y, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		if !x.IsNumeric || !y.IsNumeric {
+			return fmt.Errorf("ATAN2 requires numeric arguments")
+		}
+		result := vm.tinybasic.angleFromRadians(math.Atan2(y.NumValue, x.NumValue))
 		vm.stack.Push(newNumericBASICValue(result))
 		return nil
 
@@ -3385,6 +3454,260 @@ func (vm *BytecodeVM) callBuiltinFunction(funcName string, argCount int) error {
 		}
 		return nil
 
+	case "INSTR":
+		if argCount != 2 && argCount != 3 {
+			return fmt.Errorf("INSTR requires 2 or 3 arguments, got %d", argCount)
+		}
+
+		needleArg, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		haystackArg, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		if needleArg.IsNumeric || haystackArg.IsNumeric {
+			return fmt.Errorf("INSTR requires string arguments")
+		}
+
+		start := 1
+		if argCount == 3 {
+			startArg, err := vm.stack.Pop()
+			if err != nil {
+				return err
+			}
+			if !startArg.IsNumeric {
+				return fmt.Errorf("INSTR start position must be numeric")
+			}
+			start = int(math.Round(startArg.NumValue))
+			if start < 1 {
+				return fmt.Errorf("INSTR start position must be >= 1")
+			}
+		}
+
+		pos := basicInstr(start, haystackArg.StrValue, needleArg.StrValue)
+		vm.stack.Push(newNumericBASICValue(float64(pos)))
+		return nil
+
+	case "HEX$", "OCT$", "BIN$":
+		if argCount != 1 {
+			return fmt.Errorf("%s requires 1 argument, got %d", funcName, argCount)
+		}
+		valueArg, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		if !valueArg.IsNumeric {
+			return fmt.Errorf("%s argument must be numeric", funcName)
+		}
+		str, err := basicRadix(valueArg.NumValue, radixFunctionBases[strings.ToUpper(funcName)])
+		if err != nil {
+			return fmt.Errorf("%s: %v", funcName, err)
+		}
+		vm.stack.Push(newStringBASICValue(str))
+		return nil
+
+	case "SPACE$":
+		if argCount != 1 {
+			return fmt.Errorf("SPACE$ requires 1 argument, got %d", argCount)
+		}
+		countArg, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		if !countArg.IsNumeric {
+			return fmt.Errorf("SPACE$ count must be numeric")
+		}
+		str, err := repeatBasicChar(int(math.Round(countArg.NumValue)), ' ')
+		if err != nil {
+			return fmt.Errorf("SPACE$: %v", err)
+		}
+		vm.stack.Push(newStringBASICValue(str))
+		return nil
+
+	case "STRING$":
+		if argCount != 2 {
+			return fmt.Errorf("STRING$ requires 2 arguments, got %d", argCount)
+		}
+		charArg, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		countArg, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		if !countArg.IsNumeric {
+			return fmt.Errorf("STRING$ count must be numeric")
+		}
+
+		var ch rune
+		if charArg.IsNumeric {
+			ch = rune(int(math.Round(charArg.NumValue)))
+		} else {
+			if charArg.StrValue == "" {
+				return fmt.Errorf("STRING$ needs a non-empty character string")
+			}
+			ch, _ = utf8.DecodeRuneInString(charArg.StrValue)
+		}
+		str, err := repeatBasicChar(int(math.Round(countArg.NumValue)), ch)
+		if err != nil {
+			return fmt.Errorf("STRING$: %v", err)
+		}
+		vm.stack.Push(newStringBASICValue(str))
+		return nil
+
+	case "REPEAT$":
+		if argCount != 2 {
+			return fmt.Errorf("REPEAT$ requires 2 arguments, got %d", argCount)
+		}
+		countArg, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		strArg, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		if strArg.IsNumeric || !countArg.IsNumeric {
+			return fmt.Errorf("REPEAT$ requires a string and a count")
+		}
+		str, err := repeatString(strArg.StrValue, int(math.Round(countArg.NumValue)))
+		if err != nil {
+			return fmt.Errorf("REPEAT$: %v", err)
+		}
+		vm.stack.Push(newStringBASICValue(str))
+		return nil
+
+	case "ENVIRON$":
+		if argCount != 1 {
+			return fmt.Errorf("ENVIRON$ requires 1 argument, got %d", argCount)
+		}
+		name, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		if name.IsNumeric {
+			return fmt.Errorf("ENVIRON$ requires a variable name")
+		}
+		vm.stack.Push(newStringBASICValue(vm.tinybasic.environ(name.StrValue)))
+		return nil
+	case "SYS$":
+		if argCount != 1 {
+			return fmt.Errorf("SYS$ requires 1 argument, got %d", argCount)
+		}
+		service, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		if service.IsNumeric {
+			return fmt.Errorf("SYS$ requires a service name")
+		}
+		result, err := vm.tinybasic.sysCall(service.StrValue)
+		if err != nil {
+			return err
+		}
+		vm.stack.Push(newStringBASICValue(result))
+		return nil
+	case "FORMAT$":
+		if argCount < 1 {
+			return fmt.Errorf("FORMAT$ requires a format string")
+		}
+		// Arguments are on the stack in reverse order, the format string lowest
+		args := make([]BASICValue, argCount)
+		for i := argCount - 1; i >= 0; i-- {
+			arg, err := vm.stack.Pop()
+			if err != nil {
+				return err
+			}
+			args[i] = arg
+		}
+		if args[0].IsNumeric {
+			return fmt.Errorf("FORMAT$ format must be a string")
+		}
+		str, err := basicFormat(args[0].StrValue, args[1:])
+		if err != nil {
+			return fmt.Errorf("FORMAT$: %v", err)
+		}
+		vm.stack.Push(newStringBASICValue(str))
+		return nil
+
+	case "PRINT_ZONE":
+		// Comma in PRINT: pad to the next print zone
+		if vm.tinybasic != nil {
+			padding := vm.tinybasic.printZonePadding(vm.tinybasic.cursorX)
+			vm.tinybasic.sendMessageWrapped(shared.MessageTypeText, padding)
+			vm.tinybasic.cursorX = vm.tinybasic.advancePrintColumn(vm.tinybasic.cursorX, padding)
+		}
+		return nil
+
+	case "DRAW":
+		// DRAW commands$ - the string is already evaluated on the stack
+		arg, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		if vm.tinybasic != nil {
+			return vm.tinybasic.draw(arg)
+		}
+		return nil
+
+	case "SAVEVAR":
+		// SAVEVAR key$, value - both are already evaluated on the stack
+		value, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		key, err := vm.stack.Pop()
+		if err != nil {
+			return err
+		}
+		return vm.tinybasic.saveVar(key, value)
+
+	case "LOADVAR$", "LOADVAR":
+		if argCount < 1 || argCount > 2 {
+			return fmt.Errorf("%s requires 1 or 2 arguments, got %d", funcName, argCount)
+		}
+		args := make([]BASICValue, argCount)
+		for i := argCount - 1; i >= 0; i-- {
+			arg, err := vm.stack.Pop()
+			if err != nil {
+				return err
+			}
+			args[i] = arg
+		}
+		result, err := vm.tinybasic.loadVar(args, funcName == "LOADVAR")
+		if err != nil {
+			return err
+		}
+		vm.stack.Push(result)
+		return nil
+
+	case "PAINT":
+		// PAINT x, y, color [, border] - arguments are already evaluated on the stack
+		if argCount < 3 || argCount > 4 {
+			return fmt.Errorf("PAINT requires 3 or 4 arguments, got %d", argCount)
+		}
+		args := make([]BASICValue, argCount)
+		for i := argCount - 1; i >= 0; i-- {
+			arg, err := vm.stack.Pop()
+			if err != nil {
+				return err
+			}
+			args[i] = arg
+		}
+		if vm.tinybasic != nil {
+			var border *BASICValue
+			if argCount == 4 {
+				border = &args[3]
+			}
+			if err := vm.tinybasic.paint(args[0], args[1], args[2], border); err != nil {
+				return err
+			}
+		}
+		return nil
+
 	case "PHYSICS":
 		// PHYSICS commands - delegate to TinyBASIC physics system
 		if argCount != 1 {
@@ -3414,8 +3737,15 @@ func (vm *BytecodeVM) callBuiltinFunction(funcName string, argCount int) error {
 		return nil
 
 	default:
+		// A name that is no function is an array element, A(I,J)
+		if handled, err := vm.loadArrayElement(funcName, argCount); handled {
+			return err
+		}
 		// For complex functions, fall back to TinyBASIC
 		// This is not ideal but ensures compatibility
+		if hint := shared.DidYouMean(commandSuggestions(funcName)); hint != "" {
+			return fmt.Errorf("unsupported function in bytecode: %s (%s)", funcName, strings.ToUpper(hint))
+		}
 		return fmt.Errorf("unsupported function in bytecode: %s", funcName)
 	}
 }
@@ -3436,7 +3766,7 @@ func (vm *BytecodeVM) GetPC() int {
 
 // IsRunning returns whether VM is currently running
 func (vm *BytecodeVM) IsRunning() bool {
-	return vm.running
+	return vm.running || vm.active.Load()
 }
 
 // GetPerformanceStats returns comprehensive performance statistics
@@ -3472,6 +3802,9 @@ func (vm *BytecodeVM) Resume(pc int, inputValue BASICValue, varName string) erro
 		vm.variables[strings.ToUpper(varName)] = inputValue
 	}
 
+	vm.enter()
+	defer vm.leave()
+
 	// Resume execution from the specified PC
 	vm.pc = pc
 	vm.running = true
@@ -3482,13 +3815,27 @@ func (vm *BytecodeVM) Resume(pc int, inputValue BASICValue, varName string) erro
 		select {
 		case <-vm.ctx.Done():
 			vm.running = false
+			vm.resetExecutionStacks()
 			return vm.ctx.Err()
 		default:
 		}
 
+		if vm.tinybasic != nil && !waitForOutputRoom(vm.ctx, vm.tinybasic.OutputChan) {
+			continue
+		}
+		if err := vm.countStep(); err != nil {
+			vm.running = false
+			return err
+		}
+		vm.startKeyTrap()
+		vm.startEventTrap()
+
 		// Execute current instruction
 		err := vm.executeInstruction()
 		if err != nil {
+			if vm.trapError(err) {
+				continue
+			}
 			vm.running = false
 			return err
 		}
@@ -0,0 +1,77 @@
+package tinybasic
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestVMStackOverflow checks that pushing past the capacity is reported
+// instead of panicking and that Clear makes the stack usable again
+func TestVMStackOverflow(t *testing.T) {
+	s := NewVMStack(1000)
+	defer ReturnVMStack(s)
+	for i := 0; i < s.size; i++ {
+		if err := s.Push(BASICValue{NumValue: float64(i), IsNumeric: true}); err != nil {
+			t.Fatalf("Push %d: %v", i, err)
+		}
+	}
+	if s.Overflowed() {
+		t.Fatal("full stack reported as overflowed")
+	}
+
+	if err := s.Push(BASICValue{IsNumeric: true}); !errors.Is(err, ErrStackOverflow) {
+		t.Errorf("Push on full stack = %v, want ErrStackOverflow", err)
+	}
+	s.FastPush(BASICValue{NumValue: -1, IsNumeric: true})
+	if !s.Overflowed() || s.Size() != s.size {
+		t.Errorf("after FastPush: overflowed=%v size=%d, want true and %d", s.Overflowed(), s.Size(), s.size)
+	}
+	if top, _ := s.Peek(); top.NumValue != float64(s.size-1) {
+		t.Errorf("FastPush overwrote the top value: %v", top.NumValue)
+	}
+
+	s.Clear()
+	if s.Overflowed() {
+		t.Error("Clear did not reset the overflow")
+	}
+}
+
+// TestVMStackOverflowRuntimeError runs a program that pushes more values than
+// the stack holds and expects a VMError, not a crash
+func TestVMStackOverflowRuntimeError(t *testing.T) {
+	vm := NewBytecodeVM(nil)
+	program := &BytecodeProgram{
+		Constants:    []interface{}{1.0},
+		Labels:       map[int]int{10: 0},
+		OriginalCode: map[int]string{10: "PRINT 1+(1+(1+..."},
+	}
+	for i := 0; i <= vm.stack.size; i++ {
+		program.Instructions = append(program.Instructions, Instruction{OpCode: OP_PUSH_NUM, Operand1: 0, LineNum: 10})
+	}
+	program.Instructions = append(program.Instructions, Instruction{OpCode: OP_HALT, LineNum: 10})
+	vm.LoadProgram(program)
+
+	err := vm.Run(context.Background())
+	var vmErr *VMError
+	if !errors.As(err, &vmErr) || !errors.Is(err, ErrStackOverflow) {
+		t.Fatalf("Run = %v, want a VMError wrapping ErrStackOverflow", err)
+	}
+	if vmErr.Context.LineNumber != 10 || vmErr.Context.StackSize != vm.stack.size {
+		t.Errorf("context line=%d stack=%d, want 10 and %d", vmErr.Context.LineNumber, vmErr.Context.StackSize, vm.stack.size)
+	}
+}
+
+// TestGosubDepthBytecode checks that endless GOSUB recursion in the VM stops
+// at MaxGosubDepth with a trappable out of memory error
+func TestGosubDepthBytecode(t *testing.T) {
+	out := runTrapProgram(t, true,
+		`10 ON ERROR GOTO 100`,
+		`20 GOSUB 20`,
+		`100 IF ERR = 7 AND ERL = 20 THEN PRINT "TRAPPED"`,
+	)
+	if !strings.Contains(out, "TRAPPED") {
+		t.Errorf("output %q does not contain TRAPPED", out)
+	}
+}
@@ -0,0 +1,102 @@
+package tinybasic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// WIDTH n switches the text screen to n columns, like the 40 column mode of
+// the home computers. PRINT wraps and the comma zones end at the new width,
+// and the frontend stretches the characters so the narrower lines still
+// fill the screen; like in GW-BASIC, a new width clears the screen. When the
+// run ends, is stopped or BREAKs, the width falls back to the one of the
+// terminal.
+
+// supportedWidths are the column counts WIDTH accepts
+var supportedWidths = []int{40, 80}
+
+// widthUsage is the usage hint for WIDTH
+const widthUsage = "WIDTH 40 | WIDTH 80"
+
+// validWidth reports whether WIDTH accepts n columns
+func validWidth(n float64) bool {
+	for _, w := range supportedWidths {
+		if n == float64(w) {
+			return true
+		}
+	}
+	return false
+}
+
+// cmdWidth implements WIDTH n. Assumes lock is held.
+func (b *TinyBASIC) cmdWidth(args string) error {
+	args = strings.TrimSpace(args)
+	if args == "" {
+		return NewBASICError(ErrCategorySyntax, "EXPECTED_EXPRESSION", b.currentLine == 0, b.currentLine).
+			WithCommand("WIDTH").WithUsageHint(widthUsage)
+	}
+	val, err := b.evalExpression(args)
+	if err != nil {
+		return WrapError(err, "WIDTH", b.currentLine == 0, b.currentLine)
+	}
+	if !val.IsNumeric || !validWidth(val.NumValue) {
+		return NewBASICError(ErrCategorySyntax, "INVALID_PARAMETER_VALUE", b.currentLine == 0, b.currentLine).
+			WithCommand("WIDTH").WithUsageHint(widthUsage)
+	}
+	return b.setWidth(int(val.NumValue))
+}
+
+// setWidth switches PRINT formatting and the frontend to cols columns.
+// Assumes lock is held.
+func (b *TinyBASIC) setWidth(cols int) error {
+	b.widthSet = cols != b.screenCols
+	if cols == b.termCols {
+		return nil
+	}
+	b.termCols = cols
+	b.cursorX = 0 // The frontend clears the screen
+	if !b.sendWidthMessage(cols) {
+		return NewBASICError(ErrCategorySystem, "MESSAGE_SEND_FAILED", b.currentLine == 0, b.currentLine).WithCommand("WIDTH")
+	}
+	return nil
+}
+
+// sendWidthMessage tells the frontend how many columns to show
+func (b *TinyBASIC) sendWidthMessage(cols int) bool {
+	return b.sendMessageObject(shared.Message{
+		Type:    shared.MessageTypeGraphics,
+		Command: "TEXT_WIDTH",
+		Params:  map[string]interface{}{"cols": cols},
+	})
+}
+
+// resetWidth returns to the width of the terminal at the end of a run.
+// Assumes lock is held.
+func (b *TinyBASIC) resetWidth() {
+	if b.widthSet {
+		b.setWidth(b.screenCols)
+	}
+}
+
+// handleWidth is the bytecode version of WIDTH: pops the column count
+func (vm *BytecodeVM) handleWidth(inst *Instruction) error {
+	val, err := vm.stack.Pop()
+	if err != nil {
+		return err
+	}
+	if !val.IsNumeric || !validWidth(val.NumValue) {
+		return fmt.Errorf("WIDTH: only %v columns are supported", supportedWidths)
+	}
+	if b := vm.tinybasic; b != nil {
+		b.mu.Lock()
+		err = b.setWidth(int(val.NumValue))
+		b.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	vm.pc++
+	return nil
+}
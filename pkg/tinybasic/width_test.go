@@ -0,0 +1,93 @@
+package tinybasic
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// widthMessages collects the TEXT_WIDTH messages sent so far
+func widthMessages(b *TinyBASIC) []string {
+	var widths []string
+	for len(b.OutputChan) > 0 {
+		msg := <-b.OutputChan
+		if msg.Type == shared.MessageTypeGraphics && msg.Command == "TEXT_WIDTH" {
+			widths = append(widths, fmt.Sprint(msg.Params["cols"]))
+		}
+	}
+	return widths
+}
+
+// TestWidthResetsAtProgramEnd switches to 40 columns and expects the
+// terminal width back when the program ends
+func TestWidthResetsAtProgramEnd(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		b := NewTinyBASIC(nil)
+		startSleepProgram(b, bytecode,
+			`10 WIDTH 40`,
+			`20 WIDTH 40`,
+			`30 END`,
+		)
+		waitUntilStopped(t, b)
+
+		if got, want := widthMessages(b), []string{"40", "80"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("bytecode=%v: width messages %v, want %v", bytecode, got, want)
+		}
+		if b.termCols != 80 {
+			t.Errorf("bytecode=%v: termCols = %d after the run, want 80", bytecode, b.termCols)
+		}
+	}
+}
+
+// TestWidthRejectsUnsupported expects an error for widths other than 40
+// and 80 and no message to the frontend
+func TestWidthRejectsUnsupported(t *testing.T) {
+	for _, bytecode := range []bool{false, true} {
+		for _, stmt := range []string{`WIDTH 50`, `WIDTH "40"`, `WIDTH`} {
+			out := runTrapProgram(t, bytecode,
+				`10 ON ERROR GOTO 100`,
+				`20 `+stmt,
+				`30 PRINT "NO ERROR"`,
+				`40 END`,
+				`100 PRINT "TRAPPED"`,
+			)
+			if !strings.Contains(out, "TRAPPED") || strings.Contains(out, "NO ERROR") {
+				t.Errorf("bytecode=%v %s: output %q", bytecode, stmt, out)
+			}
+		}
+	}
+}
+
+// TestWidthSurvivesTerminalDimensions checks that the terminal size the
+// handler passes with every input does not undo WIDTH, and that the end of
+// the run returns to the latest size
+func TestWidthSurvivesTerminalDimensions(t *testing.T) {
+	b := NewTinyBASIC(nil)
+	b.mu.Lock()
+	if err := b.setWidth(40); err != nil {
+		t.Fatalf("setWidth: %v", err)
+	}
+	b.mu.Unlock()
+
+	b.SetTerminalDimensions(100, 30)
+	if b.termCols != 40 {
+		t.Errorf("termCols = %d after SetTerminalDimensions, want 40", b.termCols)
+	}
+	if got := b.printZonePadding(30); got != "\n" {
+		t.Errorf("zone after column 30 at width 40 = %q, want a new line", got)
+	}
+
+	b.mu.Lock()
+	b.resetWidth()
+	b.mu.Unlock()
+	if b.termCols != 100 {
+		t.Errorf("termCols = %d after the reset, want 100", b.termCols)
+	}
+	b.SetTerminalDimensions(80, 24)
+	if b.termCols != 80 {
+		t.Errorf("termCols = %d, want SetTerminalDimensions to apply again", b.termCols)
+	}
+}
@@ -0,0 +1,27 @@
+package tinyos
+
+import (
+	"github.com/antibyte/retroterm/pkg/configuration"
+)
+
+// autorunFile is the program in a user's home that BASIC runs when it
+// starts, like a shell rc file
+const autorunFile = "autorun.bas"
+
+// AutorunParam is the Params key of the BASIC mode message that names the
+// autorun program the terminal should start
+const AutorunParam = "autorun"
+
+// autorunProgram returns the path of the autorun program to start with
+// BASIC, or "" if there is none. Guests never get one, and the [BasicPrograms]
+// setting autorun switches the feature off for everybody.
+func (os *TinyOS) autorunProgram(sessionID, username string) string {
+	if username == "" || os.isGuestSession(sessionID) || !configuration.GetBool("BasicPrograms", "autorun", true) {
+		return ""
+	}
+	path := "/home/" + username + "/" + autorunFile
+	if !os.Vfs.Exists(path, sessionID) {
+		return ""
+	}
+	return path
+}
@@ -0,0 +1,180 @@
+package tinyos
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits of the values BASIC programs store with SAVEVAR
+const (
+	maxBasicVarsPerUser    = 64
+	maxBasicVarKeyLength   = 32
+	maxBasicVarValueLength = 256
+)
+
+// Errors of SaveBasicVar and LoadBasicVar. The BASIC commands show their text.
+var (
+	ErrBasicVarKey     = fmt.Errorf("key must be 1 to %d letters, digits, _, - or .", maxBasicVarKeyLength)
+	ErrBasicVarTooLong = fmt.Errorf("value is longer than %d characters", maxBasicVarValueLength)
+	ErrBasicVarLimit   = fmt.Errorf("no more than %d keys per user", maxBasicVarsPerUser)
+	ErrBasicVarStore   = errors.New("could not access the stored values")
+)
+
+// BasicVar is a value stored with SAVEVAR. It keeps its type, so a number
+// comes back as a number.
+type BasicVar struct {
+	Numeric bool
+	Num     float64
+	Str     string
+}
+
+// basicVarStore holds the values of guests for as long as their session
+// lives, and of users when there is no database
+type basicVarStore struct {
+	mu     sync.Mutex
+	owners map[string]map[string]BasicVar // Owner (session or user) to its values
+}
+
+// normalizeBasicVarKey checks a key and returns it in upper case, so "Score"
+// and "SCORE" are the same key like BASIC variable names
+func normalizeBasicVarKey(key string) (string, error) {
+	if key == "" || len(key) > maxBasicVarKeyLength {
+		return "", ErrBasicVarKey
+	}
+	for _, c := range key {
+		switch {
+		case c == '_', c == '-', c == '.', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		default:
+			return "", ErrBasicVarKey
+		}
+	}
+	return strings.ToUpper(key), nil
+}
+
+// basicVarOwner returns whose values a session uses: the user name of a
+// logged-in user, or "" for guests, whose values stay in memory
+func (os *TinyOS) basicVarOwner(sessionID string) string {
+	if os.isGuestSession(sessionID) {
+		return ""
+	}
+	return os.GetUsernameForSession(sessionID)
+}
+
+// SaveBasicVar stores a value for the user of the session. Guests keep their
+// values in memory until the session ends.
+func (os *TinyOS) SaveBasicVar(sessionID, key string, value BasicVar) error {
+	key, err := normalizeBasicVarKey(key)
+	if err != nil {
+		return err
+	}
+	if !value.Numeric && len(value.Str) > maxBasicVarValueLength {
+		return ErrBasicVarTooLong
+	}
+
+	username := os.basicVarOwner(sessionID)
+	if username == "" || os.db == nil {
+		return os.basicVars.save(memoryBasicVarOwner(sessionID, username), key, value)
+	}
+
+	var exists, count int
+	if err := os.db.QueryRow("SELECT COUNT(*), COALESCE(SUM(var_key = ?), 0) FROM basic_vars WHERE username = ?", key, username).Scan(&count, &exists); err != nil {
+		return fmt.Errorf("%w: %v", ErrBasicVarStore, err)
+	}
+	if exists == 0 && count >= maxBasicVarsPerUser {
+		return ErrBasicVarLimit
+	}
+	varType, stored := basicVarColumns(value)
+	if _, err := os.db.Exec("INSERT OR REPLACE INTO basic_vars (username, var_key, var_type, var_value, updated_at) VALUES (?, ?, ?, ?, ?)",
+		username, key, varType, stored, time.Now().Unix()); err != nil {
+		return fmt.Errorf("%w: %v", ErrBasicVarStore, err)
+	}
+	return nil
+}
+
+// LoadBasicVar reads a value of the user of the session. found is false if
+// the key was never saved.
+func (os *TinyOS) LoadBasicVar(sessionID, key string) (value BasicVar, found bool, err error) {
+	key, err = normalizeBasicVarKey(key)
+	if err != nil {
+		return BasicVar{}, false, err
+	}
+
+	username := os.basicVarOwner(sessionID)
+	if username == "" || os.db == nil {
+		value, found = os.basicVars.load(memoryBasicVarOwner(sessionID, username), key)
+		return value, found, nil
+	}
+
+	var varType, stored string
+	err = os.db.QueryRow("SELECT var_type, var_value FROM basic_vars WHERE username = ? AND var_key = ?", username, key).Scan(&varType, &stored)
+	if err == sql.ErrNoRows {
+		return BasicVar{}, false, nil
+	}
+	if err != nil {
+		return BasicVar{}, false, fmt.Errorf("%w: %v", ErrBasicVarStore, err)
+	}
+	if varType != "N" {
+		return BasicVar{Str: stored}, true, nil
+	}
+	num, err := strconv.ParseFloat(stored, 64)
+	if err != nil {
+		return BasicVar{}, false, fmt.Errorf("%w: %v", ErrBasicVarStore, err)
+	}
+	return BasicVar{Numeric: true, Num: num}, true, nil
+}
+
+// forgetGuestBasicVars drops the values a guest saved in the session
+func (os *TinyOS) forgetGuestBasicVars(sessionID string) {
+	os.basicVars.mu.Lock()
+	delete(os.basicVars.owners, memoryBasicVarOwner(sessionID, ""))
+	os.basicVars.mu.Unlock()
+}
+
+// basicVarColumns returns the type and the text stored in basic_vars
+func basicVarColumns(value BasicVar) (string, string) {
+	if value.Numeric {
+		return "N", strconv.FormatFloat(value.Num, 'g', -1, 64)
+	}
+	return "S", value.Str
+}
+
+// memoryBasicVarOwner is the key of the values in memory. Guests share the
+// name "guest", so their values belong to the session.
+func memoryBasicVarOwner(sessionID, username string) string {
+	if username == "" {
+		return "session:" + sessionID
+	}
+	return "user:" + username
+}
+
+// save stores a value in memory with the same limit as the database
+func (s *basicVarStore) save(owner, key string, value BasicVar) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.owners == nil {
+		s.owners = make(map[string]map[string]BasicVar)
+	}
+	values := s.owners[owner]
+	if values == nil {
+		values = make(map[string]BasicVar)
+		s.owners[owner] = values
+	}
+	if _, exists := values[key]; !exists && len(values) >= maxBasicVarsPerUser {
+		return ErrBasicVarLimit
+	}
+	values[key] = value
+	return nil
+}
+
+// load reads a value from memory
+func (s *basicVarStore) load(owner, key string) (BasicVar, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, found := s.owners[owner][key]
+	return value, found
+}
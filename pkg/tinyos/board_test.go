@@ -0,0 +1,45 @@
+package tinyos
+
+import "testing"
+
+// boardHandles returns the handles the who list currently shows
+func boardHandles(os *TinyOS) map[string]bool {
+	handles := make(map[string]bool)
+	for _, user := range os.boardPresence() {
+		handles[user.Handle] = true
+	}
+	return handles
+}
+
+// TestBoardWhoHideIsRemembered checks that "who hide" lasts beyond the board
+// session it was typed in and that "who show" undoes it
+func TestBoardWhoHideIsRemembered(t *testing.T) {
+	os := newDBTestOS(t, map[string]string{"a1": "alice", "a2": "alice", "b": "bob"})
+	addTestUser(t, os, "alice", false)
+	addTestUser(t, os, "bob", false)
+
+	os.cmdBoard([]string{"a1"})
+	os.cmdBoard([]string{"b"})
+	if got := boardHandles(os); !got["alice"] || !got["bob"] {
+		t.Fatalf("who list = %v, want alice and bob", got)
+	}
+
+	os.HandleBoardInput("who hide", "a1")
+	if got := boardHandles(os); got["alice"] || !got["bob"] {
+		t.Errorf("who list after hide = %v, want only bob", got)
+	}
+
+	// A later board session of alice starts hidden
+	os.exitBoard("a1")
+	os.cmdBoard([]string{"a2"})
+	if got := boardHandles(os); got["alice"] {
+		t.Errorf("who list after a new board session = %v, alice should stay hidden", got)
+	}
+
+	os.HandleBoardInput("who show", "a2")
+	os.exitBoard("a2")
+	os.cmdBoard([]string{"a1"})
+	if got := boardHandles(os); !got["alice"] {
+		t.Errorf("who list after show = %v, want alice again", got)
+	}
+}
@@ -0,0 +1,151 @@
+package tinyos
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// catStreamChunk is how many bytes the cat pager reads from a file at a
+// time. The pager holds at most about one page of lines plus one chunk of
+// read-ahead, however large the file is.
+const catStreamChunk = 16 * 1024
+
+// catStream reads a file for the cat pager piece by piece. It keeps no file
+// open between reads, so a pager quit in the middle of a file only leaves
+// its buffer behind for the garbage collector.
+type catStream struct {
+	path      string
+	sessionID string
+	cols      int    // Terminal width the lines are wrapped to
+	offset    int    // Bytes read so far
+	size      int    // File size at the last read
+	pending   []byte // Start of a line whose end has not been read yet
+	eof       bool
+}
+
+// rewind starts reading the file from the beginning again
+func (s *catStream) rewind() {
+	s.offset, s.pending, s.eof = 0, nil, false
+}
+
+// readCatStream reads the next chunk of a stream and returns its complete
+// lines, wrapped to the terminal, together with the file offset behind each
+// line. Lines without a newline are held back until the next chunk, unless
+// they are longer than a chunk or the file ends.
+func (os *TinyOS) readCatStream(s *catStream) ([]string, []int, error) {
+	if s.eof {
+		return nil, nil, nil
+	}
+	chunk, size, err := os.Vfs.ReadFileChunk(s.path, s.sessionID, s.offset, catStreamChunk)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.size = size
+	base := s.offset - len(s.pending) // File offset of data[0]
+	s.offset += len(chunk)
+	if len(chunk) == 0 || s.offset >= size {
+		s.eof = true
+	}
+	data := append(s.pending, chunk...)
+	s.pending = nil
+
+	var lines []string
+	var ends []int
+	addLine := func(raw []byte, end int) {
+		// Windows (\r\n) and old Mac (\r) line breaks become line breaks too
+		parts := strings.Split(strings.TrimSuffix(string(raw), "\r"), "\r")
+		for _, line := range os.wrapLinesForTerminal(parts, s.cols) {
+			lines = append(lines, line)
+			ends = append(ends, base+end)
+		}
+	}
+
+	pos := 0
+	for pos < len(data) {
+		nl := bytes.IndexByte(data[pos:], '\n')
+		if nl >= 0 {
+			addLine(data[pos:pos+nl], pos+nl+1)
+			pos += nl + 1
+			continue
+		}
+		rest := len(data) - pos
+		if s.eof {
+			addLine(data[pos:], len(data))
+			break
+		}
+		if rest <= catStreamChunk {
+			s.pending = append([]byte(nil), data[pos:]...)
+			break
+		}
+		// A line longer than a chunk is shown in pieces, cut between runes
+		cut := pos + catStreamChunk
+		for cut > pos && !utf8.RuneStart(data[cut]) {
+			cut--
+		}
+		if cut == pos {
+			cut = pos + catStreamChunk
+		}
+		addLine(data[pos:cut], cut)
+		pos = cut
+	}
+	return lines, ends, nil
+}
+
+// catPagerLine returns line i of the pager content, or false behind the
+// end. For a streamed file it reads ahead as far as needed and drops lines
+// more than a page above i; a line before the buffer means reading the file
+// again from the start.
+func (os *TinyOS) catPagerLine(state *CatPagerState, i int) (string, bool, error) {
+	if i < 0 {
+		return "", false, nil
+	}
+	if state.Stream == nil {
+		if i >= len(state.Lines) {
+			return "", false, nil
+		}
+		return state.Lines[i], true, nil
+	}
+
+	if i < state.Base {
+		state.Stream.rewind()
+		state.Lines, state.LineEnds, state.Base = nil, nil, 0
+	}
+	for i >= state.Base+len(state.Lines) {
+		if state.Stream.eof {
+			return "", false, nil
+		}
+		if drop := i - state.PageSize - state.Base; drop > 0 {
+			if drop > len(state.Lines) {
+				drop = len(state.Lines)
+			}
+			// Copy, so the dropped lines are not kept alive by the array
+			state.Lines = append([]string(nil), state.Lines[drop:]...)
+			state.LineEnds = append([]int(nil), state.LineEnds[drop:]...)
+			state.Base += drop
+		}
+		lines, ends, err := os.readCatStream(state.Stream)
+		if err != nil {
+			return "", false, err
+		}
+		state.Lines = append(state.Lines, lines...)
+		state.LineEnds = append(state.LineEnds, ends...)
+	}
+	return state.Lines[i-state.Base], true, nil
+}
+
+// catPagerPercent estimates how much of the content lies above line end:
+// by bytes for a streamed file, by lines otherwise
+func catPagerPercent(state *CatPagerState, end int) int {
+	if state.Stream == nil {
+		if len(state.Lines) == 0 {
+			return 100
+		}
+		return end * 100 / len(state.Lines)
+	}
+	last := end - 1 - state.Base
+	if state.Stream.size == 0 || last < 0 || last >= len(state.LineEnds) {
+		return 100
+	}
+	return state.LineEnds[last] * 100 / state.Stream.size
+}
@@ -0,0 +1,91 @@
+package tinyos
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/auth"
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// errTimedOut is returned by runWithTimeout when the work did not finish in time
+var errTimedOut = errors.New("timed out")
+
+// untimedCommands start an interactive mode and may legitimately wait for the
+// user or a remote host, so they run without the command timeout
+var untimedCommands = map[string]bool{
+	"edit": true, "telnet": true, "chess": true, "board": true, "basic": true,
+	"run": true, "login": true, "register": true, "passwd": true, "recover": true, "setrecovery": true, "sudo": true,
+}
+
+// runWithTimeout runs fn in its own goroutine and gives up waiting after
+// timeout. fn keeps running in the background if it overruns; its result is
+// dropped.
+func runWithTimeout(timeout time.Duration, fn func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		return errTimedOut
+	}
+}
+
+// ExecuteWithTimeout executes a shell command like ExecuteWithContext, but
+// answers "command timed out" once [Terminal] command_timeout has passed.
+// Input for the editor, telnet, pager and the login/registration/passwd/mail/
+// rename/overwrite prompts is never timed.
+func (os *TinyOS) ExecuteWithTimeout(ctx context.Context, input string) []shared.Message {
+	sessionID := auth.SessionIDFromContext(ctx)
+	timeout := configuration.GetDuration("Terminal", "command_timeout", 60*time.Second)
+	if timeout <= 0 || sessionID == "" || os.isInteractiveInput(sessionID, input) {
+		return os.ExecuteWithContext(ctx, input)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	started := time.Now()
+	result := make(chan []shared.Message, 1)
+	go func() {
+		result <- os.ExecuteWithContext(ctx, input)
+	}()
+
+	select {
+	case messages := <-result:
+		return messages
+	case <-ctx.Done():
+	}
+
+	logger.Warn(logger.AreaTerminal, "Command %q of session %s timed out after %v", truncateField(input, 60), sessionID, timeout)
+	os.stopTopRefresh(sessionID)
+	os.SetInputMode(sessionID, InputModeOSShell)
+	go func() {
+		<-result
+		logger.Info(logger.AreaTerminal, "Timed out command %q of session %s finished after %v, output dropped",
+			truncateField(input, 60), sessionID, time.Since(started).Round(time.Millisecond))
+	}()
+	return os.CreateWrappedTextMessage(sessionID, "command timed out")
+}
+
+// isInteractiveInput reports input that belongs to an interactive mode or
+// starts one
+func (os *TinyOS) isInteractiveInput(sessionID, input string) bool {
+	if os.GetInputMode(sessionID) != InputModeOSShell {
+		return true
+	}
+	if os.IsInCatPagerProcess(sessionID) || os.isInLoginProcess(sessionID) ||
+		os.isInRegistrationProcess(sessionID) || os.isInPasswordChangeProcess(sessionID) || os.isInRecoveryProcess(sessionID) || os.isInSudoPrompt(sessionID) ||
+		os.isInMailCompose(sessionID) || os.isInNewsPost(sessionID) || os.isInRenameProcess(sessionID) || os.isInLibraryConfirm(sessionID) ||
+		os.IsTelnetSessionActive(sessionID) {
+		return true
+	}
+	fields := strings.Fields(input)
+	return len(fields) > 0 && untimedCommands[strings.ToLower(fields[0])]
+}
@@ -327,6 +327,10 @@ func (os *TinyOS) handleRegistrationInput(input string, sessionID string) []shar
 		return os.handlePasswordInput(input, sessionID, state)
 	case "confirm_password":
 		return os.handleConfirmPasswordInput(input, sessionID, state)
+	case "recovery_question":
+		return os.handleRegistrationQuestionInput(input, sessionID, state)
+	case "recovery_answer":
+		return os.handleRegistrationAnswerInput(input, sessionID, state)
 	default:
 		// Unknown stage - reset registration
 		os.registrationMutex.Lock()
@@ -416,7 +420,25 @@ func (os *TinyOS) handleConfirmPasswordInput(input string, sessionID string, sta
 		}
 	}
 
-	// Perform registration
+	// Passwords match, offer the optional security question before registering
+	os.registrationMutex.Lock()
+	state.Stage = "recovery_question"
+	os.registrationMutex.Unlock()
+
+	messages := []shared.Message{
+		{Type: shared.MessageTypeInputControl, Content: "password_mode_off"}, // Disable password mode
+		{Type: shared.MessageTypeText, Content: "Password confirmed."},
+		{Type: shared.MessageTypeText, Content: ""},
+		{Type: shared.MessageTypeText, Content: "Optional: choose a security question to recover your password with 'recover'"},
+		{Type: shared.MessageTypeText, Content: "if you forget it. Leave empty to skip."},
+	}
+	messages = append(messages, recoveryQuestionList()...)
+	return append(messages, shared.Message{Type: shared.MessageTypePrompt, Content: "Security question: "})
+}
+
+// completeRegistration creates the account of a finished registration, stores
+// its security question if one was given and logs the new user in
+func (os *TinyOS) completeRegistration(sessionID string, state *RegistrationState) []shared.Message {
 	os.AddRegistrationAttempt(state.IPAddress)
 	err := os.RegisterUser(state.Username, state.Password, state.IPAddress)
 
@@ -431,6 +453,13 @@ func (os *TinyOS) handleConfirmPasswordInput(input string, sessionID string, sta
 			{Type: shared.MessageTypeText, Content: "Registration error: " + err.Error()},
 		}
 	}
+	if state.RecoveryQuestion != "" {
+		if err := os.storeRecovery(state.Username, state.RecoveryQuestion, state.RecoveryAnswerHash); err != nil {
+			logger.Error(logger.AreaAuth, "Storing the security question of %s failed: %v", state.Username, err)
+		} else {
+			logger.Info(logger.AreaAuth, "Security question set for %s at registration", state.Username)
+		}
+	}
 
 	// Automatic login after successful registration
 	messages, newSessionID, loginErr := os.LoginUser(state.Username, state.Password, state.IPAddress)
@@ -647,7 +676,7 @@ func validateUsername(username string) error {
 		}
 	}
 	// Check reserved names and control signals
-	reservedNames := []string{"admin", "root", "system", "guest", "user", "test", "demo", "api", "www", "mail", "ftp", "__break__", "__ctrl__", "__cancel__"}
+	reservedNames := []string{"admin", "root", "system", "guest", "user", "test", "demo", "api", "www", "mail", "ftp", "dyson", "__break__", "__ctrl__", "__cancel__"}
 	lowerUsername := strings.ToLower(username)
 	for _, reserved := range reservedNames {
 		if lowerUsername == reserved {
@@ -712,6 +741,9 @@ func (os *TinyOS) cmdLogout(args []string) []shared.Message {
 	if sessionID == "" {
 		return os.CreateWrappedTextMessage("", "Session ID not provided. Please log in again.")
 	}
+	if len(args) > 1 && (args[1] == "all" || args[1] == "--all") {
+		return os.cmdLogoutAll(sessionID)
+	}
 	username := os.GetUsernameForSession(sessionID)
 	if username == "" {
 		return os.CreateWrappedTextMessage(sessionID, "No valid session found. Please log in again.")
@@ -744,6 +776,10 @@ func (os *TinyOS) cmdLogout(args []string) []shared.Message {
 		}
 	}
 
+	os.forgetSFXVolume(sessionID)
+	os.forgetPrompt(sessionID)
+	os.forgetGuestBasicVars(sessionID)
+
 	// Erstelle automatisch eine neue Gast-Session mit derselben SessionID
 	// Dadurch kann der Benutzer nahtlos als Gast weiterarbeiten
 	_, err := os.CreateGuestSession(sessionID, ipAddress)
@@ -759,7 +795,68 @@ func (os *TinyOS) cmdLogout(args []string) []shared.Message {
 		{Type: shared.MessageTypeText, Content: "User " + username + " has been logged out."},
 		{Type: shared.MessageTypeText, Content: "You can continue to use the terminal as a guest."},
 		{Type: shared.MessageTypeSession, Content: sessionID}, // SessionID bleibt gleich, aber jetzt als Gast
+		defaultTheme().Message(), // Gäste nutzen das Standard-Theme
+	}
+}
+
+// cmdLogoutAll ends every session of the current user, e.g. after a suspected
+// compromise. The other sessions are revoked so their tokens cannot restore
+// them, connected tabs continue as guests, and the current session is logged
+// out last.
+func (os *TinyOS) cmdLogoutAll(sessionID string) []shared.Message {
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" || os.isGuestSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "Please log in to end your sessions.")
+	}
+
+	others := os.otherSessionsOf(username, sessionID)
+	for _, id := range others {
+		os.revokeSession(id, "This session of "+username+" was ended from another device.")
+	}
+	logger.SecurityInfo("User %s ended all sessions from %s (%d others)", username, shortSessionID(sessionID), len(others))
+
+	summary := "1 other session ended."
+	if len(others) != 1 {
+		summary = fmt.Sprintf("%d other sessions ended.", len(others))
+	}
+	return append([]shared.Message{{Type: shared.MessageTypeText, Content: summary}}, os.cmdLogout([]string{sessionID})...)
+}
+
+// otherSessionsOf returns the IDs of all sessions of username except
+// sessionID, both connected ones and those only stored in user_sessions
+func (os *TinyOS) otherSessionsOf(username, sessionID string) []string {
+	seen := map[string]bool{sessionID: true}
+	var ids []string
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	os.sessionMutex.RLock()
+	for id, session := range os.sessions {
+		if session.Username == username {
+			add(id)
+		}
+	}
+	os.sessionMutex.RUnlock()
+
+	if os.db != nil {
+		rows, err := os.db.Query("SELECT session_id FROM user_sessions WHERE username = ?", username)
+		if err != nil {
+			logger.Warn(logger.AreaAuth, "Failed to list sessions of %s: %v", username, err)
+			return ids
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id string
+			if rows.Scan(&id) == nil {
+				add(id)
+			}
+		}
 	}
+	return ids
 }
 
 // cmdWhoAmI zeigt den aktuellen Benutzernamen an
@@ -2,7 +2,10 @@ package tinyos
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/antibyte/retroterm/pkg/board"
 	"github.com/antibyte/retroterm/pkg/logger"
@@ -14,8 +17,13 @@ type BoardSession struct {
 	UI        *board.BoardUI
 	Active    bool
 	SessionID string
+	Hidden    bool // User opted out of the "who" list
 }
 
+// boardWhoPreferenceKey is the user_preferences key remembering that a user
+// chose "who hide"
+const boardWhoPreferenceKey = "board_who_hidden"
+
 // cmdBoard handles the board command
 func (os *TinyOS) cmdBoard(args []string) []shared.Message {
 	if len(args) == 0 {
@@ -71,6 +79,7 @@ func (os *TinyOS) cmdBoard(args []string) []shared.Message {
 		UI:        boardUI,
 		Active:    true,
 		SessionID: sessionID,
+		Hidden:    !isGuest && os.boardWhoHidden(username),
 	}
 	os.mu.Unlock()
 	
@@ -123,6 +132,15 @@ func (os *TinyOS) HandleBoardInput(input string, sessionID string) []shared.Mess
 		return os.exitBoard(sessionID)
 	}
 	
+	os.UpdateSessionActivity(sessionID)
+	
+	// Presence list, not available while composing so "who" can be typed in a message
+	if !boardSession.UI.IsComposing() {
+		if fields := strings.Fields(strings.ToLower(trimmedInput)); len(fields) > 0 && fields[0] == "who" {
+			return os.handleBoardWho(boardSession, fields[1:])
+		}
+	}
+	
 	// For ViewMessage state, pass raw input to handle Enter correctly
 	// For other states, use trimmed input
 	var processInput string
@@ -147,6 +165,117 @@ func (os *TinyOS) HandleBoardInput(input string, sessionID string) []shared.Mess
 	return messages
 }
 
+// handleBoardWho lists the users currently on the board or toggles the own visibility
+func (os *TinyOS) handleBoardWho(boardSession *BoardSession, args []string) []shared.Message {
+	if len(args) > 0 {
+		switch args[0] {
+		case "hide":
+			os.setBoardWhoHidden(boardSession, true)
+			return os.CreateWrappedTextMessage(boardSession.SessionID, "You are now hidden from the who list.")
+		case "show":
+			os.setBoardWhoHidden(boardSession, false)
+			return os.CreateWrappedTextMessage(boardSession.SessionID, "You are now visible in the who list.")
+		default:
+			return os.CreateWrappedTextMessage(boardSession.SessionID, "Usage: who [hide|show]")
+		}
+	}
+	
+	lines := os.boardManager.FormatPresenceList(os.boardPresence())
+	messages := make([]shared.Message, 0, len(lines))
+	for _, line := range lines {
+		messages = append(messages, shared.Message{Type: shared.MessageTypeText, Content: line})
+	}
+	return messages
+}
+
+// boardWhoHidden reports whether a user chose "who hide" in an earlier board
+// session
+func (os *TinyOS) boardWhoHidden(username string) bool {
+	value, err := os.getUserPreference(username, boardWhoPreferenceKey)
+	if err != nil {
+		logger.Warn(logger.AreaGeneral, "Failed to load board who preference of %s: %v", username, err)
+		return false
+	}
+	return value == "true"
+}
+
+// setBoardWhoHidden applies "who hide" or "who show" to all board sessions of
+// the user and remembers it for later sessions. Guests share one name, so
+// their choice only lasts for the current session.
+func (os *TinyOS) setBoardWhoHidden(boardSession *BoardSession, hidden bool) {
+	if boardSession.UI.IsGuest() {
+		os.mu.Lock()
+		boardSession.Hidden = hidden
+		os.mu.Unlock()
+		return
+	}
+
+	username := boardSession.UI.Username()
+	os.mu.Lock()
+	for _, other := range os.boardSessions {
+		if other == boardSession || (!other.UI.IsGuest() && other.UI.Username() == username) {
+			other.Hidden = hidden
+		}
+	}
+	os.mu.Unlock()
+
+	if err := os.setUserPreference(username, boardWhoPreferenceKey, strconv.FormatBool(hidden)); err != nil {
+		logger.Warn(logger.AreaGeneral, "Failed to save board who preference of %s: %v", username, err)
+	}
+}
+
+// boardPresence collects the visible board users with their idle time.
+// Guests have no handle of their own and are never listed.
+func (os *TinyOS) boardPresence() []board.BoardPresence {
+	type boardUser struct {
+		sessionID string
+		handle    string
+	}
+	
+	os.mu.Lock()
+	users := make([]boardUser, 0, len(os.boardSessions))
+	for sessionID, boardSession := range os.boardSessions {
+		if !boardSession.Active || boardSession.Hidden || boardSession.UI.IsGuest() {
+			continue
+		}
+		handle := boardSession.UI.Username()
+		if handle == "" || handle == "guest" {
+			continue
+		}
+		users = append(users, boardUser{sessionID: sessionID, handle: handle})
+	}
+	os.mu.Unlock()
+	
+	// Idle times come from the session, which is updated on every board input.
+	// A user with several board sessions is listed once with the shortest idle time.
+	idleByHandle := make(map[string]time.Duration)
+	now := time.Now()
+	os.sessionMutex.RLock()
+	for _, user := range users {
+		session, exists := os.sessions[user.sessionID]
+		if !exists {
+			continue
+		}
+		idle := now.Sub(session.LastActivity)
+		if current, seen := idleByHandle[user.handle]; !seen || idle < current {
+			idleByHandle[user.handle] = idle
+		}
+	}
+	os.sessionMutex.RUnlock()
+	
+	presence := make([]board.BoardPresence, 0, len(idleByHandle))
+	for handle, idle := range idleByHandle {
+		presence = append(presence, board.BoardPresence{Handle: handle, Idle: idle})
+	}
+	sort.Slice(presence, func(i, j int) bool {
+		if presence[i].Idle != presence[j].Idle {
+			return presence[i].Idle < presence[j].Idle
+		}
+		return presence[i].Handle < presence[j].Handle
+	})
+	return presence
+}
+
 // exitBoard exits board mode and returns to OS shell
 func (os *TinyOS) exitBoard(sessionID string) []shared.Message {
 	// Clean up board session
@@ -0,0 +1,62 @@
+package tinyos
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// bootPreferenceKey is the user_preferences key holding the boot mode
+const bootPreferenceKey = "boot"
+
+// Boot modes selectable with the boot command
+const (
+	BootFull = "full" // Complete start-up sequence, the default
+	BootFast = "fast" // Short greeting only
+)
+
+// BootModeForSession returns the boot mode of a session. Guests and users
+// who never chose a mode get the full start-up sequence.
+func (os *TinyOS) BootModeForSession(sessionID string) string {
+	if sessionID == "" || os.isGuestSession(sessionID) {
+		return BootFull
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return BootFull
+	}
+	if mode, err := os.getUserPreference(username, bootPreferenceKey); err == nil && mode == BootFast {
+		return BootFast
+	}
+	return BootFull
+}
+
+// cmdBoot shows or changes the boot mode of the logged-in user
+func (os *TinyOS) cmdBoot(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if len(cleanArgs) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "boot = "+os.BootModeForSession(sessionID))
+	}
+
+	mode := strings.ToLower(cleanArgs[0])
+	if len(cleanArgs) > 1 || (mode != BootFull && mode != BootFast) {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: boot [full | fast]")
+	}
+	if os.isGuestSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "boot: please log in to choose the boot sequence.")
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+	if err := os.setUserPreference(username, bootPreferenceKey, mode); err != nil {
+		logger.Error(logger.AreaDatabase, "Failed to save boot mode for %s: %v", username, err)
+		return os.CreateWrappedTextMessage(sessionID, "boot: could not save your preference.")
+	}
+	return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("Boot sequence set to %s.", mode))
+}
@@ -0,0 +1,112 @@
+package tinyos
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// cmdChatSubcommand handles chat clear and chat export. The chat history only
+// lives in the session, so clearing it there removes every copy.
+func (os *TinyOS) cmdChatSubcommand(sessionID string, args []string) []shared.Message {
+	if os.isGuestSession(sessionID) || os.GetUsernameForSession(sessionID) == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Please log in to manage your chat history.")
+	}
+	switch strings.ToLower(args[0]) {
+	case "clear":
+		if len(args) != 1 {
+			return os.CreateWrappedTextMessage(sessionID, "Usage: chat clear")
+		}
+		return os.cmdChatClear(sessionID)
+	case "export":
+		if len(args) != 2 {
+			return os.CreateWrappedTextMessage(sessionID, "Usage: chat export <file>")
+		}
+		return os.cmdChatExport(sessionID, args[1])
+	}
+	return os.CreateWrappedTextMessage(sessionID, "Usage: chat [clear|export <file>]")
+}
+
+// cmdChatClear deletes the chat history of the session after a y/n question
+func (os *TinyOS) cmdChatClear(sessionID string) []shared.Message {
+	history := os.chatHistoryOf(sessionID)
+	if len(history) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "No chat history to clear.")
+	}
+	question := fmt.Sprintf("Delete all %d messages of your chat history?", len(history))
+	return os.askConfirm(sessionID, question, "Clear (y/n)? ", func() []shared.Message {
+		os.clearChatHistory(sessionID)
+		return os.CreateWrappedTextMessage(sessionID, "Chat history cleared.")
+	})
+}
+
+// cmdChatExport writes the chat history as a text file. A name without
+// extension gets .txt; an existing file is only replaced after a y/n question.
+func (os *TinyOS) cmdChatExport(sessionID, target string) []shared.Message {
+	history := os.chatHistoryOf(sessionID)
+	if len(history) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "No chat history to export.")
+	}
+	username := os.GetUsernameForSession(sessionID)
+
+	if path.Ext(target) == "" {
+		target += ".txt"
+	}
+	target, err := os.ResolvePath(target, sessionID)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "chat export: "+err.Error())
+	}
+
+	var transcript strings.Builder
+	fmt.Fprintf(&transcript, "Chat transcript of %s, exported %s\n", username, time.Now().Format("2006-01-02 15:04"))
+	fmt.Fprintf(&transcript, "%d messages\n\n", len(history))
+	transcript.WriteString(formatChatHistory(history, "2006-01-02 15:04:05"))
+
+	write := func() []shared.Message {
+		if err := os.Vfs.WriteFile(target, transcript.String(), sessionID); err != nil {
+			return os.CreateWrappedTextMessage(sessionID, "chat export: "+err.Error())
+		}
+		logger.Info(logger.AreaChat, "User %s exported %d chat messages to %s", username, len(history), target)
+		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("Exported %d chat messages to %s.", len(history), target))
+	}
+	if os.Vfs.Exists(target, sessionID) {
+		return os.askLibraryConfirm(sessionID, target+" already exists.", write)
+	}
+	return write()
+}
+
+// chatHistoryOf returns a copy of the chat history of a session
+func (os *TinyOS) chatHistoryOf(sessionID string) []ChatMessage {
+	os.sessionMutex.RLock()
+	defer os.sessionMutex.RUnlock()
+	session, exists := os.sessions[sessionID]
+	if !exists {
+		return nil
+	}
+	return append([]ChatMessage(nil), session.ChatHistory...)
+}
+
+// formatChatHistory writes one paragraph per message with its time in
+// timeLayout, as chathistory shows it
+func formatChatHistory(history []ChatMessage, timeLayout string) string {
+	var result strings.Builder
+	for i, msg := range history {
+		speaker := "MCP"
+		if msg.Role == "user" {
+			speaker = "You"
+		}
+		content := msg.Content
+		if msg.Incomplete {
+			content += " [incomplete]"
+		}
+		fmt.Fprintf(&result, "[%s] %s: %s\n", msg.Time.Format(timeLayout), speaker, content)
+		if i < len(history)-1 {
+			result.WriteString("\n")
+		}
+	}
+	return result.String()
+}
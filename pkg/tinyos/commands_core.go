@@ -134,6 +134,9 @@ func (os *TinyOS) ExecuteWithContext(ctx context.Context, input string) []shared
 
 	// --- OS Shell Command Processing ---
 
+	// Any new input ends a running "top -d" refresh
+	os.stopTopRefresh(sessionID)
+
 	// Check if we are in a registration process
 	if sessionID != "" && os.isInRegistrationProcess(sessionID) {
 		// Process registration input
@@ -144,6 +147,30 @@ func (os *TinyOS) ExecuteWithContext(ctx context.Context, input string) []shared
 		// Process password change input
 		return os.handlePasswordChangeInput(input, sessionID)
 	}
+	// Check if we are recovering a password or setting the security question
+	if sessionID != "" && os.isInRecoveryProcess(sessionID) {
+		return os.handleRecoveryInput(input, sessionID)
+	}
+	// Check if sudo waits for the password
+	if sessionID != "" && os.isInSudoPrompt(sessionID) {
+		return os.handleSudoInput(input, sessionID)
+	}
+	// Check if we are composing a mail
+	if sessionID != "" && os.isInMailCompose(sessionID) {
+		return os.handleMailComposeInput(input, sessionID)
+	}
+	// Check if an admin is entering news
+	if sessionID != "" && os.isInNewsPost(sessionID) {
+		return os.handleNewsPostInput(input, sessionID)
+	}
+	// Check if we are waiting for the rename password
+	if sessionID != "" && os.isInRenameProcess(sessionID) {
+		return os.handleRenameInput(input, sessionID)
+	}
+	// Check if an export or import waits for the overwrite confirmation
+	if sessionID != "" && os.isInLibraryConfirm(sessionID) {
+		return os.handleLibraryConfirmInput(input, sessionID)
+	}
 	// Check if we are in a login process
 	if sessionID != "" && os.isInLoginProcess(sessionID) {
 		// Process login input
@@ -206,7 +233,7 @@ func (os *TinyOS) ExecuteWithContext(ctx context.Context, input string) []shared
 	args := tokens[1:] // For commands that need the Session-ID, we add it as the first argument
 	if sessionID != "" {
 		switch cmd {
-		case "whoami", "logout", "passwd", "ls", "pwd", "cd", "mkdir", "cat", "write", "rm", "limits", "chat", "chathistory", "basic", "run", "edit", "view", "resources", "telnet", "board":
+		case "whoami", "logout", "passwd", "ls", "pwd", "cd", "mkdir", "cat", "write", "rm", "limits", "chat", "chathistory", "basic", "run", "edit", "view", "resources", "telnet", "board", "sidinfo", "connect", "du", "mail", "theme", "set", "top", "reboot", "fetch", "boot", "rename", "kill", "export", "import", "ping", "env", "setenv", "getenv", "transfer", "whois", "setbio", "news", "lock", "unlock", "recover", "setrecovery", "dir", "sudo", "last", "examples", "vacuum", "uptime":
 			args = append([]string{sessionID}, args...)
 		}
 	}
@@ -243,6 +270,8 @@ func (os *TinyOS) ExecuteWithContext(ctx context.Context, input string) []shared
 		return os.cmdChatHistory(args) // File system commands
 	case "ls":
 		return os.cmdLs(args)
+	case "dir":
+		return os.cmdDir(args)
 	case "pwd":
 		return os.cmdPwd(args)
 	case "cd":
@@ -282,9 +311,71 @@ func (os *TinyOS) ExecuteWithContext(ctx context.Context, input string) []shared
 		return os.cmdPasswd(args)
 	case "board":
 		return os.cmdBoard(args)
+	case "transfer":
+		return os.cmdTransfer(args)
+	case "sudo":
+		return os.cmdSudo(args)
+	case "recover":
+		return os.cmdRecover(args)
+	case "setrecovery":
+		return os.cmdSetRecovery(args)
+	case "getenv":
+		return os.cmdGetenv(args)
+	case "setenv":
+		return os.cmdSetenv(args)
+	case "env":
+		return os.cmdEnv(args)
+	case "ping":
+		return os.cmdPing(args)
+	case "import":
+		return os.cmdImport(args)
+	case "export":
+		return os.cmdExport(args)
+	case "kill":
+		return os.cmdKill(args)
+	case "rename":
+		return os.cmdRename(args)
+	case "boot":
+		return os.cmdBoot(args)
+	case "whois":
+		return os.cmdWhois(args)
+	case "last":
+		return os.cmdLast(args)
+	case "examples":
+		return os.cmdExamples(args)
+	case "setbio":
+		return os.cmdSetBio(args)
+	case "news":
+		return os.cmdNews(args)
+	case "lock":
+		return os.cmdLock(args)
+	case "unlock":
+		return os.cmdUnlock(args)
+	case "fetch":
+		return os.cmdFetch(args)
+	case "reboot":
+		return os.cmdReboot(args)
+	case "top":
+		return os.cmdTop(args)
+	case "vacuum":
+		return os.cmdVacuum(args)
+	case "uptime":
+		return os.cmdUptime(args)
+	case "set":
+		return os.cmdSet(args)
+	case "theme":
+		return os.cmdTheme(args)
+	case "mail":
+		return os.cmdMail(args)
+	case "du":
+		return os.cmdDu(args)
+	case "connect":
+		return os.cmdConnect(args)
+	case "sidinfo":
+		return os.cmdSidInfo(args)
 	default:
 		logger.Debug(logger.AreaTerminal, "Unknown command: %s", cmd)
-		return os.CreateWrappedTextMessage(sessionID, "Unknown command: "+cmd)
+		return append(os.CreateWrappedTextMessage(sessionID, os.unknownCommandText(sessionID, cmd)), os.SFXMessage(sessionID, SFXError))
 	}
 }
 
@@ -314,6 +405,30 @@ func (os *TinyOS) ProcessCommand(sessionID string, input string) []shared.Messag
 		// Process password change input
 		return os.handlePasswordChangeInput(input, sessionID)
 	}
+	// Check if we are recovering a password or setting the security question
+	if os.isInRecoveryProcess(sessionID) {
+		return os.handleRecoveryInput(input, sessionID)
+	}
+	// Check if sudo waits for the password
+	if os.isInSudoPrompt(sessionID) {
+		return os.handleSudoInput(input, sessionID)
+	}
+	// Check if we are composing a mail
+	if os.isInMailCompose(sessionID) {
+		return os.handleMailComposeInput(input, sessionID)
+	}
+	// Check if an admin is entering news
+	if os.isInNewsPost(sessionID) {
+		return os.handleNewsPostInput(input, sessionID)
+	}
+	// Check if we are waiting for the rename password
+	if os.isInRenameProcess(sessionID) {
+		return os.handleRenameInput(input, sessionID)
+	}
+	// Check if an export or import waits for the overwrite confirmation
+	if os.isInLibraryConfirm(sessionID) {
+		return os.handleLibraryConfirmInput(input, sessionID)
+	}
 	// Check if we are in an active chess game
 	if sessionID != "" {
 		os.sessionMutex.Lock()
@@ -375,7 +490,7 @@ func (os *TinyOS) ProcessCommand(sessionID string, input string) []shared.Messag
 	// For commands that need the Session-ID, we add it as the first argument
 	if sessionID != "" {
 		switch cmd {
-		case "passwd", "whoami", "logout", "ls", "pwd", "cd", "mkdir", "cat", "write", "rm", "limits", "chat", "chathistory", "basic", "run", "edit", "view", "resources", "debug", "telnet", "chess", "board":
+		case "passwd", "whoami", "logout", "ls", "pwd", "cd", "mkdir", "cat", "write", "rm", "limits", "chat", "chathistory", "basic", "run", "edit", "view", "resources", "debug", "telnet", "chess", "board", "sidinfo", "connect", "du", "mail", "theme", "set", "top", "reboot", "fetch", "boot", "rename", "kill", "export", "import", "ping", "env", "setenv", "getenv", "transfer", "whois", "setbio", "news", "lock", "unlock", "recover", "setrecovery", "dir", "sudo", "last", "examples", "vacuum", "uptime":
 			args = append([]string{sessionID}, args...)
 			logger.Debug(logger.AreaTerminal, "Added sessionID to args for command '%s', args length: %d", cmd, len(args))
 		}
@@ -413,6 +528,8 @@ func (os *TinyOS) ProcessCommand(sessionID string, input string) []shared.Messag
 		return os.cmdChatHistory(args) // File system commands
 	case "ls":
 		return os.cmdLs(args)
+	case "dir":
+		return os.cmdDir(args)
 	case "pwd":
 		return os.cmdPwd(args)
 	case "cd":
@@ -451,9 +568,71 @@ func (os *TinyOS) ProcessCommand(sessionID string, input string) []shared.Messag
 		return os.cmdPasswd(args)
 	case "board":
 		return os.cmdBoard(args)
+	case "transfer":
+		return os.cmdTransfer(args)
+	case "sudo":
+		return os.cmdSudo(args)
+	case "recover":
+		return os.cmdRecover(args)
+	case "setrecovery":
+		return os.cmdSetRecovery(args)
+	case "getenv":
+		return os.cmdGetenv(args)
+	case "setenv":
+		return os.cmdSetenv(args)
+	case "env":
+		return os.cmdEnv(args)
+	case "ping":
+		return os.cmdPing(args)
+	case "import":
+		return os.cmdImport(args)
+	case "export":
+		return os.cmdExport(args)
+	case "kill":
+		return os.cmdKill(args)
+	case "rename":
+		return os.cmdRename(args)
+	case "boot":
+		return os.cmdBoot(args)
+	case "whois":
+		return os.cmdWhois(args)
+	case "last":
+		return os.cmdLast(args)
+	case "examples":
+		return os.cmdExamples(args)
+	case "setbio":
+		return os.cmdSetBio(args)
+	case "news":
+		return os.cmdNews(args)
+	case "lock":
+		return os.cmdLock(args)
+	case "unlock":
+		return os.cmdUnlock(args)
+	case "fetch":
+		return os.cmdFetch(args)
+	case "reboot":
+		return os.cmdReboot(args)
+	case "top":
+		return os.cmdTop(args)
+	case "vacuum":
+		return os.cmdVacuum(args)
+	case "uptime":
+		return os.cmdUptime(args)
+	case "set":
+		return os.cmdSet(args)
+	case "theme":
+		return os.cmdTheme(args)
+	case "mail":
+		return os.cmdMail(args)
+	case "du":
+		return os.cmdDu(args)
+	case "connect":
+		return os.cmdConnect(args)
+	case "sidinfo":
+		return os.cmdSidInfo(args)
 	default:
 		logger.Debug(logger.AreaTerminal, "Unknown command: %s", cmd)
-		return os.CreateWrappedTextMessage(sessionID, "Unknown command: "+cmd)
+		return append(os.CreateWrappedTextMessage(sessionID, os.unknownCommandText(sessionID, cmd)), os.SFXMessage(sessionID, SFXError))
 	}
 }
 
@@ -513,7 +692,7 @@ func (os *TinyOS) Execute(input string) []shared.Message {
 	case "passwd":
 		return os.cmdPasswd(args)
 	default:
-		return os.CreateWrappedTextMessage("", "Unknown command: "+cmd)
+		return os.CreateWrappedTextMessage("", os.unknownCommandText("", cmd))
 	}
 }
 
@@ -565,6 +744,10 @@ func (os *TinyOS) handleCatPagerInput(input string, sessionID string) []shared.M
 	if !exists {
 		return []shared.Message{{Type: shared.MessageTypeText, Content: "Error: No active CAT pager session"}}
 	}
+	// Search and jump keep the case of their argument
+	if messages, ok := os.handleCatPagerCommand(sessionID, state, input); ok {
+		return messages
+	}
 	// Process single character input (case insensitive)
 	input = strings.ToLower(strings.TrimSpace(input))
 
@@ -573,36 +756,38 @@ func (os *TinyOS) handleCatPagerInput(input string, sessionID string) []shared.M
 
 	// Handle quit commands
 	if input == "q" || input == "quit" || input == "\x1b" || input == "\x03" {
-		// Quit pager (q, quit, ESC, Ctrl+C)
-		os.catPagerMutex.Lock()
-		delete(os.catPagerStates, sessionID)
-		os.catPagerMutex.Unlock()
-
-		// Get username for proper prompt (no longer needed after quit fix)
-		// promptText := os.GetPromptForSession(sessionID)
-
-		return []shared.Message{
-			{Type: shared.MessageTypeEditor, EditorCommand: "status", EditorStatus: ""}, // Clear status line
-			{Type: shared.MessageTypePager, Content: "deactivate"},                      // Tell frontend to exit pager mode
-		}
+		// Quit pager (q, quit, ESC, Ctrl+C); a streamed file is simply not read any further
+		return os.closeCatPager(sessionID)
 	} else if input == "m" || input == "more" || input == "" || input == " " || input == "\r" || input == "\n" {
 		// Show more - display next page (m, more, empty, SPACE, ENTER)
 		return os.showNextCatPage(sessionID, state)
 	} else {
 		// Invalid input - show help but don't exit pager mode
-		return []shared.Message{{Type: shared.MessageTypeText, Content: "Press m for more, q to quit"}}
+		return []shared.Message{{Type: shared.MessageTypeText, Content: "Press m for more, /text to search, :N to go to line N, q to quit"}}
 	}
 }
 
 // showNextCatPage displays the next page of content in CAT pager
 func (os *TinyOS) showNextCatPage(sessionID string, state *CatPagerState) []shared.Message {
 	startLine := state.CurrentLine
-	endLine := startLine + state.PageSize
 
-	logger.Debug(logger.AreaTerminal, "CAT PAGER NEXT: session=%s, startLine=%d, endLine=%d, totalLines=%d, pageSize=%d",
-		sessionID, startLine, endLine, len(state.Lines), state.PageSize)
+	logger.Debug(logger.AreaTerminal, "CAT PAGER NEXT: session=%s, startLine=%d, bufferedLines=%d, pageSize=%d",
+		sessionID, startLine, len(state.Lines), state.PageSize)
 
-	if startLine >= len(state.Lines) {
+	// Get the lines for this page
+	pageLines := make([]string, 0, state.PageSize)
+	for i := startLine; i < startLine+state.PageSize; i++ {
+		line, ok, err := os.catPagerLine(state, i)
+		if err != nil {
+			return os.closeCatPager(sessionID, shared.Message{Type: shared.MessageTypeText, Content: "Error: " + err.Error()})
+		}
+		if !ok {
+			break
+		}
+		pageLines = append(pageLines, line)
+	}
+
+	if len(pageLines) == 0 {
 		// No more content
 		os.catPagerMutex.Lock()
 		delete(os.catPagerStates, sessionID)
@@ -613,54 +798,41 @@ func (os *TinyOS) showNextCatPage(sessionID string, state *CatPagerState) []shar
 		}
 	}
 
-	// Get the lines for this page
-	var pageLines []string
-	if endLine > len(state.Lines) {
-		endLine = len(state.Lines)
-	}
-
-	pageLines = state.Lines[startLine:endLine]
-
 	// Update current line position
+	endLine := startLine + len(pageLines)
 	os.catPagerMutex.Lock()
 	state.CurrentLine = endLine
 	os.catPagerMutex.Unlock()
 
 	// Prepare output
 	content := strings.Join(pageLines, "\n") // Check if there are more lines to show
-	if endLine < len(state.Lines) {
+	_, more, err := os.catPagerLine(state, endLine)
+	if err != nil {
+		return os.closeCatPager(sessionID, shared.Message{Type: shared.MessageTypeText, Content: content},
+			shared.Message{Type: shared.MessageTypeText, Content: "Error: " + err.Error()})
+	}
+	if more {
 		// More content available - show pager prompt and activate pager mode
-		basePrompt := "--- " + state.Filename + " --- m: more, q: quit"
-
-		// Limit padding to avoid performance issues with very wide terminals
-		prompt := basePrompt
-		if state.Terminal.Cols > 0 && state.Terminal.Cols <= 120 {
-			promptLen := len(basePrompt)
-			if promptLen < state.Terminal.Cols {
-				maxPadding := state.Terminal.Cols - promptLen
-				if maxPadding > 50 {
-					maxPadding = 50 // Limit padding to 50 characters max
-				}
-				padding := strings.Repeat(" ", maxPadding)
-				prompt = basePrompt + padding
-			}
-		}
-
+		status := catPagerStatus(state, catPagerPercent(state, endLine))
 		return []shared.Message{
 			{Type: shared.MessageTypeText, Content: content},
-			{Type: shared.MessageTypeEditor, EditorCommand: "status", EditorStatus: prompt}, // Set status line like editor
+			{Type: shared.MessageTypeEditor, EditorCommand: "status", EditorStatus: status}, // Set status line like editor
 			{Type: shared.MessageTypePager, Content: "activate"},                            // Tell frontend to enter pager mode
 		}
-	} else {
-		// Last page - clean up state and show final content
-		os.catPagerMutex.Lock()
-		delete(os.catPagerStates, sessionID)
-		os.catPagerMutex.Unlock()
-
-		return []shared.Message{
-			{Type: shared.MessageTypeEditor, EditorCommand: "status", EditorStatus: ""}, // Clear status line
-			{Type: shared.MessageTypePager, Content: "deactivate"},                      // Tell frontend to exit pager mode first
-			{Type: shared.MessageTypeText, Content: content},                            // Just the content, no prompt
-		}
 	}
+	// Last page - clean up state and show final content
+	return os.closeCatPager(sessionID, shared.Message{Type: shared.MessageTypeText, Content: content}) // Just the content, no prompt
+}
+
+// closeCatPager ends the pager of a session and returns the messages that
+// leave pager mode, followed by output
+func (os *TinyOS) closeCatPager(sessionID string, output ...shared.Message) []shared.Message {
+	os.catPagerMutex.Lock()
+	delete(os.catPagerStates, sessionID)
+	os.catPagerMutex.Unlock()
+
+	return append([]shared.Message{
+		{Type: shared.MessageTypeEditor, EditorCommand: "status", EditorStatus: ""}, // Clear status line
+		{Type: shared.MessageTypePager, Content: "deactivate"},                      // Tell frontend to exit pager mode first
+	}, output...)
 }
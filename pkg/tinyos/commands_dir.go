@@ -0,0 +1,126 @@
+package tinyos
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+	"github.com/antibyte/retroterm/pkg/virtualfs"
+)
+
+const (
+	dirSizeWidth      = 8                  // Width of the size column, fits "<DIR>" and sizes up to 99999999
+	dirDateLayout     = "2006-01-02 15:04" // Modification time on wide terminals
+	dirShortLayout    = "06-01-02"         // Modification date on narrow terminals
+	dirMinNameWidth   = 8                  // Names are never cut shorter than this
+	dirNarrowTerminal = 40                 // Below this width only the date is shown
+)
+
+// cmdDir shows the classic disk catalog of a directory: name, size and
+// modification time of every entry, sorted by name, with a footer counting
+// the files and their bytes. Long listings open in the pager.
+func (os *TinyOS) cmdDir(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if len(cleanArgs) > 1 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: dir [directory]")
+	}
+
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+	userHome := "/home/" + username
+
+	os.sessionMutex.RLock()
+	currentPath := userHome
+	if session, exists := os.sessions[sessionID]; exists && session.CurrentPath != "" {
+		currentPath = session.CurrentPath
+	}
+	os.sessionMutex.RUnlock()
+
+	targetPath := currentPath
+	if len(cleanArgs) == 1 {
+		if filepath.IsAbs(cleanArgs[0]) {
+			targetPath = filepath.Clean(cleanArgs[0])
+		} else {
+			targetPath = filepath.Clean(filepath.Join(currentPath, cleanArgs[0]))
+		}
+		targetPath = strings.ReplaceAll(targetPath, "\\", "/")
+	}
+
+	// Same access rule as ls: /home itself and the own home tree
+	if targetPath != "/home" && targetPath != userHome && !strings.HasPrefix(targetPath, userHome+"/") {
+		logger.Warn(logger.AreaAuth, "dir: access denied to path %s for user %s", targetPath, username)
+		return os.CreateWrappedTextMessage(sessionID, "Error: Access denied to "+targetPath)
+	}
+
+	entries, err := os.Vfs.ListDirLong(targetPath)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "Error: "+err.Error())
+	}
+
+	cols, _ := os.GetTerminalDimensions(sessionID)
+	return os.showLinesWithPager(sessionID, "dir "+targetPath, formatDirListing(targetPath, entries, cols))
+}
+
+// formatDirListing lays out the catalog for a terminal cols characters wide.
+// Names longer than the name column are cut and end in "~".
+func formatDirListing(path string, entries []virtualfs.DirEntry, cols int) []string {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+
+	layout := dirDateLayout
+	if cols < dirNarrowTerminal {
+		layout = dirShortLayout
+	}
+	// One column is kept free so a full line does not wrap
+	maxNameWidth := cols - 1 - dirSizeWidth - len(layout) - 2
+	if maxNameWidth < dirMinNameWidth {
+		maxNameWidth = dirMinNameWidth
+	}
+	nameWidth := 0
+	for _, entry := range entries {
+		nameWidth = max(nameWidth, len([]rune(entry.Name)))
+	}
+	nameWidth = min(max(nameWidth, dirMinNameWidth), maxNameWidth)
+
+	lines := []string{"Directory of " + path, ""}
+	files, bytes, dirs := 0, 0, 0
+	for _, entry := range entries {
+		size := strconv.Itoa(entry.Bytes)
+		if entry.IsDir {
+			size = "<DIR>"
+			dirs++
+		} else {
+			files++
+			bytes += entry.Bytes
+		}
+		name := []rune(entry.Name)
+		if len(name) > nameWidth {
+			name = append(name[:nameWidth-1], '~')
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %*s %s", nameWidth, string(name), dirSizeWidth, size, entry.ModTime.Format(layout)))
+	}
+
+	footer := countOf(files, "file", "files") + ", " + countOf(bytes, "byte", "bytes")
+	if dirs > 0 {
+		footer += ", " + countOf(dirs, "directory", "directories")
+	}
+	return append(lines, "", footer)
+}
+
+// countOf returns n followed by the singular or plural word
+func countOf(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return strconv.Itoa(n) + " " + plural
+}
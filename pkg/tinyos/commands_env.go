@@ -0,0 +1,211 @@
+package tinyos
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// Limits for variables set with setenv
+const (
+	maxEnvNameLength  = 64
+	maxEnvValueLength = 1024
+)
+
+// secretEnvMarkers mark variable names whose values must not leak. Only
+// admins see them in env, and only masked.
+var secretEnvMarkers = []string{"KEY", "SECRET", "TOKEN", "PASSWORD", "PASSWD", "CREDENTIAL"}
+
+// isSecretEnvName reports whether a variable probably holds a credential
+func isSecretEnvName(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, marker := range secretEnvMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// validEnvName accepts shell style names: a letter or underscore followed by
+// letters, digits and underscores
+func validEnvName(name string) bool {
+	if name == "" || len(name) > maxEnvNameLength {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// sessionEnvNames are computed for every session and cannot be set
+var sessionEnvNames = []string{"COLUMNS", "HOME", "LINES", "PWD", "SHELL", "TERM", "USER"}
+
+// sessionEnv returns the variables that describe the session itself. They
+// are the only ones guests see.
+func (os *TinyOS) sessionEnv(sessionID string) map[string]string {
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		username = "guest"
+	}
+	cols, rows := os.GetTerminalDimensions(sessionID)
+	return map[string]string{
+		"USER":    username,
+		"HOME":    "/home/" + username,
+		"PWD":     os.CurrentPathFromSession(sessionID),
+		"SHELL":   "tinyos",
+		"TERM":    "retroterm",
+		"COLUMNS": strconv.Itoa(cols),
+		"LINES":   strconv.Itoa(rows),
+	}
+}
+
+// visibleEnv returns the variables the session may read. Guests get the
+// session variables only, users also the non-secret system variables and
+// admins in admin mode everything.
+func (os *TinyOS) visibleEnv(sessionID string, admin bool) map[string]string {
+	env := os.sessionEnv(sessionID)
+	if os.isGuestSession(sessionID) {
+		return env
+	}
+	os.mu.Lock()
+	defer os.mu.Unlock()
+	for name, value := range os.systemEnv {
+		if _, computed := env[name]; computed {
+			continue
+		}
+		if admin || !isSecretEnvName(name) {
+			env[name] = value
+		}
+	}
+	return env
+}
+
+// LookupEnv returns a variable as ENVIRON$ sees it: the same set env lists
+// for the session. Hidden and unknown variables are empty. Secrets need
+// admin mode, as for getenv.
+func (os *TinyOS) LookupEnv(sessionID, name string) string {
+	return os.visibleEnv(sessionID, os.isElevatedSession(sessionID))[name]
+}
+
+// cmdEnv lists the environment of the session. Secret values are masked even
+// for admins, getenv shows them.
+func (os *TinyOS) cmdEnv(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if len(cleanArgs) > 0 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: env")
+	}
+	env := os.visibleEnv(sessionID, os.isElevatedSession(sessionID))
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		value := env[name]
+		if isSecretEnvName(name) {
+			value = "********"
+		}
+		sb.WriteString(name + "=" + value)
+	}
+	return os.CreateWrappedTextMessage(sessionID, sb.String())
+}
+
+// cmdGetenv shows the real value of one variable, secrets included. Admin only.
+func (os *TinyOS) cmdGetenv(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if len(cleanArgs) != 1 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: getenv NAME")
+	}
+	if !os.isAdminSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "getenv: permission denied, admins only. Use env to list your variables.")
+	}
+	if msgs := os.requireElevation(sessionID, "getenv"); msgs != nil {
+		return msgs
+	}
+	value, exists := os.visibleEnv(sessionID, true)[cleanArgs[0]]
+	if !exists {
+		return os.CreateWrappedTextMessage(sessionID, "getenv: "+cleanArgs[0]+" is not set.")
+	}
+	return os.CreateWrappedTextMessage(sessionID, value)
+}
+
+// cmdSetenv sets or, without a value, removes a system variable and stores
+// the change in the env_vars table. Admin only.
+func (os *TinyOS) cmdSetenv(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if len(cleanArgs) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: setenv NAME [VALUE]")
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if !os.isAdminSession(sessionID) {
+		logger.SecurityWarn("User %q (session %s) tried to set environment variable %s", username, shortSessionID(sessionID), cleanArgs[0])
+		return os.CreateWrappedTextMessage(sessionID, "setenv: permission denied, admins only.")
+	}
+	if msgs := os.requireElevation(sessionID, "setenv"); msgs != nil {
+		return msgs
+	}
+	name := cleanArgs[0]
+	if !validEnvName(name) {
+		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("setenv: invalid name %q, use letters, digits and _ (at most %d characters).", name, maxEnvNameLength))
+	}
+	for _, computed := range sessionEnvNames {
+		if name == computed {
+			return os.CreateWrappedTextMessage(sessionID, "setenv: "+name+" is set per session and cannot be changed.")
+		}
+	}
+	value := strings.Join(cleanArgs[1:], " ")
+	if len(value) > maxEnvValueLength {
+		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("setenv: value is longer than %d characters.", maxEnvValueLength))
+	}
+	if err := os.storeEnv(name, value, len(cleanArgs) > 1); err != nil {
+		logger.Error(logger.AreaDatabase, "Failed to store environment variable %s: %v", name, err)
+		return os.CreateWrappedTextMessage(sessionID, "setenv: could not save "+name+".")
+	}
+
+	if len(cleanArgs) == 1 {
+		logger.SecurityInfo("Admin %s removed environment variable %s", username, name)
+		return os.CreateWrappedTextMessage(sessionID, name+" removed.")
+	}
+	logger.SecurityInfo("Admin %s set environment variable %s", username, name)
+	return os.CreateWrappedTextMessage(sessionID, name+" set.")
+}
+
+// storeEnv writes a variable to the database first, so systemEnv never holds
+// a value that is lost on restart
+func (os *TinyOS) storeEnv(name, value string, set bool) error {
+	if os.db != nil {
+		var err error
+		if set {
+			_, err = os.db.Exec("INSERT OR REPLACE INTO env_vars (name, value) VALUES (?, ?)", name, value)
+		} else {
+			_, err = os.db.Exec("DELETE FROM env_vars WHERE name = ?", name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	os.mu.Lock()
+	defer os.mu.Unlock()
+	if set {
+		os.systemEnv[name] = value
+	} else {
+		delete(os.systemEnv, name)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package tinyos
+
+import (
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// cmdExamples links the example programs into the home directory. Scratch
+// guests start without them; for everyone else it restores examples that
+// were deleted. Files the user wrote under an example's name are kept.
+func (os *TinyOS) cmdExamples(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if len(cleanArgs) > 0 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: examples")
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+	if os.Vfs == nil {
+		return os.CreateWrappedTextMessage(sessionID, "examples: file system not available.")
+	}
+
+	var err error
+	if os.isGuestSession(sessionID) {
+		err = os.Vfs.LinkGuestExamples()
+	} else {
+		err = os.Vfs.SyncExamplePrograms(username)
+	}
+	if err != nil {
+		logger.Error(logger.AreaFileSystem, "Failed to link the examples for %s: %v", username, err)
+		return os.CreateWrappedTextMessage(sessionID, "examples: could not link the example programs.")
+	}
+	return os.CreateWrappedTextMessage(sessionID, "Example programs linked into /home/"+username+"/basic.")
+}
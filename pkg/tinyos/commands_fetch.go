@@ -0,0 +1,237 @@
+package tinyos
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/editor"
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// maxFetchRedirects limits how many redirects a download may follow
+const maxFetchRedirects = 3
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which the
+// net.IP helpers do not classify as private
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// cmdFetch downloads a small text file over http(s) into the user's VFS
+func (os *TinyOS) cmdFetch(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if os.isGuestSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "fetch: please log in to download files.")
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+	if len(cleanArgs) != 2 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: fetch <url> <filename>")
+	}
+
+	target, err := parseFetchURL(cleanArgs[0])
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "fetch: "+err.Error())
+	}
+	if msg := os.checkFetchRateLimit(username); msg != "" {
+		return os.CreateWrappedTextMessage(sessionID, msg)
+	}
+
+	filename := cleanArgs[1]
+	if !strings.HasPrefix(filename, "/") {
+		currentPath := os.CurrentPathFromSession(sessionID)
+		if currentPath == "" {
+			currentPath = "/home/" + username
+		}
+		filename = path.Join(currentPath, filename)
+	}
+
+	content, err := downloadText(target)
+	if err != nil {
+		logger.Info(logger.AreaFileSystem, "fetch of %s by %s failed: %v", target.Redacted(), username, err)
+		return os.CreateWrappedTextMessage(sessionID, "fetch: "+err.Error())
+	}
+	content = editor.CleanCodeForLoading(content)
+
+	if err := os.Vfs.WriteFile(filename, content, sessionID); err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "fetch: "+err.Error())
+	}
+	logger.Info(logger.AreaFileSystem, "User %s fetched %s into %s (%d bytes)", username, target.Redacted(), filename, len(content))
+	return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("Saved %d bytes from %s to %s.", len(content), target.Hostname(), path.Base(filename)))
+}
+
+// parseFetchURL accepts absolute http and https URLs on an allowed host
+func parseFetchURL(raw string) (*url.URL, error) {
+	target, err := url.Parse(raw)
+	if err != nil || target.Host == "" {
+		return nil, fmt.Errorf("invalid URL '%s'", raw)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, fmt.Errorf("only http and https URLs are supported")
+	}
+	if target.User != nil {
+		return nil, fmt.Errorf("URLs with credentials are not allowed")
+	}
+	if !fetchHostAllowed(target.Hostname()) {
+		logger.SecurityWarn("Fetch from non-allowed host %s rejected", target.Hostname())
+		return nil, fmt.Errorf("host '%s' is not allowed", target.Hostname())
+	}
+	return target, nil
+}
+
+// fetchHostAllowed checks the [Fetch] allowed_hosts list. Without a list
+// no host is allowed.
+func fetchHostAllowed(host string) bool {
+	return hostListed(host, configuration.GetString("Fetch", "allowed_hosts", ""))
+}
+
+// hostListed reports whether host appears in a comma-separated host list
+func hostListed(host, list string) bool {
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry != "" && strings.EqualFold(entry, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFetchRateLimit records a fetch for the user and returns an error text
+// once the hourly quota is used up
+func (os *TinyOS) checkFetchRateLimit(username string) string {
+	maxPerHour := configuration.GetInt("Fetch", "max_fetches_per_hour", 20)
+	cutoff := time.Now().Add(-time.Hour)
+
+	os.fetchMutex.Lock()
+	defer os.fetchMutex.Unlock()
+
+	recent := os.fetchTimes[username][:0]
+	for _, t := range os.fetchTimes[username] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= maxPerHour {
+		os.fetchTimes[username] = recent
+		logger.SecurityWarn("Fetch rate limit reached for user '%s'", username)
+		return fmt.Sprintf("fetch: limit of %d downloads per hour reached, please try again later.", maxPerHour)
+	}
+	os.fetchTimes[username] = append(recent, time.Now())
+	return ""
+}
+
+// downloadText fetches the URL with the configured size cap and timeout.
+// Every connection, including those of redirects, is checked by
+// refuseInternalAddress after DNS resolution.
+func downloadText(target *url.URL) (string, error) {
+	maxBytes := int64(configuration.GetInt("Fetch", "max_size_kb", 64)) * 1024
+	timeout := configuration.GetDuration("Fetch", "timeout", 10*time.Second)
+
+	dialer := &net.Dialer{Timeout: timeout, Control: refuseInternalAddress}
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil, // A proxy would hide the real destination from the address check
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxFetchRedirects {
+				return fmt.Errorf("too many redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme")
+			}
+			if !fetchHostAllowed(req.URL.Hostname()) {
+				return fmt.Errorf("redirect to host '%s' is not allowed", req.URL.Hostname())
+			}
+			return nil
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL")
+	}
+	req.Header.Set("User-Agent", "RetroTerm-fetch/1.0")
+	req.Header.Set("Accept", "text/plain, */*;q=0.5")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("download failed: %s", describeFetchError(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("server answered %s", resp.Status)
+	}
+	if resp.ContentLength > maxBytes {
+		return "", fmt.Errorf("file is larger than %d KB", maxBytes/1024)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("download failed: %s", describeFetchError(err))
+	}
+	if int64(len(body)) > maxBytes {
+		return "", fmt.Errorf("file is larger than %d KB", maxBytes/1024)
+	}
+	if !utf8.Valid(body) || strings.ContainsRune(string(body), 0) {
+		return "", fmt.Errorf("not a text file")
+	}
+	return string(body), nil
+}
+
+// refuseInternalAddress is the dialer control hook that keeps downloads away
+// from the server itself and the networks behind it
+func refuseInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isInternalIP(ip) {
+		logger.SecurityWarn("Fetch connection to internal address %s refused", address)
+		return fmt.Errorf("address %s is not allowed", host)
+	}
+	return nil
+}
+
+// isInternalIP reports addresses that must not be reachable through fetch
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() ||
+		sharedAddressSpace.Contains(ip) || (ip.To4() != nil && ip.To4()[0] == 0)
+}
+
+// describeFetchError shortens transport errors for the terminal
+func describeFetchError(err error) string {
+	if urlErr, ok := err.(*url.Error); ok {
+		if urlErr.Timeout() {
+			return "timeout"
+		}
+		err = urlErr.Err
+	}
+	if opErr, ok := err.(*net.OpError); ok && opErr.Err != nil {
+		return opErr.Err.Error()
+	}
+	return err.Error()
+}
@@ -3,11 +3,13 @@ package tinyos
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/antibyte/retroterm/pkg/logger"
 	"github.com/antibyte/retroterm/pkg/shared"
+	"github.com/antibyte/retroterm/pkg/virtualfs"
 )
 
 // cmdLs listet den Inhalt eines Verzeichnisses auf
@@ -38,10 +40,21 @@ func (os *TinyOS) cmdLs(args []string) []shared.Message {
 	}
 	os.sessionMutex.RUnlock()
 
+	// -l zeigt eine Zeile pro Eintrag mit Flags, Größe und Datum
+	long := false
+	var pathArgs []string
+	for _, arg := range cleanArgs {
+		if arg == "-l" {
+			long = true
+		} else {
+			pathArgs = append(pathArgs, arg)
+		}
+	}
+
 	// Ziel-Verzeichnis bestimmen
 	targetPath := currentPath
-	if len(cleanArgs) > 0 {
-		targetArg := cleanArgs[0]
+	if len(pathArgs) > 0 {
+		targetArg := pathArgs[0]
 
 		// Absoluten oder relativen Pfad behandeln
 		if filepath.IsAbs(targetArg) {
@@ -62,6 +75,13 @@ func (os *TinyOS) cmdLs(args []string) []shared.Message {
 		return os.CreateWrappedTextMessage(sessionID, "Error: Access denied to "+targetPath)
 	}
 
+	if long {
+		return os.listDirLong(sessionID, username, targetPath)
+	}
+	if os.lsColorEnabled(sessionID) {
+		return os.listDirColor(sessionID, username, targetPath)
+	}
+
 	// Hole Verzeichnisinhalt vom VFS
 	entries, err := os.Vfs.ListDir(targetPath)
 	if err != nil {
@@ -101,6 +121,104 @@ func (os *TinyOS) cmdLs(args []string) []shared.Message {
 	return os.CreateWrappedTextMessage(sessionID, result.String())
 }
 
+// listDirLong ist die Ausgabe von ls -l: Flags (d Verzeichnis, l verlinktes
+// Beispiel, r lesbar, w schreibbar - gesperrte Dateien haben kein w), Größe,
+// Änderungszeit und Name
+func (os *TinyOS) listDirLong(sessionID, username, targetPath string) []shared.Message {
+	entries, err := os.Vfs.ListDirLong(targetPath)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "Error: "+err.Error())
+	}
+
+	lines := []string{"Current directory: " + targetPath}
+	for _, entry := range entries {
+		flags := []byte("-rw")
+		size := strconv.Itoa(entry.Bytes)
+		name := entry.Name
+		switch {
+		case entry.IsDir:
+			flags[0] = 'd'
+			size = "-"
+			name += "/"
+		case entry.IsLink:
+			flags[0] = 'l'
+			flags[2] = '-'
+		}
+		if entry.ReadOnly {
+			flags[2] = '-'
+		}
+		lines = append(lines, fmt.Sprintf("%s %7s  %s  %s", flags, size, entry.ModTime.Format("2006-01-02 15:04"), name))
+	}
+	if storageInfo, err := os.Vfs.GetUserStorageInfo(username); err == nil {
+		lines = append(lines, "", fmt.Sprintf("%d of %d KB used", storageInfo.UsedKB, storageInfo.TotalKB))
+	}
+	return os.CreateWrappedTextMessage(sessionID, strings.Join(lines, "\n"))
+}
+
+// cmdDu zeigt den Speicherverbrauch jedes Unterverzeichnisses im eigenen Home-Baum, größte zuerst
+func (os *TinyOS) cmdDu(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+	userHome := "/home/" + username
+
+	os.sessionMutex.RLock()
+	currentPath := userHome
+	if session, exists := os.sessions[sessionID]; exists && session.CurrentPath != "" {
+		currentPath = session.CurrentPath
+	}
+	os.sessionMutex.RUnlock()
+
+	targetPath := currentPath
+	if len(cleanArgs) > 0 {
+		if filepath.IsAbs(cleanArgs[0]) {
+			targetPath = filepath.Clean(cleanArgs[0])
+		} else {
+			targetPath = filepath.Clean(filepath.Join(currentPath, cleanArgs[0]))
+		}
+		targetPath = strings.ReplaceAll(targetPath, "\\", "/")
+	} else if targetPath != userHome && !strings.HasPrefix(targetPath, userHome+"/") {
+		// Ohne Argument außerhalb des eigenen Baums (z.B. in /home) das Home-Verzeichnis auswerten
+		targetPath = userHome
+	}
+
+	// Nur der eigene Home-Baum darf ausgewertet werden
+	if targetPath != userHome && !strings.HasPrefix(targetPath, userHome+"/") {
+		logger.Warn(logger.AreaAuth, "du: access denied to path %s for user %s", targetPath, username)
+		return os.CreateWrappedTextMessage(sessionID, "du: access denied to "+targetPath)
+	}
+
+	usage, err := os.Vfs.GetDirectoryUsage(targetPath, username)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "du: "+err.Error())
+	}
+
+	lines := make([]string, 0, len(usage)+2)
+	for _, entry := range usage {
+		lines = append(lines, fmt.Sprintf("%8s  %s", formatDiskUsage(entry.Bytes), entry.Path))
+	}
+	if storageInfo, err := os.Vfs.GetUserStorageInfo(username); err == nil {
+		lines = append(lines, "", fmt.Sprintf("%d of %d KB used", storageInfo.UsedKB, storageInfo.TotalKB))
+	}
+
+	cols, _ := os.GetTerminalDimensions(sessionID)
+	return os.showLinesWithPager(sessionID, "du "+targetPath, os.wrapLinesForTerminal(lines, cols))
+}
+
+// formatDiskUsage formatiert eine Byte-Anzahl für du (Bytes unter 1 KB, sonst KB mit einer Nachkommastelle)
+func formatDiskUsage(bytes int) string {
+	if bytes < 1024 {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	return fmt.Sprintf("%.1fK", float64(bytes)/1024)
+}
+
 // cmdPwd shows the current working directory
 func (os *TinyOS) cmdPwd(args []string) []shared.Message {
 	var sessionID string
@@ -261,6 +379,12 @@ func (os *TinyOS) cmdMkdir(args []string) []shared.Message {
 		targetPath = strings.ReplaceAll(targetPath, "\\", "/")
 	}
 
+	// Other homes are closed and the areas outside /home are read-only
+	targetPath = virtualfs.CleanPath(targetPath)
+	if err := os.Vfs.CheckAccess(targetPath, sessionID, true); err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "Error: "+err.Error())
+	}
+
 	err := os.Vfs.Mkdir(targetPath)
 	if err != nil {
 		return os.CreateWrappedTextMessage(sessionID, "Error: "+err.Error())
@@ -319,96 +443,78 @@ func (os *TinyOS) cmdCat(args []string) []shared.Message {
 		targetPath = strings.ReplaceAll(targetPath, "\\", "/")
 	}
 
-	content, err := os.Vfs.ReadFile(targetPath, sessionID)
+	// The file is streamed: only the first page is read now, the pager
+	// reads the rest as the user pages through it
+	cols, rows := os.GetTerminalDimensions(sessionID)
+	state := &CatPagerState{
+		Stream:    &catStream{path: targetPath, sessionID: sessionID, cols: cols},
+		PageSize:  catPageSize,
+		Filename:  fileArg,
+		CreatedAt: time.Now(),
+		Terminal:  TerminalDimensions{Cols: cols, Rows: rows},
+	}
+	// One line more than a page tells whether the pager is needed
+	_, more, err := os.catPagerLine(state, catPageSize)
 	if err != nil {
 		return os.CreateWrappedTextMessage(sessionID, "Error: "+err.Error())
 	}
 
-	// Normalize line breaks: Windows (\r\n) to Unix (\n)
-	content = strings.ReplaceAll(content, "\r\n", "\n")
-	// Remove any remaining \r (if present)
-	content = strings.ReplaceAll(content, "\r", "\n")
+	logger.Debug(logger.AreaTerminal, "CAT COMMAND: Processing file %s for session %s, size=%d, pager=%v", fileArg, sessionID, state.Stream.size, more)
 
-	// Get terminal dimensions for proper line wrapping and pager formatting
-	cols, rows := os.GetTerminalDimensions(sessionID)
-
-	// Split content into lines
-	lines := strings.Split(content, "\n")
+	if !more {
+		return os.showLinesWithPager(sessionID, fileArg, state.Lines)
+	}
+	return os.startCatPager(sessionID, state)
+}
 
-	// Apply automatic line wrapping based on terminal width
-	lines = os.wrapLinesForTerminal(lines, cols)
+// showLinesWithPager shows already wrapped lines, using the cat pager when they
+// don't fit on one page. title appears in the pager status line.
+func (os *TinyOS) showLinesWithPager(sessionID, title string, lines []string) []shared.Message {
+	messages := os.PageLines(sessionID, title, lines)
+	if len(lines) <= catPageSize {
+		// Short content is shown at once and followed by the shell prompt
+		messages = append(messages, shared.Message{Type: shared.MessageTypeText, Content: os.GetPromptForSession(sessionID), NoNewline: true})
+	}
+	return messages
+}
 
-	// Check if file is small enough to display all at once
-	pageSize := 20
+// catPageSize is the number of lines the pager shows per page
+const catPageSize = 20
 
-	logger.Debug(logger.AreaTerminal, "CAT COMMAND: Processing file %s for session %s, original_lines=%d, wrapped_lines=%d", fileArg, sessionID, len(strings.Split(content, "\n")), len(lines))
+// PageLines shows lines at once if they fit on one page and otherwise starts
+// the pager, which then takes the input of the session until it is closed.
+// Also used by the BASIC LIST command.
+func (os *TinyOS) PageLines(sessionID, title string, lines []string) []shared.Message {
+	cols, rows := os.GetTerminalDimensions(sessionID)
+	pageSize := catPageSize
 
 	if len(lines) <= pageSize {
-		// Small file - display all content at once without prompt
-		logger.Debug(logger.AreaTerminal, "CAT SMALL FILE: File %s has %d lines, showing all content", fileArg, len(lines))
-		wrappedContent := strings.Join(lines, "\n")
-		// Get proper prompt for this session
-		promptText := os.GetPromptForSession(sessionID)
-		
-		return []shared.Message{
-			{Type: shared.MessageTypeText, Content: wrappedContent},
-			{Type: shared.MessageTypeText, Content: promptText, NoNewline: true},
-		}
+		logger.Debug(logger.AreaTerminal, "CAT SMALL FILE: File %s has %d lines, showing all content", title, len(lines))
+		return []shared.Message{{Type: shared.MessageTypeText, Content: strings.Join(lines, "\n")}}
 	}
-	// Large file - use pager
+	// Long content - use pager
 	logger.Debug(logger.AreaTerminal, "CAT PAGER INIT: Creating pager state for session %s, file %s, lines=%d",
-		sessionID, fileArg, len(lines))
-
-	// Initialize pager state
-	pagerState := &CatPagerState{
-		Lines:       lines,
-		CurrentLine: 0,
-		PageSize:    pageSize,
-		Filename:    fileArg,
-		CreatedAt:   time.Now(),
-		Terminal:    TerminalDimensions{Cols: cols, Rows: rows},
-	}
+		sessionID, title, len(lines))
+
+	return os.startCatPager(sessionID, &CatPagerState{
+		Lines:     lines,
+		PageSize:  pageSize,
+		Filename:  title,
+		CreatedAt: time.Now(),
+		Terminal:  TerminalDimensions{Cols: cols, Rows: rows},
+	})
+}
 
+// startCatPager hands the input of the session to the pager and shows the
+// first page on a cleared screen
+func (os *TinyOS) startCatPager(sessionID string, state *CatPagerState) []shared.Message {
 	os.catPagerMutex.Lock()
-	os.catPagerStates[sessionID] = pagerState
+	os.catPagerStates[sessionID] = state
 	logger.Debug(logger.AreaTerminal, "CAT PAGER STORED: Session %s added to pager states, total states=%d",
 		sessionID, len(os.catPagerStates))
 	os.catPagerMutex.Unlock()
 
-	// Clear screen and show first page
-	messages := []shared.Message{
-		{Type: shared.MessageTypeClear}, // Clear screen properly
-	}
-
-	// Show first page manually (lines 0 to pageSize-1)
-	firstPageLines := lines[0:pageSize]
-	firstPageContent := strings.Join(firstPageLines, "\n")
-
-	// Update CurrentLine to point to the first line of NEXT page
-	pagerState.CurrentLine = pageSize
-
-	// Add first page content and pager status
-	basePrompt := "--- " + fileArg + " --- m: more, q: quit"
-	prompt := basePrompt
-	if cols > 0 && cols <= 120 {
-		promptLen := len(basePrompt)
-		if promptLen < cols {
-			maxPadding := cols - promptLen
-			if maxPadding > 50 {
-				maxPadding = 50
-			}
-			padding := strings.Repeat(" ", maxPadding)
-			prompt = basePrompt + padding
-		}
-	}
-
-	messages = append(messages, []shared.Message{
-		{Type: shared.MessageTypeText, Content: firstPageContent},
-		{Type: shared.MessageTypeEditor, EditorCommand: "status", EditorStatus: prompt},
-		{Type: shared.MessageTypePager, Content: "activate"},
-	}...)
-
-	return messages
+	return append([]shared.Message{{Type: shared.MessageTypeClear}}, os.showNextCatPage(sessionID, state)...)
 }
 
 // cmdWrite writes text to a file (overwrites existing)
@@ -549,16 +655,14 @@ func (os *TinyOS) cmdRm(args []string) []shared.Message {
 	}
 
 	// Security measures - certain directories must not be deleted
+	targetPath = virtualfs.CleanPath(targetPath)
 	if targetPath == "/home" || (username != "" && targetPath == "/home/"+username) {
 		return []shared.Message{{Type: shared.MessageTypeText, Content: "Error: Cannot delete this directory."}}
 	}
 
-	// Check if the user has write access
-	if username != "" {
-		// Logged-in user may only delete within their home directory
-		if !strings.HasPrefix(targetPath, "/home/"+username) {
-			return []shared.Message{{Type: shared.MessageTypeText, Content: "Error: No permission for this operation."}}
-		}
+	// Only the own home directory may be changed
+	if err := os.Vfs.CheckAccess(targetPath, sessionID, true); err != nil {
+		return []shared.Message{{Type: shared.MessageTypeText, Content: "Error: No permission for this operation."}}
 	}
 
 	err := os.Vfs.Remove(targetPath)
@@ -596,16 +700,8 @@ func (os *TinyOS) wrapLinesForTerminal(lines []string, terminalWidth int) []stri
 					break
 				}
 
-				// Find best break point (prefer space near end of line)
-				breakPoint := terminalWidth
-
-				// Look for a space near the end to break at word boundary
-				for i := terminalWidth - 1; i >= terminalWidth*3/4 && i > 0; i-- {
-					if line[i] == ' ' {
-						breakPoint = i
-						break
-					}
-				}
+				// Find best break point (word boundary, hyphen or punctuation)
+				breakPoint := findBreak(line, terminalWidth)
 
 				// Add the wrapped portion
 				wrappedLines = append(wrappedLines, line[:breakPoint])
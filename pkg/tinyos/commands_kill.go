@@ -0,0 +1,111 @@
+package tinyos
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// minKillPrefix is the shortest session ID prefix kill accepts, top shows 8
+const minKillPrefix = 4
+
+// cmdKill stops the BASIC program running in another session, e.g. a tab
+// that no longer reacts. Users may stop programs in their own sessions,
+// admins in any session. Without arguments it lists the sessions that may be
+// stopped.
+func (os *TinyOS) cmdKill(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if len(cleanArgs) > 1 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: kill [session]")
+	}
+	admin := os.isAdminSession(sessionID)
+	if !admin && os.isGuestSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "kill: please log in to stop programs.")
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+
+	if len(cleanArgs) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, os.listKillableSessions(sessionID, username, admin && os.isElevatedSession(sessionID)))
+	}
+
+	// Users only search their own sessions, so the replies tell nothing
+	// about the session IDs of others
+	searchUser := username
+	if admin {
+		searchUser = ""
+	}
+	target, owner, err := os.findSessionByPrefix(cleanArgs[0], searchUser)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "kill: "+err.Error())
+	}
+	if owner != username {
+		if msgs := os.requireElevation(sessionID, "kill"); msgs != nil {
+			return msgs
+		}
+	}
+	if os.StopProgramCallback == nil || !os.StopProgramCallback(target) {
+		return os.CreateWrappedTextMessage(sessionID, "kill: no program running in session "+shortSessionID(target)+".")
+	}
+
+	logger.Info(logger.AreaSession, "User %s (session %s) stopped the BASIC program of session %s (%s)",
+		username, shortSessionID(sessionID), shortSessionID(target), owner)
+	return os.CreateWrappedTextMessage(sessionID, "Program in session "+shortSessionID(target)+" stopped.")
+}
+
+// findSessionByPrefix resolves a full session ID or a unique prefix of at
+// least minKillPrefix characters among the sessions of username, or of all
+// users if username is "". Returns the session ID and its user.
+func (os *TinyOS) findSessionByPrefix(prefix, username string) (string, string, error) {
+	if len(prefix) < minKillPrefix {
+		return "", "", fmt.Errorf("session id must have at least %d characters", minKillPrefix)
+	}
+	os.sessionMutex.RLock()
+	defer os.sessionMutex.RUnlock()
+
+	if session, exists := os.sessions[prefix]; exists && (username == "" || session.Username == username) {
+		return prefix, session.Username, nil
+	}
+	var found, owner string
+	for id, session := range os.sessions {
+		if !strings.HasPrefix(id, prefix) || (username != "" && session.Username != username) {
+			continue
+		}
+		if found != "" {
+			return "", "", fmt.Errorf("session id %s is ambiguous", prefix)
+		}
+		found, owner = id, session.Username
+	}
+	if found == "" {
+		return "", "", fmt.Errorf("no such session %s", prefix)
+	}
+	return found, owner, nil
+}
+
+// listKillableSessions lists the other sessions kill may target: the user's
+// own, or all sessions for admins
+func (os *TinyOS) listKillableSessions(sessionID, username string, admin bool) string {
+	var lines []string
+	os.sessionMutex.RLock()
+	for id, session := range os.sessions {
+		if id == sessionID || (!admin && session.Username != username) {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%-8s %-12s %s", shortSessionID(id), truncateField(session.Username, 12), inputModeName(session.InputMode)))
+	}
+	os.sessionMutex.RUnlock()
+
+	if len(lines) == 0 {
+		return "No other sessions. Usage: kill <session>"
+	}
+	sort.Strings(lines)
+	return fmt.Sprintf("%-8s %-12s %s\n", "SESSION", "USER", "MODE") + strings.Join(lines, "\n") + "\n\nUsage: kill <session>"
+}
@@ -0,0 +1,120 @@
+package tinyos
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+const (
+	// defaultLastEntries is how many logins last shows without a count
+	defaultLastEntries = 10
+	// maxLastEntries is the largest count last accepts
+	maxLastEntries = 50
+	// loginHistoryPerUser is how many logins are kept per user, older ones
+	// are removed when a new one is recorded
+	loginHistoryPerUser = 100
+)
+
+// recordLogin stores a login attempt of a registered user in login_history.
+// Failed attempts are kept apart from successful ones by the success flag.
+func (os *TinyOS) recordLogin(username, ipAddress string, success bool) {
+	if os.db == nil {
+		return
+	}
+	ok := 0
+	if success {
+		ok = 1
+	}
+	if _, err := os.db.Exec("INSERT INTO login_history (username, ip_address, login_at, success) VALUES (?, ?, ?, ?)",
+		username, ipAddress, time.Now().Unix(), ok); err != nil {
+		logger.Error(logger.AreaDatabase, "Failed to record login of %s: %v", username, err)
+		return
+	}
+	if _, err := os.db.Exec(`DELETE FROM login_history WHERE username = ? AND id NOT IN
+		(SELECT id FROM login_history WHERE username = ? ORDER BY id DESC LIMIT ?)`,
+		username, username, loginHistoryPerUser); err != nil {
+		logger.Error(logger.AreaDatabase, "Failed to trim login history of %s: %v", username, err)
+	}
+}
+
+// cmdLast shows the recent logins of the user with their IP addresses and
+// whether they failed. Admins in admin mode may name another user.
+func (os *TinyOS) cmdLast(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if os.isGuestSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "last: please log in to see your logins.")
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+	if os.db == nil {
+		return os.CreateWrappedTextMessage(sessionID, "last: user database not available.")
+	}
+
+	target, count := username, defaultLastEntries
+	for _, arg := range cleanArgs {
+		if n, err := strconv.Atoi(arg); err == nil {
+			if n < 1 || n > maxLastEntries {
+				return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("last: count must be between 1 and %d.", maxLastEntries))
+			}
+			count = n
+		} else if target == username && !strings.EqualFold(arg, username) {
+			target = arg
+		} else {
+			return os.CreateWrappedTextMessage(sessionID, "Usage: last [username] [count]")
+		}
+	}
+
+	if target != username {
+		if !os.isAdminSession(sessionID) {
+			logger.SecurityWarn("User %q (session %s) tried to read the login history of %q", username, shortSessionID(sessionID), target)
+			return os.CreateWrappedTextMessage(sessionID, "last: you can only see your own logins.")
+		}
+		if msgs := os.requireElevation(sessionID, "last"); msgs != nil {
+			return msgs
+		}
+		// Admins type the name in any case, the history keeps the stored one
+		if err := os.db.QueryRow("SELECT username FROM users WHERE username = ? COLLATE NOCASE", target).Scan(&target); err != nil {
+			return os.CreateWrappedTextMessage(sessionID, "last: no such user "+target)
+		}
+	}
+
+	rows, err := os.db.Query("SELECT ip_address, login_at, success FROM login_history WHERE username = ? ORDER BY id DESC LIMIT ?", target, count)
+	if err != nil {
+		logger.Error(logger.AreaDatabase, "Failed to read login history of %s: %v", target, err)
+		return os.CreateWrappedTextMessage(sessionID, "last: could not read the login history.")
+	}
+	defer rows.Close()
+
+	lines := []string{"Recent logins of " + target + ":"}
+	for rows.Next() {
+		var ip string
+		var loginAt int64
+		var success bool
+		if err := rows.Scan(&ip, &loginAt, &success); err != nil {
+			logger.Error(logger.AreaDatabase, "Failed to read login history of %s: %v", target, err)
+			continue
+		}
+		result := "ok"
+		if !success {
+			result = "FAILED"
+		}
+		lines = append(lines, fmt.Sprintf("%s  %-39s  %s", time.Unix(loginAt, 0).Format("2006-01-02 15:04"), ip, result))
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error(logger.AreaDatabase, "Failed to read login history of %s: %v", target, err)
+	}
+	if len(lines) == 1 {
+		return os.CreateWrappedTextMessage(sessionID, "No logins recorded for "+target+".")
+	}
+	return os.CreateWrappedTextMessage(sessionID, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,319 @@
+package tinyos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+const (
+	libraryFormat  = "retroterm-library" // Value of "format" in files written by export
+	libraryVersion = 1
+)
+
+// programLibrary is the JSON file written by export and read by import. Files
+// maps paths relative to the home directory to the program text.
+type programLibrary struct {
+	Format  string            `json:"format"`
+	Version int               `json:"version"`
+	Files   map[string]string `json:"files"`
+}
+
+// LibraryConfirmState holds an action that waits for y/n, e.g. an export or
+// import that would overwrite files
+type LibraryConfirmState struct {
+	Run       func() []shared.Message // Performs the action
+	CreatedAt time.Time               // Time when the question was asked
+}
+
+// cmdExport bundles all .bas files of the user's home directory into one JSON file
+func (os *TinyOS) cmdExport(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if len(cleanArgs) != 1 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: export <file.json>")
+	}
+	if os.isGuestSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "export: please log in to export your programs.")
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+
+	target := cleanArgs[0]
+	if path.Ext(target) == "" {
+		target += ".json"
+	}
+	target, err := os.ResolvePath(target, sessionID)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "export: "+err.Error())
+	}
+
+	files, err := os.Vfs.ListUserFiles(username)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "export: "+err.Error())
+	}
+	home := "/home/" + username + "/"
+	lib := programLibrary{Format: libraryFormat, Version: libraryVersion, Files: make(map[string]string)}
+	for _, f := range files {
+		// Links are the shared examples, not the user's own programs
+		if f.IsLink || !strings.HasSuffix(strings.ToLower(f.Path), ".bas") {
+			continue
+		}
+		content, err := os.Vfs.ReadFile(f.Path, sessionID)
+		if err != nil {
+			return os.CreateWrappedTextMessage(sessionID, "export: cannot read "+f.Path+": "+err.Error())
+		}
+		lib.Files[strings.TrimPrefix(f.Path, home)] = content
+	}
+	if len(lib.Files) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "export: no .bas files of your own in your home directory.")
+	}
+	data, err := json.MarshalIndent(lib, "", "  ")
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "export: "+err.Error())
+	}
+
+	write := func() []shared.Message {
+		if err := os.Vfs.WriteFile(target, string(data), sessionID); err != nil {
+			return os.CreateWrappedTextMessage(sessionID, "export: "+err.Error())
+		}
+		logger.Info(logger.AreaFileSystem, "User %s exported %d programs to %s", username, len(lib.Files), target)
+		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("Exported %d programs to %s.", len(lib.Files), target))
+	}
+	if os.Vfs.Exists(target, sessionID) {
+		return os.askLibraryConfirm(sessionID, target+" already exists.", write)
+	}
+	return write()
+}
+
+// cmdImport restores the programs of a file written by export into the
+// user's home directory. Nothing is written unless the whole set fits.
+func (os *TinyOS) cmdImport(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if len(cleanArgs) != 1 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: import <file.json>")
+	}
+	if os.isGuestSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "import: please log in to import programs.")
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+
+	source, err := os.ResolvePath(cleanArgs[0], sessionID)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "import: "+err.Error())
+	}
+	content, err := os.Vfs.ReadFile(source, sessionID)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "import: cannot read "+source+".")
+	}
+	lib, err := parseProgramLibrary(content)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "import: "+source+" is not a program library: "+err.Error()+".")
+	}
+
+	overwrites, err := os.checkLibraryFits(username, lib)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "import: "+err.Error()+". Nothing was imported.")
+	}
+	run := func() []shared.Message {
+		return os.writeLibrary(sessionID, username, source, lib, overwrites)
+	}
+	if len(overwrites) > 0 {
+		return os.askLibraryConfirm(sessionID, fmt.Sprintf("Import would overwrite %d existing files: %s", len(overwrites), strings.Join(overwrites, ", ")), run)
+	}
+	return run()
+}
+
+// parseProgramLibrary decodes and validates a library file
+func parseProgramLibrary(content string) (*programLibrary, error) {
+	dec := json.NewDecoder(strings.NewReader(content))
+	dec.DisallowUnknownFields()
+	var lib programLibrary
+	if err := dec.Decode(&lib); err != nil {
+		return nil, errors.New("invalid JSON")
+	}
+	if dec.More() {
+		return nil, errors.New("unexpected data after the library")
+	}
+	if lib.Format != libraryFormat {
+		return nil, fmt.Errorf("format must be %q", libraryFormat)
+	}
+	if lib.Version != libraryVersion {
+		return nil, fmt.Errorf("unsupported version %d", lib.Version)
+	}
+	if len(lib.Files) == 0 {
+		return nil, errors.New("no files")
+	}
+	for name := range lib.Files {
+		if err := validateLibraryPath(name); err != nil {
+			return nil, err
+		}
+	}
+	return &lib, nil
+}
+
+// validateLibraryPath accepts .bas paths relative to the home directory that
+// stay inside it
+func validateLibraryPath(name string) error {
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, "\\") || path.Clean(name) != name {
+		return fmt.Errorf("invalid file name %q", name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." || strings.HasPrefix(part, ".") {
+			return fmt.Errorf("invalid file name %q", name)
+		}
+	}
+	if !strings.HasSuffix(strings.ToLower(name), ".bas") {
+		return fmt.Errorf("%s is not a .bas file", name)
+	}
+	return nil
+}
+
+// checkLibraryFits checks the file size, directory and quota limits for the
+// whole library at once. Returns the files that already exist.
+func (os *TinyOS) checkLibraryFits(username string, lib *programLibrary) ([]string, error) {
+	existing, err := os.Vfs.ListUserFiles(username)
+	if err != nil {
+		return nil, err
+	}
+	home := "/home/" + username
+	sizes := make(map[string]int, len(existing))
+	dirFiles := make(map[string]int)
+	for _, f := range existing {
+		sizes[f.Path] = f.Bytes
+		dirFiles[path.Dir(f.Path)]++
+	}
+
+	maxFileSize := configuration.GetInt("FileSystem", "max_file_size_kb", 1024) * 1024
+	maxFiles := configuration.GetInt("FileSystem", "max_files_per_directory", 100)
+	var overwrites []string
+	growth := 0
+	newFilesIn := make(map[string]bool)
+	for name, content := range lib.Files {
+		if len(content) > maxFileSize {
+			return nil, fmt.Errorf("%s is larger than %d KB", name, maxFileSize/1024)
+		}
+		target := home + "/" + name
+		if size, exists := sizes[target]; exists {
+			overwrites = append(overwrites, name)
+			growth += len(content) - size
+			continue
+		}
+		growth += len(content)
+		dirFiles[path.Dir(target)]++
+		newFilesIn[path.Dir(target)] = true
+	}
+	for dir := range newFilesIn {
+		if count := dirFiles[dir]; count > maxFiles {
+			return nil, fmt.Errorf("%s would hold %d files, the limit is %d", dir, count, maxFiles)
+		}
+	}
+
+	if info, err := os.Vfs.GetUserStorageInfo(username); err == nil {
+		free := info.TotalKB*1024 - info.UsedKB*1024
+		if growth > free {
+			return nil, fmt.Errorf("not enough space, the import needs %d KB and %d KB are free", (growth+1023)/1024, free/1024)
+		}
+	}
+	sort.Strings(overwrites)
+	return overwrites, nil
+}
+
+// writeLibrary writes all programs of a library. The home directory may have
+// changed while the user was asked to confirm, so the limits are checked
+// again and only the confirmed files may be overwritten.
+func (os *TinyOS) writeLibrary(sessionID, username, source string, lib *programLibrary, confirmed []string) []shared.Message {
+	overwrites, err := os.checkLibraryFits(username, lib)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "import: "+err.Error()+". Nothing was imported.")
+	}
+	allowed := make(map[string]bool, len(confirmed))
+	for _, name := range confirmed {
+		allowed[name] = true
+	}
+	for _, name := range overwrites {
+		if !allowed[name] {
+			return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("import: %s was created in the meantime. Nothing was imported, please run import again.", name))
+		}
+	}
+
+	names := make([]string, 0, len(lib.Files))
+	for name := range lib.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	home := "/home/" + username
+	for i, name := range names {
+		target := home + "/" + name
+		if dir := path.Dir(target); !os.Vfs.IsDir(dir) {
+			if err := os.Vfs.MkdirAll(dir); err != nil {
+				return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("import: cannot create %s: %v. %d of %d programs imported.", dir, err, i, len(names)))
+			}
+		}
+		if err := os.Vfs.WriteFile(target, lib.Files[name], sessionID); err != nil {
+			return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("import: cannot write %s: %v. %d of %d programs imported.", name, err, i, len(names)))
+		}
+	}
+	logger.Info(logger.AreaFileSystem, "User %s imported %d programs from %s", username, len(names), source)
+	return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("Imported %d programs from %s.", len(names), source))
+}
+
+// askLibraryConfirm asks whether files may be overwritten and runs the
+// export or import on "y"
+func (os *TinyOS) askLibraryConfirm(sessionID, question string, run func() []shared.Message) []shared.Message {
+	return os.askConfirm(sessionID, question, "Overwrite (y/n)? ", run)
+}
+
+// askConfirm shows question and prompt and runs run once the user answers "y"
+func (os *TinyOS) askConfirm(sessionID, question, prompt string, run func() []shared.Message) []shared.Message {
+	os.libraryConfirmMutex.Lock()
+	os.libraryConfirmStates[sessionID] = &LibraryConfirmState{Run: run, CreatedAt: time.Now()}
+	os.libraryConfirmMutex.Unlock()
+
+	messages := os.CreateWrappedTextMessage(sessionID, question)
+	return append(messages, shared.Message{Type: shared.MessageTypePrompt, Content: prompt})
+}
+
+// isInLibraryConfirm checks if a session is waiting for the overwrite answer
+func (os *TinyOS) isInLibraryConfirm(sessionID string) bool {
+	os.libraryConfirmMutex.RLock()
+	defer os.libraryConfirmMutex.RUnlock()
+	_, exists := os.libraryConfirmStates[sessionID]
+	return exists
+}
+
+// handleLibraryConfirmInput runs the pending action if the answer is yes
+func (os *TinyOS) handleLibraryConfirmInput(input string, sessionID string) []shared.Message {
+	os.libraryConfirmMutex.Lock()
+	state, exists := os.libraryConfirmStates[sessionID]
+	delete(os.libraryConfirmStates, sessionID)
+	os.libraryConfirmMutex.Unlock()
+
+	if !exists {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Nothing to confirm.")
+	}
+	answer := strings.ToLower(strings.TrimSpace(input))
+	if answer != "y" && answer != "yes" {
+		return os.CreateWrappedTextMessage(sessionID, "Cancelled, nothing was changed.")
+	}
+	return state.Run()
+}
@@ -0,0 +1,69 @@
+package tinyos
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// cmdLock makes files read-only so they cannot be overwritten or deleted by
+// accident
+func (os *TinyOS) cmdLock(args []string) []shared.Message {
+	return os.setFilesReadOnly(args, "lock", true)
+}
+
+// cmdUnlock makes locked files writable again
+func (os *TinyOS) cmdUnlock(args []string) []shared.Message {
+	return os.setFilesReadOnly(args, "unlock", false)
+}
+
+// setFilesReadOnly implements lock and unlock for files in the user's own
+// home tree
+func (os *TinyOS) setFilesReadOnly(args []string, command string, readOnly bool) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if len(cleanArgs) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: "+command+" <file> [file...]")
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+	userHome := "/home/" + username
+
+	os.sessionMutex.RLock()
+	currentPath := userHome
+	if session, exists := os.sessions[sessionID]; exists && session.CurrentPath != "" {
+		currentPath = session.CurrentPath
+	}
+	os.sessionMutex.RUnlock()
+
+	var lines []string
+	for _, arg := range cleanArgs {
+		targetPath := filepath.Clean(filepath.Join(currentPath, arg))
+		if filepath.IsAbs(arg) {
+			targetPath = filepath.Clean(arg)
+		}
+		targetPath = strings.ReplaceAll(targetPath, "\\", "/")
+
+		if !strings.HasPrefix(targetPath, userHome+"/") {
+			logger.Warn(logger.AreaAuth, "%s: access denied to path %s for user %s", command, targetPath, username)
+			lines = append(lines, command+": access denied to "+targetPath)
+			continue
+		}
+		if err := os.Vfs.SetReadOnly(targetPath, readOnly); err != nil {
+			lines = append(lines, command+": "+err.Error())
+			continue
+		}
+		if readOnly {
+			lines = append(lines, "Locked "+arg+".")
+		} else {
+			lines = append(lines, "Unlocked "+arg+".")
+		}
+	}
+	return os.CreateWrappedTextMessage(sessionID, strings.Join(lines, "\n"))
+}
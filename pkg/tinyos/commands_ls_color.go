@@ -0,0 +1,128 @@
+package tinyos
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+	"github.com/antibyte/retroterm/pkg/virtualfs"
+)
+
+// lsColorPreferenceKey is the user_preferences key turning colored ls
+// output on or off
+const lsColorPreferenceKey = "ls_color"
+
+// Palette levels of the entry types in colored ls output. The themes are
+// monochrome, so types differ in brightness; other files keep the normal
+// text color.
+const (
+	lsColorDir   = 15
+	lsColorBasic = 12
+	lsColorSID   = 10
+	lsColorText  = 7
+)
+
+// lsEntriesPerRow is the most entries ls puts on one row
+const lsEntriesPerRow = 5
+
+// lsColorEnabled reports whether ls colors its output for a session. It is
+// on unless a logged-in user turned it off with "set color off".
+func (os *TinyOS) lsColorEnabled(sessionID string) bool {
+	if os.isGuestSession(sessionID) {
+		return true
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return true
+	}
+	value, err := os.getUserPreference(username, lsColorPreferenceKey)
+	return err != nil || value != "off"
+}
+
+// lsEntryStyle returns the type indicator and palette level of an entry:
+// directories end in "/", BASIC programs, which run starts, in "*"
+func lsEntryStyle(entry virtualfs.DirEntry) (string, int) {
+	if entry.IsDir {
+		return "/", lsColorDir
+	}
+	switch strings.ToLower(path.Ext(entry.Name)) {
+	case ".bas":
+		return "*", lsColorBasic
+	case ".sid":
+		return "", lsColorSID
+	case ".txt", ".md":
+		return "", lsColorText
+	}
+	return "", 0
+}
+
+// listDirColor is the short ls listing with colored entries and type
+// indicators. Every entry is a message of its own so it can carry a color;
+// the first entry of a row starts a new line. Rows hold up to
+// lsEntriesPerRow entries and never get wider than the terminal.
+func (os *TinyOS) listDirColor(sessionID, username, targetPath string) []shared.Message {
+	entries, err := os.Vfs.ListDirLong(targetPath)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "Error: "+err.Error())
+	}
+
+	messages := []shared.Message{{Type: shared.MessageTypeText, Content: "Current directory: " + targetPath, SessionID: sessionID}}
+	cols, _ := os.GetTerminalDimensions(sessionID)
+	rowWidth, rowEntries := 0, 0
+	for _, entry := range entries {
+		indicator, color := lsEntryStyle(entry)
+		name := entry.Name + indicator
+
+		if rowEntries > 0 && (rowEntries == lsEntriesPerRow || rowWidth+2+len(name) > cols) {
+			rowWidth, rowEntries = 0, 0
+		}
+		msg := shared.Message{Type: shared.MessageTypeText, Content: name, Color: color, SessionID: sessionID}
+		if rowEntries > 0 {
+			// The separator goes in front, the frontend drops messages that are only spaces
+			msg.Content = "  " + name
+			msg.NoNewline = true
+			rowWidth += 2
+		}
+		messages = append(messages, msg)
+		rowWidth += len(name)
+		rowEntries++
+	}
+
+	if storageInfo, err := os.Vfs.GetUserStorageInfo(username); err == nil {
+		messages = append(messages, os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("\n%d of %d KB used", storageInfo.UsedKB, storageInfo.TotalKB))...)
+	}
+	return messages
+}
+
+// setLsColor handles "set color on" and "set color off"
+func (os *TinyOS) setLsColor(sessionID string, args []string) []shared.Message {
+	if len(args) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "color = "+formatOnOff(os.lsColorEnabled(sessionID)))
+	}
+	value := strings.ToLower(args[0])
+	if len(args) > 1 || (value != "on" && value != "off") {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: set color <on | off>")
+	}
+	if os.isGuestSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "set: please log in to change the colors.")
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+	if err := os.setUserPreference(username, lsColorPreferenceKey, value); err != nil {
+		logger.Error(logger.AreaDatabase, "Failed to save the ls colors for %s: %v", username, err)
+		return os.CreateWrappedTextMessage(sessionID, "set: could not save your preference.")
+	}
+	return os.CreateWrappedTextMessage(sessionID, "Colored ls output turned "+value+".")
+}
+
+// formatOnOff shows a setting as on or off
+func formatOnOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
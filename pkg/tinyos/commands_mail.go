@@ -0,0 +1,343 @@
+package tinyos
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// MailMessage is a stored message from the user_mail table
+type MailMessage struct {
+	ID      int64
+	Sender  string
+	Subject string
+	Body    string
+	SentAt  time.Time
+	IsRead  bool
+}
+
+// cmdMail handles the mail command: list, read, send and delete
+func (os *TinyOS) cmdMail(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+
+	// Gäste können weder senden noch empfangen
+	if os.isGuestSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "mail: please log in to use mail.")
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+	if os.db == nil {
+		return os.CreateWrappedTextMessage(sessionID, "mail: database not available.")
+	}
+
+	if len(cleanArgs) == 0 {
+		return os.mailList(sessionID, username)
+	}
+
+	switch strings.ToLower(cleanArgs[0]) {
+	case "list":
+		return os.mailList(sessionID, username)
+	case "read":
+		return os.mailRead(sessionID, username, cleanArgs[1:])
+	case "send":
+		return os.mailSend(sessionID, username, cleanArgs[1:])
+	case "delete", "del":
+		return os.mailDelete(sessionID, username, cleanArgs[1:])
+	default:
+		return os.CreateWrappedTextMessage(sessionID, "Usage: mail [list | read [id] | send <user> <subject> | delete <id>]")
+	}
+}
+
+// mailList shows the mailbox of the user, newest first
+func (os *TinyOS) mailList(sessionID, username string) []shared.Message {
+	rows, err := os.db.Query(
+		"SELECT id, sender, subject, sent_at, is_read FROM user_mail WHERE recipient = ? ORDER BY id DESC",
+		username)
+	if err != nil {
+		logger.Error(logger.AreaDatabase, "Error listing mail for %s: %v", username, err)
+		return os.CreateWrappedTextMessage(sessionID, "mail: could not read mailbox.")
+	}
+	defer rows.Close()
+
+	var lines []string
+	unread := 0
+	for rows.Next() {
+		var msg MailMessage
+		var sentAt int64
+		if err := rows.Scan(&msg.ID, &msg.Sender, &msg.Subject, &sentAt, &msg.IsRead); err != nil {
+			logger.Error(logger.AreaDatabase, "Error scanning mail for %s: %v", username, err)
+			continue
+		}
+		flag := " "
+		if !msg.IsRead {
+			flag = "N"
+			unread++
+		}
+		lines = append(lines, fmt.Sprintf("%s %4d  %-16s %s  %s",
+			flag, msg.ID, msg.Sender, time.Unix(sentAt, 0).Format("2006-01-02 15:04"), msg.Subject))
+	}
+
+	if len(lines) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "Your mailbox is empty.")
+	}
+	header := []string{fmt.Sprintf("%d message(s), %d new", len(lines), unread), ""}
+	cols, _ := os.GetTerminalDimensions(sessionID)
+	return os.showLinesWithPager(sessionID, "mail", os.wrapLinesForTerminal(append(header, lines...), cols))
+}
+
+// mailRead shows one message and marks it as read. Without an id the oldest unread message is shown.
+func (os *TinyOS) mailRead(sessionID, username string, args []string) []shared.Message {
+	var row *sql.Row
+	if len(args) > 0 {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return os.CreateWrappedTextMessage(sessionID, "Usage: mail read [id]")
+		}
+		row = os.db.QueryRow(
+			"SELECT id, sender, subject, body, sent_at, is_read FROM user_mail WHERE id = ? AND recipient = ?",
+			id, username)
+	} else {
+		row = os.db.QueryRow(
+			"SELECT id, sender, subject, body, sent_at, is_read FROM user_mail WHERE recipient = ? AND is_read = 0 ORDER BY id LIMIT 1",
+			username)
+	}
+
+	var msg MailMessage
+	var sentAt int64
+	if err := row.Scan(&msg.ID, &msg.Sender, &msg.Subject, &msg.Body, &sentAt, &msg.IsRead); err != nil {
+		if err == sql.ErrNoRows {
+			if len(args) > 0 {
+				return os.CreateWrappedTextMessage(sessionID, "mail: no such message.")
+			}
+			return os.CreateWrappedTextMessage(sessionID, "No new messages.")
+		}
+		logger.Error(logger.AreaDatabase, "Error reading mail for %s: %v", username, err)
+		return os.CreateWrappedTextMessage(sessionID, "mail: could not read message.")
+	}
+	msg.SentAt = time.Unix(sentAt, 0)
+
+	if !msg.IsRead {
+		if _, err := os.db.Exec("UPDATE user_mail SET is_read = 1 WHERE id = ?", msg.ID); err != nil {
+			logger.Error(logger.AreaDatabase, "Error marking mail %d as read: %v", msg.ID, err)
+		}
+	}
+
+	lines := []string{
+		"From:    " + msg.Sender,
+		"Date:    " + msg.SentAt.Format("2006-01-02 15:04"),
+		"Subject: " + msg.Subject,
+		"",
+	}
+	lines = append(lines, strings.Split(msg.Body, "\n")...)
+	cols, _ := os.GetTerminalDimensions(sessionID)
+	return os.showLinesWithPager(sessionID, fmt.Sprintf("mail %d", msg.ID), os.wrapLinesForTerminal(lines, cols))
+}
+
+// mailDelete removes a message from the user's mailbox
+func (os *TinyOS) mailDelete(sessionID, username string, args []string) []shared.Message {
+	if len(args) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: mail delete <id>")
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: mail delete <id>")
+	}
+
+	result, err := os.db.Exec("DELETE FROM user_mail WHERE id = ? AND recipient = ?", id, username)
+	if err != nil {
+		logger.Error(logger.AreaDatabase, "Error deleting mail %d for %s: %v", id, username, err)
+		return os.CreateWrappedTextMessage(sessionID, "mail: could not delete message.")
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "mail: no such message.")
+	}
+	return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("Message %d deleted.", id))
+}
+
+// mailSend validates recipient and subject and starts composing the body
+func (os *TinyOS) mailSend(sessionID, sender string, args []string) []shared.Message {
+	if len(args) < 2 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: mail send <user> <subject>")
+	}
+
+	recipient := strings.ToLower(args[0])
+	subject := strings.Join(args[1:], " ")
+	if maxSubject := configuration.GetInt("Mail", "max_subject_length", 60); len([]rune(subject)) > maxSubject {
+		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("mail: subject too long (max. %d characters).", maxSubject))
+	}
+	if recipient == "guest" || strings.HasPrefix(recipient, "guest-") || !os.UserExists(recipient) {
+		return os.CreateWrappedTextMessage(sessionID, "mail: unknown user "+recipient)
+	}
+	if msg := os.checkMailRateLimit(sender); msg != "" {
+		return os.CreateWrappedTextMessage(sessionID, msg)
+	}
+
+	os.mailComposeMutex.Lock()
+	os.mailComposeStates[sessionID] = &MailComposeState{
+		Sender:    sender,
+		Recipient: recipient,
+		Subject:   subject,
+		CreatedAt: time.Now(),
+	}
+	os.mailComposeMutex.Unlock()
+
+	return []shared.Message{
+		{Type: shared.MessageTypeText, Content: "To:      " + recipient},
+		{Type: shared.MessageTypeText, Content: "Subject: " + subject},
+		{Type: shared.MessageTypeText, Content: "Enter your message. End with a single '.' on a line, Ctrl+C cancels."},
+		{Type: shared.MessageTypePrompt, Content: "> "},
+	}
+}
+
+// checkMailRateLimit returns an error text if sender has used up the hourly
+// quota. It counts mail_send_log, which deleting mail does not shrink.
+func (os *TinyOS) checkMailRateLimit(sender string) string {
+	maxPerHour := configuration.GetInt("Mail", "max_messages_per_hour", 10)
+	var count int
+	cutoff := time.Now().Add(-time.Hour).Unix()
+	if _, err := os.db.Exec("DELETE FROM mail_send_log WHERE sent_at <= ?", cutoff); err != nil {
+		logger.Warn(logger.AreaDatabase, "Error pruning the mail send log: %v", err)
+	}
+	err := os.db.QueryRow("SELECT COUNT(*) FROM mail_send_log WHERE sender = ? AND sent_at > ?", sender, cutoff).Scan(&count)
+	if err != nil {
+		logger.Error(logger.AreaDatabase, "Error checking mail rate limit for %s: %v", sender, err)
+		return "mail: could not send message."
+	}
+	if count >= maxPerHour {
+		logger.SecurityWarn("Mail rate limit reached for user '%s' (%d messages in the last hour)", sender, count)
+		return fmt.Sprintf("mail: limit of %d messages per hour reached, please try again later.", maxPerHour)
+	}
+	return ""
+}
+
+// isInMailCompose checks if a session is entering a mail body
+func (os *TinyOS) isInMailCompose(sessionID string) bool {
+	os.mailComposeMutex.RLock()
+	defer os.mailComposeMutex.RUnlock()
+	_, exists := os.mailComposeStates[sessionID]
+	return exists
+}
+
+// handleMailComposeInput collects body lines until "." and then stores the message
+func (os *TinyOS) handleMailComposeInput(input string, sessionID string) []shared.Message {
+	os.mailComposeMutex.Lock()
+	state, exists := os.mailComposeStates[sessionID]
+	if !exists {
+		os.mailComposeMutex.Unlock()
+		return []shared.Message{{Type: shared.MessageTypeText, Content: "Error: No active mail found."}}
+	}
+
+	if input == "__BREAK__" {
+		delete(os.mailComposeStates, sessionID)
+		os.mailComposeMutex.Unlock()
+		return []shared.Message{
+			{Type: shared.MessageTypeText, Content: "Mail cancelled."},
+			{Type: shared.MessageTypeText, Content: ""},
+		}
+	}
+
+	line := strings.TrimRight(input, "\r\n")
+	if strings.TrimSpace(line) != "." {
+		maxLength := configuration.GetInt("Mail", "max_body_length", 4000)
+		if len(strings.Join(state.Body, "\n"))+len(line)+1 > maxLength {
+			os.mailComposeMutex.Unlock()
+			return []shared.Message{
+				{Type: shared.MessageTypeText, Content: fmt.Sprintf("Message too long (max. %d characters). End with '.' to send.", maxLength)},
+				{Type: shared.MessageTypePrompt, Content: "> "},
+			}
+		}
+		state.Body = append(state.Body, line)
+		os.mailComposeMutex.Unlock()
+		return []shared.Message{{Type: shared.MessageTypePrompt, Content: "> "}}
+	}
+
+	delete(os.mailComposeStates, sessionID)
+	os.mailComposeMutex.Unlock()
+
+	// Der Absender muss noch derselbe angemeldete Benutzer sein
+	if os.isGuestSession(sessionID) || os.GetUsernameForSession(sessionID) != state.Sender {
+		logger.SecurityWarn("Mail from '%s' discarded: session %s no longer belongs to the sender", state.Sender, sessionID)
+		return os.CreateWrappedTextMessage(sessionID, "mail: session changed, message discarded.")
+	}
+	if len(state.Body) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "Empty message not sent.")
+	}
+	if msg := os.checkMailRateLimit(state.Sender); msg != "" {
+		return os.CreateWrappedTextMessage(sessionID, msg)
+	}
+
+	var mailboxSize int
+	if err := os.db.QueryRow("SELECT COUNT(*) FROM user_mail WHERE recipient = ?", state.Recipient).Scan(&mailboxSize); err == nil &&
+		mailboxSize >= configuration.GetInt("Mail", "max_mailbox_size", 200) {
+		return os.CreateWrappedTextMessage(sessionID, "mail: mailbox of "+state.Recipient+" is full.")
+	}
+
+	_, err := os.db.Exec(
+		"INSERT INTO user_mail (sender, recipient, subject, body, sent_at) VALUES (?, ?, ?, ?, ?)",
+		state.Sender, state.Recipient, state.Subject, strings.Join(state.Body, "\n"), time.Now().Unix())
+	if err != nil {
+		logger.Error(logger.AreaDatabase, "Error storing mail from %s to %s: %v", state.Sender, state.Recipient, err)
+		return os.CreateWrappedTextMessage(sessionID, "mail: could not send message.")
+	}
+	logger.Info(logger.AreaGeneral, "Mail sent from %s to %s", state.Sender, state.Recipient)
+	if _, err := os.db.Exec("INSERT INTO mail_send_log (sender, sent_at) VALUES (?, ?)", state.Sender, time.Now().Unix()); err != nil {
+		logger.Error(logger.AreaDatabase, "Error logging mail from %s for the rate limit: %v", state.Sender, err)
+	}
+
+	os.notifyNewMail(state.Recipient, state.Sender)
+	return os.CreateWrappedTextMessage(sessionID, "Message sent to "+state.Recipient+".")
+}
+
+// notifyNewMail tells online sessions of recipient about a new message
+func (os *TinyOS) notifyNewMail(recipient, sender string) {
+	if os.SendToClientCallback == nil {
+		return
+	}
+
+	os.sessionMutex.RLock()
+	var targets []string
+	for id, session := range os.sessions {
+		if session.Username == recipient {
+			targets = append(targets, id)
+		}
+	}
+	os.sessionMutex.RUnlock()
+
+	for _, id := range targets {
+		msg := shared.Message{Type: shared.MessageTypeText, Content: "*** New mail from " + sender + " - type 'mail read' ***"}
+		if err := os.SendToClientCallback(id, msg); err != nil {
+			logger.Debug(logger.AreaGeneral, "Could not notify session %s about new mail: %v", id, err)
+		}
+	}
+}
+
+// newMailNotice returns the login notice for unread messages, or "" if there are none
+func (os *TinyOS) newMailNotice(username string) string {
+	if os.db == nil {
+		return ""
+	}
+	var unread int
+	if err := os.db.QueryRow("SELECT COUNT(*) FROM user_mail WHERE recipient = ? AND is_read = 0", username).Scan(&unread); err != nil {
+		logger.Error(logger.AreaDatabase, "Error counting new mail for %s: %v", username, err)
+		return ""
+	}
+	switch unread {
+	case 0:
+		return ""
+	case 1:
+		return "You have 1 new message. Type 'mail read' to read it."
+	default:
+		return fmt.Sprintf("You have %d new messages. Type 'mail' to list them.", unread)
+	}
+}
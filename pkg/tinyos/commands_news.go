@@ -0,0 +1,279 @@
+package tinyos
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// newsPageSize is the number of entries news shows per page
+const newsPageSize = 5
+
+// maxNewsTitleLength and maxNewsBodyLength limit a news entry in characters
+const (
+	maxNewsTitleLength = 60
+	maxNewsBodyLength  = 4000
+)
+
+// NewsPostState stores a news entry while an admin enters it
+type NewsPostState struct {
+	Stage     string    // "title", "body"
+	Author    string    // Admin taken from the session
+	Title     string    // Title entered in the first stage
+	Body      []string  // Body lines entered so far
+	CreatedAt time.Time // Time when posting was started
+}
+
+// cmdNews shows the news board page by page. Admins post and delete entries.
+func (os *TinyOS) cmdNews(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if os.db == nil {
+		return os.CreateWrappedTextMessage(sessionID, "news: database not available.")
+	}
+
+	if len(cleanArgs) == 0 {
+		return os.newsList(sessionID, 1)
+	}
+	switch strings.ToLower(cleanArgs[0]) {
+	case "post":
+		if !os.isAdminSession(sessionID) {
+			return os.CreateWrappedTextMessage(sessionID, "news: only admins can post news.")
+		}
+		if msgs := os.requireElevation(sessionID, "news post"); msgs != nil {
+			return msgs
+		}
+		return os.newsPost(sessionID, strings.Join(cleanArgs[1:], " "))
+	case "delete", "del":
+		if !os.isAdminSession(sessionID) {
+			return os.CreateWrappedTextMessage(sessionID, "news: only admins can delete news.")
+		}
+		if msgs := os.requireElevation(sessionID, "news delete"); msgs != nil {
+			return msgs
+		}
+		return os.newsDelete(sessionID, cleanArgs[1:])
+	}
+	page, err := strconv.Atoi(cleanArgs[0])
+	if err != nil || page < 1 || len(cleanArgs) > 1 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: news [page | post [title] | delete <id>]")
+	}
+	return os.newsList(sessionID, page)
+}
+
+// newsList shows one page of news, newest first
+func (os *TinyOS) newsList(sessionID string, page int) []shared.Message {
+	var total int
+	if err := os.db.QueryRow("SELECT COUNT(*) FROM news").Scan(&total); err != nil {
+		logger.Error(logger.AreaDatabase, "Error counting news: %v", err)
+		return os.CreateWrappedTextMessage(sessionID, "news: could not read the news.")
+	}
+	if total == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "No news yet.")
+	}
+	pages := (total + newsPageSize - 1) / newsPageSize
+	if page > pages {
+		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("news: there are only %d page(s).", pages))
+	}
+
+	rows, err := os.db.Query("SELECT id, author, title, body, posted_at FROM news ORDER BY id DESC LIMIT ? OFFSET ?",
+		newsPageSize, (page-1)*newsPageSize)
+	if err != nil {
+		logger.Error(logger.AreaDatabase, "Error listing news: %v", err)
+		return os.CreateWrappedTextMessage(sessionID, "news: could not read the news.")
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var id, postedAt int64
+		var author, title, body string
+		if err := rows.Scan(&id, &author, &title, &body, &postedAt); err != nil {
+			logger.Error(logger.AreaDatabase, "Error scanning news: %v", err)
+			continue
+		}
+		lines = append(lines,
+			fmt.Sprintf("#%d  %s  %s", id, time.Unix(postedAt, 0).Format("2006-01-02"), author),
+			strings.ToUpper(title))
+		lines = append(lines, strings.Split(body, "\n")...)
+		lines = append(lines, "")
+	}
+
+	footer := fmt.Sprintf("Page %d of %d.", page, pages)
+	if page < pages {
+		footer += fmt.Sprintf(" Type 'news %d' for older news.", page+1)
+	}
+	lines = append(lines, footer)
+	cols, _ := os.GetTerminalDimensions(sessionID)
+	return os.showLinesWithPager(sessionID, fmt.Sprintf("news %d/%d", page, pages), os.wrapLinesForTerminal(lines, cols))
+}
+
+// newsPost starts entering a news entry. Without a title it is asked first.
+func (os *TinyOS) newsPost(sessionID, title string) []shared.Message {
+	state := &NewsPostState{
+		Stage:     "title",
+		Author:    os.GetUsernameForSession(sessionID),
+		CreatedAt: time.Now(),
+	}
+	messages := []shared.Message{{Type: shared.MessageTypeText, Content: "Ctrl+C cancels."}}
+	if title != "" {
+		if msg := checkNewsTitle(title); msg != "" {
+			return os.CreateWrappedTextMessage(sessionID, msg)
+		}
+		state.Stage = "body"
+		state.Title = title
+		messages = append(messages, shared.Message{Type: shared.MessageTypeText, Content: "Title: " + title})
+	}
+
+	os.newsPostMutex.Lock()
+	os.newsPostStates[sessionID] = state
+	os.newsPostMutex.Unlock()
+
+	if state.Stage == "title" {
+		return append(messages, shared.Message{Type: shared.MessageTypePrompt, Content: "Title: "})
+	}
+	return append(messages, newsBodyPrompt()...)
+}
+
+// newsBodyPrompt asks for the body of a news entry
+func newsBodyPrompt() []shared.Message {
+	return []shared.Message{
+		{Type: shared.MessageTypeText, Content: "Enter the news. End with a single '.' on a line."},
+		{Type: shared.MessageTypePrompt, Content: "> "},
+	}
+}
+
+// checkNewsTitle returns an error text if title cannot be used
+func checkNewsTitle(title string) string {
+	if strings.TrimSpace(title) == "" {
+		return "news: the title must not be empty."
+	}
+	if len([]rune(title)) > maxNewsTitleLength {
+		return fmt.Sprintf("news: title too long (max. %d characters).", maxNewsTitleLength)
+	}
+	return ""
+}
+
+// isInNewsPost checks if a session is entering a news entry
+func (os *TinyOS) isInNewsPost(sessionID string) bool {
+	os.newsPostMutex.RLock()
+	defer os.newsPostMutex.RUnlock()
+	_, exists := os.newsPostStates[sessionID]
+	return exists
+}
+
+// handleNewsPostInput takes the title, then body lines until "." and stores
+// the entry
+func (os *TinyOS) handleNewsPostInput(input string, sessionID string) []shared.Message {
+	os.newsPostMutex.Lock()
+	state, exists := os.newsPostStates[sessionID]
+	if !exists {
+		os.newsPostMutex.Unlock()
+		return []shared.Message{{Type: shared.MessageTypeText, Content: "Error: No active news entry found."}}
+	}
+
+	if input == "__BREAK__" {
+		delete(os.newsPostStates, sessionID)
+		os.newsPostMutex.Unlock()
+		return []shared.Message{
+			{Type: shared.MessageTypeText, Content: "News cancelled."},
+			{Type: shared.MessageTypeText, Content: ""},
+		}
+	}
+
+	line := strings.TrimRight(input, "\r\n")
+	if state.Stage == "title" {
+		title := strings.TrimSpace(line)
+		if msg := checkNewsTitle(title); msg != "" {
+			os.newsPostMutex.Unlock()
+			return []shared.Message{
+				{Type: shared.MessageTypeText, Content: msg},
+				{Type: shared.MessageTypePrompt, Content: "Title: "},
+			}
+		}
+		state.Title = title
+		state.Stage = "body"
+		os.newsPostMutex.Unlock()
+		return newsBodyPrompt()
+	}
+
+	if strings.TrimSpace(line) != "." {
+		if len(strings.Join(state.Body, "\n"))+len(line)+1 > maxNewsBodyLength {
+			os.newsPostMutex.Unlock()
+			return []shared.Message{
+				{Type: shared.MessageTypeText, Content: fmt.Sprintf("News too long (max. %d characters). End with '.' to post.", maxNewsBodyLength)},
+				{Type: shared.MessageTypePrompt, Content: "> "},
+			}
+		}
+		state.Body = append(state.Body, line)
+		os.newsPostMutex.Unlock()
+		return []shared.Message{{Type: shared.MessageTypePrompt, Content: "> "}}
+	}
+
+	delete(os.newsPostStates, sessionID)
+	os.newsPostMutex.Unlock()
+
+	// Admin rights are checked again, they may have been revoked meanwhile
+	if os.GetUsernameForSession(sessionID) != state.Author || !os.isAdminSession(sessionID) {
+		logger.SecurityWarn("News from '%s' discarded: session %s is no longer an admin session of the author", state.Author, sessionID)
+		return os.CreateWrappedTextMessage(sessionID, "news: not allowed, entry discarded.")
+	}
+	if len(state.Body) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "Empty news not posted.")
+	}
+
+	result, err := os.db.Exec("INSERT INTO news (author, title, body, posted_at) VALUES (?, ?, ?, ?)",
+		state.Author, state.Title, strings.Join(state.Body, "\n"), time.Now().Unix())
+	if err != nil {
+		logger.Error(logger.AreaDatabase, "Error storing news from %s: %v", state.Author, err)
+		return os.CreateWrappedTextMessage(sessionID, "news: could not post the entry.")
+	}
+	id, _ := result.LastInsertId()
+	logger.Info(logger.AreaGeneral, "News #%d posted by %s", id, state.Author)
+	return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("News #%d posted.", id))
+}
+
+// newsDelete removes a news entry
+func (os *TinyOS) newsDelete(sessionID string, args []string) []shared.Message {
+	if len(args) != 1 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: news delete <id>")
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(args[0], "#"), 10, 64)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: news delete <id>")
+	}
+	result, err := os.db.Exec("DELETE FROM news WHERE id = ?", id)
+	if err != nil {
+		logger.Error(logger.AreaDatabase, "Error deleting news %d: %v", id, err)
+		return os.CreateWrappedTextMessage(sessionID, "news: could not delete the entry.")
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "news: no such entry.")
+	}
+	logger.Info(logger.AreaGeneral, "News #%d deleted by %s", id, os.GetUsernameForSession(sessionID))
+	return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("News #%d deleted.", id))
+}
+
+// latestNewsNotice returns the one-line login notice for the newest news
+// entry, or "" if there is none
+func (os *TinyOS) latestNewsNotice() string {
+	if os.db == nil {
+		return ""
+	}
+	var title string
+	var postedAt int64
+	err := os.db.QueryRow("SELECT title, posted_at FROM news ORDER BY id DESC LIMIT 1").Scan(&title, &postedAt)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error(logger.AreaDatabase, "Error reading latest news: %v", err)
+		}
+		return ""
+	}
+	return fmt.Sprintf("Latest news (%s): %s - type 'news' to read more.", time.Unix(postedAt, 0).Format("2006-01-02"), title)
+}
@@ -0,0 +1,20 @@
+package tinyos
+
+import (
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// cmdPing measures the WebSocket round trip: the client echoes the ping
+// message and the terminal handler prints the reply with the elapsed time
+func (os *TinyOS) cmdPing(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if len(cleanArgs) > 0 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: ping")
+	}
+	return []shared.Message{
+		{Type: shared.MessageTypeText, Content: "PING server"},
+		shared.NewPingMessage(time.Now()),
+	}
+}
@@ -0,0 +1,50 @@
+package tinyos
+
+import (
+	"github.com/antibyte/retroterm/pkg/editor"
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// cmdReboot resets the session to the state right after connecting: all
+// telnet, editor, chess, board and BASIC state is dropped, the screen is
+// cleared and the boot banner is shown again. The login is kept.
+func (os *TinyOS) cmdReboot(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if len(cleanArgs) > 0 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: reboot")
+	}
+
+	logger.Info(logger.AreaSession, "Rebooting session %s", sessionID)
+
+	// Stops the editor, telnet relay, top refresh and all pending dialogs
+	os.cleanupSessionState(sessionID)
+	editor.GetEditorManager().DiscardSuspendedEditor(sessionID)
+
+	os.mu.Lock()
+	if os.boardSessions != nil {
+		delete(os.boardSessions, sessionID)
+	}
+	os.mu.Unlock()
+
+	// The BASIC interpreter is owned by the terminal, which also supplies the banner
+	var banner []shared.Message
+	if os.RebootCallback != nil {
+		banner = os.RebootCallback(sessionID)
+	}
+	os.SetInputMode(sessionID, InputModeOSShell)
+
+	messages := []shared.Message{
+		{Type: shared.MessageTypeClear},
+		{Type: shared.MessageTypeMode, Content: "OS_SHELL"},
+		{Type: shared.MessageTypePager, Content: "deactivate"},
+	}
+	if banner == nil {
+		banner = []shared.Message{os.ThemeMessageForSession(sessionID)}
+	}
+	messages = append(messages, banner...)
+	return append(messages, shared.Message{Type: shared.MessageTypePrompt, PromptSymbol: os.GetPromptForSession(sessionID)})
+}
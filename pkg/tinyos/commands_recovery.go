@@ -0,0 +1,583 @@
+package tinyos
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Limits of a security answer. bcrypt only uses the first 72 bytes.
+const (
+	minRecoveryAnswerLength = 2
+	maxRecoveryAnswerLength = 64
+)
+
+// recoveryQuestions are the security questions users choose from. recover
+// shows the whole list to everyone and asks which one was chosen, so nothing
+// tells whether an account exists or has a question.
+var recoveryQuestions = []string{
+	"What was the name of your first pet?",
+	"What was your first computer?",
+	"In which city were you born?",
+	"What was your childhood nickname?",
+	"What is the title of your favorite book?",
+	"What was the first program you wrote?",
+}
+
+// decoyAnswerHash is compared against answers when the account or the chosen
+// question does not match, so that a wrong answer takes as long as a real one
+var (
+	decoyAnswerHash     []byte
+	decoyAnswerHashOnce sync.Once
+)
+
+// RecoveryState stores the state of recover or setrecovery
+type RecoveryState struct {
+	Stage       string    // recover: "username", "choice", "answer", "new", "confirm"; setrecovery: "password", "question", "setup_answer"
+	Username    string    // Account being recovered or set up
+	Question    string    // recover: stored question, never shown; setrecovery: question being set
+	AnswerHash  []byte    // Stored answer hash, nil if the account or the chosen question does not match
+	NewPassword string    // New password waiting for confirmation
+	Clear       bool      // setrecovery clear: remove the question after the password check
+	IPAddress   string    // IP address the attempts are counted for
+	CreatedAt   time.Time // Time when the process was started
+}
+
+// recoveryQuestionList returns the numbered list of security questions
+func recoveryQuestionList() []shared.Message {
+	messages := make([]shared.Message, 0, len(recoveryQuestions))
+	for i, question := range recoveryQuestions {
+		messages = append(messages, shared.Message{Type: shared.MessageTypeText, Content: fmt.Sprintf("%d. %s", i+1, question)})
+	}
+	return messages
+}
+
+// normalizeRecoveryAnswer makes answers independent of case and spacing
+func normalizeRecoveryAnswer(answer string) string {
+	return strings.Join(strings.Fields(strings.ToLower(answer)), " ")
+}
+
+// parseRecoveryQuestionChoice returns the security question with the number
+// the user entered
+func parseRecoveryQuestionChoice(input string) (string, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil || n < 1 || n > len(recoveryQuestions) {
+		return "", fmt.Errorf("please enter a number from 1 to %d", len(recoveryQuestions))
+	}
+	return recoveryQuestions[n-1], nil
+}
+
+// hashRecoveryAnswer checks and hashes a security answer
+func hashRecoveryAnswer(answer string) ([]byte, error) {
+	normalized := normalizeRecoveryAnswer(answer)
+	if n := len([]rune(normalized)); n < minRecoveryAnswerLength || n > maxRecoveryAnswerLength {
+		return nil, fmt.Errorf("the answer must be %d to %d characters long", minRecoveryAnswerLength, maxRecoveryAnswerLength)
+	}
+	return bcrypt.GenerateFromPassword([]byte(normalized), bcrypt.DefaultCost)
+}
+
+// checkRecoveryAnswer compares answer with hash, or with a decoy hash if the
+// account or the chosen question does not match
+func checkRecoveryAnswer(hash []byte, answer string) bool {
+	if hash == nil {
+		decoyAnswerHashOnce.Do(func() {
+			decoyAnswerHash, _ = bcrypt.GenerateFromPassword([]byte("decoy answer"), bcrypt.DefaultCost)
+		})
+		bcrypt.CompareHashAndPassword(decoyAnswerHash, []byte(normalizeRecoveryAnswer(answer)))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword(hash, []byte(normalizeRecoveryAnswer(answer))) == nil
+}
+
+// storeRecovery sets the security question of username, an empty question
+// removes it
+func (os *TinyOS) storeRecovery(username, question string, answerHash []byte) error {
+	if os.db == nil {
+		return fmt.Errorf("database not available")
+	}
+	_, err := os.db.Exec("UPDATE users SET recovery_question = ?, recovery_answer = ? WHERE username = ?",
+		question, string(answerHash), username)
+	return err
+}
+
+// lookupRecovery returns the security question and answer hash of username.
+// ok is false if the user does not exist or has no question.
+func (os *TinyOS) lookupRecovery(username string) (string, []byte, bool) {
+	if os.db == nil {
+		return "", nil, false
+	}
+	var question, answer string
+	err := os.db.QueryRow("SELECT recovery_question, recovery_answer FROM users WHERE username = ?", username).
+		Scan(&question, &answer)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error(logger.AreaAuth, "Looking up the security question of %s failed: %v", username, err)
+		}
+		return "", nil, false
+	}
+	if question == "" || answer == "" {
+		return "", nil, false
+	}
+	return question, []byte(answer), true
+}
+
+// handleRegistrationQuestionInput takes the number of the optional security
+// question at the end of the registration; an empty input skips it
+func (os *TinyOS) handleRegistrationQuestionInput(input string, sessionID string, state *RegistrationState) []shared.Message {
+	if strings.TrimSpace(input) == "" {
+		return os.completeRegistration(sessionID, state)
+	}
+	question, err := parseRecoveryQuestionChoice(input)
+	if err != nil {
+		return []shared.Message{
+			{Type: shared.MessageTypeText, Content: "Error: " + err.Error()},
+			{Type: shared.MessageTypePrompt, Content: "Security question: "},
+		}
+	}
+
+	os.registrationMutex.Lock()
+	state.RecoveryQuestion = question
+	state.Stage = "recovery_answer"
+	os.registrationMutex.Unlock()
+
+	return []shared.Message{
+		{Type: shared.MessageTypeText, Content: "Please enter the answer (case and spacing are ignored):"},
+		{Type: shared.MessageTypeInputControl, Content: "password_mode_on"}, // Enable password mode
+		{Type: shared.MessageTypePrompt, Content: "Answer: "},
+	}
+}
+
+// handleRegistrationAnswerInput takes the answer to the security question
+// and completes the registration
+func (os *TinyOS) handleRegistrationAnswerInput(input string, sessionID string, state *RegistrationState) []shared.Message {
+	hash, err := hashRecoveryAnswer(input)
+	if err != nil {
+		return []shared.Message{
+			{Type: shared.MessageTypeText, Content: "Error: " + err.Error()},
+			{Type: shared.MessageTypePrompt, Content: "Answer: "},
+		}
+	}
+
+	os.registrationMutex.Lock()
+	state.RecoveryAnswerHash = hash
+	os.registrationMutex.Unlock()
+
+	return os.completeRegistration(sessionID, state)
+}
+
+// cmdSetRecovery starts setting, replacing or (with "clear") removing the
+// security question of the logged-in user. The password is asked first.
+func (os *TinyOS) cmdSetRecovery(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if os.isGuestSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "setrecovery: please log in to set a security question.")
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+	clear := len(cleanArgs) == 1 && strings.EqualFold(cleanArgs[0], "clear")
+	if len(cleanArgs) > 0 && !clear {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: setrecovery [clear]")
+	}
+
+	ipAddress := "127.0.0.1"
+	os.sessionMutex.RLock()
+	if session, exists := os.sessions[sessionID]; exists {
+		ipAddress = session.IPAddress
+	}
+	os.sessionMutex.RUnlock()
+
+	if blocked, remaining := os.isLoginBlocked(ipAddress); blocked {
+		logger.SecurityWarn("setrecovery blocked for IP %s. %d seconds remaining", ipAddress, remaining)
+		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("setrecovery: too many failed attempts. Try again in %d seconds.", remaining))
+	}
+
+	os.recoveryMutex.Lock()
+	os.recoveryStates[sessionID] = &RecoveryState{
+		Stage:     "password",
+		Username:  username,
+		Clear:     clear,
+		IPAddress: ipAddress,
+		CreatedAt: time.Now(),
+	}
+	os.recoveryMutex.Unlock()
+
+	return []shared.Message{
+		{Type: shared.MessageTypeText, Content: "=== SECURITY QUESTION ==="},
+		{Type: shared.MessageTypeText, Content: ""},
+		{Type: shared.MessageTypeText, Content: "Please enter your current password:"},
+		{Type: shared.MessageTypeInputControl, Content: "password_mode_on"}, // Enable password mode
+		{Type: shared.MessageTypePrompt, Content: "Current password: "},
+	}
+}
+
+// cmdRecover starts resetting a forgotten password by answering the security
+// question. It is only available to guests.
+func (os *TinyOS) cmdRecover(args []string) []shared.Message {
+	sessionID, _ := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if !os.isGuestSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "recover: you are logged in. Use passwd to change your password.")
+	}
+	ipAddress := "127.0.0.1"
+	os.sessionMutex.RLock()
+	if session, exists := os.sessions[sessionID]; exists {
+		ipAddress = session.IPAddress
+	}
+	os.sessionMutex.RUnlock()
+
+	if blocked, remaining := os.isLoginBlocked(ipAddress); blocked {
+		logger.SecurityWarn("Recovery attempt blocked for IP %s. %d seconds remaining", ipAddress, remaining)
+		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("recover: too many failed attempts. Try again in %d seconds.", remaining))
+	}
+
+	os.recoveryMutex.Lock()
+	os.recoveryStates[sessionID] = &RecoveryState{
+		Stage:     "username",
+		IPAddress: ipAddress,
+		CreatedAt: time.Now(),
+	}
+	os.recoveryMutex.Unlock()
+
+	return []shared.Message{
+		{Type: shared.MessageTypeText, Content: "=== PASSWORD RECOVERY ==="},
+		{Type: shared.MessageTypeText, Content: ""},
+		{Type: shared.MessageTypeText, Content: "Please enter your username:"},
+		{Type: shared.MessageTypePrompt, Content: "Username: "},
+	}
+}
+
+// isInRecoveryProcess checks if a session is in recover or setrecovery
+func (os *TinyOS) isInRecoveryProcess(sessionID string) bool {
+	os.recoveryMutex.RLock()
+	defer os.recoveryMutex.RUnlock()
+	_, exists := os.recoveryStates[sessionID]
+	return exists
+}
+
+// endRecovery removes the recovery state of a session
+func (os *TinyOS) endRecovery(sessionID string) {
+	os.recoveryMutex.Lock()
+	delete(os.recoveryStates, sessionID)
+	os.recoveryMutex.Unlock()
+}
+
+// handleRecoveryInput processes input during recover and setrecovery
+func (os *TinyOS) handleRecoveryInput(input string, sessionID string) []shared.Message {
+	os.recoveryMutex.RLock()
+	state, exists := os.recoveryStates[sessionID]
+	os.recoveryMutex.RUnlock()
+
+	if !exists {
+		return []shared.Message{
+			{Type: shared.MessageTypeText, Content: "Error: No active recovery process found."},
+		}
+	}
+
+	if strings.ToLower(strings.TrimSpace(input)) == "__break__" {
+		os.endRecovery(sessionID)
+		logger.Info(logger.AreaAuth, "Recovery process (%s) cancelled by user for session %s", state.Stage, shortSessionID(sessionID))
+		return []shared.Message{
+			{Type: shared.MessageTypeInputControl, Content: "password_mode_off"}, // Disable password mode if active
+			{Type: shared.MessageTypeText, Content: "Cancelled."},
+		}
+	}
+
+	switch state.Stage {
+	case "username":
+		return os.handleRecoverUsernameInput(input, sessionID, state)
+	case "choice":
+		return os.handleRecoverChoiceInput(input, sessionID, state)
+	case "answer":
+		return os.handleRecoverAnswerInput(input, sessionID, state)
+	case "new":
+		return os.handleRecoverNewPasswordInput(input, sessionID, state)
+	case "confirm":
+		return os.handleRecoverConfirmInput(input, sessionID, state)
+	case "password":
+		return os.handleSetRecoveryPasswordInput(input, sessionID, state)
+	case "question":
+		return os.handleSetRecoveryQuestionInput(input, sessionID, state)
+	case "setup_answer":
+		return os.handleSetRecoveryAnswerInput(input, sessionID, state)
+	default:
+		os.endRecovery(sessionID)
+		return []shared.Message{
+			{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+			{Type: shared.MessageTypeText, Content: "Error: Unknown recovery status. Please start again."},
+		}
+	}
+}
+
+// handleRecoverUsernameInput looks up the security question of the user and
+// asks which of the questions it is. Everyone sees the same list, so the
+// output is the same whether the account exists or not.
+func (os *TinyOS) handleRecoverUsernameInput(input string, sessionID string, state *RecoveryState) []shared.Message {
+	username := strings.TrimSpace(input)
+	if username == "" {
+		return []shared.Message{
+			{Type: shared.MessageTypeText, Content: "Error: Username cannot be empty."},
+			{Type: shared.MessageTypePrompt, Content: "Username: "},
+		}
+	}
+
+	question, hash, _ := os.lookupRecovery(username)
+	os.recoveryMutex.Lock()
+	state.Username = username
+	state.Question = question
+	state.AnswerHash = hash
+	state.Stage = "choice"
+	os.recoveryMutex.Unlock()
+	logger.Info(logger.AreaAuth, "Password recovery started for '%s' from IP %s", username, state.IPAddress)
+
+	messages := []shared.Message{
+		{Type: shared.MessageTypeText, Content: ""},
+		{Type: shared.MessageTypeText, Content: "Which security question did you choose?"},
+	}
+	messages = append(messages, recoveryQuestionList()...)
+	return append(messages, shared.Message{Type: shared.MessageTypePrompt, Content: "Question number: "})
+}
+
+// handleRecoverChoiceInput takes the number of the question. A question that
+// is not the stored one is not rejected here; its answer is compared with the
+// decoy hash and fails like a wrong answer.
+func (os *TinyOS) handleRecoverChoiceInput(input string, sessionID string, state *RecoveryState) []shared.Message {
+	question, err := parseRecoveryQuestionChoice(input)
+	if err != nil {
+		return []shared.Message{
+			{Type: shared.MessageTypeText, Content: "Error: " + err.Error()},
+			{Type: shared.MessageTypePrompt, Content: "Question number: "},
+		}
+	}
+
+	os.recoveryMutex.Lock()
+	if question != state.Question {
+		state.AnswerHash = nil
+	}
+	state.Stage = "answer"
+	os.recoveryMutex.Unlock()
+
+	return []shared.Message{
+		{Type: shared.MessageTypeText, Content: question},
+		{Type: shared.MessageTypeInputControl, Content: "password_mode_on"}, // Enable password mode
+		{Type: shared.MessageTypePrompt, Content: "Answer: "},
+	}
+}
+
+// handleRecoverAnswerInput checks the answer. A wrong answer counts as a
+// failed login of the IP and ends the recovery.
+func (os *TinyOS) handleRecoverAnswerInput(input string, sessionID string, state *RecoveryState) []shared.Message {
+	if blocked, remaining := os.isLoginBlocked(state.IPAddress); blocked {
+		os.endRecovery(sessionID)
+		logger.SecurityWarn("Recovery attempt blocked for IP %s. %d seconds remaining", state.IPAddress, remaining)
+		return []shared.Message{
+			{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+			{Type: shared.MessageTypeText, Content: fmt.Sprintf("recover: too many failed attempts. Try again in %d seconds.", remaining)},
+		}
+	}
+
+	if !checkRecoveryAnswer(state.AnswerHash, input) {
+		os.endRecovery(sessionID)
+		os.recordFailedLoginAttempt(state.IPAddress)
+		logger.SecurityWarn("Password recovery failed for '%s' from IP %s: wrong question or answer, or no security question", state.Username, state.IPAddress)
+		return []shared.Message{
+			{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+			{Type: shared.MessageTypeText, Content: "The answer is not correct. Recovery cancelled."},
+		}
+	}
+
+	os.recoveryMutex.Lock()
+	state.Stage = "new"
+	os.recoveryMutex.Unlock()
+	logger.SecurityInfo("Security question of user '%s' answered from IP %s", state.Username, state.IPAddress)
+
+	return []shared.Message{
+		{Type: shared.MessageTypeText, Content: "Answer accepted."},
+		{Type: shared.MessageTypeText, Content: ""},
+		{Type: shared.MessageTypeText, Content: "Please enter your new password:"},
+		{Type: shared.MessageTypePrompt, Content: "New password: "},
+	}
+}
+
+// handleRecoverNewPasswordInput takes the new password
+func (os *TinyOS) handleRecoverNewPasswordInput(input string, sessionID string, state *RecoveryState) []shared.Message {
+	if err := validatePassword(input); err != nil {
+		return []shared.Message{
+			{Type: shared.MessageTypeText, Content: "Error: " + err.Error()},
+			{Type: shared.MessageTypePrompt, Content: "New password: "},
+		}
+	}
+
+	os.recoveryMutex.Lock()
+	state.NewPassword = input
+	state.Stage = "confirm"
+	os.recoveryMutex.Unlock()
+
+	return []shared.Message{
+		{Type: shared.MessageTypeText, Content: "Please confirm your new password:"},
+		{Type: shared.MessageTypePrompt, Content: "Confirm password: "},
+	}
+}
+
+// handleRecoverConfirmInput stores the new password once it is confirmed
+func (os *TinyOS) handleRecoverConfirmInput(input string, sessionID string, state *RecoveryState) []shared.Message {
+	if input != state.NewPassword {
+		os.recoveryMutex.Lock()
+		state.NewPassword = ""
+		state.Stage = "new"
+		os.recoveryMutex.Unlock()
+		return []shared.Message{
+			{Type: shared.MessageTypeText, Content: "Error: Passwords do not match."},
+			{Type: shared.MessageTypeText, Content: ""},
+			{Type: shared.MessageTypePrompt, Content: "New password: "},
+		}
+	}
+	os.endRecovery(sessionID)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(state.NewPassword), bcrypt.DefaultCost)
+	if err == nil {
+		if os.db == nil {
+			err = fmt.Errorf("database not available")
+		} else {
+			_, err = os.db.Exec("UPDATE users SET password = ? WHERE username = ?", hash, state.Username)
+		}
+	}
+	if err != nil {
+		logger.Error(logger.AreaAuth, "Password recovery of %s could not store the new password: %v", state.Username, err)
+		return []shared.Message{
+			{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+			{Type: shared.MessageTypeText, Content: "Error updating password."},
+		}
+	}
+
+	os.clearFailedLoginAttempts(state.IPAddress)
+	logger.SecurityInfo("Password of user '%s' reset by recovery from IP %s", state.Username, state.IPAddress)
+
+	return []shared.Message{
+		{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+		{Type: shared.MessageTypeText, Content: "✓ Password reset. You can now log in with 'login'."},
+		{Type: shared.MessageTypeText, Content: ""},
+	}
+}
+
+// handleSetRecoveryPasswordInput verifies the password before the security
+// question is changed
+func (os *TinyOS) handleSetRecoveryPasswordInput(input string, sessionID string, state *RecoveryState) []shared.Message {
+	if os.db == nil {
+		os.endRecovery(sessionID)
+		return []shared.Message{
+			{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+			{Type: shared.MessageTypeText, Content: "Database not available."},
+		}
+	}
+	var storedHash string
+	err := os.db.QueryRow("SELECT password FROM users WHERE username = ?", state.Username).Scan(&storedHash)
+	if err == nil {
+		err = bcrypt.CompareHashAndPassword([]byte(storedHash), []byte(input))
+	}
+	if err != nil {
+		os.endRecovery(sessionID)
+		os.recordFailedLoginAttempt(state.IPAddress)
+		logger.SecurityWarn("setrecovery failed for %s from IP %s: incorrect password", state.Username, state.IPAddress)
+		return []shared.Message{
+			{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+			{Type: shared.MessageTypeText, Content: "Password is incorrect. Security question unchanged."},
+		}
+	}
+
+	os.clearFailedLoginAttempts(state.IPAddress)
+
+	if state.Clear {
+		os.endRecovery(sessionID)
+		if err := os.storeRecovery(state.Username, "", nil); err != nil {
+			logger.Error(logger.AreaAuth, "Removing the security question of %s failed: %v", state.Username, err)
+			return []shared.Message{
+				{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+				{Type: shared.MessageTypeText, Content: "Error removing the security question."},
+			}
+		}
+		logger.Info(logger.AreaAuth, "Security question removed by %s", state.Username)
+		return []shared.Message{
+			{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+			{Type: shared.MessageTypeText, Content: "Security question removed. 'recover' is no longer possible for your account."},
+		}
+	}
+
+	os.recoveryMutex.Lock()
+	state.Stage = "question"
+	os.recoveryMutex.Unlock()
+
+	messages := []shared.Message{
+		{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+		{Type: shared.MessageTypeText, Content: "Password verified."},
+		{Type: shared.MessageTypeText, Content: ""},
+	}
+	if current, _, ok := os.lookupRecovery(state.Username); ok {
+		messages = append(messages, shared.Message{Type: shared.MessageTypeText, Content: "Current question: " + current})
+	}
+	messages = append(messages, shared.Message{Type: shared.MessageTypeText, Content: "Choose a security question:"})
+	messages = append(messages, recoveryQuestionList()...)
+	return append(messages, shared.Message{Type: shared.MessageTypePrompt, Content: "Security question: "})
+}
+
+// handleSetRecoveryQuestionInput takes the number of the new security question
+func (os *TinyOS) handleSetRecoveryQuestionInput(input string, sessionID string, state *RecoveryState) []shared.Message {
+	question, err := parseRecoveryQuestionChoice(input)
+	if err != nil {
+		return []shared.Message{
+			{Type: shared.MessageTypeText, Content: "Error: " + err.Error()},
+			{Type: shared.MessageTypePrompt, Content: "Security question: "},
+		}
+	}
+
+	os.recoveryMutex.Lock()
+	state.Question = question
+	state.Stage = "setup_answer"
+	os.recoveryMutex.Unlock()
+
+	return []shared.Message{
+		{Type: shared.MessageTypeText, Content: "Please enter the answer (case and spacing are ignored):"},
+		{Type: shared.MessageTypeInputControl, Content: "password_mode_on"}, // Enable password mode
+		{Type: shared.MessageTypePrompt, Content: "Answer: "},
+	}
+}
+
+// handleSetRecoveryAnswerInput hashes and stores the question and answer
+func (os *TinyOS) handleSetRecoveryAnswerInput(input string, sessionID string, state *RecoveryState) []shared.Message {
+	hash, err := hashRecoveryAnswer(input)
+	if err != nil {
+		return []shared.Message{
+			{Type: shared.MessageTypeText, Content: "Error: " + err.Error()},
+			{Type: shared.MessageTypePrompt, Content: "Answer: "},
+		}
+	}
+	os.endRecovery(sessionID)
+
+	if err := os.storeRecovery(state.Username, state.Question, hash); err != nil {
+		logger.Error(logger.AreaAuth, "Storing the security question of %s failed: %v", state.Username, err)
+		return []shared.Message{
+			{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+			{Type: shared.MessageTypeText, Content: "Error storing the security question."},
+		}
+	}
+	logger.Info(logger.AreaAuth, "Security question set by %s", state.Username)
+
+	return []shared.Message{
+		{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+		{Type: shared.MessageTypeText, Content: "✓ Security question saved. Use 'recover' if you forget your password."},
+	}
+}
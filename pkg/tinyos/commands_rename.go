@@ -0,0 +1,199 @@
+package tinyos
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// errUsernameTaken is returned by renameUserInDB if the new name already exists
+var errUsernameTaken = errors.New("username already taken")
+
+// RenameState remembers the requested name while rename asks for the password
+type RenameState struct {
+	OldName   string    // Current username
+	NewName   string    // Requested username
+	CreatedAt time.Time // Time when the rename was started
+}
+
+// cmdRename starts renaming the logged-in user. The password is asked for
+// before anything changes.
+func (os *TinyOS) cmdRename(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if len(cleanArgs) != 1 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: rename <newname>")
+	}
+	if os.isGuestSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "rename: please log in to change your username.")
+	}
+	oldName := os.GetUsernameForSession(sessionID)
+	if oldName == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+	if isTemporaryUser(oldName) {
+		return os.CreateWrappedTextMessage(sessionID, "rename: permission denied for user "+oldName+".")
+	}
+	if os.db == nil {
+		return os.CreateWrappedTextMessage(sessionID, "rename: database not available.")
+	}
+
+	newName := cleanArgs[0]
+	if newName == oldName {
+		return os.CreateWrappedTextMessage(sessionID, "rename: you are already "+oldName+".")
+	}
+	if err := validateUsername(newName); err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "rename: "+err.Error()+".")
+	}
+	if os.usernameExists(newName) {
+		return os.CreateWrappedTextMessage(sessionID, "rename: username "+newName+" is already taken.")
+	}
+
+	os.renameMutex.Lock()
+	os.renameStates[sessionID] = &RenameState{OldName: oldName, NewName: newName, CreatedAt: time.Now()}
+	os.renameMutex.Unlock()
+	return []shared.Message{
+		{Type: shared.MessageTypeText, Content: fmt.Sprintf("Renaming %s to %s.", oldName, newName)},
+		{Type: shared.MessageTypeText, Content: "Please confirm with your password:"},
+		{Type: shared.MessageTypeInputControl, Content: "password_mode_on"},
+		{Type: shared.MessageTypePrompt, Content: "Password: "},
+	}
+}
+
+// isInRenameProcess checks if a session is waiting for the rename password
+func (os *TinyOS) isInRenameProcess(sessionID string) bool {
+	os.renameMutex.RLock()
+	defer os.renameMutex.RUnlock()
+	_, exists := os.renameStates[sessionID]
+	return exists
+}
+
+// handleRenameInput checks the password and performs the rename
+func (os *TinyOS) handleRenameInput(input string, sessionID string) []shared.Message {
+	os.renameMutex.Lock()
+	state, exists := os.renameStates[sessionID]
+	delete(os.renameStates, sessionID)
+	os.renameMutex.Unlock()
+
+	if !exists {
+		return []shared.Message{{Type: shared.MessageTypeText, Content: "Error: No active rename found."}}
+	}
+	passwordOff := shared.Message{Type: shared.MessageTypeInputControl, Content: "password_mode_off"}
+	if input == "__BREAK__" {
+		return []shared.Message{passwordOff, {Type: shared.MessageTypeText, Content: "Rename cancelled."}}
+	}
+	if !os.VerifyPassword(state.OldName, input) {
+		logger.SecurityWarn("Rename of user '%s' refused: incorrect password", state.OldName)
+		return []shared.Message{passwordOff, {Type: shared.MessageTypeText, Content: "rename: incorrect password, rename cancelled."}}
+	}
+
+	if err := os.renameUserInDB(state.OldName, state.NewName); err != nil {
+		if errors.Is(err, errUsernameTaken) {
+			return []shared.Message{passwordOff, {Type: shared.MessageTypeText, Content: "rename: username " + state.NewName + " is already taken."}}
+		}
+		logger.Error(logger.AreaDatabase, "Rename of user %s to %s failed: %v", state.OldName, state.NewName, err)
+		return []shared.Message{passwordOff, {Type: shared.MessageTypeText, Content: "rename: could not change your username, nothing was changed."}}
+	}
+
+	if err := os.Vfs.RenameUserHome(state.OldName, state.NewName); err != nil {
+		logger.Warn(logger.AreaFileSystem, "Home directory of %s not moved in memory: %v", state.OldName, err)
+	}
+	os.renameUserSessions(state.OldName, state.NewName)
+	logger.SecurityInfo("User '%s' renamed to '%s' by session %s", state.OldName, state.NewName, sessionID)
+
+	return []shared.Message{
+		passwordOff,
+		{Type: shared.MessageTypeText, Content: fmt.Sprintf("You are now %s. Your files are in /home/%s.", state.NewName, state.NewName)},
+		// The session keeps its ID; the frontend fetches a token for the new name
+		{Type: shared.MessageTypeSession, SessionID: sessionID},
+	}
+}
+
+// usernameExists reports whether a user of that name is registered
+func (os *TinyOS) usernameExists(username string) bool {
+	var count int
+	if err := os.db.QueryRow("SELECT COUNT(*) FROM users WHERE username = ?", username).Scan(&count); err != nil {
+		return false
+	}
+	return count > 0
+}
+
+// renameUserInDB moves every row of oldName to newName in one transaction, so
+// a failure leaves the old name intact. Paths below /home/oldName move along.
+func (os *TinyOS) renameUserInDB(oldName, newName string) error {
+	tx, err := os.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var taken int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM users WHERE username = ?", newName).Scan(&taken); err != nil {
+		return err
+	}
+	if taken > 0 {
+		return errUsernameTaken
+	}
+
+	oldHome, newHome := "/home/"+oldName, "/home/"+newName
+	updates := []struct {
+		table string
+		query string
+		args  []interface{}
+	}{
+		{"users", "UPDATE users SET username = ? WHERE username = ?", []interface{}{newName, oldName}},
+		{"virtual_files", `UPDATE virtual_files SET username = ?,
+			path = CASE WHEN path = ? OR substr(path, 1, ?) = ? THEN ? || substr(path, ?) ELSE path END
+			WHERE username = ?`,
+			[]interface{}{newName, oldHome, len(oldHome) + 1, oldHome + "/", newHome, len(oldHome) + 1, oldName}},
+		{"user_sessions", "UPDATE user_sessions SET username = ? WHERE username = ?", []interface{}{newName, oldName}},
+		{"user_preferences", "UPDATE user_preferences SET username = ? WHERE username = ?", []interface{}{newName, oldName}},
+		{"basic_vars", "UPDATE basic_vars SET username = ? WHERE username = ?", []interface{}{newName, oldName}},
+		{"user_mail", "UPDATE user_mail SET sender = ? WHERE sender = ?", []interface{}{newName, oldName}},
+		{"user_mail", "UPDATE user_mail SET recipient = ? WHERE recipient = ?", []interface{}{newName, oldName}},
+		{"mail_send_log", "UPDATE mail_send_log SET sender = ? WHERE sender = ?", []interface{}{newName, oldName}},
+		{"chat_usage", "UPDATE chat_usage SET username = ? WHERE username = ?", []interface{}{newName, oldName}},
+		{"login_history", "UPDATE login_history SET username = ? WHERE username = ?", []interface{}{newName, oldName}},
+		{"board_messages", "UPDATE board_messages SET author = ? WHERE author = ?", []interface{}{newName, oldName}},
+		{"board_categories", "UPDATE board_categories SET created_by = ? WHERE created_by = ?", []interface{}{newName, oldName}},
+	}
+	for _, u := range updates {
+		// The board tables only exist once the board has been opened
+		var name string
+		err := tx.QueryRow("SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?", u.table).Scan(&name)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(u.query, u.args...); err != nil {
+			return fmt.Errorf("%s: %v", u.table, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// renameUserSessions moves the active sessions of oldName to newName,
+// including working directories below the old home directory
+func (os *TinyOS) renameUserSessions(oldName, newName string) {
+	oldHome, newHome := "/home/"+oldName, "/home/"+newName
+	os.sessionMutex.Lock()
+	defer os.sessionMutex.Unlock()
+	for _, session := range os.sessions {
+		if session.Username != oldName {
+			continue
+		}
+		session.Username = newName
+		if session.CurrentPath == oldHome || strings.HasPrefix(session.CurrentPath, oldHome+"/") {
+			session.CurrentPath = newHome + strings.TrimPrefix(session.CurrentPath, oldHome)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package tinyos
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// Named sound effects played by the frontend for system events
+const (
+	SFXDisk  = "disk"  // Floppy drive access
+	SFXError = "error" // Error buzz
+	SFXLogin = "login" // Login chime
+	SFXBeep  = "beep"  // Short beep
+)
+
+// defaultSFXVolume is used for guests and users without a saved volume
+const defaultSFXVolume = 80
+
+// volumePreferenceKey is the user_preferences key holding the SFX volume
+const volumePreferenceKey = "sfx_volume"
+
+// diskSFXInterval limits drive sounds so that programs reading many files
+// do not produce a continuous rattle
+const diskSFXInterval = time.Second
+
+// SFXMessage builds a sound effect event scaled to the volume of the session.
+// A muted session gets volume 0 and the frontend skips the effect.
+func (os *TinyOS) SFXMessage(sessionID, effect string) shared.Message {
+	return shared.Message{
+		Type: shared.MessageTypeSound,
+		Params: map[string]interface{}{
+			"action": "sfx",
+			"effect": effect,
+			"volume": float64(os.sfxVolume(sessionID)) / 100,
+		},
+	}
+}
+
+// playSFX sends a sound effect to the session outside of a command response
+func (os *TinyOS) playSFX(sessionID, effect string) {
+	if os.SendToClientCallback == nil || os.sfxVolume(sessionID) == 0 {
+		return
+	}
+	if err := os.SendToClientCallback(sessionID, os.SFXMessage(sessionID, effect)); err != nil {
+		logger.Debug(logger.AreaGeneral, "Could not send %s effect to session %s: %v", effect, sessionID, err)
+	}
+}
+
+// DiskAccess is called by the VFS whenever a session reads or writes a file
+func (os *TinyOS) DiskAccess(sessionID string, write bool) {
+	os.sfxMutex.Lock()
+	last := os.lastDiskSFX[sessionID]
+	if time.Since(last) < diskSFXInterval {
+		os.sfxMutex.Unlock()
+		return
+	}
+	os.lastDiskSFX[sessionID] = time.Now()
+	os.sfxMutex.Unlock()
+
+	// Senden asynchron, da der VFS-Aufrufer noch Sperren halten kann
+	go os.playSFX(sessionID, SFXDisk)
+}
+
+// sfxVolume returns the SFX volume (0-100) of a session
+func (os *TinyOS) sfxVolume(sessionID string) int {
+	os.sfxMutex.Lock()
+	volume, cached := os.sfxVolumes[sessionID]
+	os.sfxMutex.Unlock()
+	if cached {
+		return volume
+	}
+
+	volume = defaultSFXVolume
+	if sessionID != "" && !os.isGuestSession(sessionID) {
+		if username := os.GetUsernameForSession(sessionID); username != "" {
+			if value, err := os.getUserPreference(username, volumePreferenceKey); err == nil && value != "" {
+				if stored, err := strconv.Atoi(value); err == nil && stored >= 0 && stored <= 100 {
+					volume = stored
+				}
+			}
+		}
+	}
+
+	os.sfxMutex.Lock()
+	os.sfxVolumes[sessionID] = volume
+	os.sfxMutex.Unlock()
+	return volume
+}
+
+// forgetSFXVolume drops the cached volume, e.g. when the user of a session changes
+func (os *TinyOS) forgetSFXVolume(sessionID string) {
+	os.sfxMutex.Lock()
+	delete(os.sfxVolumes, sessionID)
+	os.sfxMutex.Unlock()
+}
+
+// cmdSet shows or changes terminal settings: the SFX volume, the ls colors and the prompt
+func (os *TinyOS) cmdSet(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+
+	if len(cleanArgs) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("volume = %s\ntheme = %s\nboot = %s\ncolor = %s\nprompt = %s",
+			formatVolume(os.sfxVolume(sessionID)), os.ThemeMessageForSession(sessionID).Content, os.BootModeForSession(sessionID),
+			formatOnOff(os.lsColorEnabled(sessionID)), strings.TrimSuffix(os.promptTemplate(sessionID), " ")))
+	}
+
+	switch strings.ToLower(cleanArgs[0]) {
+	case "volume":
+		return os.setVolume(sessionID, cleanArgs[1:])
+	case "color":
+		return os.setLsColor(sessionID, cleanArgs[1:])
+	case "prompt":
+		return os.setPrompt(sessionID, cleanArgs[1:])
+	default:
+		return os.CreateWrappedTextMessage(sessionID, "Usage: set [volume <0-100 | mute> | color <on | off> | prompt <template | default>]")
+	}
+}
+
+// setVolume handles "set volume n" and "set volume mute"
+func (os *TinyOS) setVolume(sessionID string, args []string) []shared.Message {
+	if len(args) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "volume = "+formatVolume(os.sfxVolume(sessionID)))
+	}
+	if len(args) > 1 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: set volume <0-100 | mute>")
+	}
+
+	var volume int
+	switch strings.ToLower(args[0]) {
+	case "mute", "off":
+		volume = 0
+	default:
+		value, err := strconv.Atoi(args[0])
+		if err != nil || value < 0 || value > 100 {
+			return os.CreateWrappedTextMessage(sessionID, "set: volume must be between 0 and 100, or 'mute'.")
+		}
+		volume = value
+	}
+
+	os.sfxMutex.Lock()
+	os.sfxVolumes[sessionID] = volume
+	os.sfxMutex.Unlock()
+
+	note := ""
+	if os.isGuestSession(sessionID) {
+		note = " Log in to keep it."
+	} else if username := os.GetUsernameForSession(sessionID); username != "" {
+		if err := os.setUserPreference(username, volumePreferenceKey, strconv.Itoa(volume)); err != nil {
+			logger.Error(logger.AreaDatabase, "Failed to save SFX volume for %s: %v", username, err)
+			note = " (not saved)"
+		}
+	}
+
+	messages := os.CreateWrappedTextMessage(sessionID, "Volume set to "+formatVolume(volume)+"."+note)
+	if volume > 0 {
+		// Probeton mit der neuen Lautstärke
+		messages = append(messages, os.SFXMessage(sessionID, SFXBeep))
+	}
+	return messages
+}
+
+// formatVolume renders a volume for display
+func formatVolume(volume int) string {
+	if volume == 0 {
+		return "muted"
+	}
+	return strconv.Itoa(volume)
+}
@@ -0,0 +1,130 @@
+package tinyos
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// SID header layout (all values big-endian), see the HVSC SID file format description
+const (
+	sidHeaderSizeV1   = 0x76 // Version 1 headers end right after the credit strings
+	sidHeaderSizeV2   = 0x7C // Version 2+ headers add flags, start page and SID addresses
+	sidStringFieldLen = 32
+)
+
+// SIDInfo contains the metadata stored in a PSID/RSID file header
+type SIDInfo struct {
+	Format      string // "PSID" or "RSID"
+	Version     int
+	DataOffset  int
+	LoadAddress int
+	InitAddress int
+	PlayAddress int
+	Songs       int
+	StartSong   int
+	Name        string
+	Author      string
+	Released    string // Usually the copyright line
+}
+
+// ParseSIDHeader extracts the metadata from the header of a PSID/RSID file.
+// Only the header is inspected, the C64 payload is never touched.
+func ParseSIDHeader(data []byte) (*SIDInfo, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("file too short to be a SID file")
+	}
+
+	format := string(data[0:4])
+	if format != "PSID" && format != "RSID" {
+		return nil, fmt.Errorf("not a SID file (missing PSID/RSID signature)")
+	}
+
+	if len(data) < sidHeaderSizeV1 {
+		return nil, fmt.Errorf("truncated SID header")
+	}
+
+	info := &SIDInfo{
+		Format:      format,
+		Version:     int(binary.BigEndian.Uint16(data[0x04:])),
+		DataOffset:  int(binary.BigEndian.Uint16(data[0x06:])),
+		LoadAddress: int(binary.BigEndian.Uint16(data[0x08:])),
+		InitAddress: int(binary.BigEndian.Uint16(data[0x0A:])),
+		PlayAddress: int(binary.BigEndian.Uint16(data[0x0C:])),
+		Songs:       int(binary.BigEndian.Uint16(data[0x0E:])),
+		StartSong:   int(binary.BigEndian.Uint16(data[0x10:])),
+		Name:        sidString(data[0x16 : 0x16+sidStringFieldLen]),
+		Author:      sidString(data[0x36 : 0x36+sidStringFieldLen]),
+		Released:    sidString(data[0x56 : 0x56+sidStringFieldLen]),
+	}
+
+	// PSID exists in versions 1-4, RSID was introduced with version 2
+	switch {
+	case format == "PSID" && (info.Version < 1 || info.Version > 4):
+		return nil, fmt.Errorf("unsupported PSID version %d", info.Version)
+	case format == "RSID" && (info.Version < 2 || info.Version > 4):
+		return nil, fmt.Errorf("unsupported RSID version %d", info.Version)
+	}
+
+	expectedOffset := sidHeaderSizeV1
+	if info.Version >= 2 {
+		expectedOffset = sidHeaderSizeV2
+	}
+	if info.DataOffset != expectedOffset || len(data) < info.DataOffset {
+		return nil, fmt.Errorf("corrupt SID header (invalid data offset)")
+	}
+
+	return info, nil
+}
+
+// sidString decodes a zero-padded Latin-1 credit field from a SID header
+func sidString(field []byte) string {
+	var sb strings.Builder
+	for _, b := range field {
+		if b == 0 {
+			break
+		}
+		sb.WriteRune(rune(b))
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// cmdSidInfo shows the metadata stored in the header of a SID music file
+func (os *TinyOS) cmdSidInfo(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if len(cleanArgs) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: sidinfo <file.sid>")
+	}
+
+	filename := cleanArgs[0]
+	content, err := os.ReadFileWithSession(filename, sessionID)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, "Error: "+err.Error())
+	}
+
+	info, err := ParseSIDHeader([]byte(content))
+	if err != nil {
+		logger.Debug(logger.AreaFileSystem, "sidinfo: cannot parse %s: %v", filename, err)
+		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("Error: %s: %v", filename, err))
+	}
+
+	valueOrUnknown := func(s string) string {
+		if s == "" || s == "<?>" {
+			return "<unknown>"
+		}
+		return s
+	}
+
+	lines := []string{
+		fmt.Sprintf("File:      %s", filename),
+		fmt.Sprintf("Format:    %s v%d", info.Format, info.Version),
+		fmt.Sprintf("Title:     %s", valueOrUnknown(info.Name)),
+		fmt.Sprintf("Author:    %s", valueOrUnknown(info.Author)),
+		fmt.Sprintf("Released:  %s", valueOrUnknown(info.Released)),
+		fmt.Sprintf("Songs:     %d (default: %d)", info.Songs, info.StartSong),
+	}
+	return os.CreateWrappedTextMessage(sessionID, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,199 @@
+package tinyos
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// defaultSudoTimeout is used when [Security] sudo_timeout is not set
+const defaultSudoTimeout = 5 * time.Minute
+
+// SudoState stores a sudo password prompt waiting for input
+type SudoState struct {
+	Username  string    // Admin who ran sudo
+	IPAddress string    // IP address for the failed attempt limit
+	CreatedAt time.Time // Time when sudo was started
+}
+
+// sudoTimeout returns how long admin mode lasts after sudo
+func sudoTimeout() time.Duration {
+	timeout := configuration.GetDuration("Security", "sudo_timeout", defaultSudoTimeout)
+	if timeout <= 0 {
+		return defaultSudoTimeout
+	}
+	return timeout
+}
+
+// cmdSudo asks an admin for the password again and then enables admin mode
+// for the session, which admin commands require. "sudo -k" ends it early.
+func (os *TinyOS) cmdSudo(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if len(cleanArgs) == 1 && cleanArgs[0] == "-k" {
+		if os.endElevation(sessionID) {
+			logger.SecurityInfo("Admin mode of session %s ended with sudo -k", shortSessionID(sessionID))
+		}
+		return os.CreateWrappedTextMessage(sessionID, "Admin mode ended.")
+	}
+	if len(cleanArgs) > 0 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: sudo [-k]")
+	}
+
+	username := os.GetUsernameForSession(sessionID)
+	if !os.isAdminSession(sessionID) {
+		logger.SecurityWarn("User %q (session %s) tried to use sudo", username, shortSessionID(sessionID))
+		return os.CreateWrappedTextMessage(sessionID, "sudo: permission denied, admins only.")
+	}
+
+	ipAddress := "127.0.0.1"
+	os.sessionMutex.RLock()
+	if session, exists := os.sessions[sessionID]; exists {
+		ipAddress = session.IPAddress
+	}
+	os.sessionMutex.RUnlock()
+
+	if blocked, remaining := os.isLoginBlocked(ipAddress); blocked {
+		logger.SecurityWarn("sudo blocked for IP %s. %d seconds remaining", ipAddress, remaining)
+		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("sudo: too many failed attempts. Try again in %d seconds.", remaining))
+	}
+
+	os.sudoMutex.Lock()
+	os.sudoStates[sessionID] = &SudoState{Username: username, IPAddress: ipAddress, CreatedAt: time.Now()}
+	os.sudoMutex.Unlock()
+
+	return []shared.Message{
+		{Type: shared.MessageTypeInputControl, Content: "password_mode_on"}, // Enable password mode
+		{Type: shared.MessageTypePrompt, Content: "[sudo] password for " + username + ": "},
+	}
+}
+
+// isInSudoPrompt checks if a session waits for its sudo password
+func (os *TinyOS) isInSudoPrompt(sessionID string) bool {
+	os.sudoMutex.Lock()
+	defer os.sudoMutex.Unlock()
+	_, exists := os.sudoStates[sessionID]
+	return exists
+}
+
+// handleSudoInput checks the sudo password. A wrong password counts as a
+// failed login of the IP.
+func (os *TinyOS) handleSudoInput(input string, sessionID string) []shared.Message {
+	os.sudoMutex.Lock()
+	state, exists := os.sudoStates[sessionID]
+	delete(os.sudoStates, sessionID)
+	os.sudoMutex.Unlock()
+
+	if !exists {
+		return []shared.Message{
+			{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+			{Type: shared.MessageTypeText, Content: "Error: No active sudo prompt found."},
+		}
+	}
+	if strings.ToLower(strings.TrimSpace(input)) == "__break__" {
+		return []shared.Message{
+			{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+			{Type: shared.MessageTypeText, Content: "Cancelled."},
+		}
+	}
+
+	// Admin rights are checked again, they may have been revoked meanwhile
+	if os.db == nil || os.GetUsernameForSession(sessionID) != state.Username || !os.isAdminSession(sessionID) {
+		return []shared.Message{
+			{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+			{Type: shared.MessageTypeText, Content: "sudo: permission denied, admins only."},
+		}
+	}
+
+	var storedHash string
+	err := os.db.QueryRow("SELECT password FROM users WHERE username = ?", state.Username).Scan(&storedHash)
+	if err == nil {
+		err = bcrypt.CompareHashAndPassword([]byte(storedHash), []byte(input))
+	}
+	if err != nil {
+		os.recordFailedLoginAttempt(state.IPAddress)
+		logger.SecurityWarn("sudo failed for admin '%s' (session %s) from IP %s: incorrect password",
+			state.Username, shortSessionID(sessionID), state.IPAddress)
+		return []shared.Message{
+			{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+			{Type: shared.MessageTypeText, Content: "sudo: incorrect password."},
+		}
+	}
+	os.clearFailedLoginAttempts(state.IPAddress)
+
+	timeout := sudoTimeout()
+	os.sudoMutex.Lock()
+	os.elevatedUntil[sessionID] = time.Now().Add(timeout)
+	os.sudoMutex.Unlock()
+
+	logger.SecurityInfo("Admin mode granted to '%s' (session %s) from IP %s for %v",
+		state.Username, shortSessionID(sessionID), state.IPAddress, timeout)
+	return []shared.Message{
+		{Type: shared.MessageTypeInputControl, Content: "password_mode_off"},
+		{Type: shared.MessageTypeText, Content: fmt.Sprintf("Admin mode enabled for %v. Use sudo -k to end it early.", timeout)},
+	}
+}
+
+// isElevatedSession reports whether an admin session is in admin mode. An
+// expired admin mode is removed.
+func (os *TinyOS) isElevatedSession(sessionID string) bool {
+	os.sudoMutex.Lock()
+	until, exists := os.elevatedUntil[sessionID]
+	expired := exists && !time.Now().Before(until)
+	if expired {
+		delete(os.elevatedUntil, sessionID)
+	}
+	os.sudoMutex.Unlock()
+
+	if expired {
+		logger.SecurityInfo("Admin mode of session %s expired", shortSessionID(sessionID))
+	}
+	return exists && !expired && os.isAdminSession(sessionID)
+}
+
+// requireElevation returns nil if the session may run the admin command now
+// and logs its use. Otherwise it returns the message asking for sudo.
+func (os *TinyOS) requireElevation(sessionID, command string) []shared.Message {
+	if !os.isElevatedSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, command+": admin mode required. Run sudo to confirm your password.")
+	}
+	logger.SecurityInfo("Admin '%s' (session %s) used %s in admin mode",
+		os.GetUsernameForSession(sessionID), shortSessionID(sessionID), command)
+	return nil
+}
+
+// endElevation ends admin mode and a pending sudo prompt of a session.
+// Returns true if admin mode was active.
+func (os *TinyOS) endElevation(sessionID string) bool {
+	os.sudoMutex.Lock()
+	defer os.sudoMutex.Unlock()
+	delete(os.sudoStates, sessionID)
+	_, exists := os.elevatedUntil[sessionID]
+	delete(os.elevatedUntil, sessionID)
+	return exists
+}
+
+// expireElevations removes every admin mode whose time is up
+func (os *TinyOS) expireElevations() {
+	now := time.Now()
+	os.sudoMutex.Lock()
+	var expired []string
+	for sessionID, until := range os.elevatedUntil {
+		if !now.Before(until) {
+			delete(os.elevatedUntil, sessionID)
+			expired = append(expired, sessionID)
+		}
+	}
+	os.sudoMutex.Unlock()
+
+	for _, sessionID := range expired {
+		logger.SecurityInfo("Admin mode of session %s expired", shortSessionID(sessionID))
+	}
+}
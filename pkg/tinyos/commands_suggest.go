@@ -0,0 +1,37 @@
+package tinyos
+
+import "github.com/antibyte/retroterm/pkg/shared"
+
+// adminOnlyCommands are not suggested to other users, so a typo does not
+// advertise them
+var adminOnlyCommands = map[string]bool{
+	"top":    true,
+	"setenv": true,
+	"getenv": true,
+	"sudo":   true,
+}
+
+// unknownCommandText is the error for a command the shell does not know. If
+// the name looks like a typo of a command the session may use, the nearest
+// ones are suggested.
+func (os *TinyOS) unknownCommandText(sessionID, cmd string) string {
+	suggestions := shared.Suggest(cmd, shellCommands)
+	var admin, adminChecked bool
+	allowed := suggestions[:0]
+	for _, name := range suggestions {
+		if adminOnlyCommands[name] {
+			if !adminChecked {
+				admin, adminChecked = os.isAdminSession(sessionID), true
+			}
+			if !admin {
+				continue
+			}
+		}
+		allowed = append(allowed, name)
+	}
+	text := "Unknown command: " + cmd
+	if hint := shared.DidYouMean(allowed); hint != "" {
+		text += " - " + hint
+	}
+	return text
+}
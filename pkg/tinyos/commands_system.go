@@ -7,42 +7,77 @@ import (
 	"time"
 
 	"github.com/antibyte/retroterm/pkg/chess"
+	"github.com/antibyte/retroterm/pkg/configuration"
 	"github.com/antibyte/retroterm/pkg/editor"
 	"github.com/antibyte/retroterm/pkg/logger"
 	"github.com/antibyte/retroterm/pkg/shared"
 )
 
+// shellCommands are the commands listed by help, in that order
+var shellCommands = []string{
+	"help", "echo", "clear", "basic", "run", "chess", "chat", "chathistory", "register", "login", "logout", "whoami", "ls", "dir", "pwd", "cd", "mkdir", "cat", "write", "rm", "limits", "resources", "edit", "view", "debug", "telnet", "date", "about", "passwd", "board", "sidinfo", "connect", "du", "mail", "theme", "set", "top", "reboot", "fetch", "boot", "rename", "kill", "export", "import", "ping", "env", "setenv", "getenv", "transfer", "whois", "setbio", "news", "lock", "unlock", "recover", "setrecovery", "sudo", "last", "examples", "vacuum", "uptime",
+}
+
 // cmdHelp displays help information
 func (os *TinyOS) cmdHelp(args []string) []shared.Message {
-	commands := []string{
-		"help", "echo", "clear", "basic", "run", "chess", "chat", "chathistory", "register", "login", "logout", "whoami", "ls", "pwd", "cd", "mkdir", "cat", "write", "rm", "limits", "resources", "edit", "view", "debug", "telnet", "date", "about", "passwd", "board",
-	}
 	helpTexts := map[string]string{
 		"help":  "help [command]\nShows a list of all commands or help for a specific command.\nExample: help ls",
 		"echo":  "echo <text>\nReturns the text.\nExample: echo Hello World",
-		"clear": "clear\nClears the screen.\nExample: clear", "basic": "basic\nStarts BASIC mode.\nExample: basic",
+		"clear": "clear\nClears the screen.\nExample: clear", "basic": "basic [-n]\nStarts BASIC mode. If your home directory has an autorun.bas, it is loaded and run first (not for guests); -n skips it.\nExample: basic -n",
 		"run":         "run <filename>\nRun a BASIC program directly from TinyOS.\nFilename can be with or without .bas extension.\nExample: run graphics\nExample: run sprites.bas",
 		"chess":       "chess [difficulty] [color]\nStarts a chess game with the computer.\nDifficulty: easy/1, medium/2, hard/3 (default: medium)\nColor: white/w, black/b (default: white)\nExample: chess\nExample: chess easy white\nExample: chess hard black",
-		"chat":        "chat\nStarts chat mode (login required).\nExample: chat",
+		"chat":        "chat [clear|export <file>]\nStarts chat mode (login required). Answers appear as they are written, Ctrl+C cancels an answer.\nclear deletes your chat history after asking, export saves it as a text file (.txt is added if no extension is given).\nExample: chat\nExample: chat clear\nExample: chat export mcp.txt",
 		"chathistory": "chathistory\nShows the chat history (login required).\nExample: chathistory",
 		"register":    "register\nStarts the registration process.\nExample: register",
 		"login":       "login\nStarts the login process.\nExample: login",
-		"logout":      "logout\nLogs out the current user.\nExample: logout",
+		"logout":      "logout [all]\nLogs out the current user. With all, your sessions on every other device are ended too.\nExample: logout\nExample: logout all",
 		"whoami":      "whoami\nShows the current username.\nExample: whoami",
-		"ls":          "ls [directory]\nLists the contents of a directory.\nExample: ls\nExample: ls /home/alice",
+		"ls":          "ls [-l] [directory]\nLists the contents of a directory.\nDirectories end in / and BASIC programs in *; on terminals that show color, directories, programs, SID and text files are shaded differently (set color off turns this off).\n-l shows one entry per line with flags, size and date: d directory, l linked example, r readable, w writable (a locked file has no w).\nExample: ls\nExample: ls -l basic",
 		"pwd":         "pwd\nShows the current directory.\nExample: pwd",
 		"cd":          "cd <directory>\nChanges the directory.\nExample: cd /home/alice",
 		"mkdir":       "mkdir <directory>\nCreates a new directory.\nExample: mkdir testdir",
-		"cat":         "cat <file>\nShows the contents of a file.\nExample: cat readme.txt",
+		"cat":         "cat <file>\nShows the contents of a file.\nLong files open in a pager: m, Space or Enter shows the next page, /text searches forward (/ alone repeats the search), :N goes to line N, q quits.\nExample: cat readme.txt",
 		"write":       "write <file> <content>\nWrites text to a file.\nExample: write test.txt Hello World", "rm": "rm <file/directory>\nDeletes a file or empty directory.\nExample: rm test.txt",
 		"limits":    "limits\nShows your current resource limits and file usage.\nExample: limits",
 		"resources": "resources\nShows detailed system resource statistics.\nExample: resources", "edit": "edit [filename]\nOpens the full-screen text editor.\nExample: edit\nExample: edit myfile.bas",
 		"view":   "view <filename>\nOpens a file in read-only mode (view only).\nExample: view readme.txt\nExample: view myfile.bas",
-		"telnet": "telnet <servername>\nConnect to a predefined telnet server.\nUse 'telnet list' to see available servers.\nExample: telnet towel\nExample: telnet list",
+		"telnet": "telnet <servername>\nConnect to a predefined telnet server.\nUse 'telnet list' to see available servers and your connection.\nUse 'telnet kill' to close a hung connection of this session.\nExample: telnet towel\nExample: telnet list",
 		"date":   "date\nShows the current date and time with year set to 1984.\nExample: date",
 		"about":  "about\nShows information about this terminal system.\nExample: about",
+		"uptime": "uptime\nShows how long the server has been running and when it was started. Admins also see the number of open sessions.\nExample: uptime",
 		"passwd": "passwd\nChanges the password of the current user.\nExample: passwd",
-		"board":  "board\nAccess the RetroTerm BBS message board system.\nGuests can read messages, registered users can post.\nExample: board",
+		"setrecovery": "setrecovery [clear]\nSets or replaces your security question for recover after asking for your password; the question is chosen from a list. With clear the question is removed. Wrong passwords count as failed logins.\nExample: setrecovery",
+		"recover": "recover\nResets a forgotten password: choose the security question of the account from the list and answer it. A wrong question or answer counts as a failed login.\nExample: recover",
+		"board":  "board\nAccess the RetroTerm BBS message board system.\nGuests can read messages, registered users can post.\nInside the board, 'who' lists users online ('who hide' / 'who show' to opt out or in).\nExample: board",
+		"sidinfo": "sidinfo <file.sid>\nShows title, author, copyright and song count of a SID music file.\nExample: sidinfo ull.sid",
+		"connect": "connect [raw] <servername|host:port>\nConnect to a predefined server.\nIn raw mode no telnet negotiation is done (plain TCP),\nfor line-based protocols and MUDs.\nExample: connect raw mud",
+		"dir": "dir [directory]\nShows the disk catalog of a directory: name, size and modification time of each entry, sorted by name, with the number of files and bytes at the end.\nLong listings open in the pager.\nExample: dir\nExample: dir basic",
+		"du": "du [path]\nShow the size of each directory in your home tree, largest first.\nExample: du basic",
+		"mail": "mail [list | read [id] | send <user> <subject> | delete <id>]\nSend and read messages between registered users. The body is entered line by line and ends with a single '.'.\nExample: mail send alice Hello",
+		"theme": "theme [list | <name>]\nShow the available terminal themes or switch to one. Logged-in users keep their choice across sessions.\nExample: theme amber",
+		"set": "set [volume <0-100 | mute> | color <on | off> | prompt <template | default>]\nShow or change terminal settings. The volume scales sound effects such as the drive and the error buzz; mute turns them off. color off makes ls list plain names instead of shading directories, programs, SID and text files. The prompt template can use \\u (username), \\w (current path, ~ for your home) and \\t (time); other text is shown as typed. Logged-in users keep the settings.\nExample: set volume 40\nExample: set color off\nExample: set prompt \\u:\\w \\t>",
+		"top": "top [-d seconds] [-n frames]\nShow sessions, modes and resource usage (admin only, needs sudo).\n-d redraws the view every few seconds, -n sets the number of frames.\nEntering any command stops the refresh.\nExample: top -d 5",
+		"reboot": "reboot\nReset this session to a clean state without logging out.\nEnds telnet, editor, chess and BASIC sessions.\nExample: reboot",
+		"fetch": "fetch <url> <filename>\nDownload a small text or BASIC file into your home directory.\nOnly http and https URLs on hosts allowed by the admin. Requires login.\nExample: fetch https://example.com/demo.bas demo.bas",
+		"whois": "whois <username>\nShow the public profile of a registered user: registration date, whether they are online and their bio.\nExample: whois alice",
+		"last": "last [username] [count]\nShow your recent logins with time, IP address and whether the password was wrong, newest first (10 by default, at most 50). Requires login.\nAdmins may see the logins of any user after sudo.\nExample: last 20",
+		"setbio": "setbio [<text> | clear]\nShow or set the bio others see with whois, at most 160 characters. clear removes it. Requires login.\nExample: setbio Writing games in BASIC since 1983",
+		"news": "news [page | post [title] | delete <id>]\nRead the news of this system, newest first, a few entries per page. The newest entry is also shown at login.\nAdmins add entries with post after sudo: the title is asked first, then the text line by line, ending with a single '.'.\nExample: news 2",
+		"lock": "lock <file> [file...]\nProtect your files from being overwritten or deleted by accident. Locked files can still be read, loaded and run; SAVE, write and rm refuse them. ls -l shows them without w.\nExample: lock basic/game.bas",
+		"unlock": "unlock <file> [file...]\nMake locked files writable again.\nExample: unlock basic/game.bas",
+		"examples": "examples\nLinks the example programs into your basic directory and readme.txt into your home. Guests who opened the terminal with ?scratch=1 start without them; for everyone else it brings back deleted examples. Your own files with the same name are kept.\nExample: examples",
+		"boot": "boot [full | fast]\nShow or choose what you see when you connect. fast skips the start-up sequence and only greets you; full is the default. Logged-in users keep the setting.\nExample: boot fast",
+		"rename": "rename <newname>\nChange your username. Asks for your password first; your home directory, mail and settings move to the new name.\nExample: rename newname",
+		"kill": "kill [session]\nStop the BASIC program running in another of your sessions, e.g. a tab that no longer responds. Without a session, lists your other sessions; the first 8 characters of the id are enough. Admins may stop any session after sudo.\nExample: kill 3f9a2c1e",
+		"export": "export <file.json>\nSave all your .bas files (not the linked examples) in one JSON file, e.g. as a backup or to move them to another account.\nExample: export mylib.json",
+		"import": "import <file.json>\nRestore the programs of a file written by export into your home directory. Asks before overwriting files and imports nothing if the programs do not fit your quota.\nExample: import mylib.json",
+		"ping": "ping\nMeasure the round-trip time between your terminal and the server.\nExample: ping",
+		"env": "env\nLists the environment variables of your session.\nGuests see only USER, HOME, PWD and the terminal size.\nExample: env",
+		"sudo":   "sudo [-k]\nConfirm your password to enter admin mode for a few minutes (admins only). Admin commands like top, setenv, getenv, vacuum, news post and kill on other users' sessions need it.\n-k ends admin mode early.\nExample: sudo",
+		"setenv": "setenv NAME [VALUE]\nSets a system environment variable (admins only, needs sudo).\nWithout a value the variable is removed.\nExample: setenv MOTD Welcome",
+		"vacuum": "vacuum\nCompact the database and show how much space was reclaimed (admins only, needs sudo). Runs in the background; the result follows when it is done.\nExample: vacuum",
+		"getenv": "getenv NAME\nShows the value of an environment variable (admins only, needs sudo).\nExample: getenv MOTD",
+		"transfer": "transfer [code]\nContinue your session on another device. Without a code, shows a single-use code that expires after a few minutes. Enter transfer <code> on the other device to log in there; this session is then logged out.\nExample: transfer K7QX-M2PA",
 	}
 
 	// SessionID aus args extrahieren, wenn vorhanden
@@ -53,7 +88,7 @@ func (os *TinyOS) cmdHelp(args []string) []shared.Message {
 	}
 
 	if len(args) == 0 {
-		result := "Available commands:\n" + strings.Join(commands, ", ") + "\n\nUse help <command> for details"
+		result := "Available commands:\n" + strings.Join(shellCommands, ", ") + "\n\nUse help <command> for details"
 		return os.CreateWrappedTextMessage(sessionID, result)
 	}
 
@@ -97,6 +132,9 @@ func (os *TinyOS) cmdChat(args []string) []shared.Message {
 	if sessionID == "" {
 		return os.CreateWrappedTextMessage("", "Register and login to use the chat.")
 	}
+	if len(args) > 1 {
+		return os.cmdChatSubcommand(sessionID, args[1:])
+	}
 	username := os.GetUsernameForSession(sessionID)
 	if username == "" {
 		return os.CreateWrappedTextMessage(sessionID, "Register and login to use the chat.")
@@ -128,14 +166,16 @@ func (os *TinyOS) cmdChat(args []string) []shared.Message {
 	// Für Sondernachrichten wie Sound müssen wir weiterhin direkt shared.Message verwenden
 	// und können diese dann mit der Textnachricht kombinieren
 	messages := []shared.Message{
-		{Type: shared.MessageTypeSound, Content: "floppy"},
-		{Type: shared.MessageTypeChat, Content: "chat"}, // Neuer MessageType für Chat-Aktivierung
+		os.SFXMessage(sessionID, SFXDisk),
+		{Type: shared.MessageTypeChat, Content: "chat", Params: map[string]interface{}{ // Neuer MessageType für Chat-Aktivierung
+			"muteMusic": configuration.GetBool("Terminal", "pause_music_in_telnet_chat", true),
+		}},
 	}
 
 	// Nachricht mit Text-Wrapping hinzufügen
 	wrappedMsg := os.CreateWrappedTextMessage(sessionID, "Connecting to Master control program...")
 	messages = append(messages, wrappedMsg...)
-	wrappedMsg = os.CreateWrappedTextMessage(sessionID, "Ask your questions now. Enter 'exit' to return to the OS.")
+	wrappedMsg = os.CreateWrappedTextMessage(sessionID, "Ask your questions now. Ctrl+C cancels an answer, enter 'exit' to return to the OS.")
 	messages = append(messages, wrappedMsg...)
 	return messages
 }
@@ -271,9 +311,16 @@ func (os *TinyOS) cmdBasic(args []string) []shared.Message {
 		basicFilesMsg = fmt.Sprintf("Available programs: %s", strings.Join(programFiles, ", "))
 	}
 	// Important: This message contains the mode switch command
+	modeMessage := shared.Message{Type: shared.MessageTypeMode, Content: "basic"} // Signals frontend to switch to BASIC mode
+	// "basic -n" starts without the autorun program
+	if len(args) < 2 || args[1] != "-n" {
+		if autorun := os.autorunProgram(sessionID, username); autorun != "" {
+			modeMessage.Params = map[string]interface{}{AutorunParam: autorun}
+		}
+	}
 	messages := []shared.Message{
-		{Type: shared.MessageTypeMode, Content: "basic"}, // Signals frontend to switch to BASIC mode
-		{Type: shared.MessageTypeSound, Content: "floppy"},
+		modeMessage,
+		os.SFXMessage(sessionID, SFXDisk),
 		{Type: shared.MessageTypeText, Content: "TinyBASIC v1.0"},
 		{Type: shared.MessageTypeText, Content: "Ready"},
 	}
@@ -360,10 +407,19 @@ func (os *TinyOS) cmdLimits(args []string) []shared.Message {
 		response.WriteString(fmt.Sprintf("Files per Directory: max %d\n", stats.MaxFilesPerDir))
 		response.WriteString(fmt.Sprintf("File Size: max %.1f KB\n", float64(limits.MaxFileSize)/1024))
 		response.WriteString(fmt.Sprintf("Home Directory: %d/%d files\n", stats.HomeDirectoryFiles, stats.MaxFilesPerDir))
+		if stats.MaxEntries > 0 {
+			response.WriteString(fmt.Sprintf("Files and Directories: %d/%d\n", stats.EntryCount, stats.MaxEntries))
+		}
+		if stats.MaxPathDepth > 0 {
+			response.WriteString(fmt.Sprintf("Directory Depth: max %d levels\n", stats.MaxPathDepth))
+		}
 		// Status-Warnungen
 		if stats.DirectoryCount >= stats.MaxDirectories {
 			response.WriteString("\n!!! Directory limit reached!\n")
 		}
+		if stats.MaxEntries > 0 && stats.EntryCount >= stats.MaxEntries {
+			response.WriteString("\n!!! File and directory limit reached!\n")
+		}
 		if stats.HomeDirectoryFiles >= int(0.9*float64(stats.MaxFilesPerDir)) {
 			response.WriteString("\n!!! Home directory nearly full!\n")
 		}
@@ -729,7 +785,7 @@ func (os *TinyOS) cmdRun(args []string) []shared.Message {
 	} // Switch to BASIC mode with autorun parameter
 	messages := []shared.Message{
 		{Type: shared.MessageTypeMode, Content: "basic-autorun:" + filename}, // Special mode with autorun filename
-		{Type: shared.MessageTypeSound, Content: "floppy"},
+		os.SFXMessage(sessionID, SFXDisk),
 		{Type: shared.MessageTypeText, Content: "TinyBASIC v1.0"},
 		{Type: shared.MessageTypeText, Content: fmt.Sprintf("Loading and running %s...", filename)},
 		{Type: shared.MessageTypeText, Content: ""}, // Empty line for better readability
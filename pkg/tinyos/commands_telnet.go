@@ -38,7 +38,7 @@ func (os *TinyOS) cmdTelnet(args []string) []shared.Message {
 	// Show help if no server specified
 	if len(args) < 2 {
 		logger.Info(logger.AreaTerminal, "cmdTelnet: no server specified for session %s", sessionID)
-		return os.CreateWrappedTextMessage(sessionID, "telnet: server name required\nUsage: telnet <servername> or telnet list")
+		return os.CreateWrappedTextMessage(sessionID, "telnet: server name required\nUsage: telnet <servername>, telnet list or telnet kill")
 	}
 
 	serverArg := strings.ToLower(args[1])
@@ -47,7 +47,45 @@ func (os *TinyOS) cmdTelnet(args []string) []shared.Message {
 	if serverArg == "list" {
 		logger.Info(logger.AreaTerminal, "cmdTelnet: listing servers for session %s", sessionID)
 		return os.getTelnetServerList(sessionID)
-	} // Check if user is already in a telnet session
+	}
+	if serverArg == "kill" {
+		return os.killOwnTelnetSession(sessionID)
+	}
+
+	return os.startRemoteSession(sessionID, serverArg, ConnectionModeTelnet)
+}
+
+// cmdConnect opens a connection to an allowed target, optionally in raw mode.
+// "connect raw <server|host:port>" skips all telnet option negotiation, which
+// line-based protocols and some MUDs need since they choke on IAC sequences.
+func (os *TinyOS) cmdConnect(args []string) []shared.Message {
+	if len(args) == 0 {
+		return os.CreateWrappedTextMessage("", "connect: session ID missing")
+	}
+	sessionID := args[0]
+
+	usage := "Usage: connect [raw] <servername|host:port>\nOnly targets from 'telnet list' are allowed."
+	if len(args) < 2 {
+		return os.CreateWrappedTextMessage(sessionID, "connect: target required\n"+usage)
+	}
+
+	mode := ConnectionModeTelnet
+	target := args[1]
+	if strings.EqualFold(target, string(ConnectionModeRaw)) || strings.EqualFold(target, string(ConnectionModeTelnet)) {
+		mode = ConnectionMode(strings.ToLower(target))
+		if len(args) < 3 {
+			return os.CreateWrappedTextMessage(sessionID, "connect: target required\n"+usage)
+		}
+		target = args[2]
+	}
+
+	return os.startRemoteSession(sessionID, strings.ToLower(target), mode)
+}
+
+// startRemoteSession connects a session to an allowed target and starts the
+// background reader. Telnet and raw connections share the same state and cleanup.
+func (os *TinyOS) startRemoteSession(sessionID, serverArg string, mode ConnectionMode) []shared.Message {
+	// Check if user is already in a telnet session
 	logger.Info(logger.AreaTerminal, "TELNET_DIAGNOSTIC: Checking if session %s already in telnet process", sessionID)
 	if os.isInTelnetProcess(sessionID) {
 		logger.Warn(logger.AreaTerminal, "cmdTelnet: session %s already in telnet session", sessionID)
@@ -56,31 +94,26 @@ func (os *TinyOS) cmdTelnet(args []string) []shared.Message {
 	logger.Info(logger.AreaTerminal, "TELNET_DIAGNOSTIC: Session %s not in telnet process, proceeding", sessionID)
 
 	// Check connection limits to prevent resource exhaustion
-	os.telnetMutex.RLock()
-	totalConnections := len(os.telnetStates)
-	logger.Info(logger.AreaTerminal, "TELNET_DIAGNOSTIC: Current telnet connections: %d/%d", totalConnections, 10)
-	os.telnetMutex.RUnlock()
-
-	const MAX_TELNET_CONNECTIONS = 10 // Limit concurrent telnet connections
-	if totalConnections >= MAX_TELNET_CONNECTIONS {
-		logger.Warn(logger.AreaTerminal, "cmdTelnet: connection limit reached (%d/%d)", totalConnections, MAX_TELNET_CONNECTIONS)
-		return os.CreateWrappedTextMessage(sessionID, "Telnet connection limit reached. Please try again later.")
+	if refusal := os.reserveTelnetSlot(sessionID); refusal != "" {
+		return os.CreateWrappedTextMessage(sessionID, refusal)
 	}
 
 	// Get server configuration
 	logger.Info(logger.AreaTerminal, "cmdTelnet: getting server config for '%s'", serverArg)
-	serverConfig, err := os.getTelnetServerConfig(serverArg)
+	serverConfig, err := os.resolveConnectTarget(serverArg)
 	if err != nil {
 		logger.Error(logger.AreaTerminal, "cmdTelnet: server config error for '%s': %v", serverArg, err)
+		os.releaseTelnetSlot(sessionID)
 		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("telnet: %v", err))
 	}
 	logger.Info(logger.AreaTerminal, "cmdTelnet: server config found - %s at %s", serverConfig.DisplayName, serverConfig.Host)
 	// Attempt to connect
-	logger.Info(logger.AreaTerminal, "Attempting telnet connection to %s for session %s", serverConfig.Host, sessionID)
+	logger.Info(logger.AreaTerminal, "Attempting %s connection to %s for session %s", mode, serverConfig.Host, sessionID)
 	conn, err := net.DialTimeout("tcp", serverConfig.Host, 10*time.Second)
 	if err != nil {
 		logger.Error(logger.AreaTerminal, "Telnet connection failed to %s: %v", serverConfig.Host, err)
 		logger.Error(logger.AreaTerminal, "TELNET_DIAGNOSTIC: Connection failure for session %s to %s", sessionID, serverConfig.Host)
+		os.releaseTelnetSlot(sessionID)
 		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("telnet: connection failed to %s", serverConfig.DisplayName))
 	}
 	logger.Info(logger.AreaTerminal, "Telnet connection established to %s for session %s", serverConfig.Host, sessionID)
@@ -96,45 +129,54 @@ func (os *TinyOS) cmdTelnet(args []string) []shared.Message {
 		LastActivity: time.Now(),
 		OutputChan:   outputChan,
 		ShutdownChan: shutdownChan,
+		Mode:         mode,
 		ServerEcho:   false, // Will be determined during negotiation
 		LocalEcho:    true,  // Default to local echo until server takes over
 	}
-	// Store telnet state
+	// Store telnet state; it takes over the reserved slot
 	os.telnetMutex.Lock()
+	telnetState.owner = os.telnetPending[sessionID]
+	delete(os.telnetPending, sessionID)
 	os.telnetStates[sessionID] = telnetState
 	logger.Info(logger.AreaTerminal, "Telnet state stored for session %s, total sessions: %d", sessionID, len(os.telnetStates))
 	logger.Info(logger.AreaTerminal, "TELNET_DIAGNOSTIC: Telnet state successfully stored for session %s", sessionID)
-	os.telnetMutex.Unlock() // Send initial telnet negotiation - corrected for mapscii compatibility
-	logger.Info(logger.AreaTerminal, "Sending corrected telnet negotiations to %s for session %s", serverConfig.Host, sessionID)
-	// Standard telnet client behavior - send initial capabilities
-	// IAC WILL TERMINAL_TYPE - we can provide terminal type
-	conn.Write([]byte{255, 251, 24}) // IAC WILL TERMINAL_TYPE
-	logger.Info(logger.AreaTerminal, "Sent WILL TERMINAL_TYPE to %s", serverConfig.Host)
-
-	// IAC WILL NAWS - we can provide window size
-	conn.Write([]byte{255, 251, 31})                                            // IAC WILL NAWS
-	logger.Info(logger.AreaTerminal, "Sent WILL NAWS to %s", serverConfig.Host) // IAC WILL SUPPRESS_GO_AHEAD - we support suppressing go-ahead
-	conn.Write([]byte{255, 251, 3})                                             // IAC WILL SUPPRESS_GO_AHEAD
-	logger.Info(logger.AreaTerminal, "Sent WILL SUPPRESS_GO_AHEAD to %s", serverConfig.Host)
-
-	// IAC WONT ECHO - we don't want to handle local echo (server should echo)
-	// This is important for proper echo handling with telnet servers
-	conn.Write([]byte{255, 252, 1}) // IAC WONT ECHO
-	logger.Info(logger.AreaTerminal, "Sent WONT ECHO to %s (server should handle echo)", serverConfig.Host)
-
-	// Get actual client terminal dimensions instead of hardcoded values
-	clientCols, clientRows := os.GetTerminalDimensions(sessionID)
-
-	// IAC SB NAWS width1 width2 height1 height2 IAC SE
-	windowSizeCmd := []byte{
-		255, 250, 31, // IAC SB NAWS
-		byte(clientCols >> 8), byte(clientCols & 0xFF), // columns as 2 bytes
-		byte(clientRows >> 8), byte(clientRows & 0xFF), // rows as 2 bytes
-		255, 240, // IAC SE
-	}
-	conn.Write(windowSizeCmd)
-	logger.Info(logger.AreaTerminal, "Sent window size (%dx%d) to %s", clientCols, clientRows, serverConfig.Host)
-	logger.Info(logger.AreaTerminal, "Initial telnet negotiations completed for %s", serverConfig.Host)
+	os.telnetMutex.Unlock()
+
+	if mode == ConnectionModeTelnet {
+		// Send initial telnet negotiation - corrected for mapscii compatibility
+		logger.Info(logger.AreaTerminal, "Sending corrected telnet negotiations to %s for session %s", serverConfig.Host, sessionID)
+		// Standard telnet client behavior - send initial capabilities
+		// IAC WILL TERMINAL_TYPE - we can provide terminal type
+		conn.Write([]byte{255, 251, 24}) // IAC WILL TERMINAL_TYPE
+		logger.Info(logger.AreaTerminal, "Sent WILL TERMINAL_TYPE to %s", serverConfig.Host)
+
+		// IAC WILL NAWS - we can provide window size
+		conn.Write([]byte{255, 251, 31})                                            // IAC WILL NAWS
+		logger.Info(logger.AreaTerminal, "Sent WILL NAWS to %s", serverConfig.Host) // IAC WILL SUPPRESS_GO_AHEAD - we support suppressing go-ahead
+		conn.Write([]byte{255, 251, 3})                                             // IAC WILL SUPPRESS_GO_AHEAD
+		logger.Info(logger.AreaTerminal, "Sent WILL SUPPRESS_GO_AHEAD to %s", serverConfig.Host)
+
+		// IAC WONT ECHO - we don't want to handle local echo (server should echo)
+		// This is important for proper echo handling with telnet servers
+		conn.Write([]byte{255, 252, 1}) // IAC WONT ECHO
+		logger.Info(logger.AreaTerminal, "Sent WONT ECHO to %s (server should handle echo)", serverConfig.Host)
+
+		// Get actual client terminal dimensions instead of hardcoded values
+		clientCols, clientRows := os.GetTerminalDimensions(sessionID)
+
+		// IAC SB NAWS width1 width2 height1 height2 IAC SE
+		windowSizeCmd := []byte{
+			255, 250, 31, // IAC SB NAWS
+			byte(clientCols >> 8), byte(clientCols & 0xFF), // columns as 2 bytes
+			byte(clientRows >> 8), byte(clientRows & 0xFF), // rows as 2 bytes
+			255, 240, // IAC SE
+		}
+		conn.Write(windowSizeCmd)
+		logger.Info(logger.AreaTerminal, "Sent window size (%dx%d) to %s", clientCols, clientRows, serverConfig.Host)
+		logger.Info(logger.AreaTerminal, "Initial telnet negotiations completed for %s", serverConfig.Host)
+	} else {
+		logger.Info(logger.AreaTerminal, "Raw connection to %s, skipping telnet negotiation", serverConfig.Host)
+	}
 
 	logger.Info(logger.AreaTerminal, "Telnet session started for %s to %s", sessionID, serverConfig.DisplayName)
 
@@ -142,22 +184,32 @@ func (os *TinyOS) cmdTelnet(args []string) []shared.Message {
 	go os.handleTelnetSession(telnetState)
 
 	// Send an initial "activation" input after a short delay to prevent timeout
-	// Many interactive telnet services like mapscii expect user activity to stay connected
-	go func() {
-		time.Sleep(1 * time.Second) // Wait for negotiation to complete
-		logger.Info(logger.AreaTerminal, "Sending initial activation input to keep %s connection alive", serverConfig.Host)
-		// Send a simple newline to show activity without affecting the display
-		conn.Write([]byte{13}) // Carriage return
-	}()
+	// Many interactive telnet services like mapscii expect user activity to stay connected.
+	// Raw protocols get exactly what the user types.
+	if mode == ConnectionModeTelnet {
+		go func() {
+			time.Sleep(1 * time.Second) // Wait for negotiation to complete
+			logger.Info(logger.AreaTerminal, "Sending initial activation input to keep %s connection alive", serverConfig.Host)
+			// Send a simple newline to show activity without affecting the display
+			conn.Write([]byte{13}) // Carriage return
+		}()
+	}
+
+	connectedMsg := fmt.Sprintf("Connected to %s", serverConfig.DisplayName)
+	if mode == ConnectionModeRaw {
+		connectedMsg += " (raw)"
+	}
 
 	// Send initial messages
 	return []shared.Message{
 		{Type: shared.MessageTypeText, Content: fmt.Sprintf("Connecting to %s...", serverConfig.DisplayName)},
-		{Type: shared.MessageTypeText, Content: fmt.Sprintf("Connected to %s", serverConfig.DisplayName)},
+		{Type: shared.MessageTypeText, Content: connectedMsg},
 		{Type: shared.MessageTypeText, Content: "Press Ctrl+X or ESC to exit telnet session"},
 		{Type: shared.MessageTypeText, Content: "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"},
 		{Type: shared.MessageTypeTelnet, Content: "start", SessionID: sessionID, Params: map[string]interface{}{
-			"serverName": serverConfig.DisplayName,
+			"serverName":  serverConfig.DisplayName,
+			"clearScreen": configuration.GetBool("Terminal", "telnet_clear_screen", true),
+			"muteMusic":   configuration.GetBool("Terminal", "pause_music_in_telnet_chat", true),
 		}},
 	}
 }
@@ -182,6 +234,31 @@ func (os *TinyOS) getTelnetServerConfig(serverName string) (*TelnetServerConfig,
 	}, nil
 }
 
+// resolveConnectTarget looks up a target by its configured name or by its
+// host:port. Either way the target must be listed in the [Telnet] section, so
+// raw connections are subject to the same allowlist as telnet.
+func (os *TinyOS) resolveConnectTarget(target string) (*TelnetServerConfig, error) {
+	if config, err := os.getTelnetServerConfig(target); err == nil {
+		return config, nil
+	}
+
+	if strings.Contains(target, ":") {
+		for _, configValue := range configuration.GetSection("Telnet") {
+			parts := strings.Split(configValue, "|")
+			if len(parts) >= 2 && strings.EqualFold(strings.TrimSpace(parts[1]), target) {
+				return &TelnetServerConfig{
+					DisplayName: parts[0],
+					Host:        strings.TrimSpace(parts[1]),
+				}, nil
+			}
+		}
+		logger.SecurityWarn("Connection to non-allowed target %s rejected", target)
+		return nil, fmt.Errorf("target '%s' is not allowed. Use 'telnet list' to see available servers", target)
+	}
+
+	return nil, fmt.Errorf("server '%s' not found. Use 'telnet list' to see available servers", target)
+}
+
 // isInTelnetProcess checks if a session is currently in a telnet process
 func (os *TinyOS) isInTelnetProcess(sessionID string) bool {
 	os.telnetMutex.RLock()
@@ -236,10 +313,55 @@ func (os *TinyOS) getTelnetServerList(sessionID string) []shared.Message {
 		}
 	}
 
-	content.WriteString("\nUsage: telnet <servername>")
+	if state, active := os.ownTelnetConnection(sessionID); active {
+		content.WriteString(fmt.Sprintf("\nYour connection: %s (%s, %s)\n", state.ServerName, state.ServerHost, state.Mode))
+		content.WriteString(fmt.Sprintf("Connected %s ago, idle %s. Use 'telnet kill' if it hangs.\n",
+			time.Since(state.CreatedAt).Round(time.Second), time.Since(state.LastActivity).Round(time.Second)))
+	}
+
+	content.WriteString("\nUsage: telnet <servername> or connect raw <servername>")
 	return os.CreateWrappedTextMessage(sessionID, content.String())
 }
 
+// ownTelnetConnection returns a copy of the connection details of the
+// caller's telnet session; other sessions are never looked at
+func (os *TinyOS) ownTelnetConnection(sessionID string) (TelnetState, bool) {
+	os.telnetMutex.RLock()
+	defer os.telnetMutex.RUnlock()
+	state, exists := os.telnetStates[sessionID]
+	if !exists || state == nil {
+		return TelnetState{}, false
+	}
+	return TelnetState{
+		ServerName:   state.ServerName,
+		ServerHost:   state.ServerHost,
+		Mode:         state.Mode,
+		CreatedAt:    state.CreatedAt,
+		LastActivity: state.LastActivity,
+	}, true
+}
+
+// killOwnTelnetSession force-resets the telnet session of the caller, e.g.
+// when a hung connection blocks starting a new one
+func (os *TinyOS) killOwnTelnetSession(sessionID string) []shared.Message {
+	state, active := os.ownTelnetConnection(sessionID)
+	if !active {
+		return os.CreateWrappedTextMessage(sessionID, "telnet: no active telnet session.")
+	}
+
+	logger.Info(logger.AreaTerminal, "User killed telnet session to %s for session %s", state.ServerHost, sessionID)
+	os.ForceResetTelnetState(sessionID)
+	if os.GetInputMode(sessionID) == InputModeTelnet {
+		os.SetInputMode(sessionID, InputModeOSShell)
+	}
+
+	messages := []shared.Message{
+		{Type: shared.MessageTypeTelnet, Content: "end", SessionID: sessionID},
+		{Type: shared.MessageTypeMode, Content: "OS_SHELL"},
+	}
+	return append(messages, os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("Telnet connection to %s closed.", state.ServerName))...)
+}
+
 // TelnetServerConfig holds configuration for a telnet server
 type TelnetServerConfig struct {
 	DisplayName string
@@ -390,8 +512,11 @@ func (os *TinyOS) handleTelnetSession(telnetState *TelnetState) {
 			telnetState.LastActivity = time.Now()
 			logger.Debug(logger.AreaTerminal, "Telnet received %d bytes from %s for session %s", n, telnetState.ServerHost, telnetState.SessionID)
 
-			// Process and filter telnet protocol bytes before sending
-			filteredData := os.filterTelnetProtocolWithNegotiation(buffer[:n], telnetState)
+			// Process and filter telnet protocol bytes before sending (raw data is passed through)
+			filteredData := buffer[:n]
+			if telnetState.Mode != ConnectionModeRaw {
+				filteredData = os.filterTelnetProtocolWithNegotiation(buffer[:n], telnetState)
+			}
 
 			if len(filteredData) > 0 {
 				// Send data with non-blocking approach
@@ -0,0 +1,190 @@
+package tinyos
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// TerminalTheme describes the look of the CRT terminal in the frontend
+type TerminalTheme struct {
+	Name        string
+	Description string
+	Foreground  string  // Text color, brightest palette entry (#RRGGBB)
+	Glow        string  // Phosphor background glow (#RRGGBB)
+	Scanlines   float64 // Scanline intensity (0.0 to 1.0)
+	Font        string  // CSS font family
+}
+
+// defaultThemeName is used when neither settings.cfg nor the user chose a theme
+const defaultThemeName = "green"
+
+// themePreferenceKey is the user_preferences key holding the theme name
+const themePreferenceKey = "theme"
+
+// builtinThemes are the themes selectable with the theme command
+var builtinThemes = []TerminalTheme{
+	{Name: "green", Description: "Classic green phosphor (P1)", Foreground: "#5FFF5F", Glow: "#003300", Scanlines: 0.006, Font: "monospace"},
+	{Name: "amber", Description: "Amber monochrome monitor (P3)", Foreground: "#FFB000", Glow: "#2A1A00", Scanlines: 0.008, Font: "monospace"},
+	{Name: "white", Description: "Paper white terminal (P4)", Foreground: "#E8E8E8", Glow: "#1A1A1A", Scanlines: 0.004, Font: "monospace"},
+	{Name: "blue", Description: "Cool blue mainframe display", Foreground: "#66CCFF", Glow: "#001A2A", Scanlines: 0.006, Font: "monospace"},
+	{Name: "c64", Description: "Home computer light blue", Foreground: "#A5A5FF", Glow: "#14143A", Scanlines: 0.012, Font: "'Courier New', monospace"},
+}
+
+// findTheme looks up a built-in theme by name (case-insensitive)
+func findTheme(name string) (TerminalTheme, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, theme := range builtinThemes {
+		if theme.Name == name {
+			return theme, true
+		}
+	}
+	return TerminalTheme{}, false
+}
+
+// defaultTheme returns the theme from [Terminal] default_theme, or green
+func defaultTheme() TerminalTheme {
+	if theme, ok := findTheme(configuration.GetString("Terminal", "default_theme", defaultThemeName)); ok {
+		return theme
+	}
+	theme, _ := findTheme(defaultThemeName)
+	return theme
+}
+
+// palette derives the 16 brightness levels used by the frontend from the
+// foreground color; level 0 is black and level 15 the foreground itself
+func (t TerminalTheme) palette() []string {
+	r, g, b := parseHexColor(t.Foreground)
+	levels := make([]string, 16)
+	for i := range levels {
+		levels[i] = fmt.Sprintf("#%02X%02X%02X", r*i/15, g*i/15, b*i/15)
+	}
+	return levels
+}
+
+// parseHexColor splits #RRGGBB into its components
+func parseHexColor(color string) (int, int, int) {
+	value, err := strconv.ParseUint(strings.TrimPrefix(color, "#"), 16, 32)
+	if err != nil {
+		return 0, 0, 0
+	}
+	return int(value >> 16 & 0xFF), int(value >> 8 & 0xFF), int(value & 0xFF)
+}
+
+// Message builds the theme descriptor sent to the frontend
+func (t TerminalTheme) Message() shared.Message {
+	return shared.Message{
+		Type:    shared.MessageTypeTheme,
+		Content: t.Name,
+		Params: map[string]interface{}{
+			"name":       t.Name,
+			"foreground": t.Foreground,
+			"glow":       t.Glow,
+			"palette":    t.palette(),
+			"scanlines":  t.Scanlines,
+			"font":       t.Font,
+		},
+	}
+}
+
+// ThemeMessageForSession returns the theme descriptor for a session: the
+// stored preference of a logged-in user, otherwise the default theme
+func (os *TinyOS) ThemeMessageForSession(sessionID string) shared.Message {
+	if sessionID == "" || os.isGuestSession(sessionID) {
+		return defaultTheme().Message()
+	}
+	return os.themeForUser(os.GetUsernameForSession(sessionID)).Message()
+}
+
+// themeForUser returns the stored theme of a user or the default theme
+func (os *TinyOS) themeForUser(username string) TerminalTheme {
+	if username == "" {
+		return defaultTheme()
+	}
+	name, err := os.getUserPreference(username, themePreferenceKey)
+	if err != nil || name == "" {
+		return defaultTheme()
+	}
+	if theme, ok := findTheme(name); ok {
+		return theme
+	}
+	return defaultTheme()
+}
+
+// getUserPreference reads a value from user_preferences, "" if it is not set
+func (os *TinyOS) getUserPreference(username, key string) (string, error) {
+	if os.db == nil {
+		return "", fmt.Errorf("database not available")
+	}
+	var value string
+	err := os.db.QueryRow("SELECT pref_value FROM user_preferences WHERE username = ? AND pref_key = ?", username, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
+
+// setUserPreference stores a value in user_preferences
+func (os *TinyOS) setUserPreference(username, key, value string) error {
+	if os.db == nil {
+		return fmt.Errorf("database not available")
+	}
+	_, err := os.db.Exec("INSERT OR REPLACE INTO user_preferences (username, pref_key, pref_value, updated_at) VALUES (?, ?, ?, ?)",
+		username, key, value, time.Now().Unix())
+	return err
+}
+
+// cmdTheme shows the available themes or switches the terminal theme
+func (os *TinyOS) cmdTheme(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	isGuest := os.isGuestSession(sessionID)
+
+	if len(cleanArgs) == 0 || strings.EqualFold(cleanArgs[0], "list") {
+		current := os.ThemeMessageForSession(sessionID).Content
+		lines := []string{"Available themes:"}
+		for _, theme := range builtinThemes {
+			marker := "  "
+			if theme.Name == current {
+				marker = "* "
+			}
+			lines = append(lines, fmt.Sprintf("%s%-8s %s", marker, theme.Name, theme.Description))
+		}
+		lines = append(lines, "Usage: theme <name>")
+		return os.CreateWrappedTextMessage(sessionID, strings.Join(lines, "\n"))
+	}
+
+	if len(cleanArgs) > 1 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: theme [list | <name>]")
+	}
+	theme, ok := findTheme(cleanArgs[0])
+	if !ok {
+		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("theme: unknown theme '%s'. Type 'theme' for a list.", cleanArgs[0]))
+	}
+
+	// Gäste können das Theme nur für die laufende Verbindung wechseln
+	if isGuest {
+		messages := []shared.Message{theme.Message()}
+		return append(messages, os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("Theme set to %s for this connection. Log in to keep it.", theme.Name))...)
+	}
+
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+	if err := os.setUserPreference(username, themePreferenceKey, theme.Name); err != nil {
+		logger.Error(logger.AreaDatabase, "Failed to save theme for %s: %v", username, err)
+		return os.CreateWrappedTextMessage(sessionID, "theme: could not save your preference.")
+	}
+
+	messages := []shared.Message{theme.Message()}
+	return append(messages, os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("Theme set to %s.", theme.Name))...)
+}
@@ -0,0 +1,295 @@
+package tinyos
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/editor"
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// Limits for "top -d <seconds> -n <frames>"
+const (
+	defaultTopDelay  = 2 * time.Second
+	defaultTopFrames = 10
+	maxTopDelay      = 60 * time.Second
+	maxTopFrames     = 60
+)
+
+// topRow is one session line of the top view
+type topRow struct {
+	sessionID string
+	username  string
+	mode      string
+	idle      time.Duration
+	messages  int64 // Messages in the current rate limit minute
+	bandwidth int64 // Bytes in the current rate limit minute
+	state     int64 // Estimated bytes of state held for the session
+	activity  string
+}
+
+// inputModeName returns the short mode name shown by top
+func inputModeName(mode InputMode) string {
+	switch mode {
+	case InputModeOSShell:
+		return "shell"
+	case InputModeEditor:
+		return "editor"
+	case InputModeChess:
+		return "chess"
+	case InputModeTelnet:
+		return "telnet"
+	case InputModePager:
+		return "pager"
+	case InputModeLoginProcess:
+		return "login"
+	case InputModeRegistrationProcess:
+		return "register"
+	case InputModePasswordChange:
+		return "passwd"
+	case InputModeBasicInterpreter:
+		return "basic"
+	case InputModeBoard:
+		return "board"
+	default:
+		return strconv.Itoa(int(mode))
+	}
+}
+
+// cmdTop shows per-session resource usage and system totals (admins only).
+// "top -d <seconds> -n <frames>" redraws the view until a new command is entered.
+func (os *TinyOS) cmdTop(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if !os.isAdminSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "top: permission denied (admin only).")
+	}
+	if msgs := os.requireElevation(sessionID, "top"); msgs != nil {
+		return msgs
+	}
+
+	delay, frames, err := parseTopArgs(cleanArgs)
+	if err != nil {
+		return os.CreateWrappedTextMessage(sessionID, err.Error())
+	}
+
+	messages := []shared.Message{{Type: shared.MessageTypeClear}}
+	messages = append(messages, os.CreateWrappedTextMessage(sessionID, os.renderTop())...)
+	if frames > 1 {
+		os.startTopRefresh(sessionID, delay, frames)
+	}
+	return messages
+}
+
+// parseTopArgs reads the -d and -n options of top
+func parseTopArgs(args []string) (time.Duration, int, error) {
+	usage := fmt.Errorf("Usage: top [-d seconds] [-n frames]")
+	delay, frames := defaultTopDelay, 1
+	for i := 0; i < len(args); i++ {
+		if i+1 >= len(args) {
+			return 0, 0, usage
+		}
+		value, err := strconv.Atoi(args[i+1])
+		if err != nil || value < 1 {
+			return 0, 0, usage
+		}
+		switch args[i] {
+		case "-d":
+			delay = time.Duration(value) * time.Second
+			if delay > maxTopDelay {
+				delay = maxTopDelay
+			}
+			if frames == 1 {
+				frames = defaultTopFrames
+			}
+		case "-n":
+			frames = value
+			if frames > maxTopFrames {
+				frames = maxTopFrames
+			}
+		default:
+			return 0, 0, usage
+		}
+		i++
+	}
+	return delay, frames, nil
+}
+
+// startTopRefresh redraws the top view in the background, replacing a
+// refresh that is already running for the session
+func (os *TinyOS) startTopRefresh(sessionID string, delay time.Duration, frames int) {
+	if os.SendToClientCallback == nil {
+		return
+	}
+	stop := make(chan struct{})
+	os.topMutex.Lock()
+	if previous, running := os.topRefresh[sessionID]; running {
+		close(previous)
+	}
+	os.topRefresh[sessionID] = stop
+	os.topMutex.Unlock()
+
+	go func() {
+		defer func() {
+			os.topMutex.Lock()
+			if os.topRefresh[sessionID] == stop {
+				delete(os.topRefresh, sessionID)
+			}
+			os.topMutex.Unlock()
+		}()
+
+		ticker := time.NewTicker(delay)
+		defer ticker.Stop()
+		for frame := 1; frame < frames; frame++ {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			if os.GetInputMode(sessionID) != InputModeOSShell || !os.isElevatedSession(sessionID) {
+				return
+			}
+
+			messages := []shared.Message{{Type: shared.MessageTypeClear}}
+			messages = append(messages, os.CreateWrappedTextMessage(sessionID, os.renderTop())...)
+			messages = append(messages, shared.Message{Type: shared.MessageTypePrompt, PromptSymbol: os.GetPromptForSession(sessionID)})
+			for _, msg := range messages {
+				if err := os.SendToClientCallback(sessionID, msg); err != nil {
+					logger.Debug(logger.AreaResources, "Stopping top refresh for session %s: %v", sessionID, err)
+					return
+				}
+			}
+		}
+	}()
+}
+
+// stopTopRefresh ends a running top refresh of the session
+func (os *TinyOS) stopTopRefresh(sessionID string) {
+	os.topMutex.Lock()
+	defer os.topMutex.Unlock()
+	if stop, running := os.topRefresh[sessionID]; running {
+		close(stop)
+		delete(os.topRefresh, sessionID)
+	}
+}
+
+// renderTop builds the top view from the counters of the resource managers
+// and the session table; nothing is profiled
+func (os *TinyOS) renderTop() string {
+	rows := os.collectTopRows()
+	systemStats := os.SystemResourceManager.GetSystemStats()
+	basicRuns := len(os.ResourceManager.GetAllBasicExecutions())
+
+	modes := make(map[string]int)
+	users := make(map[string]bool)
+	for _, row := range rows {
+		modes[row.mode]++
+		users[row.username] = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("top - %s  sessions %d  users %d  basic %d  telnet %d  editor %d\n",
+		time.Now().Format("15:04:05"), len(rows), len(users), modes["basic"], modes["telnet"], modes["editor"]))
+	sb.WriteString(fmt.Sprintf("RAM %v/%v MB (reserved %v)  goroutines %v  BASIC runs %d\n\n",
+		systemStats["current_ram_mb"], systemStats["total_ram_mb"], systemStats["reserved_ram_mb"],
+		systemStats["total_goroutines"], basicRuns))
+	sb.WriteString(fmt.Sprintf("%-8s %-12s %-8s %6s %6s %6s %7s  %s\n",
+		"SESSION", "USER", "MODE", "IDLE", "MSG/M", "KB/M", "STATEKB", "ACTIVITY"))
+	for _, row := range rows {
+		sb.WriteString(fmt.Sprintf("%-8s %-12s %-8s %6s %6d %6.1f %7.1f  %s\n",
+			shortSessionID(row.sessionID), truncateField(row.username, 12), row.mode,
+			formatIdle(row.idle), row.messages, float64(row.bandwidth)/1024, float64(row.state)/1024, row.activity))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// collectTopRows gathers one row per session, busiest sessions first
+func (os *TinyOS) collectTopRows() []topRow {
+	counters := os.ResourceManager.GetSessionSnapshots()
+	basicRuns := os.ResourceManager.GetAllBasicExecutions()
+	editorManager := editor.GetEditorManager()
+
+	os.sessionMutex.RLock()
+	rows := make([]topRow, 0, len(os.sessions))
+	for id, session := range os.sessions {
+		row := topRow{
+			sessionID: id,
+			username:  session.Username,
+			mode:      inputModeName(session.InputMode),
+			idle:      time.Since(session.LastActivity),
+		}
+		if row.username == "" {
+			row.username = "guest"
+		}
+		for _, msg := range session.ChatHistory {
+			row.state += int64(len(msg.Content))
+		}
+		rows = append(rows, row)
+	}
+	os.sessionMutex.RUnlock()
+
+	for i := range rows {
+		row := &rows[i]
+		if counter, ok := counters[row.sessionID]; ok {
+			row.messages = counter.MessageCount
+			row.bandwidth = counter.BandwidthUsed
+		}
+		if os.IsBasicSessionActive(row.sessionID) {
+			row.mode = "basic"
+		}
+		if run, ok := basicRuns[row.username]; ok {
+			row.state += run.MemoryUsage
+			row.activity = fmt.Sprintf("%s %s", run.ProgramName, time.Since(run.StartTime).Round(time.Second))
+		}
+		if editorManager.GetEditor(row.sessionID) != nil && row.activity == "" {
+			row.activity = "editing"
+		}
+		os.telnetMutex.RLock()
+		if telnet, ok := os.telnetStates[row.sessionID]; ok {
+			row.activity = telnet.ServerHost
+		}
+		os.telnetMutex.RUnlock()
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].messages != rows[j].messages {
+			return rows[i].messages > rows[j].messages
+		}
+		return rows[i].idle < rows[j].idle
+	})
+	return rows
+}
+
+// shortSessionID returns the first eight characters of a session id
+func shortSessionID(sessionID string) string {
+	if len(sessionID) > 8 {
+		return sessionID[:8]
+	}
+	return sessionID
+}
+
+// truncateField shortens a value to fit a table column
+func truncateField(value string, width int) string {
+	if len(value) > width {
+		return value[:width-1] + "~"
+	}
+	return value
+}
+
+// formatIdle renders an idle time compactly (45s, 12m, 3h)
+func formatIdle(idle time.Duration) string {
+	switch {
+	case idle < time.Minute:
+		return fmt.Sprintf("%ds", int(idle.Seconds()))
+	case idle < time.Hour:
+		return fmt.Sprintf("%dm", int(idle.Minutes()))
+	default:
+		return fmt.Sprintf("%dh", int(idle.Hours()))
+	}
+}
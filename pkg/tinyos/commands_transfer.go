@@ -0,0 +1,224 @@
+package tinyos
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// transferCodeAlphabet leaves out characters that are easy to mix up when
+// the code is typed from another screen (0/O, 1/I/L)
+const transferCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// transferCodeLength is the number of code characters, shown as two groups
+const transferCodeLength = 8
+
+// transferCode is an open request to move a session to another device
+type transferCode struct {
+	sessionID string
+	username  string
+	expires   time.Time
+}
+
+// newTransferCode returns a random code like "K7QX-M2PA"
+func newTransferCode() (string, error) {
+	b := make([]byte, transferCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := make([]byte, 0, transferCodeLength+1)
+	for i, v := range b {
+		if i == transferCodeLength/2 {
+			code = append(code, '-')
+		}
+		code = append(code, transferCodeAlphabet[int(v)%len(transferCodeAlphabet)])
+	}
+	return string(code), nil
+}
+
+// normalizeTransferCode accepts codes typed in lower case or without the dash
+func normalizeTransferCode(code string) string {
+	code = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	if len(code) != transferCodeLength {
+		return ""
+	}
+	return code[:transferCodeLength/2] + "-" + code[transferCodeLength/2:]
+}
+
+// cmdTransfer moves a logged-in session to another device. Without an
+// argument it creates a single-use code for the current session; with a
+// code, entered in a guest session on the other device, it logs that device
+// in as the same user and ends the old session.
+func (os *TinyOS) cmdTransfer(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	switch len(cleanArgs) {
+	case 0:
+		return os.createTransferCode(sessionID)
+	case 1:
+		return os.redeemTransferCode(sessionID, cleanArgs[0])
+	default:
+		return os.CreateWrappedTextMessage(sessionID, "Usage: transfer [code]")
+	}
+}
+
+// createTransferCode hands out a code for the session. A new code replaces
+// the previous one of the same session.
+func (os *TinyOS) createTransferCode(sessionID string) []shared.Message {
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" || os.isGuestSession(sessionID) || isTemporaryUser(username) {
+		return os.CreateWrappedTextMessage(sessionID, "transfer: please log in first. Guest sessions cannot be transferred.")
+	}
+	code, err := newTransferCode()
+	if err != nil {
+		logger.Error(logger.AreaAuth, "Failed to create transfer code for session %s: %v", shortSessionID(sessionID), err)
+		return os.CreateWrappedTextMessage(sessionID, "transfer: could not create a code, please try again.")
+	}
+	ttl := configuration.GetDuration("Authentication", "transfer_code_ttl", 2*time.Minute)
+
+	os.transferMutex.Lock()
+	now := time.Now()
+	for c, t := range os.transferCodes {
+		if t.sessionID == sessionID || now.After(t.expires) {
+			delete(os.transferCodes, c)
+		}
+	}
+	os.transferCodes[code] = &transferCode{sessionID: sessionID, username: username, expires: now.Add(ttl)}
+	os.transferMutex.Unlock()
+
+	logger.SecurityInfo("User %s created a transfer code for session %s", username, shortSessionID(sessionID))
+	return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf(
+		"Transfer code: %s\nOn the other device, enter: transfer %s\nThe code works once within %s. This session is logged out when it is used.",
+		code, code, ttl))
+}
+
+// redeemTransferCode logs the guest session in as the owner of the code and
+// revokes the session the code was created in. Wrong codes count as failed
+// logins of the IP, so codes cannot be guessed faster than passwords.
+func (os *TinyOS) redeemTransferCode(sessionID, input string) []shared.Message {
+	if !os.isGuestSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "transfer: you are already logged in. Log out first to use a code.")
+	}
+	ipAddress := "127.0.0.1"
+	os.sessionMutex.RLock()
+	if session, exists := os.sessions[sessionID]; exists {
+		ipAddress = session.IPAddress
+	}
+	os.sessionMutex.RUnlock()
+
+	if blocked, remaining := os.isLoginBlocked(ipAddress); blocked {
+		logger.SecurityWarn("Transfer attempt blocked for IP %s. %d seconds remaining", ipAddress, remaining)
+		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("transfer: too many failed attempts. Try again in %d seconds.", remaining))
+	}
+
+	code := normalizeTransferCode(input)
+	os.transferMutex.Lock()
+	pending, exists := os.transferCodes[code]
+	delete(os.transferCodes, code) // Single use, even if the checks below fail
+	os.transferMutex.Unlock()
+
+	if !exists || time.Now().After(pending.expires) {
+		os.recordFailedLoginAttempt(ipAddress)
+		logger.SecurityWarn("Invalid or expired transfer code from IP %s (session %s)", ipAddress, shortSessionID(sessionID))
+		return os.CreateWrappedTextMessage(sessionID, "transfer: invalid or expired code.")
+	}
+	// The old session may have logged out since the code was created
+	if os.GetUsernameForSession(pending.sessionID) != pending.username {
+		logger.SecurityWarn("Transfer code of user %s used after session %s ended", pending.username, shortSessionID(pending.sessionID))
+		return os.CreateWrappedTextMessage(sessionID, "transfer: the session of this code has ended. Please log in.")
+	}
+	if banned, banMessage := os.IsBanned(pending.username, ipAddress); banned {
+		logger.SecurityWarn("Transfer of user %s to banned IP %s refused", pending.username, ipAddress)
+		return os.CreateWrappedTextMessage(sessionID, "transfer: "+banMessage)
+	}
+	if os.db == nil {
+		return os.CreateWrappedTextMessage(sessionID, "transfer: no database connection.")
+	}
+	var userID int64
+	if err := os.db.QueryRow("SELECT rowid FROM users WHERE username = ?", pending.username).Scan(&userID); err != nil {
+		logger.Error(logger.AreaAuth, "Transfer of user %s failed: %v", pending.username, err)
+		return os.CreateWrappedTextMessage(sessionID, "transfer: the account no longer exists.")
+	}
+
+	os.revokeSession(pending.sessionID, "This session of "+pending.username+" was moved to another device.")
+	messages, newSessionID := os.startUserSession(pending.username, userID, ipAddress, "Session transferred.")
+	logger.SecurityInfo("Session of user %s transferred from %s to %s (IP %s)",
+		pending.username, shortSessionID(pending.sessionID), shortSessionID(newSessionID), ipAddress)
+
+	return append(messages, shared.Message{
+		Type:      shared.MessageTypeSession,
+		SessionID: newSessionID,
+	})
+}
+
+// revokeSession ends a session from elsewhere, e.g. the old session of a
+// transfer. Its token is recorded in revoked_sessions so it cannot restore the
+// session later. If the session is connected, its tab is told why and
+// continues as a guest like after logout.
+func (os *TinyOS) revokeSession(sessionID, notice string) {
+	ipAddress := "127.0.0.1"
+	os.sessionMutex.Lock()
+	session, active := os.sessions[sessionID]
+	if active {
+		ipAddress = session.IPAddress
+	}
+	delete(os.sessions, sessionID)
+	os.sessionMutex.Unlock()
+
+	if os.db != nil {
+		now := time.Now()
+		if _, err := os.db.Exec("INSERT OR REPLACE INTO revoked_sessions (session_id, revoked_at) VALUES (?, ?)", sessionID, now.Unix()); err != nil {
+			logger.Error(logger.AreaAuth, "Failed to revoke session %s: %v", shortSessionID(sessionID), err)
+		}
+		// Tokens of older revocations have expired by now
+		maxAge := time.Duration(configuration.GetInt("JWT", "token_expiration_hours", 24)) * time.Hour
+		if _, err := os.db.Exec("DELETE FROM revoked_sessions WHERE revoked_at < ?", now.Add(-maxAge).Unix()); err != nil {
+			logger.Warn(logger.AreaAuth, "Failed to prune revoked sessions: %v", err)
+		}
+		if _, err := os.db.Exec("DELETE FROM user_sessions WHERE session_id = ?", sessionID); err != nil {
+			logger.Warn(logger.AreaAuth, "Failed to delete revoked session %s: %v", shortSessionID(sessionID), err)
+		}
+	}
+	os.forgetSFXVolume(sessionID)
+	os.forgetPrompt(sessionID)
+	if !active {
+		return // No tab to notify
+	}
+
+	if _, err := os.CreateGuestSession(sessionID, ipAddress); err != nil {
+		logger.Warn(logger.AreaAuth, "No guest session after revoking %s: %v", shortSessionID(sessionID), err)
+	}
+	if os.SendToClientCallback != nil {
+		for _, msg := range []shared.Message{
+			{Type: shared.MessageTypeText, Content: notice},
+			{Type: shared.MessageTypeText, Content: "You can continue to use the terminal as a guest."},
+			{Type: shared.MessageTypeSession, Content: sessionID},
+			defaultTheme().Message(),
+		} {
+			if err := os.SendToClientCallback(sessionID, msg); err != nil {
+				break // Tab already closed
+			}
+		}
+	}
+}
+
+// isSessionRevoked reports whether the session was moved to another device
+func (os *TinyOS) isSessionRevoked(sessionID string) bool {
+	if os.db == nil {
+		return false
+	}
+	var revokedAt int64
+	err := os.db.QueryRow("SELECT revoked_at FROM revoked_sessions WHERE session_id = ?", sessionID).Scan(&revokedAt)
+	if err != nil && err != sql.ErrNoRows {
+		logger.Warn(logger.AreaAuth, "Failed to check revoked session %s: %v", shortSessionID(sessionID), err)
+	}
+	return err == nil
+}
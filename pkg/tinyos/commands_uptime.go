@@ -0,0 +1,71 @@
+package tinyos
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// SetStartTime records when the server was started. main calls it once
+// before serving; until then the creation of TinyOS counts as the start.
+func (os *TinyOS) SetStartTime(start time.Time) {
+	os.startTime = start
+}
+
+// StartTime returns when the server was started
+func (os *TinyOS) StartTime() time.Time {
+	return os.startTime
+}
+
+// Uptime returns how long the server has been running
+func (os *TinyOS) Uptime() time.Duration {
+	return time.Since(os.startTime)
+}
+
+// cmdUptime shows since when the server is running. Admins also see how
+// many sessions are open.
+func (os *TinyOS) cmdUptime(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if len(cleanArgs) > 0 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: uptime")
+	}
+
+	text := fmt.Sprintf("up %s, since %s", formatUptime(os.Uptime()), os.startTime.Format("2006-01-02 15:04:05 MST"))
+	if os.isAdminSession(sessionID) {
+		metrics := os.CollectMetrics()
+		text += fmt.Sprintf("\n%d sessions (%d BASIC, %d telnet)", metrics.Sessions, metrics.BasicSessions, metrics.TelnetSessions)
+	}
+	return os.CreateWrappedTextMessage(sessionID, text)
+}
+
+// formatUptime renders a duration in words, down to minutes once it is
+// longer than a minute (3 days, 4 hours, 5 minutes)
+func formatUptime(d time.Duration) string {
+	if d < time.Minute {
+		return pluralUnit(int(d.Seconds()), "second")
+	}
+	d = d.Truncate(time.Minute)
+	days, hours, minutes := int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour), int(d%time.Hour/time.Minute)
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, pluralUnit(days, "day"))
+	}
+	if hours > 0 {
+		parts = append(parts, pluralUnit(hours, "hour"))
+	}
+	if minutes > 0 {
+		parts = append(parts, pluralUnit(minutes, "minute"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// pluralUnit returns "1 day" or "2 days"
+func pluralUnit(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,145 @@
+package tinyos
+
+import (
+	"fmt"
+	gos "os"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// vacuumCheckInterval is how often the scheduled vacuum looks whether it is due
+const vacuumCheckInterval = time.Hour
+
+// cmdVacuum compacts the database with SQLite's VACUUM (admins only). The
+// vacuum runs in the background; its result is sent to the session when done.
+func (os *TinyOS) cmdVacuum(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if !os.isAdminSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "vacuum: permission denied (admin only).")
+	}
+	if len(cleanArgs) > 0 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: vacuum")
+	}
+	if msgs := os.requireElevation(sessionID, "vacuum"); msgs != nil {
+		return msgs
+	}
+	if os.db == nil {
+		return os.CreateWrappedTextMessage(sessionID, "vacuum: database not available.")
+	}
+	if !os.vacuumMutex.TryLock() {
+		return os.CreateWrappedTextMessage(sessionID, "vacuum: a vacuum is already running.")
+	}
+
+	free, err := os.databaseFreePercent()
+	if err != nil {
+		os.vacuumMutex.Unlock()
+		logger.Error(logger.AreaDatabase, "Failed to read the free pages of the database: %v", err)
+		return os.CreateWrappedTextMessage(sessionID, "vacuum: could not read the database.")
+	}
+
+	go func() {
+		defer os.vacuumMutex.Unlock()
+		text := "Vacuum failed, see the log for details."
+		if before, after, took, err := os.vacuumDatabaseWithoutLock("vacuum by " + os.GetUsernameForSession(sessionID)); err == nil {
+			text = fmt.Sprintf("Vacuum done in %v: %s -> %s, %s reclaimed.",
+				took.Round(time.Millisecond), formatDiskUsage(int(before)), formatDiskUsage(int(after)), formatDiskUsage(int(max(before-after, 0))))
+		}
+		if os.SendToClientCallback == nil {
+			return
+		}
+		if err := os.SendToClientCallback(sessionID, shared.Message{Type: shared.MessageTypeText, Content: text}); err != nil {
+			logger.Debug(logger.AreaDatabase, "Could not send the vacuum result to session %s: %v", shortSessionID(sessionID), err)
+		}
+	}()
+
+	return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("Vacuuming the database (%.0f%% free space). The result follows when it is done.", free))
+}
+
+// databaseSize returns the size of the database file in bytes
+func databaseSize() (int64, error) {
+	info, err := gos.Stat(databaseFile)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// databaseFreePercent returns how much of the database consists of free
+// pages, the space a vacuum can give back
+func (os *TinyOS) databaseFreePercent() (float64, error) {
+	var pages, free int
+	if err := os.db.QueryRow("PRAGMA page_count").Scan(&pages); err != nil {
+		return 0, err
+	}
+	if err := os.db.QueryRow("PRAGMA freelist_count").Scan(&free); err != nil {
+		return 0, err
+	}
+	if pages == 0 {
+		return 0, nil
+	}
+	return float64(free) * 100 / float64(pages), nil
+}
+
+// vacuumDatabaseWithoutLock runs VACUUM and logs the file size before and
+// after. Other connections wait for the rewrite through their busy timeout
+// instead of failing. Assumes vacuumMutex is held.
+func (os *TinyOS) vacuumDatabaseWithoutLock(reason string) (int64, int64, time.Duration, error) {
+	before, err := databaseSize()
+	if err != nil {
+		logger.Error(logger.AreaDatabase, "VACUUM (%s): cannot read the database size: %v", reason, err)
+		return 0, 0, 0, err
+	}
+	start := time.Now()
+	if _, err := os.db.Exec("VACUUM"); err != nil {
+		logger.Error(logger.AreaDatabase, "VACUUM (%s) failed: %v", reason, err)
+		return 0, 0, 0, err
+	}
+	took := time.Since(start)
+	after, err := databaseSize()
+	if err != nil {
+		logger.Error(logger.AreaDatabase, "VACUUM (%s): cannot read the database size: %v", reason, err)
+		return 0, 0, 0, err
+	}
+	logger.Info(logger.AreaDatabase, "VACUUM (%s): %d -> %d bytes in %v", reason, before, after, took)
+	return before, after, took, nil
+}
+
+// runScheduledVacuum vacuums the database every vacuum_interval of the
+// [Database] section, 0 turns it off. The settings are read at every check,
+// so a reloaded configuration applies without a restart. A run is skipped
+// while less than vacuum_min_free_percent of the file is free, it would
+// lock the database for nothing.
+func (os *TinyOS) runScheduledVacuum() {
+	ticker := time.NewTicker(vacuumCheckInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for range ticker.C {
+		interval := configuration.GetDuration("Database", "vacuum_interval", 0)
+		if interval <= 0 || time.Since(last) < interval || os.db == nil {
+			continue
+		}
+		last = time.Now()
+
+		free, err := os.databaseFreePercent()
+		if err != nil {
+			logger.Error(logger.AreaDatabase, "Scheduled vacuum: cannot read the free pages: %v", err)
+			continue
+		}
+		if minFree := configuration.GetFloat("Database", "vacuum_min_free_percent", 10); free < minFree {
+			logger.Debug(logger.AreaDatabase, "Scheduled vacuum skipped, only %.1f%% free space", free)
+			continue
+		}
+		if !os.vacuumMutex.TryLock() {
+			continue // An admin is vacuuming right now
+		}
+		os.vacuumDatabaseWithoutLock("scheduled")
+		os.vacuumMutex.Unlock()
+	}
+}
@@ -0,0 +1,143 @@
+package tinyos
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// bioPreferenceKey is the user_preferences key holding the public bio
+const bioPreferenceKey = "bio"
+
+// maxBioLength is the maximum length of a bio in characters
+const maxBioLength = 160
+
+// cmdWhois shows the public profile of a registered user. Admins also see
+// the IP address of the last login.
+func (os *TinyOS) cmdWhois(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if len(cleanArgs) != 1 {
+		return os.CreateWrappedTextMessage(sessionID, "Usage: whois <username>")
+	}
+	if os.db == nil {
+		return os.CreateWrappedTextMessage(sessionID, "whois: user database not available.")
+	}
+
+	var username string
+	var createdAt int64
+	var ip sql.NullString
+	err := os.db.QueryRow("SELECT username, created_at, ip_address FROM users WHERE username = ? COLLATE NOCASE", cleanArgs[0]).
+		Scan(&username, &createdAt, &ip)
+	if err == sql.ErrNoRows {
+		return os.CreateWrappedTextMessage(sessionID, "whois: no such user "+cleanArgs[0])
+	}
+	if err != nil {
+		logger.Error(logger.AreaDatabase, "whois lookup of %s failed: %v", cleanArgs[0], err)
+		return os.CreateWrappedTextMessage(sessionID, "whois: could not look up the user.")
+	}
+
+	// Hidden users and the last IP are only shown in admin mode
+	isAdmin := os.isElevatedSession(sessionID)
+	status := "offline"
+	if os.isUserOnline(username, isAdmin) {
+		status = "online"
+	}
+	bio, _ := os.getUserPreference(username, bioPreferenceKey)
+	if bio == "" {
+		bio = "-"
+	}
+
+	lines := []string{
+		"User:       " + username,
+		"Registered: " + time.Unix(createdAt, 0).Format("2006-01-02"),
+		"Status:     " + status,
+		"Bio:        " + bio,
+	}
+	if isAdmin && ip.Valid && ip.String != "" {
+		lines = append(lines, "Last IP:    "+ip.String)
+	}
+	return os.CreateWrappedTextMessage(sessionID, strings.Join(lines, "\n"))
+}
+
+// isUserOnline reports whether username has an open session. Users hidden
+// from the board's who list are shown as offline, except to admins.
+func (os *TinyOS) isUserOnline(username string, isAdmin bool) bool {
+	os.sessionMutex.RLock()
+	var sessionIDs []string
+	for id, session := range os.sessions {
+		if session.Username == username {
+			sessionIDs = append(sessionIDs, id)
+		}
+	}
+	os.sessionMutex.RUnlock()
+
+	if len(sessionIDs) == 0 || isAdmin {
+		return len(sessionIDs) > 0
+	}
+	os.mu.Lock()
+	defer os.mu.Unlock()
+	for _, id := range sessionIDs {
+		if boardSession, ok := os.boardSessions[id]; ok && boardSession.Active && boardSession.Hidden {
+			return false
+		}
+	}
+	return true
+}
+
+// cmdSetBio shows, changes or clears the bio of the logged-in user
+func (os *TinyOS) cmdSetBio(args []string) []shared.Message {
+	sessionID, cleanArgs := os.ExtractSessionID(args)
+	if sessionID == "" {
+		return os.CreateWrappedTextMessage("", "No session id found.")
+	}
+	if os.isGuestSession(sessionID) {
+		return os.CreateWrappedTextMessage(sessionID, "setbio: please log in to set a bio.")
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+
+	if len(cleanArgs) == 0 {
+		bio, _ := os.getUserPreference(username, bioPreferenceKey)
+		if bio == "" {
+			return os.CreateWrappedTextMessage(sessionID, "You have no bio. Usage: setbio <text> | clear")
+		}
+		return os.CreateWrappedTextMessage(sessionID, "bio = "+bio)
+	}
+
+	bio := sanitizeBio(strings.Join(cleanArgs, " "))
+	if len(cleanArgs) == 1 && strings.EqualFold(cleanArgs[0], "clear") {
+		bio = ""
+	}
+	if n := len([]rune(bio)); n > maxBioLength {
+		return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("setbio: bio is %d characters long, at most %d are allowed.", n, maxBioLength))
+	}
+	if err := os.setUserPreference(username, bioPreferenceKey, bio); err != nil {
+		logger.Error(logger.AreaDatabase, "Failed to save bio for %s: %v", username, err)
+		return os.CreateWrappedTextMessage(sessionID, "setbio: could not save your bio.")
+	}
+	if bio == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Bio cleared.")
+	}
+	return os.CreateWrappedTextMessage(sessionID, "Bio updated.")
+}
+
+// sanitizeBio drops control characters so a bio cannot move the cursor or
+// change colors on other terminals
+func sanitizeBio(text string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, text))
+}
@@ -18,9 +18,16 @@ type Database struct {
 	conn *sql.DB
 }
 
+// databaseFile is the SQLite database of TinyOS
+const databaseFile = "tinyos.db"
+
+// databaseOptions make a connection wait up to five seconds for a lock
+// instead of failing at once, e.g. while vacuum rewrites the file
+const databaseOptions = "?_pragma=busy_timeout(5000)"
+
 // InitDB initializes the SQLite database connection and returns the connection object.
 func InitDB(dbPath string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := sql.Open("sqlite", dbPath+databaseOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -45,7 +52,9 @@ func CreateTables(db *sql.DB) error {
 			is_active INTEGER DEFAULT 1,
 			is_logged_in INTEGER DEFAULT 0,
 			created_at INTEGER NOT NULL,
-			ip_address TEXT
+			ip_address TEXT,
+			recovery_question TEXT NOT NULL DEFAULT '',
+			recovery_answer TEXT NOT NULL DEFAULT ''
 		)`,
 		`CREATE TABLE IF NOT EXISTS banned_users (
 			identifier TEXT PRIMARY KEY,
@@ -68,12 +77,64 @@ func CreateTables(db *sql.DB) error {
 			content BLOB,
 			is_dir INTEGER DEFAULT 0,
 			mod_time INTEGER NOT NULL,
+			link_target TEXT NOT NULL DEFAULT '',
+			read_only INTEGER NOT NULL DEFAULT 0,
 			PRIMARY KEY (username, path)
 		)`,
 		`CREATE TABLE IF NOT EXISTS env_vars (
 			name TEXT PRIMARY KEY,
 			value TEXT NOT NULL
 		)`,
+		`CREATE TABLE IF NOT EXISTS user_mail (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			sender TEXT NOT NULL,
+			recipient TEXT NOT NULL,
+			subject TEXT NOT NULL,
+			body TEXT NOT NULL,
+			sent_at INTEGER NOT NULL,
+			is_read INTEGER DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_user_mail_recipient ON user_mail(recipient)`,
+		`CREATE TABLE IF NOT EXISTS mail_send_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			sender TEXT NOT NULL,
+			sent_at INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_mail_send_log_sender ON mail_send_log(sender, sent_at)`,
+		`CREATE TABLE IF NOT EXISTS news (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			author TEXT NOT NULL,
+			title TEXT NOT NULL,
+			body TEXT NOT NULL,
+			posted_at INTEGER NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS login_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			username TEXT NOT NULL,
+			ip_address TEXT NOT NULL,
+			login_at INTEGER NOT NULL,
+			success INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_login_history_username ON login_history(username)`,
+		`CREATE TABLE IF NOT EXISTS revoked_sessions (
+			session_id TEXT PRIMARY KEY,
+			revoked_at INTEGER NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS user_preferences (
+			username TEXT NOT NULL,
+			pref_key TEXT NOT NULL,
+			pref_value TEXT NOT NULL,
+			updated_at INTEGER NOT NULL,
+			PRIMARY KEY (username, pref_key)
+		)`,
+		`CREATE TABLE IF NOT EXISTS basic_vars (
+			username TEXT NOT NULL,
+			var_key TEXT NOT NULL,
+			var_type TEXT NOT NULL,
+			var_value TEXT NOT NULL,
+			updated_at INTEGER NOT NULL,
+			PRIMARY KEY (username, var_key)
+		)`,
 	}
 
 	for _, query := range queries {
@@ -315,7 +376,7 @@ func CreateDefaultUsers(db *sql.DB) error {
 		_, err = db.Exec(`
 			INSERT INTO users (username, password, last_login, login_attempts, is_admin, is_active, is_logged_in, created_at)
 			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-		`, "dyson", string(hashedPassword), 0, 0, 1, 1, 0, time.Now().Unix())
+		`, "dyson", string(hashedPassword), 0, 0, 0, 1, 0, time.Now().Unix())
 
 		if err != nil {
 			return fmt.Errorf("failed to create dyson user: %w", err)
@@ -324,5 +385,11 @@ func CreateDefaultUsers(db *sql.DB) error {
 		log.Printf("[INIT] Created default user: dyson (password: daniel)")
 	}
 
+	// Older databases created dyson as admin. Its password is a public
+	// puzzle, so the flag is cleared on every start.
+	if _, err := db.Exec("UPDATE users SET is_admin = 0 WHERE username = ? AND is_admin != 0", "dyson"); err != nil {
+		return fmt.Errorf("failed to clear admin flag of dyson: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,84 @@
+package tinyos
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// newDBTestOS returns a TinyOS with a fresh database in a temporary
+// directory and one session per entry of users (session ID to username)
+func newDBTestOS(t *testing.T, users map[string]string) *TinyOS {
+	t.Helper()
+	os := &TinyOS{
+		sessions:            make(map[string]*Session),
+		sfxVolumes:          make(map[string]int),
+		lastDiskSFX:         make(map[string]time.Time),
+		recoveryStates:      make(map[string]*RecoveryState),
+		failedLoginAttempts: make(map[string]*LoginAttemptTracker),
+		mailComposeStates:   make(map[string]*MailComposeState),
+		renameStates:        make(map[string]*RenameState),
+		transferCodes:       make(map[string]*transferCode),
+		sudoStates:          make(map[string]*SudoState),
+		elevatedUntil:       make(map[string]time.Time),
+	}
+	os.initDB(filepath.Join(t.TempDir(), "tinyos.db"))
+	if os.db == nil {
+		t.Fatal("could not open the test database")
+	}
+	t.Cleanup(func() { os.db.Close() })
+	for id, username := range users {
+		os.sessions[id] = &Session{ID: id, Username: username, CurrentPath: "/home/" + username, IPAddress: "192.0.2.1"}
+	}
+	return os
+}
+
+// addTestUser inserts a registered user with the given admin flag
+func addTestUser(t *testing.T, os *TinyOS, username string, admin bool) {
+	t.Helper()
+	isAdmin := 0
+	if admin {
+		isAdmin = 1
+	}
+	if _, err := os.db.Exec("INSERT INTO users (username, password, is_admin, created_at) VALUES (?, ?, ?, ?)",
+		username, "x", isAdmin, time.Now().Unix()); err != nil {
+		t.Fatalf("adding user %s: %v", username, err)
+	}
+}
+
+// TestDysonIsNeverAdmin checks that the puzzle account gets no admin rights,
+// neither from an old database that has the flag set nor after the start
+// clears it
+func TestDysonIsNeverAdmin(t *testing.T) {
+	os := newDBTestOS(t, map[string]string{"d": "dyson", "r": "root"})
+	addTestUser(t, os, "dyson", true)
+	addTestUser(t, os, "root", true)
+
+	if os.isAdminSession("d") {
+		t.Error("dyson with is_admin=1 counts as admin")
+	}
+	if !os.isAdminSession("r") {
+		t.Error("a real admin lost the admin rights")
+	}
+
+	if err := CreateDefaultUsers(os.db); err != nil {
+		t.Fatalf("CreateDefaultUsers: %v", err)
+	}
+	var isAdmin int
+	if err := os.db.QueryRow("SELECT is_admin FROM users WHERE username = 'dyson'").Scan(&isAdmin); err != nil || isAdmin != 0 {
+		t.Errorf("is_admin of dyson after start = %d, %v; want 0", isAdmin, err)
+	}
+}
+
+// messageText joins the text of the messages a command returned
+func messageText(messages []shared.Message) string {
+	var sb strings.Builder
+	for _, msg := range messages {
+		sb.WriteString(msg.Content)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -32,15 +32,107 @@ func getDeepSeekAPIKey() string {
 	return configuration.GetString("DeepSeek", "api_key", "")
 }
 
+// deepSeekMessage is one message of a DeepSeek chat completion request
+type deepSeekMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// deepSeekRequest is the body of a DeepSeek chat completion request
+type deepSeekRequest struct {
+	Model    string            `json:"model"`
+	Messages []deepSeekMessage `json:"messages"`
+	Stream   bool              `json:"stream,omitempty"`
+}
+
+// deepSeekURL is the chat completion endpoint of the DeepSeek API
+const deepSeekURL = "https://api.deepseek.com/v1/chat/completions"
+
 // AskDeepSeek sends a request to the DeepSeek AI with chat history and returns the response as []shared.Message
 func (os *TinyOS) AskDeepSeek(query string, sessionID string) []shared.Message {
 	log.Printf("[DeepSeek] Request: %q", query)
 
+	session, messages, apiKey, errMsgs := os.prepareDeepSeekRequest(query, sessionID)
+	if errMsgs != nil {
+		return errMsgs
+	}
+
+	type deepSeekResponse struct {
+		Choices []struct {
+			Message deepSeekMessage `json:"message"`
+		} `json:"choices"`
+	}
+
+	// Build request
+	reqBody := deepSeekRequest{
+		Model:    "deepseek-chat",
+		Messages: messages,
+	}
+	body, _ := json.Marshal(reqBody)
+	client := &http.Client{Timeout: 30 * time.Second}
+	httpReq, _ := http.NewRequest("POST", deepSeekURL, bytes.NewReader(body))
+	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+	log.Printf("[DeepSeek] Request-Body: %s", string(body))
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		log.Printf("[DeepSeek] HTTP error: %v", err)
+		return []shared.Message{{
+			Content: "Error communicating with DeepSeek: " + err.Error(),
+			Type:    shared.MessageTypeText,
+		}}
+	}
+	defer resp.Body.Close()
+	log.Printf("[DeepSeek] HTTP-Status: %d %s", resp.StatusCode, resp.Status)
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	log.Printf("[DeepSeek] HTTP-Body: %s", string(bodyBytes))
+	resp.Body = io.NopCloser(bytes.NewBuffer(bodyBytes)) // Borrow body for decoding
+
+	var dsResp deepSeekResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dsResp); err != nil {
+		log.Printf("[DeepSeek] Response decode error: %v", err)
+		return []shared.Message{{
+			Content: deepSeekLinkError,
+			Type:    shared.MessageTypeText,
+		}}
+	}
+	if len(dsResp.Choices) == 0 {
+		log.Printf("[DeepSeek] Empty response received")
+		return []shared.Message{{
+			Content: deepSeekEmptyError,
+			Type:    shared.MessageTypeText}}
+	}
+	response := dsResp.Choices[0].Message.Content
+	log.Printf("[DeepSeek] Raw response: %q", response)
+	os.addAssistantMessage(session, sessionID, response, false)
+
+	resultMessages, response := os.deepSeekEffects(sessionID, response)
+	if response != "" {
+		log.Printf("[DeepSeek] Final response to user: %q", response)
+		resultMessages = append(resultMessages, shared.Message{Content: response, Type: shared.MessageTypeText})
+	}
+
+	// Log the number of generated messages
+	log.Printf("[DeepSeek] Generated %d messages for user", len(resultMessages))
+
+	return resultMessages
+}
+
+// Error texts shown when DeepSeek sends no usable answer
+const (
+	deepSeekLinkError  = "*System Error* MCP communications link disrupted. The DeepSeek neural network cannot be reached. Please try again later, Program."
+	deepSeekEmptyError = "*System Error* MCP received empty response from neural network. The artificial intelligence core may be temporarily offline."
+)
+
+// prepareDeepSeekRequest builds the messages for a request from the system
+// prompt, the chat history and query, and adds query to the chat history.
+// If no request can be made, errMsgs holds the messages for the user.
+func (os *TinyOS) prepareDeepSeekRequest(query string, sessionID string) (session *Session, messages []deepSeekMessage, apiKey string, errMsgs []shared.Message) {
 	// Extract username from session
 	username := os.GetUsernameForSession(sessionID)
 	if username == "" {
 		log.Printf("[DeepSeek] Error: not logged in")
-		return []shared.Message{{
+		return nil, nil, "", []shared.Message{{
 			Content: "Error: You must be logged in to use the chat.",
 			Type:    shared.MessageTypeText,
 		}}
@@ -52,15 +144,15 @@ func (os *TinyOS) AskDeepSeek(query string, sessionID string) []shared.Message {
 	os.sessionMutex.RUnlock()
 	if !exists {
 		log.Printf("[DeepSeek] Error: session not found")
-		return []shared.Message{{
+		return nil, nil, "", []shared.Message{{
 			Content: "Error: Session not found.",
 			Type:    shared.MessageTypeText}}
 	}
 
 	// Get API key from configuration
-	apiKey := getDeepSeekAPIKey()
+	apiKey = getDeepSeekAPIKey()
 	if apiKey == "" {
-		return []shared.Message{{
+		return nil, nil, "", []shared.Message{{
 			Content: "Error: DeepSeek API key not configured. Please set DEEPSEEK_API_KEY environment variable or configure in settings.cfg",
 			Type:    shared.MessageTypeText,
 		}}
@@ -73,31 +165,14 @@ func (os *TinyOS) AskDeepSeek(query string, sessionID string) []shared.Message {
 	prompt, err := os.PromptManager.GetMCPChatPrompt(templateData)
 	if err != nil {
 		log.Printf("[DeepSeek] Error generating chat prompt: %v", err)
-		return []shared.Message{{
+		return nil, nil, "", []shared.Message{{
 			Content: "Error: Failed to generate system prompt.",
 			Type:    shared.MessageTypeText,
 		}}
 	}
 
-	// Define request/response types
-	type deepSeekRequest struct {
-		Model    string        `json:"model"`
-		Messages []interface{} `json:"messages"`
-	}
-	type deepSeekMessage struct {
-		Role    string `json:"role"`
-		Content string `json:"content"`
-	}
-	type deepSeekResponse struct {
-		Choices []struct {
-			Message deepSeekMessage `json:"message"`
-		} `json:"choices"`
-	}
-
 	// Build messages array with system prompt and chat history
-	messages := []interface{}{
-		deepSeekMessage{Role: "system", Content: prompt},
-	}
+	messages = []deepSeekMessage{{Role: "system", Content: prompt}}
 
 	// Add chat history from session
 	os.sessionMutex.RLock()
@@ -111,8 +186,7 @@ func (os *TinyOS) AskDeepSeek(query string, sessionID string) []shared.Message {
 	os.sessionMutex.RUnlock()
 
 	// Add current user message
-	userMessage := deepSeekMessage{Role: "user", Content: query}
-	messages = append(messages, userMessage)
+	messages = append(messages, deepSeekMessage{Role: "user", Content: query})
 
 	// Log number of messages being sent
 	log.Printf("[DeepSeek] Sending %d messages to API (including system prompt)", len(messages))
@@ -126,59 +200,28 @@ func (os *TinyOS) AskDeepSeek(query string, sessionID string) []shared.Message {
 	})
 	os.sessionMutex.Unlock()
 
-	// Build request
-	reqBody := deepSeekRequest{
-		Model:    "deepseek-chat",
-		Messages: messages,
-	}
-	body, _ := json.Marshal(reqBody)
-	client := &http.Client{Timeout: 30 * time.Second}
-	httpReq, _ := http.NewRequest("POST", "https://api.deepseek.com/v1/chat/completions", bytes.NewReader(body))
-	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
-	httpReq.Header.Set("Content-Type", "application/json")
-	log.Printf("[DeepSeek] Request-Body: %s", string(body))
-	resp, err := client.Do(httpReq)
-	if err != nil {
-		log.Printf("[DeepSeek] HTTP error: %v", err)
-		return []shared.Message{{
-			Content: "Error communicating with DeepSeek: " + err.Error(),
-			Type:    shared.MessageTypeText,
-		}}
-	}
-	defer resp.Body.Close()
-	log.Printf("[DeepSeek] HTTP-Status: %d %s", resp.StatusCode, resp.Status)
-	bodyBytes, _ := io.ReadAll(resp.Body)
-	log.Printf("[DeepSeek] HTTP-Body: %s", string(bodyBytes))
-	resp.Body = io.NopCloser(bytes.NewBuffer(bodyBytes)) // Borrow body for decoding
+	return session, messages, apiKey, nil
+}
 
-	var dsResp deepSeekResponse
-	if err := json.NewDecoder(resp.Body).Decode(&dsResp); err != nil {
-		log.Printf("[DeepSeek] Response decode error: %v", err)
-		return []shared.Message{{
-			Content: "*System Error* MCP communications link disrupted. The DeepSeek neural network cannot be reached. Please try again later, Program.",
-			Type:    shared.MessageTypeText,
-		}}
-	}
-	if len(dsResp.Choices) == 0 {
-		log.Printf("[DeepSeek] Empty response received")
-		return []shared.Message{{
-			Content: "*System Error* MCP received empty response from neural network. The artificial intelligence core may be temporarily offline.",
-			Type:    shared.MessageTypeText}}
-	}
-	response := dsResp.Choices[0].Message.Content
-	log.Printf("[DeepSeek] Raw response: %q", response)
-	// Add assistant message to chat history
+// addAssistantMessage adds an answer of the MCP to the chat history.
+// incomplete marks an answer that was cancelled or cut off.
+func (os *TinyOS) addAssistantMessage(session *Session, sessionID string, content string, incomplete bool) {
 	os.sessionMutex.Lock()
 	session.ChatHistory = append(session.ChatHistory, ChatMessage{
-		Role:    "assistant",
-		Content: response,
-		Time:    time.Now(),
+		Role:       "assistant",
+		Content:    content,
+		Time:       time.Now(),
+		Incomplete: incomplete,
 	})
 	os.sessionMutex.Unlock()
 
 	// Limit chat history to last 20 messages (10 user + 10 assistant)
 	os.limitChatHistory(sessionID, 20)
+}
 
+// deepSeekEffects extracts *beep*, *talk:text* and *evil* from a response
+// and returns the effect messages and the remaining text
+func (os *TinyOS) deepSeekEffects(sessionID string, response string) ([]shared.Message, string) {
 	response = sanitizeDeepSeekResponse(response) // Extract *beep* and *talk:* (case-insensitive)
 	resultMessages := []shared.Message{}
 	// Check for *BEEP* (case-insensitive)
@@ -186,12 +229,10 @@ func (os *TinyOS) AskDeepSeek(query string, sessionID string) []shared.Message {
 	if strings.Contains(lowerResponse, "*beep*") {
 		log.Printf("[DeepSeek] *beep* detected")
 		// Remove both *beep* and *BEEP*
-		beepRegex := regexp.MustCompile(`(?i)\*beep\*`)
 		response = beepRegex.ReplaceAllString(response, "")
 		resultMessages = append(resultMessages, shared.Message{Type: shared.MessageTypeBeep})
 	}
 	// Check for *TALK:text* (case-insensitive)
-	talkRegex := regexp.MustCompile(`(?i)\*talk:(.*?)\*`)
 	matches := talkRegex.FindAllStringSubmatch(response, -1)
 	for _, match := range matches {
 		if len(match) >= 2 {
@@ -205,7 +246,6 @@ func (os *TinyOS) AskDeepSeek(query string, sessionID string) []shared.Message {
 	if strings.Contains(lowerResponse, "*evil*") {
 		log.Printf("[DeepSeek] *evil* detected - MCP is being particularly malicious")
 		// Remove *evil* from response
-		evilRegex := regexp.MustCompile(`(?i)\*evil\*`)
 		response = evilRegex.ReplaceAllString(response, "")
 		resultMessages = append(resultMessages, shared.Message{Type: shared.MessageTypeEvil}) // Also send EVIL message directly to the terminal frontend (not just chat)
 		if os.SendToClientCallback != nil {
@@ -224,18 +264,16 @@ func (os *TinyOS) AskDeepSeek(query string, sessionID string) []shared.Message {
 			log.Printf("[DeepSeek] WARNING: SendToClientCallback is nil, cannot send EVIL message to terminal frontend")
 		}
 	}
-	response = strings.TrimSpace(response)
-	if response != "" {
-		log.Printf("[DeepSeek] Final response to user: %q", response)
-		resultMessages = append(resultMessages, shared.Message{Content: response, Type: shared.MessageTypeText})
-	}
-
-	// Log the number of generated messages
-	log.Printf("[DeepSeek] Generated %d messages for user", len(resultMessages))
-
-	return resultMessages
+	return resultMessages, strings.TrimSpace(response)
 }
 
+// Markers for the effects in a DeepSeek response
+var (
+	beepRegex = regexp.MustCompile(`(?i)\*beep\*`)
+	talkRegex = regexp.MustCompile(`(?i)\*talk:(.*?)\*`)
+	evilRegex = regexp.MustCompile(`(?i)\*evil\*`)
+)
+
 // limitChatHistory limits the chat history to the last N messages
 func (os *TinyOS) limitChatHistory(sessionID string, maxMessages int) {
 	os.sessionMutex.Lock()
@@ -0,0 +1,195 @@
+package tinyos
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// deepSeekTimeoutError is shown when a streamed answer takes longer than
+// stream_timeout
+const deepSeekTimeoutError = "*System Error* MCP response timed out. The answer so far was kept in your chat history."
+
+// StreamDeepSeek asks DeepSeek like AskDeepSeek but streams the answer:
+// onChunk receives the visible text as it arrives, at most once per
+// stream_flush_interval. Cancelling ctx aborts the upstream request and keeps
+// the part received so far in the chat history, marked incomplete. The
+// returned messages are the effects of a complete answer or an error text.
+func (os *TinyOS) StreamDeepSeek(ctx context.Context, query string, sessionID string, onChunk func(string)) []shared.Message {
+	log.Printf("[DeepSeek] Streaming request: %q", query)
+
+	session, messages, apiKey, errMsgs := os.prepareDeepSeekRequest(query, sessionID)
+	if errMsgs != nil {
+		return errMsgs
+	}
+
+	// The time the MCP spends answering counts towards the chat time limits
+	username := os.GetUsernameForSession(sessionID)
+	started := time.Now()
+	defer func() {
+		os.AddChatTime(username, time.Since(started))
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, configuration.GetDuration("DeepSeek", "stream_timeout", 2*time.Minute))
+	defer cancel()
+
+	body, _ := json.Marshal(deepSeekRequest{
+		Model:    "deepseek-chat",
+		Messages: messages,
+		Stream:   true,
+	})
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", deepSeekURL, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("[DeepSeek] Could not build streaming request: %v", err)
+		return []shared.Message{{Content: deepSeekLinkError, Type: shared.MessageTypeText}}
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	stream := &chatStream{
+		interval: configuration.GetDuration("DeepSeek", "stream_flush_interval", 100*time.Millisecond),
+		onChunk:  onChunk,
+	}
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err == nil {
+		defer resp.Body.Close()
+		log.Printf("[DeepSeek] HTTP-Status: %d %s", resp.StatusCode, resp.Status)
+		if resp.StatusCode != http.StatusOK {
+			errBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			log.Printf("[DeepSeek] HTTP-Body: %s", string(errBody))
+			return []shared.Message{{Content: deepSeekLinkError, Type: shared.MessageTypeText}}
+		}
+		err = readDeepSeekStream(resp.Body, stream.add)
+	}
+	stream.flush(true)
+	response := stream.raw.String()
+
+	if err != nil {
+		if response != "" {
+			os.addAssistantMessage(session, sessionID, response, true)
+		}
+		switch {
+		case errors.Is(ctx.Err(), context.Canceled):
+			log.Printf("[DeepSeek] Streaming cancelled after %d bytes", len(response))
+			return nil
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			log.Printf("[DeepSeek] Streaming timed out after %d bytes", len(response))
+			return []shared.Message{{Content: deepSeekTimeoutError, Type: shared.MessageTypeText}}
+		}
+		log.Printf("[DeepSeek] Streaming error: %v", err)
+		return []shared.Message{{Content: deepSeekLinkError, Type: shared.MessageTypeText}}
+	}
+	if response == "" {
+		log.Printf("[DeepSeek] Empty response received")
+		return []shared.Message{{Content: deepSeekEmptyError, Type: shared.MessageTypeText}}
+	}
+
+	log.Printf("[DeepSeek] Raw response: %q", response)
+	os.addAssistantMessage(session, sessionID, response, false)
+	effects, _ := os.deepSeekEffects(sessionID, response)
+	return effects
+}
+
+// readDeepSeekStream reads the server-sent events of a streamed chat
+// completion and passes each piece of content to onDelta. It returns nil
+// once the stream ends with [DONE].
+func readDeepSeekStream(r io.Reader, onDelta func(string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		// Empty lines separate the events, lines starting with ':' keep the
+		// connection alive
+		data, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return nil
+		}
+		var event struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+		}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return err
+		}
+		for _, choice := range event.Choices {
+			if choice.Delta.Content != "" {
+				onDelta(choice.Delta.Content)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
+
+// chatStream collects a streamed answer and passes its visible text on in
+// batches, so a fast stream does not send one WebSocket message per token
+type chatStream struct {
+	raw       strings.Builder
+	sent      string
+	interval  time.Duration
+	lastFlush time.Time
+	onChunk   func(string)
+}
+
+// add appends a piece of the answer and flushes if the interval is over
+func (s *chatStream) add(delta string) {
+	s.raw.WriteString(delta)
+	if time.Since(s.lastFlush) >= s.interval {
+		s.flush(false)
+	}
+}
+
+// flush passes the visible text not sent yet to onChunk. final also sends a
+// marker that was held back because it was not complete.
+func (s *chatStream) flush(final bool) {
+	visible := streamVisibleText(s.raw.String(), final)
+	if len(visible) <= len(s.sent) || !strings.HasPrefix(visible, s.sent) {
+		return
+	}
+	s.onChunk(visible[len(s.sent):])
+	s.sent = visible
+	s.lastFlush = time.Now()
+}
+
+// streamVisibleText returns the part of a streamed answer that can be shown:
+// without emojis and effect markers. Unless final, text from a '*' that may
+// start a marker is held back until the marker is complete.
+func streamVisibleText(raw string, final bool) string {
+	text := sanitizeDeepSeekResponse(raw)
+	text = beepRegex.ReplaceAllString(text, "")
+	text = talkRegex.ReplaceAllString(text, "")
+	text = evilRegex.ReplaceAllString(text, "")
+	text = strings.TrimLeftFunc(text, unicode.IsSpace)
+	if final {
+		return strings.TrimRightFunc(text, unicode.IsSpace)
+	}
+	if i := strings.LastIndex(text, "*"); i >= 0 {
+		tail := strings.ToLower(text[i:])
+		for _, marker := range []string{"*beep*", "*evil*", "*talk:"} {
+			if strings.HasPrefix(marker, tail) || strings.HasPrefix(tail, marker) {
+				return text[:i]
+			}
+		}
+	}
+	return text
+}
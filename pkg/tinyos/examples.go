@@ -0,0 +1,112 @@
+package tinyos
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/logger"
+)
+
+// ExampleInfo describes one program of the public example gallery
+type ExampleInfo struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"` // "bas", "sid" or "txt"
+	Size        int    `json:"size"`
+	Description string `json:"description"`
+}
+
+// maxExampleDescriptionLength limits descriptions shown in the gallery
+const maxExampleDescriptionLength = 80
+
+// ListExamples returns the example programs from the examples folder, sorted by name.
+// The listing is cached in memory for [FileSystem] examples_cache_ttl so the disk
+// isn't read on every request.
+func (os *TinyOS) ListExamples() ([]ExampleInfo, error) {
+	ttl := configuration.GetDuration("FileSystem", "examples_cache_ttl", 5*time.Minute)
+
+	os.examplesCacheMutex.Lock()
+	defer os.examplesCacheMutex.Unlock()
+
+	if os.examplesCache != nil && time.Since(os.examplesCacheTime) < ttl {
+		return os.examplesCache, nil
+	}
+
+	examples, err := os.readPhysicalExamples()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]ExampleInfo, 0, len(examples))
+	for name, content := range examples {
+		ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+		list = append(list, ExampleInfo{
+			Name:        name,
+			Type:        ext,
+			Size:        len(content),
+			Description: describeExample(ext, content),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+
+	os.examplesCache = list
+	os.examplesCacheTime = time.Now()
+	logger.Debug(logger.AreaFileSystem, "Example gallery cache refreshed: %d entries", len(list))
+	return list, nil
+}
+
+// describeExample derives a short description: the first REM line of a BASIC
+// program, the title of a SID tune or the first line of a text file.
+func describeExample(ext, content string) string {
+	var description string
+	switch ext {
+	case "bas":
+		// Look through the leading REM block, skipping decoration like "REM ====="
+		for _, line := range strings.Split(content, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			// Strip the line number
+			code := strings.TrimSpace(strings.TrimLeft(line, "0123456789"))
+			if len(code) < 3 || !strings.EqualFold(code[:3], "REM") {
+				break
+			}
+			if text := strings.TrimSpace(code[3:]); hasLetterOrDigit(text) {
+				description = text
+				break
+			}
+		}
+	case "sid":
+		if info, err := ParseSIDHeader([]byte(content)); err == nil {
+			description = info.Name
+			if info.Author != "" {
+				description += " by " + info.Author
+			}
+		}
+	case "txt":
+		for _, line := range strings.Split(content, "\n") {
+			if line = strings.TrimSpace(line); hasLetterOrDigit(line) {
+				description = line
+				break
+			}
+		}
+	}
+
+	if runes := []rune(description); len(runes) > maxExampleDescriptionLength {
+		description = string(runes[:maxExampleDescriptionLength-3]) + "..."
+	}
+	return description
+}
+
+// hasLetterOrDigit reports whether s contains at least one letter or digit
+func hasLetterOrDigit(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) >= 0
+}
@@ -0,0 +1,190 @@
+package tinyos
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/logger"
+)
+
+// defaultMaxConnectionsPerIP is used when [Security] max_connections_per_ip
+// is not set. 0 in the configuration turns the limit off.
+const defaultMaxConnectionsPerIP = 5
+
+// defaultTrustedIPs are exempt from the connection limit when [Security]
+// trusted_ips is not set
+const defaultTrustedIPs = "127.0.0.1,::1"
+
+// ErrTooManyConnections is returned when an IP address already has as many
+// terminal connections as max_connections_per_ip allows
+var ErrTooManyConnections = errors.New("too many connections from this IP address")
+
+// ConnectionIP strips the port from a remote address, so all connections of
+// one host are counted together
+func ConnectionIP(address string) string {
+	address = strings.TrimSpace(address)
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+	return strings.Trim(address, "[]")
+}
+
+// ClientIP returns the address a request is counted and banned under: the
+// host of RemoteAddr. Any client can send X-Forwarded-For, so the header is
+// only honored when RemoteAddr is one of the [Security] trusted_proxies.
+func ClientIP(r *http.Request) string {
+	return clientIPFrom(r.RemoteAddr, r.Header.Values("X-Forwarded-For"),
+		configuration.GetString("Security", "trusted_proxies", ""))
+}
+
+// clientIPFrom reads the X-Forwarded-For entries from the right, as each
+// proxy appends the address it received the request from, and returns the
+// first one that is not a trusted proxy itself. Entries a client added on
+// the left are never reached.
+func clientIPFrom(remoteAddr string, forwardedFor []string, proxies string) string {
+	ip := ConnectionIP(remoteAddr)
+	if !ipListContains(proxies, ip) {
+		return ip
+	}
+	hops := strings.Split(strings.Join(forwardedFor, ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := ConnectionIP(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !ipListContains(proxies, ip) {
+			break
+		}
+	}
+	return ip
+}
+
+// maxConnectionsPerIP returns the configured limit, 0 for no limit
+func maxConnectionsPerIP() int {
+	limit := configuration.GetInt("Security", "max_connections_per_ip", defaultMaxConnectionsPerIP)
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// isTrustedIP reports whether ip is listed in [Security] trusted_ips
+func isTrustedIP(ip string) bool {
+	return ipListContains(configuration.GetString("Security", "trusted_ips", defaultTrustedIPs), ip)
+}
+
+// ipListContains reports whether ip is in a comma-separated list of single
+// addresses and CIDR ranges like 10.0.0.0/8
+func ipListContains(list, ip string) bool {
+	addr := net.ParseIP(ip)
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+			continue
+		case strings.Contains(entry, "/"):
+			if _, network, err := net.ParseCIDR(entry); err == nil && addr != nil && network.Contains(addr) {
+				return true
+			}
+		case entry == ip:
+			return true
+		case addr != nil && addr.Equal(net.ParseIP(entry)):
+			return true
+		}
+	}
+	return false
+}
+
+// TooManyConnectionsMessage is the text shown to a client whose connection
+// was refused by the per-IP limit
+func TooManyConnectionsMessage() string {
+	return fmt.Sprintf("Too many connections from your IP address (limit %d). Close another terminal window and try again.",
+		maxConnectionsPerIP())
+}
+
+// CheckIPConnectionLimit reports ErrTooManyConnections if a new connection
+// from address would exceed the limit. The WebSocket handler calls it before
+// the upgrade; the connection is only counted by RegisterIPConnection.
+func (os *TinyOS) CheckIPConnectionLimit(address string) error {
+	ip := ConnectionIP(address)
+	limit := maxConnectionsPerIP()
+	if limit == 0 || isTrustedIP(ip) {
+		return nil
+	}
+
+	os.ipConnectionMutex.Lock()
+	count := os.ipConnections[ip]
+	os.ipConnectionMutex.Unlock()
+
+	if count >= limit {
+		return ErrTooManyConnections
+	}
+	return nil
+}
+
+// RegisterIPConnection counts a connection of sessionID from address once
+// its session has been created. It fails with ErrTooManyConnections if the
+// limit is reached. The count is released by CleanupSessionResources.
+func (os *TinyOS) RegisterIPConnection(sessionID, address string) error {
+	if sessionID == "" {
+		return nil
+	}
+	ip := ConnectionIP(address)
+	limit := maxConnectionsPerIP()
+	trusted := isTrustedIP(ip)
+
+	os.ipConnectionMutex.Lock()
+	defer os.ipConnectionMutex.Unlock()
+
+	if limit > 0 && !trusted && os.ipConnections[ip] >= limit {
+		logger.SecurityWarn("Connection limit of %d reached for IP %s, refusing session %s", limit, ip, shortSessionID(sessionID))
+		return ErrTooManyConnections
+	}
+	os.ipConnections[ip]++
+	os.connectionIPs[sessionID] = append(os.connectionIPs[sessionID], ip)
+	return nil
+}
+
+// MoveIPConnections moves the counted connections of oldSessionID to
+// newSessionID when a connected client switches sessions, so the disconnect
+// releases them
+func (os *TinyOS) MoveIPConnections(oldSessionID, newSessionID string) {
+	if oldSessionID == "" || newSessionID == "" || oldSessionID == newSessionID {
+		return
+	}
+
+	os.ipConnectionMutex.Lock()
+	defer os.ipConnectionMutex.Unlock()
+
+	if ips, exists := os.connectionIPs[oldSessionID]; exists {
+		os.connectionIPs[newSessionID] = append(os.connectionIPs[newSessionID], ips...)
+		delete(os.connectionIPs, oldSessionID)
+	}
+}
+
+// releaseIPConnection drops one counted connection of sessionID
+func (os *TinyOS) releaseIPConnection(sessionID string) {
+	os.ipConnectionMutex.Lock()
+	defer os.ipConnectionMutex.Unlock()
+
+	ips := os.connectionIPs[sessionID]
+	if len(ips) == 0 {
+		return
+	}
+	ip := ips[len(ips)-1]
+	if len(ips) == 1 {
+		delete(os.connectionIPs, sessionID)
+	} else {
+		os.connectionIPs[sessionID] = ips[:len(ips)-1]
+	}
+	if os.ipConnections[ip] <= 1 {
+		delete(os.ipConnections, ip)
+	} else {
+		os.ipConnections[ip]--
+	}
+}
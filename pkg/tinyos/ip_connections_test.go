@@ -0,0 +1,66 @@
+package tinyos
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestClientIPFrom checks which address a request is counted under. The
+// X-Forwarded-For header only counts when the connection comes from a
+// trusted proxy, and then only the entries that proxy appended.
+func TestClientIPFrom(t *testing.T) {
+	const proxies = "10.0.0.1, 192.168.0.0/16"
+	for _, tc := range []struct {
+		name      string
+		remote    string
+		forwarded []string
+		want      string
+	}{
+		{"no header", "203.0.113.5:4711", nil, "203.0.113.5"},
+		{"spoofed header from a client", "203.0.113.5:4711", []string{"198.51.100.1"}, "203.0.113.5"},
+		{"ipv6 client", "[2001:db8::5]:4711", []string{"198.51.100.1"}, "2001:db8::5"},
+		{"trusted proxy", "10.0.0.1:80", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"client prepends a fake entry", "10.0.0.1:80", []string{"127.0.0.1, 198.51.100.1"}, "198.51.100.1"},
+		{"chain of trusted proxies", "10.0.0.1:80", []string{"198.51.100.1, 192.168.1.2"}, "198.51.100.1"},
+		{"several headers", "10.0.0.1:80", []string{"127.0.0.1", "198.51.100.1"}, "198.51.100.1"},
+		{"garbage entry", "10.0.0.1:80", []string{"198.51.100.1, not-an-ip"}, "10.0.0.1"},
+		{"proxy without header", "10.0.0.1:80", nil, "10.0.0.1"},
+	} {
+		if got := clientIPFrom(tc.remote, tc.forwarded, proxies); got != tc.want {
+			t.Errorf("%s: clientIPFrom(%q, %q) = %q, want %q", tc.name, tc.remote, tc.forwarded, got, tc.want)
+		}
+	}
+}
+
+// TestConnectionLimitIgnoresSpoofedHeader opens connections from one client
+// with a different X-Forwarded-For each time. Without trusted_proxies they
+// must all count against the client's own address.
+func TestConnectionLimitIgnoresSpoofedHeader(t *testing.T) {
+	os := &TinyOS{
+		ipConnections: make(map[string]int),
+		connectionIPs: make(map[string][]string),
+	}
+	for i := 0; i < defaultMaxConnectionsPerIP; i++ {
+		r := httptest.NewRequest("GET", "/ws", nil)
+		r.RemoteAddr = fmt.Sprintf("203.0.113.5:%d", 4000+i)
+		r.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i))
+		if err := os.RegisterIPConnection(fmt.Sprintf("s%d", i), ClientIP(r)); err != nil {
+			t.Fatalf("connection %d: %v", i, err)
+		}
+	}
+
+	r := httptest.NewRequest("GET", "/ws", nil)
+	r.RemoteAddr = "203.0.113.5:5000"
+	r.Header.Set("X-Forwarded-For", "198.51.100.99")
+	if err := os.CheckIPConnectionLimit(ClientIP(r)); !errors.Is(err, ErrTooManyConnections) {
+		t.Errorf("connection with a new spoofed header: err = %v, want ErrTooManyConnections", err)
+	}
+
+	// A client claiming to be a trusted IP is not exempt either
+	r.Header.Set("X-Forwarded-For", "127.0.0.1")
+	if err := os.CheckIPConnectionLimit(ClientIP(r)); !errors.Is(err, ErrTooManyConnections) {
+		t.Errorf("connection claiming 127.0.0.1: err = %v, want ErrTooManyConnections", err)
+	}
+}
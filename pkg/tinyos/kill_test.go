@@ -0,0 +1,52 @@
+package tinyos
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestKillOnlyFindsOwnSessions checks that a user cannot tell the sessions
+// of others apart from sessions that do not exist
+func TestKillOnlyFindsOwnSessions(t *testing.T) {
+	os := newDBTestOS(t, map[string]string{
+		"session_aaaa1111": "alice",
+		"session_aaaa2222": "alice",
+		"session_bbbb1111": "bob",
+		"session_bbbb2222": "bob",
+	})
+	stopped := ""
+	os.StopProgramCallback = func(sessionID string) bool {
+		stopped = sessionID
+		return true
+	}
+	kill := func(target string) string {
+		return messageText(os.cmdKill([]string{"session_aaaa1111", target}))
+	}
+
+	for _, target := range []string{"session_bbbb1111", "session_bbbb", "session_bb", "session_cccc"} {
+		if out, want := kill(target), "kill: no such session "+target; !strings.Contains(out, want) {
+			t.Errorf("kill %s = %q, want %q", target, out, want)
+		}
+	}
+	if stopped != "" {
+		t.Errorf("kill stopped session %s of another user", stopped)
+	}
+	if out := kill("session_"); !strings.Contains(out, "ambiguous") {
+		t.Errorf("kill with a prefix of two own sessions = %q, want ambiguous", out)
+	}
+	if out := kill("session_aaaa2"); !strings.Contains(out, "stopped") || stopped != "session_aaaa2222" {
+		t.Errorf("kill of an own session = %q, stopped %q", out, stopped)
+	}
+}
+
+// TestFindSessionByPrefixForAdmins checks that admins still search all
+// sessions
+func TestFindSessionByPrefixForAdmins(t *testing.T) {
+	os := newDBTestOS(t, map[string]string{"session_aaaa1111": "alice", "session_bbbb1111": "bob"})
+	if id, owner, err := os.findSessionByPrefix("session_bbbb", ""); err != nil || id != "session_bbbb1111" || owner != "bob" {
+		t.Errorf("findSessionByPrefix for admins = %q, %q, %v", id, owner, err)
+	}
+	if _, _, err := os.findSessionByPrefix("session_bbbb1111", "alice"); err == nil {
+		t.Error("alice found a session of bob by its full id")
+	}
+}
@@ -0,0 +1,79 @@
+package tinyos
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// writeTestLibrary stores a library with the given files in alice's home
+func writeTestLibrary(t *testing.T, os *TinyOS, name string, files map[string]string) {
+	t.Helper()
+	data, err := json.Marshal(programLibrary{Format: libraryFormat, Version: libraryVersion, Files: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Vfs.WriteFile("/home/alice/"+name, string(data), ""); err != nil {
+		t.Fatalf("writing %s: %v", name, err)
+	}
+}
+
+// newLibraryTestOS returns a TinyOS with alice's home and import confirmations
+func newLibraryTestOS(t *testing.T) *TinyOS {
+	t.Helper()
+	os := newAccessTestOS(t)
+	os.libraryConfirmStates = make(map[string]*LibraryConfirmState)
+	return os
+}
+
+// TestImportChecksQuotaAgainAfterConfirm fills the home directory while
+// import waits for the overwrite answer; the "y" must not exceed the quota
+func TestImportChecksQuotaAgainAfterConfirm(t *testing.T) {
+	os := newLibraryTestOS(t)
+	big := strings.Repeat("1", 900*1024)
+	writeTestLibrary(t, os, "lib.json", map[string]string{"mine.bas": "10 PRINT 1", "new.bas": big})
+
+	reply := messageText(os.cmdImport([]string{"alice-session", "lib.json"}))
+	if !os.isInLibraryConfirm("alice-session") {
+		t.Fatalf("import did not ask before overwriting mine.bas: %s", reply)
+	}
+
+	// Meanwhile another session uses up almost all of the 10 MB quota
+	for i := 0; i < 9; i++ {
+		if err := os.Vfs.WriteFile(fmt.Sprintf("/home/alice/fill%d.dat", i), strings.Repeat("x", 1000*1024), ""); err != nil {
+			t.Fatalf("filling the home directory: %v", err)
+		}
+	}
+
+	reply = messageText(os.handleLibraryConfirmInput("y", "alice-session"))
+	if !strings.Contains(reply, "not enough space") {
+		t.Errorf("import after filling the quota answered %q, want a quota error", reply)
+	}
+	if os.Vfs.Exists("/home/alice/new.bas", "") {
+		t.Error("new.bas was written although the import no longer fits")
+	}
+	if content, _ := os.Vfs.ReadFile("/home/alice/mine.bas", ""); content != "10 PRINT \"MINE\"" {
+		t.Error("mine.bas was overwritten although the import no longer fits")
+	}
+}
+
+// TestImportRefusesUnconfirmedOverwrite creates a file of the library while
+// the question is open; only the file the user agreed to may be replaced
+func TestImportRefusesUnconfirmedOverwrite(t *testing.T) {
+	os := newLibraryTestOS(t)
+	writeTestLibrary(t, os, "lib.json", map[string]string{"mine.bas": "10 PRINT 1", "other.bas": "10 PRINT 2"})
+
+	os.cmdImport([]string{"alice-session", "lib.json"})
+	if err := os.Vfs.WriteFile("/home/alice/other.bas", "10 PRINT \"KEEP\"", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	reply := messageText(os.handleLibraryConfirmInput("y", "alice-session"))
+	if !strings.Contains(reply, "other.bas") || !strings.Contains(reply, "Nothing was imported") {
+		t.Errorf("import answered %q, want it to refuse overwriting other.bas", reply)
+	}
+	if content, _ := os.Vfs.ReadFile("/home/alice/other.bas", ""); content != "10 PRINT \"KEEP\"" {
+		t.Errorf("other.bas = %q, want it unchanged", content)
+	}
+}
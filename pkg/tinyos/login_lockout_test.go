@@ -0,0 +1,67 @@
+package tinyos
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLoginLockoutDuration checks the backoff with the default settings:
+// 30s doubled per further failure, capped at one hour
+func TestLoginLockoutDuration(t *testing.T) {
+	for _, tc := range []struct {
+		excess int
+		want   time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{6, 32 * time.Minute},
+		{7, time.Hour},
+		{50, time.Hour},
+	} {
+		if got := loginLockoutDuration(tc.excess); got != tc.want {
+			t.Errorf("loginLockoutDuration(%d) = %v, want %v", tc.excess, got, tc.want)
+		}
+	}
+}
+
+// TestFailedLoginsEscalateLockout records failures for one IP and checks
+// when it is blocked and for how long
+func TestFailedLoginsEscalateLockout(t *testing.T) {
+	os := &TinyOS{failedLoginAttempts: make(map[string]*LoginAttemptTracker)}
+	const ip = "192.0.2.7"
+
+	for i := 0; i < 4; i++ {
+		os.recordFailedLoginAttempt(ip)
+	}
+	if blocked, _ := os.isLoginBlocked(ip); blocked {
+		t.Fatal("blocked before the fifth failure")
+	}
+
+	os.recordFailedLoginAttempt(ip)
+	if blocked, remaining := os.isLoginBlocked(ip); !blocked || remaining > 30 || remaining < 28 {
+		t.Errorf("after 5 failures: blocked=%v remaining=%ds, want about 30s", blocked, remaining)
+	}
+	os.recordFailedLoginAttempt(ip)
+	if blocked, remaining := os.isLoginBlocked(ip); !blocked || remaining > 60 || remaining < 58 {
+		t.Errorf("after 6 failures: blocked=%v remaining=%ds, want about 60s", blocked, remaining)
+	}
+	if blocked, _ := os.isLoginBlocked("192.0.2.8"); blocked {
+		t.Error("another IP is blocked as well")
+	}
+
+	// An hour without failures starts the count again
+	tracker := os.failedLoginAttempts[ip]
+	tracker.LastAttempt = time.Now().Add(-2 * time.Hour)
+	tracker.LockedUntil = time.Now().Add(-time.Hour)
+	os.recordFailedLoginAttempt(ip)
+	if blocked, _ := os.isLoginBlocked(ip); blocked || tracker.FailedAttempts != 1 || tracker.Lockout != 0 {
+		t.Errorf("after the reset window: blocked=%v attempts=%d lockout=%v, want a fresh count",
+			blocked, tracker.FailedAttempts, tracker.Lockout)
+	}
+
+	os.clearFailedLoginAttempts(ip)
+	if _, exists := os.failedLoginAttempts[ip]; exists {
+		t.Error("a successful login did not clear the failures")
+	}
+}
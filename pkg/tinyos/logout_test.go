@@ -0,0 +1,74 @@
+package tinyos
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// addTestUserSession stores a session of username in user_sessions, as a
+// login does
+func addTestUserSession(t *testing.T, os *TinyOS, sessionID, username string) {
+	t.Helper()
+	now := time.Now().Unix()
+	if _, err := os.db.Exec("INSERT INTO user_sessions (session_id, user_id, username, ip_address, created_at, last_activity) "+
+		"SELECT ?, rowid, username, '192.0.2.1', ?, ? FROM users WHERE username = ?", sessionID, now, now, username); err != nil {
+		t.Fatalf("adding session %s of %s: %v", sessionID, username, err)
+	}
+}
+
+// TestLogoutAllRevokesOtherSessions logs alice out everywhere from one tab.
+// Connected and stored sessions of alice are revoked, bob's session stays.
+func TestLogoutAllRevokesOtherSessions(t *testing.T) {
+	os := newDBTestOS(t, map[string]string{"a1": "alice", "a2": "alice", "b": "bob"})
+	addTestUser(t, os, "alice", false)
+	addTestUser(t, os, "bob", false)
+	// a3 is only stored, e.g. a closed browser that could restore it
+	for id, username := range map[string]string{"a1": "alice", "a2": "alice", "a3": "alice", "b": "bob"} {
+		addTestUserSession(t, os, id, username)
+	}
+
+	reply := messageText(os.cmdLogout([]string{"a1", "all"}))
+	if !strings.Contains(reply, "2 other sessions ended.") {
+		t.Errorf("logout all answered %q, want 2 other sessions", reply)
+	}
+
+	for _, id := range []string{"a2", "a3"} {
+		if !os.isSessionRevoked(id) {
+			t.Errorf("session %s is not revoked", id)
+		}
+		if _, err := os.RestoreUserSession(id, "alice", "192.0.2.1"); err == nil {
+			t.Errorf("session %s can still be restored", id)
+		}
+	}
+	if os.isSessionRevoked("b") {
+		t.Error("bob's session was revoked")
+	}
+	if n := countRows(t, os, "SELECT COUNT(*) FROM user_sessions WHERE username = 'alice'"); n != 0 {
+		t.Errorf("%d sessions of alice left in user_sessions", n)
+	}
+	if n := countRows(t, os, "SELECT COUNT(*) FROM user_sessions WHERE username = 'bob'"); n != 1 {
+		t.Errorf("%d sessions of bob left in user_sessions, want 1", n)
+	}
+	if username := os.GetUsernameForSession("a2"); username == "alice" {
+		t.Error("the connected tab a2 is still logged in as alice")
+	}
+}
+
+// TestLogoutAllNeedsLogin refuses logout all for a guest and ends nothing
+func TestLogoutAllNeedsLogin(t *testing.T) {
+	os := newDBTestOS(t, map[string]string{"g": "guest", "a": "alice"})
+	addTestUser(t, os, "alice", false)
+	addTestUserSession(t, os, "a", "alice")
+
+	reply := messageText(os.cmdLogout([]string{"g", "all"}))
+	if !strings.Contains(reply, "Please log in") {
+		t.Errorf("logout all as guest answered %q", reply)
+	}
+	if n := countRows(t, os, "SELECT COUNT(*) FROM revoked_sessions"); n != 0 {
+		t.Errorf("%d sessions revoked by a guest", n)
+	}
+	if os.GetUsernameForSession("a") != "alice" {
+		t.Error("alice was logged out by a guest")
+	}
+}
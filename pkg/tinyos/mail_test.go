@@ -0,0 +1,50 @@
+package tinyos
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// sendTestMail sends a one-line mail from the session and returns the output
+func sendTestMail(os *TinyOS, sessionID, recipient string) string {
+	out := messageText(os.cmdMail([]string{sessionID, "send", recipient, "Hello"}))
+	if !os.isInMailCompose(sessionID) {
+		return out
+	}
+	os.handleMailComposeInput("Hi there", sessionID)
+	return messageText(os.handleMailComposeInput(".", sessionID))
+}
+
+// TestMailRateLimitSurvivesDelete sends up to the hourly limit, deletes the
+// mails and expects the next send to be refused
+func TestMailRateLimitSurvivesDelete(t *testing.T) {
+	os := newDBTestOS(t, map[string]string{"alice-session": "alice"})
+	addTestUser(t, os, "alice", false)
+
+	for i := 0; i < 10; i++ {
+		if out := sendTestMail(os, "alice-session", "alice"); !strings.Contains(out, "Message sent") {
+			t.Fatalf("mail %d: %q", i+1, out)
+		}
+	}
+	rows, err := os.db.Query("SELECT id FROM user_mail")
+	if err != nil {
+		t.Fatalf("listing mails: %v", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		rows.Scan(&id)
+		ids = append(ids, id)
+	}
+	rows.Close()
+	for _, id := range ids {
+		if out := messageText(os.cmdMail([]string{"alice-session", "delete", fmt.Sprint(id)})); !strings.Contains(out, "deleted") {
+			t.Fatalf("deleting mail %d: %q", id, out)
+		}
+	}
+
+	if out := sendTestMail(os, "alice-session", "alice"); !strings.Contains(out, "limit of 10 messages per hour") {
+		t.Errorf("send after deleting all mails: %q, want the hourly limit", out)
+	}
+}
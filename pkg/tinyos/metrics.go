@@ -0,0 +1,57 @@
+package tinyos
+
+import (
+	"sync/atomic"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+)
+
+// defaultMetricsAllowedIPs may read the metrics endpoint when [Metrics]
+// allowed_ips is not set
+const defaultMetricsAllowedIPs = "127.0.0.1,::1"
+
+// serverCounters are the counters of the metrics endpoint. They only ever
+// grow and are atomics, so logins and chat requests never wait for a reader.
+type serverCounters struct {
+	loginSuccesses atomic.Int64
+	loginFailures  atomic.Int64
+	chatRequests   atomic.Int64
+}
+
+// Metrics is a snapshot of the numbers shown by the metrics endpoint
+type Metrics struct {
+	Sessions       int   // Sessions known to TinyOS, guests included
+	BasicSessions  int   // Sessions in BASIC mode
+	TelnetSessions int   // Sessions with an open telnet connection
+	LoginSuccesses int64 // Successful password logins since the start
+	LoginFailures  int64 // Logins refused for a wrong password or unknown user
+	ChatRequests   int64 // Questions sent to the MCP chat
+}
+
+// CountChatRequest counts a question sent to the MCP chat
+func (os *TinyOS) CountChatRequest() {
+	os.counters.chatRequests.Add(1)
+}
+
+// CollectMetrics returns the current metrics. The gauges hold each session
+// lock only for a len(), the counters are read without a lock.
+func (os *TinyOS) CollectMetrics() Metrics {
+	os.sessionMutex.RLock()
+	sessions := len(os.sessions)
+	os.sessionMutex.RUnlock()
+
+	return Metrics{
+		Sessions:       sessions,
+		BasicSessions:  os.GetActiveBasicSessionCount(),
+		TelnetSessions: os.GetActiveTelnetSessionCount(),
+		LoginSuccesses: os.counters.loginSuccesses.Load(),
+		LoginFailures:  os.counters.loginFailures.Load(),
+		ChatRequests:   os.counters.chatRequests.Load(),
+	}
+}
+
+// IsMetricsClientAllowed reports whether a client at address may read the
+// metrics endpoint, i.e. its IP is listed in [Metrics] allowed_ips
+func IsMetricsClientAllowed(address string) bool {
+	return ipListContains(configuration.GetString("Metrics", "allowed_ips", defaultMetricsAllowedIPs), ConnectionIP(address))
+}
@@ -0,0 +1,123 @@
+package tinyos
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// catPagerKeys is the key help shown in the pager status line
+const catPagerKeys = "m: more, /text: find, :N: line, q: quit"
+
+// handleCatPagerCommand handles the pager commands that take an argument:
+// /pattern searches forward, a lone / repeats the last search, and :N jumps
+// to line N. ok is false for any other input.
+func (os *TinyOS) handleCatPagerCommand(sessionID string, state *CatPagerState, input string) (messages []shared.Message, ok bool) {
+	input = strings.TrimLeft(strings.TrimRight(input, "\r\n"), " ")
+	switch {
+	case strings.HasPrefix(input, "/"):
+		return os.searchCatPager(sessionID, state, input[1:]), true
+	case strings.HasPrefix(input, ":"):
+		return os.jumpCatPager(sessionID, state, strings.TrimSpace(input[1:])), true
+	}
+	return nil, false
+}
+
+// catPagerStatus builds the status line of the pager: title, the position
+// in percent and the keys, padded like the editor status line
+func catPagerStatus(state *CatPagerState, percent int) string {
+	status := fmt.Sprintf("--- %s (%d%%) --- %s", state.Filename, percent, catPagerKeys)
+	// Limit padding to avoid performance issues with very wide terminals
+	if cols := state.Terminal.Cols; cols > 0 && cols <= 120 && len(status) < cols {
+		padding := cols - len(status)
+		if padding > 50 {
+			padding = 50
+		}
+		status += strings.Repeat(" ", padding)
+	}
+	return status
+}
+
+// catPagerTop returns the index of the first line on the current page.
+// CurrentLine already points behind the page.
+func catPagerTop(state *CatPagerState) int {
+	top := state.CurrentLine - state.PageSize
+	if top < 0 {
+		top = 0
+	}
+	return top
+}
+
+// searchCatPager shows the page starting at the next line that contains
+// pattern, ignoring case. The search starts below the top line of the current
+// page and wraps around to the first line. A streamed file is read through
+// chunk by chunk, so a search may take a moment on a large file.
+func (os *TinyOS) searchCatPager(sessionID string, state *CatPagerState, pattern string) []shared.Message {
+	os.catPagerMutex.Lock()
+	if pattern == "" {
+		pattern = state.LastSearch
+	} else {
+		state.LastSearch = pattern
+	}
+	start := catPagerTop(state) + 1
+	os.catPagerMutex.Unlock()
+
+	if pattern == "" {
+		return []shared.Message{{Type: shared.MessageTypeText, Content: "No previous search. Use /text to search."}}
+	}
+
+	needle := strings.ToLower(pattern)
+	for line := start; ; line++ {
+		text, ok, err := os.catPagerLine(state, line)
+		if err != nil {
+			return os.closeCatPager(sessionID, shared.Message{Type: shared.MessageTypeText, Content: "Error: " + err.Error()})
+		}
+		if !ok {
+			break
+		}
+		if strings.Contains(strings.ToLower(text), needle) {
+			return os.showCatPageAt(sessionID, state, line)
+		}
+	}
+	for line := 0; line < start; line++ {
+		text, ok, err := os.catPagerLine(state, line)
+		if err != nil {
+			return os.closeCatPager(sessionID, shared.Message{Type: shared.MessageTypeText, Content: "Error: " + err.Error()})
+		}
+		if !ok {
+			break
+		}
+		if strings.Contains(strings.ToLower(text), needle) {
+			return append([]shared.Message{{Type: shared.MessageTypeText, Content: "--- Search wrapped to top ---"}},
+				os.showCatPageAt(sessionID, state, line)...)
+		}
+	}
+	return []shared.Message{{Type: shared.MessageTypeText, Content: "Pattern not found: " + pattern}}
+}
+
+// jumpCatPager shows the page starting at line n (1-based)
+func (os *TinyOS) jumpCatPager(sessionID string, state *CatPagerState, arg string) []shared.Message {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return []shared.Message{{Type: shared.MessageTypeText, Content: "Usage: :N with a line number from 1, e.g. :100"}}
+	}
+	// A streamed file does not know its length before it is read to the end
+	_, ok, err := os.catPagerLine(state, n-1)
+	if err != nil {
+		return os.closeCatPager(sessionID, shared.Message{Type: shared.MessageTypeText, Content: "Error: " + err.Error()})
+	}
+	if !ok {
+		return []shared.Message{{Type: shared.MessageTypeText, Content: fmt.Sprintf("Line %d is past the end of the file.", n)}}
+	}
+	return os.showCatPageAt(sessionID, state, n-1)
+}
+
+// showCatPageAt moves the pager to line and shows the page from there
+func (os *TinyOS) showCatPageAt(sessionID string, state *CatPagerState, line int) []shared.Message {
+	os.catPagerMutex.Lock()
+	state.CurrentLine = line
+	os.catPagerMutex.Unlock()
+	return os.showNextCatPage(sessionID, state)
+}
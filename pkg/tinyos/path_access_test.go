@@ -0,0 +1,210 @@
+package tinyos
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/virtualfs"
+)
+
+// newAccessTestOS returns a TinyOS without database with a session of
+// alice and a secret file in bob's home
+func newAccessTestOS(t *testing.T) *TinyOS {
+	t.Helper()
+	os := &TinyOS{
+		Vfs:         virtualfs.New(nil),
+		sessions:    make(map[string]*Session),
+		sfxVolumes:  make(map[string]int),
+		lastDiskSFX: make(map[string]time.Time),
+	}
+	os.Vfs.SetTinyOSProvider(os)
+	for _, dir := range []string{"/home/alice", "/home/bob"} {
+		if err := os.Vfs.MkdirAll(dir); err != nil {
+			t.Fatalf("MkdirAll(%s): %v", dir, err)
+		}
+	}
+	if err := os.Vfs.WriteFile("/home/bob/secret.bas", "10 PRINT \"SECRET\"", ""); err != nil {
+		t.Fatalf("writing bob's file: %v", err)
+	}
+	if err := os.Vfs.WriteFile("/home/alice/mine.bas", "10 PRINT \"MINE\"", ""); err != nil {
+		t.Fatalf("writing alice's file: %v", err)
+	}
+	os.sessions["alice-session"] = &Session{ID: "alice-session", Username: "alice", CurrentPath: "/home/alice"}
+	return os
+}
+
+// TestResolvePathBlocksOtherHomes tries traversal and absolute paths into
+// another user's home and expects them to be refused
+func TestResolvePathBlocksOtherHomes(t *testing.T) {
+	os := newAccessTestOS(t)
+	for _, p := range []string{
+		"/home/bob/secret.bas",
+		"../bob/secret.bas",
+		"../../home/bob/secret.bas",
+		"/home/alice/../bob/secret.bas",
+		"./../bob/./secret.bas",
+		`..\bob\secret.bas`,
+	} {
+		if resolved, err := os.ResolvePath(p, "alice-session"); !errors.Is(err, virtualfs.ErrPermissionDenied) {
+			t.Errorf("ResolvePath(%q) = %q, %v; want permission denied", p, resolved, err)
+		}
+		if content, err := os.ReadFileWithSession(p, "alice-session"); err == nil {
+			t.Errorf("ReadFileWithSession(%q) read %q", p, content)
+		}
+	}
+}
+
+// TestResolvePathAllowsOwnHome checks that paths in the own home, also
+// with . and .., still resolve and read
+func TestResolvePathAllowsOwnHome(t *testing.T) {
+	os := newAccessTestOS(t)
+	for p, want := range map[string]string{
+		"mine.bas":                    "/home/alice/mine.bas",
+		"./basic/../mine.bas":         "/home/alice/mine.bas",
+		"/home/alice/mine.bas":        "/home/alice/mine.bas",
+		"/home/bob/../alice/mine.bas": "/home/alice/mine.bas",
+	} {
+		resolved, err := os.ResolvePath(p, "alice-session")
+		if err != nil || resolved != want {
+			t.Errorf("ResolvePath(%q) = %q, %v; want %q", p, resolved, err, want)
+			continue
+		}
+		if _, err := os.ReadFileWithSession(p, "alice-session"); err != nil {
+			t.Errorf("ReadFileWithSession(%q): %v", p, err)
+		}
+	}
+}
+
+// TestVFSBlocksCrossUserAccess checks the VFS itself, which BASIC and the
+// editor call directly
+func TestVFSBlocksCrossUserAccess(t *testing.T) {
+	os := newAccessTestOS(t)
+	vfs := os.Vfs
+
+	for _, p := range []string{"/home/bob/secret.bas", "../bob/secret.bas", "/home/alice/../bob/secret.bas"} {
+		if _, err := vfs.ReadFile(p, "alice-session"); !errors.Is(err, virtualfs.ErrPermissionDenied) {
+			t.Errorf("ReadFile(%q): %v, want permission denied", p, err)
+		}
+		if vfs.Exists(p, "alice-session") {
+			t.Errorf("Exists(%q) reports another user's file", p)
+		}
+	}
+	if _, _, err := vfs.ReadFileChunk("/home/bob/secret.bas", "alice-session", 0, 16); !errors.Is(err, virtualfs.ErrPermissionDenied) {
+		t.Errorf("ReadFileChunk: %v, want permission denied", err)
+	}
+	for _, p := range []string{"/home/bob/evil.bas", "../bob/secret.bas", "/system/evil.bas"} {
+		if err := vfs.WriteFile(p, "10 END", "alice-session"); !errors.Is(err, virtualfs.ErrPermissionDenied) {
+			t.Errorf("WriteFile(%q): %v, want permission denied", p, err)
+		}
+	}
+	if content, err := vfs.ReadFile("/home/bob/secret.bas", ""); err != nil || content == "" {
+		t.Errorf("system read without session failed: %q, %v", content, err)
+	}
+	if err := vfs.WriteFile("notes.bas", "10 END", "alice-session"); err != nil {
+		t.Errorf("writing in the own home: %v", err)
+	}
+}
+
+// TestVFSCrossUserAccessOfTemporaryUser checks that neither a plain user nor
+// the dyson puzzle account reaches another home, even with an admin flag
+// left in an old database, while a real admin does in admin mode
+func TestVFSCrossUserAccessOfTemporaryUser(t *testing.T) {
+	os := newDBTestOS(t, map[string]string{"alice-session": "alice", "dyson-session": "dyson", "root-session": "root"})
+	os.Vfs = virtualfs.New(nil)
+	os.Vfs.SetTinyOSProvider(os)
+	addTestUser(t, os, "alice", false)
+	addTestUser(t, os, "dyson", true)
+	addTestUser(t, os, "root", true)
+	if err := os.Vfs.MkdirAll("/home/bob"); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.Vfs.WriteFile("/home/bob/secret.bas", "10 PRINT \"SECRET\"", ""); err != nil {
+		t.Fatalf("writing bob's file: %v", err)
+	}
+
+	for _, sessionID := range []string{"alice-session", "dyson-session"} {
+		if content, err := os.Vfs.ReadFile("/home/bob/secret.bas", sessionID); !errors.Is(err, virtualfs.ErrPermissionDenied) {
+			t.Errorf("%s read %q, %v; want permission denied", sessionID, content, err)
+		}
+		if err := os.Vfs.WriteFile("/home/bob/evil.bas", "10 END", sessionID); !errors.Is(err, virtualfs.ErrPermissionDenied) {
+			t.Errorf("%s wrote into bob's home: %v", sessionID, err)
+		}
+	}
+	// A real admin needs admin mode (sudo) for another home
+	if _, err := os.Vfs.ReadFile("/home/bob/secret.bas", "root-session"); !errors.Is(err, virtualfs.ErrPermissionDenied) {
+		t.Errorf("admin without sudo read another home: %v", err)
+	}
+	if err := os.Vfs.WriteFile("/home/bob/evil.bas", "10 END", "root-session"); !errors.Is(err, virtualfs.ErrPermissionDenied) {
+		t.Errorf("admin without sudo wrote into another home: %v", err)
+	}
+	os.elevatedUntil["root-session"] = time.Now().Add(time.Minute)
+	if _, err := os.Vfs.ReadFile("/home/bob/secret.bas", "root-session"); err != nil {
+		t.Errorf("admin read of another home in admin mode failed: %v", err)
+	}
+	os.elevatedUntil["dyson-session"] = time.Now().Add(time.Minute)
+	if _, err := os.Vfs.ReadFile("/home/bob/secret.bas", "dyson-session"); !errors.Is(err, virtualfs.ErrPermissionDenied) {
+		t.Errorf("dyson in admin mode read another home: %v", err)
+	}
+}
+
+// TestRmAndMkdirStayInOwnHome checks that rm and mkdir only change the own
+// home, also for homes whose name starts with the user's name
+func TestRmAndMkdirStayInOwnHome(t *testing.T) {
+	os := newAccessTestOS(t)
+	os.sessions["bob-session"] = &Session{ID: "bob-session", Username: "bob", CurrentPath: "/home/bob"}
+	for _, p := range []string{"/home/alicex/f.bas", "/home/bobby/f.bas"} {
+		if err := os.Vfs.MkdirAll(path.Dir(p)); err != nil {
+			t.Fatalf("MkdirAll(%s): %v", path.Dir(p), err)
+		}
+		if err := os.Vfs.WriteFile(p, "10 END", ""); err != nil {
+			t.Fatalf("writing %s: %v", p, err)
+		}
+	}
+
+	for _, tc := range []struct{ session, path string }{
+		{"alice-session", "/home/bob/secret.bas"},
+		{"alice-session", "../bob/secret.bas"},
+		{"alice-session", "/home/alicex/f.bas"},
+		{"bob-session", "/home/bobby/f.bas"},
+	} {
+		if reply := messageText(os.cmdRm([]string{tc.session, tc.path})); !strings.Contains(reply, "No permission") {
+			t.Errorf("rm %s as %s answered %q", tc.path, tc.session, reply)
+		}
+	}
+	for _, p := range []string{"/home/alicex/f.bas", "/home/bobby/f.bas", "/home/bob/secret.bas"} {
+		if !os.Vfs.Exists(p, "") {
+			t.Errorf("%s was deleted by another user", p)
+		}
+	}
+
+	for _, tc := range []struct{ session, path string }{
+		{"alice-session", "/home/bob/planted"},
+		{"alice-session", "../bob/planted"},
+		{"alice-session", "/system/planted"},
+		{"alice-session", "/planted"},
+		{"alice-session", "/home/alicex/planted"},
+		{"bob-session", "/home/bobby/planted"},
+	} {
+		if reply := messageText(os.cmdMkdir([]string{tc.session, tc.path})); !strings.Contains(reply, "permission denied") {
+			t.Errorf("mkdir %s as %s answered %q", tc.path, tc.session, reply)
+		}
+	}
+	for _, p := range []string{"/home/bob/planted", "/system/planted", "/planted", "/home/alicex/planted", "/home/bobby/planted"} {
+		if os.Vfs.IsDir(p) {
+			t.Errorf("%s was created outside the own home", p)
+		}
+	}
+
+	// The own home still works
+	os.cmdMkdir([]string{"alice-session", "games"})
+	if !os.Vfs.IsDir("/home/alice/games") {
+		t.Error("mkdir in the own home failed")
+	}
+	os.cmdRm([]string{"alice-session", "mine.bas"})
+	if os.Vfs.Exists("/home/alice/mine.bas", "") {
+		t.Error("rm in the own home failed")
+	}
+}
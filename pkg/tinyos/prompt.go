@@ -0,0 +1,144 @@
+package tinyos
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/antibyte/retroterm/pkg/logger"
+	"github.com/antibyte/retroterm/pkg/shared"
+)
+
+// promptPreferenceKey is the user_preferences key holding the prompt template
+const promptPreferenceKey = "prompt"
+
+// Prompt templates: logged-in users get defaultUserPrompt, guests keep the
+// simple prompt until they set their own
+const (
+	defaultUserPrompt  = `\u:\w> `
+	defaultGuestPrompt = "> "
+)
+
+// maxPromptLength limits the length of a prompt template
+const maxPromptLength = 40
+
+// renderPrompt expands the placeholders of a prompt template: \u is the
+// username, \w the current path with the home directory shown as ~, \t the
+// time as HH:MM and \\ a backslash. Unknown placeholders stay as they are.
+func renderPrompt(template, username, currentPath string, now time.Time) string {
+	var sb strings.Builder
+	for i := 0; i < len(template); i++ {
+		if template[i] != '\\' || i+1 == len(template) {
+			sb.WriteByte(template[i])
+			continue
+		}
+		switch template[i+1] {
+		case 'u':
+			sb.WriteString(username)
+		case 'w':
+			sb.WriteString(shortenHome(currentPath, username))
+		case 't':
+			sb.WriteString(now.Format("15:04"))
+		case '\\':
+			sb.WriteByte('\\')
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte(template[i+1])
+		}
+		i++
+	}
+	return sb.String()
+}
+
+// shortenHome shows the home directory of username in a path as ~
+func shortenHome(currentPath, username string) string {
+	if username == "" {
+		return currentPath
+	}
+	home := "/home/" + username
+	if currentPath == home {
+		return "~"
+	}
+	if strings.HasPrefix(currentPath, home+"/") {
+		return "~" + strings.TrimPrefix(currentPath, home)
+	}
+	return currentPath
+}
+
+// promptTemplate returns the prompt template of a session: the one set in
+// the session, the saved one of a logged-in user or the default
+func (os *TinyOS) promptTemplate(sessionID string) string {
+	os.promptMutex.Lock()
+	template, cached := os.promptTemplates[sessionID]
+	os.promptMutex.Unlock()
+	if cached {
+		return template
+	}
+
+	template = defaultGuestPrompt
+	if sessionID != "" && !os.isGuestSession(sessionID) {
+		if username := os.GetUsernameForSession(sessionID); username != "" {
+			template = defaultUserPrompt
+			if value, err := os.getUserPreference(username, promptPreferenceKey); err == nil && value != "" {
+				template = value
+			}
+		}
+	}
+
+	os.promptMutex.Lock()
+	os.promptTemplates[sessionID] = template
+	os.promptMutex.Unlock()
+	return template
+}
+
+// forgetPrompt drops the cached prompt template, e.g. when the user of a session changes
+func (os *TinyOS) forgetPrompt(sessionID string) {
+	os.promptMutex.Lock()
+	delete(os.promptTemplates, sessionID)
+	os.promptMutex.Unlock()
+}
+
+// setPrompt handles "set prompt <template>" and "set prompt default". The
+// shell splits input at spaces, so the words are joined with single spaces
+// and the prompt always ends in one.
+func (os *TinyOS) setPrompt(sessionID string, args []string) []shared.Message {
+	if len(args) == 0 {
+		return os.CreateWrappedTextMessage(sessionID, "prompt = "+strings.TrimSuffix(os.promptTemplate(sessionID), " "))
+	}
+
+	isGuest := os.isGuestSession(sessionID)
+	username := os.GetUsernameForSession(sessionID)
+	if !isGuest && username == "" {
+		return os.CreateWrappedTextMessage(sessionID, "Error: Invalid session")
+	}
+
+	template, saved := strings.Join(args, " ")+" ", ""
+	if len(args) == 1 && strings.EqualFold(args[0], "default") {
+		template = defaultUserPrompt
+		if isGuest {
+			template = defaultGuestPrompt
+		}
+	} else {
+		if len(template) > maxPromptLength {
+			return os.CreateWrappedTextMessage(sessionID, fmt.Sprintf("set: the prompt must not be longer than %d characters.", maxPromptLength))
+		}
+		saved = template
+	}
+
+	note := ""
+	if isGuest {
+		if saved != "" {
+			note = " Log in to keep it."
+		}
+	} else if err := os.setUserPreference(username, promptPreferenceKey, saved); err != nil {
+		logger.Error(logger.AreaDatabase, "Failed to save the prompt for %s: %v", username, err)
+		note = " (not saved)"
+	}
+
+	os.promptMutex.Lock()
+	os.promptTemplates[sessionID] = template
+	os.promptMutex.Unlock()
+
+	return append(os.CreateWrappedTextMessage(sessionID, "Prompt set."+note),
+		shared.Message{Type: shared.MessageTypePrompt, PromptSymbol: os.GetPromptForSession(sessionID)})
+}
@@ -0,0 +1,25 @@
+package tinyos
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderPrompt(t *testing.T) {
+	now := time.Date(2024, 5, 1, 9, 7, 0, 0, time.UTC)
+	tests := []struct {
+		template, path, want string
+	}{
+		{`\u:\w> `, "/home/alice", "alice:~> "},
+		{`\u:\w> `, "/home/alice/basic", "alice:~/basic> "},
+		{`\w> `, "/home/alicebob", "/home/alicebob> "},
+		{`[\t] `, "/", "[09:07] "},
+		{`\x\\\u> `, "/", `\x\alice> `},
+		{`end\`, "/", `end\`},
+	}
+	for _, tt := range tests {
+		if got := renderPrompt(tt.template, "alice", tt.path, now); got != tt.want {
+			t.Errorf("renderPrompt(%q, %q) = %q, want %q", tt.template, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package tinyos
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newRecoveryTestOS returns a TinyOS with a guest session, alice with a
+// security question and bob without one
+func newRecoveryTestOS(t *testing.T) *TinyOS {
+	t.Helper()
+	os := newDBTestOS(t, map[string]string{"guest-session": "guest", "alice-session": "alice"})
+	addTestUser(t, os, "alice", false)
+	addTestUser(t, os, "bob", false)
+	hash, err := hashRecoveryAnswer("Commodore 64")
+	if err != nil {
+		t.Fatalf("hashRecoveryAnswer: %v", err)
+	}
+	if err := os.storeRecovery("alice", recoveryQuestions[1], hash); err != nil {
+		t.Fatalf("storeRecovery: %v", err)
+	}
+	return os
+}
+
+// recoverAs runs recover in the guest session with the given inputs and
+// returns the output of each step
+func recoverAs(os *TinyOS, inputs ...string) []string {
+	outputs := []string{messageText(os.cmdRecover([]string{"guest-session"}))}
+	for _, input := range inputs {
+		outputs = append(outputs, messageText(os.handleRecoveryInput(input, "guest-session")))
+	}
+	os.endRecovery("guest-session")
+	return outputs
+}
+
+// TestRecoverDoesNotRevealAccounts checks that an account with a question,
+// one without and an unknown name give the same output up to the answer
+func TestRecoverDoesNotRevealAccounts(t *testing.T) {
+	os := newRecoveryTestOS(t)
+	want := recoverAs(os, "alice", "2")
+	for _, username := range []string{"bob", "nobody"} {
+		got := recoverAs(os, username, "2")
+		if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("recover for %s shows %q, for alice %q", username, got, want)
+		}
+	}
+	if !strings.Contains(want[1], recoveryQuestions[4]) {
+		t.Errorf("recover does not list the questions: %q", want[1])
+	}
+}
+
+// TestRecoverNeedsQuestionAndAnswer checks that the right answer to the wrong
+// question fails and counts, and that the right pair is accepted
+func TestRecoverNeedsQuestionAndAnswer(t *testing.T) {
+	os := newRecoveryTestOS(t)
+	if out := recoverAs(os, "alice", "7", "1", "commodore 64"); !strings.Contains(out[2], "number from 1 to") || !strings.Contains(out[4], "not correct") {
+		t.Errorf("wrong question: %q", out)
+	}
+	if tracker := os.failedLoginAttempts["192.0.2.1"]; tracker == nil || tracker.FailedAttempts != 1 {
+		t.Errorf("the wrong question was not counted as a failed login: %+v", tracker)
+	}
+	if out := recoverAs(os, "alice", "2", "  COMMODORE   64 "); !strings.Contains(out[3], "Answer accepted") {
+		t.Errorf("right question and answer: %q", out)
+	}
+}
+
+// TestSetRecoveryCountsWrongPasswords checks that wrong passwords in
+// setrecovery lead to the login lockout
+func TestSetRecoveryCountsWrongPasswords(t *testing.T) {
+	os := newRecoveryTestOS(t)
+	for i := 0; i < 5; i++ {
+		os.cmdSetRecovery([]string{"alice-session"})
+		if out := messageText(os.handleRecoveryInput("wrong"+strconv.Itoa(i), "alice-session")); !strings.Contains(out, "incorrect") {
+			t.Fatalf("attempt %d: %q", i, out)
+		}
+	}
+	if out := messageText(os.cmdSetRecovery([]string{"alice-session"})); !strings.Contains(out, "too many failed attempts") {
+		t.Errorf("setrecovery after 5 wrong passwords: %q", out)
+	}
+}
@@ -0,0 +1,147 @@
+package tinyos
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// addTestFile inserts a virtual_files row
+func addTestFile(t *testing.T, os *TinyOS, username, path string) {
+	t.Helper()
+	if _, err := os.db.Exec("INSERT INTO virtual_files (username, path, content, is_dir, mod_time) VALUES (?, ?, '', 0, ?)",
+		username, path, time.Now().Unix()); err != nil {
+		t.Fatalf("adding file %s: %v", path, err)
+	}
+}
+
+// countRows counts the rows a query finds
+func countRows(t *testing.T, os *TinyOS, query string, args ...interface{}) int {
+	t.Helper()
+	var n int
+	if err := os.db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return n
+}
+
+// TestRenameUserInDBMovesRows renames alice and checks the user row, files,
+// mail and preferences while other users stay untouched
+func TestRenameUserInDBMovesRows(t *testing.T) {
+	os := newDBTestOS(t, nil)
+	addTestUser(t, os, "alice", false)
+	addTestUser(t, os, "bob", false)
+	addTestFile(t, os, "alice", "/home/alice")
+	addTestFile(t, os, "alice", "/home/alice/games/demo.bas")
+	addTestFile(t, os, "bob", "/home/bob/demo.bas")
+	if _, err := os.db.Exec("INSERT INTO user_mail (sender, recipient, subject, body, sent_at) VALUES ('alice', 'bob', 's', 'b', 0), ('bob', 'alice', 's', 'b', 0)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.setUserPreference("alice", themePreferenceKey, "amber"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.renameUserInDB("alice", "carol"); err != nil {
+		t.Fatalf("renameUserInDB: %v", err)
+	}
+
+	if n := countRows(t, os, "SELECT COUNT(*) FROM users WHERE username = 'alice'"); n != 0 {
+		t.Error("alice is still registered")
+	}
+	if n := countRows(t, os, "SELECT COUNT(*) FROM users WHERE username IN ('carol', 'bob')"); n != 2 {
+		t.Errorf("%d of carol and bob registered, want 2", n)
+	}
+	for _, path := range []string{"/home/carol", "/home/carol/games/demo.bas"} {
+		if n := countRows(t, os, "SELECT COUNT(*) FROM virtual_files WHERE username = 'carol' AND path = ?", path); n != 1 {
+			t.Errorf("%s not found after the rename", path)
+		}
+	}
+	if n := countRows(t, os, "SELECT COUNT(*) FROM virtual_files WHERE username = 'bob' AND path = '/home/bob/demo.bas'"); n != 1 {
+		t.Error("bob's file was changed")
+	}
+	if n := countRows(t, os, "SELECT COUNT(*) FROM user_mail WHERE sender = 'carol' OR recipient = 'carol'"); n != 2 {
+		t.Errorf("%d mails moved to carol, want 2", n)
+	}
+	if theme, _ := os.getUserPreference("carol", themePreferenceKey); theme != "amber" {
+		t.Errorf("theme of carol = %q, want amber", theme)
+	}
+}
+
+// TestRenameUserInDBKeepsOldNameOnFailure makes an update in the middle of
+// the transaction fail and expects nothing to have changed
+func TestRenameUserInDBKeepsOldNameOnFailure(t *testing.T) {
+	os := newDBTestOS(t, nil)
+	addTestUser(t, os, "alice", false)
+	addTestUser(t, os, "bob", false)
+	addTestFile(t, os, "alice", "/home/alice/demo.bas")
+
+	if err := os.renameUserInDB("alice", "bob"); !errors.Is(err, errUsernameTaken) {
+		t.Errorf("rename to an existing user returned %v, want errUsernameTaken", err)
+	}
+
+	if _, err := os.db.Exec("INSERT INTO chat_usage (username, date, time_used, last_session_start) VALUES ('alice', '2026-01-01', 0, 0)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.db.Exec("CREATE TRIGGER fail_rename BEFORE UPDATE ON chat_usage BEGIN SELECT RAISE(ABORT, 'broken'); END"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.renameUserInDB("alice", "carol"); err == nil {
+		t.Fatal("rename succeeded although an update failed")
+	}
+	if n := countRows(t, os, "SELECT COUNT(*) FROM users WHERE username = 'alice'"); n != 1 {
+		t.Error("alice lost the account after a failed rename")
+	}
+	if n := countRows(t, os, "SELECT COUNT(*) FROM virtual_files WHERE username = 'alice' AND path = '/home/alice/demo.bas'"); n != 1 {
+		t.Error("alice's files moved although the rename failed")
+	}
+}
+
+// TestRenameRejectsNames checks the names cmdRename refuses before it asks
+// for the password
+func TestRenameRejectsNames(t *testing.T) {
+	os := newDBTestOS(t, map[string]string{"a": "alice", "d": "dyson"})
+	addTestUser(t, os, "alice", false)
+	addTestUser(t, os, "bob", false)
+
+	for _, tc := range []struct {
+		session, name, want string
+	}{
+		{"a", "guest", "reserved"},
+		{"a", "dyson", "reserved"},
+		{"a", "Root", "reserved"},
+		{"a", "bob", "already taken"},
+		{"a", "alice", "already alice"},
+		{"a", "no/slash", "may only contain"},
+		{"d", "newdyson", "permission denied"},
+	} {
+		reply := messageText(os.cmdRename([]string{tc.session, tc.name}))
+		if !strings.Contains(reply, tc.want) {
+			t.Errorf("rename %s as %s answered %q, want %q", tc.name, os.GetUsernameForSession(tc.session), reply, tc.want)
+		}
+		if os.isInRenameProcess(tc.session) {
+			t.Errorf("rename %s asks for the password", tc.name)
+			os.handleRenameInput("__BREAK__", tc.session)
+		}
+	}
+}
+
+// TestRenameUserSessions moves the sessions of the renamed user, including
+// working directories below the old home
+func TestRenameUserSessions(t *testing.T) {
+	os := newDBTestOS(t, map[string]string{"a1": "alice", "a2": "alice", "b": "bob"})
+	os.sessions["a2"].CurrentPath = "/home/alice/games"
+	os.sessions["b"].CurrentPath = "/home/alice2"
+
+	os.renameUserSessions("alice", "carol")
+
+	if s := os.sessions["a1"]; s.Username != "carol" || s.CurrentPath != "/home/carol" {
+		t.Errorf("session a1 = %s in %s, want carol in /home/carol", s.Username, s.CurrentPath)
+	}
+	if s := os.sessions["a2"]; s.Username != "carol" || s.CurrentPath != "/home/carol/games" {
+		t.Errorf("session a2 = %s in %s, want carol in /home/carol/games", s.Username, s.CurrentPath)
+	}
+	if s := os.sessions["b"]; s.Username != "bob" || s.CurrentPath != "/home/alice2" {
+		t.Errorf("session b = %s in %s, want it unchanged", s.Username, s.CurrentPath)
+	}
+}
@@ -11,6 +11,18 @@ const (
 	// This is a sub-limit of the overall MaxBasicSessions limit
 	MaxGuestBasicSessions = 5
 
+	// DefaultMaxTelnetSessions is the default for [Terminal] telnet_max_sessions,
+	// the number of telnet and connect sessions open at the same time
+	DefaultMaxTelnetSessions = 10
+
+	// DefaultMaxTelnetSessionsPerUser is the default for [Terminal]
+	// telnet_max_sessions_per_user, the sessions one registered user may have open
+	DefaultMaxTelnetSessionsPerUser = 2
+
+	// DefaultMaxGuestTelnetSessions is the default for [Terminal]
+	// telnet_max_guest_sessions, the sessions all guests together may have open
+	DefaultMaxGuestTelnetSessions = 2
+
 	// SessionLimitMessage is the message displayed when the session limit is reached
 	SessionLimitMessage = "Too many active sessions. Try later."
 )
@@ -0,0 +1,63 @@
+package tinyos
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// sidHeader builds a SID header with the given signature, version and data
+// offset, padded to the size of its version
+func sidHeader(format string, version, dataOffset int) []byte {
+	size := sidHeaderSizeV2
+	if version < 2 {
+		size = sidHeaderSizeV1
+	}
+	data := make([]byte, size)
+	copy(data, format)
+	binary.BigEndian.PutUint16(data[0x04:], uint16(version))
+	binary.BigEndian.PutUint16(data[0x06:], uint16(dataOffset))
+	binary.BigEndian.PutUint16(data[0x08:], 0x1000)
+	binary.BigEndian.PutUint16(data[0x0E:], 3)
+	binary.BigEndian.PutUint16(data[0x10:], 1)
+	copy(data[0x16:], "Cr\xe8me  ")
+	copy(data[0x36:], "Rob Hubbard")
+	copy(data[0x56:], "1985 Gremlin")
+	return data
+}
+
+// TestParseSIDHeader checks the fields of valid version 1 and 2 headers
+func TestParseSIDHeader(t *testing.T) {
+	for _, data := range [][]byte{sidHeader("PSID", 1, sidHeaderSizeV1), sidHeader("RSID", 2, sidHeaderSizeV2)} {
+		info, err := ParseSIDHeader(data)
+		if err != nil {
+			t.Fatalf("ParseSIDHeader(%s): %v", data[:4], err)
+		}
+		if info.Name != "Crème" || info.Author != "Rob Hubbard" || info.Released != "1985 Gremlin" ||
+			info.Songs != 3 || info.StartSong != 1 || info.LoadAddress != 0x1000 {
+			t.Errorf("ParseSIDHeader(%s) = %+v", data[:4], info)
+		}
+	}
+}
+
+// TestParseSIDHeaderRejectsBrokenFiles feeds truncated and malformed headers,
+// which must give an error instead of a panic
+func TestParseSIDHeaderRejectsBrokenFiles(t *testing.T) {
+	wrongOffset := sidHeader("PSID", 2, 0x0200)
+	tooShortForV2 := sidHeader("PSID", 2, sidHeaderSizeV2)[:sidHeaderSizeV1]
+	for name, data := range map[string][]byte{
+		"empty":             nil,
+		"three bytes":       []byte("PSI"),
+		"no signature":      append([]byte("MUS1"), make([]byte, sidHeaderSizeV2)...),
+		"truncated":         sidHeader("PSID", 2, sidHeaderSizeV2)[:0x40],
+		"one byte short":    sidHeader("PSID", 1, sidHeaderSizeV1)[:sidHeaderSizeV1-1],
+		"PSID version 0":    sidHeader("PSID", 0, sidHeaderSizeV1),
+		"PSID version 5":    sidHeader("PSID", 5, sidHeaderSizeV2),
+		"RSID version 1":    sidHeader("RSID", 1, sidHeaderSizeV1),
+		"wrong data offset": wrongOffset,
+		"v2 with v1 size":   tooShortForV2,
+	} {
+		if info, err := ParseSIDHeader(data); err == nil {
+			t.Errorf("%s: parsed as %+v, want an error", name, info)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package tinyos
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newSudoTestOS returns a TinyOS with the admin root (password "secret"),
+// the plain user alice and the dyson account
+func newSudoTestOS(t *testing.T) *TinyOS {
+	t.Helper()
+	os := newDBTestOS(t, map[string]string{"r": "root", "a": "alice", "d": "dyson"})
+	addTestUser(t, os, "root", true)
+	addTestUser(t, os, "alice", false)
+	addTestUser(t, os, "dyson", false)
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.db.Exec("UPDATE users SET password = ? WHERE username = 'root'", string(hash)); err != nil {
+		t.Fatal(err)
+	}
+	return os
+}
+
+// sudo runs sudo in a session and answers the password prompt
+func sudo(os *TinyOS, sessionID, password string) string {
+	reply := messageText(os.cmdSudo([]string{sessionID}))
+	if !os.isInSudoPrompt(sessionID) {
+		return reply
+	}
+	return messageText(os.handleSudoInput(password, sessionID))
+}
+
+// TestSudoOnlyForAdmins refuses sudo for plain users and the dyson account
+func TestSudoOnlyForAdmins(t *testing.T) {
+	os := newSudoTestOS(t)
+	for _, sessionID := range []string{"a", "d"} {
+		if reply := sudo(os, sessionID, "x"); !strings.Contains(reply, "admins only") {
+			t.Errorf("sudo in session %s answered %q", sessionID, reply)
+		}
+		if os.isElevatedSession(sessionID) {
+			t.Errorf("session %s is in admin mode", sessionID)
+		}
+	}
+}
+
+// TestSudoGrantsAdminMode checks that admin commands wait for sudo, that a
+// wrong password is counted and that the right one opens admin mode
+func TestSudoGrantsAdminMode(t *testing.T) {
+	os := newSudoTestOS(t)
+	if reply := messageText(os.cmdNews([]string{"r", "post", "Hello"})); !strings.Contains(reply, "admin mode required") {
+		t.Errorf("news post without sudo answered %q", reply)
+	}
+
+	if reply := sudo(os, "r", "wrong"); !strings.Contains(reply, "incorrect password") {
+		t.Errorf("sudo with a wrong password answered %q", reply)
+	}
+	if os.isElevatedSession("r") {
+		t.Error("a wrong password opened admin mode")
+	}
+	if tracker := os.failedLoginAttempts["192.0.2.1"]; tracker == nil || tracker.FailedAttempts != 1 {
+		t.Errorf("the wrong password was not counted as a failed login: %+v", tracker)
+	}
+
+	if reply := sudo(os, "r", "secret"); !strings.Contains(reply, "Admin mode enabled") {
+		t.Fatalf("sudo with the right password answered %q", reply)
+	}
+	if msgs := os.requireElevation("r", "news post"); msgs != nil {
+		t.Errorf("admin command refused in admin mode: %q", messageText(msgs))
+	}
+	if _, exists := os.failedLoginAttempts["192.0.2.1"]; exists {
+		t.Error("the right password did not clear the failed attempts")
+	}
+	if os.isElevatedSession("a") {
+		t.Error("admin mode of root applies to another session")
+	}
+
+	messageText(os.cmdSudo([]string{"r", "-k"}))
+	if os.isElevatedSession("r") {
+		t.Error("sudo -k did not end admin mode")
+	}
+}
+
+// TestAdminModeExpires checks that admin mode ends after its time and when
+// the admin flag is taken away
+func TestAdminModeExpires(t *testing.T) {
+	os := newSudoTestOS(t)
+	sudo(os, "r", "secret")
+
+	os.elevatedUntil["r"] = time.Now().Add(-time.Second)
+	if os.isElevatedSession("r") {
+		t.Error("expired admin mode is still active")
+	}
+	if _, exists := os.elevatedUntil["r"]; exists {
+		t.Error("expired admin mode was not removed")
+	}
+
+	sudo(os, "r", "secret")
+	os.elevatedUntil["r"] = time.Now().Add(-time.Second)
+	os.expireElevations()
+	if _, exists := os.elevatedUntil["r"]; exists {
+		t.Error("expireElevations kept an expired admin mode")
+	}
+
+	sudo(os, "r", "secret")
+	if _, err := os.db.Exec("UPDATE users SET is_admin = 0 WHERE username = 'root'"); err != nil {
+		t.Fatal(err)
+	}
+	if os.isElevatedSession("r") {
+		t.Error("admin mode outlived the admin flag")
+	}
+}
+
+// TestSecretsNeedAdminMode checks that ENVIRON$, env and whois only show
+// secrets and IP addresses to an admin in admin mode
+func TestSecretsNeedAdminMode(t *testing.T) {
+	os := newSudoTestOS(t)
+	os.systemEnv = map[string]string{"DEEPSEEK_API_KEY": "sk-secret", "MOTD": "hello"}
+	if _, err := os.db.Exec("UPDATE users SET ip_address = '198.51.100.7' WHERE username = 'alice'"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, when := range []string{"without sudo", "in admin mode"} {
+		elevated := when == "in admin mode"
+		if elevated {
+			sudo(os, "r", "secret")
+		}
+		if got := os.LookupEnv("r", "DEEPSEEK_API_KEY"); (got == "sk-secret") != elevated {
+			t.Errorf("ENVIRON$ of the secret %s = %q", when, got)
+		}
+		if got := os.LookupEnv("r", "MOTD"); got != "hello" {
+			t.Errorf("ENVIRON$ of a plain variable %s = %q", when, got)
+		}
+		if env := messageText(os.cmdEnv([]string{"r"})); strings.Contains(env, "DEEPSEEK_API_KEY") != elevated {
+			t.Errorf("env %s lists %q", when, env)
+		}
+		if whois := messageText(os.cmdWhois([]string{"r", "alice"})); strings.Contains(whois, "198.51.100.7") != elevated {
+			t.Errorf("whois %s shows %q", when, whois)
+		}
+	}
+	if got := os.LookupEnv("a", "DEEPSEEK_API_KEY"); got != "" {
+		t.Errorf("ENVIRON$ of a plain user = %q", got)
+	}
+}
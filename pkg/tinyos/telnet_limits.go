@@ -0,0 +1,86 @@
+package tinyos
+
+import (
+	"fmt"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+	"github.com/antibyte/retroterm/pkg/logger"
+)
+
+// telnetOwner is who a telnet slot counts against
+type telnetOwner struct {
+	Username string
+	Guest    bool
+}
+
+// telnetLimits returns the configured limits for all sessions, for one
+// registered user and for all guests together. Values below 1 fall back to
+// the defaults; the guest limit is never looser than the others.
+func telnetLimits() (total, perUser, guests int) {
+	total = configuration.GetInt("Terminal", "telnet_max_sessions", DefaultMaxTelnetSessions)
+	if total < 1 {
+		total = DefaultMaxTelnetSessions
+	}
+	perUser = configuration.GetInt("Terminal", "telnet_max_sessions_per_user", DefaultMaxTelnetSessionsPerUser)
+	if perUser < 1 {
+		perUser = DefaultMaxTelnetSessionsPerUser
+	}
+	guests = configuration.GetInt("Terminal", "telnet_max_guest_sessions", DefaultMaxGuestTelnetSessions)
+	if guests < 1 {
+		guests = DefaultMaxGuestTelnetSessions
+	}
+	return total, min(perUser, total), min(guests, perUser, total)
+}
+
+// reserveTelnetSlot takes one of the telnet slots for sessionID before the
+// connection is dialled, so parallel starts cannot get past the limits. It
+// returns the message for the user if a limit is reached. The slot is either
+// taken over by the telnet state or given back with releaseTelnetSlot.
+func (os *TinyOS) reserveTelnetSlot(sessionID string) string {
+	owner := telnetOwner{Username: os.GetUsernameForSession(sessionID), Guest: os.isGuestSession(sessionID)}
+	maxTotal, maxPerUser, maxGuests := telnetLimits()
+
+	os.telnetMutex.Lock()
+	defer os.telnetMutex.Unlock()
+
+	if _, pending := os.telnetPending[sessionID]; pending {
+		return "Already connecting. Please wait."
+	}
+
+	total, sameUser, guests := len(os.telnetStates)+len(os.telnetPending), 0, 0
+	count := func(o telnetOwner) {
+		if o.Guest {
+			guests++
+		} else if o.Username == owner.Username {
+			sameUser++
+		}
+	}
+	for _, state := range os.telnetStates {
+		count(state.owner)
+	}
+	for _, o := range os.telnetPending {
+		count(o)
+	}
+
+	switch {
+	case total >= maxTotal:
+		logger.Warn(logger.AreaTerminal, "cmdTelnet: connection limit reached (%d/%d), denying session %s", total, maxTotal, sessionID)
+		return "Telnet connection limit reached. Please try again later."
+	case owner.Guest && guests >= maxGuests:
+		logger.Warn(logger.AreaTerminal, "cmdTelnet: guest connection limit reached (%d/%d), denying session %s", guests, maxGuests, sessionID)
+		return fmt.Sprintf("All %d telnet connections for guests are in use. Please try again later or log in.", maxGuests)
+	case !owner.Guest && sameUser >= maxPerUser:
+		logger.Warn(logger.AreaTerminal, "cmdTelnet: user %s has %d/%d connections, denying session %s", owner.Username, sameUser, maxPerUser, sessionID)
+		return fmt.Sprintf("You already have %d telnet connections open (limit %d). Close one first.", sameUser, maxPerUser)
+	}
+
+	os.telnetPending[sessionID] = owner
+	return ""
+}
+
+// releaseTelnetSlot gives back a slot whose connection was not established
+func (os *TinyOS) releaseTelnetSlot(sessionID string) {
+	os.telnetMutex.Lock()
+	defer os.telnetMutex.Unlock()
+	delete(os.telnetPending, sessionID)
+}
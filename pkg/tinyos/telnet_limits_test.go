@@ -0,0 +1,91 @@
+package tinyos
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// newTelnetLimitTestOS returns a TinyOS without database with the given
+// sessions, mapped from session ID to username
+func newTelnetLimitTestOS(users map[string]string) *TinyOS {
+	os := &TinyOS{
+		sessions:      make(map[string]*Session),
+		telnetStates:  make(map[string]*TelnetState),
+		telnetPending: make(map[string]telnetOwner),
+	}
+	for id, username := range users {
+		os.sessions[id] = &Session{ID: id, Username: username}
+	}
+	return os
+}
+
+// connectForTest reserves a slot and turns it into a telnet state like
+// startRemoteSession does after a successful dial
+func (os *TinyOS) connectForTest(sessionID string) string {
+	if refusal := os.reserveTelnetSlot(sessionID); refusal != "" {
+		return refusal
+	}
+	os.telnetMutex.Lock()
+	defer os.telnetMutex.Unlock()
+	os.telnetStates[sessionID] = &TelnetState{SessionID: sessionID, owner: os.telnetPending[sessionID]}
+	delete(os.telnetPending, sessionID)
+	return ""
+}
+
+// TestTelnetLimitPerUser checks the per-user limit, which other users and
+// closed connections do not touch
+func TestTelnetLimitPerUser(t *testing.T) {
+	os := newTelnetLimitTestOS(map[string]string{"a1": "alice", "a2": "alice", "a3": "alice", "b1": "bob"})
+	for _, id := range []string{"a1", "a2", "b1"} {
+		if refusal := os.connectForTest(id); refusal != "" {
+			t.Fatalf("session %s refused: %s", id, refusal)
+		}
+	}
+	if refusal := os.connectForTest("a3"); !strings.Contains(refusal, fmt.Sprintf("limit %d", DefaultMaxTelnetSessionsPerUser)) {
+		t.Errorf("third session of alice: %q, want the per-user limit", refusal)
+	}
+
+	os.telnetMutex.Lock()
+	delete(os.telnetStates, "a1")
+	os.telnetMutex.Unlock()
+	if refusal := os.connectForTest("a3"); refusal != "" {
+		t.Errorf("closing a connection did not free the slot: %s", refusal)
+	}
+}
+
+// TestTelnetLimitGuestsAndTotal checks that guests share a smaller pool,
+// that pending connections count and that a released slot is free again
+func TestTelnetLimitGuestsAndTotal(t *testing.T) {
+	users := make(map[string]string)
+	for i := 0; i < DefaultMaxTelnetSessions+1; i++ {
+		users[fmt.Sprintf("g%d", i)] = "guest"
+		users[fmt.Sprintf("u%d", i)] = fmt.Sprintf("user%d", i)
+	}
+	os := newTelnetLimitTestOS(users)
+
+	for i := 0; i < DefaultMaxGuestTelnetSessions; i++ {
+		if refusal := os.reserveTelnetSlot(fmt.Sprintf("g%d", i)); refusal != "" {
+			t.Fatalf("guest %d refused: %s", i, refusal)
+		}
+	}
+	if refusal := os.reserveTelnetSlot("g9"); !strings.Contains(refusal, "for guests") {
+		t.Errorf("guest over the limit: %q, want the guest limit", refusal)
+	}
+	if refusal := os.reserveTelnetSlot("g0"); refusal == "" {
+		t.Errorf("a second reservation for a connecting session was accepted")
+	}
+	os.releaseTelnetSlot("g0")
+	if refusal := os.reserveTelnetSlot("g9"); refusal != "" {
+		t.Errorf("released guest slot still taken: %s", refusal)
+	}
+
+	for i := 0; i < DefaultMaxTelnetSessions-DefaultMaxGuestTelnetSessions; i++ {
+		if refusal := os.connectForTest(fmt.Sprintf("u%d", i)); refusal != "" {
+			t.Fatalf("user %d refused: %s", i, refusal)
+		}
+	}
+	if refusal := os.connectForTest(fmt.Sprintf("u%d", DefaultMaxTelnetSessions)); !strings.Contains(refusal, "limit reached") {
+		t.Errorf("connection over the total: %q, want the server limit", refusal)
+	}
+}
@@ -70,6 +70,8 @@ type ChatMessage struct {
 	Role    string    `json:"role"`    // "user" oder "assistant"
 	Content string    `json:"content"` // Der Nachrichteninhalt
 	Time    time.Time `json:"time"`    // Zeitpunkt der Nachricht
+	// Abgebrochene oder unterbrochene Antwort, Content ist nur der empfangene Teil
+	Incomplete bool `json:"incomplete,omitempty"`
 }
 
 // Session repräsentiert eine Benutzersitzung
@@ -119,10 +121,10 @@ type TinyOS struct {
 	sessionTerminals map[string]*TerminalDimensions // Map von Session-IDs zu Terminal-Dimensionen
 
 	// BASIC Session-Tracking für Session-Limits
-	activeBasicSessions map[string]bool // Set von SessionIDs mit aktiven BASIC-Sitzungen
-	basicSessionMutex   sync.RWMutex    // Mutex für Thread-sicheren Zugriff auf BASIC-Sitzungen
-	cols                int             // Terminal-Breite in Spalten
-	rows                int             // Terminal-Höhe in Zeilen
+	activeBasicSessions map[string]time.Time // SessionID -> letzte Eingabe in der BASIC-Sitzung
+	basicSessionMutex   sync.RWMutex         // Mutex für Thread-sicheren Zugriff auf BASIC-Sitzungen
+	cols                int                  // Terminal-Breite in Spalten
+	rows                int                  // Terminal-Höhe in Zeilen
 
 	// Login process tracking
 	loginStates        map[string]*LoginState        // Map of session IDs to login status
@@ -132,17 +134,66 @@ type TinyOS struct {
 	// Password change process tracking
 	passwordChangeStates map[string]*PasswordChangeState // Map of session IDs to password change status
 	passwordChangeMutex  sync.RWMutex                    // Mutex for thread-safe access to password change status
+	// Password recovery and security question setup
+	recoveryStates map[string]*RecoveryState // Map of session IDs to recover/setrecovery status
+	recoveryMutex  sync.RWMutex              // Mutex for thread-safe access to recovery status
+	// sudo prompts and admin mode
+	sudoStates    map[string]*SudoState // Map of session IDs to pending sudo password prompts
+	elevatedUntil map[string]time.Time  // Map of session IDs to the end of their admin mode
+	sudoMutex     sync.Mutex            // Mutex for sudoStates and elevatedUntil
+	// Held while VACUUM runs, a second vacuum is refused
+	vacuumMutex sync.Mutex
+	// Login and chat counters for the metrics endpoint, updated without a lock
+	counters serverCounters
+	// Time the server was started, see SetStartTime
+	startTime time.Time
+	// Mail compose process tracking
+	mailComposeStates map[string]*MailComposeState // Map of session IDs to mail compose status
+	mailComposeMutex  sync.RWMutex                 // Mutex for thread-safe access to mail compose status
+	// News entries being entered by admins
+	newsPostStates map[string]*NewsPostState // Map of session IDs to news entries being entered
+	newsPostMutex  sync.RWMutex              // Mutex for thread-safe access to news entries being entered
+	// Rename process tracking
+	renameStates map[string]*RenameState // Map of session IDs to pending renames
+	renameMutex  sync.RWMutex            // Mutex for thread-safe access to pending renames
+	// Export/import waiting for the overwrite confirmation
+	libraryConfirmStates map[string]*LibraryConfirmState // Map of session IDs to pending exports/imports
+	libraryConfirmMutex  sync.RWMutex                    // Mutex for thread-safe access to pending exports/imports
+	// Sound effect settings per session
+	sfxVolumes  map[string]int       // Map of session IDs to SFX volume (0 = muted)
+	lastDiskSFX map[string]time.Time // Last drive sound per session, limits the rate of disk effects
+	sfxMutex    sync.Mutex           // Mutex for sfxVolumes and lastDiskSFX
+	// Prompt templates per session
+	promptTemplates map[string]string // Map of session IDs to the prompt template
+	promptMutex     sync.Mutex        // Mutex for promptTemplates
+	// Values saved with SAVEVAR by guests, and by users without database
+	basicVars basicVarStore
+	// Background refresh of the top view
+	topRefresh map[string]chan struct{} // Map of session IDs to the stop channel of a running refresh
+	topMutex   sync.Mutex               // Mutex for topRefresh
+	// Download history for the fetch rate limit
+	fetchTimes map[string][]time.Time // Map of usernames to the times of their recent fetches
+	fetchMutex sync.Mutex             // Mutex for fetchTimes
+	// Open session transfer codes
+	transferCodes map[string]*transferCode // Map of codes to the session they move
+	transferMutex sync.Mutex               // Mutex for transferCodes
 	// CAT pager process tracking
 	catPagerStates map[string]*CatPagerState // Map von Session-IDs zu CAT-Pager-Status
 	catPagerMutex  sync.RWMutex              // Mutex für Thread-sicheren Zugriff auf CAT-Pager-Status
 	// Telnet session tracking
-	telnetStates map[string]*TelnetState // Map von Session-IDs zu Telnet-Status
-	telnetMutex  sync.RWMutex            // Mutex für Thread-sicheren Zugriff auf Telnet-Status
+	telnetStates  map[string]*TelnetState // Map von Session-IDs zu Telnet-Status
+	telnetPending map[string]telnetOwner  // Reservierte Plätze von Verbindungen, die noch aufgebaut werden
+	telnetMutex   sync.RWMutex            // Mutex für Thread-sicheren Zugriff auf Telnet-Status
 
 	// Failed login attempt tracking
 	failedLoginAttempts map[string]*LoginAttemptTracker // Map von IP-Adressen zu Login-Versuch-Tracking
 	loginAttemptMutex   sync.RWMutex                    // Mutex für Thread-sicheren Zugriff auf Login-Versuche
 
+	// Terminal connections per IP address (see ip_connections.go)
+	ipConnections     map[string]int      // Map of IP addresses to their open connections
+	connectionIPs     map[string][]string // Map of session IDs to the IP of each counted connection
+	ipConnectionMutex sync.Mutex          // Mutex for ipConnections and connectionIPs
+
 	// Shutdown channel for telnet output processor
 	telnetOutputShutdown chan bool
 
@@ -150,8 +201,19 @@ type TinyOS struct {
 	boardManager  *board.BoardManager
 	boardSessions map[string]*BoardSession
 
+	// Cached example gallery listing (see ListExamples)
+	examplesCache      []ExampleInfo
+	examplesCacheTime  time.Time
+	examplesCacheMutex sync.Mutex
+
 	// Callback function for sending messages to clients
 	SendToClientCallback func(sessionID string, message shared.Message) error
+
+	// Called by reboot to release the BASIC interpreter of a session; returns the boot banner
+	RebootCallback func(sessionID string) []shared.Message
+
+	// Called by kill to stop the BASIC program of a session; reports whether one was running
+	StopProgramCallback func(sessionID string) bool
 }
 
 // LoginState stores the status of a multi-step login process
@@ -164,11 +226,13 @@ type LoginState struct {
 
 // RegistrationState speichert den Status eines mehrstufigen Registrierungsprozesses
 type RegistrationState struct {
-	Stage     string    // "username", "password", "confirm_password"
-	Username  string    // Zwischengespeicherter Benutzername
-	Password  string    // Zwischengespeichertes Passwort
-	IPAddress string    // IP-Adresse für die Registrierung
-	CreatedAt time.Time // Zeitpunkt der Registrierungsinitiierung
+	Stage              string    // "username", "password", "confirm_password", "recovery_question", "recovery_answer"
+	Username           string    // Zwischengespeicherter Benutzername
+	Password           string    // Zwischengespeichertes Passwort
+	RecoveryQuestion   string    // Optional security question for recover
+	RecoveryAnswerHash []byte    // bcrypt hash of the normalized answer
+	IPAddress          string    // IP-Adresse für die Registrierung
+	CreatedAt          time.Time // Zeitpunkt der Registrierungsinitiierung
 }
 
 // PasswordChangeState stores the state of a multi-step password change process
@@ -180,14 +244,27 @@ type PasswordChangeState struct {
 	CreatedAt       time.Time // Time when password change was initiated
 }
 
+// MailComposeState stores a mail message while its body is being entered
+type MailComposeState struct {
+	Sender    string    // Authenticated sender taken from the session
+	Recipient string    // Recipient username
+	Subject   string    // Subject line
+	Body      []string  // Body lines entered so far
+	CreatedAt time.Time // Time when composing was started
+}
+
 // CatPagerState speichert den Status eines CAT-Pager-Prozesses
 type CatPagerState struct {
-	Lines       []string           // Alle Zeilen der Datei
+	Lines       []string           // Alle Zeilen, beim Streamen nur die gepufferten ab Zeile Base
+	Base        int                // Nummer der ersten Zeile in Lines (0-basiert)
+	LineEnds    []int              // Beim Streamen: Dateiposition hinter jeder Zeile in Lines
+	Stream      *catStream         // Quelle weiterer Zeilen, nil wenn Lines den ganzen Inhalt enthält
 	CurrentLine int                // Aktuelle Zeile (0-basiert)
 	PageSize    int                // Anzahl der Zeilen pro Seite
 	Filename    string             // Name der angezeigten Datei
 	CreatedAt   time.Time          // Zeitpunkt der Pager-Initiierung
 	Terminal    TerminalDimensions // Terminal dimensions for proper status line formatting
+	LastSearch  string             // Letztes Suchmuster für "/" ohne Text
 }
 
 // TerminalDimensions speichert die Terminal-Abmessungen für eine Session
@@ -207,6 +284,7 @@ type RateLimit struct {
 type TelnetState struct {
 	ServerName    string              // Name of the connected server
 	ServerHost    string              // Host and port of the server
+	Mode          ConnectionMode      // Telnet with option negotiation or raw TCP stream
 	Connection    net.Conn            // The actual TCP connection
 	SessionID     string              // Session ID of the user
 	CreatedAt     time.Time           // Time when telnet was initiated
@@ -217,13 +295,23 @@ type TelnetState struct {
 	LocalEcho     bool                // Whether we should echo locally
 	channelClosed bool                // Flag to track if OutputChan is closed
 	channelMutex  sync.RWMutex        // Mutex to protect channel operations
+	owner         telnetOwner         // User the connection counts against for the limits
 }
 
+// ConnectionMode selects how a remote connection is handled
+type ConnectionMode string
+
+const (
+	ConnectionModeTelnet ConnectionMode = "telnet" // Telnet with IAC option negotiation
+	ConnectionModeRaw    ConnectionMode = "raw"    // Plain TCP stream without negotiation
+)
+
 // LoginAttemptTracker tracks failed login attempts for an IP address
 type LoginAttemptTracker struct {
-	FailedAttempts int       // Number of failed attempts
-	LastAttempt    time.Time // Time of last failed attempt
-	LockedUntil    time.Time // Time until which login is locked (zero if not locked)
+	FailedAttempts int           // Number of failed attempts
+	LastAttempt    time.Time     // Time of last failed attempt
+	LockedUntil    time.Time     // Time until which login is locked (zero if not locked)
+	Lockout        time.Duration // Duration of the current lockout, grows with every further failure
 }
 
 // NewTinyOS erstellt eine neue Instanz von TinyOS
@@ -237,21 +325,38 @@ func NewTinyOS(vfs *virtualfs.VFS, promptManager *shared.PromptManager) *TinyOS
 		deepSeekHistory:       make([]map[string]string, 0), chatRateLimits: make(map[string]*RateLimit),
 		bannedUsers: make(map[string]time.Time), sessions: make(map[string]*Session), // Initialisiere die Sessions-Map
 		sessionTerminals:     make(map[string]*TerminalDimensions),  // Initialisiere die Terminal-Dimensionen-Map
-		activeBasicSessions:  make(map[string]bool),                 // Initialisiere das Set für aktive BASIC-Sitzungen
+		activeBasicSessions:  make(map[string]time.Time),            // Aktive BASIC-Sitzungen mit letzter Aktivität
 		registrationStates:   make(map[string]*RegistrationState),   // Initialisiere die Registrierungs-Status-Map
 		passwordChangeStates: make(map[string]*PasswordChangeState), // Initialize password change states map
+		recoveryStates:       make(map[string]*RecoveryState),       // Initialize recovery states map
+		sudoStates:           make(map[string]*SudoState),           // Initialize sudo prompts
+		elevatedUntil:        make(map[string]time.Time),            // Initialize admin mode windows
+		mailComposeStates:    make(map[string]*MailComposeState),    // Initialize mail compose states map
+		newsPostStates:       make(map[string]*NewsPostState),       // Initialize news post states map
+		renameStates:         make(map[string]*RenameState),         // Initialize rename states map
+		libraryConfirmStates: make(map[string]*LibraryConfirmState), // Initialize export/import confirmations
+		sfxVolumes:           make(map[string]int),                  // Initialize SFX volume map
+		lastDiskSFX:          make(map[string]time.Time),            // Initialize drive sound timestamps
+		promptTemplates:      make(map[string]string),               // Initialize prompt templates
+		topRefresh:           make(map[string]chan struct{}),        // Initialize top refresh map
+		fetchTimes:           make(map[string][]time.Time),          // Initialize fetch history
+		transferCodes:        make(map[string]*transferCode),        // Initialize session transfer codes
 		loginStates:          make(map[string]*LoginState),          // Initialisiere die Login-Status-Map
 		catPagerStates:       make(map[string]*CatPagerState),       // Initialisiere die CAT-Pager-Status-Map
 		telnetStates:         make(map[string]*TelnetState),         // Initialisiere die Telnet-Status-Map
+		telnetPending:        make(map[string]telnetOwner),
 		failedLoginAttempts:  make(map[string]*LoginAttemptTracker), // Initialisiere die fehlgeschlagenen Login-Versuche-Map
+		ipConnections:        make(map[string]int),                  // Initialize connection counts per IP
+		connectionIPs:        make(map[string][]string),             // Initialize counted connections per session
 		telnetOutputShutdown: make(chan bool),                       // Initialize the shutdown channel
+		startTime:            time.Now(),                            // Replaced by main with the start of the server
 	}
 
 	// Registriere TinyOS als Provider beim VFS
 	vfs.SetTinyOSProvider(os)
 
 	// Initialisiere die Datenbank
-	os.initDB()
+	os.initDB(databaseFile)
 
 	// Umgebungsvariablen laden
 	os.loadEnvFromDB()
@@ -266,9 +371,11 @@ func NewTinyOS(vfs *virtualfs.VFS, promptManager *shared.PromptManager) *TinyOS
 			select {
 			case <-ticker.C:
 				os.CleanupExpiredSessions()
+				os.expireElevations()
 			}
 		}
 	}()
+	go os.runScheduledVacuum()
 
 	// Starte Ressourcenmanager-Cleanup
 	os.ResourceManager.StartPeriodicCleanup()
@@ -332,9 +439,9 @@ func (os *TinyOS) GetUsernameBySessionID(sessionID string) string {
 	return os.GetUsernameForSession(sessionID)
 }
 
-// GetPromptForSession generates a simple standard prompt
+// GetPromptForSession renders the prompt template of a session
 func (os *TinyOS) GetPromptForSession(sessionID string) string {
-	return "> "
+	return renderPrompt(os.promptTemplate(sessionID), os.GetUsernameForSession(sessionID), os.CurrentPathFromSession(sessionID), time.Now())
 }
 
 // VerifyPassword überprüft, ob das gegebene Passwort für den Benutzer korrekt ist
@@ -514,7 +621,7 @@ func (os *TinyOS) CleanupExpiredSessions() {
 	defer os.sessionMutex.Unlock()
 
 	now := time.Now()
-	sessionTimeout := 24 * time.Hour // Sessions laufen nach 24 Stunden Inaktivität ab
+	sessionTimeout := configuration.GetDuration("Security", "session_timeout", 24*time.Hour) // Inaktivität bis zum Ablauf
 	for id, session := range os.sessions {
 		if now.Sub(session.LastActivity) > sessionTimeout {
 			// Cleanup telnet sessions for expired sessions
@@ -570,7 +677,7 @@ func (os *TinyOS) StartBasicSession(sessionID string) bool {
 	}
 
 	// Sitzung hinzufügen
-	os.activeBasicSessions[sessionID] = true
+	os.activeBasicSessions[sessionID] = time.Now()
 	log.Printf("[BASIC-SESSION] Started BASIC session %s (total: %d/%d)",
 		sessionID, len(os.activeBasicSessions), MaxBasicSessions)
 	return true
@@ -588,6 +695,31 @@ func (os *TinyOS) EndBasicSession(sessionID string) {
 	}
 }
 
+// TouchBasicSession vermerkt eine Eingabe in der BASIC-Sitzung
+func (os *TinyOS) TouchBasicSession(sessionID string) {
+	os.basicSessionMutex.Lock()
+	defer os.basicSessionMutex.Unlock()
+
+	if _, exists := os.activeBasicSessions[sessionID]; exists {
+		os.activeBasicSessions[sessionID] = time.Now()
+	}
+}
+
+// IdleGuestBasicSessions liefert die BASIC-Sitzungen von Gästen, die länger
+// als timeout keine Eingabe mehr hatten
+func (os *TinyOS) IdleGuestBasicSessions(timeout time.Duration) []string {
+	os.basicSessionMutex.RLock()
+	defer os.basicSessionMutex.RUnlock()
+
+	var idle []string
+	for sessionID, lastActivity := range os.activeBasicSessions {
+		if time.Since(lastActivity) > timeout && os.isGuestSession(sessionID) {
+			idle = append(idle, sessionID)
+		}
+	}
+	return idle
+}
+
 // IsBasicSessionActive prüft, ob eine BASIC-Sitzung aktiv ist
 func (os *TinyOS) IsBasicSessionActive(sessionID string) bool {
 	os.basicSessionMutex.RLock()
@@ -619,6 +751,40 @@ func (os *TinyOS) isGuestSession(sessionID string) bool {
 		strings.HasPrefix(session.Username, "guest-")
 }
 
+// IsGuestSession reports whether a session belongs to a guest (used by TinyBASIC)
+func (os *TinyOS) IsGuestSession(sessionID string) bool {
+	return os.isGuestSession(sessionID)
+}
+
+// IsElevatedSession reports whether the session is an admin in admin mode,
+// i.e. within the sudo window (used by the VFS access check)
+func (os *TinyOS) IsElevatedSession(sessionID string) bool {
+	return os.isElevatedSession(sessionID)
+}
+
+// IsTemporarySession reports whether the session belongs to a temporary user
+// like dyson (used by the VFS access check)
+func (os *TinyOS) IsTemporarySession(sessionID string) bool {
+	return isTemporaryUser(os.GetUsernameForSession(sessionID))
+}
+
+// isAdminSession reports whether the session belongs to a user with the is_admin flag.
+// Temporary users like dyson are never admins: their password is part of a puzzle.
+func (os *TinyOS) isAdminSession(sessionID string) bool {
+	if os.isGuestSession(sessionID) || os.db == nil {
+		return false
+	}
+	username := os.GetUsernameForSession(sessionID)
+	if username == "" || isTemporaryUser(username) {
+		return false
+	}
+	var isAdmin int
+	if err := os.db.QueryRow("SELECT is_admin FROM users WHERE username = ?", username).Scan(&isAdmin); err != nil {
+		return false
+	}
+	return isAdmin == 1
+}
+
 // countGuestBasicSessions zählt die aktiven BASIC-Sitzungen für Gastbenutzer
 func (os *TinyOS) countGuestBasicSessions() int {
 	count := 0
@@ -740,6 +906,33 @@ func (os *TinyOS) CheckChatTimeLimits(username string) (bool, string) {
 	return false, ""
 }
 
+// AddChatTime rechnet die Dauer einer Chat-Antwort (aufgerundet auf
+// Sekunden) auf die heutige Chat-Nutzung an, die CheckChatTimeLimits prüft
+func (os *TinyOS) AddChatTime(username string, d time.Duration) {
+	if username == "" || os.db == nil || d <= 0 {
+		return
+	}
+	seconds := int((d + time.Second - 1) / time.Second)
+	today := time.Now().Format("2006-01-02")
+
+	result, err := os.db.Exec(
+		"UPDATE chat_usage SET time_used = time_used + ? WHERE username = ? AND date = ?",
+		seconds, username, today)
+	if err != nil {
+		logMessage("[TINYOS] Fehler beim Aktualisieren der Chat-Nutzung: %v", err)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		// Der Tag hat während der Sitzung gewechselt
+		_, err = os.db.Exec(
+			"INSERT INTO chat_usage (username, date, time_used, last_session_start) VALUES (?, ?, ?, ?)",
+			username, today, seconds, time.Now().Unix())
+		if err != nil {
+			logMessage("[TINYOS] Fehler beim Erstellen des Chat-Nutzungseintrags: %v", err)
+		}
+	}
+}
+
 // IsBanned prüft, ob ein Benutzer oder eine IP gebannt ist
 func (os *TinyOS) IsBanned(username, ip string) (bool, string) {
 	if username == "" && ip == "" {
@@ -891,10 +1084,10 @@ func (os *TinyOS) loadBannedUsers() {
 	}
 }
 
-// initDB initialisiert die SQLite-Datenbank
-func (os *TinyOS) initDB() {
+// initDB initialisiert die SQLite-Datenbank in der Datei path
+func (os *TinyOS) initDB(path string) {
 	// Datenbankdatei öffnen/erstellen
-	db, err := sql.Open("sqlite", "tinyos.db")
+	db, err := sql.Open("sqlite", path+databaseOptions)
 	if err != nil {
 		fmt.Printf("Fehler beim Öffnen der Datenbank: %v\n", err)
 		return
@@ -910,7 +1103,9 @@ func (os *TinyOS) initDB() {
 		is_admin INTEGER DEFAULT 0,
 		is_active INTEGER DEFAULT 1,
 		created_at INTEGER NOT NULL,
-		ip_address TEXT
+		ip_address TEXT,
+		recovery_question TEXT NOT NULL DEFAULT '',
+		recovery_answer TEXT NOT NULL DEFAULT ''
 	)`)
 	if err != nil {
 		fmt.Printf("Fehler beim Erstellen der Benutzertabelle: %v\n", err)
@@ -943,6 +1138,71 @@ func (os *TinyOS) initDB() {
 		fmt.Printf("Fehler beim Erstellen der Tabelle für Registrierungsversuche: %v\n", err)
 	}
 
+	// Erstelle die Tabelle für Benutzer-Mail
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS user_mail (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		sender TEXT NOT NULL,
+		recipient TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		body TEXT NOT NULL,
+		sent_at INTEGER NOT NULL,
+		is_read INTEGER DEFAULT 0
+	)`)
+	if err != nil {
+		fmt.Printf("Fehler beim Erstellen der Mail-Tabelle: %v\n", err)
+	}
+
+	// Sent mails for the hourly limit; deleting a mail does not remove its entry
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS mail_send_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		sender TEXT NOT NULL,
+		sent_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		fmt.Printf("Error creating mail send log table: %v\n", err)
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_mail_send_log_sender ON mail_send_log(sender, sent_at)`)
+	if err != nil {
+		fmt.Printf("Error creating mail send log index: %v\n", err)
+	}
+
+	// Dated news entries posted by admins
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS news (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		author TEXT NOT NULL,
+		title TEXT NOT NULL,
+		body TEXT NOT NULL,
+		posted_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		fmt.Printf("Error creating news table: %v\n", err)
+	}
+
+	// Erstelle die Tabelle für Benutzereinstellungen (z.B. Terminal-Theme)
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS user_preferences (
+		username TEXT NOT NULL,
+		pref_key TEXT NOT NULL,
+		pref_value TEXT NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (username, pref_key)
+	)`)
+	if err != nil {
+		fmt.Printf("Fehler beim Erstellen der Einstellungs-Tabelle: %v\n", err)
+	}
+
+	// Erstelle die Tabelle für die mit SAVEVAR gespeicherten Werte von BASIC-Programmen
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS basic_vars (
+		username TEXT NOT NULL,
+		var_key TEXT NOT NULL,
+		var_type TEXT NOT NULL,
+		var_value TEXT NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (username, var_key)
+	)`)
+	if err != nil {
+		fmt.Printf("Error creating basic_vars table: %v\n", err)
+	}
+
 	// Erstelle die Tabelle für virtuelle Dateien
 	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS virtual_files (
 		username TEXT NOT NULL,
@@ -950,11 +1210,31 @@ func (os *TinyOS) initDB() {
 		content TEXT,
 		is_dir INTEGER NOT NULL,
 		mod_time INTEGER NOT NULL,
+		link_target TEXT NOT NULL DEFAULT '',
+		read_only INTEGER NOT NULL DEFAULT 0,
 		PRIMARY KEY (username, path)
 	)`)
 	if err != nil {
 		fmt.Printf("Fehler beim Erstellen der Tabelle für virtuelle Dateien: %v\n", err)
 	}
+
+	// Add link_target column for example links (for existing installations)
+	_, err = db.Exec(`ALTER TABLE virtual_files ADD COLUMN link_target TEXT NOT NULL DEFAULT ''`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		fmt.Printf("Warning: Could not add link_target column to virtual_files: %v\n", err)
+	}
+	// Add security question columns for recover (for existing installations)
+	for _, column := range []string{"recovery_question", "recovery_answer"} {
+		_, err = db.Exec(`ALTER TABLE users ADD COLUMN ` + column + ` TEXT NOT NULL DEFAULT ''`)
+		if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+			fmt.Printf("Warning: Could not add %s column to users: %v\n", column, err)
+		}
+	}
+	// Add read_only column for files locked with lock (for existing installations)
+	_, err = db.Exec(`ALTER TABLE virtual_files ADD COLUMN read_only INTEGER NOT NULL DEFAULT 0`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		fmt.Printf("Warning: Could not add read_only column to virtual_files: %v\n", err)
+	}
 	// Erstelle die Tabelle für Benutzersitzungen
 	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS user_sessions (
 		session_id TEXT PRIMARY KEY,
@@ -975,6 +1255,31 @@ func (os *TinyOS) initDB() {
 		fmt.Printf("Warning: Could not add current_path column to user_sessions: %v\n", err)
 	}
 
+	// Successful and failed logins of registered users, shown by last
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS login_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		username TEXT NOT NULL,
+		ip_address TEXT NOT NULL,
+		login_at INTEGER NOT NULL,
+		success INTEGER NOT NULL
+	)`)
+	if err != nil {
+		fmt.Printf("Error creating login history table: %v\n", err)
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_login_history_username ON login_history(username)`)
+	if err != nil {
+		fmt.Printf("Error creating login history index: %v\n", err)
+	}
+
+	// Sessions moved to another device, their tokens must not restore them
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS revoked_sessions (
+		session_id TEXT PRIMARY KEY,
+		revoked_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		fmt.Printf("Fehler beim Erstellen der Tabelle für widerrufene Sitzungen: %v\n", err)
+	}
+
 	// Erstelle die Tabelle für Chat-Nutzung
 	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS chat_usage (
 		username TEXT NOT NULL,
@@ -1094,29 +1399,16 @@ func (os *TinyOS) syncExamplePrograms(username string) error {
 	// Kopiere/aktualisiere jedes Beispiel mit direktem Datenbankzugriff
 	var copyErrors []error
 	logMessage("[TINYOS] Beginne mit der Synchronisierung von %d Beispielprogrammen", len(examples))
-	for filename, content := range examples {
+	for filename := range examples {
 		filePath := fmt.Sprintf("/home/%s/basic/%s", username, filename)
 		logMessage("[TINYOS] Writing example file %s directly", filePath)
 
-		// Direkter DB-Zugriff als zuverlässigster Weg
-		if os.db != nil {
-			isDir := 0 // Datei, nicht Verzeichnis
-			modTime := time.Now().Unix()
-
-			// Direkt ausführen ohne Zuweisung der nicht benutzten Variable
-			_, err := os.db.Exec(
-				`INSERT OR REPLACE INTO virtual_files (username, path, content, is_dir, mod_time) 
-				VALUES (?, ?, ?, ?, ?)`,
-				username, filePath, content, isDir, modTime)
-
-			if err != nil {
-				logMessage("[TINYOS] DB-Fehler beim Kopieren des Beispielprogramms %s: %v", filename, err)
-				copyErrors = append(copyErrors, err)
-			} else {
-				logMessage("[TINYOS] Beispiel %s erfolgreich in DB", filename)
-			}
+		// Als Link auf das Beispiel ablegen; vom Benutzer geänderte Kopien bleiben erhalten
+		if err := os.Vfs.LinkExample(username, filePath, filename); err != nil {
+			logMessage("[TINYOS] Fehler beim Verlinken des Beispielprogramms %s: %v", filename, err)
+			copyErrors = append(copyErrors, err)
 		} else {
-			logMessage("[TINYOS] WARNUNG: Datenbank-Handle ist nil! Direkte DB-Operationen nicht möglich.")
+			logMessage("[TINYOS] Beispiel %s erfolgreich verlinkt", filename)
 		}
 	}
 
@@ -1219,8 +1511,9 @@ func (os *TinyOS) syncDysonFiles(username string) error {
 			targetPath = fmt.Sprintf("%s/%s", homePath, filename)
 		}
 
-		// Use VFS to write file (this will handle both in-memory and database storage)
-		err := os.Vfs.WriteFile(targetPath, content, username)
+		// Use VFS to write file (this will handle both in-memory and database storage);
+		// no session, the sync is a system write
+		err := os.Vfs.WriteFile(targetPath, content, "")
 		if err != nil {
 			logMessage("[DYSON-SYNC] VFS error copying %s: %v", filename, err)
 			copyErrors = append(copyErrors, err)
@@ -1363,30 +1656,22 @@ func (os *TinyOS) copyExamplePrograms(username string) error {
 
 	// Direkte Schreiboperation über das VFS für alle Beispielprogramme mit Timeout
 	var copyErrors []error
-	for filename, content := range examples {
+	for filename := range examples {
 		filePath := fmt.Sprintf("/home/%s/%s", username, filename)
 		logMessage("[TINYOS] Kopiere Beispiel %s nach %s", filename, filePath)
 
-		// Erstellen eines Channels für den Timeout
-		done := make(chan error, 1)
-
-		// Ausführen der Schreiboperation in einer Goroutine
-		go func(path, content string) {
-			done <- os.Vfs.WriteFile(path, content, "")
-		}(filePath, content)
-
-		// Warten auf Abschluss mit Timeout
-		select {
-		case err := <-done:
-			if err != nil {
-				logMessage("[TINYOS] Fehler beim Kopieren des Beispielprogramms %s: %v", filename, err)
-				copyErrors = append(copyErrors, err)
-			} else {
-				logMessage("[TINYOS] Beispielprogramm %s erfolgreich kopiert", filename)
-			}
-		case <-time.After(5 * time.Second):
+		err := runWithTimeout(5*time.Second, func() error {
+			return os.Vfs.LinkExample(username, filePath, filename)
+		})
+		switch {
+		case err == errTimedOut:
 			logMessage("[TINYOS] Timeout beim Kopieren des Beispielprogramms %s", filename)
 			copyErrors = append(copyErrors, fmt.Errorf("timeout beim Kopieren von %s", filename))
+		case err != nil:
+			logMessage("[TINYOS] Fehler beim Kopieren des Beispielprogramms %s: %v", filename, err)
+			copyErrors = append(copyErrors, err)
+		default:
+			logMessage("[TINYOS] Beispielprogramm %s erfolgreich kopiert", filename)
 		}
 	}
 
@@ -1399,11 +1684,8 @@ func (os *TinyOS) copyExamplePrograms(username string) error {
 	if os.db != nil {
 		homeDir := "/home/" + username
 
-		// Channel für Timeout-Kontrolle
-		done := make(chan bool, 1)
 		var filesFound int
-		// Datenbankabfrage in Goroutine
-		go func() {
+		err := runWithTimeout(10*time.Second, func() error {
 			query := `SELECT COUNT(*) FROM virtual_files 
 				WHERE username = ? 
 				AND is_dir = 0 
@@ -1417,31 +1699,26 @@ func (os *TinyOS) copyExamplePrograms(username string) error {
 			stmt, err := os.db.PrepareContext(ctx, query)
 			if err != nil {
 				logMessage("[TINYOS] Fehler beim Vorbereiten der Datenbankabfrage: %v", err)
-				done <- false
-				return
+				return err
 			}
 			defer stmt.Close()
 
-			err = stmt.QueryRowContext(ctx, username, homeDir+"/%").Scan(&filesFound)
-			if err != nil {
+			if err := stmt.QueryRowContext(ctx, username, homeDir+"/%").Scan(&filesFound); err != nil {
 				logMessage("[TINYOS] Fehler bei der Datenbankabfrage: %v", err)
-				done <- false
-				return
+				return err
 			}
-
-			done <- true
-		}()
-		// Warten auf Abschluss mit Timeout
-		select {
-		case <-done:
+			return nil
+		})
+		switch {
+		case err == errTimedOut:
+			logMessage("[TINYOS] Timeout bei der Überprüfung der kopierten Dateien")
+			return fmt.Errorf("timeout bei der Überprüfung der kopierten Dateien")
+		default:
 			logMessage("[TINYOS] Insgesamt %d Programmdateien (.bas/.sid) im Heimatverzeichnis gefunden", filesFound)
 			// Wenn keine Dateien gefunden wurden, könnte ein Problem vorliegen
 			if filesFound == 0 {
 				return fmt.Errorf("keine Programmdateien (.bas/.sid) im Heimatverzeichnis gefunden")
 			}
-		case <-time.After(10 * time.Second):
-			logMessage("[TINYOS] Timeout bei der Überprüfung der kopierten Dateien")
-			return fmt.Errorf("timeout bei der Überprüfung der kopierten Dateien")
 		}
 	}
 
@@ -1630,7 +1907,6 @@ func (os *TinyOS) recordFailedLoginAttempt(ipAddress string) {
 	defer os.loginAttemptMutex.Unlock()
 
 	maxAttempts := configuration.GetInt("Authentication", "max_failed_login_attempts", 5)
-	lockoutDuration := time.Duration(configuration.GetInt("Authentication", "login_lockout_duration_seconds", 30)) * time.Second
 
 	tracker, exists := os.failedLoginAttempts[ipAddress]
 	if !exists {
@@ -1645,19 +1921,50 @@ func (os *TinyOS) recordFailedLoginAttempt(ipAddress string) {
 	// Reset attempts if enough time has passed (1 hour)
 	if time.Since(tracker.LastAttempt) > time.Hour {
 		tracker.FailedAttempts = 0
+		tracker.Lockout = 0
 	}
 
 	tracker.FailedAttempts++
 	tracker.LastAttempt = time.Now()
 
-	// Lock account if max attempts reached
+	// Lock account if max attempts reached, every further failure escalates the lockout
 	if tracker.FailedAttempts >= maxAttempts {
-		tracker.LockedUntil = time.Now().Add(lockoutDuration)
-		logger.SecurityInfo("Login blocked for IP %s after %d failed attempts. Locked until %v",
-			ipAddress, tracker.FailedAttempts, tracker.LockedUntil)
+		tracker.Lockout = loginLockoutDuration(tracker.FailedAttempts - maxAttempts)
+		tracker.LockedUntil = time.Now().Add(tracker.Lockout)
+		if tracker.FailedAttempts == maxAttempts {
+			logger.SecurityInfo("Login blocked for IP %s after %d failed attempts. Locked for %v until %v",
+				ipAddress, tracker.FailedAttempts, tracker.Lockout, tracker.LockedUntil)
+		} else {
+			logger.SecurityWarn("Login lockout escalated for IP %s after %d failed attempts. Locked for %v until %v",
+				ipAddress, tracker.FailedAttempts, tracker.Lockout, tracker.LockedUntil)
+		}
 	}
 }
 
+// loginLockoutDuration computes the lockout after the given number of failures
+// beyond the threshold: base * multiplier^excess, capped at the configured maximum.
+func loginLockoutDuration(excess int) time.Duration {
+	base := time.Duration(configuration.GetInt("Authentication", "login_lockout_duration_seconds", 30)) * time.Second
+	multiplier := configuration.GetFloat("Authentication", "login_lockout_multiplier", 2.0)
+	maxLockout := time.Duration(configuration.GetInt("Authentication", "login_lockout_max_seconds", 3600)) * time.Second
+
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	if maxLockout < base {
+		maxLockout = base
+	}
+
+	lockout := float64(base)
+	for i := 0; i < excess; i++ {
+		lockout *= multiplier
+		if lockout >= float64(maxLockout) {
+			return maxLockout
+		}
+	}
+	return time.Duration(lockout)
+}
+
 // clearFailedLoginAttempts clears failed login attempts for an IP address after successful login
 func (os *TinyOS) clearFailedLoginAttempts(ipAddress string) {
 	os.loginAttemptMutex.Lock()
@@ -1689,6 +1996,7 @@ func (os *TinyOS) LoginUser(username, password, ipAddress string) ([]shared.Mess
 		if err == sql.ErrNoRows {
 			// Record failed login attempt for unknown username
 			os.recordFailedLoginAttempt(ipAddress)
+			os.counters.loginFailures.Add(1)
 			logger.SecurityWarn("Login failed for unknown user '%s' from IP %s", username, ipAddress)
 			return nil, "", fmt.Errorf("invalid username or password")
 		}
@@ -1699,6 +2007,8 @@ func (os *TinyOS) LoginUser(username, password, ipAddress string) ([]shared.Mess
 	if err != nil {
 		// Record failed login attempt for wrong password
 		os.recordFailedLoginAttempt(ipAddress)
+		os.recordLogin(username, ipAddress, false)
+		os.counters.loginFailures.Add(1)
 		logger.SecurityWarn("Login failed for user '%s' from IP %s: incorrect password", username, ipAddress)
 		return nil, "", fmt.Errorf("invalid username or password")
 	}
@@ -1712,9 +2022,19 @@ func (os *TinyOS) LoginUser(username, password, ipAddress string) ([]shared.Mess
 	// Clear failed login attempts for this IP after successful authentication
 	os.clearFailedLoginAttempts(ipAddress)
 	logger.SecurityInfo("Successful login for user '%s' from IP %s - cleared failed login attempts", username, ipAddress)
+	os.recordLogin(username, ipAddress, true)
+	os.counters.loginSuccesses.Add(1)
+
+	messages, sessionID := os.startUserSession(username, userID, ipAddress, "Login successful!")
+	return messages, sessionID, nil
+}
 
+// startUserSession creates a fresh session for an authenticated user, loads
+// the user's files and returns the welcome messages and the new session ID.
+// Shared by the password login and the session transfer.
+func (os *TinyOS) startUserSession(username string, userID int64, ipAddress, greeting string) ([]shared.Message, string) {
 	// Anmeldestatus in der Datenbank aktualisieren
-	_, err = os.db.Exec("UPDATE users SET is_logged_in = 1, last_login = CURRENT_TIMESTAMP, ip_address = ? WHERE username = ?", ipAddress, username)
+	_, err := os.db.Exec("UPDATE users SET is_logged_in = 1, last_login = CURRENT_TIMESTAMP, ip_address = ? WHERE username = ?", ipAddress, username)
 	if err != nil {
 		logMessage("[TINYOS] Fehler beim Aktualisieren der Anmeldestatus: %v", err)
 	}
@@ -1776,13 +2096,26 @@ func (os *TinyOS) LoginUser(username, password, ipAddress string) ([]shared.Mess
 	tinyOSDebugLog("[LOGIN] LoginUser erfolgreich abgeschlossen")
 	logger.Info(logger.AreaAuth, "Benutzer %s erfolgreich angemeldet mit Session %s", username, sessionID)
 
+	// Lautstärke des Benutzers statt der Gast-Einstellung verwenden
+	os.forgetSFXVolume(sessionID)
+	os.forgetPrompt(sessionID)
+
 	// Return welcome messages and session ID
 	messages := []shared.Message{
-		{Type: shared.MessageTypeText, Content: "Login successful!"},
+		{Type: shared.MessageTypeText, Content: greeting},
 		{Type: shared.MessageTypeText, Content: "Welcome, " + username + "!"},
-		{Type: shared.MessageTypeSound, Content: "beep"},
-		{Type: shared.MessageTypePrompt, PromptSymbol: os.GetPromptForSession(sessionID)}, // Set prompt with current path
 	}
+	if notice := os.newMailNotice(username); notice != "" {
+		messages = append(messages, shared.Message{Type: shared.MessageTypeText, Content: notice})
+	}
+	if notice := os.latestNewsNotice(); notice != "" {
+		messages = append(messages, shared.Message{Type: shared.MessageTypeText, Content: notice})
+	}
+	messages = append(messages,
+		os.themeForUser(username).Message(),
+		os.SFXMessage(sessionID, SFXLogin),
+		shared.Message{Type: shared.MessageTypePrompt, PromptSymbol: os.GetPromptForSession(sessionID)}, // Set prompt with current path
+	)
 
 	// For temporary users, add a special message to trigger token refresh in frontend
 	if isTemporaryUser(username) {
@@ -1793,7 +2126,7 @@ func (os *TinyOS) LoginUser(username, password, ipAddress string) ([]shared.Mess
 		})
 	}
 
-	return messages, sessionID, nil
+	return messages, sessionID
 }
 
 // LogoutUser meldet einen Benutzer ab
@@ -1836,6 +2169,16 @@ func (os *TinyOS) deleteUserFromDB(username string) error {
 	if err != nil {
 		return fmt.Errorf("fehler beim Löschen des Benutzers %s aus der datenbank: %v", username, err)
 	}
+	// A later user of the same name must not see the old logins
+	if _, err := os.db.Exec("DELETE FROM login_history WHERE username = ?", username); err != nil {
+		logMessage("[TINYOS] Could not delete the login history of %s: %v", username, err)
+	}
+	if _, err := os.db.Exec("DELETE FROM basic_vars WHERE username = ?", username); err != nil {
+		logMessage("[TINYOS] Could not delete the BASIC values of %s: %v", username, err)
+	}
+	if _, err := os.db.Exec("DELETE FROM mail_send_log WHERE sender = ?", username); err != nil {
+		logMessage("[TINYOS] Could not delete the mail send log of %s: %v", username, err)
+	}
 	logMessage("[TINYOS] Benutzer %s aus der Datenbank gelöscht.", username)
 	return nil
 }
@@ -1994,6 +2337,18 @@ func (os *TinyOS) UsernameFromSession(sessionID string) string {
 
 // CreateGuestSession legt eine neue Gast-Session mit gegebener SessionID und IP an und gibt sie zurück
 func (os *TinyOS) CreateGuestSession(sessionID, ip string) (*Session, error) {
+	return os.createGuestSession(sessionID, ip, false)
+}
+
+// CreateScratchGuestSession legt eine Gast-Session mit leerem Home an: Die
+// Beispielprogramme werden nicht verlinkt, der Start ist dadurch schneller.
+// Der Befehl examples holt sie bei Bedarf nach.
+func (os *TinyOS) CreateScratchGuestSession(sessionID, ip string) (*Session, error) {
+	return os.createGuestSession(sessionID, ip, true)
+}
+
+// createGuestSession legt die Gast-Session an; scratch lässt die Beispiele weg
+func (os *TinyOS) createGuestSession(sessionID, ip string, scratch bool) (*Session, error) {
 	session := &Session{
 		ID:           sessionID,
 		Username:     "guest",
@@ -2014,7 +2369,11 @@ func (os *TinyOS) CreateGuestSession(sessionID, ip string) (*Session, error) {
 		}
 
 		// Dann frisches VFS initialisieren
-		err = os.Vfs.InitializeGuestVFS()
+		if scratch {
+			err = os.Vfs.InitializeScratchGuestVFS()
+		} else {
+			err = os.Vfs.InitializeGuestVFS()
+		}
 		if err != nil {
 			logMessage("[TINYOS] Warnung: Fehler beim Initialisieren des Gast-VFS: %v", err)
 			// Nicht als kritischer Fehler behandeln, Session ist trotzdem gültig
@@ -2028,6 +2387,11 @@ func (os *TinyOS) CreateGuestSession(sessionID, ip string) (*Session, error) {
 
 // RestoreUserSession restores a user session from a JWT token
 func (os *TinyOS) RestoreUserSession(sessionID, username, ip string) (*Session, error) {
+	if os.isSessionRevoked(sessionID) {
+		logger.SecurityWarn("Refused to restore session %s of user %s from IP %s: session was transferred", shortSessionID(sessionID), username, ip)
+		return nil, fmt.Errorf("session was transferred to another device")
+	}
+
 	// For temporary users, check if they already have an existing session
 	// If the session is older than 15 minutes, don't restore it
 	if isTemporaryUser(username) {
@@ -2177,7 +2541,9 @@ func (os *TinyOS) RestoreUserSession(sessionID, username, ip string) (*Session,
 	return session, nil
 }
 
-// ResolvePath löst einen gegebenen Pfad auf und gibt den kanonischen Pfad zurück
+// ResolvePath löst einen gegebenen Pfad auf und gibt den kanonischen Pfad zurück.
+// "." und ".." werden aufgelöst; Pfade, die die Session nicht lesen darf (etwa
+// das Home eines anderen Benutzers), ergeben virtualfs.ErrPermissionDenied.
 func (os *TinyOS) ResolvePath(path string, sessionID string) (string, error) {
 	log.Printf("[DEBUG-TINYOS] ResolvePath: Eingabe-Pfad=%s, SessionID=%s", path, sessionID)
 	// Ermittle Username und CurrentPath für Session
@@ -2187,8 +2553,8 @@ func (os *TinyOS) ResolvePath(path string, sessionID string) (string, error) {
 	}
 	homeDir := "/home/" + username
 	currentPath := homeDir
-	if session, ok := os.sessions[sessionID]; ok && session.CurrentPath != "" {
-		currentPath = session.CurrentPath
+	if sessionPath := os.CurrentPathFromSession(sessionID); sessionPath != "" {
+		currentPath = sessionPath
 	}
 	log.Printf("[DEBUG-TINYOS] ResolvePath: Username=%s, HomeDir=%s, CurrentPath=%s", username, homeDir, currentPath)
 	// Wenn Pfad absolut ist, direkt verwenden, sonst relativ zu CurrentPath
@@ -2196,7 +2562,14 @@ func (os *TinyOS) ResolvePath(path string, sessionID string) (string, error) {
 	if !strings.HasPrefix(path, "/") {
 		resolvedPath = currentPath + "/" + path
 	}
+	resolvedPath = virtualfs.CleanPath(resolvedPath)
 	log.Printf("[DEBUG-TINYOS] ResolvePath: Aufgelöster Pfad=%s, SessionID=%s", resolvedPath, sessionID)
+	if os.Vfs != nil {
+		if err := os.Vfs.CheckAccess(resolvedPath, sessionID, false); err != nil {
+			logger.SecurityWarn("Session %s (%s) denied access to %s", shortSessionID(sessionID), username, resolvedPath)
+			return "", err
+		}
+	}
 	return resolvedPath, nil
 }
 
@@ -2359,64 +2732,31 @@ func (os *TinyOS) wrapText(sessionID, text string) []string {
 	return wrappedLines
 }
 
-// wrapLine umbricht eine einzelne Zeile an Wortgrenzen (TinyOS-Version)
+// wrapLine umbricht eine einzelne Zeile an Wortgrenzen (TinyOS-Version).
+// Innerhalb von Wörtern wird nach Bindestrichen und vor Satzzeichen
+// umgebrochen (siehe findBreak), nur unteilbare Wörter werden hart getrennt.
 func (os *TinyOS) wrapLine(line string, width int) []string {
-	if len(line) <= width {
+	if len(line) <= width || width < 1 {
 		return []string{line}
 	}
 
-	var result []string
 	words := strings.Fields(line)
-
 	if len(words) == 0 {
 		// Leere Zeile oder nur Leerzeichen
 		return []string{line}
 	}
 
-	currentLine := ""
-
-	for _, word := range words {
-		// Prüfe ob das Wort allein schon zu lang ist
-		if len(word) > width {
-			// Wort ist zu lang, muss hart umgebrochen werden
-			if currentLine != "" {
-				result = append(result, currentLine)
-				currentLine = ""
-			}
-
-			// Hartes Umbrechen des zu langen Wortes
-			for len(word) > width {
-				result = append(result, word[:width])
-				word = word[width:]
-			}
-
-			if len(word) > 0 {
-				currentLine = word
-			}
-		} else {
-			// Normales Wort
-			testLine := currentLine
-			if testLine != "" {
-				testLine += " "
-			}
-			testLine += word
-
-			if len(testLine) <= width {
-				// Wort passt in die aktuelle Zeile
-				currentLine = testLine
-			} else {
-				// Wort passt nicht, neue Zeile beginnen
-				if currentLine != "" {
-					result = append(result, currentLine)
-				}
-				currentLine = word
-			}
-		}
+	var result []string
+	rest := strings.Join(words, " ")
+	for len(rest) > width {
+		breakPoint := findBreak(rest, width)
+		result = append(result, strings.TrimRight(rest[:breakPoint], " "))
+		rest = strings.TrimLeft(rest[breakPoint:], " ")
 	}
 
 	// Letzte Zeile hinzufügen falls vorhanden
-	if currentLine != "" {
-		result = append(result, currentLine)
+	if rest != "" {
+		result = append(result, rest)
 	}
 
 	return result
@@ -2574,18 +2914,7 @@ func (os *TinyOS) ShowChatHistory(sessionID string) []shared.Message {
 
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("Chat History (%d messages):\n\n", len(session.ChatHistory)))
-
-	for i, msg := range session.ChatHistory {
-		timeStr := msg.Time.Format("15:04:05")
-		if msg.Role == "user" {
-			result.WriteString(fmt.Sprintf("[%s] You: %s\n", timeStr, msg.Content))
-		} else {
-			result.WriteString(fmt.Sprintf("[%s] MCP: %s\n", timeStr, msg.Content))
-		}
-		if i < len(session.ChatHistory)-1 {
-			result.WriteString("\n")
-		}
-	}
+	result.WriteString(formatChatHistory(session.ChatHistory, "15:04:05"))
 
 	return []shared.Message{{
 		Content: result.String(),
@@ -2868,6 +3197,15 @@ func (os *TinyOS) CleanupSessionResources(sessionID string) {
 		return
 	}
 
+	// The closed connection no longer counts against the limit of its IP
+	os.releaseIPConnection(sessionID)
+	os.cleanupSessionState(sessionID)
+}
+
+// cleanupSessionState drops the editor, telnet, pager, chess and dialog state
+// of a session. reboot uses it directly because the connection stays open.
+func (os *TinyOS) cleanupSessionState(sessionID string) {
+
 	// CRITICAL: Reset the input mode to default immediately.
 	// This is the most important step to prevent race conditions on reconnect.
 	// The new connection will see the correct OS_SHELL mode even if other cleanup
@@ -2894,7 +3232,7 @@ func (os *TinyOS) CleanupSessionResources(sessionID string) {
 	}
 	os.catPagerMutex.Unlock()
 
-	// 4. Clean up any pending registration, login, or password change processes
+	// 4. Clean up any pending registration, login, password change, or mail compose processes
 	os.registrationMutex.Lock()
 	delete(os.registrationStates, sessionID)
 	os.registrationMutex.Unlock()
@@ -2907,6 +3245,39 @@ func (os *TinyOS) CleanupSessionResources(sessionID string) {
 	delete(os.passwordChangeStates, sessionID)
 	os.passwordChangeMutex.Unlock()
 
+	os.recoveryMutex.Lock()
+	delete(os.recoveryStates, sessionID)
+	os.recoveryMutex.Unlock()
+
+	// Admin mode does not survive a reconnect or reboot
+	os.endElevation(sessionID)
+
+	os.mailComposeMutex.Lock()
+	delete(os.mailComposeStates, sessionID)
+	os.mailComposeMutex.Unlock()
+
+	os.newsPostMutex.Lock()
+	delete(os.newsPostStates, sessionID)
+	os.newsPostMutex.Unlock()
+
+	os.renameMutex.Lock()
+	delete(os.renameStates, sessionID)
+	os.renameMutex.Unlock()
+
+	os.libraryConfirmMutex.Lock()
+	delete(os.libraryConfirmStates, sessionID)
+	os.libraryConfirmMutex.Unlock()
+
+	os.sfxMutex.Lock()
+	delete(os.sfxVolumes, sessionID)
+	delete(os.lastDiskSFX, sessionID)
+	os.sfxMutex.Unlock()
+
+	os.forgetPrompt(sessionID)
+	os.forgetGuestBasicVars(sessionID)
+
+	os.stopTopRefresh(sessionID)
+
 	// 5. Clean up active chess game state
 	os.sessionMutex.Lock()
 	if session, exists := os.sessions[sessionID]; exists {
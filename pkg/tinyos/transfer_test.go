@@ -0,0 +1,145 @@
+package tinyos
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// transferCodeFrom returns the code createTransferCode handed out
+func transferCodeFrom(t *testing.T, reply string) string {
+	t.Helper()
+	for _, line := range strings.Split(reply, "\n") {
+		if code, ok := strings.CutPrefix(line, "Transfer code: "); ok {
+			return code
+		}
+	}
+	t.Fatalf("no transfer code in %q", reply)
+	return ""
+}
+
+// TestNewTransferCode checks the format of generated codes
+func TestNewTransferCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		code, err := newTransferCode()
+		if err != nil {
+			t.Fatalf("newTransferCode: %v", err)
+		}
+		if len(code) != transferCodeLength+1 || code[transferCodeLength/2] != '-' {
+			t.Fatalf("code %q does not look like XXXX-XXXX", code)
+		}
+		for _, c := range strings.ReplaceAll(code, "-", "") {
+			if !strings.ContainsRune(transferCodeAlphabet, c) {
+				t.Fatalf("code %q contains %q", code, c)
+			}
+		}
+		if normalizeTransferCode(code) != code {
+			t.Errorf("normalizeTransferCode(%q) changed a valid code", code)
+		}
+		seen[code] = true
+	}
+	if len(seen) < 45 {
+		t.Errorf("only %d different codes out of 50", len(seen))
+	}
+}
+
+// TestNormalizeTransferCode checks how typed codes are read
+func TestNormalizeTransferCode(t *testing.T) {
+	for _, tc := range []struct {
+		input, want string
+	}{
+		{"K7QX-M2PA", "K7QX-M2PA"},
+		{"k7qx-m2pa", "K7QX-M2PA"},
+		{"K7QXM2PA", "K7QX-M2PA"},
+		{" k7qxm2pa ", "K7QX-M2PA"},
+		{"K7QX-M2P", ""},
+		{"K7QX-M2PAB", ""},
+		{"", ""},
+	} {
+		if got := normalizeTransferCode(tc.input); got != tc.want {
+			t.Errorf("normalizeTransferCode(%q) = %q, want %q", tc.input, got, tc.want)
+		}
+	}
+}
+
+// TestTransferCodeNeedsLogin refuses codes for guests and the dyson account
+func TestTransferCodeNeedsLogin(t *testing.T) {
+	os := newDBTestOS(t, map[string]string{"g": "guest", "d": "dyson"})
+	for _, sessionID := range []string{"g", "d"} {
+		reply := messageText(os.cmdTransfer([]string{sessionID}))
+		if !strings.Contains(reply, "please log in first") {
+			t.Errorf("transfer in session %s answered %q", sessionID, reply)
+		}
+	}
+	if len(os.transferCodes) != 0 {
+		t.Errorf("%d codes were created", len(os.transferCodes))
+	}
+}
+
+// TestTransferCodeIsSingleUse uses a code once and checks that neither a
+// second try nor an expired or a wrong code is accepted
+func TestTransferCodeIsSingleUse(t *testing.T) {
+	os := newDBTestOS(t, map[string]string{"a": "alice", "g1": "guest", "g2": "guest"})
+	addTestUser(t, os, "alice", false)
+
+	// A new code replaces the previous one of the session
+	first := transferCodeFrom(t, messageText(os.cmdTransfer([]string{"a"})))
+	code := transferCodeFrom(t, messageText(os.cmdTransfer([]string{"a"})))
+	if _, exists := os.transferCodes[first]; exists || len(os.transferCodes) != 1 {
+		t.Errorf("the first code is still valid, %d codes open", len(os.transferCodes))
+	}
+
+	// The code is spent by the first attempt, even one that fails because
+	// the session has ended in the meantime
+	os.sessions["a"].Username = "guest"
+	if reply := messageText(os.cmdTransfer([]string{"g1", code})); !strings.Contains(reply, "has ended") {
+		t.Errorf("first use answered %q", reply)
+	}
+	os.sessions["a"].Username = "alice"
+	if reply := messageText(os.cmdTransfer([]string{"g2", strings.ToLower(code)})); !strings.Contains(reply, "invalid or expired") {
+		t.Errorf("second use answered %q", reply)
+	}
+
+	code = transferCodeFrom(t, messageText(os.cmdTransfer([]string{"a"})))
+	os.transferCodes[code].expires = time.Now().Add(-time.Second)
+	if reply := messageText(os.cmdTransfer([]string{"g1", code})); !strings.Contains(reply, "invalid or expired") {
+		t.Errorf("expired code answered %q", reply)
+	}
+
+	// A logged-in session cannot redeem a code
+	code = transferCodeFrom(t, messageText(os.cmdTransfer([]string{"a"})))
+	if reply := messageText(os.cmdTransfer([]string{"a", code})); !strings.Contains(reply, "already logged in") {
+		t.Errorf("redeeming in a logged-in session answered %q", reply)
+	}
+}
+
+// TestWrongTransferCodesBlockTheIP counts wrong codes as failed logins
+func TestWrongTransferCodesBlockTheIP(t *testing.T) {
+	os := newDBTestOS(t, map[string]string{"g": "guest"})
+	for i := 0; i < 5; i++ {
+		os.cmdTransfer([]string{"g", "AAAA-AAAA"})
+	}
+	reply := messageText(os.cmdTransfer([]string{"g", "AAAA-AAAA"}))
+	if !strings.Contains(reply, "too many failed attempts") {
+		t.Errorf("sixth wrong code answered %q, want the IP to be blocked", reply)
+	}
+}
+
+// TestRevokedSessionCannotBeRestored checks that the token of a transferred
+// session no longer restores it
+func TestRevokedSessionCannotBeRestored(t *testing.T) {
+	os := newDBTestOS(t, nil)
+	addTestUser(t, os, "alice", false)
+
+	os.revokeSession("old-session", "moved")
+	if !os.isSessionRevoked("old-session") {
+		t.Fatal("the session is not marked as revoked")
+	}
+	if os.isSessionRevoked("other-session") {
+		t.Error("an unrelated session counts as revoked")
+	}
+	if _, err := os.RestoreUserSession("old-session", "alice", "192.0.2.1"); err == nil {
+		t.Error("a revoked session was restored")
+	}
+}
@@ -0,0 +1,26 @@
+package tinyos
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFormatUptime checks the wording of uptime for short and long runs
+func TestFormatUptime(t *testing.T) {
+	for _, tc := range []struct {
+		uptime time.Duration
+		want   string
+	}{
+		{0, "0 seconds"},
+		{time.Second, "1 second"},
+		{59 * time.Second, "59 seconds"},
+		{time.Minute + 30*time.Second, "1 minute"},
+		{2 * time.Hour, "2 hours"},
+		{24*time.Hour + 5*time.Minute, "1 day, 5 minutes"},
+		{3*24*time.Hour + 4*time.Hour + 59*time.Minute + 59*time.Second, "3 days, 4 hours, 59 minutes"},
+	} {
+		if got := formatUptime(tc.uptime); got != tc.want {
+			t.Errorf("formatUptime(%v) = %q, want %q", tc.uptime, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package tinyos
+
+// isBreakAfter reports characters a line may end with inside a word
+func isBreakAfter(c byte) bool {
+	return c == '-' || c == '/'
+}
+
+// isBreakBefore reports punctuation a line may start with inside a word,
+// e.g. the separators of a URL
+func isBreakBefore(c byte) bool {
+	switch c {
+	case '.', ',', ';', ':', '?', '!', '&', '#':
+		return true
+	}
+	return false
+}
+
+// isAlnum reports ASCII letters and digits
+func isAlnum(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// findBreak returns where to break s, which is longer than width, so that
+// s[:n] fits. It takes the last space after the indentation, or a point
+// inside a word: after a hyphen or slash, or before punctuation that is
+// followed by more of the word. Breaks inside a word are only taken in the
+// second half of the line so short stubs don't end up alone. Without any
+// break point the word is split hard at width.
+func findBreak(s string, width int) int {
+	indent := 0
+	for indent < len(s) && s[indent] == ' ' {
+		indent++
+	}
+	for i := width; i > indent; i-- {
+		if s[i] == ' ' {
+			return i
+		}
+		if i < width/2 || s[i-1] == ' ' {
+			continue
+		}
+		if isBreakAfter(s[i-1]) && i >= 2 && isAlnum(s[i-2]) {
+			return i
+		}
+		if isBreakBefore(s[i]) && isAlnum(s[i-1]) && i+1 < len(s) && isAlnum(s[i+1]) {
+			return i
+		}
+	}
+	return width
+}
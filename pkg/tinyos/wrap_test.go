@@ -0,0 +1,49 @@
+package tinyos
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestWrapLine checks where wrapLine breaks and that every line fits
+func TestWrapLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		width int
+		want  []string
+	}{
+		{"words", "the quick brown fox", 10, []string{"the quick", "brown fox"}},
+		{"hyphenated word", "a state-of-the-art-terminal-emulator", 20, []string{"a state-of-the-art-", "terminal-emulator"}},
+		{"hyphen at line end", "it is well-known", 12, []string{"it is well-", "known"}},
+		{"url at slashes", "https://example.com/files/basic/games/snake.bas", 24, []string{"https://example.com/", "files/basic/games/snake", ".bas"}},
+		{"url query", "example.com/search?q=retro&page=2", 20, []string{"example.com/search", "?q=retro&page=2"}},
+		{"comma stays with word", "first, second, third", 14, []string{"first, second,", "third"}},
+		{"unbreakable token", strings.Repeat("x", 25), 10, []string{"xxxxxxxxxx", "xxxxxxxxxx", "xxxxx"}},
+		{"stub too short for a soft break", "a-" + strings.Repeat("b", 20), 10, []string{"a-bbbbbbbb", "bbbbbbbbbb", "bb"}},
+	}
+	os := &TinyOS{}
+	for _, tt := range tests {
+		got := os.wrapLine(tt.line, tt.width)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: wrapLine(%q, %d) = %q, want %q", tt.name, tt.line, tt.width, got, tt.want)
+		}
+		for _, line := range got {
+			if len(line) > tt.width {
+				t.Errorf("%s: line %q longer than %d", tt.name, line, tt.width)
+			}
+		}
+	}
+}
+
+// TestWrapLinesForTerminalKeepsIndent checks that the pager does not break
+// inside the indentation of a line
+func TestWrapLinesForTerminalKeepsIndent(t *testing.T) {
+	os := &TinyOS{}
+	got := os.wrapLinesForTerminal([]string{"    PRINT \"HELLO-WORLD-FROM-BASIC\""}, 20)
+	want := []string{"    PRINT \"HELLO-", "WORLD-FROM-BASIC\""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrapLinesForTerminal = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,83 @@
+package tls
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+)
+
+// DefaultContentSecurityPolicy allows what the terminal page needs: its own
+// scripts and inline code, the web fonts, data and blob URLs for generated
+// images and sounds, and WebSocket connections.
+const DefaultContentSecurityPolicy = "default-src 'self'; " +
+	"script-src 'self' 'unsafe-inline'; " +
+	"style-src 'self' 'unsafe-inline' https://fonts.googleapis.com https://webonastick.com; " +
+	"font-src 'self' data: https://fonts.gstatic.com https://webonastick.com; " +
+	"img-src 'self' data: blob:; " +
+	"media-src 'self' data: blob:; " +
+	"connect-src 'self' wss: blob:; " +
+	"object-src 'none'; base-uri 'self'; frame-ancestors 'self'"
+
+// SecurityHeaders holds the security headers the HTTPS server adds to its
+// responses. Each header can be switched off on its own.
+type SecurityHeaders struct {
+	EnableHSTS            bool
+	HSTSMaxAge            int // seconds
+	HSTSIncludeSubdomains bool
+	EnableNoSniff         bool
+	EnableCSP             bool
+	ContentSecurityPolicy string
+}
+
+// loadSecurityHeaders reads the header settings from the [TLS] section
+func loadSecurityHeaders() SecurityHeaders {
+	return SecurityHeaders{
+		EnableHSTS:            configuration.GetBool("TLS", "enable_hsts", true),
+		HSTSMaxAge:            configuration.GetInt("TLS", "hsts_max_age", 31536000),
+		HSTSIncludeSubdomains: configuration.GetBool("TLS", "hsts_include_subdomains", false),
+		EnableNoSniff:         configuration.GetBool("TLS", "enable_content_type_options", true),
+		EnableCSP:             configuration.GetBool("TLS", "enable_csp", true),
+		ContentSecurityPolicy: configuration.GetString("TLS", "content_security_policy", DefaultContentSecurityPolicy),
+	}
+}
+
+// Values returns the enabled headers by name
+func (h SecurityHeaders) Values() map[string]string {
+	values := make(map[string]string)
+	if h.EnableHSTS && h.HSTSMaxAge > 0 {
+		hsts := fmt.Sprintf("max-age=%d", h.HSTSMaxAge)
+		if h.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		values["Strict-Transport-Security"] = hsts
+	}
+	if h.EnableNoSniff {
+		values["X-Content-Type-Options"] = "nosniff"
+	}
+	if h.EnableCSP && h.ContentSecurityPolicy != "" {
+		values["Content-Security-Policy"] = h.ContentSecurityPolicy
+	}
+	return values
+}
+
+// SecurityHeadersHandler wraps next so that every response carries the
+// configured security headers. Without TLS next is returned unchanged, since
+// HSTS must only be sent over HTTPS.
+func (tm *TLSManager) SecurityHeadersHandler(next http.Handler) http.Handler {
+	if !tm.config.EnableTLS {
+		return next
+	}
+	headers := tm.config.Headers.Values()
+	if len(headers) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The headers are only added, w itself is passed on unwrapped so the
+		// WebSocket upgrade can still hijack the connection
+		for name, value := range headers {
+			w.Header().Set(name, value)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,77 @@
+package tls
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSecurityHeadersValues(t *testing.T) {
+	headers := SecurityHeaders{
+		EnableHSTS:            true,
+		HSTSMaxAge:            600,
+		HSTSIncludeSubdomains: true,
+		EnableNoSniff:         true,
+		EnableCSP:             true,
+		ContentSecurityPolicy: "default-src 'self'",
+	}
+	values := headers.Values()
+	if got := values["Strict-Transport-Security"]; got != "max-age=600; includeSubDomains" {
+		t.Errorf("Strict-Transport-Security = %q", got)
+	}
+	if got := values["X-Content-Type-Options"]; got != "nosniff" {
+		t.Errorf("X-Content-Type-Options = %q", got)
+	}
+	if got := values["Content-Security-Policy"]; got != "default-src 'self'" {
+		t.Errorf("Content-Security-Policy = %q", got)
+	}
+
+	// Each header can be switched off on its own
+	headers.EnableHSTS = false
+	headers.EnableCSP = false
+	values = headers.Values()
+	if _, ok := values["Strict-Transport-Security"]; ok {
+		t.Error("HSTS should be disabled")
+	}
+	if _, ok := values["Content-Security-Policy"]; ok {
+		t.Error("CSP should be disabled")
+	}
+	if _, ok := values["X-Content-Type-Options"]; !ok {
+		t.Error("nosniff should still be set")
+	}
+}
+
+func TestSecurityHeadersHandler(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Hijacker); !ok {
+			t.Error("response writer should still support hijacking for WebSocket upgrades")
+		}
+	})
+	config := &TLSConfig{
+		EnableTLS: true,
+		Headers:   SecurityHeaders{EnableHSTS: true, HSTSMaxAge: 60, EnableNoSniff: true},
+	}
+	manager := &TLSManager{config: config}
+
+	server := httptest.NewServer(manager.SecurityHeadersHandler(next))
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get("Strict-Transport-Security"); got != "max-age=60" {
+		t.Errorf("Strict-Transport-Security = %q", got)
+	}
+	if got := resp.Header.Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("X-Content-Type-Options = %q", got)
+	}
+
+	// Without TLS the handler is not wrapped, so no HSTS goes out over plain HTTP
+	config.EnableTLS = false
+	recorder := httptest.NewRecorder()
+	manager.SecurityHeadersHandler(http.NotFoundHandler()).ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	if got := recorder.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("HSTS sent without TLS: %q", got)
+	}
+}
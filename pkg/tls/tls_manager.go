@@ -33,6 +33,7 @@ type TLSConfig struct {
 	KeyFile            string
 	HTTPPort           string
 	HTTPSPort          string
+	Headers            SecurityHeaders
 }
 
 // NewTLSManager creates a new TLS manager with configuration
@@ -48,6 +49,7 @@ func NewTLSManager() (*TLSManager, error) {
 		KeyFile:            configuration.GetString("TLS", "key_file", "./certs/server.key"),
 		HTTPPort:           configuration.GetString("TLS", "http_port", "8080"),
 		HTTPSPort:          configuration.GetString("TLS", "https_port", "8443"),
+		Headers:            loadSecurityHeaders(),
 	}
 
 	manager := &TLSManager{
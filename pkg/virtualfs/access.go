@@ -0,0 +1,55 @@
+package virtualfs
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrPermissionDenied is returned when a session reaches for a path it may
+// not use, such as the home directory of another user
+var ErrPermissionDenied = errors.New("permission denied")
+
+// CleanPath turns an absolute path into its canonical form: backslashes
+// become slashes and "." and ".." are resolved, so /home/alice/../bob is
+// /home/bob. Access checks and lookups both use the cleaned path; a ".."
+// cannot climb above the root.
+func CleanPath(p string) string {
+	return path.Clean("/" + strings.ReplaceAll(p, "\\", "/"))
+}
+
+// CheckAccess reports ErrPermissionDenied if sessionID may not read (or,
+// with write, change) the absolute path p. A session may use its own home
+// directory; the areas outside /home (/, /home itself, /system) are shared
+// and read-only; the homes of other users are closed. Admins may use every
+// path while in admin mode (sudo), unless they are a temporary account like
+// dyson, whose password is public. Calls without a session come from the
+// system itself and are not checked.
+func (vfs *VFS) CheckAccess(p, sessionID string, write bool) error {
+	if sessionID == "" || vfs.os == nil {
+		return nil
+	}
+	p = CleanPath(p)
+
+	username := ""
+	if provider, ok := vfs.os.(interface{ UsernameFromSession(string) string }); ok {
+		username = provider.UsernameFromSession(sessionID)
+	}
+	if username == "" {
+		username = "guest"
+	}
+
+	owner := vfs.getUsernameFromPath(p)
+	if owner == username || (owner == "" && !write) {
+		return nil
+	}
+	denied := fmt.Errorf("%w: %s", ErrPermissionDenied, p)
+	if temporary, ok := vfs.os.(interface{ IsTemporarySession(string) bool }); ok && temporary.IsTemporarySession(sessionID) {
+		return denied
+	}
+	if admin, ok := vfs.os.(interface{ IsElevatedSession(string) bool }); ok && admin.IsElevatedSession(sessionID) {
+		return nil
+	}
+	return denied
+}
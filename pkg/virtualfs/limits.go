@@ -0,0 +1,115 @@
+package virtualfs
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/antibyte/retroterm/pkg/configuration"
+)
+
+const (
+	// defaultMaxPathDepth is used when [FileSystem] max_path_depth is not set
+	defaultMaxPathDepth = 8
+	// defaultMaxEntriesPerUser is used when [FileSystem] max_entries_per_user
+	// is not set
+	defaultMaxEntriesPerUser = 1000
+)
+
+var (
+	// ErrPathTooDeep is returned when a directory would lie more levels below
+	// its home directory than max_path_depth allows
+	ErrPathTooDeep = errors.New("path too deep")
+	// ErrTooManyEntries is returned when a user already has as many files and
+	// directories as max_entries_per_user allows
+	ErrTooManyEntries = errors.New("too many files and directories")
+)
+
+// maxPathDepth returns the configured depth limit, 0 for no limit
+func maxPathDepth() int {
+	return max(configuration.GetInt("FileSystem", "max_path_depth", defaultMaxPathDepth), 0)
+}
+
+// maxEntriesPerUser returns the configured entry limit, 0 for no limit
+func maxEntriesPerUser() int {
+	return max(configuration.GetInt("FileSystem", "max_entries_per_user", defaultMaxEntriesPerUser), 0)
+}
+
+// homeRelativeParts splits an absolute path below /home/<user> into the
+// parts after the home directory: /home/alice/a/b gives [a b]
+func homeRelativeParts(p string) []string {
+	rest := strings.TrimPrefix(path.Clean(p), "/home/")
+	_, below, found := strings.Cut(rest, "/")
+	if !found || below == "" {
+		return nil
+	}
+	return strings.Split(below, "/")
+}
+
+// isSystemEntry reports whether p is the home directory of a user or its
+// basic directory. Both are created by the system, so they neither count
+// against the user nor are refused by the limits.
+func isSystemEntry(p string) bool {
+	parts := homeRelativeParts(p)
+	return len(parts) == 0 || (len(parts) == 1 && parts[0] == "basic")
+}
+
+// countUserEntries counts the files and directories in the home directory
+// of username, without the home and basic directories. It stops at limit,
+// so a check costs no more than the limit allows, however large the tree.
+func (vfs *VFS) countUserEntries(username string, limit int) int {
+	home, exists := vfs.root.Children["home"].Children[username]
+	if !exists || !home.IsDir {
+		return 0
+	}
+
+	count := 0
+	pending := []*VirtualFile{home}
+	for len(pending) > 0 && count < limit {
+		dir := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, child := range dir.Children {
+			if child.IsDir {
+				pending = append(pending, child)
+				if dir == home && child.Name == "basic" {
+					continue
+				}
+			}
+			count++
+		}
+	}
+	return min(count, limit)
+}
+
+// checkEntryLimitsWithoutLock checks whether added new entries may be created
+// at p for the user owning p: directories must stay within max_path_depth and
+// the user within max_entries_per_user. Paths outside /home are not limited.
+func (vfs *VFS) checkEntryLimitsWithoutLock(p string, isDir bool, added int) error {
+	username := vfs.getUsernameFromPath(p)
+	if username == "" || isSystemEntry(p) {
+		return nil
+	}
+
+	if limit := maxPathDepth(); isDir && limit > 0 {
+		if depth := len(homeRelativeParts(p)); depth > limit {
+			return fmt.Errorf("%w: %s is %d levels below the home directory (max %d)", ErrPathTooDeep, p, depth, limit)
+		}
+	}
+	if limit := maxEntriesPerUser(); limit > 0 {
+		if count := vfs.countUserEntries(username, limit); count+added > limit {
+			return fmt.Errorf("%w: %d of %d used, remove some to make room", ErrTooManyEntries, count, limit)
+		}
+	}
+	return nil
+}
+
+// missingDirectoriesWithoutLock returns how many directories MkdirAll has to
+// create for p
+func (vfs *VFS) missingDirectoriesWithoutLock(p string) int {
+	missing := 0
+	for dir := path.Clean(p); dir != "/" && !vfs.existsWithoutLock(dir); dir = path.Dir(dir) {
+		missing++
+	}
+	return missing
+}
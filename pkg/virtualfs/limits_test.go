@@ -0,0 +1,133 @@
+package virtualfs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// newLimitsTestVFS returns a VFS without database with the home and basic
+// directories of alice. Without a loaded configuration the default limits
+// apply: 8 levels and 1000 entries.
+func newLimitsTestVFS(t *testing.T) *VFS {
+	t.Helper()
+	vfs := New(nil)
+	for _, dir := range []string{"/home/alice/basic", "/home/bob"} {
+		if err := vfs.MkdirAll(dir); err != nil {
+			t.Fatalf("MkdirAll(%s): %v", dir, err)
+		}
+	}
+	return vfs
+}
+
+// nestedPath returns a path depth levels below alice's home
+func nestedPath(depth int) string {
+	parts := []string{"/home/alice"}
+	for i := 1; i <= depth; i++ {
+		parts = append(parts, fmt.Sprintf("d%d", i))
+	}
+	return strings.Join(parts, "/")
+}
+
+// fillHome creates files and directories in alice's home until entries are
+// used. The files are spread over directories to stay within
+// max_files_per_directory.
+func fillHome(t *testing.T, vfs *VFS, entries int) {
+	t.Helper()
+	dir, files := "", 0
+	for used := vfs.countUserEntries("alice", entries); used < entries; used++ {
+		if dir == "" || files == 99 {
+			dir, files = fmt.Sprintf("/home/alice/fill%d", used), 0
+			if err := vfs.Mkdir(dir); err != nil {
+				t.Fatalf("Mkdir of entry %d below the limit: %v", used+1, err)
+			}
+			continue
+		}
+		if err := vfs.WriteFile(fmt.Sprintf("%s/f%d.bas", dir, files), "10 END", ""); err != nil {
+			t.Fatalf("WriteFile of entry %d below the limit: %v", used+1, err)
+		}
+		files++
+	}
+}
+
+// TestPathDepthLimit creates directories up to max_path_depth and expects
+// the next level to be refused
+func TestPathDepthLimit(t *testing.T) {
+	vfs := newLimitsTestVFS(t)
+
+	deepest := nestedPath(defaultMaxPathDepth)
+	if err := vfs.MkdirAll(deepest); err != nil {
+		t.Fatalf("MkdirAll at the maximum depth: %v", err)
+	}
+	if err := vfs.Mkdir(nestedPath(defaultMaxPathDepth + 1)); !errors.Is(err, ErrPathTooDeep) {
+		t.Errorf("Mkdir one level deeper: err = %v, want ErrPathTooDeep", err)
+	}
+	if err := vfs.MkdirAll(nestedPath(defaultMaxPathDepth + 1)); !errors.Is(err, ErrPathTooDeep) {
+		t.Errorf("MkdirAll one level deeper: err = %v, want ErrPathTooDeep", err)
+	}
+
+	// The depth limit is for directories, a file in the deepest one is fine
+	if err := vfs.WriteFile(deepest+"/f.bas", "10 END", ""); err != nil {
+		t.Errorf("writing a file at the maximum depth: %v", err)
+	}
+}
+
+// TestEntryLimit fills alice's home up to max_entries_per_user and expects
+// further files and directories to be refused, while bob is not affected
+func TestEntryLimit(t *testing.T) {
+	vfs := newLimitsTestVFS(t)
+
+	// The home and basic directories are created by the system and are free
+	if got := vfs.countUserEntries("alice", defaultMaxEntriesPerUser); got != 0 {
+		t.Fatalf("countUserEntries with only home and basic = %d, want 0", got)
+	}
+
+	// A file in basic counts, only the directory itself is free
+	if err := vfs.WriteFile("/home/alice/basic/f.bas", "10 END", ""); err != nil {
+		t.Fatalf("writing a file in basic: %v", err)
+	}
+	fillHome(t, vfs, defaultMaxEntriesPerUser)
+	if got := vfs.countUserEntries("alice", defaultMaxEntriesPerUser); got != defaultMaxEntriesPerUser {
+		t.Fatalf("countUserEntries = %d, want %d", got, defaultMaxEntriesPerUser)
+	}
+
+	if err := vfs.Mkdir("/home/alice/one-more"); !errors.Is(err, ErrTooManyEntries) {
+		t.Errorf("Mkdir at the limit: err = %v, want ErrTooManyEntries", err)
+	}
+	if err := vfs.WriteFile("/home/alice/one-more.bas", "10 END", ""); !errors.Is(err, ErrTooManyEntries) {
+		t.Errorf("WriteFile at the limit: err = %v, want ErrTooManyEntries", err)
+	}
+	// Overwriting an existing file creates no entry
+	if err := vfs.WriteFile("/home/alice/basic/f.bas", "20 END", ""); err != nil {
+		t.Errorf("overwriting a file at the limit: %v", err)
+	}
+	if err := vfs.Mkdir("/home/bob/mine"); err != nil {
+		t.Errorf("Mkdir for another user: %v", err)
+	}
+}
+
+// TestMkdirAllCreatesNothingWhenRefused checks that a refused MkdirAll does
+// not leave the parent directories it would have created behind
+func TestMkdirAllCreatesNothingWhenRefused(t *testing.T) {
+	vfs := newLimitsTestVFS(t)
+
+	if err := vfs.MkdirAll(nestedPath(defaultMaxPathDepth + 1)); !errors.Is(err, ErrPathTooDeep) {
+		t.Fatalf("MkdirAll too deep: err = %v, want ErrPathTooDeep", err)
+	}
+	if vfs.existsWithoutLock(nestedPath(1)) {
+		t.Errorf("refused MkdirAll left %s behind", nestedPath(1))
+	}
+
+	// Two entries left, but the path needs three directories
+	fillHome(t, vfs, defaultMaxEntriesPerUser-2)
+	if err := vfs.MkdirAll("/home/alice/x/y/z"); !errors.Is(err, ErrTooManyEntries) {
+		t.Fatalf("MkdirAll over the limit: err = %v, want ErrTooManyEntries", err)
+	}
+	if vfs.existsWithoutLock("/home/alice/x") {
+		t.Error("refused MkdirAll left /home/alice/x behind")
+	}
+	if err := vfs.MkdirAll("/home/alice/x/y"); err != nil {
+		t.Errorf("MkdirAll of two directories with two entries left: %v", err)
+	}
+}
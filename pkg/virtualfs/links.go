@@ -0,0 +1,104 @@
+package virtualfs
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// exampleStoreDir is the canonical example store on disk. Link entries point
+// into it by file name, so an updated example is seen by every user at once.
+const exampleStoreDir = "examples"
+
+// readExample returns the current content of a file in the example store
+func readExample(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(exampleStoreDir, filepath.Base(name)))
+}
+
+// IsLink reports whether the file is a read-only link into the example store
+func (f *VirtualFile) IsLink() bool {
+	return f.LinkTarget != ""
+}
+
+// data returns the content of a file, following a link into the example store
+func (f *VirtualFile) data() ([]byte, error) {
+	if !f.IsLink() {
+		return f.Content, nil
+	}
+	content, err := readExample(f.LinkTarget)
+	if err != nil {
+		return nil, fmt.Errorf("broken link to example %s", f.LinkTarget)
+	}
+	return content, nil
+}
+
+// markLinkWithoutLock turns a file loaded from the database into a link when
+// its row names a link target
+func (vfs *VFS) markLinkWithoutLock(path, target string) {
+	if target == "" {
+		return
+	}
+	if node, remaining, err := vfs.resolvePathInternalWithoutLock(path); err == nil && remaining == "" && !node.IsDir {
+		node.Content = nil
+		node.LinkTarget = target
+	}
+}
+
+// LinkExample places a read-only link to the example name at path instead of
+// a copy. A copy the user has changed is kept, an unchanged copy is replaced
+// by the link. Linked files take no space in virtual_files or the quota.
+func (vfs *VFS) LinkExample(username, path, name string) error {
+	vfs.mu.Lock()
+	defer vfs.mu.Unlock()
+	return vfs.linkExampleWithoutLock(username, path, name)
+}
+
+// linkExampleWithoutLock implements LinkExample for callers holding the lock
+func (vfs *VFS) linkExampleWithoutLock(username, path, name string) error {
+	example, err := readExample(name)
+	if err != nil {
+		return fmt.Errorf("example %s not found: %v", name, err)
+	}
+
+	if node, remaining, err := vfs.resolvePathInternalWithoutLock(path); err == nil && remaining == "" {
+		if node.IsDir {
+			return fmt.Errorf("is a directory: %s", path)
+		}
+		if node.ReadOnly {
+			vfsDebugLog("Keeping locked file %s", path)
+			return nil
+		}
+		if !node.IsLink() && !bytes.Equal(node.Content, example) {
+			vfsDebugLog("Keeping changed copy of example %s at %s", name, path)
+			return nil
+		}
+	}
+
+	target := filepath.Base(name)
+	if vfs.db != nil && username != "" && username != "guest" {
+		// The conflict clause leaves rows alone that hold a changed copy
+		result, err := vfs.db.Exec(
+			`INSERT INTO virtual_files (username, path, content, is_dir, mod_time, link_target) VALUES (?, ?, '', 0, ?, ?)
+			 ON CONFLICT(username, path) DO UPDATE SET content = '', mod_time = excluded.mod_time, link_target = excluded.link_target
+			 WHERE COALESCE(virtual_files.link_target, '') != '' OR CAST(virtual_files.content AS TEXT) = ?`,
+			username, path, time.Now().Unix(), target, string(example),
+		)
+		if err != nil {
+			return fmt.Errorf("database error linking %s: %v", path, err)
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			vfsDebugLog("Keeping changed copy of example %s stored at %s", name, path)
+			return nil
+		}
+	}
+
+	if err := vfs.createFileWithoutLock(path, "", time.Now()); err != nil {
+		return err
+	}
+	node, _, _ := vfs.resolvePathInternalWithoutLock(path)
+	node.Content = nil
+	node.LinkTarget = target
+	return nil
+}
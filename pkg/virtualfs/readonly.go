@@ -0,0 +1,57 @@
+package virtualfs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrReadOnly is returned when a locked file is to be written or removed
+var ErrReadOnly = errors.New("file is locked")
+
+// lockedError reports a refused change to the locked file at path
+func lockedError(path string) error {
+	return fmt.Errorf("%w: %s (use unlock to change it)", ErrReadOnly, path)
+}
+
+// markReadOnlyWithoutLock locks a file loaded from the database when its row
+// has the read_only flag set
+func (vfs *VFS) markReadOnlyWithoutLock(path string, readOnly bool) {
+	if !readOnly {
+		return
+	}
+	if node, remaining, err := vfs.resolvePathInternalWithoutLock(path); err == nil && remaining == "" && !node.IsDir {
+		node.ReadOnly = true
+	}
+}
+
+// SetReadOnly locks or unlocks the file at path. A locked file can still be
+// read, but WriteFile and Remove refuse it. The flag belongs to the owner of
+// the home directory the file is in.
+func (vfs *VFS) SetReadOnly(path string, readOnly bool) error {
+	vfs.mu.Lock()
+	defer vfs.mu.Unlock()
+
+	node, remaining, err := vfs.resolvePathInternalWithoutLock(path)
+	if err != nil || remaining != "" {
+		return fmt.Errorf("file not found: %s", path)
+	}
+	if node.IsDir {
+		return fmt.Errorf("is a directory: %s", path)
+	}
+
+	username := vfs.getUsernameFromPath(path)
+	if vfs.db != nil && username != "" && username != "guest" {
+		// The row may not be written yet if the file was saved a moment ago
+		_, err := vfs.db.Exec(
+			`INSERT INTO virtual_files (username, path, content, is_dir, mod_time, link_target, read_only) VALUES (?, ?, ?, 0, ?, ?, ?)
+			 ON CONFLICT(username, path) DO UPDATE SET read_only = excluded.read_only`,
+			username, path, string(node.Content), node.ModTime.Unix(), node.LinkTarget, readOnly,
+		)
+		if err != nil {
+			return fmt.Errorf("database error locking %s: %v", path, err)
+		}
+	}
+	node.ReadOnly = readOnly
+	vfsDebugLog("SetReadOnly(%s) = %v", path, readOnly)
+	return nil
+}
@@ -0,0 +1,47 @@
+package virtualfs
+
+import "fmt"
+
+// ReadFileChunk reads up to max bytes of the file at the absolute path,
+// starting at offset, and returns them together with the current file size.
+// An offset at or behind the end gives an empty chunk. Readers such as the
+// cat pager use it to walk through a file without copying all of it.
+func (vfs *VFS) ReadFileChunk(path, sessionID string, offset, max int) ([]byte, int, error) {
+	if offset < 0 || max <= 0 {
+		return nil, 0, fmt.Errorf("invalid chunk: offset %d, length %d", offset, max)
+	}
+	path = CleanPath(path)
+	if err := vfs.CheckAccess(path, sessionID, false); err != nil {
+		return nil, 0, err
+	}
+
+	vfs.mu.RLock()
+	node, remaining, err := vfs.resolvePathInternalWithoutLock(path)
+	if err != nil || remaining != "" {
+		vfs.mu.RUnlock()
+		return nil, 0, fmt.Errorf("path not found: %s", path)
+	}
+	if node.IsDir {
+		vfs.mu.RUnlock()
+		return nil, 0, fmt.Errorf("is a directory: %s", path)
+	}
+	content, err := node.data()
+	if err != nil {
+		vfs.mu.RUnlock()
+		return nil, 0, err
+	}
+	size := len(content)
+	var chunk []byte
+	if offset < size {
+		end := offset + max
+		if end > size {
+			end = size
+		}
+		// Copy, a later write replaces the content of the node
+		chunk = append([]byte(nil), content[offset:end]...)
+	}
+	vfs.mu.RUnlock()
+
+	vfs.notifyDiskAccess(sessionID, false)
+	return chunk, size, nil
+}
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -25,12 +27,14 @@ func vfsDebugLog(format string, args ...interface{}) {
 
 // VirtualFile repräsentiert eine Datei oder ein Verzeichnis im VFS
 type VirtualFile struct {
-	Name     string
-	IsDir    bool
-	Content  []byte
-	ModTime  time.Time
-	Children map[string]*VirtualFile
-	Parent   *VirtualFile
+	Name       string
+	IsDir      bool
+	Content    []byte
+	ModTime    time.Time
+	Children   map[string]*VirtualFile
+	Parent     *VirtualFile
+	LinkTarget string // Example the file links to, "" for a regular file
+	ReadOnly   bool   // Locked by its owner: readable, but not written or removed
 }
 
 // TinyOSProvider ist eine Schnittstelle für den Zugriff auf TinyOS-Funktionen
@@ -56,6 +60,9 @@ type UserStats struct {
 	HomeDirectoryFiles int
 	MaxFilesPerDir     int
 	TotalFiles         int
+	EntryCount         int // Files and directories without home and basic
+	MaxEntries         int // 0 = no limit
+	MaxPathDepth       int // 0 = no limit
 }
 
 // UserStorageInfo contains storage usage information for a user
@@ -64,6 +71,19 @@ type UserStorageInfo struct {
 	TotalKB int
 }
 
+// DirectoryUsage holds the summed content size of a directory and everything below it
+type DirectoryUsage struct {
+	Path  string
+	Bytes int
+}
+
+// FileEntry describes a file found by ListUserFiles
+type FileEntry struct {
+	Path   string // Absolute path
+	Bytes  int    // Stored size, 0 for links
+	IsLink bool   // Read-only link into the example store
+}
+
 // New erstellt ein neues virtuelles Dateisystem
 func New(db *sql.DB) *VFS {
 	// Erstelle nur das Root-Verzeichnis und die Verzeichnisse für /home und /system
@@ -290,7 +310,7 @@ func (vfs *VFS) InitializeUserVFS(username string) error {
 		// Load all files next
 		vfsDebugLog("Starting to load files for user: %s", username)
 		fileRows, err := vfs.db.Query(
-			`SELECT path, content, mod_time FROM virtual_files 
+			`SELECT path, content, mod_time, COALESCE(link_target, ''), COALESCE(read_only, 0) FROM virtual_files 
 			 WHERE username = ? AND is_dir = 0`,
 			username)
 
@@ -301,10 +321,11 @@ func (vfs *VFS) InitializeUserVFS(username string) error {
 		fileCount := 0
 		for fileRows.Next() {
 			fileCount++
-			var path string
+			var path, linkTarget string
 			var content []byte
 			var modTime int64
-			if err := fileRows.Scan(&path, &content, &modTime); err != nil {
+			var readOnly bool
+			if err := fileRows.Scan(&path, &content, &modTime, &linkTarget, &readOnly); err != nil {
 				vfsDebugLog("Error scanning file data for user %s: %v", username, err)
 				fileRows.Close()
 				return fmt.Errorf("error scanning file data: %v", err)
@@ -319,6 +340,8 @@ func (vfs *VFS) InitializeUserVFS(username string) error {
 				vfsDebugLog("Error creating file %s: %v", path, err)
 				// We continue even if an error occurs
 			} else {
+				vfs.markLinkWithoutLock(path, linkTarget)
+				vfs.markReadOnlyWithoutLock(path, readOnly)
 				vfsDebugLog("File loaded: %s", path)
 			}
 		}
@@ -333,9 +356,9 @@ func (vfs *VFS) InitializeUserVFS(username string) error {
 	return nil
 }
 
-// copyExampleFilesToUser kopiert Beispieldateien in das Benutzerverzeichnis (ohne Lock, da bereits in InitializeUserVFS gelockt)
+// copyExampleFilesToUser verlinkt die Beispieldateien in das Benutzerverzeichnis (ohne Lock, da bereits in InitializeUserVFS gelockt)
 func (vfs *VFS) copyExampleFilesToUser(username, basicPath, homePath string) {
-	vfsDebugLog("Copying example files for user: %s", username)
+	vfsDebugLog("Linking example files for user: %s", username)
 
 	// Skip example files for Dyson user - Dyson has special files managed separately
 	if username == "dyson" {
@@ -343,8 +366,7 @@ func (vfs *VFS) copyExampleFilesToUser(username, basicPath, homePath string) {
 		return
 	}
 
-	examplesDir := "examples"
-	entries, err := os.ReadDir(examplesDir)
+	entries, err := os.ReadDir(exampleStoreDir)
 	if err != nil {
 		vfsDebugLog("Error reading examples directory: %v", err)
 		return
@@ -358,12 +380,6 @@ func (vfs *VFS) copyExampleFilesToUser(username, basicPath, homePath string) {
 		name := entry.Name()
 		lowerName := strings.ToLower(name)
 
-		contentBytes, err := os.ReadFile(filepath.Join(examplesDir, name))
-		if err != nil {
-			vfsDebugLog("Error reading example file %s: %v", name, err)
-			continue
-		}
-
 		var targetPath string
 		// BASIC programs and SID files go to /basic subdirectory
 		if strings.HasSuffix(lowerName, ".bas") || strings.HasSuffix(lowerName, ".sid") {
@@ -375,31 +391,16 @@ func (vfs *VFS) copyExampleFilesToUser(username, basicPath, homePath string) {
 			// Skip other file types
 			continue
 		}
-		err = vfs.createFileInMemory(targetPath, string(contentBytes), time.Now())
-		if err != nil {
-			vfsDebugLog("Error creating %s: %v", name, err)
+		if err := vfs.linkExampleWithoutLock(username, targetPath, name); err != nil {
+			vfsDebugLog("Error linking %s: %v", name, err)
 			continue
 		}
 
-		// Speichere die Datei auch in der Datenbank für persistente Benutzer
-		if vfs.db != nil && username != "guest" {
-			modTime := time.Now().Unix()
-			_, dbErr := vfs.db.Exec(
-				`INSERT OR REPLACE INTO virtual_files (username, path, content, is_dir, mod_time) VALUES (?, ?, ?, ?, ?)`,
-				username, targetPath, string(contentBytes), 0, modTime,
-			)
-			if dbErr != nil {
-				vfsDebugLog("Error saving %s to database: %v", name, dbErr)
-			} else {
-				vfsDebugLog("%s saved to database for user %s", name, username)
-			}
-		}
-
-		vfsDebugLog("%s successfully created at %s", name, targetPath)
+		vfsDebugLog("%s successfully linked at %s", name, targetPath)
 		fileCount++
 	}
 
-	vfsDebugLog("Copied %d example files for user %s", fileCount, username)
+	vfsDebugLog("Linked %d example files for user %s", fileCount, username)
 }
 
 // createDirectoryInMemory creates a directory only in memory without database operation
@@ -677,7 +678,7 @@ func (vfs *VFS) initializeUserVFSWithoutLock(username string) error {
 
 		// Load all files next
 		fileRows, err := vfs.db.Query(
-			`SELECT path, content, mod_time FROM virtual_files 
+			`SELECT path, content, mod_time, COALESCE(link_target, ''), COALESCE(read_only, 0) FROM virtual_files 
 			 WHERE username = ? AND is_dir = 0`,
 			username)
 
@@ -687,10 +688,11 @@ func (vfs *VFS) initializeUserVFSWithoutLock(username string) error {
 
 		// Process all files
 		for fileRows.Next() {
-			var path string
+			var path, linkTarget string
 			var content []byte
 			var modTime int64
-			if err := fileRows.Scan(&path, &content, &modTime); err != nil {
+			var readOnly bool
+			if err := fileRows.Scan(&path, &content, &modTime, &linkTarget, &readOnly); err != nil {
 				fileRows.Close()
 				return fmt.Errorf("error scanning file data: %v", err)
 			}
@@ -702,6 +704,8 @@ func (vfs *VFS) initializeUserVFSWithoutLock(username string) error {
 				vfsDebugLog("Error creating file %s: %v", path, err)
 				// Continue even if there is an error
 			} else {
+				vfs.markLinkWithoutLock(path, linkTarget)
+				vfs.markReadOnlyWithoutLock(path, readOnly)
 				vfsDebugLog("File loaded: %s", path)
 			}
 		}
@@ -819,6 +823,15 @@ func (vfs *VFS) createFileWithoutLock(path, content string, modTime time.Time) e
 
 // ReadFile reads the content of a file in the VFS
 func (vfs *VFS) ReadFile(path string, sessionID string) (string, error) {
+	content, err := vfs.readFile(path, sessionID)
+	if err == nil {
+		vfs.notifyDiskAccess(sessionID, false)
+	}
+	return content, err
+}
+
+// readFile implements ReadFile
+func (vfs *VFS) readFile(path string, sessionID string) (string, error) {
 	vfs.mu.RLock()
 	defer vfs.mu.RUnlock()
 	vfsDebugLog("ReadFile: path=%s, sessionID=%s", path, sessionID) // If path is relative, convert to current working directory
@@ -850,6 +863,12 @@ func (vfs *VFS) ReadFile(path string, sessionID string) (string, error) {
 		path = currentPath + "/" + path
 		vfsDebugLog("ReadFile: Relative path detected, converted to: %s", path)
 	}
+	if strings.HasPrefix(path, "/") {
+		path = CleanPath(path)
+	}
+	if err := vfs.CheckAccess(path, sessionID, false); err != nil {
+		return "", err
+	}
 	node, remaining, err := vfs.ResolvePath(path)
 	vfsDebugLog("After ResolvePath: node=%v, remaining=%s, err=%v", node, remaining, err)
 	if err != nil && remaining == "" { // Error resolving exact path
@@ -864,12 +883,35 @@ func (vfs *VFS) ReadFile(path string, sessionID string) (string, error) {
 		vfsDebugLog("Node is a directory: %s", path)
 		return "", fmt.Errorf("is a directory: %s", path)
 	}
+	content, err := node.data()
+	if err != nil {
+		return "", err
+	}
 	vfsDebugLog("File content is being returned: %s", path)
-	return string(node.Content), nil
+	return string(content), nil
 }
 
 // WriteFile writes content to a file in the VFS. Creates the file if it does not exist.
 func (vfs *VFS) WriteFile(path, content string, sessionID string) error {
+	if err := vfs.writeFile(path, content, sessionID); err != nil {
+		return err
+	}
+	vfs.notifyDiskAccess(sessionID, true)
+	return nil
+}
+
+// notifyDiskAccess tells TinyOS about file access of a session, e.g. for the drive sound
+func (vfs *VFS) notifyDiskAccess(sessionID string, write bool) {
+	if sessionID == "" || vfs.os == nil {
+		return
+	}
+	if notifier, ok := vfs.os.(interface{ DiskAccess(string, bool) }); ok {
+		notifier.DiskAccess(sessionID, write)
+	}
+}
+
+// writeFile implements WriteFile
+func (vfs *VFS) writeFile(path, content string, sessionID string) error {
 	vfsDebugLog("WriteFile Start for path: %s (with SessionID: %s)", path, sessionID)
 
 	// SICHERHEIT: Überprüfe Dateigröße vor dem Speichern
@@ -923,6 +965,10 @@ func (vfs *VFS) WriteFile(path, content string, sessionID string) error {
 		path = currentPath + "/" + path
 		vfsDebugLog("WriteFile - Converted relative path to absolute: %s", path)
 	}
+	path = CleanPath(path)
+	if err := vfs.CheckAccess(path, sessionID, true); err != nil {
+		return err
+	}
 
 	// Extract directory and file name, ensure we are using Unix paths
 	dirPath := filepath.Dir(path)
@@ -956,7 +1002,11 @@ func (vfs *VFS) WriteFile(path, content string, sessionID string) error {
 				vfsDebugLog("WriteFile - Cannot overwrite directory")
 				return fmt.Errorf("cannot overwrite directory with file: %s", fileName)
 			}
+			if existingFile.ReadOnly {
+				return lockedError(fileName)
+			}
 			existingFile.Content = []byte(content)
+			existingFile.LinkTarget = "" // Writing to a link makes it the user's own copy
 			existingFile.ModTime = time.Now()
 			vfsDebugLog("WriteFile - Updated existing file: %s", fileName)
 		} else {
@@ -965,6 +1015,10 @@ func (vfs *VFS) WriteFile(path, content string, sessionID string) error {
 				log.Printf("[VFS-SECURITY] File creation blocked in guest directory: %v", err)
 				return err
 			}
+			if err := vfs.checkEntryLimitsWithoutLock(path, false, 1); err != nil {
+				log.Printf("[VFS-SECURITY] File creation blocked in guest directory: %v", err)
+				return err
+			}
 
 			// Create new file
 			newFile := &VirtualFile{
@@ -1005,7 +1059,11 @@ func (vfs *VFS) WriteFile(path, content string, sessionID string) error {
 				vfsDebugLog("WriteFile - Cannot overwrite directory")
 				return fmt.Errorf("cannot overwrite directory with file: %s", fileName)
 			}
+			if existingFile.ReadOnly {
+				return lockedError(fileName)
+			}
 			existingFile.Content = []byte(content)
+			existingFile.LinkTarget = "" // Writing to a link makes it the user's own copy
 			existingFile.ModTime = time.Now()
 			vfsDebugLog("WriteFile - Updated existing file: %s", fileName)
 		} else {
@@ -1014,6 +1072,10 @@ func (vfs *VFS) WriteFile(path, content string, sessionID string) error {
 				log.Printf("[VFS-SECURITY] File creation blocked in user directory: %v", err)
 				return err
 			}
+			if err := vfs.checkEntryLimitsWithoutLock(path, false, 1); err != nil {
+				log.Printf("[VFS-SECURITY] File creation blocked in user directory: %v", err)
+				return err
+			}
 
 			// Create new file
 			newFile := &VirtualFile{
@@ -1074,7 +1136,11 @@ func (vfs *VFS) WriteFile(path, content string, sessionID string) error {
 		if existingFile.IsDir {
 			return fmt.Errorf("cannot overwrite directory with file: %s", fileName)
 		}
+		if existingFile.ReadOnly {
+			return lockedError(fileName)
+		}
 		existingFile.Content = []byte(content)
+		existingFile.LinkTarget = ""
 		existingFile.ModTime = time.Now()
 		vfsDebugLog("WriteFile - Updated existing file: %s", fileName)
 	} else {
@@ -1145,7 +1211,14 @@ func (vfs *VFS) GetUserStats(username string) (*UserStats, error) {
 		Username:       username,
 		MaxDirectories: configuration.GetInt("FileSystem", "max_directories_per_user", 20),
 		MaxFilesPerDir: configuration.GetInt("FileSystem", "max_files_per_directory", 100),
+		MaxEntries:     maxEntriesPerUser(),
+		MaxPathDepth:   maxPathDepth(),
+	}
+	countLimit := stats.MaxEntries
+	if countLimit == 0 {
+		countLimit = math.MaxInt
 	}
+	stats.EntryCount = vfs.countUserEntries(username, countLimit)
 
 	// Zähle Verzeichnisse
 	stats.DirectoryCount = vfs.countUserDirectoriesLocked(username)
@@ -1202,6 +1275,12 @@ func (vfs *VFS) Exists(path string, sessionID string) bool {
 		}
 	}
 
+	if strings.HasPrefix(path, "/") {
+		path = CleanPath(path)
+		if vfs.CheckAccess(path, sessionID, false) != nil {
+			return false
+		}
+	}
 	_, remaining, err := vfs.ResolvePath(path)
 	return err == nil && remaining == ""
 }
@@ -1504,6 +1583,45 @@ func (vfs *VFS) ListDir(path string) ([]string, error) {
 	return result, nil
 }
 
+// DirEntry describes a directory entry for ls -l
+type DirEntry struct {
+	Name     string
+	IsDir    bool
+	Bytes    int // Stored size, 0 for directories and links
+	ModTime  time.Time
+	IsLink   bool
+	ReadOnly bool
+}
+
+// ListDirLong returns the entries of a directory with their details, sorted
+// by name. Access is checked as in ListDir.
+func (vfs *VFS) ListDirLong(path string) ([]DirEntry, error) {
+	if _, err := vfs.ListDir(path); err != nil {
+		return nil, err
+	}
+
+	vfs.mu.RLock()
+	defer vfs.mu.RUnlock()
+
+	node, remaining, err := vfs.resolvePathInternalWithoutLock(path)
+	if err != nil || remaining != "" || !node.IsDir {
+		return nil, fmt.Errorf("is not a directory: %s", path)
+	}
+	entries := make([]DirEntry, 0, len(node.Children))
+	for name, child := range node.Children {
+		entries = append(entries, DirEntry{
+			Name:     name,
+			IsDir:    child.IsDir,
+			Bytes:    len(child.Content),
+			ModTime:  child.ModTime,
+			IsLink:   child.IsLink(),
+			ReadOnly: child.ReadOnly,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
 // Mkdir creates a single directory
 func (vfs *VFS) Mkdir(path string) error {
 	vfs.mu.Lock()
@@ -1522,6 +1640,11 @@ func (vfs *VFS) Mkdir(path string) error {
 		return nil // Directory already exists, no error
 	}
 
+	if err := vfs.checkEntryLimitsWithoutLock(path, true, 1); err != nil {
+		log.Printf("[VFS-SECURITY] Directory creation blocked: %v", err)
+		return err
+	}
+
 	// Create the directory
 	return vfs.createDirectoryWithoutLock(path)
 }
@@ -1552,6 +1675,13 @@ func (vfs *VFS) MkdirAll(path string) error {
 		return nil // Directory already exists, no error
 	}
 
+	// Check the limits for the whole path first, so no part of it is created
+	// when the last directory would be refused
+	if err := vfs.checkEntryLimitsWithoutLock(path, true, vfs.missingDirectoriesWithoutLock(path)); err != nil {
+		log.Printf("[VFS-SECURITY] Directory creation blocked: %v", err)
+		return err
+	}
+
 	log.Printf("[VFS-MKDIRALL-DEBUG] Erstelle Verzeichnisstruktur für: %s", path)
 	// Create all parent directories
 	components := strings.Split(path, "/")
@@ -1618,6 +1748,9 @@ func (vfs *VFS) Remove(path string) error {
 	if node.IsDir && len(node.Children) > 0 {
 		return fmt.Errorf("Directory is not empty: %s", path)
 	}
+	if node.ReadOnly {
+		return lockedError(filepath.Base(path))
+	}
 
 	// Determine parent directory and remove child node
 	parentPath := filepath.Dir(path)
@@ -1762,7 +1895,46 @@ func (vfs *VFS) safeInitializeUserVFS(username string) error {
 // initializeGuestVFSWithoutLock initializes the VFS for guest users without mutex lock
 // This function is called by resolvePathInternal, where a mutex lock is already held
 func (vfs *VFS) initializeGuestVFSWithoutLock() error {
+	guestDir, basicDir, err := vfs.createGuestHomeWithoutLock()
+	if err != nil {
+		return err
+	}
+	if err := linkGuestExamples(guestDir, basicDir); err != nil {
+		return err
+	}
+
+	// Check created files in both directories
+	vfsDebugLog("[GUEST] Checking created files in guest home and basic directories")
+	homeFileCount := 0
+	basicFileCount := 0
 
+	for name, file := range guestDir.Children {
+		if file.IsDir {
+			if name == "basic" {
+				// Count files in basic directory
+				for basicName, basicFile := range file.Children {
+					if !basicFile.IsDir {
+						basicFileCount++
+						vfsDebugLog("[GUEST] Found in basic directory: %s", basicName)
+					}
+				}
+			}
+		} else {
+			homeFileCount++
+			vfsDebugLog("[GUEST] Found in guest home directory: %s", name)
+		}
+	}
+
+	vfsDebugLog("[GUEST] Number of files in guest home directory: %d", homeFileCount)
+	vfsDebugLog("[GUEST] Number of files in basic directory: %d", basicFileCount)
+
+	vfsDebugLog("[GUEST] Guest VFS successfully initialized with example programs")
+	return nil
+}
+
+// createGuestHomeWithoutLock replaces /home/guest with an empty home that
+// only holds the basic directory
+func (vfs *VFS) createGuestHomeWithoutLock() (*VirtualFile, *VirtualFile, error) {
 	// Create /home if it doesn't exist
 	if vfs.root.Children["home"] == nil {
 		vfs.root.Children["home"] = &VirtualFile{
@@ -1795,11 +1967,17 @@ func (vfs *VFS) initializeGuestVFSWithoutLock() error {
 
 	// Debug: Check if the home directory was created correctly
 	if vfs.root.Children["home"].Children["guest"] == nil {
-		return fmt.Errorf("Guest home directory was not created")
+		return nil, nil, fmt.Errorf("Guest home directory was not created")
 	}
 
-	examplesDir := "examples"
-	entries, err := os.ReadDir(examplesDir)
+	return guestDir, basicDir, nil
+}
+
+// linkGuestExamples links the example store into a guest home: BASIC
+// programs and SID files into basic, text files into the home itself. Files
+// the guest wrote under an example's name are kept.
+func linkGuestExamples(guestDir, basicDir *VirtualFile) error {
+	entries, err := os.ReadDir(exampleStoreDir)
 	if err != nil {
 		return fmt.Errorf("Error reading examples directory: %v", err)
 	}
@@ -1810,12 +1988,6 @@ func (vfs *VFS) initializeGuestVFSWithoutLock() error {
 		name := entry.Name()
 		lowerName := strings.ToLower(name)
 
-		contentBytes, err := os.ReadFile(filepath.Join(examplesDir, name))
-		if err != nil {
-			vfsDebugLog("[GUEST] Error reading example file %s: %v", name, err)
-			continue
-		}
-
 		var targetDir *VirtualFile
 		// BASIC programs and SID files go to /basic subdirectory
 		if strings.HasSuffix(lowerName, ".bas") || strings.HasSuffix(lowerName, ".sid") {
@@ -1828,43 +2000,21 @@ func (vfs *VFS) initializeGuestVFSWithoutLock() error {
 			continue
 		}
 
-		// Create the file in the appropriate directory
+		if existing, exists := targetDir.Children[name]; exists && !existing.IsLink() {
+			continue
+		}
+
+		// Link the example into the appropriate directory
 		file := &VirtualFile{
-			Name:     name,
-			IsDir:    false,
-			Content:  contentBytes,
-			ModTime:  time.Now(),
-			Parent:   targetDir,
-			Children: nil}
+			Name:       name,
+			IsDir:      false,
+			ModTime:    time.Now(),
+			Parent:     targetDir,
+			Children:   nil,
+			LinkTarget: name}
 		targetDir.Children[name] = file
 	}
 
-	// Check created files in both directories
-	vfsDebugLog("[GUEST] Checking created files in guest home and basic directories")
-	homeFileCount := 0
-	basicFileCount := 0
-
-	for name, file := range guestDir.Children {
-		if file.IsDir {
-			if name == "basic" {
-				// Count files in basic directory
-				for basicName, basicFile := range file.Children {
-					if !basicFile.IsDir {
-						basicFileCount++
-						vfsDebugLog("[GUEST] Found in basic directory: %s", basicName)
-					}
-				}
-			}
-		} else {
-			homeFileCount++
-			vfsDebugLog("[GUEST] Found in guest home directory: %s", name)
-		}
-	}
-
-	vfsDebugLog("[GUEST] Number of files in guest home directory: %d", homeFileCount)
-	vfsDebugLog("[GUEST] Number of files in basic directory: %d", basicFileCount)
-
-	vfsDebugLog("[GUEST] Guest VFS successfully initialized with example programs")
 	return nil
 }
 
@@ -1874,6 +2024,44 @@ func (vfs *VFS) InitializeGuestVFS() error {
 	return vfs.initializeGuestVFSWithoutLock()
 }
 
+// InitializeScratchGuestVFS gives the guest an empty home without the
+// example programs, which makes a scratch guest session start faster.
+// LinkGuestExamples adds them later on request.
+func (vfs *VFS) InitializeScratchGuestVFS() error {
+	vfs.mu.Lock()
+	defer vfs.mu.Unlock()
+	_, _, err := vfs.createGuestHomeWithoutLock()
+	return err
+}
+
+// LinkGuestExamples links the example programs into the guest home as
+// InitializeGuestVFS does, keeping files the guest wrote under the same name
+func (vfs *VFS) LinkGuestExamples() error {
+	vfs.mu.Lock()
+	defer vfs.mu.Unlock()
+
+	home, exists := vfs.root.Children["home"]
+	if !exists || home.Children["guest"] == nil {
+		return vfs.initializeGuestVFSWithoutLock()
+	}
+	guestDir := home.Children["guest"]
+	basicDir, exists := guestDir.Children["basic"]
+	if !exists || !basicDir.IsDir {
+		if exists {
+			return fmt.Errorf("Exists as file: /home/guest/basic")
+		}
+		basicDir = &VirtualFile{
+			Name:     "basic",
+			IsDir:    true,
+			Children: make(map[string]*VirtualFile),
+			Parent:   guestDir,
+			ModTime:  time.Now(),
+		}
+		guestDir.Children["basic"] = basicDir
+	}
+	return linkGuestExamples(guestDir, basicDir)
+}
+
 // CleanupGuestVFS deletes all files in the guest VFS
 // This function should be called when a guest session ends
 func (vfs *VFS) CleanupGuestVFS() error {
@@ -1896,6 +2084,34 @@ func (vfs *VFS) CleanupGuestVFS() error {
 	return nil
 }
 
+// RenameUserHome moves a loaded home directory from /home/oldName to
+// /home/newName in memory. The database rows are migrated by the caller, in
+// the same transaction as the rest of the rename.
+func (vfs *VFS) RenameUserHome(oldName, newName string) error {
+	vfs.mu.Lock()
+	defer vfs.mu.Unlock()
+
+	home, exists := vfs.root.Children["home"]
+	if !exists {
+		return nil
+	}
+	if _, taken := home.Children[newName]; taken {
+		return fmt.Errorf("home directory already exists: /home/%s", newName)
+	}
+	dir, exists := home.Children[oldName]
+	if !exists {
+		return nil // Not loaded, the next login reads the migrated rows
+	}
+	delete(home.Children, oldName)
+	dir.Name = newName
+	home.Children[newName] = dir
+	if _, loaded := vfs.userRoots[oldName]; loaded {
+		delete(vfs.userRoots, oldName)
+		vfs.userRoots[newName] = dir
+	}
+	return nil
+}
+
 // SyncExamplePrograms synchronizes the example programs into a user's home directory
 // Similar to InitializeGuestVFS, but for regular users
 func (vfs *VFS) SyncExamplePrograms(username string) error {
@@ -1933,9 +2149,8 @@ func (vfs *VFS) SyncExamplePrograms(username string) error {
 		}
 	}
 
-	// Copy example programs into the appropriate directories
-	examplesDir := "examples"
-	entries, err := os.ReadDir(examplesDir)
+	// Link example programs into the appropriate directories
+	entries, err := os.ReadDir(exampleStoreDir)
 	if err != nil {
 		return fmt.Errorf("error reading examples directory: %v", err)
 	}
@@ -1946,12 +2161,6 @@ func (vfs *VFS) SyncExamplePrograms(username string) error {
 		name := entry.Name()
 		lowerName := strings.ToLower(name)
 
-		contentBytes, err := os.ReadFile(filepath.Join(examplesDir, name))
-		if err != nil {
-			vfsDebugLog("[USER] Error reading example file %s: %v", name, err)
-			continue
-		}
-
 		var targetPath string
 		// BASIC programs and SID files go to /basic subdirectory
 		if strings.HasSuffix(lowerName, ".bas") || strings.HasSuffix(lowerName, ".sid") {
@@ -1964,12 +2173,11 @@ func (vfs *VFS) SyncExamplePrograms(username string) error {
 			continue
 		}
 
-		err = vfs.createFileWithoutLock(targetPath, string(contentBytes), time.Now())
-		if err != nil {
-			vfsDebugLog("[USER] Error creating %s: %v", name, err)
+		if err := vfs.linkExampleWithoutLock(username, targetPath, name); err != nil {
+			vfsDebugLog("[USER] Error linking %s: %v", name, err)
 			continue
 		}
-		vfsDebugLog("[USER] %s successfully created at %s", name, targetPath)
+		vfsDebugLog("[USER] %s successfully linked at %s", name, targetPath)
 	}
 
 	// Check created files
@@ -2019,6 +2227,102 @@ func (vfs *VFS) GetUserStorageInfo(username string) (*UserStorageInfo, error) {
 	}, nil
 }
 
+// GetDirectoryUsage returns the size of path and of every directory below it,
+// sorted largest first. Guests are served from their RAM tree, registered users
+// from the tree loaded out of virtual_files.
+func (vfs *VFS) GetDirectoryUsage(path, username string) ([]DirectoryUsage, error) {
+	if username == "" {
+		return nil, fmt.Errorf("username cannot be empty")
+	}
+
+	if username == "guest" {
+		vfs.mu.RLock()
+		_, guestExists := vfs.userRoots["guest"]
+		vfs.mu.RUnlock()
+		if !guestExists {
+			if err := vfs.InitializeGuestVFS(); err != nil {
+				return nil, fmt.Errorf("error initializing guest VFS: %v", err)
+			}
+		}
+	} else if err := vfs.safeInitializeUserVFS(username); err != nil {
+		return nil, fmt.Errorf("error initializing user VFS: %v", err)
+	}
+
+	vfs.mu.RLock()
+	defer vfs.mu.RUnlock()
+
+	node, remaining, err := vfs.resolvePathInternalWithoutLock(path)
+	if err != nil {
+		return nil, err
+	}
+	if remaining != "" {
+		return nil, fmt.Errorf("path not found: %s", path)
+	}
+	if !node.IsDir {
+		return nil, fmt.Errorf("is not a directory: %s", path)
+	}
+
+	var usage []DirectoryUsage
+	vfs.collectDirectoryUsage(node, strings.TrimSuffix(path, "/"), &usage)
+	sort.SliceStable(usage, func(i, j int) bool {
+		if usage[i].Bytes != usage[j].Bytes {
+			return usage[i].Bytes > usage[j].Bytes
+		}
+		return usage[i].Path < usage[j].Path
+	})
+	return usage, nil
+}
+
+// ListUserFiles returns every file below a user's home directory, sorted by path
+func (vfs *VFS) ListUserFiles(username string) ([]FileEntry, error) {
+	if username == "" {
+		return nil, fmt.Errorf("username cannot be empty")
+	}
+	if username != "guest" {
+		if err := vfs.safeInitializeUserVFS(username); err != nil {
+			return nil, fmt.Errorf("error initializing user VFS: %v", err)
+		}
+	}
+
+	vfs.mu.RLock()
+	defer vfs.mu.RUnlock()
+
+	homePath := "/home/" + username
+	home, remaining, err := vfs.resolvePathInternalWithoutLock(homePath)
+	if err != nil || remaining != "" || !home.IsDir {
+		return nil, fmt.Errorf("user home directory not found: %s", homePath)
+	}
+	var files []FileEntry
+	collectFiles(home, homePath, &files)
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// collectFiles appends all files below dir
+func collectFiles(dir *VirtualFile, path string, files *[]FileEntry) {
+	for name, child := range dir.Children {
+		if child.IsDir {
+			collectFiles(child, path+"/"+name, files)
+			continue
+		}
+		*files = append(*files, FileEntry{Path: path + "/" + name, Bytes: len(child.Content), IsLink: child.IsLink()})
+	}
+}
+
+// collectDirectoryUsage appends the usage of dir and all its subdirectories and returns the size of dir
+func (vfs *VFS) collectDirectoryUsage(dir *VirtualFile, path string, usage *[]DirectoryUsage) int {
+	total := 0
+	for name, child := range dir.Children {
+		if child.IsDir {
+			total += vfs.collectDirectoryUsage(child, path+"/"+name, usage)
+		} else {
+			total += len(child.Content)
+		}
+	}
+	*usage = append(*usage, DirectoryUsage{Path: path, Bytes: total})
+	return total
+}
+
 // calculateDirectorySize recursively calculates the total size of a directory
 func (vfs *VFS) calculateDirectorySize(dir *VirtualFile) int {
 	if dir == nil {